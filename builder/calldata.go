@@ -0,0 +1,131 @@
+package builder
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/davidt58/go-builder-relayer-client/errors"
+)
+
+// DecodedArg is one argument of a DecodedCall
+type DecodedArg struct {
+	// Name is the parameter name from the ABI
+	Name string `json:"name"`
+	// Type is the Solidity type (e.g. "uint256[]")
+	Type string `json:"type"`
+	// Value is the unpacked value (common.Address, *big.Int, [32]byte, ...)
+	Value interface{} `json:"value"`
+}
+
+// DecodedCall is calldata decoded against a registered ABI method
+type DecodedCall struct {
+	// Method is the method name (e.g. "redeemPositions")
+	Method string `json:"method"`
+	// Signature is the canonical signature the selector is derived from
+	Signature string `json:"signature"`
+	// Args are the decoded arguments in ABI order
+	Args []DecodedArg `json:"args"`
+}
+
+// String renders the call as method(name=value, ...) for batch previews
+func (c *DecodedCall) String() string {
+	args := make([]string, len(c.Args))
+	for i, arg := range c.Args {
+		args[i] = arg.Name + "=" + formatDecodedValue(arg.Value)
+	}
+	return c.Method + "(" + strings.Join(args, ", ") + ")"
+}
+
+// callRegistry maps 4-byte selectors to the ABI methods DecodeCall knows
+var (
+	callRegistryMu sync.RWMutex
+	callRegistry   = map[[4]byte]abi.Method{}
+)
+
+// RegisterABI makes every method of the contract ABI JSON decodable by
+// DecodeCall. A method with an already registered selector replaces it.
+func RegisterABI(abiJSON string) error {
+	parsed, err := abi.JSON(strings.NewReader(abiJSON))
+	if err != nil {
+		return errors.NewRelayerClientError("failed to parse ABI", err)
+	}
+	registerMethods(parsed)
+	return nil
+}
+
+// registerMethods adds the methods of parsed to the registry
+func registerMethods(parsed abi.ABI) {
+	callRegistryMu.Lock()
+	defer callRegistryMu.Unlock()
+	for _, method := range parsed.Methods {
+		var selector [4]byte
+		copy(selector[:], method.ID)
+		callRegistry[selector] = method
+	}
+}
+
+// DecodeCall decodes hex calldata against the registered ABIs. found is
+// false if no registered method has the calldata's selector; an error means
+// the selector matched but the arguments did not unpack.
+func DecodeCall(data string) (call *DecodedCall, found bool, err error) {
+	callData, err := hexutil.Decode(data)
+	if err != nil {
+		return nil, false, errors.NewRelayerClientError("invalid calldata", err)
+	}
+	if len(callData) < 4 {
+		return nil, false, nil
+	}
+
+	var selector [4]byte
+	copy(selector[:], callData[:4])
+	callRegistryMu.RLock()
+	method, ok := callRegistry[selector]
+	callRegistryMu.RUnlock()
+	if !ok {
+		return nil, false, nil
+	}
+
+	call, err = unpackCall(method, callData)
+	return call, true, err
+}
+
+// unpackCall decodes the arguments of callData, whose selector is method's
+func unpackCall(method abi.Method, callData []byte) (*DecodedCall, error) {
+	values, err := method.Inputs.Unpack(callData[4:])
+	if err != nil {
+		return nil, errors.NewRelayerClientError(fmt.Sprintf("failed to decode %s calldata", method.Sig), err)
+	}
+	call := &DecodedCall{Method: method.RawName, Signature: method.Sig, Args: make([]DecodedArg, len(values))}
+	for i, value := range values {
+		call.Args[i] = DecodedArg{Name: method.Inputs[i].Name, Type: method.Inputs[i].Type.String(), Value: value}
+	}
+	return call, nil
+}
+
+// formatDecodedValue renders an unpacked ABI value
+func formatDecodedValue(v interface{}) string {
+	switch value := v.(type) {
+	case common.Address:
+		return value.Hex()
+	case *big.Int:
+		return value.String()
+	case [32]byte:
+		return hexutil.Encode(value[:])
+	case []byte:
+		return hexutil.Encode(value)
+	case []*big.Int:
+		items := make([]string, len(value))
+		for i, item := range value {
+			items[i] = item.String()
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	default:
+		return fmt.Sprintf("%v", value)
+	}
+}
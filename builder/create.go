@@ -2,7 +2,9 @@ package builder
 
 import (
 	"encoding/json"
+	"fmt"
 	"math/big"
+	"strings"
 
 	"github.com/davidt58/go-builder-relayer-client/config"
 	"github.com/davidt58/go-builder-relayer-client/constants"
@@ -22,41 +24,69 @@ func CreateSafeCreateStructHash(args *models.SafeCreateTransactionArgs, sig *sig
 		return common.Hash{}, err
 	}
 
-	// For SAFE-CREATE, we use payment fields (all zeros/constants)
-	// This matches the Python implementation
-	paymentToken := common.HexToAddress(constants.ZERO_ADDRESS)
-	payment := big.NewInt(0)
-	paymentReceiver := common.HexToAddress(constants.ZERO_ADDRESS)
+	return CreateSafeCreateStructHashWithConfig(args, sig, contractConfig)
+}
 
-	// Build CreateProxy struct with payment fields
-	createProxy := &CreateProxy{
-		PaymentToken:    paymentToken,
-		Payment:         payment,
-		PaymentReceiver: paymentReceiver,
+// CreateSafeCreateStructHashWithConfig builds the Safe proxy creation struct
+// hash for the factory and chain in contractConfig
+func CreateSafeCreateStructHashWithConfig(args *models.SafeCreateTransactionArgs, sig *signer.Signer, contractConfig *config.ContractConfig) (common.Hash, error) {
+	if contractConfig == nil {
+		return common.Hash{}, errors.ErrMissingRequiredField("contractConfig")
+	}
+	if err := checkSignerChainID(sig, contractConfig.ChainID); err != nil {
+		return common.Hash{}, err
 	}
 
 	// Get verifying contract (the Safe Factory)
 	verifyingContract := common.HexToAddress(contractConfig.SafeFactory)
 
 	// Build and return the hash
-	return BuildCreateProxyHash(createProxy, verifyingContract, chainID)
+	return BuildCreateProxyHash(safeCreateProxy(), verifyingContract, contractConfig.ChainID)
+}
+
+// safeCreateProxy returns the CreateProxy a SAFE-CREATE signs. The payment
+// fields are all zeros/constants, matching the Python implementation.
+func safeCreateProxy() *CreateProxy {
+	return &CreateProxy{
+		PaymentToken:    common.HexToAddress(constants.ZERO_ADDRESS),
+		Payment:         big.NewInt(0),
+		PaymentReceiver: common.HexToAddress(constants.ZERO_ADDRESS),
+	}
 }
 
 // CreateSafeCreateSignature signs a Safe creation transaction and returns the signature
 func CreateSafeCreateSignature(args *models.SafeCreateTransactionArgs, sig *signer.Signer, chainID int64) (string, error) {
-	// Create the struct hash
-	structHash, err := CreateSafeCreateStructHash(args, sig, chainID)
+	contractConfig, err := config.GetContractConfig(chainID)
 	if err != nil {
 		return "", err
 	}
 
-	// Sign the struct hash using standard signing (not EIP-712)
-	// For SAFE-CREATE, we use regular Sign method
-	signature, err := sig.Sign(structHash.Bytes())
+	return CreateSafeCreateSignatureWithConfig(args, sig, contractConfig)
+}
+
+// CreateSafeCreateSignatureWithConfig signs a Safe creation transaction for
+// the factory and chain in contractConfig
+func CreateSafeCreateSignatureWithConfig(args *models.SafeCreateTransactionArgs, sig *signer.Signer, contractConfig *config.ContractConfig) (string, error) {
+	if contractConfig == nil {
+		return "", errors.ErrMissingRequiredField("contractConfig")
+	}
+	if err := checkSignerChainID(sig, contractConfig.ChainID); err != nil {
+		return "", err
+	}
+
+	// Build the typed data whose digest is the struct hash
+	typedData, err := createProxyTypedData(safeCreateProxy(), common.HexToAddress(contractConfig.SafeFactory), contractConfig.ChainID)
 	if err != nil {
 		return "", err
 	}
 
+	// Sign the digest directly, without the EIP-191 prefix; a remote signer
+	// is handed the typed data for eth_signTypedData_v4
+	signature, err := sig.SignTypedData(typedData)
+	if err != nil {
+		return "", errors.ErrStaged(errors.StageSign, err)
+	}
+
 	return signature, nil
 }
 
@@ -76,12 +106,41 @@ func BuildSafeCreateTransactionRequest(args *models.SafeCreateTransactionArgs, s
 		return nil, err
 	}
 
+	return BuildSafeCreateTransactionRequestWithConfig(args, sig, contractConfig)
+}
+
+// BuildSafeCreateTransactionRequestWithConfig builds a Safe creation request
+// against the factory and chain in contractConfig instead of the global registry
+func BuildSafeCreateTransactionRequestWithConfig(args *models.SafeCreateTransactionArgs, sig *signer.Signer, contractConfig *config.ContractConfig) (*models.TransactionRequest, error) {
+	if args == nil {
+		return nil, errors.ErrMissingRequiredField("args")
+	}
+	if sig == nil {
+		return nil, errors.ErrSignerNotConfigured
+	}
+	if contractConfig == nil {
+		return nil, errors.ErrMissingRequiredField("contractConfig")
+	}
+
+	// Refuse to sign a creation for a Safe the initializer will not produce
+	if !args.SkipSafeAddressCheck {
+		if err := checkSafeCreateAddress(args, contractConfig); err != nil {
+			return nil, err
+		}
+	}
+
 	// Create signature
-	signature, err := CreateSafeCreateSignature(args, sig, chainID)
+	signature, err := CreateSafeCreateSignatureWithConfig(args, sig, contractConfig)
 	if err != nil {
 		return nil, err
 	}
 
+	return assembleSafeCreateTransactionRequest(args, contractConfig, signature)
+}
+
+// assembleSafeCreateTransactionRequest builds the SAFE-CREATE request for
+// args against contractConfig's factory with the raw EIP-712 signature
+func assembleSafeCreateTransactionRequest(args *models.SafeCreateTransactionArgs, contractConfig *config.ContractConfig, signature string) (*models.TransactionRequest, error) {
 	// The "to" for Safe creation is the factory address
 	to := contractConfig.SafeFactory
 
@@ -109,6 +168,11 @@ func BuildSafeCreateTransactionRequest(args *models.SafeCreateTransactionArgs, s
 		PaymentReceiver: &paymentReceiver,
 	}
 
+	// The factory verifies the raw EIP-712 signature (v 27/28)
+	if err := checkSignatureType(signature, models.SignatureTypeEIP712); err != nil {
+		return nil, errors.ErrStaged(errors.StageSign, err)
+	}
+
 	// Create the request (matching Python structure)
 	request := &models.TransactionRequest{
 		Type:            string(models.SAFE_CREATE),
@@ -120,14 +184,147 @@ func BuildSafeCreateTransactionRequest(args *models.SafeCreateTransactionArgs, s
 		SignatureParams: signatureParams,
 	}
 
-	// Add metadata if provided
-	if args.Metadata != "" {
-		request.Metadata = &args.Metadata
-	}
+	// nil metadata omits the field; an empty string is sent as-is
+	request.Metadata = args.Metadata
 
 	return request, nil
 }
 
+// SafeCreateSigningPayload is what an external signer signs to deploy a
+// Safe, from BuildSafeCreateSigningPayload
+type SafeCreateSigningPayload struct {
+	// Args are the creation arguments to pass, with the signature, to
+	// BuildSafeCreateTransactionRequestWithSignature
+	Args *models.SafeCreateTransactionArgs
+	// DomainSeparator is the factory's EIP-712 domain separator
+	DomainSeparator common.Hash
+	// StructHash is the EIP-712 CreateProxy struct hash
+	StructHash common.Hash
+	// TypedData is the EIP-712 typed data as JSON, for eth_signTypedData_v4
+	// and wallet display
+	TypedData json.RawMessage
+	// Scheme is how the factory verifies the signature; SAFE-CREATE is
+	// always signed under SchemeEIP712, whatever the chain's scheme for SafeTx
+	Scheme signer.SignatureScheme
+	// Digest is the hash to sign under Scheme
+	Digest common.Hash
+}
+
+// BuildSafeCreateSigningPayload returns what signerAddress signs to deploy
+// its derived Safe on chainID, for signers outside the process (e.g. the
+// user's wallet). nonce is carried into the returned Args.
+func BuildSafeCreateSigningPayload(signerAddress common.Address, nonce string, chainID int64) (*SafeCreateSigningPayload, error) {
+	contractConfig, err := config.GetContractConfig(chainID)
+	if err != nil {
+		return nil, err
+	}
+	safeAddress, err := DeriveSafeAddressWithConfig(signerAddress, contractConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	typedData, err := createProxyTypedData(safeCreateProxy(), common.HexToAddress(contractConfig.SafeFactory), contractConfig.ChainID)
+	if err != nil {
+		return nil, err
+	}
+	domainSeparator, structHash, digest, err := digestParts("CreateProxy", typedData)
+	if err != nil {
+		return nil, err
+	}
+	typedDataJSON, err := json.Marshal(typedData)
+	if err != nil {
+		return nil, errors.ErrJSONMarshalFailed(err)
+	}
+
+	return &SafeCreateSigningPayload{
+		Args: &models.SafeCreateTransactionArgs{
+			SignerAddress: signerAddress.Hex(),
+			SafeAddress:   safeAddress.Hex(),
+			Nonce:         nonce,
+		},
+		DomainSeparator: domainSeparator,
+		StructHash:      structHash,
+		TypedData:       typedDataJSON,
+		Scheme:          signer.SchemeEIP712,
+		Digest:          digest,
+	}, nil
+}
+
+// BuildSafeCreateTransactionRequestWithSignature builds the same request as
+// BuildSafeCreateTransactionRequest with a signature made elsewhere, e.g.
+// over the Digest of BuildSafeCreateSigningPayload. signature may have v
+// 0/1 or 27/28; it must recover to args.SignerAddress, otherwise a
+// SignatureMismatchError is returned. args.SafeAddress is checked against
+// the derived Safe unless args.SkipSafeAddressCheck is set.
+func BuildSafeCreateTransactionRequestWithSignature(args *models.SafeCreateTransactionArgs, signature models.Signature, chainID int64) (*models.TransactionRequest, error) {
+	if args == nil {
+		return nil, errors.ErrMissingRequiredField("args")
+	}
+	if !common.IsHexAddress(args.SignerAddress) {
+		return nil, errors.ErrInvalidAddress(args.SignerAddress)
+	}
+	expected := common.HexToAddress(args.SignerAddress)
+	if signature.Signer != "" && !strings.EqualFold(signature.Signer, expected.Hex()) {
+		return nil, errors.ErrSignatureMismatch(expected.Hex(), signature.Signer)
+	}
+	if signature.SignatureType != "" && signature.SignatureType != models.SignatureTypeEIP712 {
+		return nil, errors.NewRelayerClientError(fmt.Sprintf("signature type %s is not %s; the factory verifies raw EIP-712 signatures", signature.SignatureType, models.SignatureTypeEIP712), nil)
+	}
+	contractConfig, err := config.GetContractConfig(chainID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Refuse a creation for a Safe the initializer will not produce
+	if !args.SkipSafeAddressCheck {
+		if err := checkSafeCreateAddress(args, contractConfig); err != nil {
+			return nil, err
+		}
+	}
+
+	digest, err := BuildCreateProxyHash(safeCreateProxy(), common.HexToAddress(contractConfig.SafeFactory), contractConfig.ChainID)
+	if err != nil {
+		return nil, err
+	}
+	signatureHex, err := signatureData(signature)
+	if err != nil {
+		return nil, err
+	}
+	packedSig, err := signer.SchemeEIP712.PackSignature(signatureHex)
+	if err != nil {
+		return nil, errors.ErrInvalidSignature(err)
+	}
+	recovered, err := signer.RecoverTypedSignature(digest.Bytes(), hexutil.MustDecode(packedSig), models.SignatureTypeEIP712)
+	if err != nil {
+		return nil, errors.ErrInvalidSignature(err)
+	}
+	if recovered != expected {
+		return nil, errors.ErrSignatureMismatch(expected.Hex(), recovered.Hex())
+	}
+
+	return assembleSafeCreateTransactionRequest(args, contractConfig, packedSig)
+}
+
+// checkSafeCreateAddress verifies args.SafeAddress is the Safe derived from
+// args.SignerAddress under contractConfig, ignoring case
+func checkSafeCreateAddress(args *models.SafeCreateTransactionArgs, contractConfig *config.ContractConfig) error {
+	if !common.IsHexAddress(args.SignerAddress) {
+		return errors.ErrInvalidAddress(args.SignerAddress)
+	}
+	if !common.IsHexAddress(args.SafeAddress) {
+		return errors.ErrInvalidAddress(args.SafeAddress)
+	}
+
+	derived, err := DeriveSafeAddressWithConfig(common.HexToAddress(args.SignerAddress), contractConfig)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(derived.Hex(), args.SafeAddress) {
+		return errors.ErrSafeAddressMismatch(args.SignerAddress, args.SafeAddress, derived.Hex())
+	}
+	return nil
+}
+
 // GetSafeCreationData returns the data needed for Safe creation
 // This is a helper function that can be used to inspect the creation parameters
 func GetSafeCreationData(signerAddress common.Address, chainID int64) (map[string]interface{}, error) {
@@ -141,7 +338,7 @@ func GetSafeCreationData(signerAddress common.Address, chainID int64) (map[strin
 		return nil, err
 	}
 
-	safeAddress, err := DeriveSafeAddress(signerAddress, chainID)
+	safeAddress, err := DeriveSafeAddressWithConfig(signerAddress, contractConfig)
 	if err != nil {
 		return nil, err
 	}
@@ -166,6 +363,6 @@ func VerifySafeCreationSignature(args *models.SafeCreateTransactionArgs, sig *si
 		return false, err
 	}
 
-	// Verify the signature
-	return sig.VerifySignature(structHash.Bytes(), signature)
+	// SAFE-CREATE signatures are raw EIP-712 signatures
+	return sig.VerifySignatureRaw(structHash.Bytes(), signature)
 }
@@ -0,0 +1,358 @@
+package builder
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/davidt58/go-builder-relayer-client/config"
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/models"
+	"github.com/davidt58/go-builder-relayer-client/signer"
+)
+
+func TestBuildSafeCreateTransactionRequestWithConfig(t *testing.T) {
+	custom := customContractConfig()
+	sig, err := signer.NewSigner("ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80", custom.ChainID)
+	if err != nil {
+		t.Fatalf("Failed to create signer: %v", err)
+	}
+	polygonSig, err := signer.NewSigner("ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80", 137)
+	if err != nil {
+		t.Fatalf("Failed to create signer: %v", err)
+	}
+
+	safeAddress, err := DeriveSafeAddressWithConfig(sig.Address(), custom)
+	if err != nil {
+		t.Fatalf("DeriveSafeAddressWithConfig failed: %v", err)
+	}
+	args := &models.SafeCreateTransactionArgs{
+		SignerAddress: sig.AddressHex(),
+		SafeAddress:   safeAddress.Hex(),
+	}
+
+	request, err := BuildSafeCreateTransactionRequestWithConfig(args, sig, custom)
+	if err != nil {
+		t.Fatalf("BuildSafeCreateTransactionRequestWithConfig failed: %v", err)
+	}
+
+	var to string
+	if err := json.Unmarshal(request.To, &to); err != nil {
+		t.Fatalf("failed to decode to: %v", err)
+	}
+	if to != custom.SafeFactory {
+		t.Errorf("To = %s, want custom factory %s", to, custom.SafeFactory)
+	}
+
+	// The signature covers the custom factory and chain
+	customHash, err := CreateSafeCreateStructHashWithConfig(args, sig, custom)
+	if err != nil {
+		t.Fatalf("CreateSafeCreateStructHashWithConfig failed: %v", err)
+	}
+	registeredHash, err := CreateSafeCreateStructHash(args, polygonSig, 137)
+	if err != nil {
+		t.Fatalf("CreateSafeCreateStructHash failed: %v", err)
+	}
+	if customHash == registeredHash {
+		t.Error("Custom config should produce a different struct hash")
+	}
+	valid, err := sig.VerifySignature(customHash.Bytes(), request.Signature)
+	if err != nil {
+		t.Fatalf("VerifySignature failed: %v", err)
+	}
+	if !valid {
+		t.Error("Signature should verify against the custom struct hash")
+	}
+
+	if _, err := config.GetContractConfig(custom.ChainID); err == nil {
+		t.Error("Custom chain should not be registered")
+	}
+}
+
+func TestBuildSafeCreateTransactionRequestWithConfig_MissingInputs(t *testing.T) {
+	sig, err := signer.NewSigner("ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80", 137)
+	if err != nil {
+		t.Fatalf("Failed to create signer: %v", err)
+	}
+	args := &models.SafeCreateTransactionArgs{SignerAddress: sig.AddressHex()}
+
+	tests := []struct {
+		name   string
+		args   *models.SafeCreateTransactionArgs
+		sig    *signer.Signer
+		config *config.ContractConfig
+	}{
+		{name: "nil args", sig: sig, config: customContractConfig()},
+		{name: "nil signer", args: args, config: customContractConfig()},
+		{name: "nil config", args: args, sig: sig},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := BuildSafeCreateTransactionRequestWithConfig(tt.args, tt.sig, tt.config); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+}
+
+func TestBuildSafeCreateTransactionRequest_SafeAddressCheck(t *testing.T) {
+	sig, err := signer.NewSigner("ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80", 137)
+	if err != nil {
+		t.Fatalf("Failed to create signer: %v", err)
+	}
+	derived, err := DeriveSafeAddress(sig.Address(), 137)
+	if err != nil {
+		t.Fatalf("DeriveSafeAddress failed: %v", err)
+	}
+	other := "0x5FbDB2315678afecb367f032d93F642f64180aa3"
+
+	tests := []struct {
+		name         string
+		safeAddress  string
+		skip         bool
+		wantMismatch bool
+		shouldErr    bool
+	}{
+		{name: "matching", safeAddress: derived.Hex()},
+		{name: "lowercase", safeAddress: strings.ToLower(derived.Hex())},
+		{name: "uppercase", safeAddress: "0x" + strings.ToUpper(derived.Hex()[2:])},
+		{name: "mismatching", safeAddress: other, wantMismatch: true, shouldErr: true},
+		{name: "mismatching with check skipped", safeAddress: other, skip: true},
+		{name: "invalid address", safeAddress: "0x1234", shouldErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := &models.SafeCreateTransactionArgs{
+				SignerAddress:        sig.AddressHex(),
+				SafeAddress:          tt.safeAddress,
+				SkipSafeAddressCheck: tt.skip,
+			}
+
+			request, err := BuildSafeCreateTransactionRequest(args, sig, 137)
+			if tt.shouldErr {
+				if err == nil {
+					t.Fatal("Expected error")
+				}
+				var mismatch *errors.SafeAddressMismatchError
+				if stderrors.As(err, &mismatch) != tt.wantMismatch {
+					t.Fatalf("error = %v, want SafeAddressMismatchError: %v", err, tt.wantMismatch)
+				}
+				if tt.wantMismatch {
+					if mismatch.Declared != tt.safeAddress || mismatch.Derived != derived.Hex() || mismatch.Signer != sig.AddressHex() {
+						t.Errorf("mismatch = %+v", mismatch)
+					}
+					if !strings.Contains(err.Error(), tt.safeAddress) || !strings.Contains(err.Error(), derived.Hex()) {
+						t.Errorf("error %q should name both addresses", err.Error())
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("BuildSafeCreateTransactionRequest failed: %v", err)
+			}
+			if request.ProxyWallet != tt.safeAddress {
+				t.Errorf("ProxyWallet = %s, want %s", request.ProxyWallet, tt.safeAddress)
+			}
+		})
+	}
+}
+
+func TestBuildSafeCreateSigningPayload(t *testing.T) {
+	sig, err := signer.NewSigner("ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80", 137)
+	if err != nil {
+		t.Fatalf("Failed to create signer: %v", err)
+	}
+
+	payload, err := BuildSafeCreateSigningPayload(sig.Address(), "0", 137)
+	if err != nil {
+		t.Fatalf("BuildSafeCreateSigningPayload failed: %v", err)
+	}
+	derived, err := DeriveSafeAddress(sig.Address(), 137)
+	if err != nil {
+		t.Fatalf("DeriveSafeAddress failed: %v", err)
+	}
+	if payload.Args.SafeAddress != derived.Hex() || payload.Args.SignerAddress != sig.AddressHex() || payload.Args.Nonce != "0" {
+		t.Errorf("Args = %+v, want the derived Safe %s", payload.Args, derived.Hex())
+	}
+	if payload.Scheme != signer.SchemeEIP712 {
+		t.Errorf("Scheme = %s, want %s", payload.Scheme, signer.SchemeEIP712)
+	}
+
+	// The digest is the one the in-process signer signs, and the typed data
+	// a wallet displays hashes to it
+	want, err := CreateSafeCreateStructHash(payload.Args, sig, 137)
+	if err != nil {
+		t.Fatalf("CreateSafeCreateStructHash failed: %v", err)
+	}
+	if payload.Digest != want {
+		t.Errorf("Digest = %s, want %s", payload.Digest.Hex(), want.Hex())
+	}
+	var typedData signer.TypedData
+	if err := json.Unmarshal(payload.TypedData, &typedData); err != nil {
+		t.Fatalf("failed to decode typed data: %v", err)
+	}
+	domainSeparator, structHash, digest, err := signer.HashTypedDataParts(&typedData)
+	if err != nil {
+		t.Fatalf("HashTypedDataParts failed: %v", err)
+	}
+	if digest != payload.Digest || structHash != payload.StructHash || domainSeparator != payload.DomainSeparator {
+		t.Errorf("typed data hashes to %s (%s, %s), want %s (%s, %s)", digest.Hex(), domainSeparator.Hex(), structHash.Hex(),
+			payload.Digest.Hex(), payload.DomainSeparator.Hex(), payload.StructHash.Hex())
+	}
+
+	// Signed from the payload, the request is the one BuildSafeCreateTransactionRequest builds
+	signature, err := sig.Sign(payload.Digest.Bytes())
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	request, err := BuildSafeCreateTransactionRequestWithSignature(payload.Args, models.Signature{Data: signature}, 137)
+	if err != nil {
+		t.Fatalf("BuildSafeCreateTransactionRequestWithSignature failed: %v", err)
+	}
+	expected, err := BuildSafeCreateTransactionRequest(payload.Args, sig, 137)
+	if err != nil {
+		t.Fatalf("BuildSafeCreateTransactionRequest failed: %v", err)
+	}
+	got, _ := json.Marshal(request)
+	wantJSON, _ := json.Marshal(expected)
+	if string(got) != string(wantJSON) {
+		t.Errorf("request = %s\nwant %s", got, wantJSON)
+	}
+}
+
+func TestBuildSafeCreateTransactionRequestWithSignature(t *testing.T) {
+	sig, err := signer.NewSigner("ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80", 137)
+	if err != nil {
+		t.Fatalf("Failed to create signer: %v", err)
+	}
+	payload, err := BuildSafeCreateSigningPayload(sig.Address(), "", 137)
+	if err != nil {
+		t.Fatalf("BuildSafeCreateSigningPayload failed: %v", err)
+	}
+	signature, err := sig.Sign(payload.Digest.Bytes())
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	raw := hexutil.MustDecode(signature)
+	raw[64] -= 27
+	otherDigest, err := sig.Sign(payload.StructHash.Bytes())
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	other := "0x5FbDB2315678afecb367f032d93F642f64180aa3"
+
+	tests := []struct {
+		name         string
+		args         models.SafeCreateTransactionArgs
+		signature    models.Signature
+		wantMismatch bool
+		shouldErr    bool
+	}{
+		{name: "v 27/28", args: *payload.Args, signature: models.Signature{Data: signature}},
+		{name: "v 0/1", args: *payload.Args, signature: models.Signature{Data: hexutil.Encode(raw)}},
+		{name: "declared signer and type", args: *payload.Args, signature: models.Signature{Signer: sig.AddressHex(), Data: signature, SignatureType: models.SignatureTypeEIP712}},
+		{name: "eth_sign type", args: *payload.Args, signature: models.Signature{Data: signature, SignatureType: models.SignatureTypeEthSign}, shouldErr: true},
+		{name: "declared signer differs", args: *payload.Args, signature: models.Signature{Signer: other, Data: signature}, wantMismatch: true, shouldErr: true},
+		{name: "signature over another hash", args: *payload.Args, signature: models.Signature{Data: otherDigest}, wantMismatch: true, shouldErr: true},
+		{name: "another signer", args: models.SafeCreateTransactionArgs{SignerAddress: other, SafeAddress: payload.Args.SafeAddress, SkipSafeAddressCheck: true},
+			signature: models.Signature{Data: signature}, wantMismatch: true, shouldErr: true},
+		{name: "Safe address mismatch", args: models.SafeCreateTransactionArgs{SignerAddress: sig.AddressHex(), SafeAddress: other}, signature: models.Signature{Data: signature}, shouldErr: true},
+		{name: "no signature", args: *payload.Args, shouldErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := tt.args
+			request, err := BuildSafeCreateTransactionRequestWithSignature(&args, tt.signature, 137)
+			if (err != nil) != tt.shouldErr {
+				t.Fatalf("BuildSafeCreateTransactionRequestWithSignature() error = %v, shouldErr %v", err, tt.shouldErr)
+			}
+			var mismatch *errors.SignatureMismatchError
+			if stderrors.As(err, &mismatch) != tt.wantMismatch {
+				t.Errorf("error = %v, want SignatureMismatchError: %v", err, tt.wantMismatch)
+			}
+			if err == nil && request.Signature != signature {
+				t.Errorf("Signature = %s, want %s with v 27/28", request.Signature, signature)
+			}
+		})
+	}
+}
+
+// typedDataOnlyBackend is a signer backend that, like a JSON-RPC signer,
+// cannot sign bare digests
+type typedDataOnlyBackend struct {
+	signer.SignerBackend
+}
+
+func (b typedDataOnlyBackend) SignHash(hash []byte) ([]byte, error) {
+	return nil, stderrors.New("bare digest")
+}
+
+func TestSignaturesWithTypedDataBackend(t *testing.T) {
+	local, err := signer.NewSigner("ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80", 137)
+	if err != nil {
+		t.Fatalf("Failed to create signer: %v", err)
+	}
+	// Delegate everything but bare digests to the local key
+	backend := typedDataOnlyBackend{SignerBackend: localBackend{local}}
+	remote := signer.NewSignerWithBackend(backend, local.Address(), 137)
+
+	createArgs := &models.SafeCreateTransactionArgs{SignerAddress: local.AddressHex()}
+	want, err := CreateSafeCreateSignature(createArgs, local, 137)
+	if err != nil {
+		t.Fatalf("CreateSafeCreateSignature failed: %v", err)
+	}
+	got, err := CreateSafeCreateSignature(createArgs, remote, 137)
+	if err != nil {
+		t.Fatalf("CreateSafeCreateSignature with a typed-data backend failed: %v", err)
+	}
+	if got != want {
+		t.Errorf("SAFE-CREATE signature = %s, want %s", got, want)
+	}
+
+	safeArgs := &models.SafeTransactionArgs{
+		SafeAddress:  "0x1111111111111111111111111111111111111111",
+		Nonce:        "3",
+		Transactions: []models.SafeTransaction{*models.NewSafeTransaction("0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174", "0", "0x")},
+	}
+	want, err = CreateSafeSignatureWithScheme(safeArgs, local, signer.SchemeEIP712)
+	if err != nil {
+		t.Fatalf("CreateSafeSignatureWithScheme failed: %v", err)
+	}
+	got, err = CreateSafeSignatureWithScheme(safeArgs, remote, signer.SchemeEIP712)
+	if err != nil {
+		t.Fatalf("CreateSafeSignatureWithScheme with a typed-data backend failed: %v", err)
+	}
+	if got != want {
+		t.Errorf("SAFE signature = %s, want %s", got, want)
+	}
+}
+
+// localBackend signs through a local Signer's exported methods
+type localBackend struct {
+	sig *signer.Signer
+}
+
+func (b localBackend) SignHash(hash []byte) ([]byte, error) {
+	return decodeSigned(b.sig.Sign(hash))
+}
+
+func (b localBackend) SignPersonal(message []byte) ([]byte, error) {
+	return decodeSigned(b.sig.SignMessage(message))
+}
+
+func (b localBackend) SignTypedData(typedData *signer.TypedData, digest []byte) ([]byte, error) {
+	return decodeSigned(b.sig.SignTypedData(typedData))
+}
+
+func decodeSigned(signature string, err error) ([]byte, error) {
+	if err != nil {
+		return nil, err
+	}
+	return hexutil.Decode(signature)
+}
@@ -0,0 +1,178 @@
+package builder
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+// ConditionalTokensABI is the subset of the Conditional Tokens (ERC-1155)
+// contract the CTF encoders call
+const ConditionalTokensABI = `[
+	{"name":"safeTransferFrom","type":"function","inputs":[{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"id","type":"uint256"},{"name":"value","type":"uint256"},{"name":"data","type":"bytes"}],"outputs":[]},
+	{"name":"safeBatchTransferFrom","type":"function","inputs":[{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"ids","type":"uint256[]"},{"name":"values","type":"uint256[]"},{"name":"data","type":"bytes"}],"outputs":[]},
+	{"name":"redeemPositions","type":"function","inputs":[{"name":"collateralToken","type":"address"},{"name":"parentCollectionId","type":"bytes32"},{"name":"conditionId","type":"bytes32"},{"name":"indexSets","type":"uint256[]"}],"outputs":[]},
+	{"name":"splitPosition","type":"function","inputs":[{"name":"collateralToken","type":"address"},{"name":"parentCollectionId","type":"bytes32"},{"name":"conditionId","type":"bytes32"},{"name":"partition","type":"uint256[]"},{"name":"amount","type":"uint256"}],"outputs":[]},
+	{"name":"mergePositions","type":"function","inputs":[{"name":"collateralToken","type":"address"},{"name":"parentCollectionId","type":"bytes32"},{"name":"conditionId","type":"bytes32"},{"name":"partition","type":"uint256[]"},{"name":"amount","type":"uint256"}],"outputs":[]}
+]`
+
+// conditionalTokens is the parsed ConditionalTokensABI
+var conditionalTokens = mustParseABI(ConditionalTokensABI)
+
+func init() {
+	registerMethods(conditionalTokens)
+}
+
+// mustParseABI parses an ABI the package defines
+func mustParseABI(abiJSON string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(abiJSON))
+	if err != nil {
+		panic(fmt.Sprintf("invalid ABI: %v", err))
+	}
+	return parsed
+}
+
+// EncodeCTFSafeTransfer encodes an ERC-1155 safeTransferFrom of amount of
+// position tokenID on the Conditional Tokens contract ctf
+func EncodeCTFSafeTransfer(ctf, from, to string, tokenID, amount *big.Int) (models.SafeTransaction, error) {
+	if err := checkAddresses(map[string]string{"ctf": ctf, "from": from, "to": to}); err != nil {
+		return models.SafeTransaction{}, err
+	}
+	if err := checkUints(map[string]*big.Int{"tokenID": tokenID, "amount": amount}); err != nil {
+		return models.SafeTransaction{}, err
+	}
+	return packCTFCall(ctf, "safeTransferFrom", common.HexToAddress(from), common.HexToAddress(to), tokenID, amount, []byte{})
+}
+
+// EncodeCTFBatchTransfer encodes an ERC-1155 safeBatchTransferFrom of
+// amounts[i] of position ids[i] on the Conditional Tokens contract ctf
+func EncodeCTFBatchTransfer(ctf, from, to string, ids, amounts []*big.Int) (models.SafeTransaction, error) {
+	if err := checkAddresses(map[string]string{"ctf": ctf, "from": from, "to": to}); err != nil {
+		return models.SafeTransaction{}, err
+	}
+	if len(ids) == 0 {
+		return models.SafeTransaction{}, errors.ErrMissingRequiredField("ids")
+	}
+	if len(ids) != len(amounts) {
+		return models.SafeTransaction{}, errors.NewRelayerClientError(fmt.Sprintf("got %d ids but %d amounts", len(ids), len(amounts)), nil)
+	}
+	if err := checkUintList("ids", ids); err != nil {
+		return models.SafeTransaction{}, err
+	}
+	if err := checkUintList("amounts", amounts); err != nil {
+		return models.SafeTransaction{}, err
+	}
+	return packCTFCall(ctf, "safeBatchTransferFrom", common.HexToAddress(from), common.HexToAddress(to), ids, amounts, []byte{})
+}
+
+// EncodeRedeemPositions encodes a redeemPositions call redeeming the
+// indexSets of a resolved condition for collateral
+func EncodeRedeemPositions(ctf, collateral string, parentCollectionID, conditionID common.Hash, indexSets []*big.Int) (models.SafeTransaction, error) {
+	if err := checkAddresses(map[string]string{"ctf": ctf, "collateral": collateral}); err != nil {
+		return models.SafeTransaction{}, err
+	}
+	if err := checkIndexSets("indexSets", indexSets); err != nil {
+		return models.SafeTransaction{}, err
+	}
+	return packCTFCall(ctf, "redeemPositions", common.HexToAddress(collateral), parentCollectionID, conditionID, indexSets)
+}
+
+// EncodeSplitPosition encodes a splitPosition call splitting amount of
+// collateral (or of the parent position) into the partition's positions
+func EncodeSplitPosition(ctf, collateral string, parentCollectionID, conditionID common.Hash, partition []*big.Int, amount *big.Int) (models.SafeTransaction, error) {
+	return encodePartitionCall("splitPosition", ctf, collateral, parentCollectionID, conditionID, partition, amount)
+}
+
+// EncodeMergePositions encodes a mergePositions call merging amount of each
+// of the partition's positions back into collateral (or the parent position)
+func EncodeMergePositions(ctf, collateral string, parentCollectionID, conditionID common.Hash, partition []*big.Int, amount *big.Int) (models.SafeTransaction, error) {
+	return encodePartitionCall("mergePositions", ctf, collateral, parentCollectionID, conditionID, partition, amount)
+}
+
+// encodePartitionCall encodes splitPosition or mergePositions, which share
+// their arguments
+func encodePartitionCall(method, ctf, collateral string, parentCollectionID, conditionID common.Hash, partition []*big.Int, amount *big.Int) (models.SafeTransaction, error) {
+	if err := checkAddresses(map[string]string{"ctf": ctf, "collateral": collateral}); err != nil {
+		return models.SafeTransaction{}, err
+	}
+	if err := checkIndexSets("partition", partition); err != nil {
+		return models.SafeTransaction{}, err
+	}
+	if err := checkUints(map[string]*big.Int{"amount": amount}); err != nil {
+		return models.SafeTransaction{}, err
+	}
+	return packCTFCall(ctf, method, common.HexToAddress(collateral), parentCollectionID, conditionID, partition, amount)
+}
+
+// packCTFCall packs a Conditional Tokens call into a Call to ctf
+func packCTFCall(ctf, method string, args ...interface{}) (models.SafeTransaction, error) {
+	data, err := conditionalTokens.Pack(method, args...)
+	if err != nil {
+		return models.SafeTransaction{}, errors.NewRelayerClientError(fmt.Sprintf("failed to pack %s call", method), err)
+	}
+	return models.SafeTransaction{
+		To:        common.HexToAddress(ctf).Hex(),
+		Value:     "0",
+		Data:      hexutil.Encode(data),
+		Operation: models.Call,
+	}, nil
+}
+
+// checkAddresses validates named address arguments
+func checkAddresses(addresses map[string]string) error {
+	for name, address := range addresses {
+		if address == "" {
+			return errors.ErrMissingRequiredField(name)
+		}
+		if !common.IsHexAddress(address) {
+			return errors.ErrInvalidAddress(address)
+		}
+	}
+	return nil
+}
+
+// checkUints validates named uint256 arguments
+func checkUints(values map[string]*big.Int) error {
+	for name, value := range values {
+		if value == nil {
+			return errors.ErrMissingRequiredField(name)
+		}
+		if value.Sign() < 0 || value.Cmp(maxUint256) > 0 {
+			return errors.NewRelayerClientError(fmt.Sprintf("%s %s is not a uint256", name, value), nil)
+		}
+	}
+	return nil
+}
+
+// checkUintList validates the elements of a uint256[] argument
+func checkUintList(name string, values []*big.Int) error {
+	for i, value := range values {
+		if err := checkUints(map[string]*big.Int{fmt.Sprintf("%s[%d]", name, i): value}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkIndexSets validates a non-empty list of non-zero index sets
+func checkIndexSets(name string, indexSets []*big.Int) error {
+	if len(indexSets) == 0 {
+		return errors.ErrMissingRequiredField(name)
+	}
+	if err := checkUintList(name, indexSets); err != nil {
+		return err
+	}
+	for i, indexSet := range indexSets {
+		if indexSet.Sign() == 0 {
+			return errors.NewRelayerClientError(fmt.Sprintf("%s[%d] is an empty index set", name, i), nil)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,237 @@
+package builder
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+const (
+	testCTF        = "0x4D97DCd97eC945f40cF65F87097ACe5EA0476045"
+	testCollateral = "0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174"
+	testHolder     = "0x6e0c80c90ea6c15917308F820Eac91Ce2724B5b5"
+	testRecipient  = "0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266"
+)
+
+// testConditionID is a binary market's condition on Polygon
+var testConditionID = common.HexToHash("0x5f65177b394277fd294cd75650044e32ba009a95022d88a0c1d565897d72f8f1")
+
+// bigs converts values to *big.Int
+func bigs(values ...int64) []*big.Int {
+	out := make([]*big.Int, len(values))
+	for i, v := range values {
+		out[i] = big.NewInt(v)
+	}
+	return out
+}
+
+// ctfVectors encodes one call of each CTF encoder
+func ctfVectors(t *testing.T) map[string]models.SafeTransaction {
+	t.Helper()
+
+	tokenID, _ := new(big.Int).SetString("21742633143463906290569050155826241533067272736897614950488156847949938836455", 10)
+	amount := big.NewInt(25_000_000)
+	encoders := map[string]func() (models.SafeTransaction, error){
+		"safeTransferFrom": func() (models.SafeTransaction, error) {
+			return EncodeCTFSafeTransfer(testCTF, testHolder, testRecipient, tokenID, amount)
+		},
+		"safeBatchTransferFrom": func() (models.SafeTransaction, error) {
+			return EncodeCTFBatchTransfer(testCTF, testHolder, testRecipient, []*big.Int{tokenID, big.NewInt(7)}, []*big.Int{amount, big.NewInt(1)})
+		},
+		"redeemPositions": func() (models.SafeTransaction, error) {
+			return EncodeRedeemPositions(testCTF, testCollateral, common.Hash{}, testConditionID, bigs(1, 2))
+		},
+		"splitPosition": func() (models.SafeTransaction, error) {
+			return EncodeSplitPosition(testCTF, testCollateral, common.Hash{}, testConditionID, bigs(1, 2), amount)
+		},
+		"mergePositions": func() (models.SafeTransaction, error) {
+			return EncodeMergePositions(testCTF, testCollateral, common.Hash{}, testConditionID, bigs(1, 2), amount)
+		},
+	}
+
+	vectors := make(map[string]models.SafeTransaction)
+	for name, encode := range encoders {
+		txn, err := encode()
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		vectors[name] = txn
+	}
+	return vectors
+}
+
+func TestCTFEncoders_Golden(t *testing.T) {
+	vectors := ctfVectors(t)
+
+	data, err := json.MarshalIndent(vectors, "", "  ")
+	if err != nil {
+		t.Fatalf("failed to marshal vectors: %v", err)
+	}
+	compareGolden(t, "ctf_calldata.json", append(data, '\n'))
+}
+
+func TestCTFEncoders_Selectors(t *testing.T) {
+	want := map[string]string{
+		"safeTransferFrom":      "0xf242432a",
+		"safeBatchTransferFrom": "0x2eb2c2d6",
+		"redeemPositions":       "0x01b7037c",
+		"splitPosition":         "0x72ce4275",
+		"mergePositions":        "0x9e7212ad",
+	}
+
+	for name, txn := range ctfVectors(t) {
+		if !strings.HasPrefix(txn.Data, want[name]) {
+			t.Errorf("%s calldata starts with %s, want selector %s", name, txn.Data[:10], want[name])
+		}
+		if txn.To != testCTF || txn.Value != "0" || txn.Operation != models.Call {
+			t.Errorf("%s = %+v, want a zero-value Call to the CTF", name, txn)
+		}
+	}
+}
+
+func TestEncodeCTFSafeTransfer_Layout(t *testing.T) {
+	txn, err := EncodeCTFSafeTransfer(testCTF, testHolder, testRecipient, big.NewInt(1), big.NewInt(2))
+	if err != nil {
+		t.Fatalf("EncodeCTFSafeTransfer failed: %v", err)
+	}
+
+	// selector ++ from ++ to ++ id ++ value ++ data offset ++ data length
+	want := "0xf242432a" +
+		"0000000000000000000000006e0c80c90ea6c15917308f820eac91ce2724b5b5" +
+		"000000000000000000000000f39fd6e51aad88f6f4ce6ab8827279cfffb92266" +
+		"0000000000000000000000000000000000000000000000000000000000000001" +
+		"0000000000000000000000000000000000000000000000000000000000000002" +
+		"00000000000000000000000000000000000000000000000000000000000000a0" +
+		"0000000000000000000000000000000000000000000000000000000000000000"
+	if txn.Data != want {
+		t.Errorf("calldata = %s, want %s", txn.Data, want)
+	}
+}
+
+func TestCTFEncoders_Validation(t *testing.T) {
+	tests := []struct {
+		name      string
+		encode    func() (models.SafeTransaction, error)
+		shouldErr bool
+	}{
+		{"transfer", func() (models.SafeTransaction, error) {
+			return EncodeCTFSafeTransfer(testCTF, testHolder, testRecipient, big.NewInt(1), big.NewInt(0))
+		}, false},
+		{"transfer invalid from", func() (models.SafeTransaction, error) {
+			return EncodeCTFSafeTransfer(testCTF, "0x1234", testRecipient, big.NewInt(1), big.NewInt(1))
+		}, true},
+		{"transfer missing ctf", func() (models.SafeTransaction, error) {
+			return EncodeCTFSafeTransfer("", testHolder, testRecipient, big.NewInt(1), big.NewInt(1))
+		}, true},
+		{"transfer nil amount", func() (models.SafeTransaction, error) {
+			return EncodeCTFSafeTransfer(testCTF, testHolder, testRecipient, big.NewInt(1), nil)
+		}, true},
+		{"transfer negative amount", func() (models.SafeTransaction, error) {
+			return EncodeCTFSafeTransfer(testCTF, testHolder, testRecipient, big.NewInt(1), big.NewInt(-1))
+		}, true},
+		{"batch length mismatch", func() (models.SafeTransaction, error) {
+			return EncodeCTFBatchTransfer(testCTF, testHolder, testRecipient, bigs(1, 2), bigs(1))
+		}, true},
+		{"batch empty", func() (models.SafeTransaction, error) {
+			return EncodeCTFBatchTransfer(testCTF, testHolder, testRecipient, nil, nil)
+		}, true},
+		{"batch nil id", func() (models.SafeTransaction, error) {
+			return EncodeCTFBatchTransfer(testCTF, testHolder, testRecipient, []*big.Int{nil}, bigs(1))
+		}, true},
+		{"redeem empty index sets", func() (models.SafeTransaction, error) {
+			return EncodeRedeemPositions(testCTF, testCollateral, common.Hash{}, testConditionID, nil)
+		}, true},
+		{"redeem zero index set", func() (models.SafeTransaction, error) {
+			return EncodeRedeemPositions(testCTF, testCollateral, common.Hash{}, testConditionID, bigs(1, 0))
+		}, true},
+		{"redeem invalid collateral", func() (models.SafeTransaction, error) {
+			return EncodeRedeemPositions(testCTF, "usdc", common.Hash{}, testConditionID, bigs(1))
+		}, true},
+		{"split empty partition", func() (models.SafeTransaction, error) {
+			return EncodeSplitPosition(testCTF, testCollateral, common.Hash{}, testConditionID, nil, big.NewInt(1))
+		}, true},
+		{"merge nil amount", func() (models.SafeTransaction, error) {
+			return EncodeMergePositions(testCTF, testCollateral, common.Hash{}, testConditionID, bigs(1, 2), nil)
+		}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.encode()
+			if tt.shouldErr && err == nil {
+				t.Error("Expected error but got none")
+			}
+			if !tt.shouldErr && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestDecodeCall(t *testing.T) {
+	vectors := ctfVectors(t)
+
+	tests := []struct {
+		name      string
+		data      string
+		want      string
+		wantFound bool
+		shouldErr bool
+	}{
+		{
+			name:      "redeemPositions",
+			data:      vectors["redeemPositions"].Data,
+			want:      "redeemPositions(collateralToken=0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174, parentCollectionId=0x0000000000000000000000000000000000000000000000000000000000000000, conditionId=0x5f65177b394277fd294cd75650044e32ba009a95022d88a0c1d565897d72f8f1, indexSets=[1, 2])",
+			wantFound: true,
+		},
+		{
+			name:      "safeBatchTransferFrom",
+			data:      vectors["safeBatchTransferFrom"].Data,
+			want:      "safeBatchTransferFrom(from=0x6e0c80c90ea6c15917308F820Eac91Ce2724B5b5, to=0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266, ids=[21742633143463906290569050155826241533067272736897614950488156847949938836455, 7], values=[25000000, 1], data=0x)",
+			wantFound: true,
+		},
+		{name: "unknown selector", data: "0xa9059cbb"},
+		{name: "empty", data: "0x"},
+		{name: "truncated arguments", data: vectors["splitPosition"].Data[:74], wantFound: true, shouldErr: true},
+		{name: "invalid hex", data: "0xzz", shouldErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			call, found, err := DecodeCall(tt.data)
+			if (err != nil) != tt.shouldErr {
+				t.Fatalf("DecodeCall() error = %v, shouldErr %v", err, tt.shouldErr)
+			}
+			if found != tt.wantFound {
+				t.Fatalf("found = %v, want %v", found, tt.wantFound)
+			}
+			if tt.want != "" && call.String() != tt.want {
+				t.Errorf("String() = %s\nwant %s", call.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestRegisterABI(t *testing.T) {
+	const erc20ABI = `[{"name":"approve","type":"function","inputs":[{"name":"spender","type":"address"},{"name":"amount","type":"uint256"}],"outputs":[{"name":"","type":"bool"}]}]`
+	if err := RegisterABI(erc20ABI); err != nil {
+		t.Fatalf("RegisterABI failed: %v", err)
+	}
+
+	call, found, err := DecodeCall("0x095ea7b3000000000000000000000000f39fd6e51aad88f6f4ce6ab8827279cfffb922660000000000000000000000000000000000000000000000000000000000000005")
+	if err != nil || !found {
+		t.Fatalf("DecodeCall() = %v, %v", found, err)
+	}
+	if got := call.String(); got != "approve(spender=0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266, amount=5)" {
+		t.Errorf("String() = %s", got)
+	}
+
+	if err := RegisterABI("not json"); err == nil {
+		t.Error("RegisterABI should reject invalid ABI JSON")
+	}
+}
@@ -1,10 +1,14 @@
 package builder
 
 import (
+	"fmt"
 	"math/big"
 
 	"github.com/davidt58/go-builder-relayer-client/config"
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/crypto"
 )
 
@@ -23,12 +27,27 @@ func DeriveSafeAddress(signerAddress common.Address, chainID int64) (common.Addr
 		return common.Address{}, err
 	}
 
+	return DeriveSafeAddressWithConfig(signerAddress, contractConfig)
+}
+
+// DeriveSafeAddressWithConfig calculates the Safe address using the factory in
+// contractConfig instead of looking it up in the global registry
+func DeriveSafeAddressWithConfig(signerAddress common.Address, contractConfig *config.ContractConfig) (common.Address, error) {
+	if contractConfig == nil {
+		return common.Address{}, errors.ErrMissingRequiredField("contractConfig")
+	}
+	if !common.IsHexAddress(contractConfig.SafeFactory) {
+		return common.Address{}, errors.ErrInvalidAddress(contractConfig.SafeFactory)
+	}
+
 	// Get factory address
 	factoryAddress := common.HexToAddress(contractConfig.SafeFactory)
 
-	// Calculate salt as keccak256(abi.encode(signerAddress))
-	// In Solidity ABI encoding, an address is left-padded to 32 bytes
-	salt := crypto.Keccak256Hash(common.LeftPadBytes(signerAddress.Bytes(), 32))
+	// The salt depends on the factory's configured strategy
+	salt, err := safeSalt(signerAddress, contractConfig)
+	if err != nil {
+		return common.Address{}, err
+	}
 
 	// Get the init code hash
 	initCodeHash := common.HexToHash(SAFE_INIT_CODE_HASH)
@@ -47,14 +66,40 @@ func DeriveSafeAddress(signerAddress common.Address, chainID int64) (common.Addr
 	return safeAddress, nil
 }
 
+// safeSalt computes the CREATE2 salt for signerAddress's Safe using the
+// config's SaltStrategy
+func safeSalt(signerAddress common.Address, contractConfig *config.ContractConfig) (common.Hash, error) {
+	// Initializer hash is keccak256(abi.encode(signerAddress))
+	// In Solidity ABI encoding, an address is left-padded to 32 bytes
+	initializerHash := crypto.Keccak256Hash(common.LeftPadBytes(signerAddress.Bytes(), 32))
+
+	switch contractConfig.SaltStrategy.OrDefault() {
+	case config.SaltInitializerHash:
+		return initializerHash, nil
+	case config.SaltInitializerHashChainID:
+		if contractConfig.ChainID <= 0 {
+			return common.Hash{}, errors.ErrInvalidConfiguration("chain ID must be positive")
+		}
+		chainID := math.U256Bytes(big.NewInt(contractConfig.ChainID))
+		return crypto.Keccak256Hash(initializerHash.Bytes(), chainID), nil
+	case config.SaltInitializerHashNonce:
+		saltNonce := new(big.Int)
+		if contractConfig.SaltNonce != nil {
+			saltNonce.Set(contractConfig.SaltNonce)
+		}
+		if saltNonce.Sign() < 0 || saltNonce.BitLen() > 256 {
+			return common.Hash{}, errors.ErrInvalidConfiguration("salt nonce must fit in a uint256")
+		}
+		return crypto.Keccak256Hash(initializerHash.Bytes(), math.U256Bytes(saltNonce)), nil
+	default:
+		return common.Hash{}, contractConfig.SaltStrategy.Validate()
+	}
+}
+
 // buildSafeInitializer creates the initializer data for Safe.setup()
 // This encodes the call to setup(owners, threshold, to, data, fallbackHandler, paymentToken, payment, paymentReceiver)
 // This function is still needed for Safe creation transactions (not for address derivation)
 func buildSafeInitializer(signerAddress common.Address, contractConfig *config.ContractConfig) ([]byte, error) {
-	// Safe.setup() function selector: 0xb63e800d
-	setupSelector := crypto.Keccak256([]byte("setup(address[],uint256,address,bytes,address,address,uint256,address)"))[:4]
-
-	// Encode the parameters for Safe.setup()
 	// owners: [signerAddress]
 	// threshold: 1
 	// to: 0x0 (no delegate call during setup)
@@ -63,28 +108,38 @@ func buildSafeInitializer(signerAddress common.Address, contractConfig *config.C
 	// paymentToken: 0x0 (ETH)
 	// payment: 0
 	// paymentReceiver: 0x0
-
-	// Build the ABI-encoded parameters
-	encodedParams, err := encodeSafeSetupParams(
-		[]common.Address{signerAddress}, // owners
-		big.NewInt(1),                   // threshold
-		common.Address{},                // to (zero address)
-		[]byte{},                        // data (empty)
-		common.HexToAddress(contractConfig.SafeFallbackHandler), // fallbackHandler
-		common.Address{}, // paymentToken (zero address for ETH)
-		big.NewInt(0),    // payment
-		common.Address{}, // paymentReceiver (zero address)
-	)
-	if err != nil {
-		return nil, err
+	setup := &SafeSetupConfig{
+		Owners:          []common.Address{signerAddress},
+		Threshold:       big.NewInt(1),
+		Data:            []byte{},
+		FallbackHandler: common.HexToAddress(contractConfig.SafeFallbackHandler),
+		Payment:         big.NewInt(0),
 	}
-
-	// Concatenate selector + encoded params
-	initializerData := append(setupSelector, encodedParams...)
-	return initializerData, nil
+	return setup.Encode()
 }
 
-// encodeSafeSetupParams encodes the parameters for the Safe.setup() function
+// safeSetupArguments are the parameters of Safe.setup(address[] owners,
+// uint256 threshold, address to, bytes data, address fallbackHandler,
+// address paymentToken, uint256 payment, address paymentReceiver)
+var safeSetupArguments = func() abi.Arguments {
+	addressType, _ := abi.NewType("address", "", nil)
+	addressArrayType, _ := abi.NewType("address[]", "", nil)
+	uint256Type, _ := abi.NewType("uint256", "", nil)
+	bytesType, _ := abi.NewType("bytes", "", nil)
+
+	return abi.Arguments{
+		{Name: "owners", Type: addressArrayType},
+		{Name: "threshold", Type: uint256Type},
+		{Name: "to", Type: addressType},
+		{Name: "data", Type: bytesType},
+		{Name: "fallbackHandler", Type: addressType},
+		{Name: "paymentToken", Type: addressType},
+		{Name: "payment", Type: uint256Type},
+		{Name: "paymentReceiver", Type: addressType},
+	}
+}()
+
+// encodeSafeSetupParams ABI-encodes the parameters for the Safe.setup() function
 func encodeSafeSetupParams(
 	owners []common.Address,
 	threshold *big.Int,
@@ -95,71 +150,29 @@ func encodeSafeSetupParams(
 	payment *big.Int,
 	paymentReceiver common.Address,
 ) ([]byte, error) {
-	// ABI encoding layout:
-	// - offset to owners array (32 bytes)
-	// - threshold (32 bytes)
-	// - to address (32 bytes, left-padded)
-	// - offset to data bytes (32 bytes)
-	// - fallbackHandler (32 bytes, left-padded)
-	// - paymentToken (32 bytes, left-padded)
-	// - payment (32 bytes)
-	// - paymentReceiver (32 bytes, left-padded)
-	// - owners array length (32 bytes)
-	// - owners array elements (32 bytes each, left-padded)
-	// - data length (32 bytes)
-	// - data bytes (padded to 32 byte boundary)
-
-	var encoded []byte
-
-	// Calculate offsets
-	// Offset to owners array: 8 * 32 = 256 bytes (after the 8 fixed parameters)
-	ownersOffset := big.NewInt(256)
-	encoded = append(encoded, common.LeftPadBytes(ownersOffset.Bytes(), 32)...)
-
-	// threshold
-	encoded = append(encoded, common.LeftPadBytes(threshold.Bytes(), 32)...)
-
-	// to address
-	encoded = append(encoded, common.LeftPadBytes(to.Bytes(), 32)...)
-
-	// Calculate offset to data
-	// data offset = owners offset + 32 (length) + len(owners) * 32
-	dataOffset := big.NewInt(int64(256 + 32 + len(owners)*32))
-	encoded = append(encoded, common.LeftPadBytes(dataOffset.Bytes(), 32)...)
-
-	// fallbackHandler
-	encoded = append(encoded, common.LeftPadBytes(fallbackHandler.Bytes(), 32)...)
-
-	// paymentToken
-	encoded = append(encoded, common.LeftPadBytes(paymentToken.Bytes(), 32)...)
-
-	// payment
-	encoded = append(encoded, common.LeftPadBytes(payment.Bytes(), 32)...)
-
-	// paymentReceiver
-	encoded = append(encoded, common.LeftPadBytes(paymentReceiver.Bytes(), 32)...)
-
-	// Encode owners array
-	// Array length
-	encoded = append(encoded, common.LeftPadBytes(big.NewInt(int64(len(owners))).Bytes(), 32)...)
-	// Array elements
-	for _, owner := range owners {
-		encoded = append(encoded, common.LeftPadBytes(owner.Bytes(), 32)...)
-	}
-
-	// Encode data bytes
-	// Bytes length
-	encoded = append(encoded, common.LeftPadBytes(big.NewInt(int64(len(data))).Bytes(), 32)...)
-	// Bytes data (padded to 32-byte boundary)
-	if len(data) > 0 {
-		encoded = append(encoded, data...)
-		// Pad to 32-byte boundary
-		padding := (32 - (len(data) % 32)) % 32
-		if padding > 0 {
-			encoded = append(encoded, make([]byte, padding)...)
-		}
+	if len(owners) == 0 {
+		return nil, errors.ErrInvalidSafeSetup("at least one owner is required")
+	}
+	if threshold == nil || threshold.Sign() <= 0 {
+		return nil, errors.ErrInvalidSafeSetup("threshold must be at least 1")
+	}
+	if threshold.Cmp(big.NewInt(int64(len(owners)))) > 0 {
+		return nil, errors.ErrInvalidSafeSetup(fmt.Sprintf("threshold %s exceeds owner count %d", threshold, len(owners)))
+	}
+	if payment == nil {
+		payment = big.NewInt(0)
+	}
+	if payment.Sign() < 0 {
+		return nil, errors.ErrInvalidSafeSetup("payment must not be negative")
+	}
+	if data == nil {
+		data = []byte{}
 	}
 
+	encoded, err := safeSetupArguments.Pack(owners, threshold, to, data, fallbackHandler, paymentToken, payment, paymentReceiver)
+	if err != nil {
+		return nil, errors.ErrInvalidSafeSetup(err.Error())
+	}
 	return encoded, nil
 }
 
@@ -191,7 +204,7 @@ func GetSafeDeploymentData(signerAddress common.Address, chainID int64) (map[str
 		return nil, err
 	}
 
-	safeAddress, err := DeriveSafeAddress(signerAddress, chainID)
+	safeAddress, err := DeriveSafeAddressWithConfig(signerAddress, contractConfig)
 	if err != nil {
 		return nil, err
 	}
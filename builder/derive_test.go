@@ -1,11 +1,16 @@
 package builder
 
 import (
+	"bytes"
+	"encoding/hex"
 	"math/big"
+	"strings"
 	"testing"
 
 	"github.com/davidt58/go-builder-relayer-client/config"
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 )
 
 const (
@@ -60,22 +65,97 @@ func TestDeriveSafeAddress_DifferentSigners(t *testing.T) {
 func TestDeriveSafeAddress_DifferentChains(t *testing.T) {
 	signerAddr := common.HexToAddress(testSignerAddress)
 
-	// Polygon Amoy
-	safeAmoy, err := DeriveSafeAddress(signerAddr, 80002)
-	if err != nil {
-		t.Fatalf("DeriveSafeAddress failed for Amoy: %v", err)
+	derive := func(chainID int64, strategy config.SaltStrategy) common.Address {
+		t.Helper()
+		contractConfig, err := config.GetContractConfig(chainID)
+		if err != nil {
+			t.Fatalf("GetContractConfig(%d) failed: %v", chainID, err)
+		}
+		withStrategy := *contractConfig
+		withStrategy.SaltStrategy = strategy
+		address, err := DeriveSafeAddressWithConfig(signerAddr, &withStrategy)
+		if err != nil {
+			t.Fatalf("DeriveSafeAddressWithConfig(%d, %q) failed: %v", chainID, strategy, err)
+		}
+		return address
+	}
+
+	// Polygon mainnet and Amoy share a factory and the default strategy has
+	// nothing chain-specific in the salt, so Polymarket addresses match
+	if amoy, mainnet := derive(80002, ""), derive(137, ""); amoy != mainnet {
+		t.Errorf("default strategy: Amoy=%s, Mainnet=%s, want the same address", amoy.Hex(), mainnet.Hex())
+	}
+
+	// Factories that mix the chain ID into the salt give per-chain addresses
+	if amoy, mainnet := derive(80002, config.SaltInitializerHashChainID), derive(137, config.SaltInitializerHashChainID); amoy == mainnet {
+		t.Errorf("chain ID strategy: both chains derived %s, want different addresses", amoy.Hex())
+	}
+}
+
+func TestDeriveSafeAddress_SaltStrategies(t *testing.T) {
+	signerAddr := common.HexToAddress(testSignerAddress)
+
+	tests := []struct {
+		name      string
+		chainID   int64
+		strategy  config.SaltStrategy
+		saltNonce *big.Int
+		want      string
+		shouldErr bool
+	}{
+		{name: "default", chainID: 137, want: "0xd93B25cb943D14d0d34FBaF01Fc93a0f8b5F6E47"},
+		{name: "initializer hash", chainID: 80002, strategy: config.SaltInitializerHash, want: "0xd93B25cb943D14d0d34FBaF01Fc93a0f8b5F6E47"},
+		{name: "salt nonce ignored by initializer hash", chainID: 137, strategy: config.SaltInitializerHash, saltNonce: big.NewInt(1), want: "0xd93B25cb943D14d0d34FBaF01Fc93a0f8b5F6E47"},
+		{name: "chain ID on mainnet", chainID: 137, strategy: config.SaltInitializerHashChainID, want: "0x14A28F7c264eeedDE864AE8927709E6cDaa41552"},
+		{name: "chain ID on Amoy", chainID: 80002, strategy: config.SaltInitializerHashChainID, want: "0x4F1ead30C56A13043Bf1D77f5C1F20e5609B149F"},
+		{name: "unset salt nonce", chainID: 137, strategy: config.SaltInitializerHashNonce, want: "0x6D37b93fB78b346911CFc07c9337aE035C032067"},
+		{name: "salt nonce 0", chainID: 137, strategy: config.SaltInitializerHashNonce, saltNonce: big.NewInt(0), want: "0x6D37b93fB78b346911CFc07c9337aE035C032067"},
+		{name: "salt nonce 1", chainID: 80002, strategy: config.SaltInitializerHashNonce, saltNonce: big.NewInt(1), want: "0xF24E2B4cB4D1f4Ba09Cd38a568a55F2481d6A533"},
+		{name: "negative salt nonce", chainID: 137, strategy: config.SaltInitializerHashNonce, saltNonce: big.NewInt(-1), shouldErr: true},
+		{name: "salt nonce overflow", chainID: 137, strategy: config.SaltInitializerHashNonce, saltNonce: new(big.Int).Lsh(big.NewInt(1), 256), shouldErr: true},
+		{name: "unknown strategy", chainID: 137, strategy: "owner_only", shouldErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			contractConfig, err := config.GetContractConfig(tt.chainID)
+			if err != nil {
+				t.Fatalf("GetContractConfig failed: %v", err)
+			}
+			withStrategy := *contractConfig
+			withStrategy.SaltStrategy = tt.strategy
+			withStrategy.SaltNonce = tt.saltNonce
+
+			got, err := DeriveSafeAddressWithConfig(signerAddr, &withStrategy)
+			if (err != nil) != tt.shouldErr {
+				t.Fatalf("DeriveSafeAddressWithConfig() error = %v, shouldErr %v", err, tt.shouldErr)
+			}
+			if tt.shouldErr {
+				return
+			}
+			if got != common.HexToAddress(tt.want) {
+				t.Errorf("DeriveSafeAddressWithConfig() = %s, want %s", got.Hex(), tt.want)
+			}
+		})
 	}
+}
+
+func TestDeriveSafeAddress_SaltStrategyEncoding(t *testing.T) {
+	signerAddr := common.HexToAddress(testSignerAddress)
+	custom := customContractConfig()
+	custom.SaltStrategy = config.SaltInitializerHashChainID
 
-	// Polygon Mainnet
-	safeMainnet, err := DeriveSafeAddress(signerAddr, 137)
+	derived, err := DeriveSafeAddressWithConfig(signerAddr, custom)
 	if err != nil {
-		t.Fatalf("DeriveSafeAddress failed for Mainnet: %v", err)
+		t.Fatalf("DeriveSafeAddressWithConfig failed: %v", err)
 	}
 
-	// Addresses should be the same if contract addresses are the same
-	// (which they are in our config)
-	if safeAmoy != safeMainnet {
-		t.Logf("Note: Safe addresses differ between chains: Amoy=%s, Mainnet=%s", safeAmoy.Hex(), safeMainnet.Hex())
+	// keccak256(initializerHash ++ uint256(chainId))
+	initializerHash := crypto.Keccak256(common.LeftPadBytes(signerAddr.Bytes(), 32))
+	salt := crypto.Keccak256(initializerHash, common.LeftPadBytes(big.NewInt(custom.ChainID).Bytes(), 32))
+	expected := crypto.CreateAddress2(common.HexToAddress(custom.SafeFactory), common.BytesToHash(salt), common.HexToHash(SAFE_INIT_CODE_HASH).Bytes())
+	if derived != expected {
+		t.Errorf("DeriveSafeAddressWithConfig() = %s, want %s", derived.Hex(), expected.Hex())
 	}
 }
 
@@ -160,6 +240,176 @@ func TestEncodeSafeSetupParams(t *testing.T) {
 	}
 }
 
+// safeSetupABI is the Safe.setup() ABI used as an independent reference encoder
+const safeSetupABI = `[{"type":"function","name":"setup","inputs":[
+	{"name":"_owners","type":"address[]"},
+	{"name":"_threshold","type":"uint256"},
+	{"name":"to","type":"address"},
+	{"name":"data","type":"bytes"},
+	{"name":"fallbackHandler","type":"address"},
+	{"name":"paymentToken","type":"address"},
+	{"name":"payment","type":"uint256"},
+	{"name":"paymentReceiver","type":"address"}]}]`
+
+// legacySingleOwnerSetup is the output of the original hand-rolled encoder
+// for one owner 0x1111..., threshold 1, empty data and fallback handler 0x2222...
+const legacySingleOwnerSetup = "00000000000000000000000000000000000000000000000000000000000001000000000000000000000000000000000000000000000000000000000000000001000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000001400000000000000000000000002222222222222222222222222222222222222222000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000100000000000000000000000011111111111111111111111111111111111111110000000000000000000000000000000000000000000000000000000000000000"
+
+func TestEncodeSafeSetupParams_MatchesLegacyEncoding(t *testing.T) {
+	encoded, err := encodeSafeSetupParams(
+		[]common.Address{common.HexToAddress("0x1111111111111111111111111111111111111111")},
+		big.NewInt(1),
+		common.Address{},
+		[]byte{},
+		common.HexToAddress("0x2222222222222222222222222222222222222222"),
+		common.Address{},
+		big.NewInt(0),
+		common.Address{},
+	)
+	if err != nil {
+		t.Fatalf("encodeSafeSetupParams failed: %v", err)
+	}
+
+	if got := hex.EncodeToString(encoded); got != legacySingleOwnerSetup {
+		t.Errorf("Encoding changed:\ngot  %s\nwant %s", got, legacySingleOwnerSetup)
+	}
+}
+
+func TestEncodeSafeSetupParams_MatchesABIPack(t *testing.T) {
+	parsed, err := abi.JSON(strings.NewReader(safeSetupABI))
+	if err != nil {
+		t.Fatalf("failed to parse ABI: %v", err)
+	}
+
+	owners := []common.Address{
+		common.HexToAddress("0x1111111111111111111111111111111111111111"),
+		common.HexToAddress("0x3333333333333333333333333333333333333333"),
+		common.HexToAddress("0x4444444444444444444444444444444444444444"),
+	}
+	fallbackHandler := common.HexToAddress("0x2222222222222222222222222222222222222222")
+
+	tests := []struct {
+		name      string
+		owners    []common.Address
+		threshold int64
+		data      []byte
+		payment   int64
+	}{
+		{
+			name:      "multiple owners",
+			owners:    owners,
+			threshold: 2,
+			data:      []byte{},
+		},
+		{
+			name:      "non-empty data",
+			owners:    owners[:1],
+			threshold: 1,
+			data:      []byte{0xde, 0xad, 0xbe, 0xef},
+		},
+		{
+			name:      "multiple owners with data spanning words",
+			owners:    owners,
+			threshold: 3,
+			data:      bytes.Repeat([]byte{0xab}, 33),
+			payment:   1000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded, err := encodeSafeSetupParams(tt.owners, big.NewInt(tt.threshold), common.Address{}, tt.data,
+				fallbackHandler, common.Address{}, big.NewInt(tt.payment), common.Address{})
+			if err != nil {
+				t.Fatalf("encodeSafeSetupParams failed: %v", err)
+			}
+
+			packed, err := parsed.Pack("setup", tt.owners, big.NewInt(tt.threshold), common.Address{}, tt.data,
+				fallbackHandler, common.Address{}, big.NewInt(tt.payment), common.Address{})
+			if err != nil {
+				t.Fatalf("abi.Pack failed: %v", err)
+			}
+
+			if !bytes.Equal(encoded, packed[4:]) {
+				t.Errorf("Encoding mismatch:\ngot  %x\nwant %x", encoded, packed[4:])
+			}
+
+			// The data offset must account for every owner
+			dataOffset := new(big.Int).SetBytes(encoded[3*32 : 4*32]).Int64()
+			if want := int64(8*32 + 32 + len(tt.owners)*32); dataOffset != want {
+				t.Errorf("data offset = %d, want %d", dataOffset, want)
+			}
+		})
+	}
+}
+
+func TestEncodeSafeSetupParams_Validation(t *testing.T) {
+	owner := common.HexToAddress("0x1111111111111111111111111111111111111111")
+
+	tests := []struct {
+		name      string
+		owners    []common.Address
+		threshold *big.Int
+		payment   *big.Int
+		shouldErr bool
+	}{
+		{
+			name:      "valid",
+			owners:    []common.Address{owner},
+			threshold: big.NewInt(1),
+			payment:   big.NewInt(0),
+		},
+		{
+			name:      "nil payment defaults to zero",
+			owners:    []common.Address{owner},
+			threshold: big.NewInt(1),
+		},
+		{
+			name:      "no owners",
+			threshold: big.NewInt(1),
+			payment:   big.NewInt(0),
+			shouldErr: true,
+		},
+		{
+			name:      "nil threshold",
+			owners:    []common.Address{owner},
+			payment:   big.NewInt(0),
+			shouldErr: true,
+		},
+		{
+			name:      "zero threshold",
+			owners:    []common.Address{owner},
+			threshold: big.NewInt(0),
+			payment:   big.NewInt(0),
+			shouldErr: true,
+		},
+		{
+			name:      "threshold exceeds owners",
+			owners:    []common.Address{owner},
+			threshold: big.NewInt(2),
+			payment:   big.NewInt(0),
+			shouldErr: true,
+		},
+		{
+			name:      "negative payment",
+			owners:    []common.Address{owner},
+			threshold: big.NewInt(1),
+			payment:   big.NewInt(-1),
+			shouldErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := encodeSafeSetupParams(tt.owners, tt.threshold, common.Address{}, nil,
+				common.Address{}, common.Address{}, tt.payment, common.Address{})
+			if (err != nil) != tt.shouldErr {
+				t.Errorf("encodeSafeSetupParams() error = %v, shouldErr %v", err, tt.shouldErr)
+			}
+		})
+	}
+}
+
 func TestVerifySafeAddress(t *testing.T) {
 	signerAddr := common.HexToAddress(testSignerAddress)
 
@@ -278,3 +528,61 @@ func TestDeriveSafeAddress_KnownAddress(t *testing.T) {
 func getTestContractConfig() (*config.ContractConfig, error) {
 	return config.GetContractConfig(testChainID)
 }
+
+// customContractConfig is an ad-hoc contract set that is not in the global registry
+func customContractConfig() *config.ContractConfig {
+	return &config.ContractConfig{
+		ChainID:             999999,
+		SafeFactory:         "0x1234567890123456789012345678901234567890",
+		SafeSingleton:       "0x3E5c63644E683549055b9Be8653de26E0B4CD36E",
+		SafeFallbackHandler: "0xf48f2B2d2a534e402487b3ee7C18c33Aec0Fe5e4",
+		SafeMultisend:       "0xA238CBeb142c10Ef7Ad8442C6D1f9E89e07e7761",
+	}
+}
+
+func TestDeriveSafeAddressWithConfig(t *testing.T) {
+	signerAddr := common.HexToAddress(testSignerAddress)
+	custom := customContractConfig()
+
+	derived, err := DeriveSafeAddressWithConfig(signerAddr, custom)
+	if err != nil {
+		t.Fatalf("DeriveSafeAddressWithConfig failed: %v", err)
+	}
+
+	// CREATE2 with the custom factory
+	salt := crypto.Keccak256(common.LeftPadBytes(signerAddr.Bytes(), 32))
+	expected := crypto.CreateAddress2(common.HexToAddress(custom.SafeFactory), common.BytesToHash(salt), common.HexToHash(SAFE_INIT_CODE_HASH).Bytes())
+	if derived != expected {
+		t.Errorf("DeriveSafeAddressWithConfig() = %s, want %s", derived.Hex(), expected.Hex())
+	}
+
+	registered, err := DeriveSafeAddress(signerAddr, testChainID)
+	if err != nil {
+		t.Fatalf("DeriveSafeAddress failed: %v", err)
+	}
+	if derived == registered {
+		t.Error("Custom factory should derive a different address than the registered one")
+	}
+
+	// The wrapper matches the explicit variant for registered chains
+	registeredConfig, err := config.GetContractConfig(testChainID)
+	if err != nil {
+		t.Fatalf("GetContractConfig failed: %v", err)
+	}
+	explicit, err := DeriveSafeAddressWithConfig(signerAddr, registeredConfig)
+	if err != nil {
+		t.Fatalf("DeriveSafeAddressWithConfig failed: %v", err)
+	}
+	if explicit != registered {
+		t.Errorf("DeriveSafeAddressWithConfig() = %s, want %s", explicit.Hex(), registered.Hex())
+	}
+
+	// The global registry was not touched
+	if _, err := config.GetContractConfig(custom.ChainID); err == nil {
+		t.Error("Custom chain should not be registered")
+	}
+
+	if _, err := DeriveSafeAddressWithConfig(signerAddr, nil); err == nil {
+		t.Error("Expected error for nil config")
+	}
+}
@@ -0,0 +1,254 @@
+package builder
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+// Kinds of FieldDiff
+const (
+	// DiffChanged means the field exists in both requests with different values
+	DiffChanged = "changed"
+	// DiffAdded means the field only exists in the second request
+	DiffAdded = "added"
+	// DiffRemoved means the field only exists in the first request
+	DiffRemoved = "removed"
+)
+
+// FieldDiff is a single difference between two transaction requests
+type FieldDiff struct {
+	// Path is the RFC 6901 JSON pointer of the field (e.g. "/to/1")
+	Path string `json:"path"`
+	// Kind is DiffChanged, DiffAdded or DiffRemoved
+	Kind string `json:"kind"`
+	// A is the canonical value in the first request (nil if added)
+	A interface{} `json:"a,omitempty"`
+	// B is the canonical value in the second request (nil if removed)
+	B interface{} `json:"b,omitempty"`
+}
+
+// RequestDiff lists the differences between two transaction requests
+type RequestDiff struct {
+	// Differences are ordered by path
+	Differences []FieldDiff `json:"differences"`
+}
+
+// Equal returns true if the requests have no differences
+func (d *RequestDiff) Equal() bool {
+	return len(d.Differences) == 0
+}
+
+// String renders the differences as a Markdown table for review comments
+func (d *RequestDiff) String() string {
+	if d.Equal() {
+		return "No differences"
+	}
+
+	var sb strings.Builder
+	sb.WriteString("| Path | Change | Before | After |\n")
+	sb.WriteString("| --- | --- | --- | --- |\n")
+	for _, diff := range d.Differences {
+		fmt.Fprintf(&sb, "| `%s` | %s | %s | %s |\n", diff.Path, diff.Kind, renderDiffValue(diff.A), renderDiffValue(diff.B))
+	}
+	return sb.String()
+}
+
+// renderDiffValue formats a value for a Markdown table cell
+func renderDiffValue(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("`%v`", v)
+	}
+	return "`" + string(data) + "`"
+}
+
+// DiffTransactionRequests compares two requests field by field after
+// canonicalization, so hex case and JSON whitespace never show up as
+// differences. Batched to/value/data/operation fields are compared element-wise.
+func DiffTransactionRequests(a, b *models.TransactionRequest) (*RequestDiff, error) {
+	if a == nil || b == nil {
+		return nil, errors.ErrMissingRequiredField("request")
+	}
+
+	left, err := canonicalValue(a)
+	if err != nil {
+		return nil, err
+	}
+	right, err := canonicalValue(b)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &RequestDiff{Differences: []FieldDiff{}}
+	diffValues("", left, right, diff)
+	return diff, nil
+}
+
+// DiffEncodedTransactionRequests decodes two submission payloads of either
+// schema version and compares them in the v1 shape, so a v1 and a v2 payload
+// for the same logical transaction have no differences
+func DiffEncodedTransactionRequests(a, b []byte) (*RequestDiff, error) {
+	left, _, err := models.DecodeTransactionRequest(a)
+	if err != nil {
+		return nil, err
+	}
+	right, _, err := models.DecodeTransactionRequest(b)
+	if err != nil {
+		return nil, err
+	}
+	return DiffTransactionRequests(left, right)
+}
+
+// CanonicalizeTransactionRequest returns a copy of request with hex strings
+// lowercased and the raw JSON fields compacted
+func CanonicalizeTransactionRequest(request *models.TransactionRequest) (*models.TransactionRequest, error) {
+	if request == nil {
+		return nil, errors.ErrMissingRequiredField("request")
+	}
+
+	value, err := canonicalValue(request)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, errors.ErrJSONMarshalFailed(err)
+	}
+
+	var canonical models.TransactionRequest
+	if err := json.Unmarshal(data, &canonical); err != nil {
+		return nil, errors.ErrJSONUnmarshalFailed(err)
+	}
+	return &canonical, nil
+}
+
+// canonicalValue converts a request into generic JSON values with hex strings lowercased
+func canonicalValue(request *models.TransactionRequest) (interface{}, error) {
+	data, err := json.Marshal(request)
+	if err != nil {
+		return nil, errors.ErrJSONMarshalFailed(err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, errors.ErrJSONUnmarshalFailed(err)
+	}
+	return normalizeHex(value), nil
+}
+
+// normalizeHex lowercases every 0x-prefixed hex string in a JSON value
+func normalizeHex(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		if isHexString(v) {
+			return strings.ToLower(v)
+		}
+		return v
+	case []interface{}:
+		for i := range v {
+			v[i] = normalizeHex(v[i])
+		}
+		return v
+	case map[string]interface{}:
+		for key := range v {
+			v[key] = normalizeHex(v[key])
+		}
+		return v
+	}
+	return value
+}
+
+// isHexString reports whether s is a 0x-prefixed hex string
+func isHexString(s string) bool {
+	if len(s) < 2 || s[0] != '0' || (s[1] != 'x' && s[1] != 'X') {
+		return false
+	}
+	for _, r := range s[2:] {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", r) {
+			return false
+		}
+	}
+	return true
+}
+
+// diffValues appends the differences between a and b at path to diff
+func diffValues(path string, a, b interface{}, diff *RequestDiff) {
+	switch left := a.(type) {
+	case map[string]interface{}:
+		if right, ok := b.(map[string]interface{}); ok {
+			diffObjects(path, left, right, diff)
+			return
+		}
+	case []interface{}:
+		if right, ok := b.([]interface{}); ok {
+			diffArrays(path, left, right, diff)
+			return
+		}
+	}
+
+	if !reflect.DeepEqual(a, b) {
+		diff.Differences = append(diff.Differences, FieldDiff{Path: path, Kind: DiffChanged, A: a, B: b})
+	}
+}
+
+// diffObjects compares two JSON objects key by key in sorted order
+func diffObjects(path string, a, b map[string]interface{}, diff *RequestDiff) {
+	keys := make(map[string]bool)
+	for key := range a {
+		keys[key] = true
+	}
+	for key := range b {
+		keys[key] = true
+	}
+	sorted := make([]string, 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+
+	for _, key := range sorted {
+		childPath := path + "/" + escapePointer(key)
+		left, inA := a[key]
+		right, inB := b[key]
+		switch {
+		case !inB:
+			diff.Differences = append(diff.Differences, FieldDiff{Path: childPath, Kind: DiffRemoved, A: left})
+		case !inA:
+			diff.Differences = append(diff.Differences, FieldDiff{Path: childPath, Kind: DiffAdded, B: right})
+		default:
+			diffValues(childPath, left, right, diff)
+		}
+	}
+}
+
+// diffArrays compares two JSON arrays element-wise
+func diffArrays(path string, a, b []interface{}, diff *RequestDiff) {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		childPath := fmt.Sprintf("%s/%d", path, i)
+		switch {
+		case i >= len(b):
+			diff.Differences = append(diff.Differences, FieldDiff{Path: childPath, Kind: DiffRemoved, A: a[i]})
+		case i >= len(a):
+			diff.Differences = append(diff.Differences, FieldDiff{Path: childPath, Kind: DiffAdded, B: b[i]})
+		default:
+			diffValues(childPath, a[i], b[i], diff)
+		}
+	}
+}
+
+// escapePointer escapes a key for use as a JSON pointer reference token
+func escapePointer(key string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
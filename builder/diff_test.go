@@ -0,0 +1,140 @@
+package builder
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+// diffTestRequest returns a batched SAFE request for the diff tests
+func diffTestRequest(nonce string, tos ...string) *models.TransactionRequest {
+	values := make([]string, len(tos))
+	datas := make([]string, len(tos))
+	for i := range tos {
+		values[i] = "0"
+		datas[i] = "0xABCDEF"
+	}
+	to, _ := json.Marshal(tos)
+	value, _ := json.Marshal(values)
+	data, _ := json.Marshal(datas)
+
+	return &models.TransactionRequest{
+		Type:        string(models.SAFE),
+		From:        "0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266",
+		To:          to,
+		ProxyWallet: "0xd93B25cb943D14d0d34FBaF01Fc93a0f8b5F6E47",
+		Value:       value,
+		Data:        data,
+		Signature:   "0xAAbb",
+		Nonce:       &nonce,
+	}
+}
+
+func TestDiffTransactionRequests(t *testing.T) {
+	usdc := "0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174"
+	exchange := "0x4bFb41d5B3570DeFd03C39a9A4D8dE6Bd8B8982E"
+	adapter := "0xd91E80cF2E7be2e162c6513ceD06f1dD0dA35296"
+
+	lowercased := diffTestRequest("1", strings.ToLower(usdc), strings.ToLower(exchange))
+	lowercased.From = strings.ToLower(lowercased.From)
+	lowercased.Signature = "0xaabb"
+	lowercased.To = json.RawMessage(" [ \"" + strings.ToLower(usdc) + "\",\n \"" + strings.ToLower(exchange) + "\" ] ")
+
+	tests := []struct {
+		name  string
+		a, b  *models.TransactionRequest
+		paths []string
+		kinds []string
+	}{
+		{
+			name: "identical",
+			a:    diffTestRequest("1", usdc, exchange),
+			b:    diffTestRequest("1", usdc, exchange),
+		},
+		{
+			name: "hex case and whitespace only",
+			a:    diffTestRequest("1", usdc, exchange),
+			b:    lowercased,
+		},
+		{
+			name:  "nonce only",
+			a:     diffTestRequest("1", usdc, exchange),
+			b:     diffTestRequest("2", usdc, exchange),
+			paths: []string{"/nonce"},
+			kinds: []string{DiffChanged},
+		},
+		{
+			name:  "array length",
+			a:     diffTestRequest("1", usdc, exchange),
+			b:     diffTestRequest("1", usdc, exchange, adapter),
+			paths: []string{"/data/2", "/to/2", "/value/2"},
+			kinds: []string{DiffAdded, DiffAdded, DiffAdded},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff, err := DiffTransactionRequests(tt.a, tt.b)
+			if err != nil {
+				t.Fatalf("DiffTransactionRequests failed: %v", err)
+			}
+
+			if len(diff.Differences) != len(tt.paths) {
+				t.Fatalf("got %d differences, want %d:\n%s", len(diff.Differences), len(tt.paths), diff)
+			}
+			for i, d := range diff.Differences {
+				if d.Path != tt.paths[i] || d.Kind != tt.kinds[i] {
+					t.Errorf("difference %d = %s %s, want %s %s", i, d.Kind, d.Path, tt.kinds[i], tt.paths[i])
+				}
+			}
+			if diff.Equal() != (len(tt.paths) == 0) {
+				t.Errorf("Equal() = %v", diff.Equal())
+			}
+		})
+	}
+}
+
+func TestRequestDiff_String(t *testing.T) {
+	diff, err := DiffTransactionRequests(diffTestRequest("1", "0x01"), diffTestRequest("2", "0x01"))
+	if err != nil {
+		t.Fatalf("DiffTransactionRequests failed: %v", err)
+	}
+
+	rendered := diff.String()
+	if !strings.Contains(rendered, "| `/nonce` | changed | `\"1\"` | `\"2\"` |") {
+		t.Errorf("unexpected rendering:\n%s", rendered)
+	}
+
+	data, err := json.Marshal(diff)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if !strings.Contains(string(data), `"path":"/nonce"`) {
+		t.Errorf("JSON rendering missing path: %s", data)
+	}
+}
+
+func TestCanonicalizeTransactionRequest(t *testing.T) {
+	request := diffTestRequest("1", "0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174")
+	request.To = json.RawMessage(" [ \"0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174\" ]\n")
+
+	canonical, err := CanonicalizeTransactionRequest(request)
+	if err != nil {
+		t.Fatalf("CanonicalizeTransactionRequest failed: %v", err)
+	}
+
+	if string(canonical.To) != `["0x2791bca1f2de4661ed88a30c99a7a9449aa84174"]` {
+		t.Errorf("To = %s", canonical.To)
+	}
+	if string(canonical.Data) != `["0xabcdef"]` {
+		t.Errorf("Data = %s", canonical.Data)
+	}
+	if canonical.From != strings.ToLower(request.From) || canonical.Signature != "0xaabb" {
+		t.Errorf("From/Signature not lowercased: %s %s", canonical.From, canonical.Signature)
+	}
+	if *canonical.Nonce != "1" {
+		t.Errorf("Nonce = %s, want 1", *canonical.Nonce)
+	}
+}
@@ -1,9 +1,11 @@
 package builder
 
 import (
+	"fmt"
 	"math/big"
 
 	"github.com/davidt58/go-builder-relayer-client/constants"
+	"github.com/davidt58/go-builder-relayer-client/errors"
 	"github.com/davidt58/go-builder-relayer-client/signer"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
@@ -37,8 +39,34 @@ type CreateProxy struct {
 // BuildSafeTxHash builds the EIP-712 hash for a Safe transaction
 // This follows the EIP-712 standard for typed data hashing
 func BuildSafeTxHash(safeTx *SafeTx, verifyingContract common.Address, chainID int64) (common.Hash, error) {
-	// Build the EIP-712 typed data
-	typedData := &signer.TypedData{
+	typedData, err := safeTxTypedData(safeTx, verifyingContract, chainID)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return signer.HashTypedData(typedData)
+}
+
+// SafeTxDigestParts returns the domain separator and SafeTx struct hash along
+// with the final digest BuildSafeTxHash produces, so that external verifiers
+// can recompute keccak256(0x1901 ‖ domainSeparator ‖ structHash)
+func SafeTxDigestParts(safeTx *SafeTx, verifyingContract common.Address, chainID int64) (domainSeparator, structHash, finalDigest common.Hash, err error) {
+	typedData, err := safeTxTypedData(safeTx, verifyingContract, chainID)
+	if err != nil {
+		return common.Hash{}, common.Hash{}, common.Hash{}, err
+	}
+	return digestParts("SafeTx", typedData)
+}
+
+// safeTxTypedData validates a Safe transaction and builds its EIP-712 typed data
+func safeTxTypedData(safeTx *SafeTx, verifyingContract common.Address, chainID int64) (*signer.TypedData, error) {
+	if err := safeTx.validate(); err != nil {
+		return nil, err
+	}
+	if err := checkVerifyingContract(verifyingContract); err != nil {
+		return nil, err
+	}
+
+	return &signer.TypedData{
 		Types: map[string][]signer.EIP712Type{
 			"EIP712Domain": {
 				{Name: "chainId", Type: "uint256"},
@@ -74,17 +102,43 @@ func BuildSafeTxHash(safeTx *SafeTx, verifyingContract common.Address, chainID i
 			"refundReceiver": safeTx.RefundReceiver.Hex(),
 			"nonce":          safeTx.Nonce.String(),
 		},
-	}
-
-	// Hash the typed data
-	return signer.HashTypedData(typedData)
+	}, nil
 }
 
 // BuildCreateProxyHash builds the EIP-712 hash for Safe proxy creation
 // This is used when deploying a new Safe wallet (matching Python implementation)
 func BuildCreateProxyHash(createProxy *CreateProxy, verifyingContract common.Address, chainID int64) (common.Hash, error) {
-	// Build the EIP-712 typed data
-	typedData := &signer.TypedData{
+	typedData, err := createProxyTypedData(createProxy, verifyingContract, chainID)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return signer.HashTypedData(typedData)
+}
+
+// CreateProxyDigestParts returns the domain separator and CreateProxy struct
+// hash along with the final digest BuildCreateProxyHash produces
+func CreateProxyDigestParts(createProxy *CreateProxy, verifyingContract common.Address, chainID int64) (domainSeparator, structHash, finalDigest common.Hash, err error) {
+	typedData, err := createProxyTypedData(createProxy, verifyingContract, chainID)
+	if err != nil {
+		return common.Hash{}, common.Hash{}, common.Hash{}, err
+	}
+	return digestParts("CreateProxy", typedData)
+}
+
+// createProxyTypedData validates a Safe proxy creation and builds its
+// EIP-712 typed data
+func createProxyTypedData(createProxy *CreateProxy, verifyingContract common.Address, chainID int64) (*signer.TypedData, error) {
+	if createProxy == nil {
+		return nil, errors.ErrMissingRequiredField("createProxy")
+	}
+	if err := checkUint256Fields("CreateProxy", []uint256Field{{"Payment", createProxy.Payment}}); err != nil {
+		return nil, err
+	}
+	if err := checkVerifyingContract(verifyingContract); err != nil {
+		return nil, err
+	}
+
+	return &signer.TypedData{
 		Types: map[string][]signer.EIP712Type{
 			"EIP712Domain": {
 				{Name: "name", Type: "string"},
@@ -108,10 +162,72 @@ func BuildCreateProxyHash(createProxy *CreateProxy, verifyingContract common.Add
 			"payment":         createProxy.Payment.String(),
 			"paymentReceiver": createProxy.PaymentReceiver.Hex(),
 		},
+	}, nil
+}
+
+// validate checks that every uint256 field of the SafeTx is set and in
+// range and that the operation is Call or DelegateCall, so a partially
+// built SafeTx fails with the field's name instead of panicking in the
+// encoder. Nil Data is empty calldata.
+func (t *SafeTx) validate() error {
+	if t == nil {
+		return errors.ErrMissingRequiredField("safeTx")
+	}
+	if t.Operation > 1 {
+		return errors.NewRelayerClientError(fmt.Sprintf("SafeTx.Operation %d is neither Call (0) nor DelegateCall (1)", t.Operation), nil)
 	}
+	return checkUint256Fields("SafeTx", []uint256Field{
+		{"Value", t.Value},
+		{"SafeTxGas", t.SafeTxGas},
+		{"BaseGas", t.BaseGas},
+		{"GasPrice", t.GasPrice},
+		{"Nonce", t.Nonce},
+	})
+}
 
-	// Hash the typed data
-	return signer.HashTypedData(typedData)
+// uint256Field is a named uint256 field of a typed-data struct
+type uint256Field struct {
+	name  string
+	value *big.Int
+}
+
+// checkUint256Fields returns an error naming the first field of the struct
+// that is nil or out of uint256 range
+func checkUint256Fields(structName string, fields []uint256Field) error {
+	for _, field := range fields {
+		name := structName + "." + field.name
+		if field.value == nil {
+			return errors.ErrMissingRequiredField(name)
+		}
+		if field.value.Sign() < 0 || field.value.BitLen() > 256 {
+			return errors.NewRelayerClientError(fmt.Sprintf("%s %s is out of uint256 range", name, field.value), nil)
+		}
+	}
+	return nil
+}
+
+// checkVerifyingContract rejects the zero address as EIP-712 verifying contract
+func checkVerifyingContract(verifyingContract common.Address) error {
+	if verifyingContract == (common.Address{}) {
+		return errors.NewRelayerClientError("verifyingContract is the zero address", nil)
+	}
+	return nil
+}
+
+// digestParts hashes typed data into its parts and checks that the digest
+// is the EIP-712 combination of the returned domain separator and struct hash
+func digestParts(name string, typedData *signer.TypedData) (domainSeparator, structHash, finalDigest common.Hash, err error) {
+	domainSeparator, structHash, finalDigest, err = signer.HashTypedDataParts(typedData)
+	if err != nil {
+		return common.Hash{}, common.Hash{}, common.Hash{}, err
+	}
+
+	combined := crypto.Keccak256Hash([]byte{0x19, 0x01}, domainSeparator[:], structHash[:])
+	if combined != finalDigest {
+		return common.Hash{}, common.Hash{}, common.Hash{}, errors.NewRelayerClientError(
+			fmt.Sprintf("%s digest %s does not match its parts (%s)", name, finalDigest.Hex(), combined.Hex()), nil)
+	}
+	return domainSeparator, structHash, finalDigest, nil
 }
 
 // ComputeSafeTxHash is a helper function that creates a SafeTx struct and computes its hash
@@ -0,0 +1,251 @@
+package builder
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/davidt58/go-builder-relayer-client/constants"
+)
+
+// word left-pads b to a 32-byte ABI word
+func word(b []byte) []byte {
+	return common.LeftPadBytes(b, 32)
+}
+
+// encodeSafeTxStruct hashes a SafeTx by hand, independent of the typed-data encoder
+func encodeSafeTxStruct(safeTx *SafeTx) common.Hash {
+	typeHash := GetSafeTxTypeHash()
+	return crypto.Keccak256Hash(
+		typeHash[:],
+		word(safeTx.To.Bytes()),
+		word(safeTx.Value.Bytes()),
+		crypto.Keccak256(safeTx.Data),
+		word([]byte{safeTx.Operation}),
+		word(safeTx.SafeTxGas.Bytes()),
+		word(safeTx.BaseGas.Bytes()),
+		word(safeTx.GasPrice.Bytes()),
+		word(safeTx.GasToken.Bytes()),
+		word(safeTx.RefundReceiver.Bytes()),
+		word(safeTx.Nonce.Bytes()),
+	)
+}
+
+func TestSafeTxDigestParts(t *testing.T) {
+	safe := common.HexToAddress("0xd93B25cb943D14d0d34FBaF01Fc93a0f8b5F6E47")
+	transfer := &SafeTx{
+		To:             common.HexToAddress("0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174"),
+		Value:          big.NewInt(0),
+		Data:           common.FromHex("0xa9059cbb000000000000000000000000d93b25cb943d14d0d34fbaf01fc93a0f8b5f6e4700000000000000000000000000000000000000000000000000000000000f4240"),
+		SafeTxGas:      big.NewInt(0),
+		BaseGas:        big.NewInt(0),
+		GasPrice:       big.NewInt(0),
+		GasToken:       common.Address{},
+		RefundReceiver: common.Address{},
+		Nonce:          big.NewInt(7),
+	}
+	delegateCall := *transfer
+	delegateCall.Operation = 1
+	delegateCall.Data = nil
+	withGas := *transfer
+	withGas.SafeTxGas = big.NewInt(120000)
+	withGas.Value = new(big.Int).Lsh(big.NewInt(1), 200)
+	withGas.Nonce = big.NewInt(1 << 40)
+
+	tests := []struct {
+		name    string
+		safeTx  *SafeTx
+		chainID int64
+	}{
+		{name: "transfer", safeTx: transfer, chainID: 137},
+		{name: "delegatecall without data", safeTx: &delegateCall, chainID: 137},
+		{name: "gas and large values", safeTx: &withGas, chainID: 80002},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			domain, structHash, digest, err := SafeTxDigestParts(tt.safeTx, safe, tt.chainID)
+			if err != nil {
+				t.Fatalf("SafeTxDigestParts failed: %v", err)
+			}
+
+			if want := safeDomainSeparator(tt.chainID, safe); domain != want {
+				t.Errorf("domain separator = %s, want %s", domain.Hex(), want.Hex())
+			}
+			if want := encodeSafeTxStruct(tt.safeTx); structHash != want {
+				t.Errorf("struct hash = %s, want %s", structHash.Hex(), want.Hex())
+			}
+			if want := crypto.Keccak256Hash([]byte{0x19, 0x01}, domain[:], structHash[:]); digest != want {
+				t.Errorf("digest = %s, want keccak256(0x1901 ‖ domain ‖ struct) = %s", digest.Hex(), want.Hex())
+			}
+
+			hash, err := BuildSafeTxHash(tt.safeTx, safe, tt.chainID)
+			if err != nil {
+				t.Fatalf("BuildSafeTxHash failed: %v", err)
+			}
+			if digest != hash {
+				t.Errorf("digest = %s, BuildSafeTxHash = %s", digest.Hex(), hash.Hex())
+			}
+			if fast, err := hashSafeTx(tt.safeTx, domain); err != nil || fast != digest {
+				t.Errorf("hashSafeTx = %s (%v), want %s", fast.Hex(), err, digest.Hex())
+			}
+		})
+	}
+}
+
+func TestCreateProxyDigestParts(t *testing.T) {
+	factory := common.HexToAddress("0xaacFeEa03eb1561C4e67d661e40682Bd20E3541b")
+
+	tests := []struct {
+		name        string
+		createProxy *CreateProxy
+		chainID     int64
+	}{
+		{
+			name:        "no payment",
+			createProxy: &CreateProxy{Payment: big.NewInt(0)},
+			chainID:     137,
+		},
+		{
+			name: "with payment",
+			createProxy: &CreateProxy{
+				PaymentToken:    common.HexToAddress("0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174"),
+				Payment:         big.NewInt(1500000),
+				PaymentReceiver: common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8"),
+			},
+			chainID: 80002,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			domain, structHash, digest, err := CreateProxyDigestParts(tt.createProxy, factory, tt.chainID)
+			if err != nil {
+				t.Fatalf("CreateProxyDigestParts failed: %v", err)
+			}
+
+			if want := GetDomainSeparator(constants.SAFE_FACTORY_NAME, tt.chainID, factory); domain != want {
+				t.Errorf("domain separator = %s, want %s", domain.Hex(), want.Hex())
+			}
+			typeHash := GetCreateProxyTypeHash()
+			want := crypto.Keccak256Hash(
+				typeHash[:],
+				word(tt.createProxy.PaymentToken.Bytes()),
+				word(tt.createProxy.Payment.Bytes()),
+				word(tt.createProxy.PaymentReceiver.Bytes()),
+			)
+			if structHash != want {
+				t.Errorf("struct hash = %s, want %s", structHash.Hex(), want.Hex())
+			}
+			if want := crypto.Keccak256Hash([]byte{0x19, 0x01}, domain[:], structHash[:]); digest != want {
+				t.Errorf("digest = %s, want keccak256(0x1901 ‖ domain ‖ struct) = %s", digest.Hex(), want.Hex())
+			}
+
+			hash, err := BuildCreateProxyHash(tt.createProxy, factory, tt.chainID)
+			if err != nil {
+				t.Fatalf("BuildCreateProxyHash failed: %v", err)
+			}
+			if digest != hash {
+				t.Errorf("digest = %s, BuildCreateProxyHash = %s", digest.Hex(), hash.Hex())
+			}
+		})
+	}
+}
+
+func TestSafeTxValidation(t *testing.T) {
+	safe := common.HexToAddress("0xd93B25cb943D14d0d34FBaF01Fc93a0f8b5F6E47")
+	complete := func() *SafeTx {
+		return &SafeTx{
+			To:        common.HexToAddress("0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174"),
+			Value:     big.NewInt(0),
+			SafeTxGas: big.NewInt(0),
+			BaseGas:   big.NewInt(0),
+			GasPrice:  big.NewInt(0),
+			Nonce:     big.NewInt(3),
+		}
+	}
+	with := func(modify func(*SafeTx)) *SafeTx {
+		safeTx := complete()
+		modify(safeTx)
+		return safeTx
+	}
+
+	tests := []struct {
+		name         string
+		safeTx       *SafeTx
+		zeroContract bool
+		wantField    string
+		shouldErr    bool
+	}{
+		{name: "complete", safeTx: complete()},
+		{name: "nil data", safeTx: with(func(s *SafeTx) { s.Data = nil })},
+		{name: "nil struct", safeTx: nil, wantField: "safeTx", shouldErr: true},
+		{name: "nil value", safeTx: with(func(s *SafeTx) { s.Value = nil }), wantField: "SafeTx.Value", shouldErr: true},
+		{name: "nil safeTxGas", safeTx: with(func(s *SafeTx) { s.SafeTxGas = nil }), wantField: "SafeTx.SafeTxGas", shouldErr: true},
+		{name: "nil baseGas", safeTx: with(func(s *SafeTx) { s.BaseGas = nil }), wantField: "SafeTx.BaseGas", shouldErr: true},
+		{name: "nil gasPrice", safeTx: with(func(s *SafeTx) { s.GasPrice = nil }), wantField: "SafeTx.GasPrice", shouldErr: true},
+		{name: "nil nonce", safeTx: with(func(s *SafeTx) { s.Nonce = nil }), wantField: "SafeTx.Nonce", shouldErr: true},
+		{name: "only nonce set", safeTx: &SafeTx{Nonce: big.NewInt(1)}, wantField: "SafeTx.Value", shouldErr: true},
+		{name: "negative value", safeTx: with(func(s *SafeTx) { s.Value = big.NewInt(-1) }), wantField: "SafeTx.Value", shouldErr: true},
+		{name: "value over uint256", safeTx: with(func(s *SafeTx) { s.Value = new(big.Int).Lsh(big.NewInt(1), 256) }), wantField: "SafeTx.Value", shouldErr: true},
+		{name: "unknown operation", safeTx: with(func(s *SafeTx) { s.Operation = 2 }), wantField: "SafeTx.Operation", shouldErr: true},
+		{name: "zero verifying contract", safeTx: complete(), zeroContract: true, wantField: "verifyingContract", shouldErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifyingContract := safe
+			if tt.zeroContract {
+				verifyingContract = common.Address{}
+			}
+
+			_, err := BuildSafeTxHash(tt.safeTx, verifyingContract, 137)
+			if (err != nil) != tt.shouldErr {
+				t.Fatalf("BuildSafeTxHash() error = %v, shouldErr %v", err, tt.shouldErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), tt.wantField) {
+				t.Errorf("BuildSafeTxHash() error = %v, want it to name %s", err, tt.wantField)
+			}
+			if _, _, _, err := SafeTxDigestParts(tt.safeTx, verifyingContract, 137); (err != nil) != tt.shouldErr {
+				t.Errorf("SafeTxDigestParts() error = %v, shouldErr %v", err, tt.shouldErr)
+			}
+			if _, err := SafeTxHashForVersion(tt.safeTx, verifyingContract, 137, "1.1.1"); (err != nil) != tt.shouldErr {
+				t.Errorf("SafeTxHashForVersion() error = %v, shouldErr %v", err, tt.shouldErr)
+			}
+		})
+	}
+}
+
+func TestCreateProxyValidation(t *testing.T) {
+	factory := common.HexToAddress("0xaacFeEa03eb1561C4e67d661e40682Bd20E3541b")
+
+	tests := []struct {
+		name        string
+		createProxy *CreateProxy
+		wantField   string
+		shouldErr   bool
+	}{
+		{name: "zero payment", createProxy: &CreateProxy{Payment: big.NewInt(0)}},
+		{name: "nil struct", createProxy: nil, wantField: "createProxy", shouldErr: true},
+		{name: "nil payment", createProxy: &CreateProxy{}, wantField: "CreateProxy.Payment", shouldErr: true},
+		{name: "negative payment", createProxy: &CreateProxy{Payment: big.NewInt(-5)}, wantField: "CreateProxy.Payment", shouldErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := BuildCreateProxyHash(tt.createProxy, factory, 137)
+			if (err != nil) != tt.shouldErr {
+				t.Fatalf("BuildCreateProxyHash() error = %v, shouldErr %v", err, tt.shouldErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), tt.wantField) {
+				t.Errorf("BuildCreateProxyHash() error = %v, want it to name %s", err, tt.wantField)
+			}
+			if _, _, _, err := CreateProxyDigestParts(tt.createProxy, factory, 137); (err != nil) != tt.shouldErr {
+				t.Errorf("CreateProxyDigestParts() error = %v, shouldErr %v", err, tt.shouldErr)
+			}
+		})
+	}
+}
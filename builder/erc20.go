@@ -0,0 +1,65 @@
+package builder
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/davidt58/go-builder-relayer-client/errors"
+)
+
+// ERC20ABI is the subset of the ERC-20 token interface that moves tokens or
+// grants allowances
+const ERC20ABI = `[
+	{"name":"transfer","type":"function","inputs":[{"name":"to","type":"address"},{"name":"amount","type":"uint256"}],"outputs":[{"name":"","type":"bool"}]},
+	{"name":"transferFrom","type":"function","inputs":[{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"amount","type":"uint256"}],"outputs":[{"name":"","type":"bool"}]},
+	{"name":"approve","type":"function","inputs":[{"name":"spender","type":"address"},{"name":"amount","type":"uint256"}],"outputs":[{"name":"","type":"bool"}]}
+]`
+
+// erc20 is the parsed ERC20ABI
+var erc20 = mustParseABI(ERC20ABI)
+
+// ERC20Call is a decoded transfer, transferFrom or approve call
+type ERC20Call struct {
+	// Method is "transfer", "transferFrom" or "approve"
+	Method string
+	// From is the owner of the tokens for transferFrom, zero otherwise
+	From common.Address
+	// To is the recipient, or the spender for approve
+	To common.Address
+	// Amount is the amount transferred or approved
+	Amount *big.Int
+}
+
+// DecodeERC20Call decodes hex calldata as an ERC20ABI call. Unlike
+// DecodeCall it does not consult the registry. found is false if the
+// selector is none of transfer, transferFrom or approve; an error means the
+// selector matched but the arguments did not unpack.
+func DecodeERC20Call(data string) (call *ERC20Call, found bool, err error) {
+	callData, err := hexutil.Decode(data)
+	if err != nil {
+		return nil, false, errors.NewRelayerClientError("invalid calldata", err)
+	}
+	if len(callData) < 4 {
+		return nil, false, nil
+	}
+	method, err := erc20.MethodById(callData[:4])
+	if err != nil {
+		return nil, false, nil
+	}
+
+	decoded, err := unpackCall(*method, callData)
+	if err != nil {
+		return nil, true, err
+	}
+	call = &ERC20Call{Method: decoded.Method}
+	args := decoded.Args
+	if call.Method == "transferFrom" {
+		call.From = args[0].Value.(common.Address)
+		args = args[1:]
+	}
+	call.To = args[0].Value.(common.Address)
+	call.Amount = args[1].Value.(*big.Int)
+	return call, true, nil
+}
@@ -0,0 +1,70 @@
+package builder
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+func TestDecodeERC20Call(t *testing.T) {
+	owner := common.HexToAddress("0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266")
+	recipient := common.HexToAddress("0x4D97DCd97eC945f40cF65F87097ACe5EA0476045")
+	pack := func(method string, args ...interface{}) string {
+		data, err := erc20.Pack(method, args...)
+		if err != nil {
+			t.Fatalf("Pack(%s) failed: %v", method, err)
+		}
+		return hexutil.Encode(data)
+	}
+
+	tests := []struct {
+		name      string
+		data      string
+		want      *ERC20Call
+		wantFound bool
+		shouldErr bool
+	}{
+		{
+			name:      "transfer",
+			data:      pack("transfer", recipient, big.NewInt(25)),
+			want:      &ERC20Call{Method: "transfer", To: recipient, Amount: big.NewInt(25)},
+			wantFound: true,
+		},
+		{
+			name:      "transferFrom",
+			data:      pack("transferFrom", owner, recipient, big.NewInt(7)),
+			want:      &ERC20Call{Method: "transferFrom", From: owner, To: recipient, Amount: big.NewInt(7)},
+			wantFound: true,
+		},
+		{
+			name:      "approve",
+			data:      pack("approve", recipient, big.NewInt(1)),
+			want:      &ERC20Call{Method: "approve", To: recipient, Amount: big.NewInt(1)},
+			wantFound: true,
+		},
+		{name: "other selector", data: "0x70a08231"},
+		{name: "empty", data: "0x"},
+		{name: "truncated arguments", data: "0xa9059cbb", wantFound: true, shouldErr: true},
+		{name: "invalid hex", data: "0xzz", shouldErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			call, found, err := DecodeERC20Call(tt.data)
+			if (err != nil) != tt.shouldErr {
+				t.Fatalf("DecodeERC20Call() error = %v, shouldErr %v", err, tt.shouldErr)
+			}
+			if found != tt.wantFound {
+				t.Fatalf("found = %v, want %v", found, tt.wantFound)
+			}
+			if tt.want == nil {
+				return
+			}
+			if call.Method != tt.want.Method || call.From != tt.want.From || call.To != tt.want.To || call.Amount.Cmp(tt.want.Amount) != 0 {
+				t.Errorf("DecodeERC20Call() = %+v, want %+v", call, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package builder
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/davidt58/go-builder-relayer-client/config"
+	"github.com/davidt58/go-builder-relayer-client/constants"
+	"github.com/davidt58/go-builder-relayer-client/models"
+	"github.com/davidt58/go-builder-relayer-client/signer"
+)
+
+func TestBuildSafeTransactionRequest_GasLimit(t *testing.T) {
+	sig, err := signer.NewSigner("ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80", 137)
+	if err != nil {
+		t.Fatalf("Failed to create signer: %v", err)
+	}
+	contractConfig, err := config.GetContractConfig(137)
+	if err != nil {
+		t.Fatalf("GetContractConfig failed: %v", err)
+	}
+	safeAddress := "0x6e0c80c90ea6c15917308F820Eac91Ce2724B5b5"
+
+	withGas := func(gasLimits ...string) []models.SafeTransaction {
+		txns := make([]models.SafeTransaction, len(gasLimits))
+		for i, gasLimit := range gasLimits {
+			txns[i] = testSafeTransactions()[0]
+			txns[i].GasLimit = gasLimit
+		}
+		return txns
+	}
+
+	tests := []struct {
+		name          string
+		transactions  []models.SafeTransaction
+		wantSafeTxGas string
+		wantOperation string
+		shouldErr     bool
+	}{
+		{name: "single without gas limit", transactions: withGas(""), wantSafeTxGas: "0", wantOperation: "0"},
+		{name: "single decimal gas limit", transactions: withGas("150000"), wantSafeTxGas: "150000", wantOperation: "0"},
+		{name: "single hex gas limit", transactions: withGas("0x249f0"), wantSafeTxGas: "150000", wantOperation: "0"},
+		{name: "single invalid gas limit", transactions: withGas("lots"), shouldErr: true},
+		{name: "single negative gas limit", transactions: withGas("-1"), shouldErr: true},
+		{name: "single gas limit over uint256", transactions: withGas("0x1" + strings.Repeat("0", 64)), shouldErr: true},
+		{name: "batch without gas limits", transactions: withGas("", ""), wantSafeTxGas: "0", wantOperation: "1"},
+		{name: "batch with a gas limit", transactions: withGas("", "150000"), shouldErr: true},
+		{name: "batch with all gas limits", transactions: withGas("100000", "150000"), shouldErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := &models.SafeTransactionArgs{
+				SafeAddress:  safeAddress,
+				Transactions: tt.transactions,
+				Nonce:        "7",
+			}
+
+			request, err := BuildSafeTransactionRequestWithConfig(args, sig, contractConfig)
+			if tt.shouldErr {
+				if err == nil {
+					t.Fatal("Expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("BuildSafeTransactionRequestWithConfig failed: %v", err)
+			}
+
+			if got := *request.SignatureParams.SafeTxGas; got != tt.wantSafeTxGas {
+				t.Errorf("SafeTxGas = %s, want %s", got, tt.wantSafeTxGas)
+			}
+			if got := *request.SignatureParams.Operation; got != tt.wantOperation {
+				t.Errorf("Operation = %s, want %s", got, tt.wantOperation)
+			}
+			if len(tt.transactions) > 1 {
+				return
+			}
+
+			// The signature must commit to the same safeTxGas the relayer is told
+			txn := tt.transactions[0]
+			safeTxGas, _ := new(big.Int).SetString(tt.wantSafeTxGas, 10)
+			zero := common.HexToAddress(constants.ZERO_ADDRESS)
+			want, err := ComputeSafeTxHash(
+				common.HexToAddress(txn.To), big.NewInt(0), hexutil.MustDecode(txn.Data), uint8(txn.Operation),
+				safeTxGas, big.NewInt(0), big.NewInt(0), zero, zero, big.NewInt(7),
+				common.HexToAddress(safeAddress), 137,
+			)
+			if err != nil {
+				t.Fatalf("ComputeSafeTxHash failed: %v", err)
+			}
+			if got := safeCheckSignature(t, want, request.Signature); got != sig.Address() {
+				t.Errorf("signature does not cover safeTxGas %s", tt.wantSafeTxGas)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package builder
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/davidt58/go-builder-relayer-client/errors"
+)
+
+// safeSetupSelector is the selector of Safe.setup(): 0xb63e800d
+var safeSetupSelector = crypto.Keccak256([]byte("setup(address[],uint256,address,bytes,address,address,uint256,address)"))[:4]
+
+// SafeSetupConfig holds the parameters of the Safe.setup() call a Safe proxy
+// is initialized with
+type SafeSetupConfig struct {
+	// Owners are the Safe's owners
+	Owners []common.Address
+	// Threshold is the number of owner signatures a transaction needs
+	Threshold *big.Int
+	// To and Data are the optional delegate call made during setup
+	To   common.Address
+	Data []byte
+	// FallbackHandler handles calls to functions the Safe does not implement
+	FallbackHandler common.Address
+	// PaymentToken, Payment and PaymentReceiver refund the deployment;
+	// the zero PaymentToken means the native token
+	PaymentToken    common.Address
+	Payment         *big.Int
+	PaymentReceiver common.Address
+}
+
+// Encode returns the setup() calldata for the config: the selector followed
+// by the ABI-encoded parameters
+func (s *SafeSetupConfig) Encode() ([]byte, error) {
+	params, err := encodeSafeSetupParams(s.Owners, s.Threshold, s.To, s.Data, s.FallbackHandler, s.PaymentToken, s.Payment, s.PaymentReceiver)
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]byte(nil), safeSetupSelector...), params...), nil
+}
+
+// safeSetupHeadSize is the size of setup()'s ABI head: one word per parameter
+const safeSetupHeadSize = 8 * 32
+
+// DecodeSafeInitializer decodes setup() calldata, e.g. the initializer of a
+// SAFE-CREATE request or of an on-chain createProxy call, back into its
+// parameters. The selector must be setup()'s, and the offsets and lengths
+// of the owners array and data bytes must lie within data.
+func DecodeSafeInitializer(data []byte) (*SafeSetupConfig, error) {
+	if len(data) < len(safeSetupSelector) || !bytes.Equal(data[:len(safeSetupSelector)], safeSetupSelector) {
+		return nil, errors.ErrInvalidSafeSetup("not setup() calldata")
+	}
+	args := data[len(safeSetupSelector):]
+	if len(args) < safeSetupHeadSize {
+		return nil, errors.ErrInvalidSafeSetup(fmt.Sprintf("setup() arguments are %d bytes, shorter than the %d byte head", len(args), safeSetupHeadSize))
+	}
+
+	// owners (parameter 0) is a dynamic array of 32-byte words and data
+	// (parameter 3) dynamic bytes
+	if err := checkDynamicArgument(args, "owners", 0, 32); err != nil {
+		return nil, err
+	}
+	if err := checkDynamicArgument(args, "data", 3, 1); err != nil {
+		return nil, err
+	}
+
+	values, err := safeSetupArguments.Unpack(args)
+	if err != nil {
+		return nil, errors.ErrInvalidSafeSetup(err.Error())
+	}
+	owners, ok0 := values[0].([]common.Address)
+	threshold, ok1 := values[1].(*big.Int)
+	to, ok2 := values[2].(common.Address)
+	setupData, ok3 := values[3].([]byte)
+	fallbackHandler, ok4 := values[4].(common.Address)
+	paymentToken, ok5 := values[5].(common.Address)
+	payment, ok6 := values[6].(*big.Int)
+	paymentReceiver, ok7 := values[7].(common.Address)
+	if !(ok0 && ok1 && ok2 && ok3 && ok4 && ok5 && ok6 && ok7) {
+		return nil, errors.ErrInvalidSafeSetup("unexpected setup() argument types")
+	}
+
+	return &SafeSetupConfig{
+		Owners:          owners,
+		Threshold:       threshold,
+		To:              to,
+		Data:            setupData,
+		FallbackHandler: fallbackHandler,
+		PaymentToken:    paymentToken,
+		Payment:         payment,
+		PaymentReceiver: paymentReceiver,
+	}, nil
+}
+
+// checkDynamicArgument checks that the offset in head word index of args
+// points past the head, and that the length word there and its elements of
+// elementSize bytes lie within args
+func checkDynamicArgument(args []byte, name string, index, elementSize int) error {
+	size := big.NewInt(int64(len(args)))
+	offset := new(big.Int).SetBytes(args[index*32 : (index+1)*32])
+	if offset.Cmp(big.NewInt(safeSetupHeadSize)) < 0 || new(big.Int).Add(offset, big.NewInt(32)).Cmp(size) > 0 {
+		return errors.ErrInvalidSafeSetup(fmt.Sprintf("%s offset %s is outside the %d byte arguments", name, offset, len(args)))
+	}
+
+	start := int(offset.Int64())
+	length := new(big.Int).SetBytes(args[start : start+32])
+	end := new(big.Int).Mul(length, big.NewInt(int64(elementSize)))
+	end.Add(end, big.NewInt(int64(start+32)))
+	if end.Cmp(size) > 0 {
+		return errors.ErrInvalidSafeSetup(fmt.Sprintf("%s length %s at offset %d exceeds the %d byte arguments", name, length, start, len(args)))
+	}
+	return nil
+}
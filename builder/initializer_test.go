@@ -0,0 +1,141 @@
+package builder
+
+import (
+	"bytes"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/davidt58/go-builder-relayer-client/config"
+)
+
+// sameSetup reports whether a and b hold the same parameters
+func sameSetup(a, b *SafeSetupConfig) bool {
+	return reflect.DeepEqual(a.Owners, b.Owners) && a.Threshold.Cmp(b.Threshold) == 0 && a.To == b.To &&
+		bytes.Equal(a.Data, b.Data) && a.FallbackHandler == b.FallbackHandler && a.PaymentToken == b.PaymentToken &&
+		a.Payment.Cmp(b.Payment) == 0 && a.PaymentReceiver == b.PaymentReceiver
+}
+
+func TestDecodeSafeInitializer_RoundTrip(t *testing.T) {
+	contractConfig, err := config.GetContractConfig(137)
+	if err != nil {
+		t.Fatalf("GetContractConfig failed: %v", err)
+	}
+	signer := common.HexToAddress(testSignerAddress)
+	singleOwner, err := buildSafeInitializer(signer, contractConfig)
+	if err != nil {
+		t.Fatalf("buildSafeInitializer failed: %v", err)
+	}
+
+	multiOwner := &SafeSetupConfig{
+		Owners:          []common.Address{signer, common.HexToAddress("0x1111111111111111111111111111111111111111"), common.HexToAddress("0x2222222222222222222222222222222222222222")},
+		Threshold:       big.NewInt(2),
+		Data:            []byte{},
+		FallbackHandler: common.HexToAddress(contractConfig.SafeFallbackHandler),
+		Payment:         big.NewInt(0),
+	}
+	withData := &SafeSetupConfig{
+		Owners:          []common.Address{signer},
+		Threshold:       big.NewInt(1),
+		To:              common.HexToAddress("0x3333333333333333333333333333333333333333"),
+		Data:            bytes.Repeat([]byte{0xab}, 37),
+		FallbackHandler: common.HexToAddress(contractConfig.SafeFallbackHandler),
+		PaymentToken:    common.HexToAddress("0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174"),
+		Payment:         big.NewInt(5000),
+		PaymentReceiver: common.HexToAddress("0x4444444444444444444444444444444444444444"),
+	}
+
+	tests := []struct {
+		name        string
+		initializer func() ([]byte, error)
+		want        *SafeSetupConfig
+	}{
+		{
+			name:        "single owner",
+			initializer: func() ([]byte, error) { return singleOwner, nil },
+			want: &SafeSetupConfig{
+				Owners:          []common.Address{signer},
+				Threshold:       big.NewInt(1),
+				Data:            []byte{},
+				FallbackHandler: common.HexToAddress(contractConfig.SafeFallbackHandler),
+				Payment:         big.NewInt(0),
+			},
+		},
+		{name: "multi owner", initializer: multiOwner.Encode, want: multiOwner},
+		{name: "non-empty data", initializer: withData.Encode, want: withData},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			initializer, err := tt.initializer()
+			if err != nil {
+				t.Fatalf("encoding failed: %v", err)
+			}
+			decoded, err := DecodeSafeInitializer(initializer)
+			if err != nil {
+				t.Fatalf("DecodeSafeInitializer failed: %v", err)
+			}
+			if !sameSetup(decoded, tt.want) {
+				t.Errorf("decoded %+v, want %+v", decoded, tt.want)
+			}
+
+			reencoded, err := decoded.Encode()
+			if err != nil {
+				t.Fatalf("Encode failed: %v", err)
+			}
+			if !bytes.Equal(reencoded, initializer) {
+				t.Errorf("re-encoded initializer differs:\n got %x\nwant %x", reencoded, initializer)
+			}
+		})
+	}
+}
+
+func TestDecodeSafeInitializer_Invalid(t *testing.T) {
+	setup := &SafeSetupConfig{
+		Owners:    []common.Address{common.HexToAddress(testSignerAddress)},
+		Threshold: big.NewInt(1),
+		Data:      []byte{1, 2, 3},
+	}
+	valid, err := setup.Encode()
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+
+	// withWord returns valid with the head word at index replaced by value
+	withWord := func(index int, value int64) []byte {
+		data := append([]byte(nil), valid...)
+		word := common.LeftPadBytes(big.NewInt(value).Bytes(), 32)
+		copy(data[4+index*32:], word)
+		return data
+	}
+	// The owners array starts right after the head; its length word is at 4+256
+	ownersLength := append([]byte(nil), valid...)
+	copy(ownersLength[4+256:4+288], common.LeftPadBytes(big.NewInt(1000).Bytes(), 32))
+	hugeOffset := append([]byte(nil), valid...)
+	copy(hugeOffset[4:36], bytes.Repeat([]byte{0xff}, 32))
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "empty", data: nil},
+		{name: "wrong selector", data: append([]byte{0xde, 0xad, 0xbe, 0xef}, valid[4:]...)},
+		{name: "short head", data: valid[:4+7*32]},
+		{name: "owners offset inside the head", data: withWord(0, 64)},
+		{name: "owners offset past the end", data: withWord(0, int64(len(valid)))},
+		{name: "owners offset overflowing", data: hugeOffset},
+		{name: "owners length past the end", data: ownersLength},
+		{name: "data offset past the end", data: withWord(3, int64(len(valid)+32))},
+		{name: "truncated data", data: valid[:len(valid)-32]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if decoded, err := DecodeSafeInitializer(tt.data); err == nil {
+				t.Errorf("DecodeSafeInitializer = %+v, want an error", decoded)
+			}
+		})
+	}
+}
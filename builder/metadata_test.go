@@ -0,0 +1,109 @@
+package builder
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/davidt58/go-builder-relayer-client/config"
+	"github.com/davidt58/go-builder-relayer-client/models"
+	"github.com/davidt58/go-builder-relayer-client/signer"
+)
+
+// metadataKey returns the metadata key of payload's JSON encoding, and
+// whether it is present
+func metadataKey(t *testing.T, payload interface{}) (value string, present bool) {
+	t.Helper()
+
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("json.Marshal failed: %v", err)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(encoded, &fields); err != nil {
+		t.Fatalf("json.Unmarshal failed: %v", err)
+	}
+	raw, present := fields["metadata"]
+	if !present {
+		return "", false
+	}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		t.Fatalf("metadata is not a string: %s", raw)
+	}
+	return value, true
+}
+
+func TestMetadataPresence(t *testing.T) {
+	sig, err := signer.NewSigner("ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80", testChainID)
+	if err != nil {
+		t.Fatalf("Failed to create signer: %v", err)
+	}
+	contractConfig, err := config.GetContractConfig(testChainID)
+	if err != nil {
+		t.Fatalf("GetContractConfig failed: %v", err)
+	}
+	safeAddress, err := DeriveSafeAddressWithConfig(sig.Address(), contractConfig)
+	if err != nil {
+		t.Fatalf("DeriveSafeAddressWithConfig failed: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		metadata    *string
+		wantPresent bool
+		want        string
+	}{
+		{name: "unset", metadata: nil},
+		{name: "explicit empty", metadata: models.ExplicitMetadata(""), wantPresent: true, want: ""},
+		{name: "value", metadata: models.ExplicitMetadata("order 42"), wantPresent: true, want: "order 42"},
+		{name: "MetadataOf empty", metadata: models.MetadataOf("")},
+		{name: "MetadataOf value", metadata: models.MetadataOf("order 42"), wantPresent: true, want: "order 42"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			safeArgs := &models.SafeTransactionArgs{
+				SafeAddress:  safeAddress.Hex(),
+				Transactions: testSafeTransactions(),
+				Nonce:        "3",
+				Metadata:     tt.metadata,
+			}
+			multisend, err := BuildSafeTransactionRequestWithConfig(safeArgs, sig, contractConfig)
+			if err != nil {
+				t.Fatalf("BuildSafeTransactionRequestWithConfig failed: %v", err)
+			}
+			safeArgs.Transactions = safeArgs.Transactions[:1]
+			single, err := BuildSafeTransactionRequest(safeArgs, sig, testChainID)
+			if err != nil {
+				t.Fatalf("BuildSafeTransactionRequest failed: %v", err)
+			}
+			v2, err := single.Versioned(models.RequestVersionV2)
+			if err != nil {
+				t.Fatalf("Versioned failed: %v", err)
+			}
+			create, err := BuildSafeCreateTransactionRequestWithConfig(&models.SafeCreateTransactionArgs{
+				SignerAddress: sig.AddressHex(),
+				SafeAddress:   safeAddress.Hex(),
+				Nonce:         "0",
+				Metadata:      tt.metadata,
+			}, sig, contractConfig)
+			if err != nil {
+				t.Fatalf("BuildSafeCreateTransactionRequestWithConfig failed: %v", err)
+			}
+
+			for name, payload := range map[string]interface{}{
+				"SAFE":        single,
+				"SAFE batch":  multisend,
+				"SAFE v2":     v2,
+				"SAFE-CREATE": create,
+			} {
+				got, present := metadataKey(t, payload)
+				if present != tt.wantPresent {
+					t.Errorf("%s: metadata key present = %v, want %v", name, present, tt.wantPresent)
+				}
+				if got != tt.want {
+					t.Errorf("%s: metadata = %q, want %q", name, got, tt.want)
+				}
+			}
+		})
+	}
+}
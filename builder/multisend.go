@@ -3,8 +3,12 @@ package builder
 import (
 	"bytes"
 	"encoding/binary"
+	"fmt"
+	"io"
 	"math/big"
+	"strings"
 
+	"github.com/davidt58/go-builder-relayer-client/config"
 	"github.com/davidt58/go-builder-relayer-client/constants"
 	"github.com/davidt58/go-builder-relayer-client/errors"
 	"github.com/davidt58/go-builder-relayer-client/models"
@@ -18,60 +22,92 @@ import (
 // - Each transaction is encoded as: uint8(operation) ++ address(to) ++ uint256(value) ++ uint256(dataLength) ++ bytes(data)
 // - All transactions are concatenated
 // - The result is wrapped with the multisend function selector
+// If multiSendAddress is a registered MultiSendCallOnly contract, the batch is
+// validated as MultisendCallOnly.
 func CreateSafeMultisendTransaction(transactions []models.SafeTransaction, multiSendAddress string) (*models.SafeTransaction, error) {
+	return CreateSafeMultisendTransactionWithVariant(transactions, multiSendAddress, config.MultisendVariantOf(multiSendAddress))
+}
+
+// CreateSafeMultisendTransactionWithVariant encodes transactions for the given
+// MultiSend variant. The encoding is identical for both contracts; for
+// MultisendCallOnly every inner transaction must be a Call. The outer
+// transaction always delegatecalls the MultiSend contract.
+func CreateSafeMultisendTransactionWithVariant(transactions []models.SafeTransaction, multiSendAddress string, variant config.MultisendVariant) (*models.SafeTransaction, error) {
 	if len(transactions) == 0 {
 		return nil, errors.NewRelayerClientError("no transactions to encode", nil)
 	}
+	if err := checkMultisendVariant(transactions, variant); err != nil {
+		return nil, err
+	}
 
 	// Encode all transactions using packed encoding
 	var encodedTxns bytes.Buffer
 
-	for _, txn := range transactions {
-		// Encode each transaction in the format:
-		// operation (uint8, 1 byte)
-		// to (address, 20 bytes)
-		// value (uint256, 32 bytes)
-		// dataLength (uint256, 32 bytes)
-		// data (bytes, variable length)
-
-		// Operation (1 byte)
-		encodedTxns.WriteByte(byte(txn.Operation))
-
-		// To address (20 bytes)
-		toAddr := common.HexToAddress(txn.To)
-		encodedTxns.Write(toAddr.Bytes())
-
-		// Value (32 bytes)
-		value := new(big.Int)
-		if txn.Value != "" {
-			value.SetString(txn.Value, 0)
+	for i, txn := range transactions {
+		// MultiSend runs the batch as one delegatecall, so there is no
+		// per-call safeTxGas to carry a GasLimit
+		if txn.GasLimit != "" {
+			return nil, errors.ErrInvalidGasLimit(fmt.Sprintf("transaction %d sets GasLimit %s, which cannot be applied to a MultiSend batch; execute it on its own instead", i, txn.GasLimit))
 		}
-		valueBytes := make([]byte, 32)
-		value.FillBytes(valueBytes)
-		encodedTxns.Write(valueBytes)
-
-		// Decode data
-		var dataBytes []byte
-		if txn.Data != "" && txn.Data != "0x" {
-			var err error
-			dataBytes, err = hexutil.Decode(txn.Data)
-			if err != nil {
-				return nil, errors.NewRelayerClientError("failed to decode transaction data", err)
-			}
+
+		if err := encodeMultiSendTransaction(&encodedTxns, txn); err != nil {
+			return nil, err
 		}
+	}
 
-		// Data length (32 bytes)
-		dataLength := big.NewInt(int64(len(dataBytes)))
-		dataLengthBytes := make([]byte, 32)
-		dataLength.FillBytes(dataLengthBytes)
-		encodedTxns.Write(dataLengthBytes)
+	return wrapMultiSend(encodedTxns.Bytes(), multiSendAddress)
+}
 
-		// Data (variable length)
-		if len(dataBytes) > 0 {
-			encodedTxns.Write(dataBytes)
+// WrapMultiSendPayload wraps packed MultiSend transactions encoded elsewhere,
+// e.g. by another language's SDK, into the DelegateCall transaction that
+// CreateSafeMultisendTransaction builds for the same transactions, without
+// decoding and re-encoding them. The payload must pass
+// ValidateMultiSendPayload; if multiSendAddress is a registered
+// MultiSendCallOnly contract, every inner transaction must be a Call.
+func WrapMultiSendPayload(innerPayload []byte, multiSendAddress string) (*models.SafeTransaction, error) {
+	if !common.IsHexAddress(multiSendAddress) {
+		return nil, errors.ErrInvalidAddress(multiSendAddress)
+	}
+	transactions, err := decodeMultiSendPayload(innerPayload)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkMultisendVariant(transactions, config.MultisendVariantOf(multiSendAddress)); err != nil {
+		return nil, err
+	}
+	return wrapMultiSend(innerPayload, multiSendAddress)
+}
+
+// ValidateMultiSendPayload checks that innerPayload is a sequence of packed
+// MultiSend transactions, as EncodeMultiSendData produces, and returns how
+// many it holds. Each must decode completely and use a Call or DelegateCall
+// operation.
+func ValidateMultiSendPayload(innerPayload []byte) (int, error) {
+	transactions, err := decodeMultiSendPayload(innerPayload)
+	if err != nil {
+		return 0, err
+	}
+	return len(transactions), nil
+}
+
+// decodeMultiSendPayload decodes innerPayload, rejecting operations the
+// MultiSend contract does not execute
+func decodeMultiSendPayload(innerPayload []byte) ([]models.SafeTransaction, error) {
+	transactions, err := DecodeMultiSendData(innerPayload)
+	if err != nil {
+		return nil, errors.NewRelayerClientError("invalid multisend payload", err)
+	}
+	for i, txn := range transactions {
+		if txn.Operation != models.Call && txn.Operation != models.DelegateCall {
+			return nil, errors.NewRelayerClientError(fmt.Sprintf("invalid multisend payload: transaction %d has unknown operation %d", i, txn.Operation), nil)
 		}
 	}
+	return transactions, nil
+}
 
+// wrapMultiSend builds the DelegateCall of multiSend(bytes) on
+// multiSendAddress with the packed transactions in encoded
+func wrapMultiSend(encoded []byte, multiSendAddress string) (*models.SafeTransaction, error) {
 	// Wrap with multisend function selector
 	// multiSend(bytes) - selector is 0x8d80ff0a
 	selector, err := hexutil.Decode(constants.MULTISEND_FUNCTION_SELECTOR)
@@ -90,16 +126,17 @@ func CreateSafeMultisendTransaction(transactions []models.SafeTransaction, multi
 	callData.Write(offset)
 
 	// Length of the encoded transactions
-	length := big.NewInt(int64(encodedTxns.Len()))
+	length := big.NewInt(int64(len(encoded)))
 	lengthBytes := make([]byte, 32)
 	length.FillBytes(lengthBytes)
 	callData.Write(lengthBytes)
 
 	// Encoded transactions
-	callData.Write(encodedTxns.Bytes())
+	callData.Write(encoded)
 
-	// Pad to 32-byte boundary if needed
-	remainder := callData.Len() % 32
+	// Pad the bytes parameter to a 32-byte boundary; the selector is not
+	// part of the ABI-encoded arguments
+	remainder := len(encoded) % 32
 	if remainder != 0 {
 		padding := make([]byte, 32-remainder)
 		callData.Write(padding)
@@ -122,61 +159,121 @@ func EncodeMultiSendData(transactions []models.SafeTransaction) ([]byte, error)
 	var encoded bytes.Buffer
 
 	for _, txn := range transactions {
-		// Operation (1 byte)
-		encoded.WriteByte(byte(txn.Operation))
+		if err := encodeMultiSendTransaction(&encoded, txn); err != nil {
+			return nil, err
+		}
+	}
 
-		// To address (20 bytes)
-		toAddr := common.HexToAddress(txn.To)
-		encoded.Write(toAddr.Bytes())
+	return encoded.Bytes(), nil
+}
 
-		// Value (32 bytes)
-		value := new(big.Int)
-		if txn.Value != "" {
-			value.SetString(txn.Value, 0)
-		}
-		valueBytes := make([]byte, 32)
-		value.FillBytes(valueBytes)
-		encoded.Write(valueBytes)
-
-		// Decode data
-		var dataBytes []byte
-		if txn.Data != "" && txn.Data != "0x" {
-			var err error
-			dataBytes, err = hexutil.Decode(txn.Data)
-			if err != nil {
-				return nil, errors.NewRelayerClientError("failed to decode transaction data", err)
-			}
-		}
+// encodeMultiSendTransaction appends one transaction in the packed MultiSend format:
+// operation (uint8, 1 byte)
+// to (address, 20 bytes)
+// value (uint256, 32 bytes)
+// dataLength (uint256, 32 bytes)
+// data (bytes, variable length)
+func encodeMultiSendTransaction(buf *bytes.Buffer, txn models.SafeTransaction) error {
+	// Operation (1 byte)
+	buf.WriteByte(byte(txn.Operation))
+
+	// To address (20 bytes)
+	toAddr := common.HexToAddress(txn.To)
+	buf.Write(toAddr.Bytes())
+
+	// Value (32 bytes)
+	value, err := txn.ValueBig()
+	if err != nil {
+		return err
+	}
+	valueBytes := make([]byte, 32)
+	value.FillBytes(valueBytes)
+	buf.Write(valueBytes)
 
-		// Data length (32 bytes)
-		dataLength := big.NewInt(int64(len(dataBytes)))
-		dataLengthBytes := make([]byte, 32)
-		dataLength.FillBytes(dataLengthBytes)
-		encoded.Write(dataLengthBytes)
+	// Decode data
+	dataBytes, err := decodeTransactionData(txn.Data)
+	if err != nil {
+		return err
+	}
 
-		// Data (variable length)
-		if len(dataBytes) > 0 {
-			encoded.Write(dataBytes)
-		}
+	// Data length (32 bytes)
+	dataLength := big.NewInt(int64(len(dataBytes)))
+	dataLengthBytes := make([]byte, 32)
+	dataLength.FillBytes(dataLengthBytes)
+	buf.Write(dataLengthBytes)
+
+	// Data (variable length)
+	buf.Write(dataBytes)
+	return nil
+}
+
+// maxUint256 is the largest value a uint256 transaction value can hold
+var maxUint256 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+
+// decodeTransactionData decodes a transaction's calldata in its normalized
+// form, so the bytes hashed are the ones the request carries
+func decodeTransactionData(raw string) ([]byte, error) {
+	data, err := models.NormalizeHexData(raw)
+	if err != nil {
+		return nil, err
 	}
+	return hexutil.MustDecode(data), nil
+}
 
-	return encoded.Bytes(), nil
+// parseTransactionValue parses a transaction value given in decimal or as
+// 0x-prefixed hex; empty means 0. Decimal values with leading zeros stay
+// decimal (as in the Python SDK) rather than being read as octal.
+func parseTransactionValue(raw string) (*big.Int, error) {
+	txn := models.SafeTransaction{Value: raw}
+	return txn.ValueBig()
 }
 
 // AggregateSafeTransaction combines multiple Safe transactions into a single multisend transaction
 // This is the main function to use when you need to batch multiple transactions
 func AggregateSafeTransaction(transactions []models.SafeTransaction, safeMultisend string) (*models.SafeTransaction, error) {
+	return AggregateSafeTransactionWithVariant(transactions, safeMultisend, config.MultisendVariantOf(safeMultisend))
+}
+
+// AggregateSafeTransactionWithVariant combines transactions through the given
+// MultiSend variant. A single transaction is returned as-is, but under
+// MultisendCallOnly it must still be a Call.
+func AggregateSafeTransactionWithVariant(transactions []models.SafeTransaction, safeMultisend string, variant config.MultisendVariant) (*models.SafeTransaction, error) {
 	if len(transactions) == 0 {
 		return nil, errors.NewRelayerClientError("no transactions to aggregate", nil)
 	}
 
 	// If there's only one transaction, return it as-is
 	if len(transactions) == 1 {
+		if err := checkMultisendVariant(transactions, variant); err != nil {
+			return nil, err
+		}
 		return &transactions[0], nil
 	}
 
 	// Otherwise, create a multisend transaction
-	return CreateSafeMultisendTransaction(transactions, safeMultisend)
+	return CreateSafeMultisendTransactionWithVariant(transactions, safeMultisend, variant)
+}
+
+// checkMultisendVariant validates the variant and, for MultisendCallOnly,
+// rejects DelegateCall transactions listing every offending index
+func checkMultisendVariant(transactions []models.SafeTransaction, variant config.MultisendVariant) error {
+	if err := variant.Validate(); err != nil {
+		return err
+	}
+	if variant.OrDefault() != config.MultisendCallOnly {
+		return nil
+	}
+
+	var delegateCalls []int
+	for i, txn := range transactions {
+		if txn.Operation != models.Call {
+			delegateCalls = append(delegateCalls, i)
+		}
+	}
+	if len(delegateCalls) > 0 {
+		return errors.ErrDelegateCallNotAllowed(delegateCalls)
+	}
+	return nil
 }
 
 // DecodeMultiSendData decodes multisend data back into individual transactions
@@ -198,32 +295,32 @@ func DecodeMultiSendData(data []byte) ([]models.SafeTransaction, error) {
 
 		// Read to address (20 bytes)
 		toBytes := make([]byte, 20)
-		if _, err := reader.Read(toBytes); err != nil {
+		if _, err := io.ReadFull(reader, toBytes); err != nil {
 			return nil, errors.NewRelayerClientError("failed to read to address", err)
 		}
 		to := common.BytesToAddress(toBytes)
 
 		// Read value (32 bytes)
 		valueBytes := make([]byte, 32)
-		if _, err := reader.Read(valueBytes); err != nil {
+		if _, err := io.ReadFull(reader, valueBytes); err != nil {
 			return nil, errors.NewRelayerClientError("failed to read value", err)
 		}
 		value := new(big.Int).SetBytes(valueBytes)
 
 		// Read data length (32 bytes)
 		dataLengthBytes := make([]byte, 32)
-		if _, err := reader.Read(dataLengthBytes); err != nil {
+		if _, err := io.ReadFull(reader, dataLengthBytes); err != nil {
 			return nil, errors.NewRelayerClientError("failed to read data length", err)
 		}
 		dataLength := new(big.Int).SetBytes(dataLengthBytes)
+		if dataLength.Cmp(big.NewInt(int64(reader.Len()))) > 0 {
+			return nil, errors.NewRelayerClientError(fmt.Sprintf("data length %s exceeds the %d remaining bytes", dataLength, reader.Len()), nil)
+		}
 
 		// Read data
-		var txnData []byte
-		if dataLength.Cmp(big.NewInt(0)) > 0 {
-			txnData = make([]byte, dataLength.Int64())
-			if _, err := reader.Read(txnData); err != nil {
-				return nil, errors.NewRelayerClientError("failed to read data", err)
-			}
+		txnData := make([]byte, dataLength.Int64())
+		if _, err := io.ReadFull(reader, txnData); err != nil {
+			return nil, errors.NewRelayerClientError("failed to read data", err)
 		}
 
 		txn := models.SafeTransaction{
@@ -239,6 +336,71 @@ func DecodeMultiSendData(data []byte) ([]models.SafeTransaction, error) {
 	return transactions, nil
 }
 
+// DecodeMultiSendCallData decodes the calldata of a multiSend(bytes) call, as
+// produced by CreateSafeMultisendTransaction, back into its transactions
+func DecodeMultiSendCallData(callData []byte) ([]models.SafeTransaction, error) {
+	selector, err := hexutil.Decode(constants.MULTISEND_FUNCTION_SELECTOR)
+	if err != nil {
+		return nil, errors.NewRelayerClientError("invalid multisend selector", err)
+	}
+	if len(callData) < len(selector)+64 || !bytes.Equal(callData[:len(selector)], selector) {
+		return nil, errors.NewRelayerClientError("not multiSend(bytes) calldata", nil)
+	}
+	args := callData[len(selector):]
+
+	// A single dynamic parameter: offset (32 bytes), then length (32 bytes) and data
+	offset := new(big.Int).SetBytes(args[:32])
+	if offset.Cmp(big.NewInt(32)) != 0 {
+		return nil, errors.NewRelayerClientError(fmt.Sprintf("unexpected multiSend data offset %s", offset), nil)
+	}
+	length := new(big.Int).SetBytes(args[32:64])
+	if length.Cmp(big.NewInt(int64(len(args)-64))) > 0 {
+		return nil, errors.NewRelayerClientError(fmt.Sprintf("multiSend data length %s exceeds the calldata", length), nil)
+	}
+
+	return DecodeMultiSendData(args[64 : 64+length.Int64()])
+}
+
+// DecodedTransactions reconstructs the transactions of a relayer
+// transaction from its request echo. A DelegateCall of multiSend(bytes) is
+// unwrapped into the calls it batches; other calls are returned as they were
+// signed. Without an echo it fails with an OriginalPayloadUnavailableError.
+func DecodedTransactions(txn *models.RelayerTransaction) ([]models.SafeTransaction, error) {
+	if txn == nil {
+		return nil, errors.ErrMissingRequiredField("transaction")
+	}
+	echoed, err := txn.RequestTransactions()
+	if err != nil {
+		return nil, err
+	}
+	if len(echoed) == 0 {
+		return nil, errors.ErrOriginalPayloadUnavailable(txn.TransactionID)
+	}
+
+	var transactions []models.SafeTransaction
+	for i, call := range echoed {
+		callData, err := models.NormalizeHexData(call.Data)
+		if err != nil {
+			return nil, errors.NewRelayerClientError(fmt.Sprintf("echoed call %d", i), err)
+		}
+		if call.Operation == models.DelegateCall && strings.HasPrefix(callData, constants.MULTISEND_FUNCTION_SELECTOR) {
+			batched, err := DecodeMultiSendCallData(hexutil.MustDecode(callData))
+			if err != nil {
+				return nil, errors.NewRelayerClientError(fmt.Sprintf("echoed call %d", i), err)
+			}
+			transactions = append(transactions, batched...)
+			continue
+		}
+		transactions = append(transactions, models.SafeTransaction{
+			To:        call.To,
+			Value:     call.Value,
+			Data:      callData,
+			Operation: call.Operation,
+		})
+	}
+	return transactions, nil
+}
+
 // ComputeMultiSendHash computes the hash of a multisend transaction
 // This is useful for verification and debugging
 func ComputeMultiSendHash(transactions []models.SafeTransaction) (common.Hash, error) {
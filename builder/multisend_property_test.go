@@ -0,0 +1,328 @@
+package builder
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+var (
+	multisendSeed = flag.Int64("multisend.seed", 1, "first seed of the multisend property tests")
+	multisendRuns = flag.Int("multisend.runs", 300, "number of random batches the multisend property tests check")
+)
+
+// multisendRegressionSeeds generated batches that exposed encoding bugs:
+// 1 - the multiSend bytes argument was padded together with the selector,
+// leaving the ABI arguments misaligned
+// 12 - a zero-padded decimal value ("0527") was parsed as octal
+var multisendRegressionSeeds = []int64{1, 12}
+
+// genMultisendBatch generates 1-8 random transactions from seed
+func genMultisendBatch(seed int64) []models.SafeTransaction {
+	r := rand.New(rand.NewSource(seed))
+	batch := make([]models.SafeTransaction, 1+r.Intn(8))
+	for i := range batch {
+		batch[i] = genSafeTransaction(r)
+	}
+	return batch
+}
+
+// genSafeTransaction generates a transaction with a random address, a value
+// in one of the accepted spellings, 0-1024 bytes of data and an operation
+func genSafeTransaction(r *rand.Rand) models.SafeTransaction {
+	var addr common.Address
+	r.Read(addr[:])
+	to := addr.Hex()
+	if r.Intn(2) == 0 {
+		to = strings.ToLower(to)
+	}
+
+	return models.SafeTransaction{
+		To:        to,
+		Value:     genValue(r),
+		Data:      genData(r),
+		Operation: models.OperationType(r.Intn(2)),
+	}
+}
+
+// genValue returns a uint256 as decimal (possibly zero-padded), 0x-hex in
+// either case, or "" for zero
+func genValue(r *rand.Rand) string {
+	bits := r.Intn(257)
+	value := new(big.Int).Rand(r, new(big.Int).Lsh(big.NewInt(1), uint(bits)))
+
+	switch r.Intn(5) {
+	case 0:
+		return "0x" + value.Text(16)
+	case 1:
+		return "0X" + strings.ToUpper(value.Text(16))
+	case 2:
+		return strings.Repeat("0", 1+r.Intn(3)) + value.String()
+	case 3:
+		if value.Sign() == 0 {
+			return ""
+		}
+	}
+	return value.String()
+}
+
+// genData returns random calldata; empty data is spelled "" or "0x"
+func genData(r *rand.Rand) string {
+	var length int
+	switch r.Intn(4) {
+	case 0:
+		length = 0
+	case 1:
+		length = 1 + r.Intn(64)
+	default:
+		length = r.Intn(1025)
+	}
+	if length == 0 && r.Intn(2) == 0 {
+		return ""
+	}
+	data := make([]byte, length)
+	r.Read(data)
+	return hexutil.Encode(data)
+}
+
+// wantValue parses a generated value independently of the code under test
+func wantValue(raw string) *big.Int {
+	value := new(big.Int)
+	switch {
+	case raw == "":
+	case strings.HasPrefix(raw, "0x"), strings.HasPrefix(raw, "0X"):
+		value.SetString(raw[2:], 16)
+	default:
+		value.SetString(raw, 10)
+	}
+	return value
+}
+
+// wantData decodes generated data, treating "" as empty
+func wantData(raw string) []byte {
+	if raw == "" {
+		return []byte{}
+	}
+	return hexutil.MustDecode(raw)
+}
+
+// checkMultisendBatch asserts the round-trip and encoding invariants for batch
+func checkMultisendBatch(batch []models.SafeTransaction) error {
+	multisend, err := CreateSafeMultisendTransaction(batch, testMultisend)
+	if err != nil {
+		return fmt.Errorf("CreateSafeMultisendTransaction: %v", err)
+	}
+
+	// The outer transaction always delegatecalls MultiSend without value
+	if multisend.Value != "0" {
+		return fmt.Errorf("outer value = %q, want \"0\"", multisend.Value)
+	}
+	if multisend.Operation != models.DelegateCall {
+		return fmt.Errorf("outer operation = %d, want DelegateCall", multisend.Operation)
+	}
+	if multisend.To != testMultisend {
+		return fmt.Errorf("outer to = %s, want %s", multisend.To, testMultisend)
+	}
+
+	callData := hexutil.MustDecode(multisend.Data)
+	if len(callData)%32 != 4 {
+		return fmt.Errorf("calldata length %d is not selector + 32-byte words", len(callData))
+	}
+
+	// Lossless round trip, in order
+	decoded, err := DecodeMultiSendCallData(callData)
+	if err != nil {
+		return fmt.Errorf("DecodeMultiSendCallData: %v", err)
+	}
+	if len(decoded) != len(batch) {
+		return fmt.Errorf("decoded %d transactions, want %d", len(decoded), len(batch))
+	}
+	for i, want := range batch {
+		got := decoded[i]
+		if common.HexToAddress(got.To) != common.HexToAddress(want.To) {
+			return fmt.Errorf("transaction %d: to = %s, want %s", i, got.To, want.To)
+		}
+		if gotValue := wantValue(got.Value); gotValue.Cmp(wantValue(want.Value)) != 0 {
+			return fmt.Errorf("transaction %d: value = %s, want %s (%q)", i, gotValue, wantValue(want.Value), want.Value)
+		}
+		if !bytes.Equal(wantData(got.Data), wantData(want.Data)) {
+			return fmt.Errorf("transaction %d: data = %s, want %s", i, got.Data, want.Data)
+		}
+		if got.Operation != want.Operation {
+			return fmt.Errorf("transaction %d: operation = %d, want %d", i, got.Operation, want.Operation)
+		}
+	}
+
+	// The packed data has the analytic length sum(85 + len(data_i)) and is
+	// the concatenation of the transactions encoded one by one
+	encoded, err := EncodeMultiSendData(batch)
+	if err != nil {
+		return fmt.Errorf("EncodeMultiSendData: %v", err)
+	}
+	wantLength := 0
+	var perTransaction []byte
+	for _, txn := range batch {
+		wantLength += 85 + len(wantData(txn.Data))
+		single, err := EncodeMultiSendData([]models.SafeTransaction{txn})
+		if err != nil {
+			return fmt.Errorf("EncodeMultiSendData: %v", err)
+		}
+		perTransaction = append(perTransaction, single...)
+	}
+	if len(encoded) != wantLength {
+		return fmt.Errorf("encoded length = %d, want %d", len(encoded), wantLength)
+	}
+	if !bytes.Equal(encoded, perTransaction) {
+		return fmt.Errorf("batch encoding differs from the per-transaction encodings")
+	}
+	if !bytes.Equal(callData[4+64:4+64+len(encoded)], encoded) {
+		return fmt.Errorf("calldata does not embed EncodeMultiSendData output")
+	}
+	return nil
+}
+
+func TestMultisend_Properties(t *testing.T) {
+	seeds := append([]int64(nil), multisendRegressionSeeds...)
+	for i := 0; i < *multisendRuns; i++ {
+		seeds = append(seeds, *multisendSeed+int64(i))
+	}
+
+	for _, seed := range seeds {
+		if err := checkMultisendBatch(genMultisendBatch(seed)); err != nil {
+			t.Fatalf("seed %d: %v (reproduce with -multisend.seed=%d -multisend.runs=1)", seed, err, seed)
+		}
+	}
+}
+
+func TestMultisend_PropertyRegressions(t *testing.T) {
+	transfer := "0xa9059cbb0000000000000000000000004d97dcd97ec945f40cf65f87097ace5ea0476045"
+
+	tests := []struct {
+		name  string
+		batch []models.SafeTransaction
+	}{
+		{
+			name: "decimal value with leading zero",
+			batch: []models.SafeTransaction{
+				{To: testMultisend, Value: "010", Data: "0x"},
+				{To: testMultisend, Value: "0777", Data: transfer},
+			},
+		},
+		{
+			name: "empty data spellings",
+			batch: []models.SafeTransaction{
+				{To: testMultisend, Value: "1", Data: ""},
+				{To: testMultisend, Value: "2", Data: "0x", Operation: models.DelegateCall},
+			},
+		},
+		{
+			name: "value radixes",
+			batch: []models.SafeTransaction{
+				{To: testMultisend, Value: "0x1f"},
+				{To: testMultisend, Value: "0X1F"},
+				{To: testMultisend, Value: "31"},
+			},
+		},
+		{
+			name: "max uint256 value",
+			batch: []models.SafeTransaction{
+				{To: testMultisend, Value: maxUint256.String()},
+				{To: testMultisend, Value: "0x" + maxUint256.Text(16)},
+			},
+		},
+		{
+			name: "data lengths around the word size",
+			batch: []models.SafeTransaction{
+				{To: testMultisend, Data: "0x01"},
+				{To: testMultisend, Data: hexutil.Encode(make([]byte, 32))},
+				{To: testMultisend, Data: hexutil.Encode(make([]byte, 33))},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkMultisendBatch(tt.batch); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestParseTransactionValue(t *testing.T) {
+	tests := []struct {
+		raw       string
+		want      string
+		shouldErr bool
+	}{
+		{raw: "", want: "0"},
+		{raw: "0", want: "0"},
+		{raw: "010", want: "10"},
+		{raw: "0x10", want: "16"},
+		{raw: "0X10", want: "16"},
+		{raw: maxUint256.String(), want: maxUint256.String()},
+		{raw: new(big.Int).Add(maxUint256, big.NewInt(1)).String(), shouldErr: true},
+		{raw: "-1", shouldErr: true},
+		{raw: "0x", shouldErr: true},
+		{raw: "0b101", shouldErr: true},
+		{raw: "1_000", shouldErr: true},
+		{raw: "1e18", shouldErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got, err := parseTransactionValue(tt.raw)
+			if (err != nil) != tt.shouldErr {
+				t.Fatalf("parseTransactionValue(%q) error = %v, shouldErr %v", tt.raw, err, tt.shouldErr)
+			}
+			if !tt.shouldErr && got.String() != tt.want {
+				t.Errorf("parseTransactionValue(%q) = %s, want %s", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeMultiSendCallData_Malformed(t *testing.T) {
+	multisend, err := CreateSafeMultisendTransaction(testSafeTransactions(), testMultisend)
+	if err != nil {
+		t.Fatalf("CreateSafeMultisendTransaction failed: %v", err)
+	}
+	callData := hexutil.MustDecode(multisend.Data)
+
+	// Inner data: one transaction header claiming more data than remains
+	truncated := make([]byte, 85)
+	truncated[84] = 0xff
+
+	tests := []struct {
+		name     string
+		callData []byte
+	}{
+		{name: "wrong selector", callData: append([]byte{0, 0, 0, 0}, callData[4:]...)},
+		{name: "too short", callData: callData[:40]},
+		{name: "length beyond calldata", callData: callData[:4+64+10]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecodeMultiSendCallData(tt.callData); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+
+	if _, err := DecodeMultiSendData(truncated); err == nil {
+		t.Error("DecodeMultiSendData should reject a data length beyond the input")
+	}
+	if _, err := DecodeMultiSendData(callData[4+64 : 4+64+50]); err == nil {
+		t.Error("DecodeMultiSendData should reject a truncated transaction")
+	}
+}
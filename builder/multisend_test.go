@@ -0,0 +1,298 @@
+package builder
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/davidt58/go-builder-relayer-client/config"
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/models"
+	"github.com/davidt58/go-builder-relayer-client/signer"
+)
+
+const (
+	testMultisend         = "0xA238CBeb142c10Ef7Ad8442C6D1f9E89e07e7761"
+	testMultisendCallOnly = "0x40A2aCCbd92BCA938b02010E17A5b8929b49130D"
+)
+
+// batchWithOperations returns one transfer per operation
+func batchWithOperations(operations ...models.OperationType) []models.SafeTransaction {
+	txns := make([]models.SafeTransaction, len(operations))
+	for i, operation := range operations {
+		txns[i] = testSafeTransactions()[0]
+		txns[i].Operation = operation
+	}
+	return txns
+}
+
+func TestCreateSafeMultisendTransactionWithVariant(t *testing.T) {
+	tests := []struct {
+		name         string
+		transactions []models.SafeTransaction
+		variant      config.MultisendVariant
+		errContains  string
+		shouldErr    bool
+	}{
+		{name: "standard allows delegatecall", transactions: batchWithOperations(models.Call, models.DelegateCall)},
+		{name: "unset variant is standard", transactions: batchWithOperations(models.DelegateCall, models.DelegateCall), variant: ""},
+		{name: "call-only with calls", transactions: batchWithOperations(models.Call, models.Call, models.Call), variant: config.MultisendCallOnly},
+		{
+			name:         "call-only lists delegatecall indices",
+			transactions: batchWithOperations(models.Call, models.DelegateCall, models.Call, models.DelegateCall),
+			variant:      config.MultisendCallOnly,
+			errContains:  "[1 3]",
+			shouldErr:    true,
+		},
+		{name: "unknown variant", transactions: batchWithOperations(models.Call, models.Call), variant: "delegate_only", shouldErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			txn, err := CreateSafeMultisendTransactionWithVariant(tt.transactions, testMultisend, tt.variant)
+			if tt.shouldErr {
+				if err == nil {
+					t.Fatal("Expected error")
+				}
+				if !strings.Contains(err.Error(), tt.errContains) {
+					t.Errorf("error %q does not contain %q", err.Error(), tt.errContains)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateSafeMultisendTransactionWithVariant failed: %v", err)
+			}
+			if txn.Operation != models.DelegateCall {
+				t.Errorf("outer Operation = %d, want DelegateCall", txn.Operation)
+			}
+		})
+	}
+}
+
+func TestCreateSafeMultisendTransaction_CallOnlyEquivalence(t *testing.T) {
+	transactions := batchWithOperations(models.Call, models.Call)
+
+	standard, err := CreateSafeMultisendTransaction(transactions, testMultisend)
+	if err != nil {
+		t.Fatalf("standard encoding failed: %v", err)
+	}
+	callOnly, err := CreateSafeMultisendTransactionWithVariant(transactions, testMultisendCallOnly, config.MultisendCallOnly)
+	if err != nil {
+		t.Fatalf("call-only encoding failed: %v", err)
+	}
+
+	// Both contracts share multiSend(bytes) and the packed encoding
+	if callOnly.Data != standard.Data {
+		t.Errorf("call-only calldata differs from the standard encoder:\n got %s\nwant %s", callOnly.Data, standard.Data)
+	}
+	if callOnly.To != testMultisendCallOnly {
+		t.Errorf("To = %s, want %s", callOnly.To, testMultisendCallOnly)
+	}
+	if callOnly.Operation != models.DelegateCall {
+		t.Errorf("outer Operation = %d, want DelegateCall", callOnly.Operation)
+	}
+
+	// selector ++ offset ++ length ++ packed transactions ++ padding
+	data := hexutil.MustDecode(callOnly.Data)
+	length := new(big.Int).SetBytes(data[36:68]).Int64()
+	inner := data[68 : 68+length]
+	decoded, err := DecodeMultiSendData(inner)
+	if err != nil {
+		t.Fatalf("DecodeMultiSendData failed: %v", err)
+	}
+	if len(decoded) != len(transactions) {
+		t.Fatalf("decoded %d transactions, want %d", len(decoded), len(transactions))
+	}
+}
+
+func TestCreateSafeMultisendTransaction_InfersCallOnly(t *testing.T) {
+	// The registered Polygon MultiSendCallOnly address selects the call-only checks
+	_, err := CreateSafeMultisendTransaction(batchWithOperations(models.Call, models.DelegateCall), testMultisendCallOnly)
+	if err == nil {
+		t.Fatal("Expected DelegateCall to be rejected for MultiSendCallOnly")
+	}
+}
+
+func TestBuildSafeTransactionRequestWithVariant(t *testing.T) {
+	sig, err := signer.NewSigner("ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80", 137)
+	if err != nil {
+		t.Fatalf("Failed to create signer: %v", err)
+	}
+	polygon, err := config.GetContractConfig(137)
+	if err != nil {
+		t.Fatalf("GetContractConfig failed: %v", err)
+	}
+	amoy, err := config.GetContractConfig(80002)
+	if err != nil {
+		t.Fatalf("GetContractConfig failed: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		contractConfig *config.ContractConfig
+		transactions   []models.SafeTransaction
+		variant        config.MultisendVariant
+		wantTo         string
+		shouldErr      bool
+	}{
+		{name: "standard batch", contractConfig: polygon, transactions: batchWithOperations(models.Call, models.DelegateCall), wantTo: testMultisend},
+		{name: "call-only batch", contractConfig: polygon, transactions: batchWithOperations(models.Call, models.Call), variant: config.MultisendCallOnly, wantTo: testMultisendCallOnly},
+		{name: "call-only single call", contractConfig: polygon, transactions: batchWithOperations(models.Call), variant: config.MultisendCallOnly, wantTo: testSafeTransactions()[0].To},
+		{name: "call-only single delegatecall", contractConfig: polygon, transactions: batchWithOperations(models.DelegateCall), variant: config.MultisendCallOnly, shouldErr: true},
+		{name: "call-only batch with delegatecall", contractConfig: polygon, transactions: batchWithOperations(models.DelegateCall, models.Call), variant: config.MultisendCallOnly, shouldErr: true},
+		{name: "call-only not configured", contractConfig: amoy, transactions: batchWithOperations(models.Call, models.Call), variant: config.MultisendCallOnly, shouldErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := &models.SafeTransactionArgs{
+				SafeAddress:  "0x6e0c80c90ea6c15917308F820Eac91Ce2724B5b5",
+				Transactions: tt.transactions,
+				Nonce:        "0",
+			}
+
+			request, err := BuildSafeTransactionRequestWithVariant(args, sig, tt.contractConfig, tt.variant)
+			if tt.shouldErr {
+				if err == nil {
+					t.Fatal("Expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("BuildSafeTransactionRequestWithVariant failed: %v", err)
+			}
+			if to := strings.Trim(string(request.To), `"`); to != tt.wantTo {
+				t.Errorf("To = %s, want %s", to, tt.wantTo)
+			}
+		})
+	}
+}
+
+func TestDecodedTransactions_Fixtures(t *testing.T) {
+	transfer := "0xa9059cbb0000000000000000000000004d97dcd97ec945f40cf65f87097ace5ea04760450000000000000000000000000000000000000000000000000000000000000064"
+	batch := []models.SafeTransaction{
+		{To: "0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174", Value: "0", Data: transfer, Operation: models.Call},
+		{To: "0x4D97DCd97eC945f40cF65F87097ACe5EA0476045", Value: "1000", Data: "0x", Operation: models.Call},
+	}
+
+	tests := []struct {
+		name          string
+		fixture       string
+		want          []models.SafeTransaction
+		wantEcho      int
+		wantSignature bool
+		wantErr       bool
+	}{
+		{name: "multisend echo", fixture: "relayer_transaction_multisend.json", want: batch, wantEcho: 1, wantSignature: true},
+		{name: "array echo", fixture: "relayer_transaction_arrays.json", want: batch, wantEcho: 2},
+		{name: "no echo", fixture: "relayer_transaction_no_echo.json", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := os.ReadFile(filepath.Join("testdata", tt.fixture))
+			if err != nil {
+				t.Fatalf("ReadFile failed: %v", err)
+			}
+			var response []models.RelayerTransaction
+			if err := json.Unmarshal(body, &response); err != nil {
+				t.Fatalf("decoding the fixture failed: %v", err)
+			}
+			txn := &response[0]
+			if txn.State == "" || txn.TransactionID == "" {
+				t.Fatalf("fixture decoded without its state: %+v", txn)
+			}
+
+			echoed, err := txn.RequestTransactions()
+			if err != nil || len(echoed) != tt.wantEcho {
+				t.Fatalf("RequestTransactions() = %+v, %v; want %d calls", echoed, err, tt.wantEcho)
+			}
+			if (txn.Signature != nil) != tt.wantSignature {
+				t.Errorf("Signature = %v, want echoed: %v", txn.Signature, tt.wantSignature)
+			}
+
+			decoded, err := DecodedTransactions(txn)
+			if tt.wantErr {
+				var unavailable *errors.OriginalPayloadUnavailableError
+				if !stderrors.As(err, &unavailable) || unavailable.TransactionID != txn.TransactionID {
+					t.Fatalf("error = %v, want OriginalPayloadUnavailableError for %s", err, txn.TransactionID)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DecodedTransactions failed: %v", err)
+			}
+			if !reflect.DeepEqual(decoded, tt.want) {
+				t.Errorf("DecodedTransactions() = %+v\nwant %+v", decoded, tt.want)
+			}
+		})
+	}
+}
+
+func TestWrapMultiSendPayload_MatchesCreate(t *testing.T) {
+	batches := [][]models.SafeTransaction{testSafeTransactions(), batchWithOperations(models.Call, models.DelegateCall)}
+	for seed := int64(1); seed <= 50; seed++ {
+		batches = append(batches, genMultisendBatch(seed))
+	}
+
+	for i, batch := range batches {
+		payload, err := EncodeMultiSendData(batch)
+		if err != nil {
+			t.Fatalf("batch %d: EncodeMultiSendData failed: %v", i, err)
+		}
+		wrapped, err := WrapMultiSendPayload(payload, testMultisend)
+		if err != nil {
+			t.Fatalf("batch %d: WrapMultiSendPayload failed: %v", i, err)
+		}
+		created, err := CreateSafeMultisendTransaction(batch, testMultisend)
+		if err != nil {
+			t.Fatalf("batch %d: CreateSafeMultisendTransaction failed: %v", i, err)
+		}
+		if !reflect.DeepEqual(wrapped, created) {
+			t.Errorf("batch %d: wrapped payload differs from CreateSafeMultisendTransaction:\n got %+v\nwant %+v", i, wrapped, created)
+		}
+		if count, err := ValidateMultiSendPayload(payload); err != nil || count != len(batch) {
+			t.Errorf("batch %d: ValidateMultiSendPayload = %d, %v, want %d", i, count, err, len(batch))
+		}
+	}
+}
+
+func TestWrapMultiSendPayload_Invalid(t *testing.T) {
+	payload, err := EncodeMultiSendData(batchWithOperations(models.Call, models.DelegateCall))
+	if err != nil {
+		t.Fatalf("EncodeMultiSendData failed: %v", err)
+	}
+	unknownOperation := append([]byte(nil), payload...)
+	unknownOperation[0] = 2
+
+	tests := []struct {
+		name      string
+		payload   []byte
+		multisend string
+	}{
+		{name: "empty payload", payload: nil, multisend: testMultisend},
+		{name: "truncated payload", payload: payload[:len(payload)-1], multisend: testMultisend},
+		{name: "trailing bytes", payload: append(append([]byte(nil), payload...), 0), multisend: testMultisend},
+		{name: "unknown operation", payload: unknownOperation, multisend: testMultisend},
+		{name: "delegatecall through call-only", payload: payload, multisend: testMultisendCallOnly},
+		{name: "invalid multisend address", payload: payload, multisend: "multisend"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := WrapMultiSendPayload(tt.payload, tt.multisend); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+	if _, err := ValidateMultiSendPayload(unknownOperation); err == nil {
+		t.Error("ValidateMultiSendPayload should reject an unknown operation")
+	}
+}
@@ -0,0 +1,203 @@
+package builder
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/models"
+	"github.com/davidt58/go-builder-relayer-client/signer"
+)
+
+// PreSignedTx is a Safe transaction signed ahead of submission
+type PreSignedTx struct {
+	// Nonce is the Safe nonce the transaction was signed with
+	Nonce *big.Int
+	// StructHash is the EIP-712 SafeTx hash that was signed
+	StructHash common.Hash
+	// Signature is the packed signature (v 31/32)
+	Signature string
+	// Request is the SAFE request, ready for client.SubmitSignedRequest
+	Request *models.TransactionRequest
+}
+
+var (
+	// safeTxTypeHash is keccak256 of the SafeTx type string
+	safeTxTypeHash = GetSafeTxTypeHash()
+	// safeDomainTypeHash is keccak256 of Safe's EIP712Domain type string
+	safeDomainTypeHash = crypto.Keccak256Hash([]byte("EIP712Domain(uint256 chainId,address verifyingContract)"))
+
+	// safeDomainSeparators caches Safe domain separators by safeDomainKey
+	safeDomainSeparators sync.Map
+)
+
+// safeDomainKey identifies a Safe EIP-712 domain
+type safeDomainKey struct {
+	chainID int64
+	safe    common.Address
+}
+
+// PreSignBatch signs one Safe transaction per element of transactions, with
+// nonces startNonce, startNonce+1, ... Elements with more than one
+// transaction are batched through multisend. The requests are identical to
+// those BuildSafeTransactionRequestWithMultisend builds for the same nonce;
+// the type hash and domain separator are computed once for the batch.
+func PreSignBatch(safeAddress string, startNonce *big.Int, transactions [][]models.SafeTransaction, sig *signer.Signer, chainID int64, multisend string) ([]PreSignedTx, error) {
+	if sig == nil {
+		return nil, errors.ErrSignerNotConfigured
+	}
+	if !common.IsHexAddress(safeAddress) {
+		return nil, errors.ErrInvalidAddress(safeAddress)
+	}
+	if startNonce == nil || startNonce.Sign() < 0 {
+		return nil, errors.ErrMissingRequiredField("startNonce")
+	}
+	if err := checkSignerChainID(sig, chainID); err != nil {
+		return nil, err
+	}
+
+	domainSeparator := safeDomainSeparator(chainID, common.HexToAddress(safeAddress))
+	from := sig.AddressHex()
+
+	signed := make([]PreSignedTx, len(transactions))
+	for i, txns := range transactions {
+		nonce := new(big.Int).Add(startNonce, big.NewInt(int64(i)))
+
+		item, err := preSign(safeAddress, nonce, txns, sig, from, multisend, domainSeparator)
+		if err != nil {
+			return nil, errors.NewRelayerClientError(fmt.Sprintf("pre-sign item %d (nonce %s) failed", i, nonce), err)
+		}
+		signed[i] = *item
+	}
+	return signed, nil
+}
+
+// preSign signs one batch item against a precomputed domain separator
+func preSign(safeAddress string, nonce *big.Int, transactions []models.SafeTransaction, sig *signer.Signer, from, multisend string, domainSeparator common.Hash) (*PreSignedTx, error) {
+	if len(transactions) == 0 {
+		return nil, errors.NewRelayerClientError("no transactions provided", nil)
+	}
+
+	args := &models.SafeTransactionArgs{
+		SafeAddress:  safeAddress,
+		Transactions: transactions,
+		Nonce:        nonce.String(),
+	}
+	if len(transactions) > 1 {
+		multiSendTxn, err := AggregateSafeTransaction(transactions, multisend)
+		if err != nil {
+			return nil, err
+		}
+		args.Transactions = []models.SafeTransaction{*multiSendTxn}
+	}
+
+	safeTx, err := newSafeTx(args)
+	if err != nil {
+		return nil, err
+	}
+	structHash, err := hashSafeTx(safeTx, domainSeparator)
+	if err != nil {
+		return nil, err
+	}
+
+	packedSig, err := sig.SignSafeTxHash(structHash.Bytes(), signer.DefaultSignatureScheme)
+	if err != nil {
+		return nil, errors.ErrStaged(errors.StageSign, err)
+	}
+
+	request, err := assembleSafeTransactionRequest(args, from, packedSig, signer.DefaultSignatureScheme.SignatureType())
+	if err != nil {
+		return nil, err
+	}
+
+	return &PreSignedTx{
+		Nonce:      nonce,
+		StructHash: structHash,
+		Signature:  packedSig,
+		Request:    request,
+	}, nil
+}
+
+// safeDomainSeparator returns the cached EIP-712 domain separator of the
+// Safe at verifyingContract on chainID
+func safeDomainSeparator(chainID int64, verifyingContract common.Address) common.Hash {
+	key := safeDomainKey{chainID: chainID, safe: verifyingContract}
+	if cached, ok := safeDomainSeparators.Load(key); ok {
+		return cached.(common.Hash)
+	}
+
+	data := make([]byte, 0, 3*32)
+	data = append(data, safeDomainTypeHash[:]...)
+	data = append(data, common.BigToHash(big.NewInt(chainID)).Bytes()...)
+	data = append(data, common.BytesToHash(verifyingContract.Bytes()).Bytes()...)
+	separator := crypto.Keccak256Hash(data)
+
+	safeDomainSeparators.Store(key, separator)
+	return separator
+}
+
+// hashSafeTx computes the EIP-712 hash of safeTx under domainSeparator by
+// encoding the SafeTx struct directly
+func hashSafeTx(safeTx *SafeTx, domainSeparator common.Hash) (common.Hash, error) {
+	return hashSafeTxWithType(safeTx, safeTxTypeHash, domainSeparator)
+}
+
+// hashSafeTxWithType is hashSafeTx with the SafeTx type hash of a given Safe
+// version; the field encoding is the same for every version
+func hashSafeTxWithType(safeTx *SafeTx, typeHash, domainSeparator common.Hash) (common.Hash, error) {
+	data := make([]byte, 0, 11*32)
+	data = append(data, typeHash[:]...)
+	data = append(data, common.BytesToHash(safeTx.To.Bytes()).Bytes()...)
+
+	value, err := uint256Word("value", safeTx.Value)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	data = append(data, value...)
+	data = append(data, crypto.Keccak256(safeTx.Data)...)
+	data = append(data, common.BigToHash(big.NewInt(int64(safeTx.Operation))).Bytes()...)
+
+	for _, field := range []struct {
+		name  string
+		value *big.Int
+	}{
+		{"safeTxGas", safeTx.SafeTxGas},
+		{"baseGas", safeTx.BaseGas},
+		{"gasPrice", safeTx.GasPrice},
+	} {
+		word, err := uint256Word(field.name, field.value)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		data = append(data, word...)
+	}
+
+	data = append(data, common.BytesToHash(safeTx.GasToken.Bytes()).Bytes()...)
+	data = append(data, common.BytesToHash(safeTx.RefundReceiver.Bytes()).Bytes()...)
+
+	nonce, err := uint256Word("nonce", safeTx.Nonce)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	data = append(data, nonce...)
+
+	structHash := crypto.Keccak256(data)
+	return crypto.Keccak256Hash([]byte{0x19, 0x01}, domainSeparator[:], structHash), nil
+}
+
+// uint256Word encodes value as a 32-byte big-endian word
+func uint256Word(name string, value *big.Int) ([]byte, error) {
+	if value == nil {
+		return make([]byte, 32), nil
+	}
+	if value.Sign() < 0 || value.BitLen() > 256 {
+		return nil, errors.NewRelayerClientError(fmt.Sprintf("%s %s is out of uint256 range", name, value), nil)
+	}
+	word := make([]byte, 32)
+	value.FillBytes(word)
+	return word, nil
+}
@@ -0,0 +1,226 @@
+package builder
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/davidt58/go-builder-relayer-client/models"
+	"github.com/davidt58/go-builder-relayer-client/signer"
+)
+
+// preSignItems returns a mix of single, gas-limited, delegatecall and batched items
+func preSignItems() [][]models.SafeTransaction {
+	transfer := testSafeTransactions()[0]
+	withGas := transfer
+	withGas.GasLimit = "120000"
+	delegateCall := transfer
+	delegateCall.Operation = models.DelegateCall
+
+	return [][]models.SafeTransaction{
+		{transfer},
+		{transfer, transfer},
+		{withGas},
+		{delegateCall},
+		{transfer, delegateCall, transfer},
+	}
+}
+
+func newPreSignSigner(t testing.TB) *signer.Signer {
+	t.Helper()
+	sig, err := signer.NewSigner("ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80", 137)
+	if err != nil {
+		t.Fatalf("Failed to create signer: %v", err)
+	}
+	return sig
+}
+
+func TestHashSafeTx_MatchesTypedData(t *testing.T) {
+	safe := common.HexToAddress("0x6e0c80c90ea6c15917308F820Eac91Ce2724B5b5")
+	maxUint256 := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+
+	tests := []struct {
+		name   string
+		safeTx *SafeTx
+	}{
+		{
+			name: "empty data",
+			safeTx: &SafeTx{
+				To: common.HexToAddress("0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174"), Value: big.NewInt(0),
+				SafeTxGas: big.NewInt(0), BaseGas: big.NewInt(0), GasPrice: big.NewInt(0), Nonce: big.NewInt(0),
+			},
+		},
+		{
+			name: "all fields set",
+			safeTx: &SafeTx{
+				To: common.HexToAddress("0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174"), Value: maxUint256,
+				Data: []byte{0xa9, 0x05, 0x9c, 0xbb, 0x01}, Operation: 1,
+				SafeTxGas: big.NewInt(120000), BaseGas: big.NewInt(21000), GasPrice: big.NewInt(30e9),
+				GasToken:       common.HexToAddress("0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174"),
+				RefundReceiver: common.HexToAddress("0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266"),
+				Nonce:          big.NewInt(1 << 40),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, chainID := range []int64{137, 80002} {
+				want, err := BuildSafeTxHash(tt.safeTx, safe, chainID)
+				if err != nil {
+					t.Fatalf("BuildSafeTxHash failed: %v", err)
+				}
+				got, err := hashSafeTx(tt.safeTx, safeDomainSeparator(chainID, safe))
+				if err != nil {
+					t.Fatalf("hashSafeTx failed: %v", err)
+				}
+				if got != want {
+					t.Errorf("chain %d: hashSafeTx = %s, want %s", chainID, got.Hex(), want.Hex())
+				}
+			}
+		})
+	}
+}
+
+func TestPreSignBatch_MatchesLiveBuild(t *testing.T) {
+	sig := newPreSignSigner(t)
+	safeAddress := "0x6e0c80c90ea6c15917308F820Eac91Ce2724B5b5"
+	startNonce := big.NewInt(40)
+	items := preSignItems()
+
+	signed, err := PreSignBatch(safeAddress, startNonce, items, sig, 137, testMultisend)
+	if err != nil {
+		t.Fatalf("PreSignBatch failed: %v", err)
+	}
+	if len(signed) != len(items) {
+		t.Fatalf("PreSignBatch returned %d items, want %d", len(signed), len(items))
+	}
+
+	for k, item := range signed {
+		nonce := new(big.Int).Add(startNonce, big.NewInt(int64(k)))
+		if item.Nonce.Cmp(nonce) != 0 {
+			t.Errorf("item %d: Nonce = %s, want %s", k, item.Nonce, nonce)
+		}
+
+		args := &models.SafeTransactionArgs{SafeAddress: safeAddress, Transactions: items[k], Nonce: nonce.String()}
+		live, err := BuildSafeTransactionRequestWithMultisend(args, sig, 137, testMultisend)
+		if err != nil {
+			t.Fatalf("item %d: live build failed: %v", k, err)
+		}
+
+		got, _ := json.Marshal(item.Request)
+		want, _ := json.Marshal(live)
+		if string(got) != string(want) {
+			t.Errorf("item %d: pre-signed request differs from live build:\n got %s\nwant %s", k, got, want)
+		}
+		if item.Signature != live.Signature {
+			t.Errorf("item %d: Signature = %s, want %s", k, item.Signature, live.Signature)
+		}
+
+		if got := safeCheckSignature(t, item.StructHash, item.Signature); got != sig.Address() {
+			t.Errorf("item %d: signature does not recover the signer over StructHash", k)
+		}
+	}
+}
+
+func TestPreSignBatch_Errors(t *testing.T) {
+	sig := newPreSignSigner(t)
+	safeAddress := "0x6e0c80c90ea6c15917308F820Eac91Ce2724B5b5"
+	delegateCall := testSafeTransactions()[0]
+	delegateCall.Operation = models.DelegateCall
+
+	tests := []struct {
+		name         string
+		safeAddress  string
+		startNonce   *big.Int
+		transactions [][]models.SafeTransaction
+		sig          *signer.Signer
+		chainID      int64
+		multisend    string
+		errContains  string
+	}{
+		{name: "nil signer", safeAddress: safeAddress, startNonce: big.NewInt(0), chainID: 137},
+		{name: "invalid safe", safeAddress: "0x1234", startNonce: big.NewInt(0), sig: sig, chainID: 137},
+		{name: "nil nonce", safeAddress: safeAddress, sig: sig, chainID: 137},
+		{name: "negative nonce", safeAddress: safeAddress, startNonce: big.NewInt(-1), sig: sig, chainID: 137},
+		{name: "chain mismatch", safeAddress: safeAddress, startNonce: big.NewInt(0), sig: sig, chainID: 80002},
+		{
+			name:         "empty item",
+			safeAddress:  safeAddress,
+			startNonce:   big.NewInt(7),
+			transactions: [][]models.SafeTransaction{testSafeTransactions(), {}},
+			sig:          sig,
+			chainID:      137,
+			errContains:  "item 1 (nonce 8)",
+		},
+		{
+			name:         "call-only multisend with delegatecall",
+			safeAddress:  safeAddress,
+			startNonce:   big.NewInt(0),
+			transactions: [][]models.SafeTransaction{{testSafeTransactions()[0], delegateCall}},
+			sig:          sig,
+			chainID:      137,
+			multisend:    testMultisendCallOnly,
+			errContains:  "item 0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			multisend := tt.multisend
+			if multisend == "" {
+				multisend = testMultisend
+			}
+			_, err := PreSignBatch(tt.safeAddress, tt.startNonce, tt.transactions, tt.sig, tt.chainID, multisend)
+			if err == nil {
+				t.Fatal("Expected error")
+			}
+			if !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("error %q does not contain %q", err.Error(), tt.errContains)
+			}
+		})
+	}
+}
+
+// benchmarkItems returns n single-transfer items
+func benchmarkItems(n int) [][]models.SafeTransaction {
+	items := make([][]models.SafeTransaction, n)
+	for i := range items {
+		items[i] = testSafeTransactions()
+	}
+	return items
+}
+
+func BenchmarkPreSignBatch(b *testing.B) {
+	sig := newPreSignSigner(b)
+	items := benchmarkItems(100)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := PreSignBatch("0x6e0c80c90ea6c15917308F820Eac91Ce2724B5b5", big.NewInt(0), items, sig, 137, testMultisend); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkBuildSafeTransactionRequestLoop(b *testing.B) {
+	sig := newPreSignSigner(b)
+	items := benchmarkItems(100)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for k, txns := range items {
+			args := &models.SafeTransactionArgs{
+				SafeAddress:  "0x6e0c80c90ea6c15917308F820Eac91Ce2724B5b5",
+				Transactions: txns,
+				Nonce:        big.NewInt(int64(k)).String(),
+			}
+			if _, err := BuildSafeTransactionRequestWithMultisend(args, sig, 137, testMultisend); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
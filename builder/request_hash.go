@@ -0,0 +1,125 @@
+package builder
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/models"
+	"github.com/davidt58/go-builder-relayer-client/signer"
+)
+
+// SafeRequestHash recomputes the SafeTx hash the signature of a SAFE
+// request covers, from the request fields the relayer rebuilds it from, for
+// a Safe of the given version ("" for CurrentSafeVersion)
+func SafeRequestHash(request *models.TransactionRequest, chainID int64, version string) (common.Hash, error) {
+	safeTx, err := safeTxFromRequest(request)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if !common.IsHexAddress(request.ProxyWallet) {
+		return common.Hash{}, errors.ErrInvalidAddress(request.ProxyWallet)
+	}
+	return safeTxHashFor(safeTx, common.HexToAddress(request.ProxyWallet), chainID, version)
+}
+
+// RecoverRequestSigner recovers the signer of a request's signature over
+// hash, as declared by its SignatureType
+func RecoverRequestSigner(request *models.TransactionRequest, hash common.Hash) (common.Address, error) {
+	if request == nil {
+		return common.Address{}, errors.ErrMissingRequiredField("request")
+	}
+	signature, err := hexutil.Decode(request.Signature)
+	if err != nil {
+		return common.Address{}, errors.ErrInvalidSignature(err)
+	}
+	return signer.RecoverTypedSignature(hash.Bytes(), signature, request.SignatureType)
+}
+
+// safeTxFromRequest rebuilds the SafeTx of a single-call SAFE request
+func safeTxFromRequest(request *models.TransactionRequest) (*SafeTx, error) {
+	if request == nil {
+		return nil, errors.ErrMissingRequiredField("request")
+	}
+	if request.Type != string(models.SAFE) {
+		return nil, errors.NewRelayerClientError(fmt.Sprintf("%s requests do not carry a SafeTx signature", request.Type), nil)
+	}
+	params := request.SignatureParams
+	if params == nil {
+		return nil, errors.ErrMissingRequiredField("signatureParams")
+	}
+
+	var to, data string
+	if err := json.Unmarshal(request.To, &to); err != nil || !common.IsHexAddress(to) {
+		return nil, errors.NewRelayerClientError(fmt.Sprintf("request to %s is not a single address", request.To), err)
+	}
+	if err := json.Unmarshal(request.Data, &data); err != nil {
+		return nil, errors.NewRelayerClientError(fmt.Sprintf("request data %s is not a single value", request.Data), err)
+	}
+	callData, err := hexutil.Decode(data)
+	if err != nil {
+		return nil, errors.NewRelayerClientError("failed to decode transaction data", err)
+	}
+	value := "0"
+	if len(request.Value) > 0 {
+		if err := json.Unmarshal(request.Value, &value); err != nil {
+			return nil, errors.NewRelayerClientError(fmt.Sprintf("request value %s is not a single value", request.Value), err)
+		}
+	}
+
+	safeTx := &SafeTx{To: common.HexToAddress(to), Data: callData}
+	for _, field := range []struct {
+		name  string
+		value *string
+		into  **big.Int
+	}{
+		{"value", &value, &safeTx.Value},
+		{"safeTxnGas", params.SafeTxGas, &safeTx.SafeTxGas},
+		{"baseGas", params.BaseGas, &safeTx.BaseGas},
+		{"gasPrice", params.GasPrice, &safeTx.GasPrice},
+		{"nonce", request.Nonce, &safeTx.Nonce},
+	} {
+		if field.value == nil {
+			return nil, errors.ErrMissingRequiredField(field.name)
+		}
+		parsed, ok := new(big.Int).SetString(*field.value, 0)
+		if !ok {
+			return nil, errors.NewRelayerClientError(fmt.Sprintf("request %s %q is not a number", field.name, *field.value), nil)
+		}
+		*field.into = parsed
+	}
+
+	if params.Operation == nil {
+		return nil, errors.ErrMissingRequiredField("operation")
+	}
+	switch *params.Operation {
+	case "0":
+		safeTx.Operation = uint8(models.Call)
+	case "1":
+		safeTx.Operation = uint8(models.DelegateCall)
+	default:
+		return nil, errors.NewRelayerClientError(fmt.Sprintf("request operation %q is neither 0 nor 1", *params.Operation), nil)
+	}
+
+	for _, field := range []struct {
+		name  string
+		value *string
+		into  *common.Address
+	}{
+		{"gasToken", params.GasToken, &safeTx.GasToken},
+		{"refundReceiver", params.RefundReceiver, &safeTx.RefundReceiver},
+	} {
+		if field.value == nil {
+			continue
+		}
+		if !common.IsHexAddress(*field.value) {
+			return nil, errors.ErrInvalidAddress(*field.value)
+		}
+		*field.into = common.HexToAddress(*field.value)
+	}
+	return safeTx, nil
+}
@@ -0,0 +1,122 @@
+package builder
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/davidt58/go-builder-relayer-client/config"
+	"github.com/davidt58/go-builder-relayer-client/models"
+	"github.com/davidt58/go-builder-relayer-client/signer"
+)
+
+func TestSafeRequestHash(t *testing.T) {
+	sig, err := signer.NewSigner("ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80", 137)
+	if err != nil {
+		t.Fatalf("NewSigner failed: %v", err)
+	}
+	contractConfig, err := config.GetContractConfig(137)
+	if err != nil {
+		t.Fatalf("GetContractConfig failed: %v", err)
+	}
+
+	batch := goldenSafeArgs()
+	batch.Transactions = append(batch.Transactions, batch.Transactions[0])
+	withGas := goldenSafeArgs()
+	withGas.Transactions[0].GasLimit = "90000"
+
+	tests := []struct {
+		name    string
+		args    *models.SafeTransactionArgs
+		version string
+	}{
+		{name: "single call", args: goldenSafeArgs()},
+		{name: "multisend batch", args: batch},
+		{name: "safeTxGas", args: withGas},
+		{name: "legacy Safe", args: goldenSafeArgs(), version: "1.1.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, err := BuildSafeTransactionRequestForSafeVersion(tt.args, sig, contractConfig, config.MultisendStandard, tt.version)
+			if err != nil {
+				t.Fatalf("BuildSafeTransactionRequestForSafeVersion failed: %v", err)
+			}
+
+			hash, err := SafeRequestHash(request, 137, tt.version)
+			if err != nil {
+				t.Fatalf("SafeRequestHash failed: %v", err)
+			}
+			recovered, err := RecoverRequestSigner(request, hash)
+			if err != nil {
+				t.Fatalf("RecoverRequestSigner failed: %v", err)
+			}
+			if recovered != sig.Address() {
+				t.Errorf("recovered %s, want the signer %s", recovered.Hex(), sig.AddressHex())
+			}
+
+			// A field changed after signing changes the hash and the recovered signer
+			nonce := "8"
+			request.Nonce = &nonce
+			tampered, err := SafeRequestHash(request, 137, tt.version)
+			if err != nil {
+				t.Fatalf("SafeRequestHash failed: %v", err)
+			}
+			if tampered == hash {
+				t.Error("hash should cover the nonce")
+			}
+			if recovered, _ := RecoverRequestSigner(request, tampered); recovered == sig.Address() {
+				t.Error("signature should not recover to the signer over another hash")
+			}
+		})
+	}
+}
+
+func TestSafeRequestHash_InvalidRequests(t *testing.T) {
+	operation, gas, nonce := "0", "0", "1"
+	valid := func() *models.TransactionRequest {
+		return &models.TransactionRequest{
+			Type:        string(models.SAFE),
+			To:          json.RawMessage(`"0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174"`),
+			Data:        json.RawMessage(`"0x"`),
+			ProxyWallet: "0x6e0c80c90ea6c15917308F820Eac91Ce2724B5b5",
+			Nonce:       &nonce,
+			SignatureParams: &models.SignatureParams{
+				Operation: &operation,
+				SafeTxGas: &gas,
+				BaseGas:   &gas,
+				GasPrice:  &gas,
+			},
+		}
+	}
+	with := func(modify func(*models.TransactionRequest)) *models.TransactionRequest {
+		request := valid()
+		modify(request)
+		return request
+	}
+	badOperation := "2"
+
+	tests := []struct {
+		name      string
+		request   *models.TransactionRequest
+		shouldErr bool
+	}{
+		{name: "valid", request: valid()},
+		{name: "nil", request: nil, shouldErr: true},
+		{name: "SAFE-CREATE", request: with(func(r *models.TransactionRequest) { r.Type = string(models.SAFE_CREATE) }), shouldErr: true},
+		{name: "no signature params", request: with(func(r *models.TransactionRequest) { r.SignatureParams = nil }), shouldErr: true},
+		{name: "array to", request: with(func(r *models.TransactionRequest) {
+			r.To = json.RawMessage(`["0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174"]`)
+		}), shouldErr: true},
+		{name: "no nonce", request: with(func(r *models.TransactionRequest) { r.Nonce = nil }), shouldErr: true},
+		{name: "bad operation", request: with(func(r *models.TransactionRequest) { r.SignatureParams.Operation = &badOperation }), shouldErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := SafeRequestHash(tt.request, 137, "")
+			if (err != nil) != tt.shouldErr {
+				t.Errorf("SafeRequestHash() error = %v, shouldErr %v", err, tt.shouldErr)
+			}
+		})
+	}
+}
@@ -2,10 +2,11 @@ package builder
 
 import (
 	"encoding/json"
-	"log"
+	"fmt"
 	"math/big"
 	"strings"
 
+	"github.com/davidt58/go-builder-relayer-client/config"
 	"github.com/davidt58/go-builder-relayer-client/constants"
 	"github.com/davidt58/go-builder-relayer-client/errors"
 	"github.com/davidt58/go-builder-relayer-client/models"
@@ -16,76 +17,62 @@ import (
 
 // SplitSignature splits a signature into r, s, v components
 // signatureHex should be a 65-byte hex string (with or without 0x prefix)
-// Returns r, s as hex strings with 0x prefix, and v as an integer
+// Returns r, s as hex strings with 0x prefix, and v mapped to Safe's eth_sign
+// values (0/27 -> 31, 1/28 -> 32)
+//
+// Deprecated: use signer.SplitSignatureForSafeEthSign, or signer.SplitSignatureRaw
+// for the unmodified v value.
 func SplitSignature(signatureHex string) (r, s string, v int, err error) {
-	// Remove 0x prefix if present
-	signatureHex = strings.TrimPrefix(signatureHex, "0x")
-
-	// Decode the signature
-	signature, err := hexutil.Decode("0x" + signatureHex)
-	if err != nil {
-		return "", "", 0, errors.ErrInvalidSignature(err)
-	}
-
-	if len(signature) != 65 {
-		return "", "", 0, errors.ErrInvalidSignature(errors.NewRelayerClientError("signature must be 65 bytes", nil))
-	}
-
-	// Extract r, s, v components
-	r = hexutil.Encode(signature[0:32])
-	s = hexutil.Encode(signature[32:64])
-	v = int(signature[64])
-
-	// Adjust v value: convert 27,28 or 0,1 to 31,32 for Safe
-	// Safe uses v = 31 or 32 for standard ECDSA signatures
-	if v == 27 || v == 0 {
-		v = 31
-	} else if v == 28 || v == 1 {
-		v = 32
-	} else if v != 31 && v != 32 {
-		// If v is already in a different format, try to normalize it
-		if v < 27 {
-			v += 31
-		} else if v >= 27 && v <= 28 {
-			v = v - 27 + 31
-		}
-	}
-
-	return r, s, v, nil
+	return signer.SplitSignatureForSafeEthSign(signatureHex)
 }
 
 // SplitAndPackSig splits a signature and packs it using eth_abi packed encoding
 // The packed format is: encode_packed(["uint256", "uint256", "uint8"], [r, s, v])
 // This returns a hex string with 0x prefix containing the 65 packed bytes
+//
+// Deprecated: use signer.PackSignatureForSafeEthSign.
 func SplitAndPackSig(signatureHex string) (string, error) {
-	r, s, v, err := SplitSignature(signatureHex)
-	if err != nil {
-		return "", err
-	}
+	return signer.PackSignatureForSafeEthSign(signatureHex)
+}
 
-	// Decode r and s
-	rBytes, err := hexutil.Decode(r)
-	if err != nil {
-		return "", errors.ErrInvalidSignature(err)
-	}
-	sBytes, err := hexutil.Decode(s)
+// CreateSafeStructHash builds the EIP-712 struct hash for a Safe transaction
+// Note: This function only handles single transactions. For multiple transactions,
+// use BuildSafeTransactionRequestWithMultisend which aggregates them first.
+func CreateSafeStructHash(args *models.SafeTransactionArgs, sig *signer.Signer) (common.Hash, error) {
+	return CreateSafeStructHashForVersion(args, sig, "")
+}
+
+// CreateSafeStructHashForVersion is CreateSafeStructHash for a Safe of the
+// given version (see SafeTxHashForVersion); an empty version means
+// CurrentSafeVersion
+func CreateSafeStructHashForVersion(args *models.SafeTransactionArgs, sig *signer.Signer, version string) (common.Hash, error) {
+	safeTx, err := newSafeTx(args)
 	if err != nil {
-		return "", errors.ErrInvalidSignature(err)
+		return common.Hash{}, err
 	}
 
-	// Pack: r (32 bytes) + s (32 bytes) + v (1 byte)
-	packed := make([]byte, 65)
-	copy(packed[0:32], rBytes)
-	copy(packed[32:64], sBytes)
-	packed[64] = byte(v)
+	// Get verifying contract (the Safe address)
+	verifyingContract := common.HexToAddress(args.SafeAddress)
+
+	// Get chain ID from signer
+	chainID := sig.GetChainID().Int64()
 
-	return hexutil.Encode(packed), nil
+	// Build and return the hash
+	return safeTxHashFor(safeTx, verifyingContract, chainID, version)
 }
 
-// CreateSafeStructHash builds the EIP-712 struct hash for a Safe transaction
-// Note: This function only handles single transactions. For multiple transactions,
-// use BuildSafeTransactionRequestWithMultisend which aggregates them first.
-func CreateSafeStructHash(args *models.SafeTransactionArgs, sig *signer.Signer) (common.Hash, error) {
+// signedSafeTxTypedData builds the EIP-712 typed data of the single
+// transaction in args, whose digest is CreateSafeStructHash
+func signedSafeTxTypedData(args *models.SafeTransactionArgs, sig *signer.Signer) (*signer.TypedData, error) {
+	safeTx, err := newSafeTx(args)
+	if err != nil {
+		return nil, err
+	}
+	return safeTxTypedData(safeTx, common.HexToAddress(args.SafeAddress), sig.GetChainID().Int64())
+}
+
+// newSafeTx builds the SafeTx for the single transaction in args
+func newSafeTx(args *models.SafeTransactionArgs) (*SafeTx, error) {
 	// Get the transaction data
 	var to common.Address
 	var value *big.Int
@@ -93,59 +80,67 @@ func CreateSafeStructHash(args *models.SafeTransactionArgs, sig *signer.Signer)
 	var operation uint8
 
 	if len(args.Transactions) == 0 {
-		return common.Hash{}, errors.NewRelayerClientError("no transactions provided", nil)
+		return nil, errors.NewRelayerClientError("no transactions provided", nil)
 	}
 
 	if len(args.Transactions) > 1 {
-		return common.Hash{}, errors.NewRelayerClientError("CreateSafeStructHash only supports single transactions; use BuildSafeTransactionRequestWithMultisend for multiple transactions", nil)
+		return nil, errors.NewRelayerClientError("CreateSafeStructHash only supports single transactions; use BuildSafeTransactionRequestWithMultisend for multiple transactions", nil)
 	}
 
 	// Single transaction
 	txn := args.Transactions[0]
 	to = common.HexToAddress(txn.To)
-	value = new(big.Int)
-	if txn.Value != "" {
-		value.SetString(txn.Value, 0)
+	value, err := txn.ValueBig()
+	if err != nil {
+		return nil, err
 	}
 
-	if txn.Data != "" && txn.Data != "0x" {
-		var err error
-		data, err = hexutil.Decode(txn.Data)
-		if err != nil {
-			return common.Hash{}, errors.NewRelayerClientError("failed to decode transaction data", err)
-		}
+	data, err = decodeTransactionData(txn.Data)
+	if err != nil {
+		return nil, err
 	}
 
 	operation = uint8(txn.Operation)
 
-	// Parse nonce
-	nonce := new(big.Int)
-	if args.Nonce != "" {
-		nonce.SetString(args.Nonce, 0)
+	safeTxGas, err := parseGasLimit(txn.GasLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, err := args.NonceBig()
+	if err != nil {
+		return nil, err
 	}
 
 	// Build SafeTx struct
-	safeTx := &SafeTx{
+	return &SafeTx{
 		To:             to,
 		Value:          value,
 		Data:           data,
 		Operation:      operation,
-		SafeTxGas:      big.NewInt(0),
+		SafeTxGas:      safeTxGas,
 		BaseGas:        big.NewInt(0),
 		GasPrice:       big.NewInt(0),
 		GasToken:       common.HexToAddress(constants.ZERO_ADDRESS),
 		RefundReceiver: common.HexToAddress(constants.ZERO_ADDRESS),
 		Nonce:          nonce,
-	}
-
-	// Get verifying contract (the Safe address)
-	verifyingContract := common.HexToAddress(args.SafeAddress)
-
-	// Get chain ID from signer
-	chainID := sig.GetChainID().Int64()
+	}, nil
+}
 
-	// Build and return the hash
-	return BuildSafeTxHash(safeTx, verifyingContract, chainID)
+// parseGasLimit parses a SafeTransaction GasLimit into safeTxGas; an empty
+// value means 0
+func parseGasLimit(gasLimit string) (*big.Int, error) {
+	gas := new(big.Int)
+	if gasLimit == "" {
+		return gas, nil
+	}
+	if _, ok := gas.SetString(gasLimit, 0); !ok {
+		return nil, errors.ErrInvalidGasLimit(fmt.Sprintf("%q is not a number", gasLimit))
+	}
+	if gas.Sign() < 0 || gas.BitLen() > 256 {
+		return nil, errors.ErrInvalidGasLimit(fmt.Sprintf("%s is out of uint256 range", gasLimit))
+	}
+	return gas, nil
 }
 
 // CreateSafeSignature signs a Safe transaction and returns the signature
@@ -156,55 +151,271 @@ func CreateSafeSignature(args *models.SafeTransactionArgs, sig *signer.Signer) (
 		return "", err
 	}
 
-	// Debug logging
-	log.Printf("DEBUG: EIP-712 struct hash to sign: %s", structHash.Hex())
-	log.Printf("DEBUG: Safe address: %s", args.SafeAddress)
-	log.Printf("DEBUG: Nonce: %s", args.Nonce)
-
 	// Sign the struct hash using SignEIP712StructHash (applies EIP-191 prefix, matching Python)
 	// The Polymarket relayer expects EIP-191 prefixed signatures for SAFE transactions
 	// This is different from SAFE-CREATE which uses direct signing
 	signature, err := sig.SignEIP712StructHash(structHash.Bytes())
 	if err != nil {
-		return "", err
+		return "", errors.ErrStaged(errors.StageSign, err)
 	}
 
-	log.Printf("DEBUG: Generated signature: %s", signature)
-
 	return signature, nil
 }
 
+// CreateSafeSignatureWithScheme signs a Safe transaction under scheme and
+// returns the packed signature with the matching v range (31/32 for
+// SchemeEthSign, 27/28 for SchemeEIP712)
+func CreateSafeSignatureWithScheme(args *models.SafeTransactionArgs, sig *signer.Signer, scheme signer.SignatureScheme) (string, error) {
+	return createSafeSignature(args, sig, scheme, "")
+}
+
+// createSafeSignature signs a Safe transaction of a Safe of the given
+// version under scheme
+func createSafeSignature(args *models.SafeTransactionArgs, sig *signer.Signer, scheme signer.SignatureScheme, version string) (string, error) {
+	// Create the struct hash
+	structHash, err := CreateSafeStructHashForVersion(args, sig, version)
+	if err != nil {
+		return "", err
+	}
+
+	// Hand the signer the typed data where the layout has one, so that a
+	// remote signer can sign it with eth_signTypedData_v4
+	var packedSig string
+	if layout, layoutErr := safeTxLayoutFor(version); layoutErr == nil && layout.domainChainID {
+		var typedData *signer.TypedData
+		typedData, err = signedSafeTxTypedData(args, sig)
+		if err != nil {
+			return "", err
+		}
+		packedSig, err = sig.SignSafeTx(typedData, scheme)
+	} else {
+		packedSig, err = sig.SignSafeTxHash(structHash.Bytes(), scheme)
+	}
+	if err != nil {
+		return "", errors.ErrStaged(errors.StageSign, err)
+	}
+	return packedSig, nil
+}
+
 // BuildSafeTransactionRequest builds a complete Safe transaction request
 // This is the main function to use when preparing a Safe transaction for submission
+// The signature uses signer.DefaultSignatureScheme; chainID must match the signer's
+// Calls into the Safe's own execution are rejected (see models.SafeTransactionArgs.CheckSelfCalls)
 func BuildSafeTransactionRequest(args *models.SafeTransactionArgs, sig *signer.Signer, chainID int64) (*models.TransactionRequest, error) {
+	if err := checkSignerChainID(sig, chainID); err != nil {
+		return nil, err
+	}
+	if args != nil {
+		if err := args.CheckSelfCalls(); err != nil {
+			return nil, err
+		}
+	}
+	return buildSafeTransactionRequest(args, sig, signer.DefaultSignatureScheme, "")
+}
+
+// BuildSafeTransactionRequestWithConfig builds a Safe transaction request
+// signed under contractConfig's SignatureScheme, batching several
+// transactions through contractConfig's MultiSend
+func BuildSafeTransactionRequestWithConfig(args *models.SafeTransactionArgs, sig *signer.Signer, contractConfig *config.ContractConfig) (*models.TransactionRequest, error) {
+	return BuildSafeTransactionRequestWithVariant(args, sig, contractConfig, config.MultisendStandard)
+}
+
+// BuildSafeTransactionRequestWithVariant is BuildSafeTransactionRequestWithConfig
+// batching through the given MultiSend variant of contractConfig
+func BuildSafeTransactionRequestWithVariant(args *models.SafeTransactionArgs, sig *signer.Signer, contractConfig *config.ContractConfig, variant config.MultisendVariant) (*models.TransactionRequest, error) {
+	return BuildSafeTransactionRequestForSafeVersion(args, sig, contractConfig, variant, "")
+}
+
+// BuildSafeTransactionRequestForSafeVersion is
+// BuildSafeTransactionRequestWithVariant signing for a Safe of the given
+// version (see SafeTxHashForVersion); an empty version means CurrentSafeVersion
+func BuildSafeTransactionRequestForSafeVersion(args *models.SafeTransactionArgs, sig *signer.Signer, contractConfig *config.ContractConfig, variant config.MultisendVariant, version string) (*models.TransactionRequest, error) {
+	if contractConfig == nil {
+		return nil, errors.ErrMissingRequiredField("contractConfig")
+	}
 	if args == nil {
 		return nil, errors.ErrMissingRequiredField("args")
 	}
-	if sig == nil {
-		return nil, errors.ErrSignerNotConfigured
+	if err := checkSignerChainID(sig, contractConfig.ChainID); err != nil {
+		return nil, err
+	}
+
+	args, err := aggregateSafeArgs(args, contractConfig, variant)
+	if err != nil {
+		return nil, err
+	}
+	return buildSafeTransactionRequest(args, sig, contractConfig.SignatureScheme, version)
+}
+
+// BuildSafeTransactionRequestWithSignature builds the same request as
+// BuildSafeTransactionRequestWithConfig for the chain's registered config,
+// but with a signature made elsewhere (e.g. on the user's device) instead of
+// by a Signer. The transactions are batched through MultiSend first, so the
+// signature must cover the SafeTx hash of the batch. signature may be
+// packed for the chain's SignatureScheme or a raw 65-byte signature (v
+// 0/1 or 27/28), which is packed here. It must recover to signerAddress
+// over the SafeTx hash, otherwise a SignatureMismatchError is returned.
+func BuildSafeTransactionRequestWithSignature(args *models.SafeTransactionArgs, signature models.Signature, signerAddress string, chainID int64) (*models.TransactionRequest, error) {
+	if args == nil {
+		return nil, errors.ErrMissingRequiredField("args")
+	}
+	if !common.IsHexAddress(signerAddress) {
+		return nil, errors.ErrInvalidAddress(signerAddress)
+	}
+	expected := common.HexToAddress(signerAddress)
+	if signature.Signer != "" && !strings.EqualFold(signature.Signer, expected.Hex()) {
+		return nil, errors.ErrSignatureMismatch(expected.Hex(), signature.Signer)
+	}
+	contractConfig, err := config.GetContractConfig(chainID)
+	if err != nil {
+		return nil, err
+	}
+
+	args, err = aggregateSafeArgs(args, contractConfig, config.MultisendStandard)
+	if err != nil {
+		return nil, err
+	}
+	safeTx, err := newSafeTx(args)
+	if err != nil {
+		return nil, err
+	}
+	safeTxHash, err := safeTxHashFor(safeTx, common.HexToAddress(args.SafeAddress), chainID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	scheme := contractConfig.SignatureScheme
+	sigType := scheme.SignatureType()
+	if signature.SignatureType != "" && signature.SignatureType != sigType {
+		return nil, errors.NewRelayerClientError(fmt.Sprintf("signature type %s does not match the %s scheme of chain %d", signature.SignatureType, scheme.OrDefault(), chainID), nil)
+	}
+	signatureHex, err := signatureData(signature)
+	if err != nil {
+		return nil, err
+	}
+	packedSig, err := scheme.PackSignature(signatureHex)
+	if err != nil {
+		return nil, errors.ErrInvalidSignature(err)
+	}
+
+	recovered, err := signer.RecoverTypedSignature(safeTxHash.Bytes(), hexutil.MustDecode(packedSig), sigType)
+	if err != nil {
+		return nil, errors.ErrInvalidSignature(err)
+	}
+	if recovered != expected {
+		return nil, errors.ErrSignatureMismatch(expected.Hex(), recovered.Hex())
+	}
+
+	return assembleSafeTransactionRequest(args, expected.Hex(), packedSig, sigType)
+}
+
+// signatureData returns the hex signature of signature, from Data or else
+// from Split
+func signatureData(signature models.Signature) (string, error) {
+	if signature.Data != "" {
+		return signature.Data, nil
+	}
+	if signature.Split == nil {
+		return "", errors.ErrMissingRequiredField("signature")
 	}
 
-	// Create signature
-	signature, err := CreateSafeSignature(args, sig)
+	r, err := hexutil.Decode(signature.Split.R)
+	if err != nil || len(r) != 32 {
+		return "", errors.ErrInvalidSignature(fmt.Errorf("invalid r: %s", signature.Split.R))
+	}
+	s, err := hexutil.Decode(signature.Split.S)
+	if err != nil || len(s) != 32 {
+		return "", errors.ErrInvalidSignature(fmt.Errorf("invalid s: %s", signature.Split.S))
+	}
+	if signature.Split.V < 0 || signature.Split.V > 255 {
+		return "", errors.ErrUnsupportedV(signature.Split.V)
+	}
+	return hexutil.Encode(append(append(r, s...), byte(signature.Split.V))), nil
+}
+
+// aggregateSafeArgs checks args' transactions for calls into the Safe's own
+// execution (see SafeTransactionArgs.CheckSelfCalls) and returns args with
+// them batched into one call to contractConfig's MultiSend of variant when needed
+func aggregateSafeArgs(args *models.SafeTransactionArgs, contractConfig *config.ContractConfig, variant config.MultisendVariant) (*models.SafeTransactionArgs, error) {
+	if err := args.CheckSelfCalls(); err != nil {
+		return nil, err
+	}
+	if variant.OrDefault() != config.MultisendCallOnly && len(args.Transactions) <= 1 {
+		return args, nil
+	}
+
+	multisendAddress, err := contractConfig.MultisendAddress(variant)
 	if err != nil {
 		return nil, err
 	}
+	multiSendTxn, err := AggregateSafeTransactionWithVariant(args.Transactions, multisendAddress, variant)
+	if err != nil {
+		return nil, err
+	}
+	return &models.SafeTransactionArgs{
+		SafeAddress:  args.SafeAddress,
+		Transactions: []models.SafeTransaction{*multiSendTxn},
+		Nonce:        args.Nonce,
+		NonceInt:     args.NonceInt,
+		Metadata:     args.Metadata,
+	}, nil
+}
+
+// checkSignerChainID returns a ChainIDMismatchError unless sig signs for
+// chainID. A signer without a chain ID (not created by NewSigner) is left to
+// fail when it signs.
+func checkSignerChainID(sig *signer.Signer, chainID int64) error {
+	if sig == nil {
+		return nil
+	}
+	if signerChainID := sig.GetChainID().Int64(); signerChainID != 0 && signerChainID != chainID {
+		return errors.ErrChainIDMismatch(signerChainID, chainID)
+	}
+	return nil
+}
+
+// buildSafeTransactionRequest builds a Safe transaction request signed under
+// scheme for a Safe of the given version
+func buildSafeTransactionRequest(args *models.SafeTransactionArgs, sig *signer.Signer, scheme signer.SignatureScheme, version string) (*models.TransactionRequest, error) {
+	if args == nil {
+		return nil, errors.ErrMissingRequiredField("args")
+	}
+	if sig == nil {
+		return nil, errors.ErrSignerNotConfigured
+	}
 
-	// Split and pack the signature
-	packedSig, err := SplitAndPackSig(signature)
+	// Create and pack the signature
+	packedSig, err := createSafeSignature(args, sig, scheme, version)
 	if err != nil {
 		return nil, err
 	}
 
+	return assembleSafeTransactionRequest(args, sig.AddressHex(), packedSig, scheme.SignatureType())
+}
+
+// assembleSafeTransactionRequest builds the SAFE request for args signed by
+// from with the packed signature of the given type
+func assembleSafeTransactionRequest(args *models.SafeTransactionArgs, from, packedSig string, sigType models.SignatureType) (*models.TransactionRequest, error) {
+	if err := checkSignatureType(packedSig, sigType); err != nil {
+		return nil, errors.ErrStaged(errors.StageSign, err)
+	}
+
 	// Build the transaction request
 	var to, value, data interface{}
 
 	if len(args.Transactions) == 1 {
 		// Single transaction
 		txn := args.Transactions[0]
+		normalized, err := models.NormalizeHexData(txn.Data)
+		if err != nil {
+			return nil, err
+		}
 		to = txn.To
-		value = txn.Value
-		data = txn.Data
+		value, err = wireValue(&txn)
+		if err != nil {
+			return nil, err
+		}
+		data = normalized
 	} else {
 		// Multiple transactions - need arrays
 		tos := make([]string, len(args.Transactions))
@@ -213,8 +424,16 @@ func BuildSafeTransactionRequest(args *models.SafeTransactionArgs, sig *signer.S
 
 		for i, txn := range args.Transactions {
 			tos[i] = txn.To
-			values[i] = txn.Value
-			datas[i] = txn.Data
+			wire, err := wireValue(&txn)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = wire
+			normalized, err := models.NormalizeHexData(txn.Data)
+			if err != nil {
+				return nil, err
+			}
+			datas[i] = normalized
 		}
 
 		to = tos
@@ -245,6 +464,12 @@ func BuildSafeTransactionRequest(args *models.SafeTransactionArgs, sig *signer.S
 	var operationStr string
 	if len(args.Transactions) == 1 {
 		operationStr = string(rune('0' + int(args.Transactions[0].Operation)))
+		// Signed into the SafeTx hash above; the relayer rebuilds it from here
+		gas, err := parseGasLimit(args.Transactions[0].GasLimit)
+		if err != nil {
+			return nil, err
+		}
+		safeTxGas = gas.String()
 	} else {
 		operationStr = "1" // DelegateCall for multisend
 	}
@@ -258,30 +483,83 @@ func BuildSafeTransactionRequest(args *models.SafeTransactionArgs, sig *signer.S
 		RefundReceiver: &refundReceiver,
 	}
 
+	nonce, err := wireNonce(args)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create the request (matching Python structure)
 	request := &models.TransactionRequest{
 		Type:            string(models.SAFE),
-		From:            sig.AddressHex(), // The signer address (EOA)
+		From:            from, // The signer address (EOA)
 		To:              toJSON,
 		ProxyWallet:     args.SafeAddress, // The Safe address
 		Value:           valueJSON,
 		Data:            dataJSON,
-		Nonce:           &args.Nonce,
+		Nonce:           &nonce,
 		Signature:       packedSig,
+		SignatureType:   wireSignatureType(sigType),
 		SignatureParams: signatureParams,
 	}
 
-	// Add metadata if provided
-	if args.Metadata != "" {
-		request.Metadata = &args.Metadata
-	}
+	// nil metadata omits the field; an empty string is sent as-is
+	request.Metadata = args.Metadata
 
 	return request, nil
 }
 
+// wireValue returns the value txn carries in a request: Value as given, or
+// ValueInt in decimal when only it is set
+func wireValue(txn *models.SafeTransaction) (string, error) {
+	value, err := txn.ValueBig()
+	if err != nil {
+		return "", err
+	}
+	if txn.Value == "" && txn.ValueInt != nil {
+		return value.String(), nil
+	}
+	return txn.Value, nil
+}
+
+// wireNonce returns the nonce args carries in a request: Nonce as given, or
+// NonceInt in decimal when only it is set
+func wireNonce(args *models.SafeTransactionArgs) (string, error) {
+	nonce, err := args.NonceBig()
+	if err != nil {
+		return "", err
+	}
+	if args.Nonce == "" && args.NonceInt != nil {
+		return nonce.String(), nil
+	}
+	return args.Nonce, nil
+}
+
+// wireSignatureType returns the signature type a SAFE request declares: none
+// for the default scheme's type, so relayers that infer it from v get the
+// same payload as before signature types existed
+func wireSignatureType(sigType models.SignatureType) models.SignatureType {
+	if sigType == signer.DefaultSignatureScheme.SignatureType() {
+		return ""
+	}
+	return sigType
+}
+
+// checkSignatureType returns a SignatureTypeMismatchError unless the v of
+// packedSig is in the v range of sigType
+func checkSignatureType(packedSig string, sigType models.SignatureType) error {
+	_, _, v, err := signer.SplitSignatureRaw(packedSig)
+	if err != nil {
+		return err
+	}
+	return sigType.CheckV(v)
+}
+
 // BuildSafeTransactionRequestWithMultisend builds a Safe transaction request with multisend
 // This should be used when you have multiple transactions to batch
 func BuildSafeTransactionRequestWithMultisend(args *models.SafeTransactionArgs, sig *signer.Signer, chainID int64, multisendAddress string) (*models.TransactionRequest, error) {
+	if err := args.CheckSelfCalls(); err != nil {
+		return nil, err
+	}
 	if len(args.Transactions) <= 1 {
 		// No need for multisend with single transaction
 		return BuildSafeTransactionRequest(args, sig, chainID)
@@ -298,8 +576,24 @@ func BuildSafeTransactionRequestWithMultisend(args *models.SafeTransactionArgs,
 		SafeAddress:  args.SafeAddress,
 		Transactions: []models.SafeTransaction{*multiSendTxn},
 		Nonce:        args.Nonce,
+		NonceInt:     args.NonceInt,
 		Metadata:     args.Metadata,
 	}
 
 	return BuildSafeTransactionRequest(multiSendArgs, sig, chainID)
 }
+
+// BuildVersionedSafeTransactionRequest builds a Safe transaction request
+// (batching through multisend when needed) and returns the payload in the
+// given schema version. Both versions carry the same signature.
+func BuildVersionedSafeTransactionRequest(args *models.SafeTransactionArgs, sig *signer.Signer, chainID int64, multisendAddress string, version models.RequestVersion) (interface{}, error) {
+	if !version.IsValid() {
+		return nil, errors.ErrUnsupportedRequestVersion(int(version))
+	}
+
+	request, err := BuildSafeTransactionRequestWithMultisend(args, sig, chainID, multisendAddress)
+	if err != nil {
+		return nil, err
+	}
+	return request.Versioned(version)
+}
@@ -0,0 +1,253 @@
+package builder
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/davidt58/go-builder-relayer-client/config"
+	"github.com/davidt58/go-builder-relayer-client/constants"
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/models"
+	"github.com/davidt58/go-builder-relayer-client/signer"
+)
+
+// SafeServiceProposal is a Safe transaction in the JSON format of the Safe
+// Transaction Service, as its multisig-transactions endpoints list them and
+// the Safe{Wallet} web UI exports them. The service writes safeTxGas,
+// baseGas and nonce as numbers, the other amounts as strings, and empty
+// data as null.
+type SafeServiceProposal struct {
+	Safe           string      `json:"safe"`
+	To             string      `json:"to"`
+	Value          string      `json:"value"`
+	Data           *string     `json:"data"`
+	Operation      int         `json:"operation"`
+	SafeTxGas      json.Number `json:"safeTxGas"`
+	BaseGas        json.Number `json:"baseGas"`
+	GasPrice       string      `json:"gasPrice"`
+	GasToken       string      `json:"gasToken"`
+	RefundReceiver string      `json:"refundReceiver"`
+	Nonce          json.Number `json:"nonce"`
+	SafeTxHash     string      `json:"safeTxHash"`
+	Sender         string      `json:"sender,omitempty"`
+	Signatures     string      `json:"signatures"`
+
+	// ContractTransactionHash and Signature are the names the service's
+	// proposal endpoint takes for SafeTxHash and Signatures; an import
+	// accepts either
+	ContractTransactionHash string `json:"contractTransactionHash,omitempty"`
+	Signature               string `json:"signature,omitempty"`
+}
+
+// ExportSafeServiceProposal signs the Safe transaction of args for the chain's
+// registered config, batching several transactions through MultiSend, and
+// returns it as Safe Transaction Service JSON with sig as the sender, for
+// proposing through the Safe{Wallet} UI instead of the relayer
+func ExportSafeServiceProposal(args *models.SafeTransactionArgs, sig *signer.Signer, chainID int64) ([]byte, error) {
+	if args == nil {
+		return nil, errors.ErrMissingRequiredField("args")
+	}
+	if sig == nil {
+		return nil, errors.ErrSignerNotConfigured
+	}
+	if err := checkSignerChainID(sig, chainID); err != nil {
+		return nil, err
+	}
+	contractConfig, err := config.GetContractConfig(chainID)
+	if err != nil {
+		return nil, err
+	}
+
+	args, err = aggregateSafeArgs(args, contractConfig, config.MultisendStandard)
+	if err != nil {
+		return nil, err
+	}
+	safeTx, err := newSafeTx(args)
+	if err != nil {
+		return nil, err
+	}
+	safeTxHash, err := safeTxHashFor(safeTx, common.HexToAddress(args.SafeAddress), chainID, "")
+	if err != nil {
+		return nil, err
+	}
+	signature, err := sig.SignSafeTxHash(safeTxHash.Bytes(), contractConfig.SignatureScheme)
+	if err != nil {
+		return nil, errors.ErrStaged(errors.StageSign, err)
+	}
+
+	proposal := SafeServiceProposal{
+		Safe:           common.HexToAddress(args.SafeAddress).Hex(),
+		To:             safeTx.To.Hex(),
+		Value:          safeTx.Value.String(),
+		Operation:      int(safeTx.Operation),
+		SafeTxGas:      json.Number(safeTx.SafeTxGas.String()),
+		BaseGas:        json.Number(safeTx.BaseGas.String()),
+		GasPrice:       safeTx.GasPrice.String(),
+		GasToken:       safeTx.GasToken.Hex(),
+		RefundReceiver: safeTx.RefundReceiver.Hex(),
+		Nonce:          json.Number(safeTx.Nonce.String()),
+		SafeTxHash:     safeTxHash.Hex(),
+		Sender:         sig.AddressHex(),
+		Signatures:     signature,
+	}
+	if len(safeTx.Data) > 0 {
+		data := hexutil.Encode(safeTx.Data)
+		proposal.Data = &data
+	}
+
+	body, err := json.MarshalIndent(proposal, "", "  ")
+	if err != nil {
+		return nil, errors.ErrJSONMarshalFailed(err)
+	}
+	return body, nil
+}
+
+// ImportSafeServiceProposal turns Safe Transaction Service JSON into a SAFE
+// request for the relayer. The safeTxHash is recomputed from the fields for
+// chainID and must match, or an error is returned. The relayer submits one
+// owner's signature: signatures must be a single ECDSA signature, and it must
+// recover to the sender when one is given.
+func ImportSafeServiceProposal(data []byte, chainID int64) (*models.TransactionRequest, error) {
+	var proposal SafeServiceProposal
+	if err := json.Unmarshal(data, &proposal); err != nil {
+		return nil, errors.ErrJSONUnmarshalFailed(err)
+	}
+	if proposal.SafeTxHash == "" {
+		proposal.SafeTxHash = proposal.ContractTransactionHash
+	}
+	if proposal.Signatures == "" {
+		proposal.Signatures = proposal.Signature
+	}
+
+	for _, field := range []struct{ name, value string }{
+		{"safeTxHash", proposal.SafeTxHash},
+		{"signatures", proposal.Signatures},
+		{"nonce", proposal.Nonce.String()},
+	} {
+		if field.value == "" {
+			return nil, errors.ErrMissingRequiredField(field.name)
+		}
+	}
+	if hash, err := hexutil.Decode(proposal.SafeTxHash); err != nil || len(hash) != common.HashLength {
+		return nil, errors.NewRelayerClientError(fmt.Sprintf("safeTxHash %q is not a 32-byte hash", proposal.SafeTxHash), err)
+	}
+	for _, address := range []string{proposal.Safe, proposal.To} {
+		if !common.IsHexAddress(address) {
+			return nil, errors.ErrInvalidAddress(address)
+		}
+	}
+	if proposal.Operation != int(models.Call) && proposal.Operation != int(models.DelegateCall) {
+		return nil, errors.NewRelayerClientError(fmt.Sprintf("operation %d is neither 0 nor 1", proposal.Operation), nil)
+	}
+
+	callData := "0x"
+	if proposal.Data != nil {
+		normalized, err := models.NormalizeHexData(*proposal.Data)
+		if err != nil {
+			return nil, err
+		}
+		callData = normalized
+	}
+
+	packed, err := hexutil.Decode(proposal.Signatures)
+	if err != nil || len(packed) != 65 {
+		return nil, errors.ErrInvalidSignature(fmt.Errorf("signatures must be one 65-byte ECDSA signature"))
+	}
+	var sigType models.SignatureType
+	switch packed[64] {
+	case 27, 28:
+		sigType = models.SignatureTypeEIP712
+	case 31, 32:
+		sigType = models.SignatureTypeEthSign
+	default:
+		return nil, errors.ErrUnsupportedV(int(packed[64]))
+	}
+
+	// Amounts are kept as decimal strings, the way requests built here carry them
+	numbers := make(map[string]string, 5)
+	for _, field := range []struct{ name, value string }{
+		{"value", proposal.Value},
+		{"safeTxGas", proposal.SafeTxGas.String()},
+		{"baseGas", proposal.BaseGas.String()},
+		{"gasPrice", proposal.GasPrice},
+		{"nonce", proposal.Nonce.String()},
+	} {
+		value := field.value
+		if value == "" {
+			value = "0"
+		}
+		parsed, ok := new(big.Int).SetString(value, 10)
+		if !ok || parsed.Sign() < 0 {
+			return nil, errors.NewRelayerClientError(fmt.Sprintf("%s %q is not a number", field.name, field.value), nil)
+		}
+		numbers[field.name] = parsed.String()
+	}
+
+	gasToken, refundReceiver := addressOrZero(proposal.GasToken), addressOrZero(proposal.RefundReceiver)
+	for _, address := range []string{gasToken, refundReceiver} {
+		if !common.IsHexAddress(address) {
+			return nil, errors.ErrInvalidAddress(address)
+		}
+	}
+
+	to, _ := json.Marshal(common.HexToAddress(proposal.To).Hex())
+	value, _ := json.Marshal(numbers["value"])
+	dataJSON, _ := json.Marshal(callData)
+	operation := fmt.Sprint(proposal.Operation)
+	nonce, safeTxGas, baseGas, gasPrice := numbers["nonce"], numbers["safeTxGas"], numbers["baseGas"], numbers["gasPrice"]
+	request := &models.TransactionRequest{
+		Type:          string(models.SAFE),
+		From:          proposal.Sender,
+		To:            to,
+		ProxyWallet:   common.HexToAddress(proposal.Safe).Hex(),
+		Value:         value,
+		Data:          dataJSON,
+		Nonce:         &nonce,
+		Signature:     hexutil.Encode(packed),
+		SignatureType: wireSignatureType(sigType),
+		SignatureParams: &models.SignatureParams{
+			GasPrice:       &gasPrice,
+			Operation:      &operation,
+			SafeTxGas:      &safeTxGas,
+			BaseGas:        &baseGas,
+			GasToken:       &gasToken,
+			RefundReceiver: &refundReceiver,
+		},
+	}
+
+	computed, err := SafeRequestHash(request, chainID, "")
+	if err != nil {
+		return nil, err
+	}
+	if computed != common.HexToHash(proposal.SafeTxHash) {
+		return nil, errors.ErrSafeTxHashMismatch(proposal.SafeTxHash, computed.Hex())
+	}
+
+	recovered, err := RecoverRequestSigner(request, computed)
+	if err != nil {
+		return nil, err
+	}
+	if proposal.Sender != "" {
+		if !common.IsHexAddress(proposal.Sender) {
+			return nil, errors.ErrInvalidAddress(proposal.Sender)
+		}
+		if recovered != common.HexToAddress(proposal.Sender) {
+			return nil, errors.ErrSignatureMismatch(common.HexToAddress(proposal.Sender).Hex(), recovered.Hex())
+		}
+	}
+	request.From = recovered.Hex()
+	return request, nil
+}
+
+// addressOrZero returns address, or the zero address if it is empty
+func addressOrZero(address string) string {
+	if strings.TrimSpace(address) == "" {
+		return constants.ZERO_ADDRESS
+	}
+	return address
+}
@@ -0,0 +1,179 @@
+package builder
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/davidt58/go-builder-relayer-client/config"
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/models"
+	"github.com/davidt58/go-builder-relayer-client/signer"
+)
+
+// serviceProposal returns the fixture with modify applied to its fields
+func serviceProposal(t *testing.T, modify func(map[string]interface{})) []byte {
+	t.Helper()
+	body, err := os.ReadFile(filepath.Join("testdata", "safe_service_proposal_polygon.json"))
+	if err != nil {
+		t.Fatalf("reading fixture failed: %v", err)
+	}
+	if modify == nil {
+		return body
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(body, &fields); err != nil {
+		t.Fatalf("decoding fixture failed: %v", err)
+	}
+	modify(fields)
+	body, err = json.Marshal(fields)
+	if err != nil {
+		t.Fatalf("encoding fixture failed: %v", err)
+	}
+	return body
+}
+
+func TestImportSafeServiceProposal_Fixture(t *testing.T) {
+	request, err := ImportSafeServiceProposal(serviceProposal(t, nil), 137)
+	if err != nil {
+		t.Fatalf("ImportSafeServiceProposal failed: %v", err)
+	}
+
+	if request.Type != string(models.SAFE) || request.ProxyWallet != "0x6e0c80c90ea6c15917308F820Eac91Ce2724B5b5" {
+		t.Errorf("request = %s for %s, want a SAFE request for the fixture's Safe", request.Type, request.ProxyWallet)
+	}
+	if request.From != "0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266" {
+		t.Errorf("From = %s, want the recovered owner", request.From)
+	}
+	if string(request.Data) != `"0x"` || string(request.Value) != `"1000000000000000000"` || *request.Nonce != "12" {
+		t.Errorf("data %s, value %s, nonce %s; want the fixture's", request.Data, request.Value, *request.Nonce)
+	}
+	// eth_sign (v 31/32) is the default type and is not declared
+	if request.SignatureType != "" {
+		t.Errorf("SignatureType = %q, want none for v 31/32", request.SignatureType)
+	}
+
+	// The imported request is what the builder would have signed itself
+	sig, err := signer.NewSigner("ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80", 137)
+	if err != nil {
+		t.Fatalf("NewSigner failed: %v", err)
+	}
+	contractConfig, err := config.GetContractConfig(137)
+	if err != nil {
+		t.Fatalf("GetContractConfig failed: %v", err)
+	}
+	built, err := BuildSafeTransactionRequestWithConfig(&models.SafeTransactionArgs{
+		SafeAddress:  request.ProxyWallet,
+		Transactions: []models.SafeTransaction{{To: "0x4D97DCd97eC945f40cF65F87097ACe5EA0476045", Value: "1000000000000000000", Data: "0x"}},
+		Nonce:        "12",
+	}, sig, contractConfig)
+	if err != nil {
+		t.Fatalf("BuildSafeTransactionRequestWithConfig failed: %v", err)
+	}
+	got, _ := json.Marshal(request)
+	want, _ := json.Marshal(built)
+	if string(got) != string(want) {
+		t.Errorf("imported request\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestImportSafeServiceProposal_Invalid(t *testing.T) {
+	secondOwner := "0x22e97381b72958fbf40e58eca418e49f8adddf43cb4ba5c55b44615890fb607d0792f8f4ac5cedcf3bfe61e94424e5bde67cfca738ed62e769fd4e2a19de5f5520"
+
+	tests := []struct {
+		name               string
+		modify             func(map[string]interface{})
+		chainID            int64
+		wantMismatch       bool
+		wantSignerMismatch bool
+	}{
+		{name: "nonce changed", modify: func(f map[string]interface{}) { f["nonce"] = 13 }, wantMismatch: true},
+		{name: "value changed", modify: func(f map[string]interface{}) { f["value"] = "2000000000000000000" }, wantMismatch: true},
+		{name: "other chain", chainID: 80002, wantMismatch: true},
+		{name: "no hash", modify: func(f map[string]interface{}) { delete(f, "safeTxHash") }},
+		{name: "malformed hash", modify: func(f map[string]interface{}) { f["safeTxHash"] = "0x949e" }},
+		{name: "no signature", modify: func(f map[string]interface{}) { delete(f, "signatures") }},
+		{name: "two signatures", modify: func(f map[string]interface{}) { f["signatures"] = f["signatures"].(string) + secondOwner[2:] }},
+		{name: "contract signature", modify: func(f map[string]interface{}) {
+			f["signatures"] = "0x000000000000000000000000f39fd6e51aad88f6f4ce6ab8827279cfffb92266000000000000000000000000000000000000000000000000000000000000004100"
+		}},
+		{name: "sender is not the signer", modify: func(f map[string]interface{}) { f["sender"] = "0x70997970C51812dc3A010C7d01b50e0d17dc79C8" }, wantSignerMismatch: true},
+		{name: "bad operation", modify: func(f map[string]interface{}) { f["operation"] = 2 }},
+		{name: "bad to", modify: func(f map[string]interface{}) { f["to"] = "recipient" }},
+		{name: "not JSON"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := []byte("{")
+			if tt.name != "not JSON" {
+				body = serviceProposal(t, tt.modify)
+			}
+			chainID := tt.chainID
+			if chainID == 0 {
+				chainID = 137
+			}
+			_, err := ImportSafeServiceProposal(body, chainID)
+			if err == nil {
+				t.Fatal("Expected error")
+			}
+			if mismatch := strings.Contains(err.Error(), "does not match the recomputed"); mismatch != tt.wantMismatch {
+				t.Errorf("error = %v, want a safeTxHash mismatch: %v", err, tt.wantMismatch)
+			}
+			var signerMismatch *errors.SignatureMismatchError
+			if stderrors.As(err, &signerMismatch) != tt.wantSignerMismatch {
+				t.Errorf("error = %v, want a SignatureMismatchError: %v", err, tt.wantSignerMismatch)
+			}
+		})
+	}
+}
+
+func TestSafeServiceProposal_RoundTrip(t *testing.T) {
+	sig, err := signer.NewSigner("ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80", 137)
+	if err != nil {
+		t.Fatalf("NewSigner failed: %v", err)
+	}
+	batch := goldenSafeArgs()
+	batch.Transactions = append(batch.Transactions, batch.Transactions[0])
+
+	tests := []struct {
+		name   string
+		args   *models.SafeTransactionArgs
+		golden string
+	}{
+		{name: "single call", args: goldenSafeArgs(), golden: "safe_service_export.json"},
+		{name: "multisend batch", args: batch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exported, err := ExportSafeServiceProposal(tt.args, sig, 137)
+			if err != nil {
+				t.Fatalf("ExportSafeServiceProposal failed: %v", err)
+			}
+			if tt.golden != "" {
+				compareGolden(t, tt.golden, append(exported, '\n'))
+			}
+
+			imported, err := ImportSafeServiceProposal(exported, 137)
+			if err != nil {
+				t.Fatalf("ImportSafeServiceProposal failed: %v", err)
+			}
+			contractConfig, _ := config.GetContractConfig(137)
+			built, err := BuildSafeTransactionRequestWithConfig(tt.args, sig, contractConfig)
+			if err != nil {
+				t.Fatalf("BuildSafeTransactionRequestWithConfig failed: %v", err)
+			}
+			if imported.Signature != built.Signature || imported.From != built.From || *imported.Nonce != *built.Nonce {
+				t.Errorf("imported request signed %s by %s, want %s by %s", imported.Signature, imported.From, built.Signature, built.From)
+			}
+		})
+	}
+
+	if _, err := ExportSafeServiceProposal(goldenSafeArgs(), sig, 80002); err == nil {
+		t.Error("Expected error for a signer of another chain")
+	}
+}
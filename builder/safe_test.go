@@ -1,11 +1,17 @@
 package builder
 
 import (
+	"bytes"
 	"encoding/hex"
+	"encoding/json"
+	stderrors "errors"
 	"math/big"
 	"strings"
 	"testing"
 
+	"github.com/davidt58/go-builder-relayer-client/config"
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/models"
 	"github.com/davidt58/go-builder-relayer-client/signer"
 	"github.com/ethereum/go-ethereum/common"
 )
@@ -102,3 +108,430 @@ func TestCreateSafeStructHash(t *testing.T) {
 		t.Logf("✓ Struct hash matches Python implementation: %s", structHash.Hex())
 	}
 }
+
+// TestSignerChainIDMismatch signs with a Polygon signer for Amoy and expects
+// every builder entry point to refuse
+func TestSignerChainIDMismatch(t *testing.T) {
+	sig, err := signer.NewSigner("ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80", 137)
+	if err != nil {
+		t.Fatalf("Failed to create signer: %v", err)
+	}
+	amoy, err := config.GetContractConfig(80002)
+	if err != nil {
+		t.Fatalf("GetContractConfig failed: %v", err)
+	}
+
+	safeAddress := "0xd93B25cb943D14d0d34FBaF01Fc93a0f8b5F6E47"
+	nonce := "3"
+	single := &models.SafeTransactionArgs{SafeAddress: safeAddress, Transactions: testSafeTransactions()[:1], Nonce: nonce}
+	batch := &models.SafeTransactionArgs{SafeAddress: safeAddress, Transactions: append(testSafeTransactions()[:1], testSafeTransactions()[0]), Nonce: nonce}
+	create := &models.SafeCreateTransactionArgs{SignerAddress: sig.AddressHex(), SkipSafeAddressCheck: true}
+
+	tests := []struct {
+		name  string
+		build func() error
+	}{
+		{"BuildSafeTransactionRequest", func() error {
+			_, err := BuildSafeTransactionRequest(single, sig, 80002)
+			return err
+		}},
+		{"BuildSafeTransactionRequestWithMultisend", func() error {
+			_, err := BuildSafeTransactionRequestWithMultisend(batch, sig, 80002, amoy.SafeMultisend)
+			return err
+		}},
+		{"BuildVersionedSafeTransactionRequest", func() error {
+			_, err := BuildVersionedSafeTransactionRequest(single, sig, 80002, amoy.SafeMultisend, models.RequestVersionV1)
+			return err
+		}},
+		{"BuildSafeTransactionRequestWithConfig", func() error {
+			_, err := BuildSafeTransactionRequestWithConfig(batch, sig, amoy)
+			return err
+		}},
+		{"PreSignBatch", func() error {
+			_, err := PreSignBatch(safeAddress, big.NewInt(0), [][]models.SafeTransaction{testSafeTransactions()}, sig, 80002, amoy.SafeMultisend)
+			return err
+		}},
+		{"CreateSafeCreateStructHash", func() error {
+			_, err := CreateSafeCreateStructHash(create, sig, 80002)
+			return err
+		}},
+		{"CreateSafeCreateSignature", func() error {
+			_, err := CreateSafeCreateSignature(create, sig, 80002)
+			return err
+		}},
+		{"BuildSafeCreateTransactionRequest", func() error {
+			_, err := BuildSafeCreateTransactionRequest(create, sig, 80002)
+			return err
+		}},
+		{"BuildSafeCreateTransactionRequestWithConfig", func() error {
+			_, err := BuildSafeCreateTransactionRequestWithConfig(create, sig, amoy)
+			return err
+		}},
+		{"VerifySafeCreationSignature", func() error {
+			_, err := VerifySafeCreationSignature(create, sig, "0x", 80002)
+			return err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.build()
+			var mismatch *errors.ChainIDMismatchError
+			if !stderrors.As(err, &mismatch) {
+				t.Fatalf("error = %v, want ChainIDMismatchError", err)
+			}
+			if mismatch.SignerChainID != 137 || mismatch.ChainID != 80002 {
+				t.Errorf("mismatch = %+v, want signer 137, chain 80002", mismatch)
+			}
+		})
+	}
+
+	// The matching chain still builds
+	if _, err := BuildSafeTransactionRequest(single, sig, 137); err != nil {
+		t.Errorf("BuildSafeTransactionRequest on the signer's chain failed: %v", err)
+	}
+}
+
+// deviceSign simulates a device signing the SafeTx hash of args (batched
+// through multisend) with key, returning the raw eth_sign signature
+func deviceSign(t *testing.T, key string, args *models.SafeTransactionArgs, multisend string) string {
+	t.Helper()
+
+	device, err := signer.NewSigner(key, 137)
+	if err != nil {
+		t.Fatalf("Failed to create signer: %v", err)
+	}
+	aggregated, err := AggregateSafeTransaction(args.Transactions, multisend)
+	if err != nil {
+		t.Fatalf("AggregateSafeTransaction failed: %v", err)
+	}
+	hash, err := CreateSafeStructHash(&models.SafeTransactionArgs{
+		SafeAddress:  args.SafeAddress,
+		Transactions: []models.SafeTransaction{*aggregated},
+		Nonce:        args.Nonce,
+	}, device)
+	if err != nil {
+		t.Fatalf("CreateSafeStructHash failed: %v", err)
+	}
+	signature, err := device.SignEIP712StructHash(hash.Bytes())
+	if err != nil {
+		t.Fatalf("SignEIP712StructHash failed: %v", err)
+	}
+	return signature
+}
+
+func TestBuildSafeTransactionRequestWithSignature(t *testing.T) {
+	const (
+		key       = "ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80"
+		otherKey  = "59c6995e998f97a5a0044966f0945389dc9e86dae88c7a8412f4603b6b78690d"
+		address   = "0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266"
+		otherAddr = "0x70997970C51812dc3A010C7d01b50e0d17dc79C8"
+	)
+	contractConfig, err := config.GetContractConfig(137)
+	if err != nil {
+		t.Fatalf("GetContractConfig failed: %v", err)
+	}
+	sig, err := signer.NewSigner(key, 137)
+	if err != nil {
+		t.Fatalf("Failed to create signer: %v", err)
+	}
+
+	single := goldenSafeArgs()
+	batch := goldenSafeArgs()
+	batch.Transactions = append(batch.Transactions, models.SafeTransaction{
+		To:    "0x4D97DCd97eC945f40cF65F87097ACe5EA0476045",
+		Value: "0",
+		Data:  "0x",
+	})
+
+	tests := []struct {
+		name         string
+		args         *models.SafeTransactionArgs
+		signature    func(raw string) models.Signature
+		signer       string
+		wantMismatch bool
+		shouldErr    bool
+	}{
+		{
+			name:      "single raw",
+			args:      single,
+			signature: func(raw string) models.Signature { return models.Signature{Data: raw} },
+			signer:    address,
+		},
+		{
+			name:      "batch raw",
+			args:      batch,
+			signature: func(raw string) models.Signature { return models.Signature{Data: raw} },
+			signer:    address,
+		},
+		{
+			name: "batch pre-packed",
+			args: batch,
+			signature: func(raw string) models.Signature {
+				packed, _ := signer.PackSignatureForSafeEthSign(raw)
+				return models.Signature{Signer: address, Data: packed, SignatureType: models.SignatureTypeEthSign}
+			},
+			signer: strings.ToLower(address),
+		},
+		{
+			name: "split",
+			args: batch,
+			signature: func(raw string) models.Signature {
+				r, s, v, _ := signer.SplitSignatureRaw(raw)
+				return models.Signature{Split: models.NewSplitSig(r, s, v)}
+			},
+			signer: address,
+		},
+		{
+			name:         "another signer",
+			args:         batch,
+			signature:    func(raw string) models.Signature { return models.Signature{Data: raw} },
+			signer:       otherAddr,
+			wantMismatch: true,
+			shouldErr:    true,
+		},
+		{
+			name:         "signer field disagrees",
+			args:         batch,
+			signature:    func(raw string) models.Signature { return models.Signature{Signer: otherAddr, Data: raw} },
+			signer:       address,
+			wantMismatch: true,
+			shouldErr:    true,
+		},
+		{
+			name: "eip712 signature type",
+			args: batch,
+			signature: func(raw string) models.Signature {
+				return models.Signature{Data: raw, SignatureType: models.SignatureTypeEIP712}
+			},
+			signer:    address,
+			shouldErr: true,
+		},
+		{
+			name:      "missing signature",
+			args:      batch,
+			signature: func(string) models.Signature { return models.Signature{} },
+			signer:    address,
+			shouldErr: true,
+		},
+		{
+			name:      "invalid signer address",
+			args:      batch,
+			signature: func(raw string) models.Signature { return models.Signature{Data: raw} },
+			signer:    "0x1234",
+			shouldErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw := deviceSign(t, key, tt.args, contractConfig.SafeMultisend)
+
+			request, err := BuildSafeTransactionRequestWithSignature(tt.args, tt.signature(raw), tt.signer, 137)
+			if (err != nil) != tt.shouldErr {
+				t.Fatalf("BuildSafeTransactionRequestWithSignature() error = %v, shouldErr %v", err, tt.shouldErr)
+			}
+			var mismatch *errors.SignatureMismatchError
+			if got := stderrors.As(err, &mismatch); got != tt.wantMismatch {
+				t.Errorf("SignatureMismatchError = %v, want %v (error: %v)", got, tt.wantMismatch, err)
+			}
+			if tt.shouldErr {
+				return
+			}
+
+			// Byte-compare with the request signed in one step
+			want, err := BuildSafeTransactionRequestWithConfig(tt.args, sig, contractConfig)
+			if err != nil {
+				t.Fatalf("BuildSafeTransactionRequestWithConfig failed: %v", err)
+			}
+			compareRequestJSON(t, request, want)
+		})
+	}
+}
+
+func TestBuildSafeTransactionRequestWithSignature_WrongPayload(t *testing.T) {
+	const key = "ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80"
+	contractConfig, _ := config.GetContractConfig(137)
+
+	batch := goldenSafeArgs()
+	batch.Transactions = append(batch.Transactions, batch.Transactions[0])
+
+	tests := []struct {
+		name   string
+		mutate func(args *models.SafeTransactionArgs) string
+	}{
+		{
+			name: "signed another nonce",
+			mutate: func(args *models.SafeTransactionArgs) string {
+				signed := *args
+				signed.Nonce = "8"
+				return deviceSign(t, key, &signed, contractConfig.SafeMultisend)
+			},
+		},
+		{
+			name: "signed the first call instead of the batch",
+			mutate: func(args *models.SafeTransactionArgs) string {
+				signed := *args
+				signed.Transactions = args.Transactions[:1]
+				return deviceSign(t, key, &signed, contractConfig.SafeMultisend)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw := tt.mutate(batch)
+			_, err := BuildSafeTransactionRequestWithSignature(batch, models.Signature{Data: raw}, "0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266", 137)
+			var mismatch *errors.SignatureMismatchError
+			if !stderrors.As(err, &mismatch) {
+				t.Fatalf("error = %v, want a SignatureMismatchError", err)
+			}
+		})
+	}
+}
+
+// compareRequestJSON fails unless got and want encode to the same bytes
+func compareRequestJSON(t *testing.T, got, want *models.TransactionRequest) {
+	t.Helper()
+
+	gotJSON, err := json.Marshal(got)
+	if err != nil {
+		t.Fatalf("failed to marshal request: %v", err)
+	}
+	wantJSON, err := json.Marshal(want)
+	if err != nil {
+		t.Fatalf("failed to marshal request: %v", err)
+	}
+	if !bytes.Equal(gotJSON, wantJSON) {
+		t.Errorf("request mismatch:\ngot:  %s\nwant: %s", gotJSON, wantJSON)
+	}
+}
+
+func TestBuildSafeTransactionRequest_SelfExec(t *testing.T) {
+	sig, err := signer.NewSigner("ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80", 137)
+	if err != nil {
+		t.Fatalf("Failed to create signer: %v", err)
+	}
+	polygon, err := config.GetContractConfig(137)
+	if err != nil {
+		t.Fatalf("GetContractConfig failed: %v", err)
+	}
+
+	safeAddress := "0xd93B25cb943D14d0d34FBaF01Fc93a0f8b5F6E47"
+	approve := testSafeTransactions()[0]
+	selfCall := func(data string) models.SafeTransaction {
+		return *models.NewSafeTransaction(strings.ToLower(safeAddress), "0", data)
+	}
+	// execTransaction with stale parameters, abbreviated
+	execTransaction := selfCall("0x6A761202" + strings.Repeat("00", 32))
+	// addOwnerWithThreshold(0x...01, 1)
+	addOwner := selfCall("0x0d582f13" + strings.Repeat("00", 31) + "01" + strings.Repeat("00", 31) + "01")
+
+	tests := []struct {
+		name         string
+		transactions []models.SafeTransaction
+		allow        bool
+		wantIndex    string
+	}{
+		{name: "execTransaction in a batch", transactions: []models.SafeTransaction{approve, execTransaction}, wantIndex: "index 1"},
+		{name: "single execTransaction", transactions: []models.SafeTransaction{execTransaction}, wantIndex: "index 0"},
+		{name: "allow-listed", transactions: []models.SafeTransaction{approve, execTransaction}, allow: true},
+		{name: "addOwnerWithThreshold", transactions: []models.SafeTransaction{approve, addOwner}},
+		{name: "execTransaction on another Safe", transactions: []models.SafeTransaction{
+			*models.NewSafeTransaction("0x1111111111111111111111111111111111111111", "0", execTransaction.Data),
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := &models.SafeTransactionArgs{SafeAddress: safeAddress, Transactions: tt.transactions, Nonce: "0", AllowSelfExec: tt.allow}
+
+			builds := map[string]func() error{
+				"WithConfig": func() error {
+					_, err := BuildSafeTransactionRequestWithConfig(args, sig, polygon)
+					return err
+				},
+				"WithMultisend": func() error {
+					_, err := BuildSafeTransactionRequestWithMultisend(args, sig, 137, polygon.SafeMultisend)
+					return err
+				},
+			}
+			for name, build := range builds {
+				err := build()
+				if tt.wantIndex == "" {
+					if err != nil {
+						t.Errorf("%s: unexpected error: %v", name, err)
+					}
+					continue
+				}
+				if err == nil || !strings.Contains(err.Error(), tt.wantIndex) || !strings.Contains(err.Error(), "execTransaction (0x6a761202)") {
+					t.Errorf("%s: err = %v, want execTransaction rejected at %s", name, err, tt.wantIndex)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildSafeTransactionRequest_TypedValues(t *testing.T) {
+	sig, err := signer.NewSigner("ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80", 137)
+	if err != nil {
+		t.Fatalf("Failed to create signer: %v", err)
+	}
+	polygon, err := config.GetContractConfig(137)
+	if err != nil {
+		t.Fatalf("GetContractConfig failed: %v", err)
+	}
+	safeAddress := "0xd93B25cb943D14d0d34FBaF01Fc93a0f8b5F6E47"
+	to := common.HexToAddress("0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174")
+	data := common.FromHex(testSafeTransactions()[0].Data)
+
+	stringArgs := func(transactions ...models.SafeTransaction) *models.SafeTransactionArgs {
+		return &models.SafeTransactionArgs{SafeAddress: safeAddress, Transactions: transactions, Nonce: "12"}
+	}
+	typedArgs := func(transactions ...models.SafeTransaction) *models.SafeTransactionArgs {
+		for i := range transactions {
+			transactions[i].Value = ""
+		}
+		return &models.SafeTransactionArgs{SafeAddress: safeAddress, Transactions: transactions, NonceInt: big.NewInt(12)}
+	}
+	typed := func(value int64) models.SafeTransaction {
+		return *models.NewSafeTransactionBig(to, big.NewInt(value), data)
+	}
+
+	// Typed-only args sign and send the same request as string-only args
+	for name, transactions := range map[string][]models.SafeTransaction{
+		"single": {typed(5)},
+		"batch":  {typed(5), typed(0)},
+	} {
+		fromStrings := make([]models.SafeTransaction, len(transactions))
+		for i, txn := range transactions {
+			fromStrings[i] = *models.NewSafeTransaction(txn.To, txn.Value, txn.Data)
+		}
+		want, err := BuildSafeTransactionRequestWithConfig(stringArgs(fromStrings...), sig, polygon)
+		if err != nil {
+			t.Fatalf("%s: string build failed: %v", name, err)
+		}
+		got, err := BuildSafeTransactionRequestWithConfig(typedArgs(transactions...), sig, polygon)
+		if err != nil {
+			t.Fatalf("%s: typed build failed: %v", name, err)
+		}
+		if got.Signature != want.Signature || string(got.Value) != string(want.Value) || *got.Nonce != *want.Nonce {
+			t.Errorf("%s: typed request = %+v, want %+v", name, got, want)
+		}
+	}
+
+	// Disagreeing string and typed values are rejected
+	inconsistentValue := typed(5)
+	inconsistentValue.Value = "6"
+	inconsistentNonce := stringArgs(typed(5))
+	inconsistentNonce.NonceInt = big.NewInt(13)
+	for name, args := range map[string]*models.SafeTransactionArgs{
+		"value": stringArgs(inconsistentValue),
+		"nonce": inconsistentNonce,
+		"batch": stringArgs(typed(1), inconsistentValue),
+	} {
+		if _, err := BuildSafeTransactionRequestWithConfig(args, sig, polygon); err == nil || !strings.Contains(err.Error(), "does not match its typed value") {
+			t.Errorf("%s: err = %v, want a typed value mismatch", name, err)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package builder
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/davidt58/go-builder-relayer-client/errors"
+)
+
+// CurrentSafeVersion is the Safe version deployed by the supported factories.
+// BuildSafeTxHash hashes for this version.
+const CurrentSafeVersion = "1.3.0"
+
+var (
+	// legacySafeTxTypeHash is the SafeTx type hash of Safes before 1.0.0,
+	// which named baseGas dataGas
+	legacySafeTxTypeHash = crypto.Keccak256Hash([]byte("SafeTx(address to,uint256 value,bytes data,uint8 operation,uint256 safeTxGas,uint256 dataGas,uint256 gasPrice,address gasToken,address refundReceiver,uint256 nonce)"))
+	// legacySafeDomainTypeHash is the EIP712Domain type hash of Safes before
+	// 1.3.0, whose domain has no chainId
+	legacySafeDomainTypeHash = crypto.Keccak256Hash([]byte("EIP712Domain(address verifyingContract)"))
+)
+
+// safeTxLayout is how a Safe version hashes a SafeTx
+type safeTxLayout struct {
+	// typeHash is the SafeTx type hash
+	typeHash common.Hash
+	// domainChainID is whether the EIP-712 domain includes the chain ID
+	domainChainID bool
+}
+
+// safeTxLayoutFor returns the SafeTx layout of a Safe version as reported by
+// VERSION(), e.g. "1.3.0" or "1.3.0+L2". An empty version means
+// CurrentSafeVersion.
+//   - >= 1.3.0: domain with chainId, baseGas
+//   - 1.0.0 - 1.2.x: domain without chainId, baseGas
+//   - < 1.0.0: domain without chainId, dataGas
+func safeTxLayoutFor(version string) (safeTxLayout, error) {
+	if version == "" {
+		version = CurrentSafeVersion
+	}
+	major, minor, err := parseSafeVersion(version)
+	if err != nil {
+		return safeTxLayout{}, err
+	}
+
+	switch {
+	case major > 1 || (major == 1 && minor >= 3):
+		return safeTxLayout{typeHash: safeTxTypeHash, domainChainID: true}, nil
+	case major == 1:
+		return safeTxLayout{typeHash: safeTxTypeHash}, nil
+	default:
+		return safeTxLayout{typeHash: legacySafeTxTypeHash}, nil
+	}
+}
+
+// parseSafeVersion returns the major and minor numbers of a Safe version,
+// ignoring the patch number and any "+L2" style suffix
+func parseSafeVersion(version string) (major, minor int, err error) {
+	core := version
+	if i := strings.IndexAny(core, "+-"); i >= 0 {
+		core = core[:i]
+	}
+	parts := strings.Split(strings.TrimPrefix(core, "v"), ".")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, 0, errors.ErrUnsupportedSafeVersion(version)
+	}
+
+	numbers := make([]int, len(parts))
+	for i, part := range parts {
+		n, convErr := strconv.Atoi(part)
+		if convErr != nil || n < 0 {
+			return 0, 0, errors.ErrUnsupportedSafeVersion(version)
+		}
+		numbers[i] = n
+	}
+	return numbers[0], numbers[1], nil
+}
+
+// SafeTxHashForVersion builds the EIP-712 hash of a Safe transaction as the
+// given Safe version computes it. For 1.3.0 and later it equals
+// BuildSafeTxHash; older Safes omit the chain ID from the domain, and Safes
+// before 1.0.0 also name baseGas dataGas.
+func SafeTxHashForVersion(safeTx *SafeTx, verifyingContract common.Address, chainID int64, version string) (common.Hash, error) {
+	if err := safeTx.validate(); err != nil {
+		return common.Hash{}, err
+	}
+	if err := checkVerifyingContract(verifyingContract); err != nil {
+		return common.Hash{}, err
+	}
+	layout, err := safeTxLayoutFor(version)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	var domainSeparator common.Hash
+	if layout.domainChainID {
+		domainSeparator = safeDomainSeparator(chainID, verifyingContract)
+	} else {
+		domainSeparator = legacySafeDomainSeparator(verifyingContract)
+	}
+	return hashSafeTxWithType(safeTx, layout.typeHash, domainSeparator)
+}
+
+// legacySafeDomainSeparator returns the domain separator of a Safe before
+// 1.3.0, which only covers the verifying contract
+func legacySafeDomainSeparator(verifyingContract common.Address) common.Hash {
+	data := make([]byte, 0, 2*32)
+	data = append(data, legacySafeDomainTypeHash[:]...)
+	data = append(data, common.BytesToHash(verifyingContract.Bytes()).Bytes()...)
+	return crypto.Keccak256Hash(data)
+}
+
+// safeTxHashFor hashes safeTx for the given Safe version, using
+// BuildSafeTxHash for the current layout
+func safeTxHashFor(safeTx *SafeTx, verifyingContract common.Address, chainID int64, version string) (common.Hash, error) {
+	if version == "" {
+		return BuildSafeTxHash(safeTx, verifyingContract, chainID)
+	}
+	return SafeTxHashForVersion(safeTx, verifyingContract, chainID, version)
+}
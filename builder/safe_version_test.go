@@ -0,0 +1,91 @@
+package builder
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// versionedSafeTx is the SafeTx hashed by the per-version golden hashes
+func versionedSafeTx() *SafeTx {
+	return &SafeTx{
+		To:        common.HexToAddress("0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174"),
+		Value:     big.NewInt(1000000),
+		Data:      hexutil.MustDecode("0xa9059cbb000000000000000000000000f39fd6e51aad88f6f4ce6ab8827279cfffb922660000000000000000000000000000000000000000000000000000000000000001"),
+		SafeTxGas: big.NewInt(50000),
+		BaseGas:   big.NewInt(21000),
+		GasPrice:  big.NewInt(0),
+		Nonce:     big.NewInt(7),
+	}
+}
+
+func TestSafeTxHashForVersion(t *testing.T) {
+	safe := common.HexToAddress("0x6e0c80c90ea6c15917308F820Eac91Ce2724B5b5")
+
+	// Golden hashes from go-ethereum's apitypes.TypedDataAndHash with each
+	// version's EIP712Domain and SafeTx types
+	const (
+		hashV130 = "0x8e4d5eaf3262018ae29993f946c7745f18b84a9ae1369187f12025d7bc5a4d41"
+		hashV111 = "0x0fb0bf6f915b16c4bc0c58a10bf2e06fc8ac80ebcb4d464a0257ab33650514fb"
+		hashV010 = "0x02c25a1745c145d4d9e8bc25ca37846ddaf0491c2102608bb6df6483b67dcafb"
+	)
+
+	tests := []struct {
+		version   string
+		want      string
+		shouldErr bool
+	}{
+		{version: "", want: hashV130},
+		{version: "1.3.0", want: hashV130},
+		{version: "1.3.0+L2", want: hashV130},
+		{version: "1.4.1", want: hashV130},
+		{version: "1.2.0", want: hashV111},
+		{version: "1.1.1", want: hashV111},
+		{version: "1.0.0", want: hashV111},
+		{version: "0.1.0", want: hashV010},
+		{version: "1", shouldErr: true},
+		{version: "1.x.0", shouldErr: true},
+		{version: "latest", shouldErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			got, err := SafeTxHashForVersion(versionedSafeTx(), safe, 137, tt.version)
+			if (err != nil) != tt.shouldErr {
+				t.Fatalf("SafeTxHashForVersion(%q) error = %v, shouldErr %v", tt.version, err, tt.shouldErr)
+			}
+			if !tt.shouldErr && got.Hex() != tt.want {
+				t.Errorf("SafeTxHashForVersion(%q) = %s, want %s", tt.version, got.Hex(), tt.want)
+			}
+		})
+	}
+}
+
+func TestSafeTxHashForVersion_MatchesBuildSafeTxHash(t *testing.T) {
+	safe := common.HexToAddress("0x6e0c80c90ea6c15917308F820Eac91Ce2724B5b5")
+
+	for _, chainID := range []int64{137, 80002} {
+		want, err := BuildSafeTxHash(versionedSafeTx(), safe, chainID)
+		if err != nil {
+			t.Fatalf("BuildSafeTxHash failed: %v", err)
+		}
+		got, err := SafeTxHashForVersion(versionedSafeTx(), safe, chainID, CurrentSafeVersion)
+		if err != nil {
+			t.Fatalf("SafeTxHashForVersion failed: %v", err)
+		}
+		if got != want {
+			t.Errorf("chain %d: SafeTxHashForVersion = %s, BuildSafeTxHash = %s", chainID, got.Hex(), want.Hex())
+		}
+
+		// Legacy domains have no chain ID
+		legacy, err := SafeTxHashForVersion(versionedSafeTx(), safe, chainID, "1.1.1")
+		if err != nil {
+			t.Fatalf("SafeTxHashForVersion failed: %v", err)
+		}
+		if legacy.Hex() != "0x0fb0bf6f915b16c4bc0c58a10bf2e06fc8ac80ebcb4d464a0257ab33650514fb" {
+			t.Errorf("chain %d: 1.1.1 hash = %s depends on the chain ID", chainID, legacy.Hex())
+		}
+	}
+}
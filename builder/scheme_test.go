@@ -0,0 +1,172 @@
+package builder
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/davidt58/go-builder-relayer-client/config"
+	"github.com/davidt58/go-builder-relayer-client/models"
+	"github.com/davidt58/go-builder-relayer-client/signer"
+)
+
+// safeCheckSignature mirrors the EOA branches of Safe.checkNSignatures for a
+// single 65-byte signature: v > 30 recovers against the EIP-191 prefixed
+// dataHash with v - 4, otherwise v is used as-is against dataHash
+func safeCheckSignature(t *testing.T, dataHash common.Hash, signature string) common.Address {
+	t.Helper()
+
+	sig, err := hexutil.Decode(signature)
+	if err != nil || len(sig) != 65 {
+		t.Fatalf("invalid signature %s: %v", signature, err)
+	}
+	v := sig[64]
+
+	hash := dataHash.Bytes()
+	switch {
+	case v == 0 || v == 1:
+		t.Fatalf("contract and approved-hash signatures are not EOA signatures (v=%d)", v)
+	case v > 30:
+		hash = crypto.Keccak256(append([]byte("\x19Ethereum Signed Message:\n32"), hash...))
+		v -= 4
+	}
+	if v != 27 && v != 28 {
+		// ecrecover returns the zero address for invalid v
+		return common.Address{}
+	}
+
+	recoverable := append(append([]byte(nil), sig[:64]...), v-27)
+	pub, err := crypto.SigToPub(hash, recoverable)
+	if err != nil {
+		return common.Address{}
+	}
+	return crypto.PubkeyToAddress(*pub)
+}
+
+// withV returns signature with its v byte replaced
+func withV(t *testing.T, signature string, v byte) string {
+	t.Helper()
+	sig, err := hexutil.Decode(signature)
+	if err != nil {
+		t.Fatalf("invalid signature: %v", err)
+	}
+	sig[64] = v
+	return hexutil.Encode(sig)
+}
+
+func TestSignatureSchemes_SafeRecovery(t *testing.T) {
+	sig, err := signer.NewSigner("ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80", 137)
+	if err != nil {
+		t.Fatalf("Failed to create signer: %v", err)
+	}
+	registered, err := config.GetContractConfig(137)
+	if err != nil {
+		t.Fatalf("GetContractConfig failed: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		scheme     signer.SignatureScheme
+		validV     []byte
+		mismatched func(v byte) byte
+	}{
+		{
+			name:       "eth_sign",
+			scheme:     signer.SchemeEthSign,
+			validV:     []byte{31, 32},
+			mismatched: func(v byte) byte { return v - 4 },
+		},
+		{
+			name:       "eip712",
+			scheme:     signer.SchemeEIP712,
+			validV:     []byte{27, 28},
+			mismatched: func(v byte) byte { return v + 4 },
+		},
+		{
+			name:       "unset defaults to eth_sign",
+			validV:     []byte{31, 32},
+			mismatched: func(v byte) byte { return v - 4 },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			contractConfig := *registered
+			contractConfig.SignatureScheme = tt.scheme
+
+			for nonce := 0; nonce < 4; nonce++ {
+				args := &models.SafeTransactionArgs{
+					SafeAddress:  "0x6e0c80c90ea6c15917308F820Eac91Ce2724B5b5",
+					Transactions: testSafeTransactions(),
+					Nonce:        string(rune('0' + nonce)),
+				}
+
+				request, err := BuildSafeTransactionRequestWithConfig(args, sig, &contractConfig)
+				if err != nil {
+					t.Fatalf("BuildSafeTransactionRequestWithConfig failed: %v", err)
+				}
+				safeTxHash, err := CreateSafeStructHash(args, sig)
+				if err != nil {
+					t.Fatalf("CreateSafeStructHash failed: %v", err)
+				}
+
+				v := hexutil.MustDecode(request.Signature)[64]
+				if v != tt.validV[0] && v != tt.validV[1] {
+					t.Fatalf("v = %d, want one of %v", v, tt.validV)
+				}
+
+				if got := safeCheckSignature(t, safeTxHash, request.Signature); got != sig.Address() {
+					t.Errorf("nonce %d: Safe recovered %s, want %s", nonce, got.Hex(), sig.AddressHex())
+				}
+
+				// The same r, s with the other scheme's v range recovers someone else
+				wrong := withV(t, request.Signature, tt.mismatched(v))
+				if got := safeCheckSignature(t, safeTxHash, wrong); got == sig.Address() {
+					t.Errorf("nonce %d: mismatched v range should not recover the signer", nonce)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildSafeTransactionRequest_DefaultSchemeUnchanged(t *testing.T) {
+	sig, err := signer.NewSigner("ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80", 137)
+	if err != nil {
+		t.Fatalf("Failed to create signer: %v", err)
+	}
+	args := &models.SafeTransactionArgs{
+		SafeAddress:  "0x6e0c80c90ea6c15917308F820Eac91Ce2724B5b5",
+		Transactions: testSafeTransactions(),
+		Nonce:        "1",
+	}
+
+	request, err := BuildSafeTransactionRequest(args, sig, 137)
+	if err != nil {
+		t.Fatalf("BuildSafeTransactionRequest failed: %v", err)
+	}
+
+	// Previous behaviour: EIP-191 prefixed signature packed with v 31/32
+	raw, err := CreateSafeSignature(args, sig)
+	if err != nil {
+		t.Fatalf("CreateSafeSignature failed: %v", err)
+	}
+	want, err := signer.PackSignatureForSafeEthSign(raw)
+	if err != nil {
+		t.Fatalf("PackSignatureForSafeEthSign failed: %v", err)
+	}
+	if request.Signature != want {
+		t.Errorf("Signature = %s, want %s", request.Signature, want)
+	}
+}
+
+// testSafeTransactions is a single ERC-20 transfer
+func testSafeTransactions() []models.SafeTransaction {
+	return []models.SafeTransaction{{
+		To:        "0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174",
+		Value:     "0",
+		Data:      "0xa9059cbb000000000000000000000000f39fd6e51aad88f6f4ce6ab8827279cfffb922660000000000000000000000000000000000000000000000000000000000000001",
+		Operation: models.Call,
+	}}
+}
@@ -0,0 +1,165 @@
+package builder
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"testing"
+
+	"github.com/davidt58/go-builder-relayer-client/config"
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/models"
+	"github.com/davidt58/go-builder-relayer-client/signer"
+)
+
+func TestSignatureType_Golden(t *testing.T) {
+	sig, err := signer.NewSigner("ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80", 137)
+	if err != nil {
+		t.Fatalf("Failed to create signer: %v", err)
+	}
+	registered, err := config.GetContractConfig(137)
+	if err != nil {
+		t.Fatalf("GetContractConfig failed: %v", err)
+	}
+	eip712Config := *registered
+	eip712Config.SignatureScheme = signer.SchemeEIP712
+	safeAddress, err := DeriveSafeAddressWithConfig(sig.Address(), registered)
+	if err != nil {
+		t.Fatalf("DeriveSafeAddressWithConfig failed: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		golden   string
+		build    func() (*models.TransactionRequest, error)
+		wantType models.SignatureType
+	}{
+		{
+			name:   "SAFE eip712",
+			golden: "safe_request_eip712.json",
+			build: func() (*models.TransactionRequest, error) {
+				return BuildSafeTransactionRequestWithConfig(goldenSafeArgs(), sig, &eip712Config)
+			},
+			wantType: models.SignatureTypeEIP712,
+		},
+		{
+			name:   "SAFE-CREATE",
+			golden: "safe_create_request.json",
+			build: func() (*models.TransactionRequest, error) {
+				return BuildSafeCreateTransactionRequestWithConfig(&models.SafeCreateTransactionArgs{
+					SignerAddress: sig.AddressHex(),
+					SafeAddress:   safeAddress.Hex(),
+					Nonce:         "0",
+				}, sig, registered)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, err := tt.build()
+			if err != nil {
+				t.Fatalf("build failed: %v", err)
+			}
+			if request.SignatureType != tt.wantType {
+				t.Errorf("SignatureType = %q, want %q", request.SignatureType, tt.wantType)
+			}
+
+			data, err := json.MarshalIndent(request, "", "  ")
+			if err != nil {
+				t.Fatalf("Marshal failed: %v", err)
+			}
+			compareGolden(t, tt.golden, append(data, '\n'))
+		})
+	}
+}
+
+func TestAssembleSafeTransactionRequest_SignatureType(t *testing.T) {
+	sig, err := signer.NewSigner("ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80", 137)
+	if err != nil {
+		t.Fatalf("Failed to create signer: %v", err)
+	}
+	args := goldenSafeArgs()
+	ethSignSig, err := CreateSafeSignatureWithScheme(args, sig, signer.SchemeEthSign)
+	if err != nil {
+		t.Fatalf("CreateSafeSignatureWithScheme failed: %v", err)
+	}
+	eip712Sig, err := CreateSafeSignatureWithScheme(args, sig, signer.SchemeEIP712)
+	if err != nil {
+		t.Fatalf("CreateSafeSignatureWithScheme failed: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		signature    string
+		sigType      models.SignatureType
+		wantMismatch bool
+	}{
+		{name: "eth_sign", signature: ethSignSig, sigType: models.SignatureTypeEthSign},
+		{name: "eip712", signature: eip712Sig, sigType: models.SignatureTypeEIP712},
+		{name: "untyped", signature: eip712Sig},
+		{name: "eth_sign with v 27/28", signature: eip712Sig, sigType: models.SignatureTypeEthSign, wantMismatch: true},
+		{name: "eip712 with v 31/32", signature: ethSignSig, sigType: models.SignatureTypeEIP712, wantMismatch: true},
+		{name: "contract with v 31/32", signature: ethSignSig, sigType: models.SignatureTypeContract, wantMismatch: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, err := assembleSafeTransactionRequest(args, sig.AddressHex(), tt.signature, tt.sigType)
+
+			var mismatch *errors.SignatureTypeMismatchError
+			if got := stderrors.As(err, &mismatch); got != tt.wantMismatch {
+				t.Fatalf("assembleSafeTransactionRequest() error = %v, want mismatch %v", err, tt.wantMismatch)
+			}
+			if tt.wantMismatch {
+				return
+			}
+			if err != nil {
+				t.Fatalf("assembleSafeTransactionRequest failed: %v", err)
+			}
+
+			encoded, err := json.Marshal(request)
+			if err != nil {
+				t.Fatalf("Marshal failed: %v", err)
+			}
+			var fields map[string]json.RawMessage
+			if err := json.Unmarshal(encoded, &fields); err != nil {
+				t.Fatalf("Unmarshal failed: %v", err)
+			}
+			// The default scheme's type is not declared
+			declared := tt.sigType != "" && tt.sigType != models.SignatureTypeEthSign
+			if _, present := fields["signatureType"]; present != declared {
+				t.Errorf("signatureType present = %v in %s", present, encoded)
+			}
+		})
+	}
+}
+
+// TestSignatureType_DefaultPayloadUnchanged checks that requests signed with
+// the default scheme are byte for byte the payloads sent before signature
+// types were declared, which relayers that infer the type from v expect
+func TestSignatureType_DefaultPayloadUnchanged(t *testing.T) {
+	sig, err := signer.NewSigner("ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80", 137)
+	if err != nil {
+		t.Fatalf("Failed to create signer: %v", err)
+	}
+
+	// Recorded before signature types existed; not rewritten by -update
+	baselines := map[models.RequestVersion]string{
+		models.RequestVersionV1: `{"type":"SAFE","from":"0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266","to":"0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174","proxyWallet":"0x6e0c80c90ea6c15917308F820Eac91Ce2724B5b5","data":"0xa9059cbb000000000000000000000000f39fd6e51aad88f6f4ce6ab8827279cfffb922660000000000000000000000000000000000000000000000000000000000000001","signature":"0xf0ba6621fd618d083f5bda52e739b198c54390872f417d18db864d61ab1307fd754eb5c0b37178a381ea0f03ff02b49e20334253abad6e12ee29f81058bdfdcd20","signatureParams":{"gasPrice":"0","operation":"0","safeTxnGas":"0","baseGas":"0","gasToken":"0x0000000000000000000000000000000000000000","refundReceiver":"0x0000000000000000000000000000000000000000"},"value":"0","nonce":"7","metadata":"golden"}`,
+		models.RequestVersionV2: `{"version":2,"type":"SAFE","from":"0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266","proxyWallet":"0x6e0c80c90ea6c15917308F820Eac91Ce2724B5b5","transactions":[{"to":"0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174","value":"0","data":"0xa9059cbb000000000000000000000000f39fd6e51aad88f6f4ce6ab8827279cfffb922660000000000000000000000000000000000000000000000000000000000000001","operation":0}],"signature":{"signer":"0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266","data":"0xf0ba6621fd618d083f5bda52e739b198c54390872f417d18db864d61ab1307fd754eb5c0b37178a381ea0f03ff02b49e20334253abad6e12ee29f81058bdfdcd20","params":{"gasPrice":"0","operation":"0","safeTxnGas":"0","baseGas":"0","gasToken":"0x0000000000000000000000000000000000000000","refundReceiver":"0x0000000000000000000000000000000000000000"}},"nonce":"7","metadata":"golden"}`,
+	}
+
+	for version, want := range baselines {
+		payload, err := BuildVersionedSafeTransactionRequest(goldenSafeArgs(), sig, 137, "", version)
+		if err != nil {
+			t.Fatalf("BuildVersionedSafeTransactionRequest(%s) failed: %v", version, err)
+		}
+		got, err := json.Marshal(payload)
+		if err != nil {
+			t.Fatalf("Marshal failed: %v", err)
+		}
+		if string(got) != want {
+			t.Errorf("%s payload changed:\ngot:  %s\nwant: %s", version, got, want)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package builder
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/davidt58/go-builder-relayer-client/models"
+	"github.com/davidt58/go-builder-relayer-client/signer"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite golden files in testdata")
+
+// goldenSafeArgs is the logical transaction encoded by the request golden files
+func goldenSafeArgs() *models.SafeTransactionArgs {
+	return &models.SafeTransactionArgs{
+		SafeAddress: "0x6e0c80c90ea6c15917308F820Eac91Ce2724B5b5",
+		Transactions: []models.SafeTransaction{{
+			To:        "0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174",
+			Value:     "0",
+			Data:      "0xa9059cbb000000000000000000000000f39fd6e51aad88f6f4ce6ab8827279cfffb922660000000000000000000000000000000000000000000000000000000000000001",
+			Operation: models.Call,
+		}},
+		Nonce:    "7",
+		Metadata: models.MetadataOf("golden"),
+	}
+}
+
+// compareGolden compares got with testdata/name, rewriting it with -update
+func compareGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name)
+	if *updateGolden {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", path, err)
+		}
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", path, err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s mismatch:\ngot:\n%s\nwant:\n%s", name, got, want)
+	}
+}
+
+func TestBuildVersionedSafeTransactionRequest_Golden(t *testing.T) {
+	sig, err := signer.NewSigner("ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80", 137)
+	if err != nil {
+		t.Fatalf("Failed to create signer: %v", err)
+	}
+
+	tests := []struct {
+		version models.RequestVersion
+		golden  string
+	}{
+		{models.RequestVersionV1, "safe_request_v1.json"},
+		{models.RequestVersionV2, "safe_request_v2.json"},
+	}
+
+	encoded := make(map[models.RequestVersion][]byte)
+	for _, tt := range tests {
+		t.Run(tt.version.String(), func(t *testing.T) {
+			payload, err := BuildVersionedSafeTransactionRequest(goldenSafeArgs(), sig, 137, "", tt.version)
+			if err != nil {
+				t.Fatalf("BuildVersionedSafeTransactionRequest failed: %v", err)
+			}
+			data, err := json.MarshalIndent(payload, "", "  ")
+			if err != nil {
+				t.Fatalf("Marshal failed: %v", err)
+			}
+			data = append(data, '\n')
+			compareGolden(t, tt.golden, data)
+			encoded[tt.version] = data
+
+			if _, version, err := models.DecodeTransactionRequest(data); err != nil || version != tt.version {
+				t.Errorf("DecodeTransactionRequest() version = %v, err = %v, want %v", version, err, tt.version)
+			}
+		})
+	}
+
+	// Both payloads describe the same logical transaction
+	diff, err := DiffEncodedTransactionRequests(encoded[models.RequestVersionV1], encoded[models.RequestVersionV2])
+	if err != nil {
+		t.Fatalf("DiffEncodedTransactionRequests failed: %v", err)
+	}
+	if !diff.Equal() {
+		t.Errorf("v1 and v2 payloads differ:\n%s", diff)
+	}
+}
+
+func TestBuildVersionedSafeTransactionRequest_UnsupportedVersion(t *testing.T) {
+	sig, err := signer.NewSigner("ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80", 137)
+	if err != nil {
+		t.Fatalf("Failed to create signer: %v", err)
+	}
+
+	for _, version := range []models.RequestVersion{0, 3} {
+		if _, err := BuildVersionedSafeTransactionRequest(goldenSafeArgs(), sig, 137, "", version); err == nil {
+			t.Errorf("Expected error for version %s", version)
+		}
+	}
+}
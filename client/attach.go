@@ -0,0 +1,32 @@
+package client
+
+import (
+	"encoding/json"
+
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+// AttachResponse rebuilds a response from a document written by its
+// MarshalJSON, e.g. in another process, and attaches it to this client so
+// its Wait and GetTransaction methods work. The document must be for the
+// client's chain. A reattached deployment records its Safe as deployed
+// once a wait sees it mined, but does not verify the deployment on chain.
+// Its LatencyBreakdown covers the states this client's waits see.
+func (c *RelayClient) AttachResponse(doc []byte) (*models.ClientRelayerTransactionResponse, error) {
+	var response models.ClientRelayerTransactionResponse
+	if err := json.Unmarshal(doc, &response); err != nil {
+		return nil, err
+	}
+	if response.ChainID != c.chainID {
+		return nil, errors.ErrTransactionChainIDMismatch(response.TransactionID, c.chainID, response.ChainID)
+	}
+
+	response.SetClient(c)
+	response.SetClock(c.clock)
+	if response.Type == models.SAFE_CREATE && response.SafeAddress != "" {
+		response.SetWaitHook(c.deploymentWaitHook(response.SafeAddress, false))
+	}
+	c.trackLatency(&response)
+	return &response, nil
+}
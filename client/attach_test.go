@@ -0,0 +1,96 @@
+package client
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"strings"
+	"testing"
+
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/models"
+	"github.com/davidt58/go-builder-relayer-client/relayertest"
+)
+
+func TestAttachResponse_RoundTrip(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	relayer.ScriptStates(models.STATE_NEW, models.STATE_MINED, models.STATE_CONFIRMED)
+
+	submitter := newTestClient(t, relayer)
+	response, err := submitter.Execute(relayertest.SampleApprovalBatch(), "")
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	doc, err := json.Marshal(response)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	// A fresh client, as in the process that picks the job up
+	waiter := newTestClient(t, relayer)
+	attached, err := waiter.AttachResponse(doc)
+	if err != nil {
+		t.Fatalf("AttachResponse failed: %v", err)
+	}
+	if attached.TransactionID != response.TransactionID || attached.OperationID != response.OperationID ||
+		attached.ChainID != 137 || attached.Type != models.SAFE || attached.Nonce != response.Nonce ||
+		attached.SafeAddress != response.SafeAddress || !attached.SubmittedAt.Equal(testClockStart) {
+		t.Errorf("attached = %+v, want the fields of %+v", attached, response)
+	}
+
+	if _, err := attached.GetTransaction(); err != nil {
+		t.Fatalf("GetTransaction failed: %v", err)
+	}
+	txn, err := attached.Wait()
+	if err != nil {
+		t.Fatalf("Wait failed: %v", err)
+	}
+	if txn.TransactionID != response.TransactionID || txn.State != models.STATE_CONFIRMED {
+		t.Errorf("waited for %s in %s, want %s confirmed", txn.TransactionID, txn.State, response.TransactionID)
+	}
+}
+
+func TestAttachResponse_Deployment(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	relayer.SetDeployed(false)
+	relayer.ScriptStates(models.STATE_MINED)
+
+	response, err := newTestClient(t, relayer).Deploy()
+	if err != nil {
+		t.Fatalf("Deploy failed: %v", err)
+	}
+	doc, err := json.Marshal(response)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	waiter := newTestClient(t, relayer)
+	attached, err := waiter.AttachResponse(doc)
+	if err != nil {
+		t.Fatalf("AttachResponse failed: %v", err)
+	}
+	if attached.Type != models.SAFE_CREATE {
+		t.Fatalf("Type = %s, want %s", attached.Type, models.SAFE_CREATE)
+	}
+	if _, err := attached.WaitUntilMined(); err != nil {
+		t.Fatalf("WaitUntilMined failed: %v", err)
+	}
+	// The wait recorded the deployment, so Execute need not check it
+	if deployed, ok := waiter.cachedDeployed(attached.SafeAddress); !ok || !deployed {
+		t.Errorf("cached deployed = %v, %v; want true", deployed, ok)
+	}
+}
+
+func TestAttachResponse_ChainMismatch(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	c := newTestClient(t, relayer)
+
+	_, err := c.AttachResponse([]byte(`{"version":1,"transactionId":"tx-1","chainId":80002}`))
+	var mismatch *errors.TransactionChainIDMismatchError
+	if !stderrors.As(err, &mismatch) || mismatch.ChainID != 137 || mismatch.TransactionChainID != 80002 {
+		t.Fatalf("error = %v, want a TransactionChainIDMismatchError", err)
+	}
+
+	if _, err := c.AttachResponse([]byte(`{"version":1,"chainId":137}`)); err == nil || !strings.Contains(err.Error(), "transactionId") {
+		t.Errorf("error = %v, want a missing transactionId", err)
+	}
+}
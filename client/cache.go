@@ -0,0 +1,186 @@
+package client
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/davidt58/go-builder-relayer-client/clock"
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+// ReadOptions configures a cached read (see WithResponseCache)
+type ReadOptions struct {
+	// SkipCache queries the relayer even when a cached response is fresh.
+	// The fresh response replaces the cached one.
+	SkipCache bool
+}
+
+// WithResponseCache caches GetDeployed and GetNonce responses, which the
+// cache is disabled without. A deployed Safe never becomes undeployed, so
+// "deployed" is kept for the life of the client and "not deployed" for
+// notDeployedTTL (10s if notDeployedTTL is not positive). A nonce is kept
+// until the client submits a transaction signed by or for that address;
+// submissions from elsewhere are not seen, so use ReadOptions.SkipCache or
+// InvalidateCache when other submitters share the Safe.
+func WithResponseCache(notDeployedTTL time.Duration) Option {
+	return func(c *RelayClient) error {
+		if notDeployedTTL <= 0 {
+			notDeployedTTL = deployedTTL
+		}
+		c.responseCache = &responseCache{notDeployedTTL: notDeployedTTL}
+		return nil
+	}
+}
+
+// responseCache holds GetDeployed and GetNonce responses. A nil cache is
+// disabled: it never has an entry and ignores stores.
+type responseCache struct {
+	// clock is the client's clock, set once options are applied
+	clock          clock.Clock
+	notDeployedTTL time.Duration
+
+	mu       sync.Mutex
+	deployed map[string]deployedEntry
+	nonces   map[nonceCacheKey]string
+}
+
+// nonceCacheKey identifies a cached nonce by lowercase address and signer type
+type nonceCacheKey struct {
+	address    string
+	signerType string
+}
+
+// cachedDeployed returns the cached deployed status of safeAddress, if fresh
+func (rc *responseCache) cachedDeployed(safeAddress string) (deployed, ok bool) {
+	if rc == nil {
+		return false, false
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	entry, ok := rc.deployed[strings.ToLower(safeAddress)]
+	if !ok {
+		return false, false
+	}
+	if !entry.deployed && clock.OrReal(rc.clock).Now().Sub(entry.checkedAt) >= rc.notDeployedTTL {
+		return false, false
+	}
+	return entry.deployed, true
+}
+
+// storeDeployed caches the deployed status of safeAddress
+func (rc *responseCache) storeDeployed(safeAddress string, deployed bool) {
+	if rc == nil {
+		return
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if rc.deployed == nil {
+		rc.deployed = make(map[string]deployedEntry)
+	}
+	rc.deployed[strings.ToLower(safeAddress)] = deployedEntry{deployed: deployed, checkedAt: clock.OrReal(rc.clock).Now()}
+}
+
+// cachedNonce returns the cached nonce of address for signerType
+func (rc *responseCache) cachedNonce(address, signerType string) (string, bool) {
+	if rc == nil {
+		return "", false
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	nonce, ok := rc.nonces[nonceCacheKey{strings.ToLower(address), signerType}]
+	return nonce, ok
+}
+
+// storeNonce caches the nonce of address for signerType
+func (rc *responseCache) storeNonce(address, signerType, nonce string) {
+	if rc == nil {
+		return
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if rc.nonces == nil {
+		rc.nonces = make(map[nonceCacheKey]string)
+	}
+	rc.nonces[nonceCacheKey{strings.ToLower(address), signerType}] = nonce
+}
+
+// invalidateSubmission drops what a submission of request makes stale: the
+// nonces of its signer and Safe, and a "not deployed" result for the Safe
+func (rc *responseCache) invalidateSubmission(request *models.TransactionRequest) {
+	if rc == nil {
+		return
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	from, safe := strings.ToLower(request.From), strings.ToLower(request.ProxyWallet)
+	for key := range rc.nonces {
+		if key.address == from || key.address == safe {
+			delete(rc.nonces, key)
+		}
+	}
+	if entry, ok := rc.deployed[safe]; ok && !entry.deployed {
+		delete(rc.deployed, safe)
+	}
+}
+
+// clear drops every cached response
+func (rc *responseCache) clear() {
+	if rc == nil {
+		return
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	rc.deployed = nil
+	rc.nonces = nil
+}
+
+// InvalidateCache drops every response cached by WithResponseCache
+func (c *ReadOnlyClient) InvalidateCache() {
+	c.responseCache.clear()
+}
+
+// GetDeployedWithOptions is GetDeployed with per-call cache options
+func (c *ReadOnlyClient) GetDeployedWithOptions(safeAddress string, opts ReadOptions) (bool, error) {
+	if !opts.SkipCache {
+		if deployed, ok := c.responseCache.cachedDeployed(safeAddress); ok {
+			return deployed, nil
+		}
+	}
+
+	deployed, err := c.getDeployed(context.Background(), safeAddress)
+	if err != nil {
+		return false, err
+	}
+	c.responseCache.storeDeployed(safeAddress, deployed)
+	return deployed, nil
+}
+
+// GetNonceWithOptions is GetNonce with per-call cache options
+func (c *ReadOnlyClient) GetNonceWithOptions(signerAddress, signerType string, opts ReadOptions) (*models.NonceResponse, error) {
+	return c.getCachedNonce(context.Background(), signerAddress, signerType, opts)
+}
+
+// getCachedNonce returns the cached nonce of signerAddress unless opts skip
+// the cache, otherwise fetches and caches it, aborting when ctx is done
+func (c *ReadOnlyClient) getCachedNonce(ctx context.Context, signerAddress, signerType string, opts ReadOptions) (*models.NonceResponse, error) {
+	if !opts.SkipCache {
+		if nonce, ok := c.responseCache.cachedNonce(signerAddress, signerType); ok {
+			return &models.NonceResponse{Nonce: nonce}, nil
+		}
+	}
+
+	response, err := c.getNonce(ctx, signerAddress, signerType)
+	if err != nil {
+		return nil, err
+	}
+	c.responseCache.storeNonce(signerAddress, signerType, response.Nonce)
+	return response, nil
+}
@@ -0,0 +1,169 @@
+package client
+
+import (
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/davidt58/go-builder-relayer-client/clock"
+	"github.com/davidt58/go-builder-relayer-client/config"
+	"github.com/davidt58/go-builder-relayer-client/relayertest"
+)
+
+// countHits counts the requests the relayer receives on path
+func countHits(f *fakeRelayer, path string) *int32 {
+	hits := new(int32)
+	f.Handle(path, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(hits, 1)
+		f.ServeDefault(w, r)
+	})
+	return hits
+}
+
+// newCachedClient creates a test client with WithResponseCache(notDeployedTTL)
+func newCachedClient(t *testing.T, f *fakeRelayer, notDeployedTTL time.Duration) *RelayClient {
+	t.Helper()
+	c, err := NewRelayClient(f.URL(), 137, testPrivateKey, config.NewBuilderConfig("test-key", "dGVzdC1zZWNyZXQ=", "test-pass"),
+		WithResponseCache(notDeployedTTL), WithClock(clock.NewAutoFake(testClockStart)), WithLogOutput(io.Discard))
+	if err != nil {
+		t.Fatalf("NewRelayClient failed: %v", err)
+	}
+	return c
+}
+
+func TestResponseCache_DisabledByDefault(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	deployedHits, nonceHits := countHits(relayer, GET_DEPLOYED), countHits(relayer, GET_NONCE)
+	c := newTestClient(t, relayer)
+
+	for i := 0; i < 3; i++ {
+		if _, err := c.GetDeployed(relayertest.TestSafeAddress(137)); err != nil {
+			t.Fatalf("GetDeployed failed: %v", err)
+		}
+		if _, err := c.GetNonce(relayertest.TestAddress, string(c.nonceSignerType)); err != nil {
+			t.Fatalf("GetNonce failed: %v", err)
+		}
+	}
+	if *deployedHits != 3 || *nonceHits != 3 {
+		t.Errorf("hits = %d deployed, %d nonce, want 3 each without a cache", *deployedHits, *nonceHits)
+	}
+}
+
+func TestResponseCache_Deployed(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	hits := countHits(relayer, GET_DEPLOYED)
+	c := newCachedClient(t, relayer, time.Minute)
+	safe := relayertest.TestSafeAddress(137)
+
+	// deployed is cached for good
+	for i := 0; i < 5; i++ {
+		if deployed, err := c.GetDeployed(safe); err != nil || !deployed {
+			t.Fatalf("GetDeployed = %v, %v", deployed, err)
+		}
+	}
+	if *hits != 1 {
+		t.Errorf("hits = %d, want 1 for repeated reads of a deployed Safe", *hits)
+	}
+	testClock(c).Advance(24 * time.Hour)
+	c.GetDeployed(safe)
+	if _, err := c.GetDeployedWithOptions(safe, ReadOptions{SkipCache: true}); err != nil {
+		t.Fatalf("GetDeployedWithOptions failed: %v", err)
+	}
+	if *hits != 2 {
+		t.Errorf("hits = %d, want 2 after one SkipCache read", *hits)
+	}
+
+	// not deployed is cached for the TTL only
+	relayer.SetDeployed(false)
+	other := "0x1111111111111111111111111111111111111111"
+	c.GetDeployed(other)
+	c.GetDeployed(other)
+	if *hits != 3 {
+		t.Errorf("hits = %d, want 3 within the not-deployed TTL", *hits)
+	}
+	testClock(c).Advance(time.Minute)
+	c.GetDeployed(other)
+	if *hits != 4 {
+		t.Errorf("hits = %d, want 4 once the not-deployed TTL expired", *hits)
+	}
+
+	c.InvalidateCache()
+	c.GetDeployed(safe)
+	if *hits != 5 {
+		t.Errorf("hits = %d, want 5 after InvalidateCache", *hits)
+	}
+}
+
+func TestResponseCache_NonceInvalidatedOnSubmit(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	relayer.SetNonce(3)
+	hits := countHits(relayer, GET_NONCE)
+	c := newCachedClient(t, relayer, 0)
+	signerType := string(c.nonceSignerType)
+
+	getNonce := func() string {
+		t.Helper()
+		response, err := c.GetNonce(relayertest.TestAddress, signerType)
+		if err != nil {
+			t.Fatalf("GetNonce failed: %v", err)
+		}
+		return response.Nonce
+	}
+
+	if getNonce() != "3" || getNonce() != "3" || *hits != 1 {
+		t.Fatalf("hits = %d, want 1 for repeated nonce reads", *hits)
+	}
+
+	// Execute's preflight reads the cached nonce, and its submission makes it stale
+	if _, err := c.Execute(relayertest.SampleApprovalBatch(), "approve"); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if *hits != 1 {
+		t.Errorf("hits = %d, want Execute to use the cached nonce", *hits)
+	}
+	if nonce := getNonce(); nonce != "4" {
+		t.Errorf("nonce after submit = %s, want 4", nonce)
+	}
+	if *hits != 2 {
+		t.Errorf("hits = %d, want 2 after the submission invalidated the nonce", *hits)
+	}
+
+	// A submission from elsewhere is only seen with SkipCache
+	relayer.SetNonce(9)
+	if nonce := getNonce(); nonce != "4" {
+		t.Errorf("cached nonce = %s, want 4", nonce)
+	}
+	response, err := c.GetNonceWithOptions(relayertest.TestAddress, signerType, ReadOptions{SkipCache: true})
+	if err != nil || response.Nonce != "9" {
+		t.Fatalf("GetNonceWithOptions = %+v, %v, want 9", response, err)
+	}
+	if nonce := getNonce(); nonce != "9" {
+		t.Errorf("nonce after SkipCache = %s, want the refreshed 9", nonce)
+	}
+}
+
+func TestResponseCache_Concurrent(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	c := newCachedClient(t, relayer, 0)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if _, err := c.GetNonce(relayertest.TestAddress, string(c.nonceSignerType)); err != nil {
+				t.Errorf("GetNonce failed: %v", err)
+			}
+			if _, err := c.GetDeployed(relayertest.TestSafeAddress(137)); err != nil {
+				t.Errorf("GetDeployed failed: %v", err)
+			}
+			if i%5 == 0 {
+				c.InvalidateCache()
+			}
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,129 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/davidt58/go-builder-relayer-client/config"
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+// CancelTransaction asks the relayer to cancel a transaction that is still in
+// STATE_NEW. Transactions that have already executed cannot be cancelled; an
+// error is returned without contacting the cancel endpoint if the current
+// state is past STATE_NEW, and the relayer rejects races it detects itself.
+func (c *RelayClient) CancelTransaction(transactionID string) (*models.CancelTransactionResponse, error) {
+	if err := c.assertBuilderCredsNeeded(); err != nil {
+		return nil, err
+	}
+
+	op := newOperation("CancelTransaction", 0)
+	defer op.cancel()
+
+	txn, err := c.cancellableTransaction(op.ctx, transactionID)
+	if err != nil {
+		return nil, op.tag(err)
+	}
+
+	response, err := c.cancelTransaction(op.ctx, txn.TransactionID, "")
+	return response, op.tag(err)
+}
+
+// ReplaceTransaction submits newTransactions through the same Safe with the
+// Safe nonce of transactionID, then marks the original superseded by the
+// replacement. Only one of the two can execute since they share a nonce.
+// If the replacement was submitted but the original could not be marked
+// superseded (e.g. it executed in the meantime), the replacement response is
+// returned together with the error.
+func (c *RelayClient) ReplaceTransaction(transactionID string, newTransactions []models.SafeTransaction, metadata string) (*models.ClientRelayerTransactionResponse, error) {
+	if err := c.assertSignerNeeded(); err != nil {
+		return nil, err
+	}
+	if err := c.assertBuilderCredsNeeded(); err != nil {
+		return nil, err
+	}
+	if len(newTransactions) == 0 {
+		return nil, errors.NewRelayerClientError("no transactions provided", nil)
+	}
+	if err := c.checkExecuteLimits(newTransactions, models.MetadataOf(metadata), config.MultisendStandard); err != nil {
+		return nil, err
+	}
+
+	op := c.newSubmitOperation("ReplaceTransaction", 0)
+	defer op.cancel()
+
+	original, err := c.cancellableTransaction(op.ctx, transactionID)
+	if err != nil {
+		return nil, op.tag(err)
+	}
+	nonce := stringValue(original.Nonce)
+	if nonce == "" {
+		return nil, op.tag(errors.ErrMissingRequiredField("nonce"))
+	}
+
+	safeAddress := ""
+	if common.IsHexAddress(original.SafeAddress) {
+		safeAddress = common.HexToAddress(original.SafeAddress).Hex()
+	}
+
+	response, err := c.executeOperation(op, safeAddress, newTransactions, models.MetadataOf(metadata), nonce, ExecuteOptions{})
+	if err != nil {
+		return nil, op.tag(err)
+	}
+
+	if _, err := c.cancelTransaction(op.ctx, transactionID, response.TransactionID); err != nil {
+		msg := fmt.Sprintf("replacement %s submitted but %s could not be marked superseded", response.TransactionID, transactionID)
+		return response, op.tag(errors.NewRelayerClientError(msg, err))
+	}
+
+	return response, nil
+}
+
+// cancellableTransaction fetches a transaction and checks it is still in STATE_NEW
+func (c *RelayClient) cancellableTransaction(ctx context.Context, transactionID string) (*models.RelayerTransaction, error) {
+	txn, err := c.getTransaction(ctx, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	if txn.State != models.STATE_NEW {
+		return txn, errors.ErrNotCancellable(transactionID, string(txn.State))
+	}
+	return txn, nil
+}
+
+// cancelTransaction posts a cancellation, optionally naming the replacement
+func (c *RelayClient) cancelTransaction(ctx context.Context, transactionID, replacedBy string) (*models.CancelTransactionResponse, error) {
+	request := &models.CancelTransactionRequest{
+		TransactionID: transactionID,
+		ReplacedBy:    replacedBy,
+	}
+
+	body, err := marshalBody(request)
+	if err != nil {
+		return nil, err
+	}
+	headers, err := c.generateBuilderHeaders("POST", CANCEL_TRANSACTION, body)
+	if err != nil {
+		return nil, err
+	}
+
+	var response models.CancelTransactionResponse
+	send := func(headers map[string]string) error {
+		return c.httpClient.PostJSONContext(ctx, CANCEL_TRANSACTION, headers, body, &response)
+	}
+	if err := c.retryUnknownKey(send(headers), "POST", CANCEL_TRANSACTION, body, send); err != nil {
+		return nil, c.quotaError(err)
+	}
+	return &response, nil
+}
+
+// stringValue dereferences s, returning "" for nil
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
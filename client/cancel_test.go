@@ -0,0 +1,235 @@
+package client
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+// cancelRecorder records cancel requests and answers them with state, or with status if non-zero
+type cancelRecorder struct {
+	mu       sync.Mutex
+	requests []models.CancelTransactionRequest
+}
+
+func (r *cancelRecorder) install(relayer *fakeRelayer, status int) {
+	relayer.Handle(CANCEL_TRANSACTION, func(w http.ResponseWriter, req *http.Request) {
+		var request models.CancelTransactionRequest
+		json.NewDecoder(req.Body).Decode(&request)
+		r.mu.Lock()
+		r.requests = append(r.requests, request)
+		r.mu.Unlock()
+
+		if status != 0 {
+			w.WriteHeader(status)
+			json.NewEncoder(w).Encode(models.ErrorResponse{Error: "transaction already executed"})
+			return
+		}
+		state := models.STATE_CANCELLED
+		if request.ReplacedBy != "" {
+			state = models.STATE_REPLACED
+		}
+		json.NewEncoder(w).Encode(models.CancelTransactionResponse{TransactionID: request.TransactionID, State: state})
+	})
+}
+
+func (r *cancelRecorder) calls() []models.CancelTransactionRequest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]models.CancelTransactionRequest(nil), r.requests...)
+}
+
+// serveTransaction makes the fake relayer report txn for every GET_TRANSACTION
+func serveTransaction(relayer *fakeRelayer, txn models.RelayerTransaction) {
+	relayer.Handle(GET_TRANSACTION, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]models.RelayerTransaction{txn})
+	})
+}
+
+func TestCancelTransaction(t *testing.T) {
+	tests := []struct {
+		name         string
+		state        models.RelayerTransactionState
+		cancelStatus int
+		wantCalls    int
+		shouldErr    bool
+	}{
+		{
+			name:      "cancel before execute",
+			state:     models.STATE_NEW,
+			wantCalls: 1,
+		},
+		{
+			name:      "cancel after mined is rejected locally",
+			state:     models.STATE_MINED,
+			wantCalls: 0,
+			shouldErr: true,
+		},
+		{
+			name:      "cancel after executed is rejected locally",
+			state:     models.STATE_EXECUTED,
+			wantCalls: 0,
+			shouldErr: true,
+		},
+		{
+			name:         "relayer rejects a cancel that raced execution",
+			state:        models.STATE_NEW,
+			cancelStatus: http.StatusConflict,
+			wantCalls:    1,
+			shouldErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			relayer := newFakeRelayer(t)
+			serveTransaction(relayer, models.RelayerTransaction{TransactionID: "tx-1", State: tt.state})
+			recorder := &cancelRecorder{}
+			recorder.install(relayer, tt.cancelStatus)
+			c := newTestClient(t, relayer)
+
+			response, err := c.CancelTransaction("tx-1")
+			if tt.shouldErr {
+				if err == nil {
+					t.Fatal("Expected error")
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("CancelTransaction failed: %v", err)
+				}
+				if response.State != models.STATE_CANCELLED {
+					t.Errorf("State = %s, want %s", response.State, models.STATE_CANCELLED)
+				}
+			}
+
+			calls := recorder.calls()
+			if len(calls) != tt.wantCalls {
+				t.Fatalf("cancel calls = %d, want %d", len(calls), tt.wantCalls)
+			}
+			if len(calls) > 0 && calls[0].TransactionID != "tx-1" {
+				t.Errorf("cancelled %s, want tx-1", calls[0].TransactionID)
+			}
+		})
+	}
+}
+
+func TestReplaceTransaction(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	c := newTestClient(t, relayer)
+	safeAddress, err := c.GetExpectedSafe()
+	if err != nil {
+		t.Fatalf("GetExpectedSafe failed: %v", err)
+	}
+
+	nonce := "0"
+	serveTransaction(relayer, models.RelayerTransaction{
+		TransactionID: "tx-original",
+		State:         models.STATE_NEW,
+		SafeAddress:   safeAddress,
+		Nonce:         &nonce,
+	})
+	recorder := &cancelRecorder{}
+	recorder.install(relayer, 0)
+
+	response, err := c.ReplaceTransaction("tx-original", testTransactions(), "replacement")
+	if err != nil {
+		t.Fatalf("ReplaceTransaction failed: %v", err)
+	}
+
+	submissions := relayer.Submissions()
+	if len(submissions) != 1 {
+		t.Fatalf("submissions = %d, want 1", len(submissions))
+	}
+	if got := submissions[0].Nonce; got == nil || *got != nonce {
+		t.Errorf("replacement nonce = %v, want %s", got, nonce)
+	}
+
+	calls := recorder.calls()
+	if len(calls) != 1 {
+		t.Fatalf("cancel calls = %d, want 1", len(calls))
+	}
+	if calls[0].TransactionID != "tx-original" || calls[0].ReplacedBy != response.TransactionID {
+		t.Errorf("cancel request = %+v, want tx-original replaced by %s", calls[0], response.TransactionID)
+	}
+}
+
+func TestReplaceTransaction_Rejected(t *testing.T) {
+	nonce := "0"
+	tests := []struct {
+		name string
+		txn  models.RelayerTransaction
+	}{
+		{
+			name: "already mined",
+			txn:  models.RelayerTransaction{TransactionID: "tx-1", State: models.STATE_MINED, Nonce: &nonce},
+		},
+		{
+			name: "unknown nonce",
+			txn:  models.RelayerTransaction{TransactionID: "tx-1", State: models.STATE_NEW},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			relayer := newFakeRelayer(t)
+			serveTransaction(relayer, tt.txn)
+			recorder := &cancelRecorder{}
+			recorder.install(relayer, 0)
+			c := newTestClient(t, relayer)
+
+			if _, err := c.ReplaceTransaction("tx-1", testTransactions(), ""); err == nil {
+				t.Fatal("Expected error")
+			}
+			if n := len(relayer.Submissions()); n != 0 {
+				t.Errorf("submissions = %d, want 0", n)
+			}
+			if n := len(recorder.calls()); n != 0 {
+				t.Errorf("cancel calls = %d, want 0", n)
+			}
+		})
+	}
+}
+
+func TestPollUntilState_Cancelled(t *testing.T) {
+	target := []models.RelayerTransactionState{models.STATE_CONFIRMED}
+
+	tests := []struct {
+		name           string
+		states         []models.RelayerTransactionState
+		wantReplacedBy string
+	}{
+		{
+			name:   "cancelled",
+			states: []models.RelayerTransactionState{models.STATE_NEW, models.STATE_CANCELLED},
+		},
+		{
+			name:   "replaced",
+			states: []models.RelayerTransactionState{models.STATE_NEW, models.STATE_REPLACED},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			relayer := newFakeRelayer(t)
+			relayer.ScriptStates(tt.states...)
+			c := newTestClient(t, relayer)
+
+			txn, err := c.PollUntilState("tx-1", target, models.STATE_FAILED, 10, 1)
+			var cancelled *errors.CancelledError
+			if !stderrors.As(err, &cancelled) {
+				t.Fatalf("error = %v, want CancelledError", err)
+			}
+			if cancelled.State != string(tt.states[len(tt.states)-1]) {
+				t.Errorf("State = %s, want %s", cancelled.State, tt.states[len(tt.states)-1])
+			}
+			if txn == nil || !txn.IsCancelled() {
+				t.Errorf("txn = %+v, want cancelled transaction", txn)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package client
+
+import (
+	"context"
+	stderrors "errors"
+
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+// knownKeyScopes are the scopes GetKeyCapabilities records as granted or denied
+var knownKeyScopes = []models.KeyScope{models.KeyScopeRead, models.KeyScopeSubmit}
+
+// GetKeyCapabilities asks the relayer's key-info endpoint for the scopes of
+// the builder API key and remembers the missing ones, so Deploy and Execute
+// fail immediately instead of being rejected after signing. It returns
+// errors.ErrKeyInfoUnavailable when the relayer has no key-info endpoint.
+func (c *RelayClient) GetKeyCapabilities() (*models.KeyCapabilities, error) {
+	return c.getKeyCapabilities(context.Background())
+}
+
+// getKeyCapabilities fetches and records the key's scopes, aborting when ctx is done
+func (c *RelayClient) getKeyCapabilities(ctx context.Context) (*models.KeyCapabilities, error) {
+	if err := c.assertBuilderCredsNeeded(); err != nil {
+		return nil, err
+	}
+	if c.keyInfoEndpointMissing() {
+		return nil, errors.ErrKeyInfoUnavailable
+	}
+
+	headers, err := c.generateBuilderHeaders("GET", GET_KEY_INFO, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var capabilities models.KeyCapabilities
+	send := func(headers map[string]string) error {
+		return c.httpClient.GetJSONContext(ctx, GET_KEY_INFO, headers, &capabilities)
+	}
+	if err := c.retryUnknownKey(send(headers), "GET", GET_KEY_INFO, nil, send); err != nil {
+		if errors.IsNotFound(err) {
+			c.keyScopesMu.Lock()
+			c.noKeyInfoEndpoint = true
+			c.keyScopesMu.Unlock()
+			return nil, errors.ErrKeyInfoUnavailable
+		}
+		return nil, err
+	}
+	if capabilities.KeyID == "" {
+		capabilities.KeyID = c.builderKeyName()
+	}
+
+	c.keyScopesMu.Lock()
+	defer c.keyScopesMu.Unlock()
+	for _, scope := range knownKeyScopes {
+		if capabilities.Has(scope) {
+			delete(c.deniedScopes, scope)
+		} else {
+			c.denyScope(scope, errors.ErrInsufficientKeyPermissions(capabilities.KeyID, string(scope), nil))
+		}
+	}
+	return &capabilities, nil
+}
+
+// checkKeyCapabilities reports the key's scopes at startup for
+// WithBuilderCredentialCheck. A relayer without a key-info endpoint passes;
+// a read-only key is logged, since it still serves reads.
+func (c *RelayClient) checkKeyCapabilities() error {
+	capabilities, err := c.GetKeyCapabilities()
+	if err == errors.ErrKeyInfoUnavailable {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !capabilities.Has(models.KeyScopeSubmit) {
+		c.logger.Printf("Builder API key %q has no %q scope: Deploy and Execute will fail", capabilities.KeyID, models.KeyScopeSubmit)
+	}
+	return nil
+}
+
+// ResetCapabilities forgets the scopes the builder API key was found to
+// lack, e.g. after the key has been upgraded, so the next submission is
+// sent to the relayer again
+func (c *RelayClient) ResetCapabilities() {
+	c.keyScopesMu.Lock()
+	defer c.keyScopesMu.Unlock()
+	c.deniedScopes = nil
+	c.noKeyInfoEndpoint = false
+}
+
+// checkKeyScope returns the cached InsufficientKeyPermissionsError if the
+// builder API key is known to lack scope
+func (c *RelayClient) checkKeyScope(scope models.KeyScope) error {
+	c.keyScopesMu.Lock()
+	defer c.keyScopesMu.Unlock()
+	if denied, ok := c.deniedScopes[scope]; ok {
+		return denied
+	}
+	return nil
+}
+
+// permissionError returns err as an InsufficientKeyPermissionsError, and
+// remembers it, when the relayer rejected a request needing scope with a 403
+// CodeInsufficientPermissions. The relayer may name the scope in the error
+// details. Other errors are returned unchanged.
+func (c *RelayClient) permissionError(err error, scope models.KeyScope) error {
+	var apiErr *errors.RelayerApiError
+	if !stderrors.As(err, &apiErr) || apiErr.StatusCode != 403 || apiErr.Code != errors.CodeInsufficientPermissions {
+		return err
+	}
+	missing := string(scope)
+	if details, ok := apiErr.Details.(map[string]interface{}); ok {
+		if named, ok := details["scope"].(string); ok && named != "" {
+			missing = named
+		}
+	}
+
+	denied := errors.ErrInsufficientKeyPermissions(c.builderKeyName(), missing, err)
+	c.keyScopesMu.Lock()
+	c.denyScope(scope, denied)
+	c.keyScopesMu.Unlock()
+	c.logger.Printf("Builder API key %q lacks the %q scope; failing further requests needing it until ResetCapabilities", denied.KeyID, denied.Scope)
+	return denied
+}
+
+// denyScope records that requests needing scope fail with denied;
+// keyScopesMu must be held
+func (c *RelayClient) denyScope(scope models.KeyScope, denied *errors.InsufficientKeyPermissionsError) {
+	if c.deniedScopes == nil {
+		c.deniedScopes = make(map[models.KeyScope]*errors.InsufficientKeyPermissionsError)
+	}
+	c.deniedScopes[scope] = denied
+}
+
+// builderKeyName identifies the active builder key: its KeyID, or its API
+// key when it has none
+func (c *RelayClient) builderKeyName() string {
+	cred := c.builderConfig.ActiveCredential()
+	if cred.KeyID != "" {
+		return cred.KeyID
+	}
+	return cred.APIKey
+}
+
+// keyInfoEndpointMissing reports whether the relayer answered GET_KEY_INFO with a 404
+func (c *RelayClient) keyInfoEndpointMissing() bool {
+	c.keyScopesMu.Lock()
+	defer c.keyScopesMu.Unlock()
+	return c.noKeyInfoEndpoint
+}
@@ -0,0 +1,190 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	stderrors "errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/davidt58/go-builder-relayer-client/config"
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/models"
+	"github.com/davidt58/go-builder-relayer-client/relayertest"
+)
+
+// rejectSubmissions makes the relayer answer every submission with a 403
+// CodeInsufficientPermissions, counting them
+func rejectSubmissions(f *fakeRelayer, details interface{}) *int32 {
+	hits := new(int32)
+	f.Handle(SUBMIT_TRANSACTION, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(hits, 1)
+		code := errors.CodeInsufficientPermissions
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(models.ErrorResponse{Error: "key is read-only", Code: &code, Details: details})
+	})
+	return hits
+}
+
+// serveKeyInfo makes the relayer's key-info endpoint report scopes, counting requests
+func serveKeyInfo(f *fakeRelayer, scopes ...models.KeyScope) *int32 {
+	hits := new(int32)
+	f.Handle(GET_KEY_INFO, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(hits, 1)
+		json.NewEncoder(w).Encode(models.KeyCapabilities{KeyID: "test-key", Scopes: scopes})
+	})
+	return hits
+}
+
+// assertMissingScope fails unless err is an InsufficientKeyPermissionsError naming scope
+func assertMissingScope(t *testing.T, err error, scope models.KeyScope) {
+	t.Helper()
+	var permErr *errors.InsufficientKeyPermissionsError
+	if !stderrors.As(err, &permErr) {
+		t.Fatalf("error = %v, want InsufficientKeyPermissionsError", err)
+	}
+	if permErr.Scope != string(scope) || permErr.KeyID != "test-key" {
+		t.Errorf("missing scope = %q of key %q, want %q of test-key", permErr.Scope, permErr.KeyID, scope)
+	}
+	if !strings.Contains(err.Error(), `"`+string(scope)+`" scope`) {
+		t.Errorf("error %q does not name the %q scope", err.Error(), scope)
+	}
+}
+
+func TestKeyPermissions_ReactiveDetection(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	submits := rejectSubmissions(relayer, nil)
+	nonces := countHits(relayer, GET_NONCE)
+	c := newTestClient(t, relayer)
+
+	_, err := c.Execute(relayertest.SampleApprovalBatch(), "")
+	assertMissingScope(t, err, models.KeyScopeSubmit)
+
+	// The determination is cached: the next call fails before the nonce
+	// fetch and signing
+	noncesBefore := atomic.LoadInt32(nonces)
+	_, err = c.Execute(relayertest.SampleApprovalBatch(), "")
+	assertMissingScope(t, err, models.KeyScopeSubmit)
+	if got := atomic.LoadInt32(submits); got != 1 {
+		t.Errorf("relayer got %d submissions, want 1", got)
+	}
+	if got := atomic.LoadInt32(nonces); got != noncesBefore {
+		t.Errorf("cached denial still fetched the nonce (%d requests, want %d)", got, noncesBefore)
+	}
+	if _, err := c.Deploy(); !stderrors.As(err, new(*errors.InsufficientKeyPermissionsError)) {
+		t.Errorf("Deploy error = %v, want the cached InsufficientKeyPermissionsError", err)
+	}
+
+	// After a reset the relayer is asked again
+	c.ResetCapabilities()
+	relayer.Handle(SUBMIT_TRANSACTION, relayer.ServeDefault)
+	if _, err := c.Execute(relayertest.SampleApprovalBatch(), ""); err != nil {
+		t.Fatalf("Execute after ResetCapabilities failed: %v", err)
+	}
+}
+
+func TestKeyPermissions_ScopeFromDetails(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	submits := rejectSubmissions(relayer, map[string]string{"scope": "safe:write"})
+	c := newTestClient(t, relayer)
+
+	_, err := c.Execute(relayertest.SampleApprovalBatch(), "")
+	assertMissingScope(t, err, "safe:write")
+
+	// Cached for submissions, naming the scope the relayer reported
+	_, err = c.Execute(relayertest.SampleApprovalBatch(), "")
+	assertMissingScope(t, err, "safe:write")
+	if got := atomic.LoadInt32(submits); got != 1 {
+		t.Errorf("relayer got %d submissions, want 1", got)
+	}
+}
+
+func TestKeyPermissions_OtherForbiddenIsUntyped(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	relayer.Handle(SUBMIT_TRANSACTION, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(models.ErrorResponse{Error: "forbidden"})
+	})
+	c := newTestClient(t, relayer)
+
+	_, err := c.Execute(relayertest.SampleApprovalBatch(), "")
+	if err == nil || stderrors.As(err, new(*errors.InsufficientKeyPermissionsError)) {
+		t.Fatalf("error = %v, want a plain API error", err)
+	}
+	if denied := c.checkKeyScope(models.KeyScopeSubmit); denied != nil {
+		t.Errorf("a 403 without the permissions code was cached: %v", denied)
+	}
+}
+
+func TestGetKeyCapabilities(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	serveKeyInfo(relayer, models.KeyScopeRead)
+	submits := countHits(relayer, SUBMIT_TRANSACTION)
+	c := newTestClient(t, relayer)
+
+	capabilities, err := c.GetKeyCapabilities()
+	if err != nil {
+		t.Fatalf("GetKeyCapabilities failed: %v", err)
+	}
+	if !capabilities.Has(models.KeyScopeRead) || capabilities.Has(models.KeyScopeSubmit) {
+		t.Errorf("capabilities = %+v, want read only", capabilities)
+	}
+
+	_, err = c.Execute(relayertest.SampleApprovalBatch(), "")
+	assertMissingScope(t, err, models.KeyScopeSubmit)
+	if got := atomic.LoadInt32(submits); got != 0 {
+		t.Errorf("relayer got %d submissions from a read-only key, want 0", got)
+	}
+
+	// An upgraded key is picked up by asking again
+	serveKeyInfo(relayer, models.KeyScopeRead, models.KeyScopeSubmit)
+	if _, err := c.GetKeyCapabilities(); err != nil {
+		t.Fatalf("GetKeyCapabilities failed: %v", err)
+	}
+	if _, err := c.Execute(relayertest.SampleApprovalBatch(), ""); err != nil {
+		t.Errorf("Execute with an upgraded key failed: %v", err)
+	}
+}
+
+func TestGetKeyCapabilities_NoEndpoint(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	hits := countHits(relayer, GET_KEY_INFO)
+	c := newTestClient(t, relayer)
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.GetKeyCapabilities(); err != errors.ErrKeyInfoUnavailable {
+			t.Fatalf("GetKeyCapabilities error = %v, want ErrKeyInfoUnavailable", err)
+		}
+	}
+	if got := atomic.LoadInt32(hits); got != 1 {
+		t.Errorf("key-info endpoint asked %d times, want once", got)
+	}
+	if _, err := c.Execute(relayertest.SampleApprovalBatch(), ""); err != nil {
+		t.Errorf("Execute failed: %v", err)
+	}
+}
+
+func TestBuilderCredentialCheck_KeyScopes(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	hits := serveKeyInfo(relayer, models.KeyScopeRead)
+
+	var logs bytes.Buffer
+	c, err := NewRelayClient(relayer.URL(), 137, testPrivateKey, config.NewBuilderConfig("test-key", "dGVzdC1zZWNyZXQ=", "test-pass"),
+		WithBuilderCredentialCheck(), WithLogOutput(&logs))
+	if err != nil {
+		t.Fatalf("NewRelayClient failed: %v", err)
+	}
+	if got := atomic.LoadInt32(hits); got != 1 {
+		t.Errorf("key-info endpoint asked %d times at startup, want once", got)
+	}
+	if !strings.Contains(logs.String(), `has no "submit" scope`) {
+		t.Errorf("read-only key was not logged: %q", logs.String())
+	}
+
+	c.logger.SetOutput(io.Discard)
+	_, err = c.Execute(relayertest.SampleApprovalBatch(), "")
+	assertMissingScope(t, err, models.KeyScopeSubmit)
+}
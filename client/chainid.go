@@ -0,0 +1,42 @@
+package client
+
+import (
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+// WithCrossChainReads lets GetTransaction, GetTransactions and
+// PollUntilState return transactions the relayer reports for another chain.
+// By default a relayer serving several chains from one host is trusted
+// only for the client's chain: a single read of another chain's
+// transaction fails with a TransactionChainIDMismatchError and lists drop
+// such transactions.
+func WithCrossChainReads() Option {
+	return func(c *RelayClient) error {
+		c.crossChainReads = true
+		return nil
+	}
+}
+
+// checkTransactionChain returns a TransactionChainIDMismatchError if txn is
+// on another chain and cross-chain reads are off. Relayers that do not
+// report a chain ID (zero) are trusted.
+func (c *ReadOnlyClient) checkTransactionChain(txn *models.RelayerTransaction) error {
+	if c.crossChainReads || txn.ChainID == 0 || txn.ChainID == c.chainID {
+		return nil
+	}
+	return errors.ErrTransactionChainIDMismatch(txn.TransactionID, c.chainID, txn.ChainID)
+}
+
+// filterTransactionChain removes the transactions on other chains from
+// txns, in place, unless cross-chain reads are on, and returns how many
+// were removed
+func (c *ReadOnlyClient) filterTransactionChain(txns []models.RelayerTransaction) ([]models.RelayerTransaction, int) {
+	kept := txns[:0]
+	for i := range txns {
+		if c.checkTransactionChain(&txns[i]) == nil {
+			kept = append(kept, txns[i])
+		}
+	}
+	return kept, len(txns) - len(kept)
+}
@@ -0,0 +1,159 @@
+package client
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+// amoyChainID is the chain a misrouted relayer response reports
+const amoyChainID = 80002
+
+// newChainClient creates a test client, permitting cross-chain reads if permissive
+func newChainClient(t *testing.T, relayer *fakeRelayer, permissive bool) *RelayClient {
+	t.Helper()
+	c := newTestClient(t, relayer)
+	if permissive {
+		if err := WithCrossChainReads()(c); err != nil {
+			t.Fatalf("WithCrossChainReads failed: %v", err)
+		}
+	}
+	return c
+}
+
+func TestGetTransaction_ChainID(t *testing.T) {
+	tests := []struct {
+		name       string
+		chainID    int64
+		permissive bool
+		wantErr    bool
+	}{
+		{name: "client chain", chainID: 137},
+		{name: "chain not reported", chainID: 0},
+		{name: "other chain", chainID: amoyChainID, wantErr: true},
+		{name: "other chain, cross-chain reads", chainID: amoyChainID, permissive: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			relayer := newFakeRelayer(t)
+			relayer.Handle(GET_TRANSACTION, func(w http.ResponseWriter, r *http.Request) {
+				json.NewEncoder(w).Encode([]models.RelayerTransaction{{
+					TransactionID: r.URL.Query().Get("id"),
+					State:         models.STATE_MINED,
+					ChainID:       tt.chainID,
+				}})
+			})
+			c := newChainClient(t, relayer, tt.permissive)
+
+			txn, err := c.GetTransaction("tx-amoy")
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("GetTransaction failed: %v", err)
+				}
+				if txn.ChainID != tt.chainID {
+					t.Errorf("ChainID = %d, want %d", txn.ChainID, tt.chainID)
+				}
+				return
+			}
+
+			var mismatch *errors.TransactionChainIDMismatchError
+			if !stderrors.As(err, &mismatch) {
+				t.Fatalf("GetTransaction = %v, want a TransactionChainIDMismatchError", err)
+			}
+			if mismatch.TransactionID != "tx-amoy" || mismatch.ChainID != 137 || mismatch.TransactionChainID != amoyChainID {
+				t.Errorf("mismatch = %+v", mismatch)
+			}
+			for _, want := range []string{"tx-amoy", "137", "80002"} {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not name %s", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestGetTransactions_ChainID(t *testing.T) {
+	tests := []struct {
+		name       string
+		permissive bool
+		want       []string
+	}{
+		{name: "strict", want: []string{"tx-polygon", "tx-unreported"}},
+		{name: "cross-chain reads", permissive: true, want: []string{"tx-polygon", "tx-amoy", "tx-unreported"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			relayer := newFakeRelayer(t)
+			relayer.Handle(GET_TRANSACTIONS, func(w http.ResponseWriter, r *http.Request) {
+				json.NewEncoder(w).Encode(models.GetTransactionsResponse{Transactions: []models.RelayerTransaction{
+					{TransactionID: "tx-polygon", ChainID: 137},
+					{TransactionID: "tx-amoy", ChainID: amoyChainID},
+					{TransactionID: "tx-unreported"},
+				}})
+			})
+			c := newChainClient(t, relayer, tt.permissive)
+
+			response, err := c.GetTransactions()
+			if err != nil {
+				t.Fatalf("GetTransactions failed: %v", err)
+			}
+			var got []string
+			for _, txn := range response.Transactions {
+				got = append(got, txn.TransactionID)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("transactions = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPollUntilState_ChainID(t *testing.T) {
+	tests := []struct {
+		name       string
+		permissive bool
+		wantPolls  int32
+	}{
+		{name: "strict", wantPolls: 1},
+		{name: "cross-chain reads", permissive: true, wantPolls: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			relayer := newFakeRelayer(t)
+			var polls int32
+			relayer.Handle(GET_TRANSACTION, func(w http.ResponseWriter, r *http.Request) {
+				state := models.STATE_MINED
+				if atomic.AddInt32(&polls, 1) > 1 {
+					state = models.STATE_CONFIRMED
+				}
+				json.NewEncoder(w).Encode([]models.RelayerTransaction{{
+					TransactionID: r.URL.Query().Get("id"),
+					State:         state,
+					ChainID:       amoyChainID,
+				}})
+			})
+			c := newChainClient(t, relayer, tt.permissive)
+
+			txn, err := c.PollUntilState("tx-amoy", []models.RelayerTransactionState{models.STATE_CONFIRMED}, models.STATE_FAILED, 5, 1)
+			var mismatch *errors.TransactionChainIDMismatchError
+			if stderrors.As(err, &mismatch) == tt.permissive {
+				t.Errorf("PollUntilState = %v, want a TransactionChainIDMismatchError: %v", err, !tt.permissive)
+			}
+			if tt.permissive && (err != nil || txn.State != models.STATE_CONFIRMED) {
+				t.Errorf("PollUntilState = %v, %v; want the confirmed transaction", txn, err)
+			}
+			if got := atomic.LoadInt32(&polls); got != tt.wantPolls {
+				t.Errorf("polled %d times, want %d", got, tt.wantPolls)
+			}
+		})
+	}
+}
@@ -1,51 +1,204 @@
 package client
 
 import (
+	"context"
 	"encoding/json"
+	stderrors "errors"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"sync"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
+
 	"github.com/davidt58/go-builder-relayer-client/builder"
+	"github.com/davidt58/go-builder-relayer-client/clock"
 	"github.com/davidt58/go-builder-relayer-client/config"
 	"github.com/davidt58/go-builder-relayer-client/errors"
 	"github.com/davidt58/go-builder-relayer-client/http"
 	"github.com/davidt58/go-builder-relayer-client/models"
+	"github.com/davidt58/go-builder-relayer-client/redact"
 	"github.com/davidt58/go-builder-relayer-client/signer"
 )
 
 // RelayClient is the main client for interacting with the Relayer API
 type RelayClient struct {
-	relayerURL     string
-	chainID        int64
-	contractConfig *config.ContractConfig
-	signer         *signer.Signer
-	builderConfig  *config.BuilderConfig
-	httpClient     *http.Client
-	logger         *log.Logger
+	// ReadOnlyClient provides the relayer URL, chain, contract config, HTTP
+	// client and the unauthenticated read methods
+	ReadOnlyClient
+
+	signer        *signer.Signer
+	builderConfig *config.BuilderConfig
+	logger        *log.Logger
+
+	// httpOptions are collected from Options and applied when building httpClient
+	httpOptions []http.ClientOption
+
+	// rpcClient is used for optional on-chain checks (see WithRPCClient)
+	rpcClient ReceiptFetcher
+
+	// clock times polling waits and the health cache (see WithClock)
+	clock clock.Clock
+
+	// deriveSafe derives a signer's Safe address; tests wrap it to count
+	// derivations
+	deriveSafe func(signerAddress common.Address, contractConfig *config.ContractConfig) (common.Address, error)
+
+	// nonceSignerType is the signer type used when Execute fetches the nonce
+	nonceSignerType models.SignerType
+
+	// strictStateTransitions makes PollUntilState fail on state regressions
+	// instead of treating them as stale reads
+	strictStateTransitions bool
+
+	// requestVersion is the submission payload schema; negotiateVersion
+	// replaces it with the relayer's newest supported version on first submit
+	versionMu        sync.Mutex
+	requestVersion   models.RequestVersion
+	negotiateVersion bool
+
+	// pollErrorBudget is the number of consecutive transient errors
+	// PollUntilState tolerates before giving up
+	pollErrorBudget int
+
+	// expiryWarning logs once that every builder credential has expired
+	expiryWarning sync.Once
+
+	// eventSink receives the state changes observed while polling (see WithEventSink)
+	eventSink EventSink
+
+	// environment labels events and metrics (see WithEnvironment and Environment)
+	environment config.Environment
+
+	// latencyResponses are the responses whose polled states are recorded
+	// for LatencyBreakdown, by transaction ID, until a terminal state;
+	// metrics receives their phases once confirmed (see WithMetricsCollector)
+	latencyMu        sync.Mutex
+	latencyResponses map[string]*models.ClientRelayerTransactionResponse
+	metrics          MetricsCollector
+
+	// queue is the lazily created SubmissionQueue used by EnqueueExecute
+	queueMu sync.Mutex
+	queue   *SubmissionQueue
+
+	// submissionStore persists in-flight submissions for RecoverPending;
+	// submittedOps maps transaction IDs to the operation IDs of their records
+	submissionStore SubmissionStore
+	submittedMu     sync.Mutex
+	submittedOps    map[string]string
+
+	// healthGate makes Deploy and Execute check the relayer status, cached
+	// for healthTTL; submitOutcomes records recent submissions for inference
+	healthGate      bool
+	healthTTL       time.Duration
+	healthMu        sync.Mutex
+	healthStatus    *models.RelayerStatus
+	healthCheckedAt time.Time
+	submitOutcomes  []bool
+
+	// coldStartTolerance makes Deploy and Execute warm the relayer up when
+	// it is unreachable before it has ever responded (see WarmUp); warmMu
+	// guards responded and warmedUp, which make that happen at most once
+	coldStartTolerance bool
+	warmUpBudget       time.Duration
+	warmMu             sync.Mutex
+	responded          bool
+	warmedUp           bool
+
+	// deployedCache holds the pre-flight deployed checks by Safe address
+	deployedMu    sync.Mutex
+	deployedCache map[string]deployedEntry
+
+	// expectedSafeAddress is the signer's Safe as derived with expectedSafeConfig
+	expectedSafeMu      sync.Mutex
+	expectedSafeAddress string
+	expectedSafeConfig  *config.ContractConfig
+
+	// safeVersions caches the VERSION() of Safes used with ExecuteOnSafe
+	safeVersionMu sync.Mutex
+	safeVersions  map[common.Address]string
+
+	// limits are the relayer request size limits checked before submission
+	limits config.RelayerLimits
+
+	// spendPolicy limits the tokens Safe transactions move (see WithSpendPolicy)
+	spendPolicy *SpendPolicy
+
+	// policy decides which operations may be signed (see WithPolicy)
+	policy Policy
+
+	// usage is the latest quota snapshot; quotaLow records whether
+	// onQuotaLow has fired for the current drop below quotaThreshold
+	usageMu         sync.Mutex
+	usage           *models.Usage
+	noUsageEndpoint bool
+	quotaThreshold  float64
+	onQuotaLow      func(models.Usage)
+	quotaLow        bool
+
+	// deniedScopes are the scopes the builder API key was found to lack, so
+	// requests needing them fail before signing; noKeyInfoEndpoint records a
+	// 404 from GET_KEY_INFO. checkKeyScopes fetches the scopes at startup.
+	keyScopesMu       sync.Mutex
+	deniedScopes      map[models.KeyScope]*errors.InsufficientKeyPermissionsError
+	noKeyInfoEndpoint bool
+	checkKeyScopes    bool
+
+	// nonceStore coordinates ReserveNonces; created on first use when unset
+	nonceStoreMu sync.Mutex
+	nonceStore   NonceStore
+	nonceTTL     time.Duration
+	onNonceGap   func(NonceGap)
+
+	// redactor scrubs the logger's output and the request dumps it logs;
+	// strictRedaction makes it panic on text that needed scrubbing
+	redactor        *redact.Redactor
+	strictRedaction bool
+	logOutput       io.Writer
+
+	// debugValidatePath is the relayer's signature debug endpoint
+	debugValidatePath string
+
+	// flags are the feature flags in effect (see WithFlags);
+	// signatureSchemeSet records an explicit WithSignatureScheme
+	flags              Flags
+	signatureSchemeSet bool
+
+	// lifecycle stops background components and refuses work after Close
+	lifecycle lifecycle
 }
 
 // NewRelayClient creates a new RelayClient instance
 // privateKey can be empty if only read operations are needed
-// builderConfig can be nil if only read operations are needed
-func NewRelayClient(relayerURL string, chainID int64, privateKey string, builderConfig *config.BuilderConfig) (*RelayClient, error) {
-	// Validate relayer URL
+// builderConfig can be nil if only read operations are needed, but if set it must be valid
+// opts can customise the client (e.g. WithPinnedCertificates)
+// relayerURL can be empty for a chain with a registered relayer (see config.LookupRelayerURL)
+func NewRelayClient(relayerURL string, chainID int64, privateKey string, builderConfig *config.BuilderConfig, opts ...Option) (*RelayClient, error) {
+	// Fall back to the relayer registered for the chain
+	var relayerEnv config.Environment
 	if relayerURL == "" {
-		return nil, errors.ErrMissingRequiredField("relayerURL")
+		var err error
+		relayerURL, relayerEnv, err = config.LookupRelayerURL(chainID)
+		if err != nil {
+			return nil, errors.NewRelayerClientError("relayerURL is required", err)
+		}
 	}
 
 	// Get contract configuration for the chain
-	contractConfig, err := config.GetContractConfig(chainID)
+	contractConfig, configVersion, err := config.GetContractConfigVersion(chainID)
 	if err != nil {
 		return nil, err
 	}
 
-	// Create HTTP client
-	httpClient := http.NewClient(relayerURL)
-
-	// Create logger
-	logger := log.New(os.Stdout, "[RelayClient] ", log.LstdFlags)
+	// Incomplete builder credentials would otherwise only fail on the first
+	// authenticated request
+	if builderConfig != nil {
+		if err := builderConfig.Validate(); err != nil {
+			return nil, err
+		}
+	}
 
 	// Create signer if private key is provided
 	var sig *signer.Signer
@@ -57,89 +210,124 @@ func NewRelayClient(relayerURL string, chainID int64, privateKey string, builder
 	}
 
 	client := &RelayClient{
-		relayerURL:     relayerURL,
-		chainID:        chainID,
-		contractConfig: contractConfig,
-		signer:         sig,
-		builderConfig:  builderConfig,
-		httpClient:     httpClient,
-		logger:         logger,
+		ReadOnlyClient: ReadOnlyClient{
+			relayerURL:     relayerURL,
+			chainID:        chainID,
+			contractConfig: contractConfig,
+			configVersion:  configVersion,
+		},
+		signer:          sig,
+		builderConfig:   builderConfig,
+		redactor:        redact.Default(),
+		logOutput:       os.Stdout,
+		clock:           clock.Real,
+		deriveSafe:      builder.DeriveSafeAddressWithConfig,
+		nonceSignerType: models.SAFE_SIGNER,
+		pollErrorBudget: defaultPollErrorBudget,
+		requestVersion:  models.RequestVersionV1,
+		limits:          config.DefaultRelayerLimits(),
+		nonceTTL:        DefaultNonceReservationTTL,
+		policy:          AllowAll{},
 	}
 
-	return client, nil
-}
+	// Apply options
+	for _, opt := range opts {
+		if err := opt(client); err != nil {
+			return nil, err
+		}
+	}
 
-// GetNonce retrieves the nonce for the signer
-func (c *RelayClient) GetNonce(signerAddress, signerType string) (*models.NonceResponse, error) {
-	// Build query parameters
-	path := fmt.Sprintf("%s?address=%s&type=%s", GET_NONCE, signerAddress, signerType)
+	if client.responseCache != nil {
+		client.responseCache.clock = client.clock
+	}
 
-	// Make GET request
-	var response models.NonceResponse
-	if err := c.httpClient.GetJSON(path, nil, &response); err != nil {
-		return nil, err
+	// Create logger; everything it writes is scrubbed, including the
+	// client's own secrets
+	client.redactor = client.redactor.WithSecrets(clientSecrets(privateKey, builderConfig)...)
+	if client.strictRedaction {
+		client.redactor = client.redactor.Strict()
+	}
+	client.logger = log.New(client.redactor.Writer(client.logOutput), "[RelayClient] ", log.LstdFlags)
+	client.applyFlags()
+	client.warnExpiredCredentials()
+	if relayerEnv != "" {
+		client.logger.Printf("Using the %s relayer for chain %d: %s", relayerEnv, chainID, relayerURL)
 	}
 
-	return &response, nil
-}
+	// The signer and contract config must target the client's chain, or
+	// requests would be signed for one chain and submitted for another
+	if err := client.checkChainID(); err != nil {
+		return nil, err
+	}
 
-// GetTransaction retrieves a transaction by ID
-func (c *RelayClient) GetTransaction(transactionID string) (*models.RelayerTransaction, error) {
-	// Build query parameters
-	path := fmt.Sprintf("%s?id=%s", GET_TRANSACTION, transactionID)
+	// Credentials tagged for one environment must not reach another's relayer
+	if err := client.checkEnvironment(); err != nil {
+		return nil, err
+	}
 
-	// Make GET request - API returns an array
-	var response []models.RelayerTransaction
-	if err := c.httpClient.GetJSON(path, nil, &response); err != nil {
+	// Create HTTP client, recording usage and relayer liveness from every response
+	client.httpOptions = append(client.httpOptions, http.WithResponseHook(client.observeResponse), http.WithResponseHook(client.observeLiveness))
+	client.httpClient, err = http.NewClientWithOptions(relayerURL, client.httpOptions...)
+	if err != nil {
 		return nil, err
 	}
 
-	// Return first transaction from array
-	if len(response) == 0 {
-		return nil, errors.NewRelayerClientError(fmt.Sprintf("transaction not found: %s", transactionID), nil)
+	if client.checkKeyScopes {
+		if err := client.checkKeyCapabilities(); err != nil {
+			return nil, err
+		}
 	}
 
-	return &response[0], nil
+	return client, nil
 }
 
-// GetTransactions retrieves all transactions for the builder
+// GetTransactions retrieves all transactions for the builder. Transactions
+// on other chains are dropped unless WithCrossChainReads is set.
 func (c *RelayClient) GetTransactions() (*models.GetTransactionsResponse, error) {
+	return c.getTransactions(context.Background())
+}
+
+// getTransactions retrieves all transactions for the builder, aborting when ctx is done
+func (c *RelayClient) getTransactions(ctx context.Context) (*models.GetTransactionsResponse, error) {
 	// Ensure builder credentials are configured
 	if err := c.assertBuilderCredsNeeded(); err != nil {
 		return nil, err
 	}
 
-	// Generate authentication headers
-	headers, err := c.generateBuilderHeaders("GET", GET_TRANSACTIONS, nil)
+	// Generate authentication headers for the path the request is sent to
+	request := getTransactionsRequest{}
+	path, headers, err := c.prepareEndpoint(request, nil)
 	if err != nil {
 		return nil, err
 	}
 
 	// Make GET request
 	var response models.GetTransactionsResponse
-	if err := c.httpClient.GetJSON(GET_TRANSACTIONS, headers, &response); err != nil {
-		return nil, err
+	send := func(headers map[string]string) error {
+		return c.httpClient.GetJSONContext(ctx, path, headers, &response)
+	}
+	if err := c.retryUnknownKey(send(headers), request.method(), path, nil, send); err != nil {
+		return nil, c.quotaError(err)
 	}
 
-	return &response, nil
-}
-
-// GetDeployed checks if a Safe wallet is deployed
-func (c *RelayClient) GetDeployed(safeAddress string) (bool, error) {
-	// Build query parameters
-	path := fmt.Sprintf("%s?address=%s", GET_DEPLOYED, safeAddress)
-
-	// Make GET request
-	var response models.DeployedResponse
-	if err := c.httpClient.GetJSON(path, nil, &response); err != nil {
-		return false, err
+	var dropped int
+	response.Transactions, dropped = c.filterTransactionChain(response.Transactions)
+	if dropped > 0 {
+		c.logger.Printf("Dropped %d transactions the relayer returned for other chains than %d", dropped, c.chainID)
 	}
 
-	return response.Deployed, nil
+	return &response, nil
 }
 
 // Deploy creates and submits a Safe wallet deployment transaction
 func (c *RelayClient) Deploy() (*models.ClientRelayerTransactionResponse, error) {
+	return c.DeployWithOptions(DeployOptions{})
+}
+
+// DeployWithOptions creates and submits a Safe wallet deployment transaction.
+// If opts.Timeout is set, the whole sequence must finish within it or an
+// OperationTimeoutError naming the in-flight step is returned.
+func (c *RelayClient) DeployWithOptions(opts DeployOptions) (*models.ClientRelayerTransactionResponse, error) {
 	c.logger.Println("Starting Safe wallet deployment...")
 
 	// Ensure signer is configured
@@ -152,47 +340,79 @@ func (c *RelayClient) Deploy() (*models.ClientRelayerTransactionResponse, error)
 		return nil, err
 	}
 
+	if opts.VerifyDeployment && c.rpcClient == nil {
+		return nil, errors.ErrInvalidConfiguration("VerifyDeployment requires an RPC client")
+	}
+	if opts.Metadata != nil {
+		if err := c.limits.CheckMetadata(*opts.Metadata); err != nil {
+			return nil, err
+		}
+	}
+
+	op := c.newSubmitOperation("Deploy", opts.Timeout)
+	defer op.cancel()
+	c.logger.Printf("Operation ID: %s", op.id)
+
+	response, err := c.deploy(op, opts)
+	var retry bool
+	if retry, err = c.warmUpOnColdStart(op, err); retry {
+		response, err = c.deploy(op, opts)
+	}
+	return response, op.tag(err)
+}
+
+// deploy runs the deployed check, build and submit steps of a Deploy within op's budget
+func (c *RelayClient) deploy(op *operation, opts DeployOptions) (*models.ClientRelayerTransactionResponse, error) {
 	signerAddress := c.signer.AddressHex()
 	c.logger.Printf("Signer address: %s", signerAddress)
 	c.logger.Printf("Chain ID: %d", c.chainID)
 
-	// Get expected Safe address
-	safeAddress, err := c.GetExpectedSafe()
+	c.logger.Println("Checking if Safe is already deployed...")
+	info, err := c.preflight(op, preflightOptions{purpose: preflightDeploy, forceSubmit: opts.ForceSubmit})
 	if err != nil {
-		c.logger.Printf("Error deriving Safe address: %v", err)
 		return nil, err
 	}
+	safeAddress := info.SafeAddress
 	c.logger.Printf("Derived Safe address: %s", safeAddress)
 
-	// Check if already deployed
-	c.logger.Println("Checking if Safe is already deployed...")
-	deployed, err := c.GetDeployed(safeAddress)
-	if err == nil && deployed {
-		errMsg := fmt.Sprintf("Safe already deployed at %s", safeAddress)
-		c.logger.Println(errMsg)
-		return nil, errors.NewRelayerClientError(errMsg, nil)
+	// Nothing is signed for a deployment the policy denies
+	if err := c.checkPolicy(models.SAFE_CREATE, safeAddress, nil); err != nil {
+		return nil, err
+	}
+
+	// The relayer rejects a second deployment while one is in flight; point
+	// the caller at the pending one instead
+	err = op.run(stepDeployedCheck, func(ctx context.Context) error {
+		return c.checkPendingDeployment(ctx, safeAddress, info.Nonce)
+	})
+	if err != nil {
+		return nil, err
 	}
-	c.logger.Println("Safe not yet deployed, proceeding with deployment")
 
-	// For SAFE-CREATE transactions, nonce is always "0" for the EOA signature
-	// The relayer will handle the actual nonce internally
 	// Build Safe creation transaction request
 	createArgs := &models.SafeCreateTransactionArgs{
 		SignerAddress: signerAddress,
 		SafeAddress:   safeAddress,
-		Nonce:         "0",
-		Metadata:      "",
+		Nonce:         info.Nonce,
+		Metadata:      opts.Metadata,
 	}
 
+	contractConfig := c.currentContractConfig()
 	c.logger.Println("Building SAFE-CREATE transaction request...")
-	c.logger.Printf("Factory address: %s", c.contractConfig.SafeFactory)
-	c.logger.Printf("Singleton address: %s", c.contractConfig.SafeSingleton)
+	c.logger.Printf("Factory address: %s", contractConfig.SafeFactory)
+	c.logger.Printf("Singleton address: %s", contractConfig.SafeSingleton)
 
-	request, err := builder.BuildSafeCreateTransactionRequest(createArgs, c.signer, c.chainID)
+	var request *models.TransactionRequest
+	err = op.run(stepBuild, func(ctx context.Context) error {
+		var buildErr error
+		request, buildErr = builder.BuildSafeCreateTransactionRequestWithConfig(createArgs, c.signer, contractConfig)
+		return buildErr
+	})
 	if err != nil {
 		c.logger.Printf("Error building transaction request: %v", err)
 		return nil, err
 	}
+	op.signed = c.clock.Now()
 
 	c.logger.Printf("Transaction type: %s", request.Type)
 	c.logger.Printf("Transaction from: %s", request.From)
@@ -200,11 +420,22 @@ func (c *RelayClient) Deploy() (*models.ClientRelayerTransactionResponse, error)
 	c.logger.Println("Submitting transaction to relayer...")
 
 	// Submit the transaction
-	response, err := c.submitTransaction(request)
+	var response *models.ClientRelayerTransactionResponse
+	err = op.run(stepSubmit, func(ctx context.Context) error {
+		var submitErr error
+		response, submitErr = c.submitTransaction(ctx, request)
+		return submitErr
+	})
 	if err != nil {
 		c.logger.Printf("Error submitting transaction: %v", err)
 		return nil, err
 	}
+	op.stamp(response)
+
+	// A cached "not deployed" result is stale once the deployment is in
+	// flight; once a wait sees it mined, the next Execute need not check
+	c.forgetDeployed(safeAddress)
+	response.SetWaitHook(c.deploymentWaitHook(safeAddress, opts.VerifyDeployment))
 
 	c.logger.Printf("✓ Transaction submitted successfully!")
 	c.logger.Printf("Transaction ID: %s", response.TransactionID)
@@ -214,8 +445,102 @@ func (c *RelayClient) Deploy() (*models.ClientRelayerTransactionResponse, error)
 	return response, nil
 }
 
+// deploymentWaitHook returns the wait hook of a deployment of safeAddress:
+// it records the Safe as deployed once mined, after verifying the
+// deployment on chain when verify is set
+func (c *RelayClient) deploymentWaitHook(safeAddress string, verify bool) func(*models.RelayerTransaction) error {
+	return func(txn *models.RelayerTransaction) error {
+		if verify {
+			if err := c.verifyDeployedTransaction(txn); err != nil {
+				return err
+			}
+		}
+		if txn.State == models.STATE_MINED || txn.State == models.STATE_CONFIRMED {
+			c.recordDeployed(safeAddress, true)
+		}
+		return nil
+	}
+}
+
 // Execute submits one or more transactions to be executed through the Safe
 func (c *RelayClient) Execute(transactions []models.SafeTransaction, metadata string) (*models.ClientRelayerTransactionResponse, error) {
+	return c.ExecuteWithOptions(transactions, metadata, ExecuteOptions{})
+}
+
+// ExecuteWithOptions submits one or more transactions to be executed through the Safe.
+// If opts.Timeout is set, the whole sequence must finish within it or an
+// OperationTimeoutError naming the in-flight step is returned.
+func (c *RelayClient) ExecuteWithOptions(transactions []models.SafeTransaction, metadata string, opts ExecuteOptions) (*models.ClientRelayerTransactionResponse, error) {
+	if err := c.assertSignerNeeded(); err != nil {
+		return nil, err
+	}
+
+	// Get nonce for the signer address (EOA), not the Safe address
+	// This matches Python: get_nonce(from_address, TransactionType.SAFE.value)
+	return c.execute(c.signer.AddressHex(), "", transactions, metadata, opts)
+}
+
+// ExecuteOnSafe submits transactions through an existing Safe the signer owns
+// (e.g. one created with the Safe web app) instead of the derived Safe. The
+// nonce is fetched for safeAddress and the transaction is signed with
+// safeAddress as the EIP-712 verifying contract. Ownership is not checked
+// locally; the relayer rejects signatures from non-owners.
+func (c *RelayClient) ExecuteOnSafe(safeAddress string, transactions []models.SafeTransaction, metadata string) (*models.ClientRelayerTransactionResponse, error) {
+	if !common.IsHexAddress(safeAddress) {
+		return nil, errors.ErrInvalidAddress(safeAddress)
+	}
+	safeAddress = common.HexToAddress(safeAddress).Hex()
+
+	return c.execute(safeAddress, safeAddress, transactions, metadata, ExecuteOptions{})
+}
+
+// SubmitSignedRequest submits a request that was built and signed earlier,
+// e.g. one item of builder.PreSignBatch. The request is sent as-is; it only
+// succeeds if its nonce is still the Safe's next nonce, which is checked
+// before submitting (see SubmitOptions.SkipFreshnessCheck).
+func (c *RelayClient) SubmitSignedRequest(request *models.TransactionRequest) (*models.ClientRelayerTransactionResponse, error) {
+	return c.SubmitSignedRequestWithOptions(request, SubmitOptions{})
+}
+
+// SubmitSignedRequestWithOptions is SubmitSignedRequest with per-call options
+func (c *RelayClient) SubmitSignedRequestWithOptions(request *models.TransactionRequest, opts SubmitOptions) (*models.ClientRelayerTransactionResponse, error) {
+	if request == nil {
+		return nil, errors.ErrMissingRequiredField("request")
+	}
+	if request.Signature == "" {
+		return nil, errors.ErrMissingRequiredField("signature")
+	}
+	if err := c.assertBuilderCredsNeeded(); err != nil {
+		return nil, err
+	}
+
+	op := c.newSubmitOperation("SubmitSignedRequest", opts.Timeout)
+	defer op.cancel()
+
+	if !opts.SkipFreshnessCheck {
+		err := op.run(stepNonce, func(ctx context.Context) error {
+			return c.checkNonceFreshness(ctx, request)
+		})
+		if err != nil {
+			return nil, op.tag(err)
+		}
+	}
+
+	var response *models.ClientRelayerTransactionResponse
+	err := op.run(stepSubmit, func(ctx context.Context) error {
+		var submitErr error
+		response, submitErr = c.submitTransaction(ctx, request)
+		return submitErr
+	})
+	if err == nil {
+		op.stamp(response)
+	}
+	return response, op.tag(err)
+}
+
+// execute runs the nonce, build and submit steps of an Execute. The nonce is
+// fetched for nonceAddress; an empty safeAddress means the derived Safe.
+func (c *RelayClient) execute(nonceAddress, safeAddress string, transactions []models.SafeTransaction, metadata string, opts ExecuteOptions) (*models.ClientRelayerTransactionResponse, error) {
 	// Ensure signer is configured
 	if err := c.assertSignerNeeded(); err != nil {
 		return nil, err
@@ -229,50 +554,140 @@ func (c *RelayClient) Execute(transactions []models.SafeTransaction, metadata st
 	if len(transactions) == 0 {
 		return nil, errors.NewRelayerClientError("no transactions provided", nil)
 	}
+	if err := opts.Multisend.Validate(); err != nil {
+		return nil, err
+	}
+
+	meta := models.MetadataOf(metadata)
+	if opts.Metadata != nil {
+		meta = opts.Metadata
+	}
+
+	// The relayer rejects oversized requests with a generic 400; fail before
+	// anything is fetched or signed
+	if err := c.checkExecuteLimits(transactions, meta, opts.Multisend); err != nil {
+		return nil, err
+	}
 
-	// Get expected Safe address
-	safeAddress, err := c.GetExpectedSafe()
+	op := c.newSubmitOperation("Execute", opts.Timeout)
+	defer op.cancel()
+
+	run := func() (*models.ClientRelayerTransactionResponse, error) {
+		info, err := c.preflight(op, preflightOptions{
+			purpose:           preflightExecute,
+			safeAddress:       safeAddress,
+			nonceAddress:      nonceAddress,
+			forceSubmit:       opts.ForceSubmit,
+			skipDeployedCheck: opts.SkipDeployedCheck,
+			autoDeploy:        opts.AutoDeploy && safeAddress == "",
+		})
+		if err != nil {
+			return nil, err
+		}
+		return c.executeOperation(op, safeAddress, transactions, meta, info.Nonce, opts)
+	}
+
+	response, err := run()
+	var retry bool
+	if retry, err = c.warmUpOnColdStart(op, err); retry {
+		response, err = run()
+	}
+	return response, op.tag(err)
+}
+
+// executeWithNonce builds, signs and submits a Safe transaction using the given nonce
+func (c *RelayClient) executeWithNonce(transactions []models.SafeTransaction, metadata, nonce string) (*models.ClientRelayerTransactionResponse, error) {
+	op := c.newSubmitOperation("Execute", 0)
+	defer op.cancel()
+
+	response, err := c.executeOperation(op, "", transactions, models.MetadataOf(metadata), nonce, ExecuteOptions{})
+	return response, op.tag(err)
+}
+
+// executeOperation runs the build and submit steps of an Execute within op's
+// budget, applying opts' MultiSend variant, spend policy override and
+// AllowSelfExec; an empty safeAddress means the derived Safe.
+func (c *RelayClient) executeOperation(op *operation, safeAddress string, transactions []models.SafeTransaction, metadata *string, nonce string, opts ExecuteOptions) (*models.ClientRelayerTransactionResponse, error) {
+	// An existing Safe may predate the current SafeTx layout
+	version, err := c.detectSafeVersion(op, safeAddress)
 	if err != nil {
 		return nil, err
 	}
 
-	// Get signer (EOA) address - this is the "from" address
-	fromAddress := c.signer.AddressHex()
+	// Default to the expected (derived) Safe address
+	if safeAddress == "" {
+		derived, err := c.GetExpectedSafe()
+		if err != nil {
+			return nil, errors.ErrStaged(errors.StageDerive, err)
+		}
+		safeAddress = derived
+	}
 
-	// Get nonce for the signer address (EOA), not the Safe address
-	// This matches Python: get_nonce(from_address, TransactionType.SAFE.value)
-	nonceResp, err := c.GetNonce(fromAddress, string(models.SAFE))
+	// Nothing is signed for a batch the policy denies or over the spend policy
+	if err := c.checkPolicy(models.SAFE, safeAddress, transactions); err != nil {
+		return nil, err
+	}
+	spends, err := c.checkSpendPolicy(safeAddress, transactions, opts.OverrideToken)
 	if err != nil {
 		return nil, err
 	}
+	// The spends stay reserved only once the batch is submitted
+	submitted := false
+	defer func() {
+		if !submitted {
+			c.releaseSpends(spends)
+		}
+	}()
 
 	// Build Safe transaction request
 	txArgs := &models.SafeTransactionArgs{
-		SafeAddress:  safeAddress,
-		Transactions: transactions,
-		Nonce:        nonceResp.Nonce,
-		Metadata:     metadata,
+		SafeAddress:   safeAddress,
+		Transactions:  transactions,
+		Nonce:         nonce,
+		Metadata:      metadata,
+		AllowSelfExec: opts.AllowSelfExec,
 	}
 
 	var request *models.TransactionRequest
-	if len(transactions) > 1 {
-		// Use multisend for multiple transactions
-		request, err = builder.BuildSafeTransactionRequestWithMultisend(txArgs, c.signer, c.chainID, c.contractConfig.SafeMultisend)
-	} else {
-		// Single transaction
-		request, err = builder.BuildSafeTransactionRequest(txArgs, c.signer, c.chainID)
+	err = op.run(stepBuild, func(ctx context.Context) error {
+		// Multiple transactions are batched through multisend; the signature
+		// scheme comes from the contract config
+		var buildErr error
+		request, buildErr = builder.BuildSafeTransactionRequestForSafeVersion(txArgs, c.signer, c.currentContractConfig(), opts.Multisend, version)
+		return buildErr
+	})
+	if err != nil {
+		return nil, err
 	}
+	op.signed = c.clock.Now()
 
+	// Submit the transaction
+	var response *models.ClientRelayerTransactionResponse
+	err = op.run(stepSubmit, func(ctx context.Context) error {
+		var submitErr error
+		response, submitErr = c.submitTransaction(ctx, request)
+		return submitErr
+	})
 	if err != nil {
 		return nil, err
 	}
+	op.stamp(response)
+	submitted = true
 
-	// Submit the transaction
-	return c.submitTransaction(request)
+	return response, nil
 }
 
 // PollUntilState polls a transaction until it reaches one of the target states
 func (c *RelayClient) PollUntilState(transactionID string, states []models.RelayerTransactionState, failState models.RelayerTransactionState, maxPolls, pollFrequency int) (*models.RelayerTransaction, error) {
+	op := newOperation("PollUntilState", 0)
+	defer op.cancel()
+
+	txn, err := c.pollUntilState(op.ctx, transactionID, states, failState, maxPolls, pollFrequency)
+	return txn, op.tag(err)
+}
+
+// pollUntilState polls a transaction, sending every request with ctx
+func (c *RelayClient) pollUntilState(ctx context.Context, transactionID string, states []models.RelayerTransactionState, failState models.RelayerTransactionState, maxPolls, pollFrequency int) (*models.RelayerTransaction, error) {
 	if maxPolls <= 0 {
 		maxPolls = 100 // Default max polls
 	}
@@ -280,40 +695,159 @@ func (c *RelayClient) PollUntilState(transactionID string, states []models.Relay
 		pollFrequency = 2 // Default 2 seconds
 	}
 
-	// Create a map of target states for quick lookup
-	targetStates := make(map[models.RelayerTransactionState]bool)
-	for _, state := range states {
-		targetStates[state] = true
+	// Poll until target state is reached or max polls exceeded. With
+	// long-polling the relayer holds each request for up to the interval.
+	interval := time.Duration(pollFrequency) * time.Second
+	p := newTransactionPoll(transactionID, states, failState)
+	if c.longPollAvailable(ctx) > 0 {
+		p.longPoll = interval
 	}
-
-	// Poll until target state is reached or max polls exceeded
 	for i := 0; i < maxPolls; i++ {
-		// Get transaction
-		txn, err := c.GetTransaction(transactionID)
-		if err != nil {
-			return nil, err
+		if done, txn, err := c.pollOnce(ctx, p); done {
+			c.emitWaitEnd(p, txn, err)
+			return txn, err
 		}
 
-		// Check if in target state
-		if targetStates[txn.State] {
-			return txn, nil
+		// Wait before next poll, unless the relayer already waited
+		if !p.longPolled {
+			c.clock.Sleep(interval)
 		}
+	}
+
+	err := errors.ErrPollingTimeout(transactionID)
+	c.emitWaitTimeout(p, err)
+	return p.lastTxn, err
+}
+
+// transactionPoll is the progress of waiting for one transaction
+type transactionPoll struct {
+	transactionID     string
+	targetStates      stateSet
+	failState         models.RelayerTransactionState
+	lastState         models.RelayerTransactionState
+	lastTxn           *models.RelayerTransaction
+	consecutiveErrors int
+	// attempts counts the polls so far, including failed ones
+	attempts int
+	// longPoll is how long each request asks the relayer to wait for a
+	// change, zero for plain requests; longPolled reports whether the last
+	// request was a successful long-poll
+	longPoll   time.Duration
+	longPolled bool
+	// fetched and last are reused by every poll: each response is decoded
+	// into fetched and copied to last once accepted, which lastTxn then
+	// points to, so polling does not allocate a transaction per request
+	fetched models.RelayerTransaction
+	last    models.RelayerTransaction
+}
+
+// stateSet is a set of transaction states. Target sets hold a few states,
+// so a scan is as fast as a map lookup and needs no allocation.
+type stateSet []models.RelayerTransactionState
 
-		// Check if in fail state
-		if failState != "" && txn.State == failState {
-			return txn, errors.ErrTransactionFailed(transactionID, string(txn.State))
+// contains reports whether state is in the set
+func (s stateSet) contains(state models.RelayerTransactionState) bool {
+	for _, target := range s {
+		if target == state {
+			return true
 		}
+	}
+	return false
+}
 
-		// Check if in a terminal failure state
-		if txn.IsFailed() {
-			return txn, errors.ErrTransactionFailed(transactionID, string(txn.State))
+// newTransactionPoll starts waiting for transactionID to reach one of states
+func newTransactionPoll(transactionID string, states []models.RelayerTransactionState, failState models.RelayerTransactionState) *transactionPoll {
+	return &transactionPoll{transactionID: transactionID, targetStates: stateSet(states), failState: failState}
+}
+
+// pollOnce fetches the transaction once and reports whether the wait is
+// over, with its outcome. Transient errors and stale reads do not end it.
+func (c *RelayClient) pollOnce(ctx context.Context, p *transactionPoll) (bool, *models.RelayerTransaction, error) {
+	transactionID := p.transactionID
+	p.attempts++
+
+	// Get transaction
+	txn := &p.fetched
+	var err error
+	if p.longPoll > 0 {
+		err = c.getTransactionWaitInto(ctx, transactionID, p.longPoll, txn)
+	} else {
+		err = c.fetchTransactionInto(ctx, transactionID, 0, txn)
+	}
+	p.longPolled = p.longPoll > 0 && err == nil
+	if err != nil {
+		// Transient errors consume a poll slot instead of aborting the wait
+		if !isTransientPollError(ctx, err, p.lastTxn != nil) {
+			return true, p.lastTxn, err
+		}
+		p.consecutiveErrors++
+		if p.consecutiveErrors >= c.pollErrorBudget {
+			return true, p.lastTxn, errors.ErrPollErrorBudgetExhausted(transactionID, p.consecutiveErrors, string(p.lastState), err)
+		}
+		c.logger.Printf("Transient error polling transaction %s (%d/%d): %v", transactionID, p.consecutiveErrors, c.pollErrorBudget, err)
+		return false, nil, nil
+	}
+	p.consecutiveErrors = 0
+
+	// A state earlier than one already seen is usually a stale read from a
+	// lagging replica; skip it unless strict state transitions are enabled
+	if p.lastState != "" && models.IsRegression(p.lastState, txn.State) {
+		c.logger.Printf("Transaction %s state regressed from %s to %s", transactionID, p.lastState, txn.State)
+		if c.strictStateTransitions {
+			return true, txn, errors.ErrInvalidTransition(transactionID, string(p.lastState), string(txn.State))
 		}
+		return false, nil, nil
+	}
+	p.last = *txn
+	txn = &p.last
+	c.emitStateChange(p, p.lastState, txn)
+	p.lastState = txn.State
+	p.lastTxn = txn
+	c.observeLatency(txn)
+	if txn.State.IsTerminal() {
+		c.resolveSubmission(transactionID)
+	}
+
+	// Check if in target state
+	if p.targetStates.contains(txn.State) {
+		return true, txn, nil
+	}
 
-		// Wait before next poll
-		time.Sleep(time.Duration(pollFrequency) * time.Second)
+	// Cancellation is reported separately from failure
+	if txn.IsCancelled() {
+		return true, txn, errors.ErrTransactionCancelled(transactionID, string(txn.State), stringValue(txn.ReplacedBy))
 	}
 
-	return nil, errors.ErrPollingTimeout(transactionID)
+	// Check if in fail state
+	if p.failState != "" && txn.State == p.failState {
+		return true, txn, errors.ErrTransactionFailed(transactionID, string(txn.State))
+	}
+
+	// Check if in a terminal failure state
+	if txn.IsFailed() {
+		return true, txn, errors.ErrTransactionFailed(transactionID, string(txn.State))
+	}
+	return false, nil, nil
+}
+
+// isTransientPollError reports whether a failed poll should be retried rather
+// than failing the wait. Rate limiting, 5xx responses and errors classified
+// by http.RetryableError are transient; a not-found response is transient
+// only until the transaction has been observed (relayer indexing lag).
+// Cancellation, auth failures and other 4xx responses are not.
+func isTransientPollError(ctx context.Context, err error, seen bool) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if errors.IsNotFound(err) {
+		return !seen
+	}
+
+	var apiErr *errors.RelayerApiError
+	if stderrors.As(err, &apiErr) {
+		return apiErr.StatusCode == 429 || apiErr.StatusCode >= 500
+	}
+	return http.RetryableError(err)
 }
 
 // GetExpectedSafe derives the expected Safe address for the signer
@@ -322,46 +856,170 @@ func (c *RelayClient) GetExpectedSafe() (string, error) {
 		return "", err
 	}
 
-	safeAddress, err := builder.DeriveSafeAddress(c.signer.Address(), c.chainID)
+	return c.expectedSafe()
+}
+
+// submitTransaction submits a transaction request to the relayer
+func (c *RelayClient) submitTransaction(ctx context.Context, request *models.TransactionRequest) (*models.ClientRelayerTransactionResponse, error) {
+	if err := c.checkKeyScope(models.KeyScopeSubmit); err != nil {
+		return nil, err
+	}
+
+	// Debug: Print the request being sent, redacted
+	operationID := http.OperationIDFromContext(ctx)
+	c.logger.Printf("DEBUG: Submitting transaction request (operation %s):\n%s", operationID, c.redactor.JSON(request))
+
+	// Encode the request in the negotiated schema version; the HMAC covers
+	// exactly this body
+	version, err := c.resolveRequestVersion(ctx)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	versioned, err := request.Versioned(version)
+	if err != nil {
+		return nil, err
+	}
+	body, err := marshalBody(versioned)
+	if err != nil {
+		return nil, err
 	}
 
-	return safeAddress.Hex(), nil
-}
+	// Generate authentication headers for the path the request is sent to
+	submitEndpoint := submitTransactionRequest{}
+	path, headers, err := c.prepareEndpoint(submitEndpoint, body)
+	if err != nil {
+		return nil, err
+	}
 
-// submitTransaction submits a transaction request to the relayer
-func (c *RelayClient) submitTransaction(request *models.TransactionRequest) (*models.ClientRelayerTransactionResponse, error) {
-	// Debug: Print the request being sent
-	requestJSON, _ := json.MarshalIndent(request, "", "  ")
-	log.Printf("DEBUG: Submitting transaction request:\n%s", string(requestJSON))
+	// Close waits for the submission to be recorded
+	done, err := c.lifecycle.begin()
+	if err != nil {
+		return nil, err
+	}
+	defer done()
 
-	// Generate authentication headers
-	headers, err := c.generateBuilderHeaders("POST", SUBMIT_TRANSACTION, request)
+	// Persist the submission before it can reach the relayer
+	record, err := c.beginSubmission(operationID, request, body)
 	if err != nil {
 		return nil, err
 	}
 
 	// Submit the transaction
 	var response models.SubmitTransactionResponse
-	if err := c.httpClient.PostJSON(SUBMIT_TRANSACTION, headers, request, &response); err != nil {
-		return nil, err
+	send := func(headers map[string]string) error {
+		return c.httpClient.PostJSONContext(ctx, path, headers, body, &response)
 	}
+	err = c.quotaError(c.retryUnknownKey(send(headers), submitEndpoint.method(), path, body, send))
+	err = c.permissionError(err, models.KeyScopeSubmit)
+	c.recordSubmitOutcome(err)
+	// Even a failed submission may have reached the relayer
+	c.responseCache.invalidateSubmission(request)
+	if err != nil {
+		c.abandonSubmission(record, err)
+		nonce := ""
+		if request.Nonce != nil {
+			nonce = *request.Nonce
+		}
+		return nil, errors.ErrSubmissionFailed(request.Type, request.ProxyWallet, nonce, err)
+	}
+	c.completeSubmission(record, response.TransactionID)
 
 	// Create response wrapper
 	clientResponse := models.NewClientRelayerTransactionResponse(response.TransactionID)
+	clientResponse.OperationID = operationID
+	clientResponse.ChainID = c.chainID
+	clientResponse.Type = models.TransactionType(request.Type)
+	clientResponse.SafeAddress = request.ProxyWallet
+	if request.Nonce != nil {
+		clientResponse.Nonce = *request.Nonce
+	}
+	clientResponse.SubmittedAt = c.clock.Now()
 	clientResponse.SetClient(c)
+	clientResponse.SetClock(c.clock)
+	c.trackLatency(clientResponse)
 
 	return clientResponse, nil
 }
 
-// generateBuilderHeaders creates authentication headers for Builder API requests
-func (c *RelayClient) generateBuilderHeaders(method, requestPath string, body interface{}) (map[string]string, error) {
+// generateBuilderHeaders creates authentication headers for Builder API
+// requests, signing the path as sent, including any relayer URL path prefix.
+// body is the exact bytes sent, nil for none; GET and DELETE requests are
+// sent without a body, so they must not be given one.
+func (c *RelayClient) generateBuilderHeaders(method, requestPath string, body []byte) (map[string]string, error) {
 	if c.builderConfig == nil {
 		return nil, errors.ErrBuilderCredsNotConfigured
 	}
+	if len(body) > 0 && !methodHasBody(method) {
+		return nil, errors.ErrBodyNotAllowed(method, requestPath)
+	}
+	c.warnExpiredCredentials()
+
+	return c.builderConfig.GenerateBuilderHeaders(method, c.httpClient.RequestPath(requestPath), body)
+}
 
-	return c.builderConfig.GenerateBuilderHeaders(method, requestPath, body)
+// warnExpiredCredentials logs, once per client, that every builder
+// credential has expired, the first time it is noticed
+func (c *RelayClient) warnExpiredCredentials() {
+	if c.builderConfig == nil || !c.builderConfig.AllCredentialsExpired() {
+		return
+	}
+	c.expiryWarning.Do(func() {
+		c.logger.Printf("Warning: all %d builder credentials have expired", len(c.builderConfig.Credentials))
+	})
+}
+
+// retryUnknownKey resends a request with the previous builder credential
+// when the relayer rejected it with CodeUnknownKey, which happens while a
+// rotated key is still propagating. err is the first attempt's result and is
+// returned unchanged for any other failure or when there is no previous key.
+func (c *RelayClient) retryUnknownKey(err error, method, requestPath string, body []byte, send func(headers map[string]string) error) error {
+	if !errors.IsUnknownKey(err) {
+		return err
+	}
+	previous, ok := c.builderConfig.PreviousCredential()
+	if !ok {
+		return err
+	}
+
+	headers, headerErr := c.builderConfig.GenerateHeadersWith(previous, method, c.httpClient.RequestPath(requestPath), body)
+	if headerErr != nil {
+		return err
+	}
+	c.logger.Printf("Relayer does not know key %q yet, retrying %s %s with key %q", c.builderConfig.ActiveCredential().KeyID, method, requestPath, previous.KeyID)
+	return send(headers)
+}
+
+// methodHasBody reports whether requests with method carry a body
+func methodHasBody(method string) bool {
+	switch method {
+	case "GET", "HEAD", "DELETE":
+		return false
+	}
+	return true
+}
+
+// marshalBody encodes the body of an authenticated request once, so the
+// headers sign the very bytes that are sent
+func marshalBody(v interface{}) (json.RawMessage, error) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return nil, errors.ErrJSONMarshalFailed(err)
+	}
+	return body, nil
+}
+
+// checkChainID returns a ChainIDMismatchError if the signer or contract
+// config targets a different chain than the client
+func (c *RelayClient) checkChainID() error {
+	if c.signer != nil {
+		if signerChainID := c.signer.GetChainID().Int64(); signerChainID != c.chainID {
+			return errors.ErrChainIDMismatch(signerChainID, c.chainID)
+		}
+	}
+	if contractConfig := c.currentContractConfig(); contractConfig.ChainID != c.chainID {
+		return errors.ErrInvalidConfiguration(fmt.Sprintf("contract config chain ID %d does not match client chain ID %d", contractConfig.ChainID, c.chainID))
+	}
+	return nil
 }
 
 // assertSignerNeeded checks if signer is configured
@@ -380,22 +1038,18 @@ func (c *RelayClient) assertBuilderCredsNeeded() error {
 	return c.builderConfig.Validate()
 }
 
+// SetNonceSignerType sets the signer type Execute uses when fetching the nonce
+// (defaults to SAFE). The type must be built in or registered with models.RegisterSignerType.
+func (c *RelayClient) SetNonceSignerType(signerType models.SignerType) error {
+	st, err := models.ParseSignerType(string(signerType))
+	if err != nil {
+		return err
+	}
+	c.nonceSignerType = st
+	return nil
+}
+
 // GetSigner returns the signer (if configured)
 func (c *RelayClient) GetSigner() *signer.Signer {
 	return c.signer
 }
-
-// GetChainID returns the chain ID
-func (c *RelayClient) GetChainID() int64 {
-	return c.chainID
-}
-
-// GetRelayerURL returns the relayer URL
-func (c *RelayClient) GetRelayerURL() string {
-	return c.relayerURL
-}
-
-// GetContractConfig returns the contract configuration
-func (c *RelayClient) GetContractConfig() *config.ContractConfig {
-	return c.contractConfig
-}
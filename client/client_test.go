@@ -0,0 +1,284 @@
+package client
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	stderrors "errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/davidt58/go-builder-relayer-client/config"
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/models"
+	"github.com/davidt58/go-builder-relayer-client/signer"
+)
+
+func TestGetNonce_SignerTypeValidation(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	c := newTestClient(t, relayer)
+
+	var gotType string
+	relayer.Handle(GET_NONCE, func(w http.ResponseWriter, r *http.Request) {
+		gotType = r.URL.Query().Get("type")
+		w.Write([]byte(`{"nonce":"7"}`))
+	})
+
+	if _, err := c.GetNonce(c.signer.AddressHex(), "PROXY"); err == nil {
+		t.Fatal("expected error for unregistered signer type")
+	}
+	if gotType != "" {
+		t.Errorf("unregistered signer type reached the relayer: %s", gotType)
+	}
+
+	models.RegisterSignerType("PROXY")
+
+	resp, err := c.GetNonce(c.signer.AddressHex(), "PROXY")
+	if err != nil {
+		t.Fatalf("GetNonce failed: %v", err)
+	}
+	if gotType != "PROXY" {
+		t.Errorf("type query param = %s, want PROXY", gotType)
+	}
+	if resp.Nonce != "7" {
+		t.Errorf("Nonce = %s, want 7", resp.Nonce)
+	}
+}
+
+func TestExecute_NonceSignerType(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	c := newTestClient(t, relayer)
+
+	if err := c.SetNonceSignerType("UNKNOWN"); err == nil {
+		t.Error("expected error for unregistered signer type")
+	}
+
+	proxy := models.RegisterSignerType("PROXY")
+	if err := c.SetNonceSignerType(proxy); err != nil {
+		t.Fatalf("SetNonceSignerType failed: %v", err)
+	}
+
+	var gotType string
+	relayer.Handle(GET_NONCE, func(w http.ResponseWriter, r *http.Request) {
+		gotType = r.URL.Query().Get("type")
+		w.Write([]byte(`{"nonce":"0"}`))
+	})
+
+	if _, err := c.Execute(testTransactions(), ""); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if gotType != "PROXY" {
+		t.Errorf("Execute fetched nonce with type %s, want PROXY", gotType)
+	}
+}
+
+func TestExecute_SubmissionErrorContext(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	c := newTestClient(t, relayer)
+
+	relayer.Handle(SUBMIT_TRANSACTION, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":"invalid request","details":[{"field":"nonce","message":"nonce too low"}]}`))
+	})
+
+	_, err := c.Execute(testTransactions(), "")
+	if err == nil {
+		t.Fatal("expected submission error")
+	}
+
+	var apiErr *errors.RelayerApiError
+	if !stderrors.As(err, &apiErr) {
+		t.Fatalf("error = %v, want wrapped RelayerApiError", err)
+	}
+	if len(apiErr.FieldErrors()) != 1 || apiErr.FieldErrors()[0].Field != "nonce" {
+		t.Errorf("FieldErrors() = %v, want nonce error", apiErr.FieldErrors())
+	}
+
+	safeAddress, _ := c.GetExpectedSafe()
+	for _, want := range []string{"SAFE", safeAddress, "nonce 0", "nonce: nonce too low"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err.Error(), want)
+		}
+	}
+}
+
+func TestNewRelayClient_BuilderCredentialCheck(t *testing.T) {
+	validSecret := "dGVzdC1zZWNyZXQ="
+
+	tests := []struct {
+		name      string
+		builder   *config.BuilderConfig
+		opts      []Option
+		shouldErr bool
+	}{
+		{"lazy by default", config.NewBuilderConfig("key", "not base64!", "pass"), nil, false},
+		{"partial credentials", config.NewBuilderConfig("key", validSecret, ""), nil, true},
+		{"invalid base64 secret", config.NewBuilderConfig("key", "not base64!", "pass"), []Option{WithBuilderCredentialCheck()}, true},
+		{"missing passphrase", config.NewBuilderConfig("key", validSecret, ""), []Option{WithBuilderCredentialCheck()}, true},
+		{"no builder config", nil, []Option{WithBuilderCredentialCheck()}, true},
+		{"valid", config.NewBuilderConfig("key", validSecret, "pass"), []Option{WithBuilderCredentialCheck()}, false},
+	}
+
+	// The check asks the relayer for the key's scopes; this one has no key-info endpoint
+	relayer := newFakeRelayer(t)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewRelayClient(relayer.URL(), 137, "", tt.builder, tt.opts...)
+			if tt.shouldErr && err == nil {
+				t.Error("Expected error but got none")
+			}
+			if !tt.shouldErr && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestNewRelayClient_UnsupportedChain(t *testing.T) {
+	constructors := map[string]func() error{
+		"NewRelayClient": func() error {
+			_, err := NewRelayClient("https://relayer.example.com", 1, testPrivateKey, nil)
+			return err
+		},
+		"NewReadOnlyClient": func() error {
+			_, err := NewReadOnlyClient("https://relayer.example.com", 1)
+			return err
+		},
+	}
+
+	for name, construct := range constructors {
+		t.Run(name, func(t *testing.T) {
+			err := construct()
+			var chainErr *errors.UnsupportedChainError
+			if !stderrors.As(err, &chainErr) {
+				t.Fatalf("error = %v, want an UnsupportedChainError", err)
+			}
+			if chainErr.ChainID != 1 {
+				t.Errorf("ChainID = %d, want 1", chainErr.ChainID)
+			}
+			if want := config.GetSupportedChainIDs(); !reflect.DeepEqual(chainErr.SupportedChains, want) {
+				t.Errorf("SupportedChains = %v, want %v", chainErr.SupportedChains, want)
+			}
+			if msg := err.Error(); !strings.Contains(msg, "137, 80002") || !strings.Contains(msg, "config.AddChainConfig") {
+				t.Errorf("error %q should list the supported chains and point to AddChainConfig", msg)
+			}
+		})
+	}
+}
+
+func TestRelayClient_ChainIDConsistency(t *testing.T) {
+	amoySigner, err := signer.NewSigner(testPrivateKey, 80002)
+	if err != nil {
+		t.Fatalf("NewSigner failed: %v", err)
+	}
+	amoyConfig, err := config.GetContractConfig(80002)
+	if err != nil {
+		t.Fatalf("GetContractConfig failed: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		mutate       func(c *RelayClient)
+		wantMismatch bool
+		shouldErr    bool
+	}{
+		{name: "consistent", mutate: func(c *RelayClient) {}},
+		{name: "no signer", mutate: func(c *RelayClient) { c.signer = nil }},
+		{name: "signer for another chain", mutate: func(c *RelayClient) { c.signer = amoySigner }, wantMismatch: true, shouldErr: true},
+		{name: "contract config for another chain", mutate: func(c *RelayClient) { c.contractConfig = amoyConfig }, shouldErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			relayer := newFakeRelayer(t)
+			c, err := NewRelayClient(relayer.URL(), 137, testPrivateKey, nil, func(c *RelayClient) error {
+				tt.mutate(c)
+				return nil
+			})
+			if !tt.shouldErr {
+				if err != nil {
+					t.Fatalf("NewRelayClient failed: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("NewRelayClient succeeded with chain %d", c.chainID)
+			}
+			var mismatch *errors.ChainIDMismatchError
+			if got := stderrors.As(err, &mismatch); got != tt.wantMismatch {
+				t.Errorf("error = %v, want ChainIDMismatchError %v", err, tt.wantMismatch)
+			}
+		})
+	}
+}
+
+func TestNewRelayClient_BasePathSigning(t *testing.T) {
+	var signedPaths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		message := r.Header.Get("POLY_BUILDER_TIMESTAMP") + r.Method + r.URL.Path
+		h := hmac.New(sha256.New, []byte("test-secret"))
+		h.Write([]byte(message))
+		if r.Header.Get("POLY_BUILDER_SIGNATURE") == base64.URLEncoding.EncodeToString(h.Sum(nil)) {
+			signedPaths = append(signedPaths, r.URL.Path)
+		}
+		w.Write([]byte(`{"transactions":[]}`))
+	}))
+	defer server.Close()
+
+	secret := base64.URLEncoding.EncodeToString([]byte("test-secret"))
+	for _, base := range []string{server.URL + "/relayer", server.URL + "/relayer/"} {
+		signedPaths = nil
+		c, err := NewRelayClient(base, 137, testPrivateKey, config.NewBuilderConfig("test-key", secret, "test-pass"), WithLogOutput(io.Discard))
+		if err != nil {
+			t.Fatalf("NewRelayClient failed: %v", err)
+		}
+		if _, err := c.GetTransactions(); err != nil {
+			t.Fatalf("GetTransactions failed: %v", err)
+		}
+		if want := []string{"/relayer" + GET_TRANSACTIONS}; !reflect.DeepEqual(signedPaths, want) {
+			t.Errorf("base %s: correctly signed requests = %v, want %v", base, signedPaths, want)
+		}
+	}
+}
+
+func TestNewRelayClient_DefaultRelayerURL(t *testing.T) {
+	var logs bytes.Buffer
+	c, err := NewRelayClient("", 137, testPrivateKey, nil, WithLogOutput(&logs))
+	if err != nil {
+		t.Fatalf("NewRelayClient failed: %v", err)
+	}
+	if c.relayerURL != "https://relayer-v2.polymarket.com" {
+		t.Errorf("relayerURL = %q, want the production relayer", c.relayerURL)
+	}
+	if !strings.Contains(logs.String(), "Using the production relayer for chain 137") {
+		t.Errorf("the resolved relayer was not logged: %q", logs.String())
+	}
+
+	// An explicit URL wins and is not logged as a default
+	logs.Reset()
+	c, err = NewRelayClient("https://relayer.example.com", 137, testPrivateKey, nil, WithLogOutput(&logs))
+	if err != nil {
+		t.Fatalf("NewRelayClient failed: %v", err)
+	}
+	if c.relayerURL != "https://relayer.example.com" || strings.Contains(logs.String(), "Using the") {
+		t.Errorf("relayerURL = %q, logs = %q, want the explicit URL", c.relayerURL, logs.String())
+	}
+
+	// A chain with contracts but no registered relayer needs a URL
+	polygon, err := config.GetContractConfig(137)
+	if err != nil {
+		t.Fatalf("GetContractConfig failed: %v", err)
+	}
+	unregistered := *polygon
+	unregistered.ChainID = 31338
+	config.AddChainConfig(&unregistered)
+	if _, err := NewRelayClient("", 31338, testPrivateKey, nil, WithLogOutput(io.Discard)); err == nil {
+		t.Error("Expected error for a chain without a registered relayer")
+	}
+}
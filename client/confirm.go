@@ -0,0 +1,136 @@
+package client
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+// BlockNumberFetcher reports the chain head. *ethclient.Client satisfies
+// this interface; an RPC client passed to WithRPCClient needs it for
+// WaitForConfirmations.
+type BlockNumberFetcher interface {
+	BlockNumber(ctx context.Context) (uint64, error)
+}
+
+// WaitForConfirmations waits until the transaction's block is n blocks deep,
+// independent of the relayer's own confirmation policy. It waits for the
+// relayer to report the transaction mined, then polls the RPC client for the
+// receipt and chain head. If the receipt disappears or moves to another
+// block, the reorg is counted and the wait falls back to the relayer state.
+// Requires an RPC client that implements BlockNumberFetcher.
+// Default polling: max 100 polls per phase, every 2 seconds
+func (c *RelayClient) WaitForConfirmations(transactionID string, n uint64) (*models.ConfirmationResult, error) {
+	return c.WaitForConfirmationsWithOptions(transactionID, n, 100, 2)
+}
+
+// WaitForConfirmationsWithOptions waits for n confirmations with custom polling options
+func (c *RelayClient) WaitForConfirmationsWithOptions(transactionID string, n uint64, maxPolls, pollFrequency int) (*models.ConfirmationResult, error) {
+	op := newOperation("WaitForConfirmations", 0)
+	defer op.cancel()
+
+	result, err := c.waitForConfirmations(op.ctx, transactionID, n, maxPolls, pollFrequency)
+	return result, op.tag(err)
+}
+
+// waitForConfirmations alternates between waiting on the relayer for a hash
+// and polling the chain until the receipt is n blocks deep
+func (c *RelayClient) waitForConfirmations(ctx context.Context, transactionID string, n uint64, maxPolls, pollFrequency int) (*models.ConfirmationResult, error) {
+	if c.rpcClient == nil {
+		return nil, errors.ErrInvalidConfiguration("WaitForConfirmations requires an RPC client")
+	}
+	heads, ok := c.rpcClient.(BlockNumberFetcher)
+	if !ok {
+		return nil, errors.ErrInvalidConfiguration("WaitForConfirmations requires an RPC client that reports the block number")
+	}
+	if maxPolls <= 0 {
+		maxPolls = 100
+	}
+	if pollFrequency <= 0 {
+		pollFrequency = 2
+	}
+	if n == 0 {
+		n = 1
+	}
+
+	result := &models.ConfirmationResult{}
+	states := []models.RelayerTransactionState{models.STATE_MINED, models.STATE_CONFIRMED}
+	hashless := 0
+	for {
+		txn, err := c.pollUntilState(ctx, transactionID, states, models.STATE_FAILED, maxPolls, pollFrequency)
+		if err != nil {
+			return nil, err
+		}
+		result.Transaction = txn
+
+		// The relayer may report the state before the hash; without one
+		// there is no receipt to look up
+		if !txn.IsMined() {
+			if hashless++; hashless >= maxPolls {
+				return nil, errors.ErrInvalidResponse(fmt.Sprintf("transaction %s is %s but has no hash", transactionID, txn.State))
+			}
+			c.clock.Sleep(time.Duration(pollFrequency) * time.Second)
+			continue
+		}
+
+		reorged, err := c.pollConfirmationDepth(ctx, heads, txn, n, result, maxPolls, pollFrequency)
+		if err != nil {
+			return nil, err
+		}
+		if !reorged {
+			return result, nil
+		}
+		result.Reorgs++
+		c.logger.Printf("Transaction %s was reorged out of block %d (reorg %d), waiting for the relayer", transactionID, result.BlockNumber, result.Reorgs)
+		result.BlockNumber = 0
+		result.Confirmations = 0
+	}
+}
+
+// pollConfirmationDepth polls the receipt of a mined transaction, which must
+// have a hash, until it is n blocks deep. It reports true if a receipt that
+// was seen disappears or moves to a different block.
+func (c *RelayClient) pollConfirmationDepth(ctx context.Context, heads BlockNumberFetcher, txn *models.RelayerTransaction, n uint64, result *models.ConfirmationResult, maxPolls, pollFrequency int) (bool, error) {
+	hash := common.HexToHash(*txn.Hash)
+	var included *types.Receipt
+
+	for i := 0; i < maxPolls; i++ {
+		receipt, err := c.rpcClient.TransactionReceipt(ctx, hash)
+		switch {
+		case stderrors.Is(err, ethereum.NotFound):
+			if included != nil {
+				return true, nil
+			}
+		case err != nil:
+			return false, errors.NewRelayerClientError(fmt.Sprintf("failed to fetch receipt for %s", hash.Hex()), err)
+		case included != nil && receipt.BlockHash != included.BlockHash:
+			return true, nil
+		default:
+			included = receipt
+			head, err := heads.BlockNumber(ctx)
+			if err != nil {
+				return false, errors.NewRelayerClientError("failed to fetch block number", err)
+			}
+
+			result.BlockNumber = receipt.BlockNumber.Uint64()
+			if head >= result.BlockNumber {
+				result.Confirmations = head - result.BlockNumber + 1
+			}
+			if result.Confirmations >= n {
+				return false, nil
+			}
+		}
+
+		c.clock.Sleep(time.Duration(pollFrequency) * time.Second)
+	}
+
+	return false, errors.ErrPollingTimeout(txn.TransactionID)
+}
@@ -0,0 +1,312 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+// scriptedChain is an RPC stub whose head advances one block per receipt
+// poll; script entries run before the poll with the matching number
+type scriptedChain struct {
+	mu      sync.Mutex
+	head    uint64
+	receipt *types.Receipt
+	polls   int
+	script  map[int]func(*scriptedChain)
+}
+
+func (c *scriptedChain) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.polls++
+	if step, ok := c.script[c.polls]; ok {
+		step(c)
+	}
+	c.head++
+	if c.receipt == nil || c.receipt.TxHash != txHash {
+		return nil, ethereum.NotFound
+	}
+	return c.receipt, nil
+}
+
+func (c *scriptedChain) BlockNumber(ctx context.Context) (uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.head, nil
+}
+
+// includeAt returns a script step that puts the transaction in block number
+func includeAt(number uint64) func(*scriptedChain) {
+	return func(c *scriptedChain) {
+		c.receipt = testReceipt(number)
+	}
+}
+
+// reorgOut is a script step that drops the transaction's receipt
+func reorgOut(c *scriptedChain) {
+	c.receipt = nil
+}
+
+func testReceipt(number uint64) *types.Receipt {
+	return &types.Receipt{
+		TxHash:      common.HexToHash(testCreationTxHash),
+		BlockNumber: new(big.Int).SetUint64(number),
+		BlockHash:   common.BigToHash(new(big.Int).SetUint64(number)),
+		Status:      types.ReceiptStatusSuccessful,
+	}
+}
+
+// receiptOnly is an RPC client without block number support
+type receiptOnly struct{}
+
+func (receiptOnly) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	return testReceipt(100), nil
+}
+
+// serveMinedCounting serves a mined transaction and counts the polls
+func serveMinedCounting(relayer *fakeRelayer, state models.RelayerTransactionState) func() int {
+	var mu sync.Mutex
+	polls := 0
+	relayer.Handle(GET_TRANSACTION, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		polls++
+		mu.Unlock()
+		hash := testCreationTxHash
+		json.NewEncoder(w).Encode([]models.RelayerTransaction{{
+			TransactionID: r.URL.Query().Get("id"),
+			State:         state,
+			Hash:          &hash,
+		}})
+	})
+	return func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return polls
+	}
+}
+
+func TestWaitForConfirmations(t *testing.T) {
+
+	tests := []struct {
+		name              string
+		rpc               func() ReceiptFetcher
+		state             models.RelayerTransactionState
+		n                 uint64
+		wantBlock         uint64
+		wantConfirmations uint64
+		wantReorgs        int
+		wantRelayerPolls  int
+		shouldErr         bool
+	}{
+		{
+			name: "already deep",
+			rpc: func() ReceiptFetcher {
+				return &scriptedChain{head: 138, receipt: testReceipt(100)}
+			},
+			state:             models.STATE_CONFIRMED,
+			n:                 30,
+			wantBlock:         100,
+			wantConfirmations: 40,
+			wantRelayerPolls:  1,
+		},
+		{
+			name: "waits for blocks",
+			rpc: func() ReceiptFetcher {
+				return &scriptedChain{head: 99, receipt: testReceipt(100)}
+			},
+			state:             models.STATE_MINED,
+			n:                 30,
+			wantBlock:         100,
+			wantConfirmations: 30,
+			wantRelayerPolls:  1,
+		},
+		{
+			name: "receipt not indexed yet",
+			rpc: func() ReceiptFetcher {
+				return &scriptedChain{head: 99, script: map[int]func(*scriptedChain){4: includeAt(102)}}
+			},
+			state:             models.STATE_MINED,
+			n:                 3,
+			wantBlock:         102,
+			wantConfirmations: 3,
+			wantRelayerPolls:  1,
+		},
+		{
+			name: "reorged out and re-included",
+			rpc: func() ReceiptFetcher {
+				return &scriptedChain{head: 99, receipt: testReceipt(100), script: map[int]func(*scriptedChain){
+					5: reorgOut,
+					9: includeAt(108),
+				}}
+			},
+			state:             models.STATE_MINED,
+			n:                 30,
+			wantBlock:         108,
+			wantConfirmations: 30,
+			wantReorgs:        1,
+			wantRelayerPolls:  2,
+		},
+		{
+			name: "moved to another block",
+			rpc: func() ReceiptFetcher {
+				return &scriptedChain{head: 99, receipt: testReceipt(100), script: map[int]func(*scriptedChain){
+					5: includeAt(104),
+				}}
+			},
+			state:             models.STATE_MINED,
+			n:                 10,
+			wantBlock:         104,
+			wantConfirmations: 10,
+			wantReorgs:        1,
+			wantRelayerPolls:  2,
+		},
+		{
+			name: "relayer reports failure",
+			rpc: func() ReceiptFetcher {
+				return &scriptedChain{head: 99}
+			},
+			state:     models.STATE_FAILED,
+			n:         30,
+			shouldErr: true,
+		},
+		{
+			name:      "no block number support",
+			rpc:       func() ReceiptFetcher { return receiptOnly{} },
+			state:     models.STATE_MINED,
+			n:         30,
+			shouldErr: true,
+		},
+		{
+			name:      "no rpc client",
+			rpc:       func() ReceiptFetcher { return nil },
+			state:     models.STATE_MINED,
+			n:         30,
+			shouldErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			relayer := newFakeRelayer(t)
+			relayerPolls := serveMinedCounting(relayer, tt.state)
+			c := newTestClient(t, relayer)
+			if rpc := tt.rpc(); rpc != nil {
+				c.rpcClient = rpc
+			}
+
+			result, err := c.WaitForConfirmations("tx-1", tt.n)
+			if tt.shouldErr {
+				if err == nil {
+					t.Fatal("Expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("WaitForConfirmations failed: %v", err)
+			}
+
+			if result.BlockNumber != tt.wantBlock {
+				t.Errorf("BlockNumber = %d, want %d", result.BlockNumber, tt.wantBlock)
+			}
+			if result.Confirmations != tt.wantConfirmations {
+				t.Errorf("Confirmations = %d, want %d", result.Confirmations, tt.wantConfirmations)
+			}
+			if result.Reorgs != tt.wantReorgs {
+				t.Errorf("Reorgs = %d, want %d", result.Reorgs, tt.wantReorgs)
+			}
+			if got := relayerPolls(); got != tt.wantRelayerPolls {
+				t.Errorf("relayer polls = %d, want %d", got, tt.wantRelayerPolls)
+			}
+			if result.Transaction == nil || result.Transaction.TransactionID != "tx-1" {
+				t.Errorf("Transaction = %+v, want tx-1", result.Transaction)
+			}
+		})
+	}
+}
+
+func TestWaitForConfirmations_MissingHash(t *testing.T) {
+	for _, hashAfter := range []int{2, 0} {
+		relayer := newFakeRelayer(t)
+		var mu sync.Mutex
+		polls := 0
+		relayer.Handle(GET_TRANSACTION, func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			polls++
+			txn := models.RelayerTransaction{TransactionID: "tx-1", State: models.STATE_MINED}
+			if hashAfter > 0 && polls > hashAfter {
+				hash := testCreationTxHash
+				txn.Hash = &hash
+			}
+			mu.Unlock()
+			json.NewEncoder(w).Encode([]models.RelayerTransaction{txn})
+		})
+		c := newTestClient(t, relayer)
+		c.rpcClient = &scriptedChain{head: 99, receipt: testReceipt(100)}
+
+		result, err := c.WaitForConfirmationsWithOptions("tx-1", 1, 5, 1)
+		if hashAfter == 0 {
+			// Never hashed: a typed error rather than a panic
+			if err == nil || polls != 5 {
+				t.Errorf("error = %v after %d polls, want an error after 5", err, polls)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("WaitForConfirmations failed: %v", err)
+		}
+		if result.BlockNumber != 100 || polls != hashAfter+1 {
+			t.Errorf("block %d after %d polls, want block 100 after %d", result.BlockNumber, polls, hashAfter+1)
+		}
+	}
+}
+
+func TestWait_WithConfirmations(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	serveMinedCounting(relayer, models.STATE_MINED)
+	chain := &scriptedChain{head: 99, receipt: testReceipt(100), script: map[int]func(*scriptedChain){3: reorgOut, 5: includeAt(103)}}
+	c := newTestClient(t, relayer)
+	c.rpcClient = chain
+
+	resp, err := c.Execute(testTransactions(), "")
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	hooked := false
+	resp.SetWaitHook(func(*models.RelayerTransaction) error {
+		hooked = true
+		return nil
+	})
+	txn, err := resp.Wait(models.WithConfirmations(30))
+	if err != nil {
+		t.Fatalf("Wait failed: %v", err)
+	}
+	if txn.State != models.STATE_MINED {
+		t.Errorf("State = %s, want %s", txn.State, models.STATE_MINED)
+	}
+	if head, _ := chain.BlockNumber(context.Background()); head < 103+29 {
+		t.Errorf("Wait returned at head %d, before 30 confirmations of block 103", head)
+	}
+	if !hooked {
+		t.Error("wait hook did not run")
+	}
+
+	result, err := resp.WaitForConfirmations(30)
+	if err != nil {
+		t.Fatalf("WaitForConfirmations failed: %v", err)
+	}
+	if result.Reorgs != 0 || result.BlockNumber != 103 {
+		t.Errorf("result = %+v, want block 103 without reorgs", result)
+	}
+}
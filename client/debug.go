@@ -0,0 +1,197 @@
+package client
+
+import (
+	"context"
+	stderrors "errors"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/davidt58/go-builder-relayer-client/builder"
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+// Divergence names where local and remote signature validation disagree
+type Divergence string
+
+const (
+	// DivergenceNone means both sides computed the same hash and signer
+	DivergenceNone Divergence = "none"
+	// DivergenceHash means the relayer hashed the payload differently
+	DivergenceHash Divergence = "hash"
+	// DivergenceRecovery means the hashes match but the recovered signers differ
+	DivergenceRecovery Divergence = "recovery"
+	// DivergenceVerdict means hash and signer match but only one side
+	// accepts the signature
+	DivergenceVerdict Divergence = "verdict"
+	// DivergenceUnknown means the relayer has no debug endpoint to compare with
+	DivergenceUnknown Divergence = "unknown"
+)
+
+// WithDebugValidatePath sets the path of the relayer's signature debug
+// endpoint (default DEBUG_VALIDATE)
+func WithDebugValidatePath(path string) Option {
+	return func(c *RelayClient) error {
+		if !strings.HasPrefix(path, "/") {
+			return errors.ErrInvalidConfiguration("debug validate path must start with /")
+		}
+		c.debugValidatePath = path
+		return nil
+	}
+}
+
+// RequestVerification is the client's own check of a SAFE request's signature
+type RequestVerification struct {
+	// ComputedHash is the SafeTx hash rebuilt from the request
+	ComputedHash common.Hash
+	// Recovered is the signer recovered from the signature over ComputedHash
+	// (zero if recovery failed)
+	Recovered common.Address
+	// Valid reports whether Recovered is the request's From
+	Valid bool
+	// Reason explains why the signature is invalid
+	Reason string
+}
+
+// SignatureDiagnosis compares the client's and the relayer's validation of
+// a request's signature
+type SignatureDiagnosis struct {
+	// Local is the client's validation
+	Local *RequestVerification
+	// Remote is the relayer's validation, nil if it has no debug endpoint
+	Remote *models.RemoteValidation
+	// Divergence is where the two disagree
+	Divergence Divergence
+}
+
+// VerifyRequestSignatures rebuilds the SafeTx hash of a SAFE request for
+// the client's chain and the Safe's version, recovers the signer and checks
+// it is the request's From. An invalid signature is reported in the result;
+// the error is for requests that cannot be checked at all.
+func (c *RelayClient) VerifyRequestSignatures(request *models.TransactionRequest) (*RequestVerification, error) {
+	op := newOperation("VerifyRequestSignatures", 0)
+	defer op.cancel()
+
+	if request == nil {
+		return nil, errors.ErrMissingRequiredField("request")
+	}
+	version, err := c.detectSafeVersion(op, request.ProxyWallet)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := builder.SafeRequestHash(request, c.chainID, version)
+	if err != nil {
+		return nil, err
+	}
+
+	verification := &RequestVerification{ComputedHash: hash}
+	recovered, err := builder.RecoverRequestSigner(request, hash)
+	switch {
+	case err != nil:
+		verification.Reason = err.Error()
+	case !strings.EqualFold(recovered.Hex(), request.From):
+		verification.Recovered = recovered
+		verification.Reason = "signature recovers to " + recovered.Hex() + ", not from " + request.From
+	default:
+		verification.Recovered = recovered
+		verification.Valid = true
+	}
+	return verification, nil
+}
+
+// DebugValidateRequest sends request to the relayer's signature debug
+// endpoint (see WithDebugValidatePath), which reports the hash and signers
+// it computes without submitting anything. An EndpointUnsupportedError is
+// returned if the relayer does not serve the endpoint.
+func (c *RelayClient) DebugValidateRequest(request *models.TransactionRequest) (*models.RemoteValidation, error) {
+	op := newOperation("DebugValidateRequest", 0)
+	defer op.cancel()
+
+	validation, err := c.debugValidateRequest(op.ctx, request)
+	return validation, op.tag(err)
+}
+
+// debugValidateRequest calls the debug endpoint with ctx
+func (c *RelayClient) debugValidateRequest(ctx context.Context, request *models.TransactionRequest) (*models.RemoteValidation, error) {
+	if request == nil {
+		return nil, errors.ErrMissingRequiredField("request")
+	}
+	path := c.debugValidatePath
+	if path == "" {
+		path = DEBUG_VALIDATE
+	}
+
+	// Send the body exactly as it would be submitted
+	version, err := c.resolveRequestVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+	versioned, err := request.Versioned(version)
+	if err != nil {
+		return nil, err
+	}
+	body, err := marshalBody(versioned)
+	if err != nil {
+		return nil, err
+	}
+	headers, err := c.generateBuilderHeaders("POST", path, body)
+	if err != nil {
+		return nil, err
+	}
+
+	var validation models.RemoteValidation
+	send := func(headers map[string]string) error {
+		return c.httpClient.PostJSONContext(ctx, path, headers, body, &validation)
+	}
+	if err := c.retryUnknownKey(send(headers), "POST", path, body, send); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, errors.ErrEndpointUnsupported(path)
+		}
+		return nil, err
+	}
+	return &validation, nil
+}
+
+// DiagnoseRequestSignature validates request's signature locally and on the
+// relayer and reports where they diverge: in hashing, in recovery or only
+// in the verdict. Against a relayer without the debug endpoint the
+// diagnosis has only the local result and DivergenceUnknown.
+func (c *RelayClient) DiagnoseRequestSignature(request *models.TransactionRequest) (*SignatureDiagnosis, error) {
+	local, err := c.VerifyRequestSignatures(request)
+	if err != nil {
+		return nil, err
+	}
+
+	remote, err := c.DebugValidateRequest(request)
+	var unsupported *errors.EndpointUnsupportedError
+	if stderrors.As(err, &unsupported) {
+		return &SignatureDiagnosis{Local: local, Divergence: DivergenceUnknown}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &SignatureDiagnosis{Local: local, Remote: remote, Divergence: diverge(local, remote)}, nil
+}
+
+// diverge returns the first step at which local and remote disagree
+func diverge(local *RequestVerification, remote *models.RemoteValidation) Divergence {
+	if !strings.EqualFold(remote.ComputedHash, local.ComputedHash.Hex()) {
+		return DivergenceHash
+	}
+	// Agreeing means both recovered the local signer, or neither recovered any
+	agree := local.Recovered == (common.Address{}) && len(remote.RecoveredSigners) == 0
+	for _, signer := range remote.RecoveredSigners {
+		if local.Recovered != (common.Address{}) && strings.EqualFold(signer, local.Recovered.Hex()) {
+			agree = true
+		}
+	}
+	if !agree {
+		return DivergenceRecovery
+	}
+	if remote.Valid != local.Valid {
+		return DivergenceVerdict
+	}
+	return DivergenceNone
+}
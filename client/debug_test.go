@@ -0,0 +1,178 @@
+package client
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/davidt58/go-builder-relayer-client/builder"
+	"github.com/davidt58/go-builder-relayer-client/config"
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+// signedTestRequest builds and signs a SAFE request for the client's Safe
+func signedTestRequest(t *testing.T, c *RelayClient) *models.TransactionRequest {
+	t.Helper()
+
+	safe, err := c.GetExpectedSafe()
+	if err != nil {
+		t.Fatalf("GetExpectedSafe failed: %v", err)
+	}
+	request, err := builder.BuildSafeTransactionRequestForSafeVersion(&models.SafeTransactionArgs{
+		SafeAddress:  safe,
+		Transactions: retryBatch(),
+		Nonce:        "3",
+	}, c.signer, c.currentContractConfig(), config.MultisendStandard, "")
+	if err != nil {
+		t.Fatalf("BuildSafeTransactionRequestForSafeVersion failed: %v", err)
+	}
+	return request
+}
+
+func TestVerifyRequestSignatures(t *testing.T) {
+	c := newTestClient(t, newFakeRelayer(t))
+
+	request := signedTestRequest(t, c)
+	verification, err := c.VerifyRequestSignatures(request)
+	if err != nil {
+		t.Fatalf("VerifyRequestSignatures failed: %v", err)
+	}
+	if !verification.Valid || verification.Recovered != c.signer.Address() {
+		t.Errorf("verification = %+v, want valid and recovered to the signer", verification)
+	}
+
+	// Signed by the client, but claimed for another EOA
+	request.From = "0x70997970C51812dc3A010C7d01b50e0d17dc79C8"
+	verification, err = c.VerifyRequestSignatures(request)
+	if err != nil {
+		t.Fatalf("VerifyRequestSignatures failed: %v", err)
+	}
+	if verification.Valid || !strings.Contains(verification.Reason, c.signer.AddressHex()) {
+		t.Errorf("verification = %+v, want invalid naming the recovered signer", verification)
+	}
+
+	request.Type = string(models.SAFE_CREATE)
+	if _, err := c.VerifyRequestSignatures(request); err == nil {
+		t.Error("VerifyRequestSignatures should reject requests without a SafeTx")
+	}
+}
+
+func TestDiagnoseRequestSignature(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		remote  func(local *RequestVerification) *models.RemoteValidation
+		want    Divergence
+		wantNil bool
+	}{
+		{
+			name: "agreeing",
+			remote: func(local *RequestVerification) *models.RemoteValidation {
+				return &models.RemoteValidation{ComputedHash: local.ComputedHash.Hex(), RecoveredSigners: []string{strings.ToLower(local.Recovered.Hex())}, Valid: true}
+			},
+			want: DivergenceNone,
+		},
+		{
+			name: "hash differs",
+			remote: func(local *RequestVerification) *models.RemoteValidation {
+				return &models.RemoteValidation{ComputedHash: "0x" + strings.Repeat("ab", 32), RecoveredSigners: []string{"0x70997970C51812dc3A010C7d01b50e0d17dc79C8"}, Reason: "signer is not an owner"}
+			},
+			want: DivergenceHash,
+		},
+		{
+			name: "recovery differs",
+			remote: func(local *RequestVerification) *models.RemoteValidation {
+				return &models.RemoteValidation{ComputedHash: local.ComputedHash.Hex(), RecoveredSigners: []string{"0x70997970C51812dc3A010C7d01b50e0d17dc79C8"}, Reason: "signer is not an owner"}
+			},
+			want: DivergenceRecovery,
+		},
+		{
+			name: "verdict differs",
+			remote: func(local *RequestVerification) *models.RemoteValidation {
+				return &models.RemoteValidation{ComputedHash: local.ComputedHash.Hex(), RecoveredSigners: []string{local.Recovered.Hex()}, Reason: "nonce already used"}
+			},
+			want: DivergenceVerdict,
+		},
+		{
+			name: "custom path",
+			path: "/v2/debug/signature",
+			remote: func(local *RequestVerification) *models.RemoteValidation {
+				return &models.RemoteValidation{ComputedHash: local.ComputedHash.Hex(), RecoveredSigners: []string{local.Recovered.Hex()}, Valid: true}
+			},
+			want: DivergenceNone,
+		},
+		{
+			name:    "unsupported",
+			want:    DivergenceUnknown,
+			wantNil: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			relayer := newFakeRelayer(t)
+			c := newTestClient(t, relayer)
+			path := DEBUG_VALIDATE
+			if tt.path != "" {
+				path = tt.path
+				if err := WithDebugValidatePath(path)(c); err != nil {
+					t.Fatalf("WithDebugValidatePath failed: %v", err)
+				}
+			}
+			request := signedTestRequest(t, c)
+			local, err := c.VerifyRequestSignatures(request)
+			if err != nil {
+				t.Fatalf("VerifyRequestSignatures failed: %v", err)
+			}
+
+			var received models.TransactionRequest
+			if tt.remote != nil {
+				relayer.Handle(path, func(w http.ResponseWriter, r *http.Request) {
+					if r.Header.Get("POLY_BUILDER_API_KEY") == "" {
+						w.WriteHeader(http.StatusUnauthorized)
+						return
+					}
+					json.NewDecoder(r.Body).Decode(&received)
+					json.NewEncoder(w).Encode(tt.remote(local))
+				})
+			}
+
+			diagnosis, err := c.DiagnoseRequestSignature(request)
+			if err != nil {
+				t.Fatalf("DiagnoseRequestSignature failed: %v", err)
+			}
+			if diagnosis.Divergence != tt.want {
+				t.Errorf("Divergence = %s, want %s", diagnosis.Divergence, tt.want)
+			}
+			if (diagnosis.Remote == nil) != tt.wantNil {
+				t.Errorf("Remote = %+v, want nil %v", diagnosis.Remote, tt.wantNil)
+			}
+			if !diagnosis.Local.Valid {
+				t.Errorf("Local = %+v, want valid", diagnosis.Local)
+			}
+			if tt.remote != nil && received.Signature != request.Signature {
+				t.Errorf("debug endpoint received signature %q, want the request's", received.Signature)
+			}
+			if n := len(relayer.Submissions()); n != 0 {
+				t.Errorf("got %d submissions, want none", n)
+			}
+		})
+	}
+}
+
+func TestDebugValidateRequest_Unsupported(t *testing.T) {
+	c := newTestClient(t, newFakeRelayer(t))
+
+	_, err := c.DebugValidateRequest(signedTestRequest(t, c))
+	var unsupported *errors.EndpointUnsupportedError
+	if !stderrors.As(err, &unsupported) || unsupported.Endpoint != DEBUG_VALIDATE {
+		t.Fatalf("error = %v, want an EndpointUnsupportedError for %s", err, DEBUG_VALIDATE)
+	}
+
+	if err := WithDebugValidatePath("debug")(c); err == nil {
+		t.Error("WithDebugValidatePath should reject a relative path")
+	}
+}
@@ -0,0 +1,124 @@
+package client
+
+import (
+	"context"
+	stderrors "errors"
+	"time"
+
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+// deployedTTL is how long Deploy and Execute trust a "not deployed" result. A deployed
+// Safe never becomes undeployed, so positive results are kept for the life
+// of the client.
+const deployedTTL = 10 * time.Second
+
+// deployedEntry is a cached deployed-status result
+type deployedEntry struct {
+	deployed  bool
+	checkedAt time.Time
+}
+
+// cachedDeployed returns the cached deployed status of safeAddress, if fresh
+func (c *RelayClient) cachedDeployed(safeAddress string) (deployed, ok bool) {
+	c.deployedMu.Lock()
+	defer c.deployedMu.Unlock()
+
+	entry, ok := c.deployedCache[safeAddress]
+	if !ok {
+		return false, false
+	}
+	if !entry.deployed && c.clock.Now().Sub(entry.checkedAt) >= deployedTTL {
+		return false, false
+	}
+	return entry.deployed, true
+}
+
+// recordDeployed caches the deployed status of safeAddress
+func (c *RelayClient) recordDeployed(safeAddress string, deployed bool) {
+	c.deployedMu.Lock()
+	defer c.deployedMu.Unlock()
+
+	if c.deployedCache == nil {
+		c.deployedCache = make(map[string]deployedEntry)
+	}
+	c.deployedCache[safeAddress] = deployedEntry{deployed: deployed, checkedAt: c.clock.Now()}
+}
+
+// forgetDeployed drops a cached "not deployed" result for safeAddress, e.g.
+// once a deployment has been submitted
+func (c *RelayClient) forgetDeployed(safeAddress string) {
+	c.deployedMu.Lock()
+	defer c.deployedMu.Unlock()
+
+	if entry, ok := c.deployedCache[safeAddress]; ok && !entry.deployed {
+		delete(c.deployedCache, safeAddress)
+	}
+}
+
+// isDeployed reports whether safeAddress is deployed, using the cache when
+// fresh. A failed check is reported as onFailure and not cached: Execute
+// assumes deployed so that a flaky deployed endpoint does not block
+// executions, and the relayer still rejects them if the Safe is missing.
+func (c *RelayClient) isDeployed(ctx context.Context, safeAddress string, onFailure bool) (bool, error) {
+	if deployed, ok := c.cachedDeployed(safeAddress); ok {
+		return deployed, nil
+	}
+
+	deployed, err := c.getDeployed(ctx, safeAddress)
+	if err != nil {
+		if ctx.Err() != nil {
+			return false, err
+		}
+		c.logger.Printf("Deployment check failed: %v", err)
+		return onFailure, nil
+	}
+	c.recordDeployed(safeAddress, deployed)
+	return deployed, nil
+}
+
+// ensureDeployed runs the deployed check of an Execute's preflight. If the
+// Safe is not deployed it returns a SafeNotDeployedError, or with autoDeploy
+// deploys it and waits until the deployment is mined.
+func (c *RelayClient) ensureDeployed(op *operation, safeAddress string, autoDeploy, forceSubmit bool) error {
+	var deployed bool
+	err := op.run(stepDeployedCheck, func(ctx context.Context) error {
+		var checkErr error
+		deployed, checkErr = c.isDeployed(ctx, safeAddress, true)
+		return checkErr
+	})
+	if err != nil || deployed {
+		return err
+	}
+	if !autoDeploy {
+		return errors.ErrStaged(errors.StageDeployedCheck, errors.ErrSafeNotDeployed(safeAddress))
+	}
+
+	c.logger.Printf("Safe %s not deployed, deploying before execution", safeAddress)
+	// A deployment already in flight is waited for instead
+	var transactionID string
+	response, err := c.deploy(op, DeployOptions{ForceSubmit: forceSubmit})
+	var pending *errors.DeploymentPendingError
+	switch {
+	case stderrors.As(err, &pending):
+		c.logger.Printf("Deployment of Safe %s already pending as %s, waiting for it", safeAddress, pending.TransactionID)
+		transactionID = pending.TransactionID
+	case err != nil:
+		return err
+	default:
+		transactionID = response.TransactionID
+	}
+
+	err = op.run(stepAutoDeploy, func(ctx context.Context) error {
+		states := []models.RelayerTransactionState{models.STATE_MINED, models.STATE_CONFIRMED}
+		_, waitErr := c.pollUntilState(ctx, transactionID, states, models.STATE_FAILED, 100, 2)
+		return waitErr
+	})
+	if err != nil {
+		return err
+	}
+
+	c.recordDeployed(safeAddress, true)
+	return nil
+}
@@ -0,0 +1,200 @@
+package client
+
+import (
+	stderrors "errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+// countDeployedChecks counts GET_DEPLOYED requests while serving the default response
+func countDeployedChecks(relayer *fakeRelayer) func() int {
+	var calls int32
+	relayer.Handle(GET_DEPLOYED, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		relayer.ServeDefault(w, r)
+	})
+	return func() int { return int(atomic.LoadInt32(&calls)) }
+}
+
+func TestExecute_DeployedCheck(t *testing.T) {
+	tests := []struct {
+		name      string
+		deployed  bool
+		opts      ExecuteOptions
+		wantTypes []models.TransactionType
+		shouldErr bool
+	}{
+		{
+			name:      "deployed",
+			deployed:  true,
+			wantTypes: []models.TransactionType{models.SAFE},
+		},
+		{
+			name:      "not deployed",
+			shouldErr: true,
+		},
+		{
+			name:      "skip check",
+			opts:      ExecuteOptions{SkipDeployedCheck: true},
+			wantTypes: []models.TransactionType{models.SAFE},
+		},
+		{
+			name:      "auto deploy",
+			opts:      ExecuteOptions{AutoDeploy: true},
+			wantTypes: []models.TransactionType{models.SAFE_CREATE, models.SAFE},
+		},
+		{
+			name:      "auto deploy when already deployed",
+			deployed:  true,
+			opts:      ExecuteOptions{AutoDeploy: true},
+			wantTypes: []models.TransactionType{models.SAFE},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			relayer := newFakeRelayer(t)
+			relayer.SetDeployed(tt.deployed)
+			relayer.ScriptStates(models.STATE_NEW, models.STATE_MINED)
+			c := newTestClient(t, relayer)
+
+			_, err := c.ExecuteWithOptions(testTransactions(), "", tt.opts)
+			if (err != nil) != tt.shouldErr {
+				t.Fatalf("ExecuteWithOptions() error = %v, shouldErr %v", err, tt.shouldErr)
+			}
+			if tt.shouldErr {
+				var notDeployed *errors.SafeNotDeployedError
+				if !stderrors.As(err, &notDeployed) {
+					t.Fatalf("error = %v, want SafeNotDeployedError", err)
+				}
+				expected, _ := c.GetExpectedSafe()
+				if notDeployed.SafeAddress != expected {
+					t.Errorf("SafeAddress = %s, want %s", notDeployed.SafeAddress, expected)
+				}
+			}
+
+			submitted := relayer.Submissions()
+			if len(submitted) != len(tt.wantTypes) {
+				t.Fatalf("got %d submissions, want %d", len(submitted), len(tt.wantTypes))
+			}
+			for i, want := range tt.wantTypes {
+				if submitted[i].Type != string(want) {
+					t.Errorf("submission %d type = %s, want %s", i, submitted[i].Type, want)
+				}
+			}
+		})
+	}
+}
+
+func TestExecute_AutoDeployFailure(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	relayer.SetDeployed(false)
+	relayer.ScriptStates(models.STATE_FAILED)
+	c := newTestClient(t, relayer)
+
+	_, err := c.ExecuteWithOptions(testTransactions(), "", ExecuteOptions{AutoDeploy: true})
+	if stage := errors.StageOf(err); stage != errors.StageAutoDeploy {
+		t.Fatalf("Stage = %q, want %s (error: %v)", stage, errors.StageAutoDeploy, err)
+	}
+	if n := len(relayer.Submissions()); n != 1 {
+		t.Errorf("got %d submissions, want only the deployment", n)
+	}
+}
+
+func TestExecuteOnSafe_NoAutoDeploy(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	relayer.SetDeployed(false)
+	c := newTestClient(t, relayer)
+
+	safe := "0x6e0c80c90ea6c15917308F820Eac91Ce2724B5b5"
+	_, err := c.ExecuteOnSafe(safe, testTransactions(), "")
+
+	var notDeployed *errors.SafeNotDeployedError
+	if !stderrors.As(err, &notDeployed) || notDeployed.SafeAddress != safe {
+		t.Fatalf("error = %v, want SafeNotDeployedError for %s", err, safe)
+	}
+}
+
+func TestExecute_DeployedCache(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	relayer.SetDeployed(false)
+	checks := countDeployedChecks(relayer)
+	c := newTestClient(t, relayer)
+
+	// A "not deployed" result is reused within deployedTTL
+	for i := 0; i < 2; i++ {
+		if _, err := c.Execute(testTransactions(), ""); err == nil {
+			t.Fatal("Execute on an undeployed Safe should fail")
+		}
+	}
+	if got := checks(); got != 1 {
+		t.Errorf("got %d deployed checks within the TTL, want 1", got)
+	}
+
+	// Submitting a deployment drops the negative result
+	if _, err := c.Deploy(); err != nil {
+		t.Fatalf("Deploy failed: %v", err)
+	}
+	relayer.SetDeployed(true)
+	before := checks()
+	if _, err := c.Execute(testTransactions(), ""); err != nil {
+		t.Fatalf("Execute after deployment failed: %v", err)
+	}
+	if got := checks() - before; got != 1 {
+		t.Errorf("got %d deployed checks after Deploy, want 1", got)
+	}
+
+	// A deployed Safe stays deployed, so the positive result does not expire
+	testClock(c).Advance(10 * deployedTTL)
+	before = checks()
+	if _, err := c.Execute(testTransactions(), ""); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if got := checks() - before; got != 0 {
+		t.Errorf("got %d deployed checks for a deployed Safe, want 0", got)
+	}
+}
+
+func TestExecute_DeployedCacheExpires(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	relayer.SetDeployed(false)
+	checks := countDeployedChecks(relayer)
+	c := newTestClient(t, relayer)
+
+	if _, err := c.Execute(testTransactions(), ""); err == nil {
+		t.Fatal("Execute on an undeployed Safe should fail")
+	}
+
+	// Deployed elsewhere; picked up once the negative result expires
+	relayer.SetDeployed(true)
+	testClock(c).Advance(deployedTTL)
+	if _, err := c.Execute(testTransactions(), ""); err != nil {
+		t.Fatalf("Execute after the TTL failed: %v", err)
+	}
+	if got := checks(); got != 2 {
+		t.Errorf("got %d deployed checks, want 2", got)
+	}
+}
+
+func TestExecute_AutoDeployCachesDeployed(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	relayer.SetDeployed(false)
+	checks := countDeployedChecks(relayer)
+	relayer.ScriptStates(models.STATE_MINED)
+	c := newTestClient(t, relayer)
+
+	if _, err := c.ExecuteWithOptions(testTransactions(), "", ExecuteOptions{AutoDeploy: true}); err != nil {
+		t.Fatalf("ExecuteWithOptions failed: %v", err)
+	}
+	before := checks()
+	if _, err := c.Execute(testTransactions(), ""); err != nil {
+		t.Fatalf("Execute after auto-deploy failed: %v", err)
+	}
+	if got := checks() - before; got != 0 {
+		t.Errorf("got %d deployed checks after auto-deploy, want 0", got)
+	}
+}
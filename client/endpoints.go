@@ -1,5 +1,17 @@
 package client
 
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
 // API endpoints for the Relayer API
 const (
 	// GET_NONCE returns the current nonce for a signer
@@ -16,4 +28,156 @@ const (
 
 	// SUBMIT_TRANSACTION submits a new transaction to the relayer
 	SUBMIT_TRANSACTION = "/submit"
+
+	// CANCEL_TRANSACTION cancels a transaction that has not executed yet
+	CANCEL_TRANSACTION = "/cancel"
+
+	// GET_CAPABILITIES lists the features the relayer supports
+	GET_CAPABILITIES = "/capabilities"
+
+	// GET_STATUS reports relayer health and maintenance windows
+	GET_STATUS = "/status"
+
+	// GET_USAGE reports the builder's quota usage
+	GET_USAGE = "/usage"
+
+	// GET_KEY_INFO reports the scopes of the builder API key
+	GET_KEY_INFO = "/key-info"
+
+	// DEBUG_VALIDATE reports the hash and signers the relayer computes for a
+	// submission payload; not every deployment serves it
+	DEBUG_VALIDATE = "/debug/validate"
 )
+
+// endpoint is a typed request for one relayer API operation. Client methods
+// translate their arguments into one and send it to the path it encodes, so
+// paths, parameter normalization and, for authenticated operations, the path
+// the builder headers sign are defined in one place.
+type endpoint interface {
+	// method is the operation's HTTP method
+	method() string
+	// encode validates and normalizes the request's parameters and returns
+	// the path and query string it is sent to
+	encode() (string, error)
+	// authenticated reports whether the operation needs builder headers
+	authenticated() bool
+}
+
+// getNonceRequest is GET_NONCE; the response is a models.NonceResponse
+type getNonceRequest struct {
+	signerAddress string
+	signerType    string
+}
+
+func (r getNonceRequest) method() string      { return "GET" }
+func (r getNonceRequest) authenticated() bool { return false }
+
+func (r getNonceRequest) encode() (string, error) {
+	address, err := checksumAddress(r.signerAddress)
+	if err != nil {
+		return "", err
+	}
+	if _, err := models.ParseSignerType(r.signerType); err != nil {
+		return "", err
+	}
+	return encodePath(GET_NONCE, url.Values{"address": {address}, "type": {r.signerType}}), nil
+}
+
+// getDeployedRequest is GET_DEPLOYED; the response is a models.DeployedResponse
+type getDeployedRequest struct {
+	safeAddress string
+}
+
+func (r getDeployedRequest) method() string      { return "GET" }
+func (r getDeployedRequest) authenticated() bool { return false }
+
+func (r getDeployedRequest) encode() (string, error) {
+	address, err := checksumAddress(r.safeAddress)
+	if err != nil {
+		return "", err
+	}
+	return encodePath(GET_DEPLOYED, url.Values{"address": {address}}), nil
+}
+
+// getTransactionRequest is GET_TRANSACTION; the response is a
+// []models.RelayerTransaction holding the transaction
+type getTransactionRequest struct {
+	transactionID string
+	// wait, when positive, is sent as the long-poll parameter rounded up
+	// to whole seconds
+	wait time.Duration
+}
+
+func (r getTransactionRequest) method() string      { return "GET" }
+func (r getTransactionRequest) authenticated() bool { return false }
+
+func (r getTransactionRequest) encode() (string, error) {
+	if r.transactionID == "" {
+		return "", errors.ErrMissingRequiredField("transactionID")
+	}
+	// Built by hand rather than from url.Values: every poll encodes one,
+	// and an ID needing no escaping costs no allocation beyond the path
+	path := GET_TRANSACTION + "?id=" + url.QueryEscape(r.transactionID)
+	if r.wait > 0 {
+		path += "&" + longPollParam + "=" + strconv.FormatInt(int64((r.wait+time.Second-1)/time.Second), 10)
+	}
+	return path, nil
+}
+
+// getTransactionsRequest is GET_TRANSACTIONS; the response is a
+// models.GetTransactionsResponse
+type getTransactionsRequest struct{}
+
+func (r getTransactionsRequest) method() string          { return "GET" }
+func (r getTransactionsRequest) authenticated() bool     { return true }
+func (r getTransactionsRequest) encode() (string, error) { return GET_TRANSACTIONS, nil }
+
+// submitTransactionRequest is SUBMIT_TRANSACTION, sent with the versioned
+// request as its body; the response is a models.SubmitTransactionResponse
+type submitTransactionRequest struct{}
+
+func (r submitTransactionRequest) method() string          { return "POST" }
+func (r submitTransactionRequest) authenticated() bool     { return true }
+func (r submitTransactionRequest) encode() (string, error) { return SUBMIT_TRANSACTION, nil }
+
+// checksumAddress returns address in its EIP-55 checksummed form, the one
+// the relayer's lookups are keyed by
+func checksumAddress(address string) (string, error) {
+	if !common.IsHexAddress(address) {
+		return "", errors.ErrInvalidAddress(address)
+	}
+	return common.HexToAddress(address).Hex(), nil
+}
+
+// encodePath appends query, with its keys sorted, to path
+func encodePath(path string, query url.Values) string {
+	if len(query) == 0 {
+		return path
+	}
+	return path + "?" + query.Encode()
+}
+
+// getEndpoint sends the unauthenticated GET req and decodes its response
+// into target, aborting when ctx is done
+func (c *ReadOnlyClient) getEndpoint(ctx context.Context, req endpoint, target interface{}) error {
+	path, err := req.encode()
+	if err != nil {
+		return err
+	}
+	return c.httpClient.GetJSONContext(ctx, path, nil, target)
+}
+
+// prepareEndpoint encodes req and returns the path it is sent to with, for
+// an authenticated operation, builder headers signing exactly that path and
+// body, nil for none
+func (c *RelayClient) prepareEndpoint(req endpoint, body []byte) (string, map[string]string, error) {
+	path, err := req.encode()
+	if err != nil || !req.authenticated() {
+		return path, nil, err
+	}
+	headers, err := c.generateBuilderHeaders(req.method(), path, body)
+	if err != nil {
+		return "", nil, err
+	}
+	return path, headers, nil
+}
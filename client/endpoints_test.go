@@ -0,0 +1,87 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+func TestEndpoint_Encode(t *testing.T) {
+	const (
+		lower    = "0xf39fd6e51aad88f6f4ce6ab8827279cfffb92266"
+		checksum = "0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266"
+	)
+	tests := []struct {
+		name          string
+		request       endpoint
+		wantMethod    string
+		wantPath      string
+		authenticated bool
+	}{
+		{"nonce", getNonceRequest{signerAddress: lower, signerType: string(models.SAFE_SIGNER)}, "GET", "/nonce?address=" + checksum + "&type=SAFE", false},
+		{"nonce keeps checksum", getNonceRequest{signerAddress: checksum, signerType: string(models.EOA)}, "GET", "/nonce?address=" + checksum + "&type=EOA", false},
+		{"deployed", getDeployedRequest{safeAddress: lower}, "GET", "/deployed?address=" + checksum, false},
+		{"transaction", getTransactionRequest{transactionID: "tx-1"}, "GET", "/transaction?id=tx-1", false},
+		{"transaction escapes id", getTransactionRequest{transactionID: "a b&c=d"}, "GET", "/transaction?id=a+b%26c%3Dd", false},
+		{"transaction long poll", getTransactionRequest{transactionID: "tx-1", wait: 1500 * time.Millisecond}, "GET", "/transaction?id=tx-1&wait=2", false},
+		{"transactions", getTransactionsRequest{}, "GET", "/transactions", true},
+		{"submit", submitTransactionRequest{}, "POST", "/submit", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, err := tt.request.encode()
+			if err != nil {
+				t.Fatalf("encode failed: %v", err)
+			}
+			if path != tt.wantPath || tt.request.method() != tt.wantMethod || tt.request.authenticated() != tt.authenticated {
+				t.Errorf("%s %s (authenticated %v), want %s %s (authenticated %v)",
+					tt.request.method(), path, tt.request.authenticated(), tt.wantMethod, tt.wantPath, tt.authenticated)
+			}
+		})
+	}
+}
+
+func TestEndpoint_EncodeInvalid(t *testing.T) {
+	tests := map[string]endpoint{
+		"nonce address":       getNonceRequest{signerAddress: "0x1234", signerType: string(models.SAFE_SIGNER)},
+		"nonce signer type":   getNonceRequest{signerAddress: "0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266", signerType: "NOT-A-SIGNER"},
+		"deployed address":    getDeployedRequest{safeAddress: "safe"},
+		"empty transactionID": getTransactionRequest{},
+	}
+	for name, request := range tests {
+		t.Run(name, func(t *testing.T) {
+			if path, err := request.encode(); err == nil {
+				t.Errorf("encode = %s, want an error", path)
+			}
+		})
+	}
+}
+
+func TestPrepareEndpoint(t *testing.T) {
+	c := newTestClient(t, newFakeRelayer(t))
+
+	path, headers, err := c.prepareEndpoint(getTransactionsRequest{}, nil)
+	if err != nil {
+		t.Fatalf("prepareEndpoint failed: %v", err)
+	}
+	if path != GET_TRANSACTIONS || headers["POLY_BUILDER_SIGNATURE"] == "" {
+		t.Errorf("prepareEndpoint = %s, %v, want signed headers for %s", path, headers, GET_TRANSACTIONS)
+	}
+
+	// A GET is sent without a body, so it signs none
+	if _, _, err := c.prepareEndpoint(getTransactionsRequest{}, []byte(`{}`)); err == nil {
+		t.Error("prepareEndpoint signed a GET with a body")
+	}
+	_, emptyHeaders, err := c.prepareEndpoint(getTransactionsRequest{}, []byte{})
+	if err != nil || emptyHeaders["POLY_BUILDER_SIGNATURE"] != headers["POLY_BUILDER_SIGNATURE"] {
+		t.Errorf("empty body signed %v, %v, want the signature of no body", emptyHeaders, err)
+	}
+
+	// Unauthenticated operations are sent without builder headers
+	path, headers, err = c.prepareEndpoint(getDeployedRequest{safeAddress: "0x6e0c80c90ea6c15917308F820Eac91Ce2724B5b5"}, nil)
+	if err != nil || headers != nil || path != "/deployed?address=0x6e0c80c90ea6c15917308F820Eac91Ce2724B5b5" {
+		t.Errorf("prepareEndpoint = %s, %v, %v", path, headers, err)
+	}
+}
@@ -0,0 +1,118 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+	"testing"
+
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	relayerhttp "github.com/davidt58/go-builder-relayer-client/http"
+	"github.com/davidt58/go-builder-relayer-client/models"
+	"github.com/davidt58/go-builder-relayer-client/relayertest"
+)
+
+// serveEndpoints scripts the endpoints the ScriptedRelayer does not serve
+// by default: tx-nonce-0 is mined and every other transaction is new
+func serveEndpoints(f *fakeRelayer) {
+	state := func(id string) models.RelayerTransactionState {
+		if id == "tx-nonce-0" {
+			return models.STATE_MINED
+		}
+		return models.STATE_NEW
+	}
+	f.Handle(GET_TRANSACTION, func(w http.ResponseWriter, r *http.Request) {
+		id := r.URL.Query().Get("id")
+		nonce := "0"
+		json.NewEncoder(w).Encode([]models.RelayerTransaction{{TransactionID: id, State: state(id), Nonce: &nonce}})
+	})
+	f.Handle(GET_TRANSACTIONS, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(models.GetTransactionsResponse{Transactions: []models.RelayerTransaction{
+			{TransactionID: "tx-nonce-0", State: models.STATE_MINED},
+		}})
+	})
+	f.Handle(CANCEL_TRANSACTION, func(w http.ResponseWriter, r *http.Request) {
+		var request models.CancelTransactionRequest
+		json.NewDecoder(r.Body).Decode(&request)
+		json.NewEncoder(w).Encode(models.CancelTransactionResponse{TransactionID: request.TransactionID, State: models.STATE_CANCELLED})
+	})
+	f.Handle(GET_STATUS, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(models.RelayerStatus{Healthy: true, Message: "ok"})
+	})
+	f.Handle(GET_USAGE, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(models.Usage{Limit: 100, Remaining: 90})
+	})
+}
+
+func TestResponseEnvelope_AllEndpoints(t *testing.T) {
+	tests := []struct {
+		name     string
+		relayer  relayertest.Envelope
+		envelope relayerhttp.ResponseEnvelope
+	}{
+		{"bare", relayertest.NoEnvelope, relayerhttp.EnvelopeNone},
+		{"bare with auto detection", relayertest.NoEnvelope, relayerhttp.EnvelopeAuto},
+		{"enveloped", relayertest.DataEnvelope, relayerhttp.EnvelopeDataWrapper},
+		{"enveloped with auto detection", relayertest.DataEnvelope, relayerhttp.EnvelopeAuto},
+		{"enveloped errors with 200", relayertest.DataEnvelopeOK, relayerhttp.EnvelopeDataWrapper},
+		{"enveloped errors with 200 and auto detection", relayertest.DataEnvelopeOK, relayerhttp.EnvelopeAuto},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			relayer := newFakeRelayer(t)
+			relayer.SetEnvelope(tt.relayer)
+			serveEndpoints(relayer)
+			c := newLoggingTestClient(t, relayer, new(bytes.Buffer), WithResponseEnvelope(tt.envelope))
+
+			relayer.SetDeployed(false)
+			if _, err := c.Deploy(); err != nil {
+				t.Fatalf("Deploy failed: %v", err)
+			}
+			relayer.SetDeployed(true)
+
+			response, err := c.Execute(relayertest.SampleApprovalBatch(), "")
+			if err != nil {
+				t.Fatalf("Execute failed: %v", err)
+			}
+			if txn, err := response.WaitUntilMined(); err != nil || txn.State != models.STATE_MINED {
+				t.Fatalf("WaitUntilMined = %+v, %v", txn, err)
+			}
+
+			pending, err := c.Execute(relayertest.SampleApprovalBatch(), "")
+			if err != nil {
+				t.Fatalf("second Execute failed: %v", err)
+			}
+			if cancelled, err := c.CancelTransaction(pending.TransactionID); err != nil || cancelled.State != models.STATE_CANCELLED {
+				t.Fatalf("CancelTransaction = %+v, %v", cancelled, err)
+			}
+
+			if list, err := c.GetTransactions(); err != nil || len(list.Transactions) != 1 {
+				t.Fatalf("GetTransactions = %+v, %v", list, err)
+			}
+			if status, err := c.GetRelayerStatus(); err != nil || !status.Healthy || status.Message != "ok" {
+				t.Fatalf("GetRelayerStatus = %+v, %v", status, err)
+			}
+			if usage, err := c.GetUsage(); err != nil || usage.Remaining != 90 {
+				t.Fatalf("GetUsage = %+v, %v", usage, err)
+			}
+			// A 404, possibly reported only inside the envelope
+			if _, err := c.GetKeyCapabilities(); err != errors.ErrKeyInfoUnavailable {
+				t.Fatalf("GetKeyCapabilities error = %v, want ErrKeyInfoUnavailable", err)
+			}
+
+			// A rejected submission, possibly with a 200 status
+			relayer.Handle(SUBMIT_TRANSACTION, func(w http.ResponseWriter, r *http.Request) {
+				code := "INVALID_SIGNATURE"
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(models.ErrorResponse{Error: "signature does not match", Code: &code})
+			})
+			_, err = c.Execute(relayertest.SampleApprovalBatch(), "")
+			var apiErr *errors.RelayerApiError
+			if !stderrors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest || apiErr.Code != "INVALID_SIGNATURE" {
+				t.Fatalf("error = %v, want a 400 RelayerApiError", err)
+			}
+		})
+	}
+}
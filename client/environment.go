@@ -0,0 +1,55 @@
+package client
+
+import (
+	"github.com/davidt58/go-builder-relayer-client/config"
+	"github.com/davidt58/go-builder-relayer-client/errors"
+)
+
+// WithEnvironment tags the client with the relayer environment it is meant
+// for, e.g. config.EnvProduction, or a custom one. NewRelayClient fails with
+// an EnvironmentMismatchError when the relayer URL is a registered deployment
+// of another environment or the builder credentials are tagged with another.
+func WithEnvironment(env config.Environment) Option {
+	return func(c *RelayClient) error {
+		c.environment = env
+		return nil
+	}
+}
+
+// Environment returns the client's environment: its WithEnvironment tag,
+// else the builder credentials' tag, else the environment the relayer URL is
+// registered in (see config.RelayerEnvironment). It is empty when none is
+// known. Events and metrics are labelled with it.
+func (c *RelayClient) Environment() config.Environment {
+	return c.environment
+}
+
+// checkEnvironment returns an EnvironmentMismatchError if the client's tag,
+// the builder credentials' tag and the registered environment of the relayer
+// URL disagree; untagged or unregistered sides are not compared. It then
+// settles the client's Environment.
+func (c *RelayClient) checkEnvironment() error {
+	relayerEnv, registered := config.RelayerEnvironment(c.relayerURL)
+	var credentialsEnv config.Environment
+	if c.builderConfig != nil {
+		credentialsEnv = c.builderConfig.Environment
+	}
+
+	relayer := "relayer " + c.relayerURL
+	switch {
+	case registered && credentialsEnv != "" && credentialsEnv != relayerEnv:
+		return errors.ErrEnvironmentMismatch("builder credentials", string(credentialsEnv), relayer, string(relayerEnv))
+	case registered && c.environment != "" && c.environment != relayerEnv:
+		return errors.ErrEnvironmentMismatch("client", string(c.environment), relayer, string(relayerEnv))
+	case c.environment != "" && credentialsEnv != "" && c.environment != credentialsEnv:
+		return errors.ErrEnvironmentMismatch("builder credentials", string(credentialsEnv), "client", string(c.environment))
+	}
+
+	if c.environment == "" {
+		c.environment = credentialsEnv
+	}
+	if c.environment == "" {
+		c.environment = relayerEnv
+	}
+	return nil
+}
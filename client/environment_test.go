@@ -0,0 +1,119 @@
+package client
+
+import (
+	"bytes"
+	stderrors "errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/davidt58/go-builder-relayer-client/config"
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/models"
+	"github.com/davidt58/go-builder-relayer-client/relayertest"
+)
+
+func TestNewRelayClient_Environment(t *testing.T) {
+	const (
+		production = "https://relayer-v2.polymarket.com"
+		staging    = "https://relayer-v2-staging.polymarket.dev/"
+		private    = "https://relayer.internal.example"
+	)
+	tests := []struct {
+		name        string
+		relayerURL  string
+		credentials config.Environment
+		client      config.Environment
+		want        config.Environment
+		wantTagged  string
+	}{
+		{name: "untagged", relayerURL: production, want: config.EnvProduction},
+		{name: "untagged unregistered", relayerURL: private},
+		{name: "matching credentials", relayerURL: production, credentials: config.EnvProduction, want: config.EnvProduction},
+		{name: "matching client", relayerURL: staging, client: config.EnvStaging, want: config.EnvStaging},
+		{name: "unregistered relayer", relayerURL: private, credentials: config.EnvStaging, want: config.EnvStaging},
+		{name: "custom tag", relayerURL: private, credentials: "qa", client: "qa", want: "qa"},
+		{name: "credentials mismatch", relayerURL: production, credentials: config.EnvStaging, wantTagged: "builder credentials"},
+		{name: "client mismatch", relayerURL: staging, client: config.EnvProduction, wantTagged: "client"},
+		{name: "credentials and client mismatch", relayerURL: private, credentials: config.EnvStaging, client: "qa", wantTagged: "builder credentials"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builderConfig := config.NewBuilderConfig("test-key", "dGVzdC1zZWNyZXQ=", "test-pass")
+			builderConfig.Environment = tt.credentials
+			var opts []Option
+			if tt.client != "" {
+				opts = append(opts, WithEnvironment(tt.client))
+			}
+
+			c, err := NewRelayClient(tt.relayerURL, 137, testPrivateKey, builderConfig, append(opts, WithLogOutput(new(bytes.Buffer)))...)
+			if tt.wantTagged != "" {
+				var mismatch *errors.EnvironmentMismatchError
+				if !stderrors.As(err, &mismatch) || mismatch.Tagged != tt.wantTagged {
+					t.Fatalf("error = %v, want an EnvironmentMismatchError for the %s", err, tt.wantTagged)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewRelayClient failed: %v", err)
+			}
+			if env := c.Environment(); env != tt.want {
+				t.Errorf("Environment = %q, want %q", env, tt.want)
+			}
+		})
+	}
+}
+
+// environmentCollector records the environment labels of its observations
+type environmentCollector struct {
+	latencyCollector
+	mu   sync.Mutex
+	envs []config.Environment
+}
+
+func (e *environmentCollector) ObserveEnvironmentLatency(env config.Environment, phase models.LatencyPhase, txType models.TransactionType, d time.Duration) {
+	e.mu.Lock()
+	e.envs = append(e.envs, env)
+	e.mu.Unlock()
+	e.ObserveLatency(phase, txType, d)
+}
+
+func TestEnvironment_Labels(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	scriptTimedStates(relayer, false,
+		snapshot{models.STATE_NEW, 0},
+		snapshot{models.STATE_CONFIRMED, 10 * time.Second},
+	)
+	collector := &environmentCollector{}
+	sink := NewChannelSink(16)
+	c := newLoggingTestClient(t, relayer, new(bytes.Buffer), WithEnvironment(config.EnvStaging), WithMetricsCollector(collector), WithEventSink(sink))
+
+	response, err := c.Execute(relayertest.SampleApprovalBatch(), "")
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if _, err := response.Wait(); err != nil {
+		t.Fatalf("Wait failed: %v", err)
+	}
+
+	if len(collector.envs) == 0 {
+		t.Fatal("no labelled observations")
+	}
+	for _, env := range collector.envs {
+		if env != config.EnvStaging {
+			t.Errorf("observation labelled %q, want %q", env, config.EnvStaging)
+		}
+	}
+	close(sink.events)
+	events := 0
+	for change := range sink.Events() {
+		events++
+		if change.Environment != config.EnvStaging {
+			t.Errorf("%s event labelled %q, want %q", change.Kind, change.Environment, config.EnvStaging)
+		}
+	}
+	if events == 0 {
+		t.Error("no events")
+	}
+}
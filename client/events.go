@@ -0,0 +1,146 @@
+package client
+
+import (
+	"time"
+
+	"github.com/davidt58/go-builder-relayer-client/config"
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+// StateChangeKind classifies a TransactionStateChange
+type StateChangeKind string
+
+const (
+	// StateChanged reports a state different from the one previously
+	// observed during the wait. The first observation is not a change.
+	StateChanged StateChangeKind = "STATE_CHANGED"
+	// StateWaitCompleted reports the transaction reached a target state
+	StateWaitCompleted StateChangeKind = "COMPLETED"
+	// StateWaitFailed reports the wait ended with an error other than a
+	// timeout, e.g. the transaction failed or was cancelled
+	StateWaitFailed StateChangeKind = "FAILED"
+	// StateWaitTimedOut reports the polls ran out before a target state
+	StateWaitTimedOut StateChangeKind = "TIMED_OUT"
+)
+
+// TransactionStateChange is an event observed while polling a transaction.
+// PreviousState and State are equal for the terminal kinds.
+type TransactionStateChange struct {
+	Kind StateChangeKind
+	// TransactionID and TransactionHash identify the transaction; the hash
+	// is empty until the relayer reports one
+	TransactionID   string
+	TransactionHash string
+	// PreviousState is the state observed before State, empty if none was
+	PreviousState models.RelayerTransactionState
+	State         models.RelayerTransactionState
+	// ObservedAt is the client's clock time of the poll; RelayerUpdatedAt is
+	// the transaction's updatedAt as reported by the relayer
+	ObservedAt       time.Time
+	RelayerUpdatedAt string
+	// Attempt is the 1-based poll of the wait that observed the event
+	Attempt int
+	// Err is why a StateWaitFailed or StateWaitTimedOut wait ended
+	Err error
+	// Environment is the environment of the client that observed the
+	// event (see RelayClient.Environment), empty when unknown
+	Environment config.Environment
+}
+
+// EventSink receives the state changes observed while polling. It is called
+// synchronously from the polling goroutine, so it should return quickly.
+type EventSink interface {
+	OnStateChange(TransactionStateChange)
+}
+
+// WithEventSink reports to sink every state change PollUntilState, the
+// WaitUntil methods, WaitAll and WaitAny observe, and how each wait ended
+func WithEventSink(sink EventSink) Option {
+	return func(c *RelayClient) error {
+		if sink == nil {
+			return errors.ErrMissingRequiredField("sink")
+		}
+		c.eventSink = sink
+		return nil
+	}
+}
+
+// ChannelSink is an EventSink that sends every event on a channel. Sends
+// block while the channel is full, so the events must be drained for
+// polling to make progress.
+type ChannelSink struct {
+	events chan TransactionStateChange
+}
+
+// NewChannelSink creates a ChannelSink whose channel buffers buffer events
+func NewChannelSink(buffer int) *ChannelSink {
+	return &ChannelSink{events: make(chan TransactionStateChange, buffer)}
+}
+
+// Events returns the channel the events are sent on
+func (s *ChannelSink) Events() <-chan TransactionStateChange {
+	return s.events
+}
+
+// OnStateChange sends change on the channel
+func (s *ChannelSink) OnStateChange(change TransactionStateChange) {
+	s.events <- change
+}
+
+// emitStateChange reports a transition from previous to the state of txn,
+// unless there was no previous state or it is unchanged
+func (c *RelayClient) emitStateChange(p *transactionPoll, previous models.RelayerTransactionState, txn *models.RelayerTransaction) {
+	if c.eventSink == nil || previous == "" || previous == txn.State {
+		return
+	}
+	c.eventSink.OnStateChange(c.stateChangeEvent(StateChanged, p, previous, txn, nil))
+}
+
+// emitWaitEnd reports how the wait of p ended, given its outcome
+func (c *RelayClient) emitWaitEnd(p *transactionPoll, txn *models.RelayerTransaction, err error) {
+	if c.eventSink == nil {
+		return
+	}
+	kind := StateWaitCompleted
+	if err != nil {
+		kind = StateWaitFailed
+	}
+	if txn == nil {
+		txn = p.lastTxn
+	}
+	state := p.lastState
+	if txn != nil {
+		state = txn.State
+	}
+	c.eventSink.OnStateChange(c.stateChangeEvent(kind, p, state, txn, err))
+}
+
+// emitWaitTimeout reports that the polls of p ran out
+func (c *RelayClient) emitWaitTimeout(p *transactionPoll, err error) {
+	if c.eventSink == nil {
+		return
+	}
+	c.eventSink.OnStateChange(c.stateChangeEvent(StateWaitTimedOut, p, p.lastState, p.lastTxn, err))
+}
+
+// stateChangeEvent builds an event for p; txn may be nil when no state was
+// observed
+func (c *RelayClient) stateChangeEvent(kind StateChangeKind, p *transactionPoll, previous models.RelayerTransactionState, txn *models.RelayerTransaction, err error) TransactionStateChange {
+	change := TransactionStateChange{
+		Kind:          kind,
+		TransactionID: p.transactionID,
+		PreviousState: previous,
+		State:         previous,
+		ObservedAt:    c.clock.Now(),
+		Attempt:       p.attempts,
+		Err:           err,
+		Environment:   c.environment,
+	}
+	if txn != nil {
+		change.TransactionHash = stringValue(txn.Hash)
+		change.State = txn.State
+		change.RelayerUpdatedAt = txn.UpdatedAt
+	}
+	return change
+}
@@ -0,0 +1,153 @@
+package client
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+// drainEvents returns the events buffered in sink
+func drainEvents(sink *ChannelSink) []TransactionStateChange {
+	var events []TransactionStateChange
+	for {
+		select {
+		case event := <-sink.Events():
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
+
+// eventSummary is the part of an event the tests compare
+type eventSummary struct {
+	kind     StateChangeKind
+	previous models.RelayerTransactionState
+	state    models.RelayerTransactionState
+	attempt  int
+}
+
+func summarize(events []TransactionStateChange) []eventSummary {
+	summaries := make([]eventSummary, len(events))
+	for i, event := range events {
+		summaries[i] = eventSummary{event.Kind, event.PreviousState, event.State, event.Attempt}
+	}
+	return summaries
+}
+
+func TestEventSink_PollUntilState(t *testing.T) {
+	tests := []struct {
+		name     string
+		states   []models.RelayerTransactionState
+		maxPolls int
+		want     []eventSummary
+	}{
+		{
+			name:     "progression to confirmed",
+			states:   []models.RelayerTransactionState{models.STATE_NEW, models.STATE_EXECUTED, models.STATE_MINED, models.STATE_CONFIRMED},
+			maxPolls: 10,
+			want: []eventSummary{
+				{StateChanged, models.STATE_NEW, models.STATE_EXECUTED, 2},
+				{StateChanged, models.STATE_EXECUTED, models.STATE_MINED, 3},
+				{StateChanged, models.STATE_MINED, models.STATE_CONFIRMED, 4},
+				{StateWaitCompleted, models.STATE_CONFIRMED, models.STATE_CONFIRMED, 4},
+			},
+		},
+		{
+			name:     "repeated states are not changes",
+			states:   []models.RelayerTransactionState{models.STATE_NEW, models.STATE_NEW, models.STATE_MINED, models.STATE_MINED, models.STATE_CONFIRMED},
+			maxPolls: 10,
+			want: []eventSummary{
+				{StateChanged, models.STATE_NEW, models.STATE_MINED, 3},
+				{StateChanged, models.STATE_MINED, models.STATE_CONFIRMED, 5},
+				{StateWaitCompleted, models.STATE_CONFIRMED, models.STATE_CONFIRMED, 5},
+			},
+		},
+		{
+			name:     "failure",
+			states:   []models.RelayerTransactionState{models.STATE_NEW, models.STATE_FAILED},
+			maxPolls: 10,
+			want: []eventSummary{
+				{StateChanged, models.STATE_NEW, models.STATE_FAILED, 2},
+				{StateWaitFailed, models.STATE_FAILED, models.STATE_FAILED, 2},
+			},
+		},
+		{
+			name:     "timeout",
+			states:   []models.RelayerTransactionState{models.STATE_NEW, models.STATE_EXECUTED},
+			maxPolls: 3,
+			want: []eventSummary{
+				{StateChanged, models.STATE_NEW, models.STATE_EXECUTED, 2},
+				{StateWaitTimedOut, models.STATE_EXECUTED, models.STATE_EXECUTED, 3},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			relayer := newFakeRelayer(t)
+			relayer.ScriptStates(tt.states...)
+			c := newTestClient(t, relayer)
+			sink := NewChannelSink(16)
+			if err := WithEventSink(sink)(c); err != nil {
+				t.Fatalf("WithEventSink failed: %v", err)
+			}
+
+			_, waitErr := c.PollUntilState("tx-1", []models.RelayerTransactionState{models.STATE_CONFIRMED}, models.STATE_FAILED, tt.maxPolls, 1)
+
+			events := drainEvents(sink)
+			if got := summarize(events); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("events = %+v, want %+v", got, tt.want)
+			}
+			for _, event := range events {
+				if event.TransactionID != "tx-1" || event.ObservedAt.Before(testClockStart) {
+					t.Errorf("event %+v lacks the transaction identity or observation time", event)
+				}
+			}
+			if last := events[len(events)-1]; (last.Err == nil) != (waitErr == nil) {
+				t.Errorf("terminal event error = %v, want the wait's %v", last.Err, waitErr)
+			}
+		})
+	}
+}
+
+func TestEventSink_WaitAll(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	relayer.ScriptStates(models.STATE_NEW, models.STATE_NEW, models.STATE_MINED, models.STATE_MINED)
+	c := newTestClient(t, relayer)
+	sink := NewChannelSink(16)
+	if err := WithEventSink(sink)(c); err != nil {
+		t.Fatalf("WithEventSink failed: %v", err)
+	}
+
+	responses := []*models.ClientRelayerTransactionResponse{
+		models.NewClientRelayerTransactionResponse("tx-1"),
+		models.NewClientRelayerTransactionResponse("tx-2"),
+	}
+	if _, err := c.WaitAll(responses, models.WaitOptions{TargetStates: []models.RelayerTransactionState{models.STATE_MINED}}); err != nil {
+		t.Fatalf("WaitAll failed: %v", err)
+	}
+
+	// The script is shared: both transactions see NEW on the first round and
+	// MINED on the second, each tracked separately
+	byID := map[string][]eventSummary{}
+	for _, event := range drainEvents(sink) {
+		byID[event.TransactionID] = append(byID[event.TransactionID], eventSummary{event.Kind, event.PreviousState, event.State, event.Attempt})
+	}
+	want := []eventSummary{
+		{StateChanged, models.STATE_NEW, models.STATE_MINED, 2},
+		{StateWaitCompleted, models.STATE_MINED, models.STATE_MINED, 2},
+	}
+	for _, id := range []string{"tx-1", "tx-2"} {
+		if !reflect.DeepEqual(byID[id], want) {
+			t.Errorf("%s events = %+v, want %+v", id, byID[id], want)
+		}
+	}
+}
+
+func TestWithEventSink_Nil(t *testing.T) {
+	if err := WithEventSink(nil)(&RelayClient{}); err == nil {
+		t.Error("Expected error for a nil sink")
+	}
+}
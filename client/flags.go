@@ -0,0 +1,106 @@
+package client
+
+import (
+	"os"
+	"strings"
+
+	"github.com/davidt58/go-builder-relayer-client/config"
+	"github.com/davidt58/go-builder-relayer-client/signer"
+)
+
+// FlagsEnvVar names the environment variable read by NewRelayClient for
+// feature flags, a comma-separated list of Flags field names such as
+// "UseEIP712DomainChainID,-UseCorrectedSignatureScheme". A name switches its
+// flag on and a name prefixed with "-" switches it off; when a flag is named
+// twice, the last entry wins. Names are case-insensitive.
+const FlagsEnvVar = "RELAYER_CLIENT_FLAGS"
+
+// Flags switch on behavior changes that alter what goes over the wire, so
+// they can be rolled out gradually and turned off without a release. Every
+// flag defaults to off, the current behavior.
+type Flags struct {
+	// UseEIP712DomainChainID signs every Safe transaction with the chain ID
+	// in its EIP-712 domain, the Safe 1.3.0+ layout, without reading the
+	// Safe's VERSION(). Off, ExecuteOnSafe signs Safes that report a version
+	// before 1.3.0 without it.
+	UseEIP712DomainChainID bool
+	// UseCorrectedSignatureScheme signs SAFE transactions with plain EIP-712
+	// signatures (v of 27/28) instead of the chain's configured scheme.
+	// WithSignatureScheme takes precedence.
+	UseCorrectedSignatureScheme bool
+}
+
+// flag returns the field of f called name, ignoring case, or nil
+func (f *Flags) flag(name string) *bool {
+	switch strings.ToLower(name) {
+	case "useeip712domainchainid":
+		return &f.UseEIP712DomainChainID
+	case "usecorrectedsignaturescheme":
+		return &f.UseCorrectedSignatureScheme
+	}
+	return nil
+}
+
+// flagOverride switches the flag called name on or off
+type flagOverride struct {
+	name string
+	on   bool
+}
+
+// parseFlags reads the comma-separated list value into overrides, in list
+// order, and returns the names it does not know
+func parseFlags(value string) ([]flagOverride, []string) {
+	var overrides []flagOverride
+	var unknown []string
+	var known Flags
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		on := !strings.HasPrefix(name, "-")
+		if !on {
+			name = strings.TrimSpace(name[1:])
+		}
+		if name == "" {
+			continue
+		}
+		if known.flag(name) == nil {
+			unknown = append(unknown, name)
+			continue
+		}
+		overrides = append(overrides, flagOverride{name: name, on: on})
+	}
+	return overrides, unknown
+}
+
+// WithFlags sets the flags the client starts with, replacing those of an
+// earlier WithFlags. RELAYER_CLIENT_FLAGS takes precedence: a deployment can
+// switch on a flag the code leaves off and switch off one the code sets.
+func WithFlags(flags Flags) Option {
+	return func(c *RelayClient) error {
+		c.flags = flags
+		return nil
+	}
+}
+
+// Flags returns the feature flags in effect
+func (c *RelayClient) Flags() Flags {
+	return c.flags
+}
+
+// applyFlags overrides the client's flags with those from the environment
+// and applies the ones read at construction. Unknown names are logged rather
+// than rejected, so rolling back to a release without a flag still starts.
+func (c *RelayClient) applyFlags() {
+	overrides, unknown := parseFlags(os.Getenv(FlagsEnvVar))
+	for _, name := range unknown {
+		c.logger.Printf("Warning: ignoring unknown flag %q in %s", name, FlagsEnvVar)
+	}
+	for _, override := range overrides {
+		*c.flags.flag(override.name) = override.on
+	}
+
+	if c.flags.UseCorrectedSignatureScheme && !c.signatureSchemeSet {
+		c.overrideContractConfig(func(contractConfig *config.ContractConfig) {
+			contractConfig.SignatureScheme = signer.SchemeEIP712
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package client
+
+import (
+	"encoding/base64"
+	"io"
+	"log"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/davidt58/go-builder-relayer-client/builder"
+	"github.com/davidt58/go-builder-relayer-client/clock"
+	"github.com/davidt58/go-builder-relayer-client/config"
+	"github.com/davidt58/go-builder-relayer-client/models"
+	"github.com/davidt58/go-builder-relayer-client/signer"
+)
+
+// newFlaggedClient returns a test client created with RELAYER_CLIENT_FLAGS
+// set to env and opts
+func newFlaggedClient(t *testing.T, relayer *fakeRelayer, env string, opts ...Option) *RelayClient {
+	t.Helper()
+	t.Setenv(FlagsEnvVar, env)
+
+	secret := base64.URLEncoding.EncodeToString([]byte("test-secret"))
+	builderConfig := config.NewBuilderConfig("test-key", secret, "test-pass")
+	c, err := NewRelayClient(relayer.URL(), 137, testPrivateKey, builderConfig, append([]Option{WithLogOutput(io.Discard)}, opts...)...)
+	if err != nil {
+		t.Fatalf("NewRelayClient failed: %v", err)
+	}
+	c.logger = log.New(io.Discard, "", 0)
+	c.clock = clock.NewAutoFake(testClockStart)
+	return c
+}
+
+func TestFlags_Sources(t *testing.T) {
+	tests := []struct {
+		name  string
+		env   string
+		flags Flags
+		want  Flags
+	}{
+		{name: "default"},
+		{name: "environment", env: "UseEIP712DomainChainID, usecorrectedsignaturescheme", want: Flags{UseEIP712DomainChainID: true, UseCorrectedSignatureScheme: true}},
+		{name: "unknown names are ignored", env: "UseEIP712DomainChainID,UseV3Headers,-UseV3Headers,,-", want: Flags{UseEIP712DomainChainID: true}},
+		{name: "option", flags: Flags{UseEIP712DomainChainID: true}, want: Flags{UseEIP712DomainChainID: true}},
+		{name: "option and environment", env: "UseCorrectedSignatureScheme", flags: Flags{UseEIP712DomainChainID: true}, want: Flags{UseEIP712DomainChainID: true, UseCorrectedSignatureScheme: true}},
+		{name: "environment switches off an option", env: "-UseEIP712DomainChainID", flags: Flags{UseEIP712DomainChainID: true, UseCorrectedSignatureScheme: true}, want: Flags{UseCorrectedSignatureScheme: true}},
+		{name: "last environment entry wins", env: "-UseEIP712DomainChainID, UseEIP712DomainChainID, UseCorrectedSignatureScheme, - UseCorrectedSignatureScheme", want: Flags{UseEIP712DomainChainID: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newFlaggedClient(t, newFakeRelayer(t), tt.env, WithFlags(Flags{UseEIP712DomainChainID: true}), WithFlags(tt.flags))
+			if got := c.Flags(); got != tt.want {
+				t.Errorf("Flags() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFlags_UseEIP712DomainChainID(t *testing.T) {
+	tests := []struct {
+		name        string
+		flag        bool
+		wantVersion string
+		wantCalls   int
+	}{
+		{name: "off signs for the reported version", wantVersion: "1.1.1", wantCalls: 1},
+		{name: "on signs with the chain ID", flag: true, wantVersion: "", wantCalls: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			relayer := newFakeRelayer(t)
+			c := newFlaggedClient(t, relayer, "", WithFlags(Flags{UseEIP712DomainChainID: tt.flag}))
+			rpc := &versionRPC{version: "1.1.1"}
+			c.rpcClient = rpc
+
+			if _, err := c.ExecuteOnSafe(otherSafe, testTransactions(), ""); err != nil {
+				t.Fatalf("ExecuteOnSafe failed: %v", err)
+			}
+			if rpc.callCount() != tt.wantCalls {
+				t.Errorf("VERSION() called %d times, want %d", rpc.callCount(), tt.wantCalls)
+			}
+
+			args := &models.SafeTransactionArgs{SafeAddress: otherSafe, Transactions: testTransactions(), Nonce: "0"}
+			expected, err := builder.BuildSafeTransactionRequestForSafeVersion(args, c.signer, c.currentContractConfig(), config.MultisendStandard, tt.wantVersion)
+			if err != nil {
+				t.Fatalf("BuildSafeTransactionRequestForSafeVersion failed: %v", err)
+			}
+			if relayer.Submissions()[0].Signature != expected.Signature {
+				t.Errorf("signature was not made for Safe version %q", tt.wantVersion)
+			}
+		})
+	}
+}
+
+func TestFlags_UseCorrectedSignatureScheme(t *testing.T) {
+	tests := []struct {
+		name       string
+		env        string
+		opts       []Option
+		wantScheme signer.SignatureScheme
+		validV     []byte
+	}{
+		{name: "off", validV: []byte{31, 32}},
+		{name: "on", env: "UseCorrectedSignatureScheme", wantScheme: signer.SchemeEIP712, validV: []byte{27, 28}},
+		{
+			name:       "explicit scheme wins",
+			env:        "UseCorrectedSignatureScheme",
+			opts:       []Option{WithSignatureScheme(signer.SchemeEthSign)},
+			wantScheme: signer.SchemeEthSign,
+			validV:     []byte{31, 32},
+		},
+	}
+
+	registered, err := config.GetContractConfig(137)
+	if err != nil {
+		t.Fatalf("GetContractConfig failed: %v", err)
+	}
+	registeredScheme := registered.SignatureScheme
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			relayer := newFakeRelayer(t)
+			c := newFlaggedClient(t, relayer, tt.env, tt.opts...)
+			if got := c.currentContractConfig().SignatureScheme; got != tt.wantScheme {
+				t.Errorf("SignatureScheme = %q, want %q", got, tt.wantScheme)
+			}
+			if registered, _ := config.GetContractConfig(137); registered.SignatureScheme != registeredScheme {
+				t.Errorf("registered config scheme changed to %q", registered.SignatureScheme)
+			}
+
+			if _, err := c.Execute(testTransactions(), ""); err != nil {
+				t.Fatalf("Execute failed: %v", err)
+			}
+			v := hexutil.MustDecode(relayer.Submissions()[0].Signature)[64]
+			if v != tt.validV[0] && v != tt.validV[1] {
+				t.Errorf("signature v = %d, want one of %v", v, tt.validV)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package client
+
+import (
+	"context"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+// checkNonceFreshness compares a pre-built SAFE request's nonce with the
+// Safe's next nonce, fetched past the response cache, so a request whose
+// nonce was consumed since it was built fails with a StaleNonceError instead
+// of the relayer's generic rejection. A request exactly one ahead fails with
+// a NonceNotYetCurrentError. Requests without a nonce, such as SAFE-CREATE,
+// are not checked.
+func (c *RelayClient) checkNonceFreshness(ctx context.Context, request *models.TransactionRequest) error {
+	if request.Type != string(models.SAFE) || request.Nonce == nil {
+		return nil
+	}
+	requestNonce, ok := new(big.Int).SetString(*request.Nonce, 10)
+	if !ok {
+		return errors.NewRelayerClientError("request nonce is not a valid integer: "+*request.Nonce, nil)
+	}
+
+	nonceAddress, err := c.requestNonceAddress(request)
+	if err != nil {
+		return err
+	}
+	nonceResp, err := c.getCachedNonce(ctx, nonceAddress, string(c.nonceSignerType), ReadOptions{SkipCache: true})
+	if err != nil {
+		return err
+	}
+	current, ok := new(big.Int).SetString(nonceResp.Nonce, 10)
+	if !ok {
+		return errors.ErrInvalidResponse("nonce is not a valid integer: " + nonceResp.Nonce)
+	}
+
+	delta := new(big.Int).Sub(current, requestNonce)
+	switch {
+	case delta.Sign() == 0:
+		return nil
+	case delta.Cmp(big.NewInt(-1)) == 0:
+		return errors.ErrNonceNotYetCurrent(requestNonce.String(), current.String())
+	default:
+		return errors.ErrStaleNonce(requestNonce.String(), current.String(), delta.Int64())
+	}
+}
+
+// requestNonceAddress returns the address Execute fetches the nonce of
+// request's Safe for: the signer for its derived Safe, the Safe itself for
+// any other (see ExecuteOnSafe)
+func (c *RelayClient) requestNonceAddress(request *models.TransactionRequest) (string, error) {
+	if !common.IsHexAddress(request.From) {
+		return "", errors.ErrInvalidAddress(request.From)
+	}
+	if !common.IsHexAddress(request.ProxyWallet) {
+		return "", errors.ErrInvalidAddress(request.ProxyWallet)
+	}
+
+	derived, err := c.deriveSafe(common.HexToAddress(request.From), c.currentContractConfig())
+	if err != nil {
+		return "", err
+	}
+	if strings.EqualFold(derived.Hex(), request.ProxyWallet) {
+		return request.From, nil
+	}
+	return request.ProxyWallet, nil
+}
@@ -0,0 +1,136 @@
+package client
+
+import (
+	stderrors "errors"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/davidt58/go-builder-relayer-client/builder"
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+// preSign signs a single-transaction request for safeAddress with nonce
+func preSign(t *testing.T, c *RelayClient, safeAddress string, nonce int64) *models.TransactionRequest {
+	t.Helper()
+	transfer := *models.NewSafeTransaction("0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174", "0", "0x")
+	signed, err := builder.PreSignBatch(safeAddress, big.NewInt(nonce), [][]models.SafeTransaction{{transfer}}, c.signer, 137, c.contractConfig.SafeMultisend)
+	if err != nil {
+		t.Fatalf("PreSignBatch failed: %v", err)
+	}
+	return signed[0].Request
+}
+
+func TestSubmitSignedRequest_NonceFreshness(t *testing.T) {
+	tests := []struct {
+		name          string
+		requestNonce  int64
+		relayerNonce  int64
+		wantStale     int64 // delta of the StaleNonceError; 0 for none
+		wantNotYet    bool
+		wantSubmitted bool
+	}{
+		{name: "current", requestNonce: 3, relayerNonce: 3, wantSubmitted: true},
+		{name: "stale", requestNonce: 1, relayerNonce: 3, wantStale: 2},
+		{name: "one ahead", requestNonce: 4, relayerNonce: 3, wantNotYet: true},
+		{name: "further ahead", requestNonce: 6, relayerNonce: 3, wantStale: -3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			relayer := newFakeRelayer(t)
+			relayer.SetNonce(tt.relayerNonce)
+			submits := countHits(relayer, SUBMIT_TRANSACTION)
+			c := newTestClient(t, relayer)
+			safeAddress, err := c.GetExpectedSafe()
+			if err != nil {
+				t.Fatalf("GetExpectedSafe failed: %v", err)
+			}
+
+			_, err = c.SubmitSignedRequest(preSign(t, c, safeAddress, tt.requestNonce))
+
+			var stale *errors.StaleNonceError
+			var notYet *errors.NonceNotYetCurrentError
+			switch {
+			case tt.wantStale != 0:
+				if !stderrors.As(err, &stale) || stale.Delta != tt.wantStale ||
+					stale.RequestNonce != big.NewInt(tt.requestNonce).String() || stale.CurrentNonce != big.NewInt(tt.relayerNonce).String() {
+					t.Fatalf("error = %v, want a StaleNonceError with delta %d", err, tt.wantStale)
+				}
+			case tt.wantNotYet:
+				if !stderrors.As(err, &notYet) || notYet.RequestNonce != "4" || notYet.CurrentNonce != "3" {
+					t.Fatalf("error = %v, want a NonceNotYetCurrentError", err)
+				}
+				if stderrors.As(err, &stale) {
+					t.Error("a request one ahead is reported as stale")
+				}
+			default:
+				if err != nil {
+					t.Fatalf("SubmitSignedRequest failed: %v", err)
+				}
+			}
+			if err != nil && errors.StageOf(err) != errors.StageNonce {
+				t.Errorf("stage = %q, want %q", errors.StageOf(err), errors.StageNonce)
+			}
+			if got := *submits == 1; got != tt.wantSubmitted {
+				t.Errorf("submitted = %v, want %v", got, tt.wantSubmitted)
+			}
+		})
+	}
+}
+
+func TestSubmitSignedRequest_SkipFreshnessCheck(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	relayer.SetNonce(3)
+	nonceHits := countHits(relayer, GET_NONCE)
+	c := newTestClient(t, relayer)
+	safeAddress, err := c.GetExpectedSafe()
+	if err != nil {
+		t.Fatalf("GetExpectedSafe failed: %v", err)
+	}
+
+	// The stale request reaches the relayer, which rejects it
+	_, err = c.SubmitSignedRequestWithOptions(preSign(t, c, safeAddress, 1), SubmitOptions{SkipFreshnessCheck: true})
+	var apiErr *errors.RelayerApiError
+	if !stderrors.As(err, &apiErr) || errors.StageOf(err) != errors.StageSubmit {
+		t.Fatalf("error = %v, want the relayer's rejection", err)
+	}
+	if *nonceHits != 0 {
+		t.Errorf("nonce fetched %d times, want 0", *nonceHits)
+	}
+}
+
+func TestSubmitSignedRequest_FreshnessNonceAddress(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	var mu sync.Mutex
+	var addresses []string
+	relayer.Handle(GET_NONCE, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		addresses = append(addresses, r.URL.Query().Get("address"))
+		mu.Unlock()
+		relayer.ServeDefault(w, r)
+	})
+	c := newTestClient(t, relayer)
+	derived, err := c.GetExpectedSafe()
+	if err != nil {
+		t.Fatalf("GetExpectedSafe failed: %v", err)
+	}
+
+	// The derived Safe's nonce is fetched for the signer, another Safe's
+	// for the Safe itself, as Execute and ExecuteOnSafe do
+	existing := "0x4444444444444444444444444444444444444444"
+	for _, safeAddress := range []string{derived, existing} {
+		if _, err := c.SubmitSignedRequest(preSign(t, c, safeAddress, relayer.Nonce())); err != nil {
+			t.Fatalf("SubmitSignedRequest to %s failed: %v", safeAddress, err)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(addresses) != 2 || !strings.EqualFold(addresses[0], c.signer.AddressHex()) || !strings.EqualFold(addresses[1], existing) {
+		t.Errorf("nonce fetched for %v, want the signer then %s", addresses, existing)
+	}
+}
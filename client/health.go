@@ -0,0 +1,171 @@
+package client
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/http"
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+const (
+	// defaultHealthTTL is how long the health gate caches the relayer status
+	defaultHealthTTL = 30 * time.Second
+
+	// healthWindowSize is the number of recent submissions used to infer health
+	healthWindowSize = 10
+	// healthMinSamples is the number of submissions needed before their
+	// error rate can mark the relayer unhealthy
+	healthMinSamples = 4
+)
+
+// WithHealthGate makes Deploy and Execute check the relayer status before
+// submitting and fail fast with a RelayerUnavailableError while it is
+// unhealthy or in maintenance. The status is cached for ttl (30s if ttl is
+// not positive). DeployOptions.ForceSubmit and ExecuteOptions.ForceSubmit
+// bypass the gate.
+func WithHealthGate(ttl time.Duration) Option {
+	return func(c *RelayClient) error {
+		if ttl <= 0 {
+			ttl = defaultHealthTTL
+		}
+		c.healthGate = true
+		c.healthTTL = ttl
+		return nil
+	}
+}
+
+// GetRelayerStatus queries the relayer's status endpoint. Relayers without
+// one are probed with a lightweight read, and health is inferred from the
+// probe and the error rate of recent submissions.
+func (c *RelayClient) GetRelayerStatus() (*models.RelayerStatus, error) {
+	return c.getRelayerStatus(context.Background())
+}
+
+// getRelayerStatus queries the relayer status, aborting when ctx is done
+func (c *RelayClient) getRelayerStatus(ctx context.Context) (*models.RelayerStatus, error) {
+	var status models.RelayerStatus
+	err := c.httpClient.GetJSONContext(ctx, GET_STATUS, nil, &status)
+	if err == nil {
+		if status.MaintenanceUntil != nil && status.MaintenanceUntil.After(c.clock.Now()) {
+			status.Healthy = false
+		}
+		return &status, nil
+	}
+	if errors.IsNotFound(err) {
+		return c.inferRelayerStatus(ctx)
+	}
+
+	var apiErr *errors.RelayerApiError
+	if stderrors.As(err, &apiErr) && apiErr.StatusCode == 503 {
+		return &models.RelayerStatus{Healthy: false, Message: apiErr.Message}, nil
+	}
+	return nil, err
+}
+
+// probeRequest is the cheap read used to check that the relayer responds: the
+// nonce of the zero address
+var probeRequest = getNonceRequest{signerAddress: common.Address{}.Hex(), signerType: string(models.SAFE_SIGNER)}
+
+// inferRelayerStatus probes the nonce endpoint and checks the error rate of
+// recent submissions
+func (c *RelayClient) inferRelayerStatus(ctx context.Context) (*models.RelayerStatus, error) {
+	status := &models.RelayerStatus{Healthy: true, Inferred: true}
+
+	var nonce models.NonceResponse
+	if err := c.getEndpoint(ctx, probeRequest, &nonce); err != nil {
+		if ctx.Err() != nil || !isServerError(err) {
+			return nil, err
+		}
+		status.Healthy = false
+		status.Message = fmt.Sprintf("probe failed: %v", err)
+		return status, nil
+	}
+
+	if failures, total := c.recentSubmitFailures(); total >= healthMinSamples && failures*2 >= total {
+		status.Healthy = false
+		status.Message = fmt.Sprintf("%d of the last %d submissions failed", failures, total)
+	}
+	return status, nil
+}
+
+// checkHealthGate returns a RelayerUnavailableError if the cached relayer
+// status is unhealthy or cannot be determined
+func (c *RelayClient) checkHealthGate(ctx context.Context) error {
+	status, err := c.cachedRelayerStatus(ctx)
+	if err != nil {
+		return errors.ErrRelayerUnavailable(nil, "", err)
+	}
+	if !status.Healthy {
+		return errors.ErrRelayerUnavailable(status.MaintenanceUntil, status.Message, nil)
+	}
+	return nil
+}
+
+// cachedRelayerStatus returns the relayer status, querying it at most once per TTL
+func (c *RelayClient) cachedRelayerStatus(ctx context.Context) (*models.RelayerStatus, error) {
+	c.healthMu.Lock()
+	if c.healthStatus != nil && c.clock.Now().Sub(c.healthCheckedAt) < c.healthTTL {
+		status := c.healthStatus
+		c.healthMu.Unlock()
+		return status, nil
+	}
+	c.healthMu.Unlock()
+
+	// The query runs unlocked: inferring health reads the submission window
+	status, err := c.getRelayerStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	c.healthMu.Lock()
+	c.healthStatus = status
+	c.healthCheckedAt = c.clock.Now()
+	c.healthMu.Unlock()
+	return status, nil
+}
+
+// recordSubmitOutcome adds a submission result to the health window. A
+// server-side failure also drops the cached status so the gate re-checks.
+func (c *RelayClient) recordSubmitOutcome(err error) {
+	failed := err != nil && isServerError(err)
+
+	c.healthMu.Lock()
+	defer c.healthMu.Unlock()
+
+	c.submitOutcomes = append(c.submitOutcomes, failed)
+	if len(c.submitOutcomes) > healthWindowSize {
+		c.submitOutcomes = c.submitOutcomes[len(c.submitOutcomes)-healthWindowSize:]
+	}
+	if failed {
+		c.healthStatus = nil
+	}
+}
+
+// recentSubmitFailures returns the failed and total submissions in the health window
+func (c *RelayClient) recentSubmitFailures() (failures, total int) {
+	c.healthMu.Lock()
+	defer c.healthMu.Unlock()
+
+	for _, failed := range c.submitOutcomes {
+		if failed {
+			failures++
+		}
+	}
+	return failures, len(c.submitOutcomes)
+}
+
+// isServerError reports whether err points at the relayer rather than the
+// request: a 5xx response or a retryable transport error
+func isServerError(err error) bool {
+	var apiErr *errors.RelayerApiError
+	if stderrors.As(err, &apiErr) {
+		return apiErr.StatusCode >= 500
+	}
+	return http.RetryableError(err)
+}
@@ -0,0 +1,249 @@
+package client
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+// serveStatus answers the status endpoint with the statuses in order,
+// repeating the last one, and returns the number of status requests
+func serveStatus(relayer *fakeRelayer, statuses ...func(w http.ResponseWriter)) func() int {
+	var mu sync.Mutex
+	calls := 0
+	relayer.Handle(GET_STATUS, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		i := calls
+		calls++
+		mu.Unlock()
+		if i >= len(statuses) {
+			i = len(statuses) - 1
+		}
+		statuses[i](w)
+	})
+	return func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return calls
+	}
+}
+
+func statusBody(status models.RelayerStatus) func(w http.ResponseWriter) {
+	return func(w http.ResponseWriter) {
+		json.NewEncoder(w).Encode(status)
+	}
+}
+
+func statusCode(code int) func(w http.ResponseWriter) {
+	return func(w http.ResponseWriter) {
+		w.WriteHeader(code)
+		json.NewEncoder(w).Encode(models.ErrorResponse{Error: "scheduled maintenance"})
+	}
+}
+
+func TestGetRelayerStatus(t *testing.T) {
+	future := time.Date(2026, 3, 1, 14, 0, 0, 0, time.UTC)
+	past := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		status       func(w http.ResponseWriter)
+		probeFails   bool
+		submitErrors int
+		wantHealthy  bool
+		wantInferred bool
+		wantMessage  string
+		shouldErr    bool
+	}{
+		{name: "healthy", status: statusBody(models.RelayerStatus{Healthy: true}), wantHealthy: true},
+		{name: "maintenance window ahead", status: statusBody(models.RelayerStatus{Healthy: true, MaintenanceUntil: &future, Message: "upgrade"}), wantMessage: "upgrade"},
+		{name: "maintenance window over", status: statusBody(models.RelayerStatus{Healthy: true, MaintenanceUntil: &past}), wantHealthy: true},
+		{name: "status 503", status: statusCode(http.StatusServiceUnavailable), wantMessage: "scheduled maintenance"},
+		{name: "status 500", status: statusCode(http.StatusInternalServerError), shouldErr: true},
+		{name: "no endpoint, probe healthy", wantHealthy: true, wantInferred: true},
+		{name: "no endpoint, probe failing", probeFails: true, wantInferred: true},
+		{name: "no endpoint, submissions failing", submitErrors: 3, wantInferred: true, wantMessage: "3 of the last 5 submissions failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			relayer := newFakeRelayer(t)
+			if tt.status != nil {
+				serveStatus(relayer, tt.status)
+			}
+			if tt.probeFails {
+				relayer.Handle(GET_NONCE, func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusBadGateway)
+				})
+			}
+			c := newTestClient(t, relayer)
+			for i := 0; i < 5; i++ {
+				var err error
+				if i < tt.submitErrors {
+					err = errors.NewRelayerApiError(http.StatusServiceUnavailable, "unavailable")
+				}
+				if tt.submitErrors > 0 {
+					c.recordSubmitOutcome(err)
+				}
+			}
+
+			status, err := c.GetRelayerStatus()
+			if tt.shouldErr {
+				if err == nil {
+					t.Fatal("Expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetRelayerStatus failed: %v", err)
+			}
+			if status.Healthy != tt.wantHealthy || status.Inferred != tt.wantInferred {
+				t.Errorf("status = %+v, want healthy %v inferred %v", status, tt.wantHealthy, tt.wantInferred)
+			}
+			if tt.wantMessage != "" && status.Message != tt.wantMessage {
+				t.Errorf("Message = %q, want %q", status.Message, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestHealthGate(t *testing.T) {
+	until := time.Date(2026, 3, 1, 14, 0, 0, 0, time.UTC)
+	maintenance := statusBody(models.RelayerStatus{Healthy: false, MaintenanceUntil: &until, Message: "database migration"})
+
+	tests := []struct {
+		name        string
+		gate        bool
+		status      func(w http.ResponseWriter)
+		force       bool
+		deploy      bool
+		wantBlocked bool
+	}{
+		{name: "healthy", gate: true, status: statusBody(models.RelayerStatus{Healthy: true})},
+		{name: "maintenance blocks execute", gate: true, status: maintenance, wantBlocked: true},
+		{name: "maintenance blocks deploy", gate: true, status: maintenance, deploy: true, wantBlocked: true},
+		{name: "force submit", gate: true, status: maintenance, force: true},
+		{name: "force deploy", gate: true, status: maintenance, force: true, deploy: true},
+		{name: "gate disabled", status: maintenance},
+		{name: "no status endpoint", gate: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			relayer := newFakeRelayer(t)
+			relayer.SetDeployed(!tt.deploy)
+			statusCalls := func() int { return 0 }
+			if tt.status != nil {
+				statusCalls = serveStatus(relayer, tt.status)
+			}
+			c := newTestClient(t, relayer)
+			if tt.gate {
+				if err := WithHealthGate(0)(c); err != nil {
+					t.Fatalf("WithHealthGate failed: %v", err)
+				}
+			}
+
+			var err error
+			if tt.deploy {
+				_, err = c.DeployWithOptions(DeployOptions{ForceSubmit: tt.force})
+			} else {
+				_, err = c.ExecuteWithOptions(testTransactions(), "", ExecuteOptions{ForceSubmit: tt.force})
+			}
+
+			if !tt.wantBlocked {
+				if err != nil {
+					t.Fatalf("submission failed: %v", err)
+				}
+				if len(relayer.Submissions()) != 1 {
+					t.Errorf("Expected 1 submission, got %d", len(relayer.Submissions()))
+				}
+				if (!tt.gate || tt.force) && statusCalls() != 0 {
+					t.Errorf("status queried %d times with the gate off", statusCalls())
+				}
+				return
+			}
+
+			var unavailable *errors.RelayerUnavailableError
+			if !stderrors.As(err, &unavailable) {
+				t.Fatalf("error = %v, want RelayerUnavailableError", err)
+			}
+			if unavailable.MaintenanceUntil == nil || !unavailable.MaintenanceUntil.Equal(until) {
+				t.Errorf("MaintenanceUntil = %v, want %v", unavailable.MaintenanceUntil, until)
+			}
+			if unavailable.Message != "database migration" {
+				t.Errorf("Message = %q", unavailable.Message)
+			}
+			var staged *errors.StagedError
+			if !stderrors.As(err, &staged) || staged.Stage != errors.StageHealthCheck {
+				t.Errorf("error = %v, want stage %s", err, errors.StageHealthCheck)
+			}
+			if len(relayer.Submissions()) != 0 {
+				t.Errorf("Expected nothing submitted, got %d", len(relayer.Submissions()))
+			}
+		})
+	}
+}
+
+func TestHealthGate_Flapping(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	healthy := statusBody(models.RelayerStatus{Healthy: true})
+	down := statusBody(models.RelayerStatus{Healthy: false, Message: "degraded"})
+	statusCalls := serveStatus(relayer, healthy, down, healthy, healthy)
+
+	c := newTestClient(t, relayer)
+	advance := testClock(c).Advance
+	if err := WithHealthGate(30 * time.Second)(c); err != nil {
+		t.Fatalf("WithHealthGate failed: %v", err)
+	}
+	execute := func() error {
+		_, err := c.Execute(testTransactions(), "")
+		return err
+	}
+
+	steps := []struct {
+		name        string
+		advance     time.Duration
+		wantBlocked bool
+		wantCalls   int
+	}{
+		{name: "healthy", wantCalls: 1},
+		{name: "cached within TTL", advance: 10 * time.Second, wantCalls: 1},
+		{name: "down after TTL", advance: 25 * time.Second, wantBlocked: true, wantCalls: 2},
+		{name: "still cached as down", advance: 5 * time.Second, wantBlocked: true, wantCalls: 2},
+		{name: "recovered", advance: 30 * time.Second, wantCalls: 3},
+	}
+
+	for _, step := range steps {
+		advance(step.advance)
+		err := execute()
+		var unavailable *errors.RelayerUnavailableError
+		if blocked := stderrors.As(err, &unavailable); blocked != step.wantBlocked {
+			t.Fatalf("%s: err = %v, want blocked %v", step.name, err, step.wantBlocked)
+		}
+		if !step.wantBlocked && err != nil {
+			t.Fatalf("%s: Execute failed: %v", step.name, err)
+		}
+		if got := statusCalls(); got != step.wantCalls {
+			t.Errorf("%s: status calls = %d, want %d", step.name, got, step.wantCalls)
+		}
+	}
+
+	// A server-side submit failure drops the cached status immediately
+	relayer.Handle(SUBMIT_TRANSACTION, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(models.ErrorResponse{Error: "unavailable"})
+	})
+	if err := execute(); err == nil {
+		t.Fatal("Expected submit to fail")
+	}
+	execute()
+	if got := statusCalls(); got != 4 {
+		t.Errorf("status calls after submit failure = %d, want 4", got)
+	}
+}
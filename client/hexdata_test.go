@@ -0,0 +1,99 @@
+package client
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/davidt58/go-builder-relayer-client/builder"
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+func TestExecute_NormalizesHexData(t *testing.T) {
+	const to = "0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174"
+	transfer := "a9059cbb0000000000000000000000004d97dcd97ec945f40cf65f87097ace5ea04760450000000000000000000000000000000000000000000000000000000000000064"
+	upper := "A9059CBB0000000000000000000000004D97DCD97EC945F40CF65F87097ACE5EA04760450000000000000000000000000000000000000000000000000000000000000064"
+
+	tests := []struct {
+		name     string
+		datas    []string
+		wantData string
+	}{
+		{name: "unprefixed", datas: []string{transfer}, wantData: `"0x` + transfer + `"`},
+		{name: "uppercase", datas: []string{"0x" + upper}, wantData: `"0x` + transfer + `"`},
+		{name: "uppercase prefix", datas: []string{"0X" + upper}, wantData: `"0x` + transfer + `"`},
+		{name: "empty", datas: []string{""}, wantData: `"0x"`},
+		// A batch is signed as a multiSend call carrying the normalized calls
+		{name: "batch", datas: []string{upper, "", "0X"}},
+	}
+
+	// submit executes datas and returns the submitted request and its hash
+	submit := func(t *testing.T, datas []string) (models.TransactionRequest, string) {
+		t.Helper()
+		relayer := newFakeRelayer(t)
+		c := newTestClient(t, relayer)
+		txs := make([]models.SafeTransaction, len(datas))
+		for i, data := range datas {
+			txs[i] = *models.NewSafeTransaction(to, "0", data)
+		}
+		if _, err := c.Execute(txs, ""); err != nil {
+			t.Fatalf("Execute failed: %v", err)
+		}
+		submitted := relayer.Submissions()
+		if len(submitted) != 1 {
+			t.Fatalf("got %d submissions, want 1", len(submitted))
+		}
+		request := submitted[0]
+		hash, err := builder.SafeRequestHash(&request, 137, "")
+		if err != nil {
+			t.Fatalf("SafeRequestHash failed: %v", err)
+		}
+		recovered, err := builder.RecoverRequestSigner(&request, hash)
+		if err != nil {
+			t.Fatalf("RecoverRequestSigner failed: %v", err)
+		}
+		if recovered != c.signer.Address() {
+			t.Errorf("signature recovers %s, want the signer %s", recovered.Hex(), c.signer.AddressHex())
+		}
+		return request, hash.Hex()
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, hash := submit(t, tt.datas)
+			data := string(request.Data)
+			if !strings.HasPrefix(data, `"0x`) || data != strings.ToLower(data) {
+				t.Errorf("data = %s, want lowercase 0x-prefixed hex", data)
+			}
+			if tt.wantData != "" && data != tt.wantData {
+				t.Errorf("data = %s, want %s", data, tt.wantData)
+			}
+			if tt.wantData == "" && !strings.Contains(data, transfer) {
+				t.Errorf("data = %s, want it to carry %s", data, transfer)
+			}
+
+			normalized := make([]string, len(tt.datas))
+			for i, data := range tt.datas {
+				var err error
+				if normalized[i], err = models.NormalizeHexData(data); err != nil {
+					t.Fatalf("NormalizeHexData(%q) failed: %v", data, err)
+				}
+			}
+			if _, want := submit(t, normalized); hash != want {
+				t.Errorf("hash = %s, want %s as for normalized data", hash, want)
+			}
+
+			var payload map[string]json.RawMessage
+			body, err := json.Marshal(request)
+			if err != nil {
+				t.Fatalf("Marshal failed: %v", err)
+			}
+			if err := json.Unmarshal(body, &payload); err != nil {
+				t.Fatalf("Unmarshal failed: %v", err)
+			}
+			if string(payload["data"]) != data {
+				t.Errorf("serialized data = %s, want %s", payload["data"], data)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	stderrors "errors"
+	"io"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+// payoutTransactions returns n distinct single transfers
+func payoutTransactions(n int) []models.SafeTransaction {
+	transactions := make([]models.SafeTransaction, n)
+	for i := range transactions {
+		transactions[i] = *models.NewSafeTransaction("0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174", strconv.Itoa(i+1), "0x")
+	}
+	return transactions
+}
+
+// failNonceOnce makes the fake relayer reject the first submission using nonce
+func failNonceOnce(relayer *fakeRelayer, nonce string) {
+	failed := false
+	relayer.Handle(SUBMIT_TRANSACTION, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var request models.TransactionRequest
+		json.Unmarshal(body, &request)
+
+		if !failed && request.Nonce != nil && *request.Nonce == nonce {
+			failed = true
+			w.WriteHeader(http.StatusServiceUnavailable)
+			json.NewEncoder(w).Encode(models.ErrorResponse{Error: "temporarily unavailable"})
+			return
+		}
+
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		relayer.ServeDefault(w, r)
+	})
+}
+
+func TestExecuteIndependent(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	c := newTestClient(t, relayer)
+
+	responses, err := c.ExecuteIndependent(payoutTransactions(3), "payout")
+	if err != nil {
+		t.Fatalf("ExecuteIndependent failed: %v", err)
+	}
+	if len(responses) != 3 {
+		t.Fatalf("got %d responses, want 3", len(responses))
+	}
+	for i, response := range responses {
+		if want := "tx-nonce-" + strconv.Itoa(i); response.TransactionID != want {
+			t.Errorf("responses[%d] = %s, want %s", i, response.TransactionID, want)
+		}
+	}
+
+	for i, request := range relayer.Submissions() {
+		if string(request.To) != `"0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174"` {
+			t.Errorf("submission %d was not sent as an independent transaction: to=%s", i, request.To)
+		}
+	}
+}
+
+func TestExecuteIndependent_PartialFailureAndResume(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	failNonceOnce(relayer, "2")
+	c := newTestClient(t, relayer)
+
+	transactions := payoutTransactions(5)
+	responses, err := c.ExecuteIndependent(transactions, "payout")
+
+	var partial *errors.PartialExecutionError
+	if !stderrors.As(err, &partial) {
+		t.Fatalf("error = %v, want PartialExecutionError", err)
+	}
+	if partial.Total != 5 || partial.FailedIndex != 2 || partial.FailedNonce != "2" {
+		t.Errorf("partial = {Total:%d FailedIndex:%d FailedNonce:%s}, want {5 2 2}", partial.Total, partial.FailedIndex, partial.FailedNonce)
+	}
+	if len(responses) != 2 {
+		t.Fatalf("got %d responses before the failure, want 2", len(responses))
+	}
+	if len(relayer.Submissions()) != 2 {
+		t.Errorf("relayer accepted %d submissions, want 2 (sequence must stop at the gap)", len(relayer.Submissions()))
+	}
+
+	// Resume from the gap
+	resumed, err := c.ExecuteIndependent(transactions[partial.FailedIndex:], "payout")
+	if err != nil {
+		t.Fatalf("resume failed: %v", err)
+	}
+	for i, response := range resumed {
+		if want := "tx-nonce-" + strconv.Itoa(partial.FailedIndex+i); response.TransactionID != want {
+			t.Errorf("resumed[%d] = %s, want %s", i, response.TransactionID, want)
+		}
+	}
+
+	submitted := relayer.Submissions()
+	if len(submitted) != 5 {
+		t.Fatalf("relayer accepted %d submissions, want 5", len(submitted))
+	}
+	for i, request := range submitted {
+		if *request.Nonce != strconv.Itoa(i) {
+			t.Errorf("submission %d used nonce %s, want %d", i, *request.Nonce, i)
+		}
+	}
+}
+
+func TestExecuteIndependent_InterleavedWithQueue(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	c := newTestClient(t, relayer)
+
+	before, err := c.EnqueueExecute(testTransactions(), "")
+	if err != nil {
+		t.Fatalf("EnqueueExecute failed: %v", err)
+	}
+	responses, err := c.ExecuteIndependent(payoutTransactions(3), "")
+	if err != nil {
+		t.Fatalf("ExecuteIndependent failed: %v", err)
+	}
+	after, err := c.EnqueueExecute(testTransactions(), "")
+	if err != nil {
+		t.Fatalf("EnqueueExecute failed: %v", err)
+	}
+
+	if before.Nonce() != "0" || after.Nonce() != "4" {
+		t.Errorf("queued nonces = %s, %s, want 0, 4", before.Nonce(), after.Nonce())
+	}
+	if len(responses) != 3 || responses[0].TransactionID != "tx-nonce-1" || responses[2].TransactionID != "tx-nonce-3" {
+		t.Errorf("independent sequence was not contiguous: %v", responses)
+	}
+}
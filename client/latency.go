@@ -0,0 +1,107 @@
+package client
+
+import (
+	"time"
+
+	"github.com/davidt58/go-builder-relayer-client/config"
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+// maxLatencyResponses bounds the responses tracked for LatencyBreakdown;
+// beyond it the earliest submitted is dropped, so responses that are never
+// waited for do not accumulate
+const maxLatencyResponses = 1024
+
+// MetricsCollector receives the client's measurements, e.g. to feed
+// histograms labelled by phase
+type MetricsCollector interface {
+	// ObserveLatency records how long a confirmed transaction of txType
+	// spent in phase. Only known phases are reported.
+	ObserveLatency(phase models.LatencyPhase, txType models.TransactionType, d time.Duration)
+}
+
+// EnvironmentMetricsCollector is a MetricsCollector that also takes the
+// client's Environment as a label, so dashboards can split traffic by
+// environment. Collectors implementing it are called with
+// ObserveEnvironmentLatency instead of ObserveLatency.
+type EnvironmentMetricsCollector interface {
+	MetricsCollector
+	ObserveEnvironmentLatency(env config.Environment, phase models.LatencyPhase, txType models.TransactionType, d time.Duration)
+}
+
+// WithMetricsCollector reports the LatencyBreakdown of every transaction a
+// poll sees confirmed to collector
+func WithMetricsCollector(collector MetricsCollector) Option {
+	return func(c *RelayClient) error {
+		if collector == nil {
+			return errors.ErrMissingRequiredField("collector")
+		}
+		c.metrics = collector
+		return nil
+	}
+}
+
+// newSubmitOperation is newOperation for an operation that submits a
+// transaction, timing its start for the response's LatencyBreakdown
+func (c *RelayClient) newSubmitOperation(name string, timeout time.Duration) *operation {
+	op := newOperation(name, timeout)
+	op.started = c.clock.Now()
+	return op
+}
+
+// stamp records the operation's start and signing times on its response
+func (o *operation) stamp(response *models.ClientRelayerTransactionResponse) {
+	response.StartedAt = o.started
+	response.SignedAt = o.signed
+}
+
+// trackLatency records the states polls see for response's transaction
+func (c *RelayClient) trackLatency(response *models.ClientRelayerTransactionResponse) {
+	c.latencyMu.Lock()
+	defer c.latencyMu.Unlock()
+
+	if c.latencyResponses == nil {
+		c.latencyResponses = make(map[string]*models.ClientRelayerTransactionResponse)
+	}
+	if len(c.latencyResponses) >= maxLatencyResponses {
+		var earliest *models.ClientRelayerTransactionResponse
+		for _, tracked := range c.latencyResponses {
+			if earliest == nil || tracked.SubmittedAt.Before(earliest.SubmittedAt) {
+				earliest = tracked
+			}
+		}
+		delete(c.latencyResponses, earliest.TransactionID)
+	}
+	c.latencyResponses[response.TransactionID] = response
+}
+
+// observeLatency records a polled snapshot on its tracked response. A
+// terminal state ends the tracking; a confirmation reports the breakdown
+// to the metrics collector.
+func (c *RelayClient) observeLatency(txn *models.RelayerTransaction) {
+	c.latencyMu.Lock()
+	response, ok := c.latencyResponses[txn.TransactionID]
+	if ok && txn.State.IsTerminal() {
+		delete(c.latencyResponses, txn.TransactionID)
+	}
+	c.latencyMu.Unlock()
+	if !ok {
+		return
+	}
+
+	response.RecordState(txn)
+	if txn.State != models.STATE_CONFIRMED || c.metrics == nil {
+		return
+	}
+	labelled, _ := c.metrics.(EnvironmentMetricsCollector)
+	for _, phase := range response.LatencyBreakdown().Phases() {
+		switch {
+		case !phase.Known:
+		case labelled != nil:
+			labelled.ObserveEnvironmentLatency(c.environment, phase.Phase, response.Type, phase.Duration)
+		default:
+			c.metrics.ObserveLatency(phase.Phase, response.Type, phase.Duration)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/davidt58/go-builder-relayer-client/models"
+	"github.com/davidt58/go-builder-relayer-client/relayertest"
+)
+
+// relayerEpoch is the relayer's clock at submission, deliberately apart
+// from the client's testClockStart
+var relayerEpoch = time.Date(2026, 3, 1, 11, 59, 58, 0, time.UTC)
+
+// latencyCollector records ObserveLatency calls
+type latencyCollector struct {
+	mu       sync.Mutex
+	observed map[models.LatencyPhase]time.Duration
+	txTypes  []models.TransactionType
+}
+
+func (l *latencyCollector) ObserveLatency(phase models.LatencyPhase, txType models.TransactionType, d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.observed == nil {
+		l.observed = make(map[models.LatencyPhase]time.Duration)
+	}
+	l.observed[phase] = d
+	l.txTypes = append(l.txTypes, txType)
+}
+
+// snapshot is a scripted transaction state entered at an offset from relayerEpoch
+type snapshot struct {
+	state models.RelayerTransactionState
+	at    time.Duration
+}
+
+// scriptTimedStates makes the transaction endpoint report snapshots in
+// order, one per poll, with createdAt at relayerEpoch and updatedAt when
+// the state was entered; perState also reports stateTimestamps
+func scriptTimedStates(f *fakeRelayer, perState bool, snapshots ...snapshot) {
+	var mu sync.Mutex
+	poll := 0
+	stamp := func(d time.Duration) string { return relayerEpoch.Add(d).Format(time.RFC3339Nano) }
+	f.Handle(GET_TRANSACTION, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		current := snapshots[len(snapshots)-1]
+		if poll < len(snapshots) {
+			current = snapshots[poll]
+		}
+		poll++
+		mu.Unlock()
+
+		txn := models.RelayerTransaction{
+			TransactionID: r.URL.Query().Get("id"),
+			State:         current.state,
+			CreatedAt:     stamp(0),
+			UpdatedAt:     stamp(current.at),
+		}
+		if perState {
+			txn.StateTimestamps = map[models.RelayerTransactionState]string{}
+			for _, s := range snapshots {
+				if s.at <= current.at {
+					txn.StateTimestamps[s.state] = stamp(s.at)
+				}
+			}
+		}
+		json.NewEncoder(w).Encode([]models.RelayerTransaction{txn})
+	})
+}
+
+// newTimedClient is newTestClient whose relayer takes 200ms to answer the
+// nonce request and 300ms to accept a submission, by the client's clock
+func newTimedClient(t *testing.T, relayer *fakeRelayer, opts ...Option) *RelayClient {
+	t.Helper()
+	var c *RelayClient
+	relayer.Handle(GET_NONCE, func(w http.ResponseWriter, r *http.Request) {
+		testClock(c).Advance(200 * time.Millisecond)
+		relayer.ServeDefault(w, r)
+	})
+	relayer.Handle(SUBMIT_TRANSACTION, func(w http.ResponseWriter, r *http.Request) {
+		testClock(c).Advance(300 * time.Millisecond)
+		relayer.ServeDefault(w, r)
+	})
+	c = newLoggingTestClient(t, relayer, new(bytes.Buffer), opts...)
+	return c
+}
+
+// assertPhase fails unless phase has the given duration, or is unknown when want is negative
+func assertPhase(t *testing.T, phase models.PhaseLatency, want time.Duration) {
+	t.Helper()
+	if want < 0 {
+		if phase.Known || phase.Duration != 0 {
+			t.Errorf("%s = %v (known %v), want unknown", phase.Phase, phase.Duration, phase.Known)
+		}
+		return
+	}
+	if !phase.Known || phase.Duration != want {
+		t.Errorf("%s = %v (known %v), want %v", phase.Phase, phase.Duration, phase.Known, want)
+	}
+}
+
+func TestLatencyBreakdown_AllPhases(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	scriptTimedStates(relayer, false,
+		snapshot{models.STATE_NEW, 0},
+		snapshot{models.STATE_EXECUTED, 4 * time.Second},
+		snapshot{models.STATE_MINED, 16 * time.Second},
+		snapshot{models.STATE_CONFIRMED, 46 * time.Second},
+	)
+	collector := &latencyCollector{}
+	c := newTimedClient(t, relayer, WithMetricsCollector(collector))
+
+	response, err := c.Execute(relayertest.SampleApprovalBatch(), "")
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if !response.StartedAt.Equal(testClockStart) || response.SignedAt.Sub(response.StartedAt) != 200*time.Millisecond {
+		t.Errorf("started %v, signed %v", response.StartedAt, response.SignedAt)
+	}
+
+	// Nothing relayer-side is known before a wait
+	breakdown := response.LatencyBreakdown()
+	assertPhase(t, breakdown.Build, 200*time.Millisecond)
+	assertPhase(t, breakdown.Submit, 300*time.Millisecond)
+	assertPhase(t, breakdown.Queue, -1)
+	assertPhase(t, breakdown.Total, -1)
+
+	if _, err := response.Wait(); err != nil {
+		t.Fatalf("Wait failed: %v", err)
+	}
+	breakdown = response.LatencyBreakdown()
+	assertPhase(t, breakdown.Build, 200*time.Millisecond)
+	assertPhase(t, breakdown.Submit, 300*time.Millisecond)
+	assertPhase(t, breakdown.Queue, 4*time.Second)
+	assertPhase(t, breakdown.Mining, 12*time.Second)
+	assertPhase(t, breakdown.Confirmation, 30*time.Second)
+	assertPhase(t, breakdown.Total, 46500*time.Millisecond)
+
+	want := map[models.LatencyPhase]time.Duration{
+		models.PhaseBuild:        200 * time.Millisecond,
+		models.PhaseSubmit:       300 * time.Millisecond,
+		models.PhaseQueue:        4 * time.Second,
+		models.PhaseMining:       12 * time.Second,
+		models.PhaseConfirmation: 30 * time.Second,
+		models.PhaseTotal:        46500 * time.Millisecond,
+	}
+	if len(collector.observed) != len(want) {
+		t.Fatalf("collector observed %v, want %v", collector.observed, want)
+	}
+	for phase, d := range want {
+		if collector.observed[phase] != d {
+			t.Errorf("collector %s = %v, want %v", phase, collector.observed[phase], d)
+		}
+	}
+	for _, txType := range collector.txTypes {
+		if txType != models.SAFE {
+			t.Errorf("collector label %s, want SAFE", txType)
+		}
+	}
+}
+
+func TestLatencyBreakdown_SkippedStates(t *testing.T) {
+	for _, perState := range []bool{false, true} {
+		relayer := newFakeRelayer(t)
+		scriptTimedStates(relayer, perState,
+			snapshot{models.STATE_NEW, 0},
+			snapshot{models.STATE_EXECUTED, 3 * time.Second},
+			snapshot{models.STATE_MINED, 5 * time.Second},
+			snapshot{models.STATE_CONFIRMED, 9 * time.Second},
+		)
+		c := newTimedClient(t, relayer)
+
+		response, err := c.Execute(relayertest.SampleApprovalBatch(), "")
+		if err != nil {
+			t.Fatalf("Execute failed: %v", err)
+		}
+		// Reads outside a wait are not recorded, so the wait only sees
+		// STATE_CONFIRMED
+		for i := 0; i < 3; i++ {
+			if _, err := c.GetTransaction(response.TransactionID); err != nil {
+				t.Fatalf("GetTransaction failed: %v", err)
+			}
+		}
+		if _, err := response.Wait(); err != nil {
+			t.Fatalf("Wait failed: %v", err)
+		}
+
+		breakdown := response.LatencyBreakdown()
+		if perState {
+			assertPhase(t, breakdown.Queue, 3*time.Second)
+			assertPhase(t, breakdown.Mining, 2*time.Second)
+			assertPhase(t, breakdown.Confirmation, 4*time.Second)
+			assertPhase(t, breakdown.Total, 9500*time.Millisecond)
+		} else {
+			assertPhase(t, breakdown.Queue, -1)
+			assertPhase(t, breakdown.Mining, -1)
+			assertPhase(t, breakdown.Confirmation, -1)
+			assertPhase(t, breakdown.Total, -1)
+		}
+	}
+}
+
+func TestLatencyBreakdown_Reattached(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	scriptTimedStates(relayer, false,
+		snapshot{models.STATE_EXECUTED, 2 * time.Second},
+		snapshot{models.STATE_MINED, 7 * time.Second},
+	)
+	response, err := newTimedClient(t, relayer).Execute(relayertest.SampleApprovalBatch(), "")
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	doc, err := json.Marshal(response)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	attached, err := newTestClient(t, relayer).AttachResponse(doc)
+	if err != nil {
+		t.Fatalf("AttachResponse failed: %v", err)
+	}
+	if _, err := attached.WaitUntilMined(); err != nil {
+		t.Fatalf("WaitUntilMined failed: %v", err)
+	}
+	breakdown := attached.LatencyBreakdown()
+	assertPhase(t, breakdown.Build, 200*time.Millisecond)
+	assertPhase(t, breakdown.Submit, 300*time.Millisecond)
+	assertPhase(t, breakdown.Queue, 2*time.Second)
+	assertPhase(t, breakdown.Mining, 5*time.Second)
+	assertPhase(t, breakdown.Confirmation, -1)
+}
@@ -0,0 +1,125 @@
+package client
+
+import (
+	"context"
+	"sync"
+
+	"github.com/davidt58/go-builder-relayer-client/errors"
+)
+
+// component is a background part of the client that Close stops
+type component struct {
+	name string
+	stop func(ctx context.Context) error
+}
+
+// lifecycle tracks the client's background components and in-flight
+// submissions so Close can stop them. Components register themselves when
+// they start; none can start once Close has begun, and no submission once
+// the components are stopped.
+type lifecycle struct {
+	mu         sync.Mutex
+	closing    bool
+	closed     bool
+	components []component
+	inFlight   sync.WaitGroup
+}
+
+// register adds a component stopped by Close, or returns ErrClientClosed
+func (l *lifecycle) register(name string, stop func(ctx context.Context) error) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closing {
+		return errors.ErrClientClosed
+	}
+	l.components = append(l.components, component{name: name, stop: stop})
+	return nil
+}
+
+// err returns ErrClientClosed once Close has begun
+func (l *lifecycle) err() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closing {
+		return errors.ErrClientClosed
+	}
+	return nil
+}
+
+// begin marks a submission in flight until the returned func is called, or
+// returns ErrClientClosed
+func (l *lifecycle) begin() (func(), error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return nil, errors.ErrClientClosed
+	}
+	l.inFlight.Add(1)
+	return l.inFlight.Done, nil
+}
+
+// stopComponents stops the registered components, newest first, and returns
+// the first error. It reports false if Close had already begun.
+func (l *lifecycle) stopComponents(ctx context.Context) (bool, error) {
+	l.mu.Lock()
+	if l.closing {
+		l.mu.Unlock()
+		return false, nil
+	}
+	l.closing = true
+	components := l.components
+	l.components = nil
+	l.mu.Unlock()
+
+	var first error
+	for i := len(components) - 1; i >= 0; i-- {
+		if err := components[i].stop(ctx); err != nil && first == nil {
+			first = errors.NewRelayerClientError("stopping "+components[i].name, err)
+		}
+	}
+	return true, first
+}
+
+// close refuses new submissions and waits for those in flight, or for ctx
+func (l *lifecycle) close(ctx context.Context) error {
+	l.mu.Lock()
+	l.closed = true
+	l.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		l.inFlight.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close shuts the client down: it stops the background components (the
+// submission queue drains what was enqueued), waits for submissions in
+// flight to finish recording, closes the idle connections to the relayer
+// and flushes the submission store if it implements SubmissionFlusher.
+// Waiting stops when ctx is done, returning ctx.Err() once the rest is
+// closed. Afterwards every request fails with errors.ErrClientClosed.
+// Closing a closed client does nothing.
+func (c *RelayClient) Close(ctx context.Context) error {
+	open, err := c.lifecycle.stopComponents(ctx)
+	if !open {
+		return nil
+	}
+	if closeErr := c.lifecycle.close(ctx); err == nil {
+		err = closeErr
+	}
+	c.httpClient.Close()
+
+	if flusher, ok := c.submissionStore.(SubmissionFlusher); ok {
+		if flushErr := flusher.FlushSubmissions(); flushErr != nil && err == nil {
+			err = errors.ErrSubmissionStoreFailed("flush", flushErr)
+		}
+	}
+	return err
+}
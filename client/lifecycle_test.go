@@ -0,0 +1,159 @@
+package client
+
+import (
+	"context"
+	stderrors "errors"
+	"net/http"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+// flushingStore counts FlushSubmissions calls
+type flushingStore struct {
+	SubmissionStore
+	flushes int
+}
+
+func (s *flushingStore) FlushSubmissions() error {
+	s.flushes++
+	return nil
+}
+
+// waitForGoroutines waits for the goroutine count to fall to want
+func waitForGoroutines(t *testing.T, want int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		got := runtime.NumGoroutine()
+		if got <= want {
+			return
+		}
+		if time.Now().After(deadline) {
+			buf := make([]byte, 1<<16)
+			t.Fatalf("%d goroutines after Close, want at most %d:\n%s", got, want, buf[:runtime.Stack(buf, true)])
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestClose_NoGoroutineLeak(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	before := runtime.NumGoroutine()
+
+	c := newTestClient(t, relayer)
+	if _, err := c.Execute(testTransactions(), ""); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	handle, err := c.EnqueueExecute(testTransactions(), "")
+	if err != nil {
+		t.Fatalf("EnqueueExecute failed: %v", err)
+	}
+	if _, err := handle.Result(); err != nil {
+		t.Fatalf("queued submission failed: %v", err)
+	}
+
+	if err := c.Close(context.Background()); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	waitForGoroutines(t, before)
+
+	if err := c.Close(context.Background()); err != nil {
+		t.Errorf("second Close = %v, want nil", err)
+	}
+}
+
+func TestClose_RejectsCalls(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	store := &flushingStore{}
+	c := newStoreClient(t, relayer, filepath.Join(t.TempDir(), "submissions.jsonl"), func(s SubmissionStore) SubmissionStore {
+		store.SubmissionStore = s
+		return store
+	})
+	if err := c.Close(context.Background()); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if store.flushes != 1 {
+		t.Errorf("store flushed %d times, want 1", store.flushes)
+	}
+
+	calls := []struct {
+		name string
+		call func() error
+	}{
+		{name: "Execute", call: func() error { _, err := c.Execute(testTransactions(), ""); return err }},
+		{name: "EnqueueExecute", call: func() error { _, err := c.EnqueueExecute(testTransactions(), ""); return err }},
+		{name: "GetTransaction", call: func() error { _, err := c.GetTransaction("tx-1"); return err }},
+		{name: "GetNonce", call: func() error { _, err := c.GetNonce(c.signer.AddressHex(), string(models.SAFE_SIGNER)); return err }},
+	}
+	for _, tt := range calls {
+		if err := tt.call(); !stderrors.Is(err, errors.ErrClientClosed) {
+			t.Errorf("%s after Close = %v, want ErrClientClosed", tt.name, err)
+		}
+	}
+	if n := len(relayer.Submissions()); n != 0 {
+		t.Errorf("got %d submissions after Close, want none", n)
+	}
+}
+
+func TestClose_WaitsForInFlightSubmissions(t *testing.T) {
+	tests := []struct {
+		name    string
+		timeout time.Duration
+		wantErr error
+	}{
+		{name: "submission finishes", timeout: 5 * time.Second},
+		{name: "deadline first", timeout: 50 * time.Millisecond, wantErr: context.DeadlineExceeded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			relayer := newFakeRelayer(t)
+			arrived, release := make(chan struct{}), make(chan struct{})
+			relayer.Handle(SUBMIT_TRANSACTION, func(w http.ResponseWriter, r *http.Request) {
+				close(arrived)
+				<-release
+				relayer.ServeDefault(w, r)
+			})
+			c := newTestClient(t, relayer)
+
+			executed := make(chan error, 1)
+			go func() {
+				_, err := c.Execute(testTransactions(), "")
+				executed <- err
+			}()
+			<-arrived
+
+			ctx, cancel := context.WithTimeout(context.Background(), tt.timeout)
+			defer cancel()
+			closed := make(chan error, 1)
+			go func() { closed <- c.Close(ctx) }()
+
+			if tt.wantErr == nil {
+				select {
+				case err := <-closed:
+					t.Fatalf("Close returned %v with a submission in flight", err)
+				case <-time.After(50 * time.Millisecond):
+				}
+				close(release)
+			}
+			if err := <-closed; !stderrors.Is(err, tt.wantErr) {
+				t.Errorf("Close = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				close(release)
+			}
+
+			if err := <-executed; err != nil {
+				t.Errorf("in-flight Execute failed: %v", err)
+			}
+			if n := len(relayer.Submissions()); n != 1 {
+				t.Errorf("got %d submissions, want 1", n)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package client
+
+import (
+	"strings"
+
+	"github.com/davidt58/go-builder-relayer-client/builder"
+	"github.com/davidt58/go-builder-relayer-client/config"
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+// WithRelayerLimits replaces the request size limits checked before
+// submission (default config.DefaultRelayerLimits), e.g. for a stricter
+// staging relayer. Zero fields disable the corresponding check.
+func WithRelayerLimits(limits config.RelayerLimits) Option {
+	return func(c *RelayClient) error {
+		if err := limits.Validate(); err != nil {
+			return err
+		}
+		c.limits = limits
+		return nil
+	}
+}
+
+// CurrentLimits returns the request size limits the client enforces
+func (c *RelayClient) CurrentLimits() config.RelayerLimits {
+	return c.limits
+}
+
+// checkExecuteLimits returns a LimitExceededError if a SAFE request for
+// transactions, batched through variant, would exceed the relayer limits
+func (c *RelayClient) checkExecuteLimits(transactions []models.SafeTransaction, metadata *string, variant config.MultisendVariant) error {
+	if err := c.limits.CheckBatchCount(len(transactions)); err != nil {
+		return err
+	}
+	if metadata != nil {
+		if err := c.limits.CheckMetadata(*metadata); err != nil {
+			return err
+		}
+	}
+	if c.limits.MaxCalldataBytes == 0 {
+		return nil
+	}
+
+	// Size the data that will be signed: the transaction itself, or the
+	// multiSend call batching them. Encoding errors surface in the build step.
+	multisendAddress := ""
+	if len(transactions) > 1 || variant.OrDefault() == config.MultisendCallOnly {
+		address, err := c.currentContractConfig().MultisendAddress(variant)
+		if err != nil {
+			return nil
+		}
+		multisendAddress = address
+	}
+	aggregated, err := builder.AggregateSafeTransactionWithVariant(transactions, multisendAddress, variant)
+	if err != nil {
+		return nil
+	}
+	return c.limits.CheckCalldata(len(strings.TrimPrefix(strings.TrimPrefix(aggregated.Data, "0x"), "0X")) / 2)
+}
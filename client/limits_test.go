@@ -0,0 +1,181 @@
+package client
+
+import (
+	stderrors "errors"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/davidt58/go-builder-relayer-client/builder"
+	"github.com/davidt58/go-builder-relayer-client/config"
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+// transactionsWithData returns count transactions each carrying size bytes of data
+func transactionsWithData(count, size int) []models.SafeTransaction {
+	transactions := make([]models.SafeTransaction, count)
+	for i := range transactions {
+		transactions[i] = *models.NewSafeTransaction("0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174", "0", hexutil.Encode(make([]byte, size)))
+	}
+	return transactions
+}
+
+// multisendSize returns the calldata size of batching transactions through multiSend
+func multisendSize(t *testing.T, c *RelayClient, transactions []models.SafeTransaction) int {
+	t.Helper()
+
+	aggregated, err := builder.AggregateSafeTransaction(transactions, c.contractConfig.SafeMultisend)
+	if err != nil {
+		t.Fatalf("AggregateSafeTransaction failed: %v", err)
+	}
+	return len(hexutil.MustDecode(aggregated.Data))
+}
+
+func TestExecute_RelayerLimits(t *testing.T) {
+	batch := transactionsWithData(2, 40)
+
+	tests := []struct {
+		name         string
+		limits       func(c *RelayClient) config.RelayerLimits
+		transactions []models.SafeTransaction
+		metadata     string
+		wantLimit    errors.Limit
+	}{
+		{
+			name:         "calldata at limit",
+			limits:       func(*RelayClient) config.RelayerLimits { return config.RelayerLimits{MaxCalldataBytes: 100} },
+			transactions: transactionsWithData(1, 100),
+		},
+		{
+			name:         "calldata one byte over",
+			limits:       func(*RelayClient) config.RelayerLimits { return config.RelayerLimits{MaxCalldataBytes: 100} },
+			transactions: transactionsWithData(1, 101),
+			wantLimit:    errors.LimitCalldata,
+		},
+		{
+			name: "multisend calldata at limit",
+			limits: func(c *RelayClient) config.RelayerLimits {
+				return config.RelayerLimits{MaxCalldataBytes: multisendSize(t, c, batch)}
+			},
+			transactions: batch,
+		},
+		{
+			name: "multisend calldata one byte over",
+			limits: func(c *RelayClient) config.RelayerLimits {
+				return config.RelayerLimits{MaxCalldataBytes: multisendSize(t, c, batch) - 1}
+			},
+			transactions: batch,
+			wantLimit:    errors.LimitCalldata,
+		},
+		{
+			name:         "metadata at limit",
+			limits:       func(*RelayClient) config.RelayerLimits { return config.RelayerLimits{MaxMetadataBytes: 16} },
+			transactions: testTransactions(),
+			metadata:     strings.Repeat("m", 16),
+		},
+		{
+			name:         "metadata one byte over",
+			limits:       func(*RelayClient) config.RelayerLimits { return config.RelayerLimits{MaxMetadataBytes: 16} },
+			transactions: testTransactions(),
+			metadata:     strings.Repeat("m", 17),
+			wantLimit:    errors.LimitMetadata,
+		},
+		{
+			name:         "batch count at limit",
+			limits:       func(*RelayClient) config.RelayerLimits { return config.RelayerLimits{MaxBatchCount: 3} },
+			transactions: transactionsWithData(3, 0),
+		},
+		{
+			name:         "batch count one over",
+			limits:       func(*RelayClient) config.RelayerLimits { return config.RelayerLimits{MaxBatchCount: 3} },
+			transactions: transactionsWithData(4, 0),
+			wantLimit:    errors.LimitBatchCount,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			relayer := newFakeRelayer(t)
+			var nonceRequests int32
+			relayer.Handle(GET_NONCE, func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&nonceRequests, 1)
+				relayer.ServeDefault(w, r)
+			})
+			c := newTestClient(t, relayer)
+			if err := WithRelayerLimits(tt.limits(c))(c); err != nil {
+				t.Fatalf("WithRelayerLimits failed: %v", err)
+			}
+
+			_, err := c.Execute(tt.transactions, tt.metadata)
+			if tt.wantLimit == "" {
+				if err != nil {
+					t.Fatalf("Execute failed: %v", err)
+				}
+				return
+			}
+
+			var limitErr *errors.LimitExceededError
+			if !stderrors.As(err, &limitErr) || limitErr.Limit != tt.wantLimit {
+				t.Fatalf("error = %v, want a LimitExceededError for %s", err, tt.wantLimit)
+			}
+			if limitErr.Actual != limitErr.Max+1 {
+				t.Errorf("Actual = %d, Max = %d, want one over", limitErr.Actual, limitErr.Max)
+			}
+			if n := atomic.LoadInt32(&nonceRequests); n != 0 {
+				t.Errorf("fetched the nonce %d times before rejecting the request", n)
+			}
+			if n := len(relayer.Submissions()); n != 0 {
+				t.Errorf("got %d submissions, want none", n)
+			}
+		})
+	}
+}
+
+func TestRelayerLimits_OtherPaths(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	relayer.SetDeployed(false)
+	c := newTestClient(t, relayer)
+	if err := WithRelayerLimits(config.RelayerLimits{MaxMetadataBytes: 4, MaxBatchCount: 2})(c); err != nil {
+		t.Fatalf("WithRelayerLimits failed: %v", err)
+	}
+
+	var limitErr *errors.LimitExceededError
+	if _, err := c.DeployWithOptions(DeployOptions{Metadata: models.ExplicitMetadata("12345")}); !stderrors.As(err, &limitErr) {
+		t.Errorf("Deploy error = %v, want a LimitExceededError", err)
+	}
+	if _, err := c.EnqueueExecute(transactionsWithData(3, 0), ""); !stderrors.As(err, &limitErr) {
+		t.Errorf("EnqueueExecute error = %v, want a LimitExceededError", err)
+	}
+	if n := len(relayer.Submissions()); n != 0 {
+		t.Errorf("got %d submissions, want none", n)
+	}
+
+	// Independent transactions are submitted one per request
+	relayer.SetDeployed(true)
+	if _, err := c.ExecuteIndependent(transactionsWithData(3, 0), ""); err != nil {
+		t.Errorf("ExecuteIndependent failed: %v", err)
+	}
+}
+
+func TestCurrentLimits(t *testing.T) {
+	c := newTestClient(t, newFakeRelayer(t))
+	if got := c.CurrentLimits(); got != config.DefaultRelayerLimits() {
+		t.Errorf("CurrentLimits() = %+v, want the defaults", got)
+	}
+
+	staging := config.RelayerLimits{MaxCalldataBytes: 1024, MaxMetadataBytes: 64, MaxBatchCount: 5}
+	if err := WithRelayerLimits(staging)(c); err != nil {
+		t.Fatalf("WithRelayerLimits failed: %v", err)
+	}
+	if got := c.CurrentLimits(); got != staging {
+		t.Errorf("CurrentLimits() = %+v, want %+v", got, staging)
+	}
+
+	if err := WithRelayerLimits(config.RelayerLimits{MaxBatchCount: -1})(c); err == nil {
+		t.Error("WithRelayerLimits should reject negative limits")
+	}
+}
@@ -0,0 +1,223 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/http"
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+// longPollParam is the GET_TRANSACTION query parameter, in whole seconds,
+// that asks the relayer to hold the request until the transaction changes
+const longPollParam = "wait"
+
+// maxCachedTransactions bounds the transactions whose last response is kept
+// for conditional requests; the oldest entry is evicted first
+const maxCachedTransactions = 1024
+
+// longPollSupport is what the relayer's capabilities say about long-polling
+type longPollSupport int
+
+const (
+	longPollUnknown longPollSupport = iota
+	longPollSupported
+	longPollUnsupported
+)
+
+// cachedTransaction is the last response for a transaction and its validators
+type cachedTransaction struct {
+	validators http.Validators
+	txn        models.RelayerTransaction
+}
+
+// WithLongPolling makes GetTransactionWait and PollUntilState long-poll
+// GET_TRANSACTION, each request held by the relayer for up to maxWait, if
+// the relayer's capabilities endpoint reports longPoll. Otherwise they fall
+// back to plain requests. maxWait should stay below the HTTP client timeout.
+func WithLongPolling(maxWait time.Duration) Option {
+	return func(c *RelayClient) error {
+		if maxWait < time.Second {
+			return errors.ErrInvalidConfiguration("long-poll wait must be at least 1s")
+		}
+		c.longPollMu.Lock()
+		c.longPollWait = maxWait
+		c.longPollMu.Unlock()
+		return nil
+	}
+}
+
+// GetTransactionWait retrieves a transaction by ID, letting the relayer hold
+// the request for up to timeout (capped by WithLongPolling's maxWait) until
+// the transaction changes from the last response seen. Without long-polling
+// it returns the current transaction at once, like GetTransaction.
+func (c *ReadOnlyClient) GetTransactionWait(transactionID string, timeout time.Duration) (*models.RelayerTransaction, error) {
+	return c.getTransactionWait(context.Background(), transactionID, timeout)
+}
+
+// getTransactionWait is GetTransactionWait bound to ctx
+func (c *ReadOnlyClient) getTransactionWait(ctx context.Context, transactionID string, timeout time.Duration) (*models.RelayerTransaction, error) {
+	txn := new(models.RelayerTransaction)
+	if err := c.getTransactionWaitInto(ctx, transactionID, timeout, txn); err != nil {
+		return nil, err
+	}
+	return txn, nil
+}
+
+// getTransactionWaitInto is getTransactionWait decoding into out
+func (c *ReadOnlyClient) getTransactionWaitInto(ctx context.Context, transactionID string, timeout time.Duration, out *models.RelayerTransaction) error {
+	maxWait := c.longPollAvailable(ctx)
+	if timeout > maxWait {
+		timeout = maxWait
+	}
+	return c.fetchTransactionInto(ctx, transactionID, timeout, out)
+}
+
+// longPollAvailable returns the longest wait a long-poll request may ask
+// for, or zero if long-polling is disabled or the relayer does not support
+// it. Support is read from the capabilities endpoint once; a failed read is
+// retried on the next call. While one caller reads it the others make plain
+// requests rather than wait for the read.
+func (c *ReadOnlyClient) longPollAvailable(ctx context.Context) time.Duration {
+	c.longPollMu.Lock()
+	wait, state := c.longPollWait, c.longPollState
+	probe := wait > 0 && state == longPollUnknown && !c.longPollProbing
+	if probe {
+		c.longPollProbing = true
+	}
+	c.longPollMu.Unlock()
+
+	if probe {
+		state = c.probeLongPoll(ctx)
+	}
+	if wait == 0 || state != longPollSupported {
+		return 0
+	}
+	return wait
+}
+
+// probeLongPoll reads long-poll support from the capabilities endpoint,
+// without holding longPollMu for the request, and records it. A relayer
+// without the endpoint does not support long-polling; a failed read leaves
+// support unknown.
+func (c *ReadOnlyClient) probeLongPoll(ctx context.Context) longPollSupport {
+	var capabilities models.CapabilitiesResponse
+	err := c.httpClient.GetJSONContext(ctx, GET_CAPABILITIES, nil, &capabilities)
+
+	state := longPollUnknown
+	if err == nil || errors.IsNotFound(err) {
+		state = longPollUnsupported
+		if capabilities.LongPoll {
+			state = longPollSupported
+		}
+	}
+
+	c.longPollMu.Lock()
+	defer c.longPollMu.Unlock()
+	c.longPollProbing = false
+	c.longPollState = state
+	return state
+}
+
+// fetchTransaction gets a transaction with the validators of the previous
+// response, so an unchanged transaction is a 304 answered from the cache.
+// A positive wait is sent as the long-poll parameter, rounded up to seconds.
+func (c *ReadOnlyClient) fetchTransaction(ctx context.Context, transactionID string, wait time.Duration) (*models.RelayerTransaction, error) {
+	txn := new(models.RelayerTransaction)
+	if err := c.fetchTransactionInto(ctx, transactionID, wait, txn); err != nil {
+		return nil, err
+	}
+	return txn, nil
+}
+
+// transactionLists pools the slices GET_TRANSACTION responses, which are
+// arrays, are decoded into
+var transactionLists = sync.Pool{
+	New: func() interface{} {
+		list := make([]models.RelayerTransaction, 0, 1)
+		return &list
+	},
+}
+
+// fetchTransactionInto is fetchTransaction decoding into out, which is only
+// written when it succeeds. A transaction on another chain is an error
+// unless cross-chain reads are on.
+func (c *ReadOnlyClient) fetchTransactionInto(ctx context.Context, transactionID string, wait time.Duration, out *models.RelayerTransaction) error {
+	path, err := getTransactionRequest{transactionID: transactionID, wait: wait}.encode()
+	if err != nil {
+		return err
+	}
+
+	// The API returns an array. A reused element is zeroed first, as
+	// decoding leaves fields missing from the response untouched.
+	list := transactionLists.Get().(*[]models.RelayerTransaction)
+	defer transactionLists.Put(list)
+	*list = append((*list)[:0], models.RelayerTransaction{})
+
+	cached, ok := c.cachedTransaction(transactionID)
+	validators, notModified, err := c.httpClient.GetConditionalJSONContext(ctx, path, nil, cached.validators, list)
+	if err != nil {
+		return err
+	}
+	if notModified {
+		if !ok {
+			return errors.ErrInvalidResponse("304 Not Modified for a transaction never fetched")
+		}
+		if err := c.checkTransactionChain(&cached.txn); err != nil {
+			return err
+		}
+		c.cacheTransaction(transactionID, validators, &cached.txn)
+		*out = cached.txn
+		return nil
+	}
+
+	// Return first transaction from array
+	if len(*list) == 0 {
+		return errors.ErrTransactionNotFound(transactionID)
+	}
+	if err := c.checkTransactionChain(&(*list)[0]); err != nil {
+		return err
+	}
+
+	*out = (*list)[0]
+	if !validators.IsZero() {
+		c.cacheTransaction(transactionID, validators, out)
+	}
+	return nil
+}
+
+// cachedTransaction returns the cached response for transactionID
+func (c *ReadOnlyClient) cachedTransaction(transactionID string) (cachedTransaction, bool) {
+	c.txCacheMu.Lock()
+	defer c.txCacheMu.Unlock()
+
+	cached, ok := c.txCache[transactionID]
+	if !ok {
+		return cachedTransaction{}, false
+	}
+	return *cached, true
+}
+
+// cacheTransaction records txn and its validators, evicting the oldest
+// entry once maxCachedTransactions are cached. A cached transaction is
+// updated in place.
+func (c *ReadOnlyClient) cacheTransaction(transactionID string, validators http.Validators, txn *models.RelayerTransaction) {
+	c.txCacheMu.Lock()
+	defer c.txCacheMu.Unlock()
+
+	if cached, ok := c.txCache[transactionID]; ok {
+		cached.validators, cached.txn = validators, *txn
+		return
+	}
+	if c.txCache == nil {
+		c.txCache = make(map[string]*cachedTransaction)
+	}
+	c.txCacheOrder = append(c.txCacheOrder, transactionID)
+	if len(c.txCacheOrder) > maxCachedTransactions {
+		delete(c.txCache, c.txCacheOrder[0])
+		c.txCacheOrder = c.txCacheOrder[1:]
+	}
+	c.txCache[transactionID] = &cachedTransaction{validators: validators, txn: *txn}
+}
@@ -0,0 +1,280 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+// etagRelayer serves GET_TRANSACTION with an ETag per state, answering
+// matching If-None-Match requests with a 304, and records every request
+type etagRelayer struct {
+	mu       sync.Mutex
+	state    models.RelayerTransactionState
+	noETag   bool
+	requests []*http.Request
+	bodies   int
+}
+
+func serveETags(relayer *fakeRelayer, state models.RelayerTransactionState) *etagRelayer {
+	e := &etagRelayer{state: state}
+	relayer.Handle(GET_TRANSACTION, func(w http.ResponseWriter, r *http.Request) {
+		e.mu.Lock()
+		e.requests = append(e.requests, r)
+		state, noETag := e.state, e.noETag
+		e.mu.Unlock()
+
+		etag := strconv.Quote(string(state))
+		if !noETag {
+			w.Header().Set("ETag", etag)
+			if r.Header.Get("If-None-Match") == etag {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		}
+		e.mu.Lock()
+		e.bodies++
+		e.mu.Unlock()
+		json.NewEncoder(w).Encode([]models.RelayerTransaction{{TransactionID: r.URL.Query().Get("id"), State: state}})
+	})
+	return e
+}
+
+func (e *etagRelayer) setState(state models.RelayerTransactionState) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.state = state
+}
+
+// last returns the latest request and the number of full bodies served
+func (e *etagRelayer) last() (*http.Request, int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.requests[len(e.requests)-1], e.bodies
+}
+
+// serveCapabilities makes the fake relayer report long-poll support
+func serveCapabilities(relayer *fakeRelayer, longPoll bool) {
+	relayer.Handle(GET_CAPABILITIES, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(models.CapabilitiesResponse{LongPoll: longPoll})
+	})
+}
+
+func TestGetTransaction_ETag(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	etags := serveETags(relayer, models.STATE_NEW)
+	c := newTestClient(t, relayer)
+
+	steps := []struct {
+		name         string
+		state        models.RelayerTransactionState
+		wantIfNone   string
+		wantBodies   int
+		wantTxnState models.RelayerTransactionState
+	}{
+		{name: "first fetch", state: models.STATE_NEW, wantBodies: 1, wantTxnState: models.STATE_NEW},
+		{name: "unchanged is a 304", state: models.STATE_NEW, wantIfNone: `"STATE_NEW"`, wantBodies: 1, wantTxnState: models.STATE_NEW},
+		{name: "changed", state: models.STATE_MINED, wantIfNone: `"STATE_NEW"`, wantBodies: 2, wantTxnState: models.STATE_MINED},
+		{name: "unchanged again", state: models.STATE_MINED, wantIfNone: `"STATE_MINED"`, wantBodies: 2, wantTxnState: models.STATE_MINED},
+	}
+
+	for _, step := range steps {
+		etags.setState(step.state)
+		txn, err := c.GetTransaction("tx-1")
+		if err != nil {
+			t.Fatalf("%s: GetTransaction failed: %v", step.name, err)
+		}
+		if txn.State != step.wantTxnState || txn.TransactionID != "tx-1" {
+			t.Errorf("%s: transaction = %+v, want tx-1 in %s", step.name, txn, step.wantTxnState)
+		}
+		request, bodies := etags.last()
+		if got := request.Header.Get("If-None-Match"); got != step.wantIfNone {
+			t.Errorf("%s: If-None-Match = %q, want %q", step.name, got, step.wantIfNone)
+		}
+		if bodies != step.wantBodies {
+			t.Errorf("%s: served %d bodies, want %d", step.name, bodies, step.wantBodies)
+		}
+		if request.URL.Query().Has(longPollParam) {
+			t.Errorf("%s: plain request sent %s", step.name, longPollParam)
+		}
+	}
+
+	// A cached transaction is a copy
+	txn, _ := c.GetTransaction("tx-1")
+	txn.State = models.STATE_FAILED
+	if again, _ := c.GetTransaction("tx-1"); again.State != models.STATE_MINED {
+		t.Errorf("cached state = %s after modifying a result", again.State)
+	}
+}
+
+func TestGetTransaction_WithoutETags(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	etags := serveETags(relayer, models.STATE_NEW)
+	etags.noETag = true
+	c := newTestClient(t, relayer)
+
+	for i := 1; i <= 2; i++ {
+		if _, err := c.GetTransaction("tx-1"); err != nil {
+			t.Fatalf("GetTransaction failed: %v", err)
+		}
+		request, bodies := etags.last()
+		if request.Header.Get("If-None-Match") != "" || bodies != i {
+			t.Errorf("request %d: If-None-Match %q, %d bodies; want plain GETs", i, request.Header.Get("If-None-Match"), bodies)
+		}
+	}
+}
+
+func TestGetTransactionWait(t *testing.T) {
+	tests := []struct {
+		name         string
+		capabilities *bool
+		maxWait      time.Duration
+		timeout      time.Duration
+		wantWait     string
+	}{
+		{name: "long-poll", capabilities: boolPtr(true), maxWait: 10 * time.Second, timeout: 3 * time.Second, wantWait: "3"},
+		{name: "capped by max wait", capabilities: boolPtr(true), maxWait: 5 * time.Second, timeout: time.Minute, wantWait: "5"},
+		{name: "rounded up to seconds", capabilities: boolPtr(true), maxWait: 10 * time.Second, timeout: 1500 * time.Millisecond, wantWait: "2"},
+		{name: "relayer without support", capabilities: boolPtr(false), maxWait: 10 * time.Second, timeout: 3 * time.Second},
+		{name: "no capabilities endpoint", maxWait: 10 * time.Second, timeout: 3 * time.Second},
+		{name: "not configured", capabilities: boolPtr(true), timeout: 3 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			relayer := newFakeRelayer(t)
+			etags := serveETags(relayer, models.STATE_NEW)
+			if tt.capabilities != nil {
+				serveCapabilities(relayer, *tt.capabilities)
+			}
+			c := newTestClient(t, relayer)
+			if tt.maxWait > 0 {
+				if err := WithLongPolling(tt.maxWait)(c); err != nil {
+					t.Fatalf("WithLongPolling failed: %v", err)
+				}
+			}
+
+			if _, err := c.GetTransaction("tx-1"); err != nil {
+				t.Fatalf("GetTransaction failed: %v", err)
+			}
+			// The long-poll times out without a change: 304, answered from the cache
+			txn, err := c.GetTransactionWait("tx-1", tt.timeout)
+			if err != nil {
+				t.Fatalf("GetTransactionWait failed: %v", err)
+			}
+			if txn.State != models.STATE_NEW {
+				t.Errorf("State = %s, want %s", txn.State, models.STATE_NEW)
+			}
+			request, bodies := etags.last()
+			if got := request.URL.Query().Get(longPollParam); got != tt.wantWait {
+				t.Errorf("%s = %q, want %q", longPollParam, got, tt.wantWait)
+			}
+			if request.Header.Get("If-None-Match") != `"STATE_NEW"` || bodies != 1 {
+				t.Errorf("If-None-Match %q, %d bodies; want a conditional request answered with a 304", request.Header.Get("If-None-Match"), bodies)
+			}
+		})
+	}
+
+	if err := WithLongPolling(0)(newTestClient(t, newFakeRelayer(t))); err == nil {
+		t.Error("WithLongPolling should reject a wait under 1s")
+	}
+}
+
+func TestGetTransactionWait_ProbeDoesNotBlock(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	etags := serveETags(relayer, models.STATE_NEW)
+	probing := make(chan struct{})
+	release := make(chan struct{})
+	var releaseOnce sync.Once
+	// A failing test must still let the held request finish
+	defer releaseOnce.Do(func() { close(release) })
+	relayer.Handle(GET_CAPABILITIES, func(w http.ResponseWriter, r *http.Request) {
+		close(probing)
+		<-release
+		json.NewEncoder(w).Encode(models.CapabilitiesResponse{LongPoll: true})
+	})
+	c := newTestClient(t, relayer)
+	if err := WithLongPolling(10 * time.Second)(c); err != nil {
+		t.Fatalf("WithLongPolling failed: %v", err)
+	}
+
+	probed := make(chan error, 1)
+	go func() {
+		_, err := c.GetTransactionWait("tx-1", 3*time.Second)
+		probed <- err
+	}()
+	<-probing
+
+	// While the capabilities are being read another caller makes a plain request
+	done := make(chan error, 1)
+	go func() {
+		_, err := c.GetTransactionWait("tx-2", 3*time.Second)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("GetTransactionWait failed: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("GetTransactionWait blocked on the capabilities request")
+	}
+	if request, _ := etags.last(); request.URL.Query().Get(longPollParam) != "" {
+		t.Errorf("request during the probe asked to wait %q, want a plain request", request.URL.Query().Get(longPollParam))
+	}
+
+	releaseOnce.Do(func() { close(release) })
+	if err := <-probed; err != nil {
+		t.Fatalf("GetTransactionWait failed: %v", err)
+	}
+	if _, err := c.GetTransactionWait("tx-1", 3*time.Second); err != nil {
+		t.Fatalf("GetTransactionWait failed: %v", err)
+	}
+	if request, _ := etags.last(); request.URL.Query().Get(longPollParam) != "3" {
+		t.Errorf("%s = %q after the probe, want 3", longPollParam, request.URL.Query().Get(longPollParam))
+	}
+}
+
+func TestPollUntilState_LongPoll(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	states := []models.RelayerTransactionState{models.STATE_NEW, models.STATE_NEW, models.STATE_MINED}
+	var mu sync.Mutex
+	var waits []string
+	relayer.Handle(GET_TRANSACTION, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		waits = append(waits, r.URL.Query().Get(longPollParam))
+		state := states[0]
+		if len(states) > 1 {
+			states = states[1:]
+		}
+		mu.Unlock()
+		json.NewEncoder(w).Encode([]models.RelayerTransaction{{TransactionID: "tx-1", State: state}})
+	})
+	serveCapabilities(relayer, true)
+	c := newTestClient(t, relayer)
+	if err := WithLongPolling(10 * time.Second)(c); err != nil {
+		t.Fatalf("WithLongPolling failed: %v", err)
+	}
+
+	txn, err := c.PollUntilState("tx-1", []models.RelayerTransactionState{models.STATE_MINED}, models.STATE_FAILED, 10, 2)
+	if err != nil || txn.State != models.STATE_MINED {
+		t.Fatalf("PollUntilState() = %+v, %v", txn, err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(waits) != 3 || waits[0] != "2" || waits[2] != "2" {
+		t.Errorf("wait parameters = %v, want 2 on every request", waits)
+	}
+	if slept := testClock(c).Slept(); len(slept) != 0 {
+		t.Errorf("slept %v, want the relayer to do the waiting", slept)
+	}
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
@@ -0,0 +1,95 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+func TestMetadataThreading(t *testing.T) {
+	tests := []struct {
+		name     string
+		submit   func(c *RelayClient) error
+		want     *string
+		wantType models.TransactionType
+	}{
+		{
+			name: "execute without metadata",
+			submit: func(c *RelayClient) error {
+				_, err := c.Execute(testTransactions(), "")
+				return err
+			},
+			wantType: models.SAFE,
+		},
+		{
+			name: "execute with metadata",
+			submit: func(c *RelayClient) error {
+				_, err := c.Execute(testTransactions(), "order 42")
+				return err
+			},
+			want:     models.ExplicitMetadata("order 42"),
+			wantType: models.SAFE,
+		},
+		{
+			name: "execute with explicit empty metadata",
+			submit: func(c *RelayClient) error {
+				_, err := c.ExecuteWithOptions(testTransactions(), "", ExecuteOptions{Metadata: models.ExplicitMetadata("")})
+				return err
+			},
+			want:     models.ExplicitMetadata(""),
+			wantType: models.SAFE,
+		},
+		{
+			name: "execute option replaces argument",
+			submit: func(c *RelayClient) error {
+				_, err := c.ExecuteWithOptions(testTransactions(), "ignored", ExecuteOptions{Metadata: models.ExplicitMetadata("option")})
+				return err
+			},
+			want:     models.ExplicitMetadata("option"),
+			wantType: models.SAFE,
+		},
+		{
+			name: "deploy without metadata",
+			submit: func(c *RelayClient) error {
+				_, err := c.Deploy()
+				return err
+			},
+			wantType: models.SAFE_CREATE,
+		},
+		{
+			name: "deploy with explicit empty metadata",
+			submit: func(c *RelayClient) error {
+				_, err := c.DeployWithOptions(DeployOptions{Metadata: models.ExplicitMetadata("")})
+				return err
+			},
+			want:     models.ExplicitMetadata(""),
+			wantType: models.SAFE_CREATE,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			relayer := newFakeRelayer(t)
+			relayer.SetDeployed(tt.wantType == models.SAFE)
+			c := newTestClient(t, relayer)
+
+			if err := tt.submit(c); err != nil {
+				t.Fatalf("submit failed: %v", err)
+			}
+
+			submitted := relayer.Submissions()
+			if len(submitted) != 1 || submitted[0].Type != string(tt.wantType) {
+				t.Fatalf("got %d submissions, want one %s", len(submitted), tt.wantType)
+			}
+			got := submitted[0].Metadata
+			switch {
+			case tt.want == nil && got != nil:
+				t.Errorf("metadata = %q, want the field omitted", *got)
+			case tt.want != nil && got == nil:
+				t.Errorf("metadata omitted, want %q", *tt.want)
+			case tt.want != nil && *got != *tt.want:
+				t.Errorf("metadata = %q, want %q", *got, *tt.want)
+			}
+		})
+	}
+}
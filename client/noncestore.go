@@ -0,0 +1,260 @@
+package client
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/davidt58/go-builder-relayer-client/clock"
+	"github.com/davidt58/go-builder-relayer-client/errors"
+)
+
+// NonceRange is a reserved run of consecutive Safe nonces, Start to End inclusive
+type NonceRange struct {
+	// ID identifies the reservation in the NonceStore
+	ID string `json:"id"`
+	// Start is the first reserved nonce
+	Start uint64 `json:"start"`
+	// End is the last reserved nonce
+	End uint64 `json:"end"`
+	// ExpiresAt is when the range is reclaimed unless committed or released
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// Count returns the number of nonces in the range
+func (r NonceRange) Count() int {
+	return int(r.End-r.Start) + 1
+}
+
+// NonceGap is an expired or released range that could not be reclaimed
+// because a higher nonce was already committed. The Safe cannot execute the
+// committed nonces until transactions with the gap's nonces are submitted.
+type NonceGap struct {
+	// Safe is the Safe whose nonces have the gap
+	Safe string
+	// Start is the first nonce of the gap
+	Start uint64
+	// End is the last nonce of the gap
+	End uint64
+	// HighestCommitted is the highest nonce committed above the gap
+	HighestCommitted uint64
+}
+
+// NonceStore allocates nonce ranges for Safes shared by several submitters.
+// Every call must be atomic per Safe (in Redis, e.g. a Lua script), and
+// expired reservations must be processed before the call's own work. Each
+// call returns the gaps found while reclaiming ranges.
+type NonceStore interface {
+	// Reserve allocates count consecutive nonces of safe, none below floor
+	// (the relayer's next nonce), expiring after ttl
+	Reserve(safe string, floor uint64, count int, ttl time.Duration) (NonceRange, []NonceGap, error)
+	// Commit marks the reservation's nonces as used
+	Commit(safe, id string) ([]NonceGap, error)
+	// Release hands the reservation's nonces back unused
+	Release(safe, id string) ([]NonceGap, error)
+}
+
+// MemoryNonceStore is a NonceStore for submitters in the same process
+type MemoryNonceStore struct {
+	clock clock.Clock
+
+	mu    sync.Mutex
+	safes map[string]*nonceLedger
+}
+
+// nonceLedger is the allocation state of one Safe
+type nonceLedger struct {
+	// next is the lowest nonce never handed out above all live ranges
+	next uint64
+	// reserved are the live reservations by ID
+	reserved map[string]NonceRange
+	// free are reclaimed ranges below next, sorted and not adjacent
+	free []NonceRange
+	// committed reports whether highestCommitted is set
+	committed        bool
+	highestCommitted uint64
+}
+
+// NewMemoryNonceStore creates an empty MemoryNonceStore. Reservations expire
+// by clk; nil means the system clock.
+func NewMemoryNonceStore(clk clock.Clock) *MemoryNonceStore {
+	return &MemoryNonceStore{
+		clock: clock.OrReal(clk),
+		safes: make(map[string]*nonceLedger),
+	}
+}
+
+// Reserve implements NonceStore. The lowest reclaimed range that fits is
+// reused before new nonces are allocated.
+func (s *MemoryNonceStore) Reserve(safe string, floor uint64, count int, ttl time.Duration) (NonceRange, []NonceGap, error) {
+	if count <= 0 {
+		return NonceRange{}, nil, errors.ErrInvalidConfiguration(fmt.Sprintf("nonce count %d must be positive", count))
+	}
+	if ttl <= 0 {
+		return NonceRange{}, nil, errors.ErrInvalidConfiguration("nonce reservation TTL must be positive")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock.Now()
+	ledger := s.ledger(safe)
+	gaps := ledger.expire(safe, now)
+	ledger.raiseFloor(floor)
+
+	r := NonceRange{ID: newReservationID(), ExpiresAt: now.Add(ttl)}
+	r.Start = ledger.take(uint64(count))
+	r.End = r.Start + uint64(count) - 1
+	ledger.reserved[r.ID] = r
+	return r, gaps, nil
+}
+
+// Commit implements NonceStore
+func (s *MemoryNonceStore) Commit(safe, id string) ([]NonceGap, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ledger := s.ledger(safe)
+	gaps := ledger.expire(safe, s.clock.Now())
+	r, ok := ledger.reserved[id]
+	if !ok {
+		return gaps, errors.ErrNonceReservationInactive(id)
+	}
+
+	delete(ledger.reserved, id)
+	if !ledger.committed || r.End > ledger.highestCommitted {
+		ledger.committed = true
+		ledger.highestCommitted = r.End
+	}
+	return gaps, nil
+}
+
+// Release implements NonceStore
+func (s *MemoryNonceStore) Release(safe, id string) ([]NonceGap, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ledger := s.ledger(safe)
+	gaps := ledger.expire(safe, s.clock.Now())
+	r, ok := ledger.reserved[id]
+	if !ok {
+		return gaps, errors.ErrNonceReservationInactive(id)
+	}
+
+	delete(ledger.reserved, id)
+	if gap := ledger.reclaim(safe, r); gap != nil {
+		gaps = append(gaps, *gap)
+	}
+	return gaps, nil
+}
+
+// ledger returns the ledger of safe, creating it on first use
+func (s *MemoryNonceStore) ledger(safe string) *nonceLedger {
+	key := strings.ToLower(safe)
+	ledger, ok := s.safes[key]
+	if !ok {
+		ledger = &nonceLedger{reserved: make(map[string]NonceRange)}
+		s.safes[key] = ledger
+	}
+	return ledger
+}
+
+// expire reclaims the reservations that expired by now, lowest first
+func (l *nonceLedger) expire(safe string, now time.Time) []NonceGap {
+	var expired []NonceRange
+	for id, r := range l.reserved {
+		if !now.Before(r.ExpiresAt) {
+			expired = append(expired, r)
+			delete(l.reserved, id)
+		}
+	}
+	sort.Slice(expired, func(i, j int) bool { return expired[i].Start < expired[j].Start })
+
+	var gaps []NonceGap
+	for _, r := range expired {
+		if gap := l.reclaim(safe, r); gap != nil {
+			gaps = append(gaps, *gap)
+		}
+	}
+	return gaps
+}
+
+// reclaim makes r allocatable again unless a higher nonce was committed, in
+// which case it returns the gap r leaves
+func (l *nonceLedger) reclaim(safe string, r NonceRange) *NonceGap {
+	if l.committed && l.highestCommitted > r.End {
+		return &NonceGap{Safe: safe, Start: r.Start, End: r.End, HighestCommitted: l.highestCommitted}
+	}
+
+	l.free = append(l.free, NonceRange{Start: r.Start, End: r.End})
+	sort.Slice(l.free, func(i, j int) bool { return l.free[i].Start < l.free[j].Start })
+
+	// Merge adjacent ranges, then return a range ending at next to the counter
+	merged := l.free[:1]
+	for _, f := range l.free[1:] {
+		if last := &merged[len(merged)-1]; f.Start == last.End+1 {
+			last.End = f.End
+		} else {
+			merged = append(merged, f)
+		}
+	}
+	l.free = merged
+	if last := l.free[len(l.free)-1]; last.End+1 == l.next {
+		l.next = last.Start
+		l.free = l.free[:len(l.free)-1]
+	}
+	return nil
+}
+
+// raiseFloor moves the counter up to floor, dropping reclaimed nonces the
+// relayer has already seen used
+func (l *nonceLedger) raiseFloor(floor uint64) {
+	if floor > l.next {
+		l.next = floor
+	}
+
+	kept := l.free[:0]
+	for _, f := range l.free {
+		if f.End < floor {
+			continue
+		}
+		if f.Start < floor {
+			f.Start = floor
+		}
+		kept = append(kept, f)
+	}
+	l.free = kept
+}
+
+// take returns the start of count consecutive unallocated nonces
+func (l *nonceLedger) take(count uint64) uint64 {
+	for i, f := range l.free {
+		if f.End-f.Start+1 < count {
+			continue
+		}
+		start := f.Start
+		if f.End-f.Start+1 == count {
+			l.free = append(l.free[:i], l.free[i+1:]...)
+		} else {
+			l.free[i].Start += count
+		}
+		return start
+	}
+
+	start := l.next
+	l.next += count
+	return start
+}
+
+// newReservationID returns a random reservation ID
+func newReservationID() string {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(fmt.Sprintf("crypto/rand failed: %v", err))
+	}
+	return hex.EncodeToString(b[:])
+}
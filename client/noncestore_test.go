@@ -0,0 +1,249 @@
+package client
+
+import (
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/davidt58/go-builder-relayer-client/clock"
+)
+
+const testSafe = "0x6e0c80c90ea6c15917308F820Eac91Ce2724B5b5"
+
+// mustReserve reserves count nonces and fails the test on error
+func mustReserve(t *testing.T, store NonceStore, floor uint64, count int, ttl time.Duration) (NonceRange, []NonceGap) {
+	t.Helper()
+	r, gaps, err := store.Reserve(testSafe, floor, count, ttl)
+	if err != nil {
+		t.Fatalf("Reserve(%d, %d) failed: %v", floor, count, err)
+	}
+	return r, gaps
+}
+
+func TestMemoryNonceStore_ContiguousUnderConcurrency(t *testing.T) {
+	store := NewMemoryNonceStore(clock.NewFake(testClockStart))
+
+	const workers = 50
+	var mu sync.Mutex
+	var ranges []NonceRange
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(count int) {
+			defer wg.Done()
+			r, _, err := store.Reserve(testSafe, 3, count, time.Minute)
+			if err != nil {
+				t.Errorf("Reserve failed: %v", err)
+				return
+			}
+			mu.Lock()
+			ranges = append(ranges, r)
+			mu.Unlock()
+		}(i%5 + 1)
+	}
+	wg.Wait()
+
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].Start < ranges[j].Start })
+	next := uint64(3)
+	ids := make(map[string]bool)
+	for _, r := range ranges {
+		if r.Start != next {
+			t.Fatalf("range %d-%d starts at %d, want the contiguous next nonce %d", r.Start, r.End, r.Start, next)
+		}
+		if ids[r.ID] {
+			t.Fatalf("duplicate reservation ID %s", r.ID)
+		}
+		ids[r.ID] = true
+		next = r.End + 1
+	}
+	// 10 workers each for counts 1..5
+	if want := uint64(3 + 10*(1+2+3+4+5)); next != want {
+		t.Errorf("allocated up to %d, want %d", next, want)
+	}
+}
+
+func TestMemoryNonceStore_Lifecycle(t *testing.T) {
+	tests := []struct {
+		name string
+		// run performs the scenario and returns the next reservation of 5
+		// nonces and the gaps reported along the way
+		run       func(t *testing.T, store NonceStore, clk *clock.Fake) (NonceRange, []NonceGap)
+		wantStart uint64
+		wantGaps  []NonceGap
+	}{
+		{
+			name: "released top range is reused",
+			run: func(t *testing.T, store NonceStore, clk *clock.Fake) (NonceRange, []NonceGap) {
+				a, _ := mustReserve(t, store, 0, 5, time.Minute)
+				gaps, err := store.Release(testSafe, a.ID)
+				if err != nil {
+					t.Fatalf("Release failed: %v", err)
+				}
+				r, more := mustReserve(t, store, 0, 5, time.Minute)
+				return r, append(gaps, more...)
+			},
+			wantStart: 0,
+		},
+		{
+			name: "expired range below a live one is reused",
+			run: func(t *testing.T, store NonceStore, clk *clock.Fake) (NonceRange, []NonceGap) {
+				mustReserve(t, store, 0, 5, time.Minute)
+				mustReserve(t, store, 0, 5, 10*time.Minute)
+				clk.Advance(2 * time.Minute)
+				return mustReserve(t, store, 0, 5, time.Minute)
+			},
+			wantStart: 0,
+		},
+		{
+			name: "expired ranges merge back into the counter",
+			run: func(t *testing.T, store NonceStore, clk *clock.Fake) (NonceRange, []NonceGap) {
+				mustReserve(t, store, 0, 2, time.Minute)
+				mustReserve(t, store, 0, 3, time.Minute)
+				clk.Advance(time.Minute)
+				return mustReserve(t, store, 0, 5, time.Minute)
+			},
+			wantStart: 0,
+		},
+		{
+			name: "reclaimed range too small is skipped",
+			run: func(t *testing.T, store NonceStore, clk *clock.Fake) (NonceRange, []NonceGap) {
+				a, _ := mustReserve(t, store, 0, 2, time.Minute)
+				mustReserve(t, store, 0, 5, time.Hour)
+				if _, err := store.Release(testSafe, a.ID); err != nil {
+					t.Fatalf("Release failed: %v", err)
+				}
+				return mustReserve(t, store, 0, 5, time.Minute)
+			},
+			wantStart: 7,
+		},
+		{
+			name: "expired range below a committed one is a gap",
+			run: func(t *testing.T, store NonceStore, clk *clock.Fake) (NonceRange, []NonceGap) {
+				mustReserve(t, store, 0, 5, time.Minute)
+				b, _ := mustReserve(t, store, 0, 5, 10*time.Minute)
+				gaps, err := store.Commit(testSafe, b.ID)
+				if err != nil {
+					t.Fatalf("Commit failed: %v", err)
+				}
+				clk.Advance(2 * time.Minute)
+				r, more := mustReserve(t, store, 0, 5, time.Minute)
+				return r, append(gaps, more...)
+			},
+			wantStart: 10,
+			wantGaps:  []NonceGap{{Safe: testSafe, Start: 0, End: 4, HighestCommitted: 9}},
+		},
+		{
+			name: "released range below a committed one is a gap",
+			run: func(t *testing.T, store NonceStore, clk *clock.Fake) (NonceRange, []NonceGap) {
+				a, _ := mustReserve(t, store, 0, 5, time.Minute)
+				b, _ := mustReserve(t, store, 0, 5, time.Minute)
+				if _, err := store.Commit(testSafe, b.ID); err != nil {
+					t.Fatalf("Commit failed: %v", err)
+				}
+				gaps, err := store.Release(testSafe, a.ID)
+				if err != nil {
+					t.Fatalf("Release failed: %v", err)
+				}
+				r, more := mustReserve(t, store, 0, 5, time.Minute)
+				return r, append(gaps, more...)
+			},
+			wantStart: 10,
+			wantGaps:  []NonceGap{{Safe: testSafe, Start: 0, End: 4, HighestCommitted: 9}},
+		},
+		{
+			name: "relayer nonce above the counter",
+			run: func(t *testing.T, store NonceStore, clk *clock.Fake) (NonceRange, []NonceGap) {
+				a, _ := mustReserve(t, store, 0, 5, time.Minute)
+				mustReserve(t, store, 0, 5, time.Hour)
+				if _, err := store.Release(testSafe, a.ID); err != nil {
+					t.Fatalf("Release failed: %v", err)
+				}
+				// Nonces 0-11 were used outside the store
+				return mustReserve(t, store, 12, 5, time.Minute)
+			},
+			wantStart: 12,
+		},
+		{
+			name: "relayer nonce inside a reclaimed range",
+			run: func(t *testing.T, store NonceStore, clk *clock.Fake) (NonceRange, []NonceGap) {
+				a, _ := mustReserve(t, store, 0, 8, time.Minute)
+				mustReserve(t, store, 0, 5, time.Hour)
+				if _, err := store.Release(testSafe, a.ID); err != nil {
+					t.Fatalf("Release failed: %v", err)
+				}
+				return mustReserve(t, store, 3, 5, time.Minute)
+			},
+			wantStart: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clk := clock.NewFake(testClockStart)
+			store := NewMemoryNonceStore(clk)
+
+			r, gaps := tt.run(t, store, clk)
+			if r.Start != tt.wantStart || r.Count() != 5 {
+				t.Errorf("next reservation = %d-%d, want 5 nonces from %d", r.Start, r.End, tt.wantStart)
+			}
+			if len(gaps) != len(tt.wantGaps) {
+				t.Fatalf("gaps = %+v, want %+v", gaps, tt.wantGaps)
+			}
+			for i := range gaps {
+				if gaps[i] != tt.wantGaps[i] {
+					t.Errorf("gap %d = %+v, want %+v", i, gaps[i], tt.wantGaps[i])
+				}
+			}
+		})
+	}
+}
+
+func TestMemoryNonceStore_InactiveReservations(t *testing.T) {
+	clk := clock.NewFake(testClockStart)
+	store := NewMemoryNonceStore(clk)
+
+	committed, _ := mustReserve(t, store, 0, 2, time.Minute)
+	if _, err := store.Commit(testSafe, committed.ID); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	expired, _ := mustReserve(t, store, 0, 2, time.Minute)
+	clk.Advance(time.Minute)
+
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{"commit twice", func() error { _, err := store.Commit(testSafe, committed.ID); return err }},
+		{"release committed", func() error { _, err := store.Release(testSafe, committed.ID); return err }},
+		{"commit expired", func() error { _, err := store.Commit(testSafe, expired.ID); return err }},
+		{"release unknown", func() error { _, err := store.Release(testSafe, "unknown"); return err }},
+		{"zero count", func() error { _, _, err := store.Reserve(testSafe, 0, 0, time.Minute); return err }},
+		{"zero ttl", func() error { _, _, err := store.Reserve(testSafe, 0, 1, 0); return err }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.call(); err == nil {
+				t.Error("Expected error but got none")
+			}
+		})
+	}
+}
+
+func TestMemoryNonceStore_SafesAreIndependent(t *testing.T) {
+	store := NewMemoryNonceStore(clock.NewFake(testClockStart))
+
+	mustReserve(t, store, 0, 5, time.Minute)
+	other, _, err := store.Reserve("0x5FbDB2315678afecb367f032d93F642f64180aa3", 0, 5, time.Minute)
+	if err != nil {
+		t.Fatalf("Reserve failed: %v", err)
+	}
+	if other.Start != 0 {
+		t.Errorf("other Safe's range starts at %d, want 0", other.Start)
+	}
+	// Addresses are compared case-insensitively
+	if same, _, _ := store.Reserve(testSafe[:2]+"6E0C80C90EA6C15917308F820EAC91CE2724B5B5", 0, 1, time.Minute); same.Start != 5 {
+		t.Errorf("range starts at %d, want 5", same.Start)
+	}
+}
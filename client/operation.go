@@ -0,0 +1,149 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/davidt58/go-builder-relayer-client/config"
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/http"
+)
+
+// DeployOptions configures a Deploy call
+type DeployOptions struct {
+	// Timeout bounds the whole deployment sequence (deployed check, build,
+	// submit). Zero means no overall deadline.
+	Timeout time.Duration
+	// VerifyDeployment makes the returned response's Wait check the factory's
+	// ProxyCreation event against the expected Safe address (requires WithRPCClient)
+	VerifyDeployment bool
+	// ForceSubmit skips the health gate (see WithHealthGate)
+	ForceSubmit bool
+	// Metadata is attached to the SAFE-CREATE request. nil omits the field;
+	// models.ExplicitMetadata("") sends it empty.
+	Metadata *string
+}
+
+// ExecuteOptions configures an Execute call
+type ExecuteOptions struct {
+	// Timeout bounds the whole execution sequence (deployed check, nonce,
+	// build, submit), including any deployment made for AutoDeploy.
+	// Zero means no overall deadline.
+	Timeout time.Duration
+	// Multisend selects the MultiSend contract for batches. MultisendCallOnly
+	// rejects DelegateCall transactions before anything is signed; empty
+	// means config.MultisendStandard.
+	Multisend config.MultisendVariant
+	// ForceSubmit skips the health gate (see WithHealthGate)
+	ForceSubmit bool
+	// SkipDeployedCheck skips the pre-flight check that the Safe is deployed.
+	// Without it, executing on an undeployed Safe fails with a
+	// SafeNotDeployedError before the nonce is fetched.
+	SkipDeployedCheck bool
+	// AutoDeploy deploys the derived Safe when the pre-flight check finds it
+	// undeployed, waits until the deployment is mined and then executes.
+	// The nonce for the execution is fetched after the deployment.
+	AutoDeploy bool
+	// Metadata, when set, replaces the metadata argument and is sent as-is,
+	// so models.ExplicitMetadata("") sends an empty field where the empty
+	// argument omits it
+	Metadata *string
+	// OverrideToken lets the batch exceed the client's spend policy when it
+	// matches the policy's OverrideToken (see SpendPolicy). Each override is
+	// logged with the limits it exceeds; the token itself is not.
+	OverrideToken string
+	// AllowSelfExec lets the batch call execTransaction, a module execution
+	// or setup on the Safe itself, which is otherwise rejected before
+	// anything is signed (see models.SafeTransactionArgs.CheckSelfCalls)
+	AllowSelfExec bool
+}
+
+// SubmitOptions configures a SubmitSignedRequest call
+type SubmitOptions struct {
+	// Timeout bounds the freshness check and the submission. Zero means no
+	// overall deadline.
+	Timeout time.Duration
+	// SkipFreshnessCheck submits without first comparing the request's
+	// nonce with the Safe's next nonce. Without it, a request whose nonce
+	// was consumed since it was built fails with a StaleNonceError, and one
+	// exactly one ahead with a NonceNotYetCurrentError, before anything is
+	// submitted.
+	SkipFreshnessCheck bool
+}
+
+// Steps of Deploy and Execute, reported in OperationTimeoutError and StagedError
+const (
+	stepDeployedCheck = string(errors.StageDeployedCheck)
+	stepHealthCheck   = string(errors.StageHealthCheck)
+	stepNonce         = string(errors.StageNonce)
+	stepBuild         = string(errors.StageBuild)
+	stepSubmit        = string(errors.StageSubmit)
+	stepAutoDeploy    = string(errors.StageAutoDeploy)
+	stepSafeVersion   = string(errors.StageSafeVersion)
+	stepWarmUp        = string(errors.StageWarmUp)
+)
+
+// operation tracks the ID, deadline and completed steps of a multi-request call
+type operation struct {
+	id        string
+	name      string
+	timeout   time.Duration
+	ctx       context.Context
+	cancel    context.CancelFunc
+	completed []string
+	// started and signed time a submitting operation for its response's
+	// LatencyBreakdown; zero when not recorded
+	started time.Time
+	signed  time.Time
+}
+
+// newOperation starts tracking an operation under a fresh operation ID, which
+// is sent with every request made through the operation's context; a zero
+// timeout means no deadline
+func newOperation(name string, timeout time.Duration) *operation {
+	id := http.NewOperationID()
+	ctx := http.ContextWithOperationID(context.Background(), id)
+	cancel := context.CancelFunc(func() {})
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	}
+
+	return &operation{
+		id:      id,
+		name:    name,
+		timeout: timeout,
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+}
+
+// run executes a step within the operation's budget. Failures are wrapped in a
+// StagedError naming the step; if the deadline expires before or during the
+// step, the underlying error is an OperationTimeoutError.
+func (o *operation) run(step string, fn func(ctx context.Context) error) error {
+	stage := errors.Stage(step)
+	if o.ctx.Err() != nil {
+		return errors.ErrStaged(stage, o.timeoutError(step))
+	}
+
+	if err := fn(o.ctx); err != nil {
+		if o.ctx.Err() == context.DeadlineExceeded {
+			return errors.ErrStaged(stage, o.timeoutError(step))
+		}
+		return errors.ErrStaged(stage, err)
+	}
+
+	o.completed = append(o.completed, step)
+	return nil
+}
+
+// tag records the operation ID on an error returned by the operation
+func (o *operation) tag(err error) error {
+	return errors.WithOperationID(err, o.id)
+}
+
+// timeoutError builds the error reported when the budget expires during step
+func (o *operation) timeoutError(step string) error {
+	completed := append([]string(nil), o.completed...)
+	return errors.ErrOperationTimeout(o.name, step, completed, o.timeout)
+}
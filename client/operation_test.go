@@ -0,0 +1,154 @@
+package client
+
+import (
+	"context"
+	stderrors "errors"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/davidt58/go-builder-relayer-client/errors"
+)
+
+func TestExecuteWithOptions_TimeoutDuringSubmit(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	relayer.Delay(SUBMIT_TRANSACTION, 2*time.Second)
+	c := newTestClient(t, relayer)
+
+	start := time.Now()
+	_, err := c.ExecuteWithOptions(testTransactions(), "", ExecuteOptions{Timeout: 200 * time.Millisecond})
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("ExecuteWithOptions took %s, want it bounded by the timeout", elapsed)
+	}
+
+	var timeoutErr *errors.OperationTimeoutError
+	if !stderrors.As(err, &timeoutErr) {
+		t.Fatalf("error = %v, want OperationTimeoutError", err)
+	}
+	if timeoutErr.Operation != "Execute" || timeoutErr.Step != stepSubmit {
+		t.Errorf("timed out in %s/%s, want Execute/%s", timeoutErr.Operation, timeoutErr.Step, stepSubmit)
+	}
+	want := []string{stepDeployedCheck, stepNonce, stepBuild}
+	if !reflect.DeepEqual(timeoutErr.CompletedSteps, want) {
+		t.Errorf("CompletedSteps = %v, want %v", timeoutErr.CompletedSteps, want)
+	}
+	if !stderrors.Is(err, context.DeadlineExceeded) {
+		t.Error("OperationTimeoutError should unwrap to context.DeadlineExceeded")
+	}
+}
+
+func TestExecuteWithOptions_TimeoutDuringNonce(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	relayer.Delay(GET_NONCE, 2*time.Second)
+	c := newTestClient(t, relayer)
+
+	_, err := c.ExecuteWithOptions(testTransactions(), "", ExecuteOptions{Timeout: 100 * time.Millisecond})
+
+	var timeoutErr *errors.OperationTimeoutError
+	if !stderrors.As(err, &timeoutErr) {
+		t.Fatalf("error = %v, want OperationTimeoutError", err)
+	}
+	if timeoutErr.Step != stepNonce || !reflect.DeepEqual(timeoutErr.CompletedSteps, []string{stepDeployedCheck}) {
+		t.Errorf("Step = %s, CompletedSteps = %v, want %s after %s", timeoutErr.Step, timeoutErr.CompletedSteps, stepNonce, stepDeployedCheck)
+	}
+}
+
+func TestDeployWithOptions_TimeoutDuringDeployedCheck(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	relayer.Delay(GET_DEPLOYED, 2*time.Second)
+	c := newTestClient(t, relayer)
+
+	_, err := c.DeployWithOptions(DeployOptions{Timeout: 100 * time.Millisecond})
+
+	var timeoutErr *errors.OperationTimeoutError
+	if !stderrors.As(err, &timeoutErr) {
+		t.Fatalf("error = %v, want OperationTimeoutError", err)
+	}
+	if timeoutErr.Operation != "Deploy" || timeoutErr.Step != stepDeployedCheck {
+		t.Errorf("timed out in %s/%s, want Deploy/%s", timeoutErr.Operation, timeoutErr.Step, stepDeployedCheck)
+	}
+	if len(relayer.Submissions()) != 0 {
+		t.Error("nothing should be submitted after the deadline expired")
+	}
+}
+
+func TestDeployWithOptions_WithinBudget(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	relayer.SetDeployed(false)
+	relayer.Delay(GET_DEPLOYED, 5*time.Millisecond)
+	c := newTestClient(t, relayer)
+
+	response, err := c.DeployWithOptions(DeployOptions{Timeout: 5 * time.Second})
+	if err != nil {
+		t.Fatalf("DeployWithOptions failed: %v", err)
+	}
+	if response.TransactionID == "" {
+		t.Error("expected a transaction ID")
+	}
+}
+
+func TestExecute_OperationIDCorrelation(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	c := newTestClient(t, relayer)
+
+	first, err := c.Execute(testTransactions(), "")
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	second, err := c.Execute(testTransactions(), "")
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	// The deployed check is cached after the first Execute
+	ids := relayer.OperationIDs()
+	if len(ids) != 5 {
+		t.Fatalf("got %d requests, want 5 (deployed check + nonce + submit, then nonce + submit)", len(ids))
+	}
+	if ids[0] == "" || ids[0] != ids[1] || ids[1] != ids[2] {
+		t.Errorf("requests of one Execute carry IDs %q, %q and %q, want the same non-empty ID", ids[0], ids[1], ids[2])
+	}
+	if ids[3] != ids[4] {
+		t.Errorf("requests of one Execute carry IDs %q and %q, want the same ID", ids[3], ids[4])
+	}
+	if ids[0] == ids[3] {
+		t.Errorf("two Execute calls share operation ID %q", ids[0])
+	}
+	if first.OperationID != ids[0] || second.OperationID != ids[3] {
+		t.Errorf("response OperationIDs = %q, %q, want %q, %q", first.OperationID, second.OperationID, ids[0], ids[3])
+	}
+}
+
+func TestExecute_OperationIDOnError(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	relayer.Handle(SUBMIT_TRANSACTION, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	c := newTestClient(t, relayer)
+
+	_, err := c.Execute(testTransactions(), "")
+	if err == nil {
+		t.Fatal("expected submission error")
+	}
+
+	var clientErr *errors.RelayerClientError
+	if !stderrors.As(err, &clientErr) {
+		t.Fatalf("error = %v, want RelayerClientError", err)
+	}
+	ids := relayer.OperationIDs()
+	if clientErr.OperationID == "" || clientErr.OperationID != ids[len(ids)-1] {
+		t.Errorf("error OperationID = %q, want %q", clientErr.OperationID, ids[len(ids)-1])
+	}
+}
+
+func TestExecuteWithOptions_TimeoutCarriesOperationID(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	relayer.Delay(GET_NONCE, 2*time.Second)
+	c := newTestClient(t, relayer)
+
+	_, err := c.ExecuteWithOptions(testTransactions(), "", ExecuteOptions{Timeout: 100 * time.Millisecond})
+	if id := errors.OperationIDOf(err); id == "" {
+		t.Errorf("timeout error %v carries no operation ID", err)
+	}
+}
@@ -0,0 +1,141 @@
+package client
+
+import (
+	"github.com/davidt58/go-builder-relayer-client/clock"
+	"github.com/davidt58/go-builder-relayer-client/config"
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/http"
+	"github.com/davidt58/go-builder-relayer-client/signer"
+)
+
+// Option configures a RelayClient at construction time
+type Option func(*RelayClient) error
+
+// WithHTTPOptions passes options through to the underlying HTTP client
+func WithHTTPOptions(opts ...http.ClientOption) Option {
+	return func(c *RelayClient) error {
+		c.httpOptions = append(c.httpOptions, opts...)
+		return nil
+	}
+}
+
+// WithPinnedCertificates only allows relayer connections whose leaf
+// certificate SPKI SHA-256 hash is in the given list (hex or base64)
+func WithPinnedCertificates(spkiSHA256 ...string) Option {
+	return WithHTTPOptions(http.WithPinnedCertificates(spkiSHA256...))
+}
+
+// WithMinTLSVersion sets the minimum TLS version for relayer connections (default TLS 1.2)
+func WithMinTLSVersion(version uint16) Option {
+	return WithHTTPOptions(http.WithMinTLSVersion(version))
+}
+
+// WithMaxResponseSize caps relayer response bodies at the given number of
+// bytes (default http.DefaultMaxResponseSize)
+func WithMaxResponseSize(bytes int64) Option {
+	return WithHTTPOptions(http.WithMaxResponseSize(bytes))
+}
+
+// WithResponseEnvelope sets how relayer response payloads are wrapped, for
+// deployments that answer {"success": ..., "data": ...} (default
+// http.EnvelopeNone)
+func WithResponseEnvelope(mode http.ResponseEnvelope) Option {
+	return WithHTTPOptions(http.WithResponseEnvelope(mode))
+}
+
+// WithBuilderCredentialCheck validates the builder credentials and decodes the
+// secret during NewRelayClient, so bad credentials fail at startup instead of
+// on the first authenticated request. If the relayer has a key-info
+// endpoint, the key's scopes are also fetched (see GetKeyCapabilities).
+func WithBuilderCredentialCheck() Option {
+	return func(c *RelayClient) error {
+		if c.builderConfig == nil {
+			return errors.ErrBuilderCredsNotConfigured
+		}
+		c.checkKeyScopes = true
+		return c.builderConfig.Precompute()
+	}
+}
+
+// WithStrictStateTransitions makes PollUntilState return a TransitionError when
+// a poll reports an earlier state than one already seen, rather than logging
+// it and treating it as a stale read
+func WithStrictStateTransitions() Option {
+	return func(c *RelayClient) error {
+		c.strictStateTransitions = true
+		return nil
+	}
+}
+
+// defaultPollErrorBudget is the number of consecutive transient errors
+// PollUntilState tolerates by default
+const defaultPollErrorBudget = 5
+
+// WithPollErrorBudget sets how many consecutive transient errors (5xx, rate
+// limiting, network failures) PollUntilState tolerates before returning a
+// PollErrorBudgetError. Each failed poll still consumes one of maxPolls.
+func WithPollErrorBudget(consecutiveErrors int) Option {
+	return func(c *RelayClient) error {
+		if consecutiveErrors < 1 {
+			return errors.ErrInvalidConfiguration("poll error budget must be at least 1")
+		}
+		c.pollErrorBudget = consecutiveErrors
+		return nil
+	}
+}
+
+// WithSignatureScheme selects how SAFE transactions are signed for relayer
+// deployments that expect a scheme other than the chain's configured one.
+// The client's contract config is copied, so the global registry is unchanged,
+// and the scheme is kept when the config is refreshed.
+func WithSignatureScheme(scheme signer.SignatureScheme) Option {
+	return func(c *RelayClient) error {
+		if err := scheme.Validate(); err != nil {
+			return err
+		}
+		c.overrideContractConfig(func(contractConfig *config.ContractConfig) {
+			contractConfig.SignatureScheme = scheme
+		})
+		c.signatureSchemeSet = true
+		return nil
+	}
+}
+
+// WithSigner signs with sig instead of a private key passed to
+// NewRelayClient, e.g. a signer.NewRemoteSigner delegating to a signing
+// service. sig must be for the client's chain.
+func WithSigner(sig *signer.Signer) Option {
+	return func(c *RelayClient) error {
+		if sig == nil {
+			return errors.ErrSignerNotConfigured
+		}
+		c.signer = sig
+		return nil
+	}
+}
+
+// WithSubmissionStore persists every submission to store just before and
+// after it is POSTed, so RecoverPending can reconcile submissions that were
+// in flight when the process stopped
+func WithSubmissionStore(store SubmissionStore) Option {
+	return func(c *RelayClient) error {
+		if store == nil {
+			return errors.ErrMissingRequiredField("store")
+		}
+		c.submissionStore = store
+		return nil
+	}
+}
+
+// WithClock replaces the system clock used for polling waits and the health
+// gate cache, e.g. with a clock.Fake in tests. BuilderConfig has its own Clock
+// for HMAC timestamps.
+func WithClock(clk clock.Clock) Option {
+	return func(c *RelayClient) error {
+		if clk == nil {
+			return errors.ErrMissingRequiredField("clock")
+		}
+		c.clock = clk
+		return nil
+	}
+}
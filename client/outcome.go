@@ -0,0 +1,105 @@
+package client
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+// ExecutionSuccessEventTopic is the log topic of the Safe's
+// ExecutionSuccess(bytes32 txHash, uint256 payment) event
+var ExecutionSuccessEventTopic = crypto.Keccak256Hash([]byte("ExecutionSuccess(bytes32,uint256)"))
+
+// ExecutionFailureEventTopic is the log topic of the Safe's
+// ExecutionFailure(bytes32 txHash, uint256 payment) event
+var ExecutionFailureEventTopic = crypto.Keccak256Hash([]byte("ExecutionFailure(bytes32,uint256)"))
+
+// WaitForOutcome waits until the transaction is mined and reports whether
+// it took effect: the relayer state, and with an RPC client (see
+// WithRPCClient) the receipt status and the Safe's execution event. Without
+// one both are models.OnChainStatusUnknown and models.SafeExecutionUnknown.
+// When the relayer reports the transaction failed, the outcome is returned
+// along with the failure error.
+// Default polling: max 100 polls, every 2 seconds
+func (c *RelayClient) WaitForOutcome(transactionID string) (*models.ExecutionOutcome, error) {
+	return c.WaitForOutcomeWithOptions(transactionID, 100, 2)
+}
+
+// WaitForOutcomeWithOptions waits for the outcome with custom polling options
+func (c *RelayClient) WaitForOutcomeWithOptions(transactionID string, maxPolls, pollFrequency int) (*models.ExecutionOutcome, error) {
+	op := newOperation("WaitForOutcome", 0)
+	defer op.cancel()
+
+	outcome, err := c.waitForOutcome(op.ctx, transactionID, maxPolls, pollFrequency)
+	return outcome, op.tag(err)
+}
+
+// waitForOutcome polls until the transaction is mined or failed, then
+// checks its receipt when an RPC client is configured
+func (c *RelayClient) waitForOutcome(ctx context.Context, transactionID string, maxPolls, pollFrequency int) (*models.ExecutionOutcome, error) {
+	states := []models.RelayerTransactionState{models.STATE_MINED, models.STATE_CONFIRMED}
+	txn, waitErr := c.pollUntilState(ctx, transactionID, states, models.STATE_FAILED, maxPolls, pollFrequency)
+	if txn == nil || (waitErr != nil && !txn.IsFailed()) {
+		return nil, waitErr
+	}
+
+	outcome := &models.ExecutionOutcome{
+		Transaction:          txn,
+		RelayerState:         txn.State,
+		OnChainStatus:        models.OnChainStatusUnknown,
+		SafeExecutionSuccess: models.SafeExecutionUnknown,
+		TxHash:               stringValue(txn.Hash),
+	}
+	if txn.BlockNumber != nil && *txn.BlockNumber > 0 {
+		outcome.BlockNumber = uint64(*txn.BlockNumber)
+	}
+	if c.rpcClient == nil || outcome.TxHash == "" {
+		return outcome, waitErr
+	}
+
+	receipt, err := c.rpcClient.TransactionReceipt(ctx, common.HexToHash(outcome.TxHash))
+	if err != nil {
+		if waitErr != nil {
+			return outcome, waitErr
+		}
+		return outcome, errors.NewRelayerClientError("failed to fetch transaction receipt", err)
+	}
+
+	outcome.GasUsed = receipt.GasUsed
+	if receipt.BlockNumber != nil {
+		outcome.BlockNumber = receipt.BlockNumber.Uint64()
+	}
+	if receipt.Status == types.ReceiptStatusSuccessful {
+		outcome.OnChainStatus = models.OnChainStatusSuccess
+		outcome.SafeExecutionSuccess = DecodeSafeExecution(receipt, common.HexToAddress(txn.SafeAddress))
+	} else {
+		// A reverted execTransaction executed nothing
+		outcome.OnChainStatus = models.OnChainStatusReverted
+		outcome.SafeExecutionSuccess = models.SafeExecutionFailed
+	}
+	return outcome, waitErr
+}
+
+// DecodeSafeExecution returns the execution status reported by the
+// ExecutionSuccess or ExecutionFailure event the Safe at safe emitted in the
+// receipt, or models.SafeExecutionUnknown without one, e.g. for a Safe
+// deployment. A zero safe matches events from any address.
+func DecodeSafeExecution(receipt *types.Receipt, safe common.Address) models.SafeExecutionStatus {
+	for _, entry := range receipt.Logs {
+		if len(entry.Topics) == 0 || (safe != (common.Address{}) && entry.Address != safe) {
+			continue
+		}
+		switch entry.Topics[0] {
+		case ExecutionSuccessEventTopic:
+			return models.SafeExecutionSucceeded
+		case ExecutionFailureEventTopic:
+			return models.SafeExecutionFailed
+		}
+	}
+	return models.SafeExecutionUnknown
+}
@@ -0,0 +1,125 @@
+package client
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+const (
+	testExecTxHash  = "0x9a8b7c6d5e4f3a2b1c0d9e8f5f2a1c7e6b1d0e4c3a8f9b2d7c6e5a4b3c2d1e0f"
+	testOutcomeSafe = "0xd93B25cb943D14d0d34FBaF01Fc93a0f8b5F6E47"
+)
+
+// serveExecTransaction makes the fake relayer report a SAFE transaction of
+// testOutcomeSafe in state
+func serveExecTransaction(relayer *fakeRelayer, state models.RelayerTransactionState) {
+	relayer.Handle(GET_TRANSACTION, func(w http.ResponseWriter, r *http.Request) {
+		hash, block := testExecTxHash, int64(41)
+		json.NewEncoder(w).Encode([]models.RelayerTransaction{{
+			TransactionID: r.URL.Query().Get("id"),
+			State:         state,
+			Type:          models.SAFE,
+			SafeAddress:   testOutcomeSafe,
+			Hash:          &hash,
+			BlockNumber:   &block,
+		}})
+	})
+}
+
+// execReceipt is a canned receipt of testExecTxHash with a log of each
+// topic, emitted by emitter
+func execReceipt(status uint64, emitter string, topics ...common.Hash) *types.Receipt {
+	receipt := &types.Receipt{
+		Status:      status,
+		TxHash:      common.HexToHash(testExecTxHash),
+		BlockNumber: big.NewInt(42),
+		GasUsed:     84000,
+	}
+	for _, topic := range topics {
+		receipt.Logs = append(receipt.Logs, &types.Log{
+			Address: common.HexToAddress(emitter),
+			Topics:  []common.Hash{topic, common.HexToHash("0x01")},
+			Data:    make([]byte, 32),
+		})
+	}
+	return receipt
+}
+
+func TestWaitForOutcome(t *testing.T) {
+	otherSafe := "0x1111111111111111111111111111111111111111"
+
+	tests := []struct {
+		name        string
+		state       models.RelayerTransactionState
+		receipt     *types.Receipt
+		wantChain   models.OnChainStatus
+		wantSafe    models.SafeExecutionStatus
+		wantFailed  bool
+		wantSucceed bool
+	}{
+		{"no RPC, confirmed", models.STATE_CONFIRMED, nil, models.OnChainStatusUnknown, models.SafeExecutionUnknown, false, false},
+		{"no RPC, mined", models.STATE_MINED, nil, models.OnChainStatusUnknown, models.SafeExecutionUnknown, false, false},
+		{"ExecutionSuccess", models.STATE_CONFIRMED, execReceipt(1, testOutcomeSafe, ExecutionSuccessEventTopic),
+			models.OnChainStatusSuccess, models.SafeExecutionSucceeded, false, true},
+		{"ExecutionFailure", models.STATE_CONFIRMED, execReceipt(1, testOutcomeSafe, ExecutionFailureEventTopic),
+			models.OnChainStatusSuccess, models.SafeExecutionFailed, false, false},
+		{"ExecutionSuccess of another Safe", models.STATE_MINED, execReceipt(1, otherSafe, ExecutionSuccessEventTopic),
+			models.OnChainStatusSuccess, models.SafeExecutionUnknown, false, false},
+		{"no Safe event", models.STATE_CONFIRMED, execReceipt(1, testOutcomeSafe),
+			models.OnChainStatusSuccess, models.SafeExecutionUnknown, false, false},
+		{"reverted", models.STATE_MINED, execReceipt(0, testOutcomeSafe),
+			models.OnChainStatusReverted, models.SafeExecutionFailed, false, false},
+		{"relayer failed, reverted", models.STATE_FAILED, execReceipt(0, testOutcomeSafe),
+			models.OnChainStatusReverted, models.SafeExecutionFailed, true, false},
+		{"relayer failed, no RPC", models.STATE_FAILED, nil, models.OnChainStatusUnknown, models.SafeExecutionUnknown, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			relayer := newFakeRelayer(t)
+			serveExecTransaction(relayer, tt.state)
+			c := newTestClient(t, relayer)
+			if tt.receipt != nil {
+				c.rpcClient = &fixtureReceiptFetcher{receipt: tt.receipt}
+			}
+
+			response := models.NewClientRelayerTransactionResponse("tx-1")
+			response.SetClient(c)
+			outcome, err := response.WaitForOutcome()
+
+			if tt.wantFailed != (err != nil && strings.Contains(err.Error(), "tx-1 failed")) || (!tt.wantFailed && err != nil) {
+				t.Fatalf("err = %v, want failed %v", err, tt.wantFailed)
+			}
+			if outcome == nil {
+				t.Fatal("outcome is nil")
+			}
+			if outcome.RelayerState != tt.state || outcome.OnChainStatus != tt.wantChain || outcome.SafeExecutionSuccess != tt.wantSafe {
+				t.Errorf("outcome = %s/%s/%s, want %s/%s/%s", outcome.RelayerState, outcome.OnChainStatus, outcome.SafeExecutionSuccess,
+					tt.state, tt.wantChain, tt.wantSafe)
+			}
+			if outcome.Succeeded() != tt.wantSucceed {
+				t.Errorf("Succeeded() = %v, want %v", outcome.Succeeded(), tt.wantSucceed)
+			}
+			if outcome.TxHash != testExecTxHash {
+				t.Errorf("TxHash = %s, want %s", outcome.TxHash, testExecTxHash)
+			}
+
+			// The receipt's block and gas win over the relayer's view
+			wantBlock, wantGas := uint64(41), uint64(0)
+			if tt.receipt != nil {
+				wantBlock, wantGas = 42, 84000
+			}
+			if outcome.BlockNumber != wantBlock || outcome.GasUsed != wantGas {
+				t.Errorf("block %d gas %d, want block %d gas %d", outcome.BlockNumber, outcome.GasUsed, wantBlock, wantGas)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package client
+
+import (
+	"context"
+	"strings"
+
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+// pendingDeployment returns the most recently created SAFE-CREATE of
+// safeAddress that has not reached a terminal state, or nil. A nonce is
+// only compared when both the transaction and nonce carry one.
+func (c *RelayClient) pendingDeployment(ctx context.Context, safeAddress, nonce string) (*models.RelayerTransaction, error) {
+	list, err := c.getTransactions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending *models.RelayerTransaction
+	for i := range list.Transactions {
+		txn := &list.Transactions[i]
+		if txn.Type != models.SAFE_CREATE || txn.State.IsTerminal() || !strings.EqualFold(txn.SafeAddress, safeAddress) {
+			continue
+		}
+		if nonce != "" && txn.Nonce != nil && *txn.Nonce != nonce {
+			continue
+		}
+		if pending == nil || txn.CreatedAt > pending.CreatedAt {
+			pending = txn
+		}
+	}
+	return pending, nil
+}
+
+// checkPendingDeployment fails with a DeploymentPendingError if a deployment
+// of safeAddress with nonce is in flight. A failed lookup is logged and
+// ignored: the relayer still rejects a duplicate, only less clearly.
+func (c *RelayClient) checkPendingDeployment(ctx context.Context, safeAddress, nonce string) error {
+	pending, err := c.pendingDeployment(ctx, safeAddress, nonce)
+	if err != nil {
+		if ctx.Err() != nil {
+			return err
+		}
+		c.logger.Printf("Pending deployment check failed: %v", err)
+		return nil
+	}
+	if pending == nil {
+		return nil
+	}
+	return errors.ErrDeploymentAlreadyPending(safeAddress, pending.TransactionID, string(pending.State))
+}
+
+// ResumeDeploy returns a response for the signer's deployment that is still
+// in flight at the relayer, e.g. after Deploy failed with a
+// DeploymentPendingError or the process restarted, so the caller can Wait on
+// it. Like AttachResponse's, the response records the Safe as deployed once
+// a wait sees it mined. It fails with ErrNoPendingDeployment when no
+// deployment is in flight.
+func (c *RelayClient) ResumeDeploy() (*models.ClientRelayerTransactionResponse, error) {
+	if err := c.assertSignerNeeded(); err != nil {
+		return nil, err
+	}
+	safeAddress, err := c.expectedSafe()
+	if err != nil {
+		return nil, err
+	}
+
+	pending, err := c.pendingDeployment(context.Background(), safeAddress, "")
+	if err != nil {
+		return nil, err
+	}
+	if pending == nil {
+		return nil, errors.ErrNoPendingDeployment
+	}
+
+	response := models.NewClientRelayerTransactionResponse(pending.TransactionID)
+	response.ChainID = c.chainID
+	response.Type = models.SAFE_CREATE
+	response.SafeAddress = safeAddress
+	if pending.Nonce != nil {
+		response.Nonce = *pending.Nonce
+	}
+	response.SetClient(c)
+	response.SetClock(c.clock)
+	response.SetWaitHook(c.deploymentWaitHook(safeAddress, false))
+	response.RecordState(pending)
+	c.trackLatency(response)
+	return response, nil
+}
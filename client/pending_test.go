@@ -0,0 +1,161 @@
+package client
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+	"testing"
+
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/models"
+	"github.com/davidt58/go-builder-relayer-client/relayertest"
+)
+
+// listTransactions makes the relayer list txns from its transactions endpoint
+func listTransactions(f *fakeRelayer, txns ...models.RelayerTransaction) {
+	f.Handle(GET_TRANSACTIONS, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(models.GetTransactionsResponse{Transactions: txns})
+	})
+}
+
+// safeCreate returns a listed SAFE-CREATE of safeAddress in state
+func safeCreate(id, safeAddress string, state models.RelayerTransactionState) models.RelayerTransaction {
+	nonce := "0"
+	return models.RelayerTransaction{
+		TransactionID: id,
+		State:         state,
+		Type:          models.SAFE_CREATE,
+		SafeAddress:   safeAddress,
+		ChainID:       137,
+		Nonce:         &nonce,
+		CreatedAt:     "2026-03-01T11:59:00Z",
+	}
+}
+
+func TestDeploy_PendingDeployment(t *testing.T) {
+	otherSafe := "0x4444444444444444444444444444444444444444"
+	tests := []struct {
+		name        string
+		listed      func(safeAddress string) []models.RelayerTransaction
+		wantPending string
+	}{
+		{name: "no pending", listed: func(string) []models.RelayerTransaction { return nil }},
+		{
+			name: "pending with the same nonce",
+			listed: func(safeAddress string) []models.RelayerTransaction {
+				return []models.RelayerTransaction{
+					safeCreate("tx-old", safeAddress, models.STATE_FAILED),
+					safeCreate("tx-pending", safeAddress, models.STATE_EXECUTED),
+				}
+			},
+			wantPending: "tx-pending",
+		},
+		{
+			name: "pending but failed",
+			listed: func(safeAddress string) []models.RelayerTransaction {
+				return []models.RelayerTransaction{safeCreate("tx-failed", safeAddress, models.STATE_FAILED)}
+			},
+		},
+		{
+			name: "pending for another Safe",
+			listed: func(string) []models.RelayerTransaction {
+				return []models.RelayerTransaction{safeCreate("tx-other", otherSafe, models.STATE_NEW)}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			relayer := newFakeRelayer(t)
+			relayer.SetDeployed(false)
+			c := newTestClient(t, relayer)
+			safeAddress, err := c.GetExpectedSafe()
+			if err != nil {
+				t.Fatalf("GetExpectedSafe failed: %v", err)
+			}
+			listTransactions(relayer, tt.listed(safeAddress)...)
+
+			response, err := c.Deploy()
+			if tt.wantPending == "" {
+				if err != nil {
+					t.Fatalf("Deploy failed: %v", err)
+				}
+				if len(relayer.Submissions()) != 1 || response.Type != models.SAFE_CREATE {
+					t.Errorf("submitted %d transactions, response %+v", len(relayer.Submissions()), response)
+				}
+				return
+			}
+
+			var pending *errors.DeploymentPendingError
+			if !stderrors.As(err, &pending) || pending.TransactionID != tt.wantPending || pending.SafeAddress != safeAddress {
+				t.Fatalf("error = %v, want a DeploymentPendingError for %s", err, tt.wantPending)
+			}
+			if n := len(relayer.Submissions()); n != 0 {
+				t.Errorf("submitted %d transactions, want 0", n)
+			}
+		})
+	}
+}
+
+func TestDeploy_PendingCheckFailureIgnored(t *testing.T) {
+	// The relayer serves no transactions endpoint; Deploy goes ahead
+	relayer := newFakeRelayer(t)
+	relayer.SetDeployed(false)
+	if _, err := newTestClient(t, relayer).Deploy(); err != nil {
+		t.Fatalf("Deploy failed: %v", err)
+	}
+	if n := len(relayer.Submissions()); n != 1 {
+		t.Errorf("submitted %d transactions, want 1", n)
+	}
+}
+
+func TestResumeDeploy(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	relayer.SetDeployed(false)
+	c := newTestClient(t, relayer)
+	safeAddress, err := c.GetExpectedSafe()
+	if err != nil {
+		t.Fatalf("GetExpectedSafe failed: %v", err)
+	}
+
+	listTransactions(relayer, safeCreate("tx-failed", safeAddress, models.STATE_FAILED))
+	if _, err := c.ResumeDeploy(); err != errors.ErrNoPendingDeployment {
+		t.Fatalf("error = %v, want ErrNoPendingDeployment", err)
+	}
+
+	listTransactions(relayer, safeCreate("tx-pending", safeAddress, models.STATE_NEW))
+	relayer.ScriptStates(models.STATE_EXECUTED, models.STATE_MINED)
+	response, err := c.ResumeDeploy()
+	if err != nil {
+		t.Fatalf("ResumeDeploy failed: %v", err)
+	}
+	if response.TransactionID != "tx-pending" || response.Type != models.SAFE_CREATE || response.SafeAddress != safeAddress || response.ChainID != 137 {
+		t.Fatalf("response = %+v", response)
+	}
+	if txn, err := response.WaitUntilMined(); err != nil || txn.State != models.STATE_MINED {
+		t.Fatalf("WaitUntilMined = %+v, %v", txn, err)
+	}
+	if deployed, ok := c.cachedDeployed(safeAddress); !ok || !deployed {
+		t.Error("resumed deployment did not record the Safe as deployed")
+	}
+}
+
+func TestExecute_AutoDeployWaitsForPendingDeployment(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	relayer.SetDeployed(false)
+	c := newTestClient(t, relayer)
+	safeAddress, err := c.GetExpectedSafe()
+	if err != nil {
+		t.Fatalf("GetExpectedSafe failed: %v", err)
+	}
+	listTransactions(relayer, safeCreate("tx-pending", safeAddress, models.STATE_NEW))
+	relayer.ScriptStates(models.STATE_MINED)
+
+	if _, err := c.ExecuteWithOptions(relayertest.SampleApprovalBatch(), "", ExecuteOptions{AutoDeploy: true}); err != nil {
+		t.Fatalf("ExecuteWithOptions failed: %v", err)
+	}
+	submissions := relayer.Submissions()
+	if len(submissions) != 1 || submissions[0].Type != string(models.SAFE) {
+		t.Errorf("submissions = %+v, want only the execution", submissions)
+	}
+}
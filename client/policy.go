@@ -0,0 +1,312 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/davidt58/go-builder-relayer-client/builder"
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+// Policy decides whether the client may sign an operation. It is evaluated
+// before anything is signed for every Deploy and every Safe transaction the
+// client builds; a denial fails the call with a PolicyDeniedError.
+type Policy interface {
+	Evaluate(op PendingOperation) PolicyDecision
+}
+
+// PolicyDecision is a Policy's verdict on an operation
+type PolicyDecision struct {
+	// Allow permits the operation
+	Allow bool
+	// Rule names the rule that decided, reported with a denial
+	Rule string
+	// Reason explains a denial
+	Reason string
+}
+
+// PendingOperation describes an operation about to be signed
+type PendingOperation struct {
+	// Type is models.SAFE for a batch or models.SAFE_CREATE for a deployment
+	Type models.TransactionType
+	// Safe is the Safe the operation targets, checksummed
+	Safe string
+	// Signer is the signing EOA, checksummed
+	Signer string
+	// Calls are the batch's transactions, in order; none for a deployment
+	Calls []PendingCall
+}
+
+// PendingCall is one transaction of a pending batch
+type PendingCall struct {
+	// Index is the call's position in the batch
+	Index int
+	// To is the call's target, checksummed
+	To string
+	// Value is the native value sent
+	Value *big.Int
+	// Operation is Call or DelegateCall
+	Operation models.OperationType
+	// Data is the normalized calldata
+	Data string
+	// Selector is the lowercase 4-byte selector, or "0x" for calldata
+	// shorter than a selector
+	Selector string
+	// Method is the decoded method name, if its ABI is registered (see
+	// builder.RegisterABI) or it is an ERC-20 call
+	Method string
+	// ERC20 is the decoded transfer, transferFrom or approve of a Call,
+	// with its recipient and amount
+	ERC20 *builder.ERC20Call
+}
+
+// AllowAll is the default Policy: it allows every operation
+type AllowAll struct{}
+
+// Evaluate implements Policy
+func (AllowAll) Evaluate(PendingOperation) PolicyDecision {
+	return PolicyDecision{Allow: true}
+}
+
+// WithPolicy evaluates policy before signing every operation (AllowAll by
+// default). A *RulePolicy is validated as ParseRulePolicy validates one.
+func WithPolicy(policy Policy) Option {
+	return func(c *RelayClient) error {
+		if policy == nil {
+			return errors.ErrMissingRequiredField("policy")
+		}
+		if rules, ok := policy.(*RulePolicy); ok {
+			if err := rules.normalize(); err != nil {
+				return err
+			}
+		}
+		c.policy = policy
+		return nil
+	}
+}
+
+// Rule names reported by RulePolicy
+const (
+	RuleDenyOperations  = "deny.operations"
+	RuleDenySelectors   = "deny.selectors"
+	RuleDenyRecipients  = "deny.recipients"
+	RuleAllowOperations = "allow.operations"
+	RuleAllowSelectors  = "allow.selectors"
+	RuleAllowRecipients = "allow.recipients"
+)
+
+// AnyToken is the Recipients key matching every token
+const AnyToken = "*"
+
+// PolicyRules are the conditions of one side of a RulePolicy. Empty fields
+// impose nothing.
+type PolicyRules struct {
+	// Operations are transaction types: "SAFE" and "SAFE-CREATE"
+	Operations []models.TransactionType `json:"operations,omitempty"`
+	// Selectors are 4-byte selectors like "0xa9059cbb"; "0x" stands for
+	// calls without calldata
+	Selectors []string `json:"selectors,omitempty"`
+	// Recipients are recipient (or spender) addresses of ERC-20 transfer,
+	// transferFrom and approve calls, by token address or AnyToken
+	Recipients map[string][]string `json:"recipients,omitempty"`
+}
+
+// RulePolicy is a Policy configured by allow and deny rules; a deny rule
+// that matches wins over any allow rule. An operation is denied when:
+//
+//   - its type is in Deny.Operations, a call's selector is in
+//     Deny.Selectors, or an ERC-20 call sends to a recipient in
+//     Deny.Recipients for its token; or
+//   - Allow.Operations is set and lacks its type, Allow.Selectors is set and
+//     lacks a call's selector, or Allow.Recipients is set and an ERC-20 call
+//     sends to a recipient not listed for its token (a token without an
+//     entry allows no recipients).
+type RulePolicy struct {
+	Allow PolicyRules `json:"allow"`
+	Deny  PolicyRules `json:"deny"`
+}
+
+// selectorPattern matches a normalized selector
+var selectorPattern = regexp.MustCompile(`^0x([0-9a-f]{8})?$`)
+
+// ParseRulePolicy reads a RulePolicy from a JSON document such as
+//
+//	{
+//	  "allow": {
+//	    "operations": ["SAFE"],
+//	    "selectors": ["0xa9059cbb"],
+//	    "recipients": {"0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174": ["0x..."]}
+//	  },
+//	  "deny": {"recipients": {"*": ["0x..."]}}
+//	}
+//
+// Unknown fields, operation types, selectors and addresses are errors, so a
+// typo cannot silently widen the policy.
+func ParseRulePolicy(data []byte) (*RulePolicy, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	var policy RulePolicy
+	if err := decoder.Decode(&policy); err != nil {
+		return nil, errors.NewRelayerClientError("invalid policy document", err)
+	}
+	if err := policy.normalize(); err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// normalize validates the policy's rules and puts selectors in lowercase
+// and addresses in checksum form
+func (p *RulePolicy) normalize() error {
+	for _, rules := range []*PolicyRules{&p.Allow, &p.Deny} {
+		for _, operation := range rules.Operations {
+			if operation != models.SAFE && operation != models.SAFE_CREATE {
+				return errors.ErrInvalidConfiguration(fmt.Sprintf("unknown policy operation %q", operation))
+			}
+		}
+		for i, selector := range rules.Selectors {
+			selector = strings.ToLower(selector)
+			if !selectorPattern.MatchString(selector) {
+				return errors.ErrInvalidConfiguration(fmt.Sprintf("invalid policy selector %q", rules.Selectors[i]))
+			}
+			rules.Selectors[i] = selector
+		}
+		recipients := make(map[string][]string, len(rules.Recipients))
+		for token, addresses := range rules.Recipients {
+			if token != AnyToken {
+				if !common.IsHexAddress(token) {
+					return errors.ErrInvalidAddress(token)
+				}
+				token = common.HexToAddress(token).Hex()
+			}
+			for _, address := range addresses {
+				if !common.IsHexAddress(address) {
+					return errors.ErrInvalidAddress(address)
+				}
+				recipients[token] = append(recipients[token], common.HexToAddress(address).Hex())
+			}
+		}
+		if rules.Recipients != nil {
+			rules.Recipients = recipients
+		}
+	}
+	return nil
+}
+
+// Evaluate implements Policy
+func (p *RulePolicy) Evaluate(op PendingOperation) PolicyDecision {
+	if containsOperation(p.Deny.Operations, op.Type) {
+		return deny(RuleDenyOperations, "%s operations are denied", op.Type)
+	}
+	for _, call := range op.Calls {
+		if containsString(p.Deny.Selectors, call.Selector) {
+			return deny(RuleDenySelectors, "call %d to %s uses denied selector %s", call.Index, call.To, call.Selector)
+		}
+		if call.ERC20 != nil && p.Deny.listsRecipient(call.To, call.ERC20.To.Hex()) {
+			return deny(RuleDenyRecipients, "call %d (%s of token %s) names denied recipient %s", call.Index, call.ERC20.Method, call.To, call.ERC20.To.Hex())
+		}
+	}
+
+	if len(p.Allow.Operations) > 0 && !containsOperation(p.Allow.Operations, op.Type) {
+		return deny(RuleAllowOperations, "%s operations are not allowed", op.Type)
+	}
+	for _, call := range op.Calls {
+		if len(p.Allow.Selectors) > 0 && !containsString(p.Allow.Selectors, call.Selector) {
+			return deny(RuleAllowSelectors, "call %d to %s uses selector %s, which is not allowed", call.Index, call.To, call.Selector)
+		}
+		if call.ERC20 != nil && len(p.Allow.Recipients) > 0 && !p.Allow.listsRecipient(call.To, call.ERC20.To.Hex()) {
+			return deny(RuleAllowRecipients, "call %d (%s of token %s) names %s, which is not an allowed recipient", call.Index, call.ERC20.Method, call.To, call.ERC20.To.Hex())
+		}
+	}
+	return PolicyDecision{Allow: true}
+}
+
+// listsRecipient reports whether recipient is listed for token or AnyToken
+func (r PolicyRules) listsRecipient(token, recipient string) bool {
+	return containsString(r.Recipients[token], recipient) || containsString(r.Recipients[AnyToken], recipient)
+}
+
+// deny returns a denial by rule
+func deny(rule, format string, args ...interface{}) PolicyDecision {
+	return PolicyDecision{Rule: rule, Reason: fmt.Sprintf(format, args...)}
+}
+
+// containsOperation reports whether operations holds operation
+func containsOperation(operations []models.TransactionType, operation models.TransactionType) bool {
+	for _, o := range operations {
+		if o == operation {
+			return true
+		}
+	}
+	return false
+}
+
+// containsString reports whether values holds value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// checkPolicy evaluates the client's policy on an operation of type on safe
+// batching transactions, and returns a PolicyDeniedError if it is denied
+func (c *RelayClient) checkPolicy(operationType models.TransactionType, safe string, transactions []models.SafeTransaction) error {
+	if _, allowAll := c.policy.(AllowAll); allowAll || c.policy == nil {
+		return nil
+	}
+
+	decision := c.policy.Evaluate(describeOperation(operationType, safe, c.signer.AddressHex(), transactions))
+	if decision.Allow {
+		return nil
+	}
+	c.logger.Printf("Policy denied %s operation on Safe %s (rule %s): %s", operationType, safe, decision.Rule, decision.Reason)
+	return errors.ErrPolicyDenied(string(operationType), decision.Rule, decision.Reason)
+}
+
+// describeOperation builds the PendingOperation a Policy evaluates
+func describeOperation(operationType models.TransactionType, safe, signer string, transactions []models.SafeTransaction) PendingOperation {
+	op := PendingOperation{
+		Type:   operationType,
+		Safe:   common.HexToAddress(safe).Hex(),
+		Signer: common.HexToAddress(signer).Hex(),
+	}
+	for i, tx := range transactions {
+		call := PendingCall{
+			Index:     i,
+			To:        common.HexToAddress(tx.To).Hex(),
+			Operation: tx.Operation,
+			Data:      tx.Data,
+			Selector:  "0x",
+		}
+		if value, err := tx.ValueBig(); err == nil {
+			call.Value = value
+		}
+		if data, err := models.NormalizeHexData(tx.Data); err == nil {
+			call.Data = data
+			if len(data) >= 10 {
+				call.Selector = data[:10]
+			}
+			if decoded, found, _ := builder.DecodeCall(data); found && decoded != nil {
+				call.Method = decoded.Method
+			}
+			if tx.Operation == models.Call {
+				call.ERC20, _, _ = builder.DecodeERC20Call(data)
+				if call.Method == "" && call.ERC20 != nil {
+					call.Method = call.ERC20.Method
+				}
+			}
+		}
+		op.Calls = append(op.Calls, call)
+	}
+	return op
+}
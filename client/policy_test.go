@@ -0,0 +1,186 @@
+package client
+
+import (
+	stderrors "errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/davidt58/go-builder-relayer-client/builder"
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+const (
+	policyToken      = "0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174"
+	policyOther      = "0xc2132D05D31c914a87C6611C10748AEb04B58e8F"
+	policyAlice      = "0x1111111111111111111111111111111111111111"
+	policyBob        = "0x2222222222222222222222222222222222222222"
+	transferSelector = "0xa9059cbb"
+)
+
+// erc20Tx returns a Call of method on token to recipient for amount 1
+func erc20Tx(t *testing.T, token, method, recipient string) models.SafeTransaction {
+	t.Helper()
+	txn, err := models.NewTxBuilder().
+		To(token).
+		FromABICall(builder.ERC20ABI, method, common.HexToAddress(recipient), big.NewInt(1)).
+		Call().
+		Build()
+	if err != nil {
+		t.Fatalf("building %s: %v", method, err)
+	}
+	return txn
+}
+
+func TestRulePolicy_Evaluate(t *testing.T) {
+	transferToAlice := erc20Tx(t, policyToken, "transfer", policyAlice)
+	transferToBob := erc20Tx(t, policyToken, "transfer", policyBob)
+	approveAlice := erc20Tx(t, policyToken, "approve", policyAlice)
+	otherToAlice := erc20Tx(t, policyOther, "transfer", policyAlice)
+	plainCall := *models.NewSafeTransaction(policyAlice, "0", "0x")
+
+	tests := []struct {
+		name     string
+		document string
+		opType   models.TransactionType
+		batch    []models.SafeTransaction
+		wantRule string
+	}{
+		{"empty policy allows", `{}`, models.SAFE, []models.SafeTransaction{transferToBob, plainCall}, ""},
+		{"deny operations", `{"deny": {"operations": ["SAFE-CREATE"]}}`, models.SAFE_CREATE, nil, RuleDenyOperations},
+		{"deny operations passes others", `{"deny": {"operations": ["SAFE-CREATE"]}}`, models.SAFE, []models.SafeTransaction{transferToBob}, ""},
+		{"deny selectors", `{"deny": {"selectors": ["0x095EA7B3"]}}`, models.SAFE, []models.SafeTransaction{transferToAlice, approveAlice}, RuleDenySelectors},
+		{"deny recipients per token", `{"deny": {"recipients": {"` + strings.ToLower(policyToken) + `": ["` + policyBob + `"]}}}`, models.SAFE, []models.SafeTransaction{transferToBob}, RuleDenyRecipients},
+		{"deny recipients of any token", `{"deny": {"recipients": {"*": ["` + policyAlice + `"]}}}`, models.SAFE, []models.SafeTransaction{otherToAlice}, RuleDenyRecipients},
+		{"allow operations", `{"allow": {"operations": ["SAFE-CREATE"]}}`, models.SAFE, []models.SafeTransaction{plainCall}, RuleAllowOperations},
+		{"allow operations permits listed", `{"allow": {"operations": ["SAFE-CREATE"]}}`, models.SAFE_CREATE, nil, ""},
+		{"allow selectors", `{"allow": {"selectors": ["` + transferSelector + `"]}}`, models.SAFE, []models.SafeTransaction{transferToAlice, approveAlice}, RuleAllowSelectors},
+		{"allow selectors without calldata", `{"allow": {"selectors": ["` + transferSelector + `"]}}`, models.SAFE, []models.SafeTransaction{plainCall}, RuleAllowSelectors},
+		{"allow selectors 0x for plain calls", `{"allow": {"selectors": ["0x"]}}`, models.SAFE, []models.SafeTransaction{plainCall}, ""},
+		{"allow recipients permits listed", `{"allow": {"recipients": {"` + policyToken + `": ["` + policyAlice + `"]}}}`, models.SAFE, []models.SafeTransaction{transferToAlice, approveAlice}, ""},
+		{"allow recipients rejects unlisted", `{"allow": {"recipients": {"` + policyToken + `": ["` + policyAlice + `"]}}}`, models.SAFE, []models.SafeTransaction{transferToAlice, transferToBob}, RuleAllowRecipients},
+		{"allow recipients rejects unlisted token", `{"allow": {"recipients": {"` + policyToken + `": ["` + policyAlice + `"]}}}`, models.SAFE, []models.SafeTransaction{otherToAlice}, RuleAllowRecipients},
+		{"allow recipients ignores non-ERC20 calls", `{"allow": {"recipients": {"` + policyToken + `": ["` + policyAlice + `"]}}}`, models.SAFE, []models.SafeTransaction{plainCall}, ""},
+		{
+			"deny wins over allow",
+			`{"allow": {"selectors": ["` + transferSelector + `"], "recipients": {"*": ["` + policyAlice + `", "` + policyBob + `"]}},
+			  "deny": {"recipients": {"` + policyToken + `": ["` + policyBob + `"]}}}`,
+			models.SAFE, []models.SafeTransaction{transferToAlice, transferToBob}, RuleDenyRecipients,
+		},
+		{
+			"deny operations wins over allow operations",
+			`{"allow": {"operations": ["SAFE"]}, "deny": {"operations": ["SAFE"]}}`,
+			models.SAFE, []models.SafeTransaction{plainCall}, RuleDenyOperations,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := ParseRulePolicy([]byte(tt.document))
+			if err != nil {
+				t.Fatalf("ParseRulePolicy failed: %v", err)
+			}
+			decision := policy.Evaluate(describeOperation(tt.opType, policySafe, policyAlice, tt.batch))
+			if decision.Allow != (tt.wantRule == "") || decision.Rule != tt.wantRule {
+				t.Errorf("decision = %+v, want rule %q", decision, tt.wantRule)
+			}
+			if !decision.Allow && decision.Reason == "" {
+				t.Error("denial has no reason")
+			}
+		})
+	}
+}
+
+// policySafe is an arbitrary Safe address for policy evaluation
+const policySafe = "0x3333333333333333333333333333333333333333"
+
+func TestParseRulePolicy_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"unknown field":     `{"allow": {"selector": ["0xa9059cbb"]}}`,
+		"unknown operation": `{"allow": {"operations": ["PROXY"]}}`,
+		"short selector":    `{"deny": {"selectors": ["0xa9059c"]}}`,
+		"invalid token":     `{"allow": {"recipients": {"usdc": ["` + policyAlice + `"]}}}`,
+		"invalid recipient": `{"allow": {"recipients": {"*": ["alice"]}}}`,
+		"not JSON":          `allow everything`,
+	}
+	for name, document := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ParseRulePolicy([]byte(document)); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+}
+
+func TestDescribeOperation(t *testing.T) {
+	batch := []models.SafeTransaction{
+		erc20Tx(t, strings.ToLower(policyToken), "transfer", policyBob),
+		{To: policyAlice, Value: "7", Data: "0xDEADBEEF00", Operation: models.DelegateCall},
+	}
+	op := describeOperation(models.SAFE, strings.ToLower(policySafe), strings.ToLower(policyAlice), batch)
+
+	if op.Safe != common.HexToAddress(policySafe).Hex() || op.Signer != policyAlice || len(op.Calls) != 2 {
+		t.Fatalf("operation = %+v", op)
+	}
+	transfer := op.Calls[0]
+	if transfer.To != policyToken || transfer.Selector != transferSelector || transfer.Method != "transfer" ||
+		transfer.ERC20 == nil || transfer.ERC20.To.Hex() != policyBob || transfer.ERC20.Amount.Int64() != 1 {
+		t.Errorf("transfer call = %+v", transfer)
+	}
+	// A DelegateCall is not decoded as a token movement
+	delegate := op.Calls[1]
+	if delegate.Selector != "0xdeadbeef" || delegate.ERC20 != nil || delegate.Value.Int64() != 7 || delegate.Index != 1 {
+		t.Errorf("delegate call = %+v", delegate)
+	}
+}
+
+func TestWithPolicy_DeniesBeforeSigning(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	submits := countHits(relayer, SUBMIT_TRANSACTION)
+	c := newTestClient(t, relayer)
+	policy := &RulePolicy{Allow: PolicyRules{
+		Operations: []models.TransactionType{models.SAFE},
+		Selectors:  []string{"0xA9059CBB"},
+		Recipients: map[string][]string{strings.ToLower(policyToken): {strings.ToLower(policyAlice)}},
+	}}
+	if err := WithPolicy(policy)(c); err != nil {
+		t.Fatalf("WithPolicy failed: %v", err)
+	}
+
+	_, err := c.Execute([]models.SafeTransaction{erc20Tx(t, policyToken, "transfer", policyBob)}, "")
+	var denied *errors.PolicyDeniedError
+	if !stderrors.As(err, &denied) || denied.Rule != RuleAllowRecipients || denied.Operation != "SAFE" {
+		t.Fatalf("error = %v, want a PolicyDeniedError from %s", err, RuleAllowRecipients)
+	}
+
+	// The deployed check is cached, so a fresh client sees the undeployed Safe
+	relayer.SetDeployed(false)
+	fresh := newTestClient(t, relayer)
+	if err := WithPolicy(policy)(fresh); err != nil {
+		t.Fatalf("WithPolicy failed: %v", err)
+	}
+	if _, err := fresh.Deploy(); !stderrors.As(err, &denied) || denied.Rule != RuleAllowOperations {
+		t.Fatalf("Deploy error = %v, want a PolicyDeniedError from %s", err, RuleAllowOperations)
+	}
+	if got := *submits; got != 0 {
+		t.Fatalf("relayer got %d submissions, want 0", got)
+	}
+
+	// The normalized policy allows the listed transfer
+	relayer.SetDeployed(true)
+	if _, err := c.Execute([]models.SafeTransaction{erc20Tx(t, policyToken, "transfer", policyAlice)}, ""); err != nil {
+		t.Errorf("allowed Execute failed: %v", err)
+	}
+}
+
+func TestWithPolicy_Invalid(t *testing.T) {
+	if err := WithPolicy(nil)(&RelayClient{}); err == nil {
+		t.Error("Expected error for a nil policy")
+	}
+	if err := WithPolicy(&RulePolicy{Deny: PolicyRules{Selectors: []string{"transfer"}}})(&RelayClient{}); err == nil {
+		t.Error("Expected error for an invalid rule")
+	}
+}
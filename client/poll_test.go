@@ -0,0 +1,316 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	relayerhttp "github.com/davidt58/go-builder-relayer-client/http"
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+func TestPollUntilState_Regressions(t *testing.T) {
+	target := []models.RelayerTransactionState{models.STATE_CONFIRMED}
+
+	tests := []struct {
+		name      string
+		states    []models.RelayerTransactionState
+		opts      []Option
+		wantState models.RelayerTransactionState
+		wantWait  time.Duration
+		shouldErr bool
+	}{
+		{
+			name:      "forward progress",
+			states:    []models.RelayerTransactionState{models.STATE_NEW, models.STATE_MINED, models.STATE_CONFIRMED},
+			wantState: models.STATE_CONFIRMED,
+			wantWait:  2 * time.Second,
+		},
+		{
+			name:      "stale read is skipped",
+			states:    []models.RelayerTransactionState{models.STATE_MINED, models.STATE_NEW, models.STATE_CONFIRMED},
+			wantState: models.STATE_CONFIRMED,
+			wantWait:  2 * time.Second,
+		},
+		{
+			name:      "strict mode rejects regression",
+			states:    []models.RelayerTransactionState{models.STATE_MINED, models.STATE_EXECUTED, models.STATE_CONFIRMED},
+			opts:      []Option{WithStrictStateTransitions()},
+			wantState: models.STATE_EXECUTED,
+			wantWait:  time.Second,
+			shouldErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			relayer := newFakeRelayer(t)
+			relayer.ScriptStates(tt.states...)
+			c := newTestClient(t, relayer)
+			for _, opt := range tt.opts {
+				if err := opt(c); err != nil {
+					t.Fatalf("option failed: %v", err)
+				}
+			}
+
+			txn, err := c.PollUntilState("tx-1", target, models.STATE_FAILED, 10, 1)
+			if tt.shouldErr {
+				var transitionErr *errors.TransitionError
+				if !stderrors.As(err, &transitionErr) {
+					t.Fatalf("error = %v, want TransitionError", err)
+				}
+			} else if err != nil {
+				t.Fatalf("PollUntilState failed: %v", err)
+			}
+			if txn.State != tt.wantState {
+				t.Errorf("State = %s, want %s", txn.State, tt.wantState)
+			}
+			if waited := testClock(c).Now().Sub(testClockStart); waited != tt.wantWait {
+				t.Errorf("waited %s between polls, want %s", waited, tt.wantWait)
+			}
+		})
+	}
+}
+
+// pollStep is one scripted poll response: a state, or an HTTP error status
+type pollStep struct {
+	state  models.RelayerTransactionState
+	status int
+}
+
+// scriptPolls makes the fake relayer answer successive polls with steps and
+// returns a function reporting how many polls were made
+func scriptPolls(relayer *fakeRelayer, steps ...pollStep) func() int {
+	var mu sync.Mutex
+	poll := 0
+	relayer.Handle(GET_TRANSACTION, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		step := steps[len(steps)-1]
+		if poll < len(steps) {
+			step = steps[poll]
+		}
+		poll++
+		mu.Unlock()
+
+		if step.status != 0 {
+			w.WriteHeader(step.status)
+			json.NewEncoder(w).Encode(map[string]string{"error": http.StatusText(step.status)})
+			return
+		}
+		json.NewEncoder(w).Encode([]models.RelayerTransaction{{
+			TransactionID: r.URL.Query().Get("id"),
+			State:         step.state,
+		}})
+	})
+
+	return func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return poll
+	}
+}
+
+func TestPollUntilState_TransientErrors(t *testing.T) {
+	target := []models.RelayerTransactionState{models.STATE_CONFIRMED}
+	state := func(s models.RelayerTransactionState) pollStep { return pollStep{state: s} }
+	status := func(code int) pollStep { return pollStep{status: code} }
+
+	tests := []struct {
+		name          string
+		steps         []pollStep
+		opts          []Option
+		maxPolls      int
+		wantPolls     int
+		wantState     models.RelayerTransactionState
+		shouldErr     bool
+		wantBudgetErr bool
+	}{
+		{
+			name:      "errors around a state transition are retried",
+			steps:     []pollStep{state(models.STATE_NEW), status(502), state(models.STATE_MINED), status(503), status(502), state(models.STATE_CONFIRMED)},
+			maxPolls:  10,
+			wantPolls: 6,
+			wantState: models.STATE_CONFIRMED,
+		},
+		{
+			name:      "rate limiting is retried",
+			steps:     []pollStep{status(429), state(models.STATE_CONFIRMED)},
+			maxPolls:  10,
+			wantPolls: 2,
+			wantState: models.STATE_CONFIRMED,
+		},
+		{
+			name:      "not found before first observation is retried",
+			steps:     []pollStep{status(404), status(404), state(models.STATE_NEW), state(models.STATE_CONFIRMED)},
+			maxPolls:  10,
+			wantPolls: 4,
+			wantState: models.STATE_CONFIRMED,
+		},
+		{
+			name:          "budget exhaustion reports last observed state",
+			steps:         []pollStep{state(models.STATE_MINED), status(502)},
+			maxPolls:      20,
+			wantPolls:     6,
+			wantState:     models.STATE_MINED,
+			shouldErr:     true,
+			wantBudgetErr: true,
+		},
+		{
+			name:          "custom budget",
+			steps:         []pollStep{state(models.STATE_MINED), status(503), state(models.STATE_MINED), status(503), status(503)},
+			opts:          []Option{WithPollErrorBudget(2)},
+			maxPolls:      20,
+			wantPolls:     5,
+			wantState:     models.STATE_MINED,
+			shouldErr:     true,
+			wantBudgetErr: true,
+		},
+		{
+			name:      "auth failure aborts",
+			steps:     []pollStep{state(models.STATE_NEW), status(401)},
+			maxPolls:  10,
+			wantPolls: 2,
+			wantState: models.STATE_NEW,
+			shouldErr: true,
+		},
+		{
+			name:      "not found after observation aborts",
+			steps:     []pollStep{state(models.STATE_MINED), status(404)},
+			maxPolls:  10,
+			wantPolls: 2,
+			wantState: models.STATE_MINED,
+			shouldErr: true,
+		},
+		{
+			name:      "errors consume poll slots",
+			steps:     []pollStep{state(models.STATE_NEW), status(502), status(502), state(models.STATE_CONFIRMED)},
+			maxPolls:  3,
+			wantPolls: 3,
+			wantState: models.STATE_NEW,
+			shouldErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			relayer := newFakeRelayer(t)
+			polls := scriptPolls(relayer, tt.steps...)
+			c := newTestClient(t, relayer)
+			for _, opt := range tt.opts {
+				if err := opt(c); err != nil {
+					t.Fatalf("option failed: %v", err)
+				}
+			}
+
+			txn, err := c.PollUntilState("tx-1", target, models.STATE_FAILED, tt.maxPolls, 1)
+			if tt.shouldErr {
+				if err == nil {
+					t.Fatal("Expected error")
+				}
+			} else if err != nil {
+				t.Fatalf("PollUntilState failed: %v", err)
+			}
+
+			var budgetErr *errors.PollErrorBudgetError
+			if got := stderrors.As(err, &budgetErr); got != tt.wantBudgetErr {
+				t.Fatalf("PollErrorBudgetError = %v, want %v (err: %v)", got, tt.wantBudgetErr, err)
+			}
+			if budgetErr != nil && budgetErr.LastState != string(tt.wantState) {
+				t.Errorf("LastState = %s, want %s", budgetErr.LastState, tt.wantState)
+			}
+
+			if txn == nil || txn.State != tt.wantState {
+				t.Errorf("txn = %+v, want state %s", txn, tt.wantState)
+			}
+			if got := polls(); got != tt.wantPolls {
+				t.Errorf("polls = %d, want %d", got, tt.wantPolls)
+			}
+		})
+	}
+}
+
+func TestWithPollErrorBudget_Invalid(t *testing.T) {
+	c := &RelayClient{}
+	if err := WithPollErrorBudget(0)(c); err == nil {
+		t.Error("Expected error for zero budget")
+	}
+}
+
+// pollAllocBudget is the most allocations one poll of a pending transaction
+// may make, through an in-process transport. Nearly all of them are net/http's
+// request and timeout plumbing; a poll makes 40 with Go 1.27. Before polls
+// reused their transaction and response buffer it made 46.
+const pollAllocBudget = 42
+
+// staticTransport answers every request in process with a copy of response
+type staticTransport struct {
+	body []byte
+}
+
+func (s staticTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode:    http.StatusOK,
+		Header:        http.Header{"Content-Type": {"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(s.body)),
+		ContentLength: int64(len(s.body)),
+		Request:       r,
+	}, nil
+}
+
+func TestPollOnce_Allocations(t *testing.T) {
+	if raceEnabled {
+		t.Skip("the race detector's instrumentation allocates; the budget applies to normal builds")
+	}
+	body, err := json.Marshal([]models.RelayerTransaction{{TransactionID: "tx-1", State: models.STATE_NEW}})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	c := newTestClient(t, newFakeRelayer(t))
+	c.httpClient, err = relayerhttp.NewClientWithOptions("http://relayer.test", relayerhttp.WithTransport(staticTransport{body: body}))
+	if err != nil {
+		t.Fatalf("NewClientWithOptions failed: %v", err)
+	}
+
+	p := newTransactionPoll("tx-1", []models.RelayerTransactionState{models.STATE_MINED, models.STATE_CONFIRMED}, models.STATE_FAILED)
+	ctx := context.Background()
+	allocs := testing.AllocsPerRun(100, func() {
+		if done, _, err := c.pollOnce(ctx, p); done || err != nil {
+			t.Fatalf("pollOnce() = %v, %v; want a pending transaction", done, err)
+		}
+	})
+	if p.lastTxn != &p.last || p.last.State != models.STATE_NEW {
+		t.Errorf("last transaction = %+v, want the reused STATE_NEW transaction", p.lastTxn)
+	}
+	t.Logf("%.0f allocations per poll", allocs)
+	if allocs > pollAllocBudget {
+		t.Errorf("%.0f allocations per poll, budget %d", allocs, pollAllocBudget)
+	}
+}
+
+func BenchmarkPollLoop(b *testing.B) {
+	relayer := newFakeRelayer(b)
+	states := make([]models.RelayerTransactionState, 20)
+	for i := range states {
+		states[i] = models.STATE_NEW
+	}
+	target := []models.RelayerTransactionState{models.STATE_MINED}
+	c := newTestClient(b, relayer)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		relayer.ScriptStates(append(states, models.STATE_MINED)...)
+		b.StartTimer()
+		if _, err := c.PollUntilState("tx-1", target, models.STATE_FAILED, len(states)+1, 1); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
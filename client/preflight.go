@@ -0,0 +1,172 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+// PreflightInfo is the state of the client's Safe that Deploy and Execute
+// check before building a transaction
+type PreflightInfo struct {
+	// SignerAddress is the address of the client's signer
+	SignerAddress string
+	// SafeAddress is the Safe derived for the signer
+	SafeAddress string
+	// Deployed reports whether the Safe is deployed
+	Deployed bool
+	// Nonce is the nonce the next operation is signed with: the Safe's next
+	// nonce once it is deployed, "0" for its deployment
+	Nonce string
+}
+
+// preflightPurpose is the operation a pre-flight check prepares for
+type preflightPurpose int
+
+const (
+	// preflightInspect only reports the state, for Preflight
+	preflightInspect preflightPurpose = iota
+	// preflightDeploy fails if the Safe is already deployed
+	preflightDeploy
+	// preflightExecute fails, or deploys with autoDeploy, if the Safe is not deployed
+	preflightExecute
+)
+
+// preflightOptions configure a pre-flight check
+type preflightOptions struct {
+	purpose preflightPurpose
+	// safeAddress is the Safe to check, empty for the derived Safe
+	safeAddress string
+	// nonceAddress is the address the nonce is fetched for
+	nonceAddress string
+	// forceSubmit skips the health gate
+	forceSubmit bool
+	// skipDeployedCheck trusts that the Safe is deployed (Execute only)
+	skipDeployedCheck bool
+	// autoDeploy deploys a missing Safe and waits for it (Execute only)
+	autoDeploy bool
+}
+
+// Preflight reports the signer's Safe, whether it is deployed and the nonce
+// the next Deploy or Execute would sign with, as they would see them. It
+// shares their caches and submits nothing.
+func (c *RelayClient) Preflight() (PreflightInfo, error) {
+	if err := c.assertSignerNeeded(); err != nil {
+		return PreflightInfo{}, err
+	}
+
+	op := newOperation("Preflight", 0)
+	defer op.cancel()
+
+	info, err := c.preflight(op, preflightOptions{
+		purpose:      preflightInspect,
+		nonceAddress: c.signer.AddressHex(),
+		forceSubmit:  true,
+	})
+	if err != nil {
+		return PreflightInfo{}, op.tag(err)
+	}
+	return *info, nil
+}
+
+// preflight runs the checks before a Deploy or Execute within op's budget:
+// it resolves the Safe, derived once per contract config, gates on the
+// relayer's health, checks the Safe's deployment through the deployed
+// cache and fetches the nonce the operation signs with
+func (c *RelayClient) preflight(op *operation, opts preflightOptions) (*PreflightInfo, error) {
+	info := &PreflightInfo{SignerAddress: c.signer.AddressHex(), SafeAddress: opts.safeAddress}
+	if info.SafeAddress == "" {
+		derived, err := c.expectedSafe()
+		if err != nil {
+			c.logger.Printf("Error deriving Safe address: %v", err)
+			return nil, errors.ErrStaged(errors.StageDerive, err)
+		}
+		info.SafeAddress = derived
+	}
+
+	// Fail fast if the builder key is known to be unable to submit
+	if opts.purpose != preflightInspect {
+		if err := c.checkKeyScope(models.KeyScopeSubmit); err != nil {
+			return nil, err
+		}
+	}
+
+	// Fail fast if the relayer reports itself unavailable
+	if c.healthGate && !opts.forceSubmit {
+		if err := op.run(stepHealthCheck, c.checkHealthGate); err != nil {
+			return nil, err
+		}
+	}
+
+	switch {
+	case opts.purpose == preflightExecute && opts.skipDeployedCheck:
+		info.Deployed = true
+	case opts.purpose == preflightExecute:
+		// Fail fast with a clear error instead of a relayer nonce or
+		// signature rejection when the Safe does not exist yet
+		if err := c.ensureDeployed(op, info.SafeAddress, opts.autoDeploy, opts.forceSubmit); err != nil {
+			return nil, err
+		}
+		info.Deployed = true
+	default:
+		// A failed check counts as "not deployed" for a deployment; the
+		// relayer rejects duplicates
+		err := op.run(stepDeployedCheck, func(ctx context.Context) error {
+			var checkErr error
+			info.Deployed, checkErr = c.isDeployed(ctx, info.SafeAddress, opts.purpose != preflightDeploy)
+			return checkErr
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.purpose == preflightDeploy {
+		if info.Deployed {
+			errMsg := fmt.Sprintf("Safe already deployed at %s", info.SafeAddress)
+			c.logger.Println(errMsg)
+			return nil, errors.ErrStaged(errors.StageDeployedCheck, errors.NewRelayerClientError(errMsg, nil))
+		}
+		c.logger.Println("Safe not yet deployed, proceeding with deployment")
+	}
+
+	// For SAFE-CREATE transactions, nonce is always "0" for the EOA signature
+	// The relayer will handle the actual nonce internally
+	if !info.Deployed {
+		info.Nonce = "0"
+		return info, nil
+	}
+	err := op.run(stepNonce, func(ctx context.Context) error {
+		nonceResp, nonceErr := c.getCachedNonce(ctx, opts.nonceAddress, string(c.nonceSignerType), ReadOptions{})
+		if nonceErr != nil {
+			return nonceErr
+		}
+		info.Nonce = nonceResp.Nonce
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// expectedSafe returns the signer's derived Safe, deriving it again only
+// when the contract config has changed
+func (c *RelayClient) expectedSafe() (string, error) {
+	contractConfig := c.currentContractConfig()
+
+	c.expectedSafeMu.Lock()
+	defer c.expectedSafeMu.Unlock()
+	if c.expectedSafeAddress != "" && c.expectedSafeConfig == contractConfig {
+		return c.expectedSafeAddress, nil
+	}
+
+	safeAddress, err := c.deriveSafe(c.signer.Address(), contractConfig)
+	if err != nil {
+		return "", err
+	}
+	c.expectedSafeAddress, c.expectedSafeConfig = safeAddress.Hex(), contractConfig
+	return c.expectedSafeAddress, nil
+}
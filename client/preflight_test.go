@@ -0,0 +1,115 @@
+package client
+
+import (
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/davidt58/go-builder-relayer-client/config"
+	"github.com/davidt58/go-builder-relayer-client/models"
+	"github.com/davidt58/go-builder-relayer-client/relayertest"
+)
+
+// countNonceRequests counts GET_NONCE requests while serving the default response
+func countNonceRequests(relayer *fakeRelayer) func() int {
+	var calls int32
+	relayer.Handle(GET_NONCE, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		relayer.ServeDefault(w, r)
+	})
+	return func() int { return int(atomic.LoadInt32(&calls)) }
+}
+
+// countDerivations counts the Safe address derivations of c
+func countDerivations(c *RelayClient) func() int {
+	var calls int32
+	derive := c.deriveSafe
+	c.deriveSafe = func(signerAddress common.Address, contractConfig *config.ContractConfig) (common.Address, error) {
+		atomic.AddInt32(&calls, 1)
+		return derive(signerAddress, contractConfig)
+	}
+	return func() int { return int(atomic.LoadInt32(&calls)) }
+}
+
+func TestPreflight(t *testing.T) {
+	tests := []struct {
+		name       string
+		deployed   bool
+		wantNonce  string
+		wantNonces int
+	}{
+		{name: "deployed", deployed: true, wantNonce: "0", wantNonces: 1},
+		{name: "not deployed", wantNonce: "0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			relayer := newFakeRelayer(t)
+			relayer.SetDeployed(tt.deployed)
+			deployedChecks, nonces := countDeployedChecks(relayer), countNonceRequests(relayer)
+			c := newTestClient(t, relayer)
+
+			info, err := c.Preflight()
+			if err != nil {
+				t.Fatalf("Preflight failed: %v", err)
+			}
+			want := PreflightInfo{SignerAddress: relayertest.TestAddress, SafeAddress: relayertest.TestSafeAddress(137), Deployed: tt.deployed, Nonce: tt.wantNonce}
+			if info != want {
+				t.Errorf("Preflight = %+v, want %+v", info, want)
+			}
+			if nonces() != tt.wantNonces {
+				t.Errorf("fetched the nonce %d times, want %d", nonces(), tt.wantNonces)
+			}
+
+			// The deployed status is cached for the next Preflight, Deploy or Execute
+			if _, err := c.Preflight(); err != nil {
+				t.Fatalf("second Preflight failed: %v", err)
+			}
+			if deployedChecks() != 1 {
+				t.Errorf("checked deployment %d times, want 1", deployedChecks())
+			}
+			if len(relayer.Submissions()) != 0 {
+				t.Errorf("Preflight submitted %d transactions", len(relayer.Submissions()))
+			}
+		})
+	}
+}
+
+func TestDeployThenExecute_RequestCounts(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	relayer.SetDeployed(false)
+	relayer.ScriptStates(models.STATE_NEW, models.STATE_MINED)
+	deployedChecks, nonces := countDeployedChecks(relayer), countNonceRequests(relayer)
+	c := newTestClient(t, relayer)
+	derivations := countDerivations(c)
+
+	response, err := c.Deploy()
+	if err != nil {
+		t.Fatalf("Deploy failed: %v", err)
+	}
+	if _, err := response.WaitUntilMined(); err != nil {
+		t.Fatalf("Wait failed: %v", err)
+	}
+	if deployedChecks() != 1 || nonces() != 0 || derivations() != 1 {
+		t.Errorf("Deploy: %d deployed checks, %d nonce requests, %d derivations; want 1, 0, 1", deployedChecks(), nonces(), derivations())
+	}
+
+	// The mined deployment is remembered: Execute only fetches the nonce
+	relayer.SetDeployed(true)
+	if _, err := c.Execute(testTransactions(), ""); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if deployedChecks() != 1 || nonces() != 1 || derivations() != 1 {
+		t.Errorf("Deploy and Execute: %d deployed checks, %d nonce requests, %d derivations; want 1, 1, 1", deployedChecks(), nonces(), derivations())
+	}
+
+	types := make([]string, 0, 2)
+	for _, submitted := range relayer.Submissions() {
+		types = append(types, submitted.Type)
+	}
+	if len(types) != 2 || types[0] != string(models.SAFE_CREATE) || types[1] != string(models.SAFE) {
+		t.Errorf("submitted %v, want a SAFE-CREATE then a SAFE", types)
+	}
+}
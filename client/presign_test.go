@@ -0,0 +1,107 @@
+package client
+
+import (
+	"bytes"
+	"io"
+	"math/big"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/davidt58/go-builder-relayer-client/builder"
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+// recordSubmitBodies captures the raw body of every submission before the
+// default handler processes it
+func recordSubmitBodies(relayer *fakeRelayer) func() [][]byte {
+	var mu sync.Mutex
+	var bodies [][]byte
+	relayer.Handle(SUBMIT_TRANSACTION, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		bodies = append(bodies, body)
+		mu.Unlock()
+
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		relayer.ServeDefault(w, r)
+	})
+	return func() [][]byte {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([][]byte(nil), bodies...)
+	}
+}
+
+func TestSubmitSignedRequest_MatchesLiveExecute(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	bodies := recordSubmitBodies(relayer)
+	c := newTestClient(t, relayer)
+
+	safeAddress, err := c.GetExpectedSafe()
+	if err != nil {
+		t.Fatalf("GetExpectedSafe failed: %v", err)
+	}
+	transfer := *models.NewSafeTransaction("0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174", "0", "0x")
+	items := [][]models.SafeTransaction{{transfer}, {transfer, transfer}, {transfer}}
+
+	signed, err := builder.PreSignBatch(safeAddress, big.NewInt(0), items, c.signer, 137, c.contractConfig.SafeMultisend)
+	if err != nil {
+		t.Fatalf("PreSignBatch failed: %v", err)
+	}
+	for k, item := range signed {
+		response, err := c.SubmitSignedRequest(item.Request)
+		if err != nil {
+			t.Fatalf("item %d: SubmitSignedRequest failed: %v", k, err)
+		}
+		if want := "tx-nonce-" + item.Nonce.String(); response.TransactionID != want {
+			t.Errorf("item %d: TransactionID = %s, want %s", k, response.TransactionID, want)
+		}
+	}
+
+	// Build the same items live, each with the relayer at nonce k
+	for k := range items {
+		liveRelayer := newFakeRelayer(t)
+		liveRelayer.SetNonce(int64(k))
+		liveBodies := recordSubmitBodies(liveRelayer)
+		if _, err := newTestClient(t, liveRelayer).Execute(items[k], ""); err != nil {
+			t.Fatalf("item %d: live Execute failed: %v", k, err)
+		}
+
+		if got, want := bodies()[k], liveBodies()[0]; !bytes.Equal(got, want) {
+			t.Errorf("item %d: pre-signed body differs from live Execute:\n got %s\nwant %s", k, got, want)
+		}
+	}
+}
+
+func TestSubmitSignedRequest_Invalid(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	c := newTestClient(t, relayer)
+
+	readOnly, err := NewRelayClient(relayer.URL(), 137, "", nil)
+	if err != nil {
+		t.Fatalf("NewRelayClient failed: %v", err)
+	}
+	signedRequest := &models.TransactionRequest{Type: string(models.SAFE), Signature: "0x01"}
+
+	tests := []struct {
+		name    string
+		client  *RelayClient
+		request *models.TransactionRequest
+	}{
+		{name: "nil request", client: c},
+		{name: "unsigned request", client: c, request: &models.TransactionRequest{Type: string(models.SAFE)}},
+		{name: "no builder credentials", client: readOnly, request: signedRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.client.SubmitSignedRequest(tt.request); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+	if len(relayer.Submissions()) != 0 {
+		t.Errorf("Expected nothing submitted, got %d", len(relayer.Submissions()))
+	}
+}
@@ -0,0 +1,406 @@
+package client
+
+import (
+	"context"
+	stderrors "errors"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/davidt58/go-builder-relayer-client/config"
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/http"
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+// DefaultQueueMaxRetries is the number of times a queued submission is retried
+// (with a freshly fetched nonce) before the failure is surfaced on its handle.
+// Only failures that may succeed on a retry count (see retryableSubmission);
+// any other failure is surfaced at once.
+const DefaultQueueMaxRetries = 2
+
+// ErrQueueClosed is returned when enqueueing into a queue that has been shut down
+var ErrQueueClosed = errors.ErrQueueClosed
+
+// SubmissionHandle is returned by EnqueueExecute and resolves once the queued
+// transactions have been submitted (or have failed)
+type SubmissionHandle struct {
+	transactions []models.SafeTransaction
+	metadata     string
+	// independent submits each transaction separately with consecutive nonces
+	independent bool
+
+	done      chan struct{}
+	nonce     string
+	response  *models.ClientRelayerTransactionResponse
+	responses []*models.ClientRelayerTransactionResponse
+	err       error
+}
+
+// Done returns a channel that is closed when the submission has completed
+func (h *SubmissionHandle) Done() <-chan struct{} {
+	return h.done
+}
+
+// Result blocks until the submission has completed and returns its outcome
+func (h *SubmissionHandle) Result() (*models.ClientRelayerTransactionResponse, error) {
+	<-h.done
+	return h.response, h.err
+}
+
+// Nonce blocks until the submission has completed and returns the Safe nonce
+// that was assigned to it (empty if no nonce could be assigned)
+func (h *SubmissionHandle) Nonce() string {
+	<-h.done
+	return h.nonce
+}
+
+// complete records the outcome and releases any waiters
+func (h *SubmissionHandle) complete(nonce string, response *models.ClientRelayerTransactionResponse, err error) {
+	h.nonce = nonce
+	h.response = response
+	h.err = err
+	close(h.done)
+}
+
+// SubmissionQueue serializes Execute calls through a single worker so that
+// concurrent callers are assigned consecutive Safe nonces
+type SubmissionQueue struct {
+	client     *RelayClient
+	maxRetries int
+
+	mu      sync.Mutex
+	pending []*SubmissionHandle
+	closed  bool
+	wake    chan struct{}
+	stopped chan struct{}
+
+	// nextNonce is the nonce the next submission will use; nil means it must
+	// be fetched from the relayer
+	nextNonce *big.Int
+}
+
+// NewSubmissionQueue creates a queue bound to the given client and starts its
+// worker, which the client's Close shuts down. The queue of a closed client
+// is closed from the start.
+func NewSubmissionQueue(client *RelayClient) *SubmissionQueue {
+	q := &SubmissionQueue{
+		client:     client,
+		maxRetries: DefaultQueueMaxRetries,
+		wake:       make(chan struct{}, 1),
+		stopped:    make(chan struct{}),
+	}
+	if err := client.lifecycle.register("submission queue", q.Shutdown); err != nil {
+		q.closed = true
+		close(q.stopped)
+		return q
+	}
+	go q.run()
+	return q
+}
+
+// SetMaxRetries sets how many times a submission failing with a retryable
+// error is retried before the error is reported on its handle
+func (q *SubmissionQueue) SetMaxRetries(maxRetries int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if maxRetries < 0 {
+		maxRetries = 0
+	}
+	q.maxRetries = maxRetries
+}
+
+// Enqueue adds transactions to the queue and returns immediately with a handle
+func (q *SubmissionQueue) Enqueue(transactions []models.SafeTransaction, metadata string) (*SubmissionHandle, error) {
+	return q.enqueue(transactions, metadata, false)
+}
+
+// enqueue adds a handle to the queue
+func (q *SubmissionQueue) enqueue(transactions []models.SafeTransaction, metadata string, independent bool) (*SubmissionHandle, error) {
+	if len(transactions) == 0 {
+		return nil, errors.NewRelayerClientError("no transactions provided", nil)
+	}
+	if err := q.checkLimits(transactions, metadata, independent); err != nil {
+		return nil, err
+	}
+
+	handle := &SubmissionHandle{
+		transactions: transactions,
+		metadata:     metadata,
+		independent:  independent,
+		done:         make(chan struct{}),
+	}
+
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		if err := q.client.lifecycle.err(); err != nil {
+			return nil, err
+		}
+		return nil, ErrQueueClosed
+	}
+	q.pending = append(q.pending, handle)
+	q.mu.Unlock()
+
+	q.signal()
+	return handle, nil
+}
+
+// checkLimits checks the requests a handle will submit against the client's
+// relayer limits: one batch, or one request per transaction if independent
+func (q *SubmissionQueue) checkLimits(transactions []models.SafeTransaction, metadata string, independent bool) error {
+	if !independent {
+		return q.client.checkExecuteLimits(transactions, models.MetadataOf(metadata), config.MultisendStandard)
+	}
+	for i := range transactions {
+		if err := q.client.checkExecuteLimits(transactions[i:i+1], models.MetadataOf(metadata), config.MultisendStandard); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Shutdown stops accepting new submissions and waits for the queued ones to
+// drain. If ctx expires first, the remaining handles are failed with the
+// context error and ctx.Err() is returned.
+func (q *SubmissionQueue) Shutdown(ctx context.Context) error {
+	q.mu.Lock()
+	q.closed = true
+	q.mu.Unlock()
+	q.signal()
+
+	select {
+	case <-q.stopped:
+		return nil
+	case <-ctx.Done():
+		q.mu.Lock()
+		remaining := q.pending
+		q.pending = nil
+		q.mu.Unlock()
+		for _, handle := range remaining {
+			handle.complete("", nil, ctx.Err())
+		}
+		return ctx.Err()
+	}
+}
+
+// signal wakes the worker without blocking
+func (q *SubmissionQueue) signal() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// run is the single worker that drains the queue in FIFO order
+func (q *SubmissionQueue) run() {
+	defer close(q.stopped)
+
+	for {
+		q.mu.Lock()
+		if len(q.pending) == 0 {
+			closed := q.closed
+			q.mu.Unlock()
+			if closed {
+				return
+			}
+			<-q.wake
+			continue
+		}
+		handle := q.pending[0]
+		q.pending = q.pending[1:]
+		maxRetries := q.maxRetries
+		q.mu.Unlock()
+
+		if handle.independent {
+			q.processIndependent(handle, maxRetries)
+		} else {
+			q.process(handle, maxRetries)
+		}
+	}
+}
+
+// process submits a single handle, retrying with a refreshed nonce on failure
+func (q *SubmissionQueue) process(handle *SubmissionHandle, maxRetries int) {
+	var lastErr error
+	var nonceStr string
+
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		nonce, err := q.reserveNonce()
+		if err != nil {
+			lastErr = err
+			if !retryableSubmission(err) {
+				break
+			}
+			continue
+		}
+		nonceStr = nonce.String()
+
+		response, err := q.client.executeWithNonce(handle.transactions, handle.metadata, nonceStr)
+		if err != nil {
+			// The relayer may have a different view of the nonce now; refetch it
+			q.nextNonce = nil
+			lastErr = err
+			if !retryableSubmission(err) {
+				break
+			}
+			continue
+		}
+
+		q.nextNonce = new(big.Int).Add(nonce, big.NewInt(1))
+		handle.complete(nonceStr, response, nil)
+		return
+	}
+
+	handle.complete(nonceStr, nil, lastErr)
+}
+
+// processIndependent submits each transaction of a handle as its own Safe
+// transaction with consecutive nonces. Only fetching the starting nonce is
+// retried; a failed submission stops the sequence, since the relayer would
+// reject the later nonces, and is reported as a PartialExecutionError.
+func (q *SubmissionQueue) processIndependent(handle *SubmissionHandle, maxRetries int) {
+	total := len(handle.transactions)
+
+	var nonce *big.Int
+	var err error
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		if nonce, err = q.reserveNonce(); err == nil || !retryableSubmission(err) {
+			break
+		}
+	}
+	if err != nil {
+		handle.complete("", nil, errors.ErrPartialExecution(total, 0, "", err))
+		return
+	}
+	startNonce := nonce.String()
+
+	for i, transaction := range handle.transactions {
+		nonceStr := nonce.String()
+		response, err := q.client.executeWithNonce([]models.SafeTransaction{transaction}, handle.metadata, nonceStr)
+		if err != nil {
+			q.nextNonce = nil
+			handle.complete(startNonce, nil, errors.ErrPartialExecution(total, i, nonceStr, err))
+			return
+		}
+
+		handle.responses = append(handle.responses, response)
+		nonce = new(big.Int).Add(nonce, big.NewInt(1))
+		q.nextNonce = new(big.Int).Set(nonce)
+	}
+
+	handle.complete(startNonce, handle.responses[total-1], nil)
+}
+
+// retryableSubmission reports whether a queued submission that failed with
+// err may succeed when retried with a freshly fetched nonce: rate limiting,
+// 5xx responses, errors classified by http.RetryableError, and nonces the
+// relayer rejected because another submission took them. Validation, policy,
+// auth and other 4xx failures would fail again and are not retried.
+func retryableSubmission(err error) bool {
+	var stale *errors.StaleNonceError
+	var notCurrent *errors.NonceNotYetCurrentError
+	if stderrors.As(err, &stale) || stderrors.As(err, &notCurrent) {
+		return true
+	}
+
+	var apiErr *errors.RelayerApiError
+	if stderrors.As(err, &apiErr) {
+		if apiErr.StatusCode == 429 || apiErr.StatusCode >= 500 {
+			return true
+		}
+		return (apiErr.StatusCode == 400 || apiErr.StatusCode == 409) && strings.Contains(strings.ToLower(apiErr.Message), "nonce")
+	}
+	return http.RetryableError(err)
+}
+
+// reserveNonce returns the nonce for the next submission, fetching it from
+// the relayer when the local counter is not initialised
+func (q *SubmissionQueue) reserveNonce() (*big.Int, error) {
+	if q.nextNonce != nil {
+		return new(big.Int).Set(q.nextNonce), nil
+	}
+
+	if err := q.client.assertSignerNeeded(); err != nil {
+		return nil, err
+	}
+
+	nonceResp, err := q.client.GetNonceWithOptions(q.client.signer.AddressHex(), string(q.client.nonceSignerType), ReadOptions{SkipCache: true})
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, ok := new(big.Int).SetString(nonceResp.Nonce, 10)
+	if !ok {
+		return nil, errors.ErrInvalidResponse("nonce is not a valid integer: " + nonceResp.Nonce)
+	}
+
+	return nonce, nil
+}
+
+// EnqueueExecute queues transactions for sequential submission through the
+// client's SubmissionQueue, creating the queue on first use
+func (c *RelayClient) EnqueueExecute(transactions []models.SafeTransaction, metadata string) (*SubmissionHandle, error) {
+	if err := c.assertSignerNeeded(); err != nil {
+		return nil, err
+	}
+	if err := c.assertBuilderCredsNeeded(); err != nil {
+		return nil, err
+	}
+
+	return c.SubmissionQueue().Enqueue(transactions, metadata)
+}
+
+// ExecuteIndependent submits each transaction as a separate Safe transaction
+// (no multisend), so one failing on-chain does not revert the others. The
+// Safe nonce n is fetched once and the transactions are submitted in order
+// with nonces n, n+1, ..., n+N-1 through the client's SubmissionQueue, so
+// concurrent EnqueueExecute calls cannot take a nonce from the middle of the
+// sequence. If a submission fails the sequence stops and a
+// PartialExecutionError is returned together with the responses of the
+// transactions already submitted; retry with transactions[FailedIndex:].
+func (c *RelayClient) ExecuteIndependent(transactions []models.SafeTransaction, metadata string) ([]*models.ClientRelayerTransactionResponse, error) {
+	if err := c.assertSignerNeeded(); err != nil {
+		return nil, err
+	}
+	if err := c.assertBuilderCredsNeeded(); err != nil {
+		return nil, err
+	}
+
+	handle, err := c.SubmissionQueue().enqueue(transactions, metadata, true)
+	if err != nil {
+		return nil, err
+	}
+
+	<-handle.done
+	return handle.responses, handle.err
+}
+
+// SubmissionQueue returns the client's submission queue, creating it on first use
+func (c *RelayClient) SubmissionQueue() *SubmissionQueue {
+	c.queueMu.Lock()
+	defer c.queueMu.Unlock()
+	if c.queue == nil {
+		c.queue = NewSubmissionQueue(c)
+	}
+	return c.queue
+}
+
+// Shutdown drains the client's submission queue (if one was started) and
+// closes it for good: later EnqueueExecute and ExecuteIndependent calls fail
+// with ErrQueueClosed. It then closes idle connections to the relayer. Only
+// direct calls such as Execute keep working; Close shuts the client down
+// entirely.
+func (c *RelayClient) Shutdown(ctx context.Context) error {
+	c.queueMu.Lock()
+	queue := c.queue
+	c.queueMu.Unlock()
+
+	if queue != nil {
+		if err := queue.Shutdown(ctx); err != nil {
+			return err
+		}
+	}
+	c.CloseIdleConnections()
+	return nil
+}
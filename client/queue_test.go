@@ -0,0 +1,161 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+func testTransactions() []models.SafeTransaction {
+	return []models.SafeTransaction{
+		*models.NewSafeTransaction("0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174", "0", "0x"),
+	}
+}
+
+func TestSubmissionQueue_ConcurrentEnqueue(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	c := newTestClient(t, relayer)
+
+	const count = 20
+	handles := make([]*SubmissionHandle, count)
+
+	var wg sync.WaitGroup
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			handle, err := c.EnqueueExecute(testTransactions(), "")
+			if err != nil {
+				t.Errorf("EnqueueExecute failed: %v", err)
+				return
+			}
+			handles[i] = handle
+		}(i)
+	}
+	wg.Wait()
+
+	seen := make(map[string]bool)
+	for _, handle := range handles {
+		if handle == nil {
+			t.Fatal("missing handle")
+		}
+		response, err := handle.Result()
+		if err != nil {
+			t.Fatalf("submission failed: %v", err)
+		}
+		if response.TransactionID != "tx-nonce-"+handle.Nonce() {
+			t.Errorf("TransactionID = %s, want result for nonce %s", response.TransactionID, handle.Nonce())
+		}
+		if seen[handle.Nonce()] {
+			t.Errorf("nonce %s assigned twice", handle.Nonce())
+		}
+		seen[handle.Nonce()] = true
+	}
+
+	submitted := relayer.Submissions()
+	if len(submitted) != count {
+		t.Fatalf("submitted %d transactions, want %d", len(submitted), count)
+	}
+	for i, request := range submitted {
+		if request.Nonce == nil || *request.Nonce != strconv.Itoa(i) {
+			t.Errorf("submission %d nonce = %v, want %d", i, request.Nonce, i)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := c.Shutdown(ctx); err != nil {
+		t.Errorf("Shutdown failed: %v", err)
+	}
+	if _, err := c.EnqueueExecute(testTransactions(), ""); err != ErrQueueClosed {
+		t.Errorf("EnqueueExecute after Shutdown error = %v, want ErrQueueClosed", err)
+	}
+}
+
+func TestSubmissionQueue_RetriesWithFreshNonce(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	c := newTestClient(t, relayer)
+
+	// Another process consumes nonces behind the queue's back
+	first, err := c.EnqueueExecute(testTransactions(), "")
+	if err != nil {
+		t.Fatalf("EnqueueExecute failed: %v", err)
+	}
+	if _, err := first.Result(); err != nil {
+		t.Fatalf("first submission failed: %v", err)
+	}
+
+	relayer.SetNonce(relayer.Nonce() + 3)
+
+	second, err := c.EnqueueExecute(testTransactions(), "")
+	if err != nil {
+		t.Fatalf("EnqueueExecute failed: %v", err)
+	}
+	<-second.Done()
+	if _, err := second.Result(); err != nil {
+		t.Fatalf("second submission failed: %v", err)
+	}
+	if second.Nonce() != "4" {
+		t.Errorf("Nonce = %s, want 4", second.Nonce())
+	}
+}
+
+func TestSubmissionQueue_SurfacesFailure(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	c := newTestClient(t, relayer)
+	c.SubmissionQueue().SetMaxRetries(0)
+
+	handle, err := c.EnqueueExecute([]models.SafeTransaction{
+		*models.NewSafeTransaction("0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174", "0", "0xzz"),
+	}, "")
+	if err != nil {
+		t.Fatalf("EnqueueExecute failed: %v", err)
+	}
+	if _, err := handle.Result(); err == nil {
+		t.Error("expected error for invalid transaction data")
+	}
+}
+
+func TestSubmissionQueue_RetriesOnlyTransientFailures(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       int
+		message      string
+		wantAttempts int32
+	}{
+		{"unauthorized", http.StatusUnauthorized, "invalid signature", 1},
+		{"validation", http.StatusBadRequest, "invalid transaction data", 1},
+		{"unavailable", http.StatusServiceUnavailable, "upstream unavailable", DefaultQueueMaxRetries + 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			relayer := newFakeRelayer(t)
+			var attempts int32
+			relayer.Handle(SUBMIT_TRANSACTION, func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&attempts, 1)
+				w.WriteHeader(tt.status)
+				json.NewEncoder(w).Encode(models.ErrorResponse{Error: tt.message})
+			})
+			c := newTestClient(t, relayer)
+
+			handle, err := c.EnqueueExecute(testTransactions(), "")
+			if err != nil {
+				t.Fatalf("EnqueueExecute failed: %v", err)
+			}
+			if _, err := handle.Result(); err == nil {
+				t.Fatal("expected the submission to fail")
+			}
+			if got := atomic.LoadInt32(&attempts); got != tt.wantAttempts {
+				t.Errorf("submitted %d times, want %d", got, tt.wantAttempts)
+			}
+		})
+	}
+}
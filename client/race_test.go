@@ -0,0 +1,7 @@
+//go:build race
+
+package client
+
+// raceEnabled reports whether the tests run with the race detector, whose
+// instrumentation allocates
+const raceEnabled = true
@@ -0,0 +1,218 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/davidt58/go-builder-relayer-client/builder"
+	"github.com/davidt58/go-builder-relayer-client/config"
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/http"
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+// ReadOnlyClient makes the relayer's unauthenticated read calls and derives
+// Safe addresses. It holds no signer or builder credentials and has no
+// submit methods, so code holding one cannot sign or send transactions.
+// RelayClient embeds it.
+type ReadOnlyClient struct {
+	relayerURL string
+	chainID    int64
+	httpClient *http.Client
+
+	// contractConfig is the chain's registered config as of configVersion,
+	// with configOverrides applied; it is refreshed when the registry changes
+	configMu        sync.RWMutex
+	contractConfig  *config.ContractConfig
+	configVersion   uint64
+	configOverrides []func(*config.ContractConfig)
+
+	// txCache holds the last response and validators of each transaction
+	// fetched, for conditional GetTransaction requests
+	txCacheMu    sync.Mutex
+	txCache      map[string]*cachedTransaction
+	txCacheOrder []string
+
+	// longPollWait enables long-polling GET_TRANSACTION for up to that long
+	// per request once the relayer's capabilities confirm support;
+	// longPollProbing is set while a caller reads them
+	longPollMu      sync.Mutex
+	longPollWait    time.Duration
+	longPollState   longPollSupport
+	longPollProbing bool
+
+	// crossChainReads accepts transactions the relayer reports for other chains
+	crossChainReads bool
+
+	// responseCache holds GetDeployed and GetNonce responses; nil disables
+	// it (see WithResponseCache)
+	responseCache *responseCache
+}
+
+// NewReadOnlyClient creates a ReadOnlyClient for the relayer at relayerURL
+// httpOpts can customise the HTTP client (e.g. http.WithPinnedCertificates)
+func NewReadOnlyClient(relayerURL string, chainID int64, httpOpts ...http.ClientOption) (*ReadOnlyClient, error) {
+	if relayerURL == "" {
+		return nil, errors.ErrMissingRequiredField("relayerURL")
+	}
+
+	contractConfig, version, err := config.GetContractConfigVersion(chainID)
+	if err != nil {
+		return nil, err
+	}
+
+	httpClient, err := http.NewClientWithOptions(relayerURL, httpOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReadOnlyClient{
+		relayerURL:     relayerURL,
+		chainID:        chainID,
+		contractConfig: contractConfig,
+		configVersion:  version,
+		httpClient:     httpClient,
+	}, nil
+}
+
+// CloseIdleConnections closes the client's idle connections to the relayer
+func (c *ReadOnlyClient) CloseIdleConnections() {
+	c.httpClient.CloseIdleConnections()
+}
+
+// DeriveSafeAddressFor derives the Safe address owned by signerAddress on
+// the client's chain
+func (c *ReadOnlyClient) DeriveSafeAddressFor(signerAddress common.Address) (common.Address, error) {
+	return builder.DeriveSafeAddressWithConfig(signerAddress, c.currentContractConfig())
+}
+
+// GetNonce retrieves the nonce for the signer, from the response cache when
+// enabled (see WithResponseCache)
+// signerType must be a built-in SignerType or one added with models.RegisterSignerType
+func (c *ReadOnlyClient) GetNonce(signerAddress, signerType string) (*models.NonceResponse, error) {
+	return c.GetNonceWithOptions(signerAddress, signerType, ReadOptions{})
+}
+
+// getNonce retrieves the nonce for the signer, aborting when ctx is done
+func (c *ReadOnlyClient) getNonce(ctx context.Context, signerAddress, signerType string) (*models.NonceResponse, error) {
+	var response models.NonceResponse
+	if err := c.getEndpoint(ctx, getNonceRequest{signerAddress: signerAddress, signerType: signerType}, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// GetTransaction retrieves a transaction by ID. A transaction on another
+// chain is returned as an errors.TransactionChainIDMismatchError unless
+// WithCrossChainReads is set.
+func (c *ReadOnlyClient) GetTransaction(transactionID string) (*models.RelayerTransaction, error) {
+	return c.getTransaction(context.Background(), transactionID)
+}
+
+// getTransaction retrieves a transaction by ID, aborting when ctx is done.
+// It sends the validators of the previous response, so an unchanged
+// transaction costs the relayer a 304 and is not parsed again.
+func (c *ReadOnlyClient) getTransaction(ctx context.Context, transactionID string) (*models.RelayerTransaction, error) {
+	return c.fetchTransaction(ctx, transactionID, 0)
+}
+
+// GetTransactionInto is GetTransaction decoding into out, for polling
+// loops that reuse one RelayerTransaction instead of allocating one per call.
+// out is overwritten only when the call succeeds.
+func (c *ReadOnlyClient) GetTransactionInto(transactionID string, out *models.RelayerTransaction) error {
+	if out == nil {
+		return errors.ErrMissingRequiredField("out")
+	}
+	return c.fetchTransactionInto(context.Background(), transactionID, 0, out)
+}
+
+// GetDeployed checks if a Safe wallet is deployed, from the response cache
+// when enabled (see WithResponseCache)
+func (c *ReadOnlyClient) GetDeployed(safeAddress string) (bool, error) {
+	return c.GetDeployedWithOptions(safeAddress, ReadOptions{})
+}
+
+// getDeployed checks if a Safe wallet is deployed, aborting when ctx is done
+func (c *ReadOnlyClient) getDeployed(ctx context.Context, safeAddress string) (bool, error) {
+	var response models.DeployedResponse
+	if err := c.getEndpoint(ctx, getDeployedRequest{safeAddress: safeAddress}, &response); err != nil {
+		return false, err
+	}
+
+	return response.Deployed, nil
+}
+
+// GetChainID returns the chain ID
+func (c *ReadOnlyClient) GetChainID() int64 {
+	return c.chainID
+}
+
+// GetRelayerURL returns the relayer URL
+func (c *ReadOnlyClient) GetRelayerURL() string {
+	return c.relayerURL
+}
+
+// GetContractConfig returns the contract configuration
+func (c *ReadOnlyClient) GetContractConfig() *config.ContractConfig {
+	return c.currentContractConfig()
+}
+
+// RefreshContractConfig reloads the chain's contract config from the
+// registry. Clients refresh on their own when config.ConfigVersion changes;
+// call it to apply a reload immediately.
+func (c *ReadOnlyClient) RefreshContractConfig() error {
+	registered, version, err := config.GetContractConfigVersion(c.chainID)
+	if err != nil {
+		return err
+	}
+
+	c.configMu.Lock()
+	defer c.configMu.Unlock()
+	if version < c.configVersion {
+		// A concurrent refresh already loaded a newer config
+		return nil
+	}
+	contractConfig := registered
+	if len(c.configOverrides) > 0 {
+		copied := *registered
+		for _, override := range c.configOverrides {
+			override(&copied)
+		}
+		contractConfig = &copied
+	}
+	c.contractConfig = contractConfig
+	c.configVersion = version
+	return nil
+}
+
+// currentContractConfig returns the contract config, first refreshing it if
+// the registry changed since it was loaded. If the refresh fails the loaded
+// config is kept.
+func (c *ReadOnlyClient) currentContractConfig() *config.ContractConfig {
+	c.configMu.RLock()
+	contractConfig, stale := c.contractConfig, c.configVersion != config.ConfigVersion()
+	c.configMu.RUnlock()
+	if !stale || c.RefreshContractConfig() != nil {
+		return contractConfig
+	}
+
+	c.configMu.RLock()
+	defer c.configMu.RUnlock()
+	return c.contractConfig
+}
+
+// overrideContractConfig applies override to the client's contract config
+// now and after every refresh. The registered config is not modified.
+func (c *ReadOnlyClient) overrideContractConfig(override func(*config.ContractConfig)) {
+	c.configMu.Lock()
+	defer c.configMu.Unlock()
+
+	copied := *c.contractConfig
+	override(&copied)
+	c.contractConfig = &copied
+	c.configOverrides = append(c.configOverrides, override)
+}
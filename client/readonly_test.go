@@ -0,0 +1,284 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/davidt58/go-builder-relayer-client/builder"
+	"github.com/davidt58/go-builder-relayer-client/config"
+	"github.com/davidt58/go-builder-relayer-client/models"
+	"github.com/davidt58/go-builder-relayer-client/signer"
+)
+
+func TestNewReadOnlyClient(t *testing.T) {
+	tests := []struct {
+		name       string
+		relayerURL string
+		chainID    int64
+		shouldErr  bool
+	}{
+		{name: "polygon", relayerURL: "http://localhost", chainID: 137},
+		{name: "amoy", relayerURL: "http://localhost", chainID: 80002},
+		{name: "missing URL", chainID: 137, shouldErr: true},
+		{name: "unknown chain", relayerURL: "http://localhost", chainID: 999, shouldErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewReadOnlyClient(tt.relayerURL, tt.chainID)
+			if (err != nil) != tt.shouldErr {
+				t.Fatalf("NewReadOnlyClient() error = %v, shouldErr %v", err, tt.shouldErr)
+			}
+			if err == nil && c.GetChainID() != tt.chainID {
+				t.Errorf("GetChainID() = %d, want %d", c.GetChainID(), tt.chainID)
+			}
+		})
+	}
+}
+
+func TestReadOnlyClient_Reads(t *testing.T) {
+	relayer := newFakeRelayer(t)
+
+	var authHeaders []string
+	serveTransaction(relayer, models.RelayerTransaction{TransactionID: "tx-1", State: models.STATE_MINED})
+	relayer.Handle(GET_DEPLOYED, func(w http.ResponseWriter, r *http.Request) {
+		authHeaders = append(authHeaders, r.Header.Get("POLY_BUILDER_API_KEY"))
+		json.NewEncoder(w).Encode(models.DeployedResponse{Deployed: true})
+	})
+
+	c, err := NewReadOnlyClient(relayer.URL(), 137)
+	if err != nil {
+		t.Fatalf("NewReadOnlyClient failed: %v", err)
+	}
+
+	txn, err := c.GetTransaction("tx-1")
+	if err != nil {
+		t.Fatalf("GetTransaction failed: %v", err)
+	}
+	if txn.State != models.STATE_MINED {
+		t.Errorf("State = %s, want %s", txn.State, models.STATE_MINED)
+	}
+
+	deployed, err := c.GetDeployed("0x6e0c80c90ea6c15917308F820Eac91Ce2724B5b5")
+	if err != nil {
+		t.Fatalf("GetDeployed failed: %v", err)
+	}
+	if !deployed {
+		t.Error("GetDeployed() = false, want true")
+	}
+	if len(authHeaders) != 1 || authHeaders[0] != "" {
+		t.Errorf("read-only requests must not carry builder credentials, got %q", authHeaders)
+	}
+
+	if _, err := c.GetNonce("0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266", string(models.SAFE_SIGNER)); err != nil {
+		t.Fatalf("GetNonce failed: %v", err)
+	}
+}
+
+func TestReadOnlyClient_DeriveSafeAddressFor(t *testing.T) {
+	c, err := NewReadOnlyClient("http://localhost", 137)
+	if err != nil {
+		t.Fatalf("NewReadOnlyClient failed: %v", err)
+	}
+
+	owner := common.HexToAddress("0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266")
+	got, err := c.DeriveSafeAddressFor(owner)
+	if err != nil {
+		t.Fatalf("DeriveSafeAddressFor failed: %v", err)
+	}
+	want, err := builder.DeriveSafeAddress(owner, 137)
+	if err != nil {
+		t.Fatalf("DeriveSafeAddress failed: %v", err)
+	}
+	if got != want {
+		t.Errorf("DeriveSafeAddressFor() = %s, want %s", got.Hex(), want.Hex())
+	}
+
+	// The full client derives through the same embedded read-only client
+	full := newTestClient(t, newFakeRelayer(t))
+	expected, err := full.GetExpectedSafe()
+	if err != nil {
+		t.Fatalf("GetExpectedSafe failed: %v", err)
+	}
+	if expected != want.Hex() {
+		t.Errorf("GetExpectedSafe() = %s, want %s", expected, want.Hex())
+	}
+}
+
+func TestReadOnlyClient_MethodSet(t *testing.T) {
+	// Signing and submitting must stay off the read-only type entirely
+	readOnly := reflect.TypeOf(&ReadOnlyClient{})
+	for _, name := range []string{"Execute", "ExecuteWithOptions", "ExecuteOnSafe", "Deploy", "DeployWithOptions", "CancelTransaction", "ReplaceTransaction", "GetTransactions", "GetSigner"} {
+		if _, ok := readOnly.MethodByName(name); ok {
+			t.Errorf("ReadOnlyClient must not have method %s", name)
+		}
+	}
+
+	// RelayClient exposes every read-only method through embedding
+	full := reflect.TypeOf(&RelayClient{})
+	for i := 0; i < readOnly.NumMethod(); i++ {
+		if _, ok := full.MethodByName(readOnly.Method(i).Name); !ok {
+			t.Errorf("RelayClient is missing read-only method %s", readOnly.Method(i).Name)
+		}
+	}
+}
+
+func TestGetTransactionInto(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	replacement := "tx-2"
+	responses := []models.RelayerTransaction{
+		{TransactionID: "tx-1", State: models.STATE_REPLACED, ReplacedBy: &replacement},
+		{TransactionID: "tx-1", State: models.STATE_MINED},
+	}
+	var mu sync.Mutex
+	relayer.Handle(GET_TRANSACTION, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		response := responses[0]
+		responses = responses[1:]
+		mu.Unlock()
+		json.NewEncoder(w).Encode([]models.RelayerTransaction{response})
+	})
+	c := newTestClient(t, relayer)
+
+	var txn models.RelayerTransaction
+	if err := c.GetTransactionInto("tx-1", &txn); err != nil {
+		t.Fatalf("GetTransactionInto failed: %v", err)
+	}
+	if txn.State != models.STATE_REPLACED || txn.ReplacedBy == nil || *txn.ReplacedBy != replacement {
+		t.Errorf("first transaction = %+v, want replaced by %s", txn, replacement)
+	}
+
+	// A field missing from the next response is not carried over
+	if err := c.GetTransactionInto("tx-1", &txn); err != nil {
+		t.Fatalf("GetTransactionInto failed: %v", err)
+	}
+	if txn.State != models.STATE_MINED || txn.ReplacedBy != nil {
+		t.Errorf("second transaction = %+v, want mined with no replacement", txn)
+	}
+
+	if err := c.GetTransactionInto("tx-1", nil); err == nil {
+		t.Error("GetTransactionInto should reject a nil out")
+	}
+}
+
+// replaceChainConfig registers a copy of chain 137's config changed by
+// mutate, restoring the original when the test ends
+func replaceChainConfig(t *testing.T, mutate func(*config.ContractConfig)) {
+	t.Helper()
+
+	original, err := config.GetContractConfig(137)
+	if err != nil {
+		t.Fatalf("GetContractConfig failed: %v", err)
+	}
+	t.Cleanup(func() { config.AddChainConfig(original) })
+
+	updated := *original
+	mutate(&updated)
+	config.AddChainConfig(&updated)
+}
+
+func TestContractConfig_RefreshedOnRegistryChange(t *testing.T) {
+	const newMultisend = "0x9641d764fc13c8B624c04430C7356C1C7C8102e2"
+
+	relayer := newFakeRelayer(t)
+	c := newTestClient(t, relayer)
+	originalMultisend := c.GetContractConfig().SafeMultisend
+
+	if _, err := c.Execute(retryBatch(), ""); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	// Hot-reload the config mid-flow
+	replaceChainConfig(t, func(contractConfig *config.ContractConfig) {
+		contractConfig.SafeMultisend = newMultisend
+	})
+
+	if _, err := c.Execute(retryBatch(), ""); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	submitted := relayer.Submissions()
+	if len(submitted) != 2 {
+		t.Fatalf("got %d submissions, want 2", len(submitted))
+	}
+	if to, _ := requestCall(t, submitted[0]); to != originalMultisend {
+		t.Errorf("first batch sent to %s, want %s", to, originalMultisend)
+	}
+	if to, _ := requestCall(t, submitted[1]); to != newMultisend {
+		t.Errorf("batch after the reload sent to %s, want %s", to, newMultisend)
+	}
+}
+
+func TestContractConfig_RefreshKeepsOverrides(t *testing.T) {
+	c := newTestClient(t, newFakeRelayer(t))
+	if err := WithSignatureScheme(signer.SchemeEIP712)(c); err != nil {
+		t.Fatalf("WithSignatureScheme failed: %v", err)
+	}
+
+	const newFactory = "0x9641d764fc13c8B624c04430C7356C1C7C8102e2"
+	replaceChainConfig(t, func(contractConfig *config.ContractConfig) {
+		contractConfig.SafeFactory = newFactory
+	})
+	if err := c.RefreshContractConfig(); err != nil {
+		t.Fatalf("RefreshContractConfig failed: %v", err)
+	}
+
+	got := c.GetContractConfig()
+	if got.SafeFactory != newFactory {
+		t.Errorf("SafeFactory = %s, want %s", got.SafeFactory, newFactory)
+	}
+	if got.SignatureScheme != signer.SchemeEIP712 {
+		t.Errorf("SignatureScheme = %q, want the client's override %q", got.SignatureScheme, signer.SchemeEIP712)
+	}
+	if registered, _ := config.GetContractConfig(137); registered.SignatureScheme == signer.SchemeEIP712 {
+		t.Error("the override leaked into the registry")
+	}
+
+	// The derived Safe follows the new factory
+	derived, err := c.GetExpectedSafe()
+	if err != nil {
+		t.Fatalf("GetExpectedSafe failed: %v", err)
+	}
+	want, err := builder.DeriveSafeAddressWithConfig(c.signer.Address(), got)
+	if err != nil {
+		t.Fatalf("DeriveSafeAddressWithConfig failed: %v", err)
+	}
+	if derived != want.Hex() {
+		t.Errorf("GetExpectedSafe() = %s, want %s", derived, want.Hex())
+	}
+}
+
+func TestContractConfig_ConcurrentReload(t *testing.T) {
+	c := newTestClient(t, newFakeRelayer(t))
+	original := c.GetContractConfig()
+	t.Cleanup(func() { config.AddChainConfig(original) })
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			updated := *original
+			config.AddChainConfig(&updated)
+		}()
+		go func() {
+			defer wg.Done()
+			if _, err := c.GetExpectedSafe(); err != nil {
+				t.Errorf("GetExpectedSafe failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	c.GetContractConfig()
+	c.configMu.RLock()
+	defer c.configMu.RUnlock()
+	if c.configVersion != config.ConfigVersion() {
+		t.Errorf("client at version %d, want the registry's %d", c.configVersion, config.ConfigVersion())
+	}
+}
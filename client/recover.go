@@ -0,0 +1,220 @@
+package client
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/http"
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+// RecoveredSubmission is the outcome of reconciling one pending SubmissionRecord
+type RecoveredSubmission struct {
+	// Record is the stored submission, with TransactionID filled in if it was
+	// found on the relayer
+	Record SubmissionRecord
+	// Transaction is the relayer's view of the submission; nil if the relayer
+	// has no matching transaction
+	Transaction *models.RelayerTransaction
+	// Resolved is true if the submission reached a final outcome (a terminal
+	// state, never received by the relayer, or lost) and was marked resolved
+	Resolved bool
+	// Lost is true if the record has a transaction ID the relayer no longer
+	// knows (it answered 404). The record is resolved so it is not looked up
+	// again; whether its nonce was used is unknown.
+	Lost bool
+}
+
+// RecoverPending reconciles the submission store's pending records against
+// the relayer, typically once at startup. Records with a transaction ID are
+// looked up with GetTransaction; records saved before a crash that lost the
+// ID are matched in GetTransactions by type, Safe address and nonce. Records
+// in a terminal state, records the relayer never received, and records
+// whose transaction the relayer no longer knows (reported as Lost) are marked
+// resolved; the rest stay pending and their transactions can be polled.
+func (c *RelayClient) RecoverPending() ([]RecoveredSubmission, error) {
+	if c.submissionStore == nil {
+		return nil, errors.ErrSubmissionStoreNotConfigured
+	}
+
+	op := newOperation("RecoverPending", 0)
+	defer op.cancel()
+
+	pending, err := c.submissionStore.ListPending()
+	if err != nil {
+		return nil, err
+	}
+
+	var listed *models.GetTransactionsResponse
+	results := make([]RecoveredSubmission, 0, len(pending))
+	for _, record := range pending {
+		result := RecoveredSubmission{Record: record}
+
+		if record.TransactionID != "" {
+			txn, err := c.getTransaction(op.ctx, record.TransactionID)
+			if errors.IsNotFound(err) {
+				c.logger.Printf("Relayer has no transaction %s for operation %s, resolving it as lost", record.TransactionID, record.OperationID)
+				if err := c.submissionStore.MarkResolved(record.OperationID); err != nil {
+					return results, err
+				}
+				result.Resolved = true
+				result.Lost = true
+				results = append(results, result)
+				continue
+			}
+			if err != nil {
+				return results, op.tag(err)
+			}
+			result.Transaction = txn
+		} else {
+			if listed == nil {
+				if listed, err = c.getTransactions(op.ctx); err != nil {
+					return results, op.tag(err)
+				}
+			}
+			result.Transaction = matchSubmission(record, listed.Transactions)
+			if result.Transaction == nil {
+				// The POST never reached the relayer, so the nonce is unused
+				if err := c.submissionStore.MarkResolved(record.OperationID); err != nil {
+					return results, err
+				}
+				result.Resolved = true
+				results = append(results, result)
+				continue
+			}
+
+			result.Record.TransactionID = result.Transaction.TransactionID
+			if err := c.submissionStore.SaveSubmission(result.Record); err != nil {
+				return results, err
+			}
+		}
+
+		if result.Transaction != nil && result.Transaction.State.IsTerminal() {
+			if err := c.submissionStore.MarkResolved(record.OperationID); err != nil {
+				return results, err
+			}
+			result.Resolved = true
+		} else if result.Transaction != nil {
+			c.trackSubmission(result.Transaction.TransactionID, record.OperationID)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// matchSubmission finds the relayer transaction a record without an ID
+// produced, preferring one that was not replaced
+func matchSubmission(record SubmissionRecord, transactions []models.RelayerTransaction) *models.RelayerTransaction {
+	var match *models.RelayerTransaction
+	for i := range transactions {
+		txn := &transactions[i]
+		if string(txn.Type) != record.Type || !strings.EqualFold(txn.SafeAddress, record.SafeAddress) {
+			continue
+		}
+		if record.Nonce != "" && stringValue(txn.Nonce) != record.Nonce {
+			continue
+		}
+		if match == nil || (match.State == models.STATE_REPLACED && txn.State != models.STATE_REPLACED) {
+			match = txn
+		}
+	}
+	return match
+}
+
+// beginSubmission saves the record for a submission about to be POSTed. A
+// failed save aborts the submission, since it could not be recovered.
+func (c *RelayClient) beginSubmission(operationID string, request *models.TransactionRequest, body interface{}) (*SubmissionRecord, error) {
+	if c.submissionStore == nil {
+		return nil, nil
+	}
+
+	encoded, err := json.Marshal(body)
+	if err != nil {
+		return nil, errors.ErrJSONMarshalFailed(err)
+	}
+	if operationID == "" {
+		operationID = http.NewOperationID()
+	}
+
+	record := &SubmissionRecord{
+		OperationID: operationID,
+		Type:        request.Type,
+		SafeAddress: request.ProxyWallet,
+		Nonce:       stringValue(request.Nonce),
+		ContentHash: crypto.Keccak256Hash(encoded).Hex(),
+		CreatedAt:   time.Now().UTC(),
+		Transaction: signedSafeTransaction(request),
+		Metadata:    request.Metadata,
+	}
+	if err := c.submissionStore.SaveSubmission(*record); err != nil {
+		return nil, errors.ErrSubmissionStoreFailed("save", err)
+	}
+	return record, nil
+}
+
+// completeSubmission saves the transaction ID of an accepted submission. A
+// failed save is only logged: the record stays pending and RecoverPending
+// can still match it by nonce.
+func (c *RelayClient) completeSubmission(record *SubmissionRecord, transactionID string) {
+	if record == nil {
+		return
+	}
+
+	submittedAt := time.Now().UTC()
+	record.TransactionID = transactionID
+	record.SubmittedAt = &submittedAt
+	if err := c.submissionStore.SaveSubmission(*record); err != nil {
+		c.logger.Printf("Failed to record transaction %s for operation %s: %v", transactionID, record.OperationID, err)
+		return
+	}
+	c.trackSubmission(transactionID, record.OperationID)
+}
+
+// abandonSubmission resolves the record of a submission the relayer
+// definitively rejected. Other failures (timeouts, 5xx) leave it pending,
+// since the relayer may have accepted it.
+func (c *RelayClient) abandonSubmission(record *SubmissionRecord, err error) {
+	if record == nil {
+		return
+	}
+
+	var apiErr *errors.RelayerApiError
+	if !stderrors.As(err, &apiErr) || apiErr.StatusCode < 400 || apiErr.StatusCode >= 500 || apiErr.StatusCode == 408 || apiErr.StatusCode == 429 {
+		return
+	}
+	if err := c.submissionStore.MarkResolved(record.OperationID); err != nil {
+		c.logger.Printf("Failed to resolve rejected submission for operation %s: %v", record.OperationID, err)
+	}
+}
+
+// trackSubmission remembers the record of transactionID so polling can
+// resolve it once the transaction reaches a terminal state
+func (c *RelayClient) trackSubmission(transactionID, operationID string) {
+	c.submittedMu.Lock()
+	defer c.submittedMu.Unlock()
+
+	if c.submittedOps == nil {
+		c.submittedOps = make(map[string]string)
+	}
+	c.submittedOps[transactionID] = operationID
+}
+
+// resolveSubmission marks the record of transactionID resolved, if any
+func (c *RelayClient) resolveSubmission(transactionID string) {
+	c.submittedMu.Lock()
+	operationID, ok := c.submittedOps[transactionID]
+	delete(c.submittedOps, transactionID)
+	c.submittedMu.Unlock()
+
+	if !ok {
+		return
+	}
+	if err := c.submissionStore.MarkResolved(operationID); err != nil {
+		c.logger.Printf("Failed to resolve submission for operation %s: %v", operationID, err)
+	}
+}
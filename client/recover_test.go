@@ -0,0 +1,402 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+// relayerLedger serves GET_TRANSACTION and GET_TRANSACTIONS from the fake
+// relayer's recorded submissions, with settable states
+type relayerLedger struct {
+	relayer *fakeRelayer
+	mu      sync.Mutex
+	states  map[string]models.RelayerTransactionState
+}
+
+func newRelayerLedger(relayer *fakeRelayer) *relayerLedger {
+	l := &relayerLedger{relayer: relayer, states: make(map[string]models.RelayerTransactionState)}
+	relayer.Handle(GET_TRANSACTIONS, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(models.GetTransactionsResponse{Transactions: l.transactions()})
+	})
+	relayer.Handle(GET_TRANSACTION, func(w http.ResponseWriter, r *http.Request) {
+		matched := []models.RelayerTransaction{}
+		for _, txn := range l.transactions() {
+			if txn.TransactionID == r.URL.Query().Get("id") {
+				matched = append(matched, txn)
+			}
+		}
+		json.NewEncoder(w).Encode(matched)
+	})
+	return l
+}
+
+func (l *relayerLedger) setState(transactionID string, state models.RelayerTransactionState) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.states[transactionID] = state
+}
+
+func (l *relayerLedger) transactions() []models.RelayerTransaction {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var transactions []models.RelayerTransaction
+	for _, request := range l.relayer.Submissions() {
+		id := "tx-nonce-" + stringValue(request.Nonce)
+		state, ok := l.states[id]
+		if !ok {
+			state = models.STATE_NEW
+		}
+		transactions = append(transactions, models.RelayerTransaction{
+			TransactionID: id,
+			State:         state,
+			Type:          models.TransactionType(request.Type),
+			SafeAddress:   request.ProxyWallet,
+			ChainID:       137,
+			Nonce:         request.Nonce,
+		})
+	}
+	return transactions
+}
+
+// crashingStore panics when the client saves the transaction ID, simulating
+// a process that dies between the POST succeeding and persisting its result
+type crashingStore struct {
+	SubmissionStore
+}
+
+func (s crashingStore) SaveSubmission(record SubmissionRecord) error {
+	if record.TransactionID != "" {
+		panic("crash")
+	}
+	return s.SubmissionStore.SaveSubmission(record)
+}
+
+// failingStore rejects every save
+type failingStore struct {
+	SubmissionStore
+}
+
+func (failingStore) SaveSubmission(SubmissionRecord) error {
+	return os.ErrPermission
+}
+
+// newStoreClient creates a test client (a fresh "process") persisting to path
+func newStoreClient(t *testing.T, relayer *fakeRelayer, path string, wrap func(SubmissionStore) SubmissionStore) *RelayClient {
+	t.Helper()
+
+	store, err := NewFileSubmissionStore(path)
+	if err != nil {
+		t.Fatalf("NewFileSubmissionStore failed: %v", err)
+	}
+	c := newTestClient(t, relayer)
+	c.submissionStore = store
+	if wrap != nil {
+		c.submissionStore = wrap(store)
+	}
+	return c
+}
+
+// pendingRecords lists the pending records in the store at path
+func pendingRecords(t *testing.T, path string) []SubmissionRecord {
+	t.Helper()
+
+	store, err := NewFileSubmissionStore(path)
+	if err != nil {
+		t.Fatalf("NewFileSubmissionStore failed: %v", err)
+	}
+	pending, err := store.ListPending()
+	if err != nil {
+		t.Fatalf("ListPending failed: %v", err)
+	}
+	return pending
+}
+
+func TestFileSubmissionStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "submissions.jsonl")
+	store, err := NewFileSubmissionStore(path)
+	if err != nil {
+		t.Fatalf("NewFileSubmissionStore failed: %v", err)
+	}
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, id := range []string{"op-b", "op-a", "op-c"} {
+		record := SubmissionRecord{OperationID: id, Type: "SAFE", Nonce: "1", CreatedAt: start.Add(time.Duration(i) * time.Second)}
+		if err := store.SaveSubmission(record); err != nil {
+			t.Fatalf("SaveSubmission failed: %v", err)
+		}
+	}
+
+	// Update one record, resolve another, then leave a torn line behind
+	if err := store.SaveSubmission(SubmissionRecord{OperationID: "op-a", Type: "SAFE", Nonce: "1", TransactionID: "tx-1", CreatedAt: start.Add(time.Second)}); err != nil {
+		t.Fatalf("SaveSubmission failed: %v", err)
+	}
+	if err := store.MarkResolved("op-c"); err != nil {
+		t.Fatalf("MarkResolved failed: %v", err)
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	f.WriteString(`{"operationId":"op-d","ty`)
+	f.Close()
+	if err := store.SaveSubmission(SubmissionRecord{OperationID: "op-e", Type: "SAFE", CreatedAt: start.Add(time.Hour)}); err != nil {
+		t.Fatalf("SaveSubmission after torn line failed: %v", err)
+	}
+
+	pending, err := store.ListPending()
+	if err != nil {
+		t.Fatalf("ListPending failed: %v", err)
+	}
+	var ids []string
+	for _, record := range pending {
+		ids = append(ids, record.OperationID)
+	}
+	if got := strings.Join(ids, ","); got != "op-b,op-a,op-e" {
+		t.Fatalf("pending = %s, want op-b,op-a,op-e", got)
+	}
+	if pending[1].TransactionID != "tx-1" {
+		t.Errorf("op-a TransactionID = %q, want the updated record", pending[1].TransactionID)
+	}
+
+	if err := store.SaveSubmission(SubmissionRecord{}); err == nil {
+		t.Error("Expected error for a record without OperationID")
+	}
+}
+
+func TestRecoverPending_CrashBeforeTransactionIDSaved(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	ledger := newRelayerLedger(relayer)
+	path := filepath.Join(t.TempDir(), "submissions.jsonl")
+
+	// The relayer accepts the submission, then the process dies
+	c := newStoreClient(t, relayer, path, func(s SubmissionStore) SubmissionStore { return crashingStore{s} })
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("Expected the simulated crash")
+			}
+		}()
+		c.Execute(testTransactions(), "")
+	}()
+
+	pending := pendingRecords(t, path)
+	if len(pending) != 1 {
+		t.Fatalf("Expected 1 pending record after the crash, got %d", len(pending))
+	}
+	record := pending[0]
+	if record.TransactionID != "" || record.Nonce != "0" || record.ContentHash == "" || record.OperationID == "" {
+		t.Fatalf("unexpected pre-submit record: %+v", record)
+	}
+	if ids := relayer.OperationIDs(); ids[len(ids)-1] != record.OperationID {
+		t.Errorf("record OperationID %s does not match the submit request's %s", record.OperationID, ids[len(ids)-1])
+	}
+
+	// After restart the submission is found by Safe and nonce
+	ledger.setState("tx-nonce-0", models.STATE_CONFIRMED)
+	restarted := newStoreClient(t, relayer, path, nil)
+	results, err := restarted.RecoverPending()
+	if err != nil {
+		t.Fatalf("RecoverPending failed: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("Expected 1 result, got %d", len(results))
+	}
+	result := results[0]
+	if result.Transaction == nil || result.Transaction.TransactionID != "tx-nonce-0" {
+		t.Fatalf("Transaction = %+v, want tx-nonce-0", result.Transaction)
+	}
+	if !result.Resolved || result.Record.TransactionID != "tx-nonce-0" {
+		t.Errorf("result = %+v, want resolved with the recovered transaction ID", result)
+	}
+	if pending := pendingRecords(t, path); len(pending) != 0 {
+		t.Errorf("Expected no pending records, got %d", len(pending))
+	}
+}
+
+func TestRecoverPending_CrashBeforeResolved(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	ledger := newRelayerLedger(relayer)
+	path := filepath.Join(t.TempDir(), "submissions.jsonl")
+
+	// Submitted and ID saved, but the process stops before the outcome is known
+	c := newStoreClient(t, relayer, path, nil)
+	response, err := c.Execute(testTransactions(), "")
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	pending := pendingRecords(t, path)
+	if len(pending) != 1 || pending[0].TransactionID != response.TransactionID || pending[0].SubmittedAt == nil {
+		t.Fatalf("pending = %+v, want one submitted record for %s", pending, response.TransactionID)
+	}
+
+	// Still in flight: recovery reports it and keeps it pending
+	ledger.setState(response.TransactionID, models.STATE_MINED)
+	results, err := newStoreClient(t, relayer, path, nil).RecoverPending()
+	if err != nil {
+		t.Fatalf("RecoverPending failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Resolved || results[0].Transaction.State != models.STATE_MINED {
+		t.Fatalf("results = %+v, want one unresolved mined transaction", results)
+	}
+	if len(pendingRecords(t, path)) != 1 {
+		t.Fatal("Expected the in-flight record to stay pending")
+	}
+
+	// Terminal on the next restart
+	ledger.setState(response.TransactionID, models.STATE_FAILED)
+	results, err = newStoreClient(t, relayer, path, nil).RecoverPending()
+	if err != nil {
+		t.Fatalf("RecoverPending failed: %v", err)
+	}
+	if len(results) != 1 || !results[0].Resolved || results[0].Transaction.State != models.STATE_FAILED {
+		t.Fatalf("results = %+v, want one resolved failed transaction", results)
+	}
+	if len(pendingRecords(t, path)) != 0 {
+		t.Error("Expected no pending records")
+	}
+}
+
+func TestRecoverPending_NeverReachedRelayer(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	newRelayerLedger(relayer)
+	relayer.Handle(SUBMIT_TRANSACTION, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		json.NewEncoder(w).Encode(models.ErrorResponse{Error: "upstream unavailable"})
+	})
+	path := filepath.Join(t.TempDir(), "submissions.jsonl")
+
+	c := newStoreClient(t, relayer, path, nil)
+	if _, err := c.Execute(testTransactions(), ""); err == nil {
+		t.Fatal("Expected submit to fail")
+	}
+	// The outcome of a 5xx is unknown, so the record stays pending
+	if len(pendingRecords(t, path)) != 1 {
+		t.Fatal("Expected the record to stay pending after a 5xx")
+	}
+
+	results, err := newStoreClient(t, relayer, path, nil).RecoverPending()
+	if err != nil {
+		t.Fatalf("RecoverPending failed: %v", err)
+	}
+	if len(results) != 1 || !results[0].Resolved || results[0].Transaction != nil {
+		t.Fatalf("results = %+v, want one resolved record without a transaction", results)
+	}
+	if len(pendingRecords(t, path)) != 0 {
+		t.Error("Expected no pending records")
+	}
+}
+
+func TestRecoverPending_LostTransaction(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	relayer.Handle(GET_TRANSACTION, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(models.ErrorResponse{Error: "transaction not found"})
+	})
+	path := filepath.Join(t.TempDir(), "submissions.jsonl")
+
+	response, err := newStoreClient(t, relayer, path, nil).Execute(testTransactions(), "")
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	// The relayer has forgotten the transaction: it is reported once and
+	// not looked up again
+	results, err := newStoreClient(t, relayer, path, nil).RecoverPending()
+	if err != nil {
+		t.Fatalf("RecoverPending failed: %v", err)
+	}
+	if len(results) != 1 || !results[0].Lost || !results[0].Resolved || results[0].Transaction != nil ||
+		results[0].Record.TransactionID != response.TransactionID {
+		t.Fatalf("results = %+v, want %s resolved as lost", results, response.TransactionID)
+	}
+	if len(pendingRecords(t, path)) != 0 {
+		t.Error("Expected no pending records")
+	}
+	if results, err := newStoreClient(t, relayer, path, nil).RecoverPending(); err != nil || len(results) != 0 {
+		t.Errorf("second RecoverPending = %+v, %v, want nothing to recover", results, err)
+	}
+}
+
+func TestSubmissionStore_Lifecycle(t *testing.T) {
+
+	tests := []struct {
+		name        string
+		submit      http.HandlerFunc
+		wrap        func(SubmissionStore) SubmissionStore
+		poll        bool
+		wantPending int
+		shouldErr   bool
+	}{
+		{name: "accepted stays pending until polled", wantPending: 1},
+		{name: "polled to a terminal state resolves", poll: true, wantPending: 0},
+		{
+			name: "rejected resolves immediately",
+			submit: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(models.ErrorResponse{Error: "invalid signature"})
+			},
+			wantPending: 0,
+			shouldErr:   true,
+		},
+		{
+			name:      "failed save aborts the submission",
+			wrap:      func(s SubmissionStore) SubmissionStore { return failingStore{s} },
+			shouldErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			relayer := newFakeRelayer(t)
+			ledger := newRelayerLedger(relayer)
+			if tt.submit != nil {
+				relayer.Handle(SUBMIT_TRANSACTION, tt.submit)
+			}
+			path := filepath.Join(t.TempDir(), "submissions.jsonl")
+			c := newStoreClient(t, relayer, path, tt.wrap)
+
+			response, err := c.Execute(testTransactions(), "")
+			if tt.shouldErr {
+				if err == nil {
+					t.Fatal("Expected error")
+				}
+				if tt.wrap != nil && len(relayer.Submissions()) != 0 {
+					t.Error("Expected nothing submitted when the store cannot save")
+				}
+			} else if err != nil {
+				t.Fatalf("Execute failed: %v", err)
+			}
+
+			if tt.poll {
+				ledger.setState(response.TransactionID, models.STATE_CONFIRMED)
+				if _, err := c.PollUntilState(response.TransactionID, []models.RelayerTransactionState{models.STATE_CONFIRMED}, "", 3, 1); err != nil {
+					t.Fatalf("PollUntilState failed: %v", err)
+				}
+			}
+
+			if got := len(pendingRecords(t, path)); got != tt.wantPending {
+				t.Errorf("pending records = %d, want %d", got, tt.wantPending)
+			}
+		})
+	}
+}
+
+func TestRecoverPending_NoStore(t *testing.T) {
+	c := newTestClient(t, newFakeRelayer(t))
+	if _, err := c.RecoverPending(); err != errors.ErrSubmissionStoreNotConfigured {
+		t.Errorf("RecoverPending() error = %v, want ErrSubmissionStoreNotConfigured", err)
+	}
+	if _, err := NewRelayClient("http://localhost", 137, "", nil, WithSubmissionStore(nil)); err == nil {
+		t.Error("Expected error for a nil store")
+	}
+}
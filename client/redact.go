@@ -0,0 +1,64 @@
+package client
+
+import (
+	"io"
+	"strings"
+
+	"github.com/davidt58/go-builder-relayer-client/config"
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/redact"
+)
+
+// WithRedactionRules sets the rules the client's log output is scrubbed
+// with (default redact.Rules{}). The client's private key and builder
+// credentials are always redacted.
+func WithRedactionRules(rules redact.Rules) Option {
+	return func(c *RelayClient) error {
+		if rules.MaxCalldataBytes > 0 && rules.MaxCalldataBytes < 4 {
+			return errors.ErrInvalidConfiguration("MaxCalldataBytes must keep at least the 4-byte selector")
+		}
+		c.redactor = redact.New(rules)
+		return nil
+	}
+}
+
+// WithStrictRedaction makes the client's logger panic when it is given a
+// signature, secret or oversized calldata that was not redacted first, so
+// tests and debug builds catch log calls that bypass redaction
+func WithStrictRedaction() Option {
+	return func(c *RelayClient) error {
+		c.strictRedaction = true
+		return nil
+	}
+}
+
+// WithLogOutput sends the client's log output to w (default os.Stdout)
+func WithLogOutput(w io.Writer) Option {
+	return func(c *RelayClient) error {
+		if w == nil {
+			return errors.ErrMissingRequiredField("w")
+		}
+		c.logOutput = w
+		return nil
+	}
+}
+
+// Redactor returns the redactor applied to the client's log output, for
+// applications that log requests or metrics labels themselves
+func (c *RelayClient) Redactor() *redact.Redactor {
+	return c.redactor
+}
+
+// clientSecrets returns the secrets the client was created with
+func clientSecrets(privateKey string, builderConfig *config.BuilderConfig) []string {
+	var secrets []string
+	if privateKey != "" {
+		secrets = append(secrets, privateKey, strings.TrimPrefix(privateKey, "0x"))
+	}
+	if builderConfig != nil {
+		for _, cred := range append(builderConfig.Credentials, builderConfig.ActiveCredential()) {
+			secrets = append(secrets, cred.Secret, cred.Passphrase)
+		}
+	}
+	return secrets
+}
@@ -0,0 +1,108 @@
+package client
+
+import (
+	"bytes"
+	"encoding/base64"
+	"log"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/davidt58/go-builder-relayer-client/clock"
+	"github.com/davidt58/go-builder-relayer-client/config"
+	"github.com/davidt58/go-builder-relayer-client/redact"
+)
+
+// newLoggingTestClient is newTestClient with its log output captured in buf
+func newLoggingTestClient(t *testing.T, f *fakeRelayer, buf *bytes.Buffer, opts ...Option) *RelayClient {
+	t.Helper()
+
+	secret := base64.URLEncoding.EncodeToString([]byte("test-secret"))
+	builderConfig := config.NewBuilderConfig("test-key", secret, "test-passphrase")
+
+	c, err := NewRelayClient(f.URL(), 137, testPrivateKey, builderConfig, append([]Option{WithLogOutput(buf)}, opts...)...)
+	if err != nil {
+		t.Fatalf("NewRelayClient failed: %v", err)
+	}
+	c.clock = clock.NewAutoFake(testClockStart)
+	return c
+}
+
+func TestExecute_LogsAreRedacted(t *testing.T) {
+	var clientLog, globalLog bytes.Buffer
+	log.SetOutput(&globalLog)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	relayer := newFakeRelayer(t)
+	c := newLoggingTestClient(t, relayer, &clientLog, WithStrictRedaction())
+
+	// Calldata well over the default truncation limit
+	if _, err := c.Execute(transactionsWithData(2, 200), ""); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	submitted := relayer.Submissions()
+	if len(submitted) != 1 {
+		t.Fatalf("got %d submissions, want 1", len(submitted))
+	}
+	signature := submitted[0].Signature
+	data := string(submitted[0].Data)
+
+	logged := clientLog.String() + globalLog.String()
+	if !strings.Contains(clientLog.String(), "Submitting transaction request") {
+		t.Fatalf("the request was not logged:\n%s", clientLog.String())
+	}
+	for name, secret := range map[string]string{
+		"signature":   strings.TrimPrefix(signature, "0x"),
+		"calldata":    strings.Trim(data, `"0x`),
+		"private key": strings.TrimPrefix(testPrivateKey, "0x"),
+		"passphrase":  "test-passphrase",
+	} {
+		if strings.Contains(logged, secret) {
+			t.Errorf("log output contains the %s", name)
+		}
+	}
+	if short := c.Redactor().Signature(signature); !strings.Contains(clientLog.String(), short) {
+		t.Errorf("log output does not contain the shortened signature %s", short)
+	}
+}
+
+func TestStrictRedaction_PanicsOnUnredactedLog(t *testing.T) {
+	var buf bytes.Buffer
+	relayer := newFakeRelayer(t)
+
+	lenient := newLoggingTestClient(t, relayer, &buf)
+	lenient.logger.Printf("key %s", testPrivateKey)
+	if strings.Contains(buf.String(), strings.TrimPrefix(testPrivateKey, "0x")) || !strings.Contains(buf.String(), redact.Placeholder) {
+		t.Errorf("private key was not redacted: %s", buf.String())
+	}
+
+	strict := newLoggingTestClient(t, relayer, &buf, WithStrictRedaction())
+	defer func() {
+		if recover() == nil {
+			t.Error("strict redaction did not panic on an unredacted private key")
+		}
+	}()
+	strict.logger.Printf("key %s", testPrivateKey)
+}
+
+func TestRedactionOptions(t *testing.T) {
+	tests := []struct {
+		name      string
+		opt       Option
+		shouldErr bool
+	}{
+		{"default rules", WithRedactionRules(redact.Rules{}), false},
+		{"no truncation", WithRedactionRules(redact.Rules{MaxCalldataBytes: -1}), false},
+		{"truncating the selector", WithRedactionRules(redact.Rules{MaxCalldataBytes: 2}), true},
+		{"nil log output", WithLogOutput(nil), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.opt(&RelayClient{})
+			if (err != nil) != tt.shouldErr {
+				t.Errorf("error = %v, shouldErr %v", err, tt.shouldErr)
+			}
+		})
+	}
+}
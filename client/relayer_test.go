@@ -0,0 +1,50 @@
+package client
+
+import (
+	"encoding/base64"
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/davidt58/go-builder-relayer-client/clock"
+	"github.com/davidt58/go-builder-relayer-client/config"
+	"github.com/davidt58/go-builder-relayer-client/relayertest"
+)
+
+// testPrivateKey is the well-known Hardhat account #0 key
+const testPrivateKey = relayertest.TestPrivateKey
+
+// fakeRelayer is the scripted relayer the client tests run against
+type fakeRelayer = relayertest.ScriptedRelayer
+
+// newFakeRelayer starts a relayer that tracks the Safe nonce, reports the
+// Safe as deployed and records submissions
+func newFakeRelayer(t testing.TB) *fakeRelayer {
+	t.Helper()
+	return relayertest.NewScriptedRelayer(t)
+}
+
+// newTestClient creates a RelayClient with a signer and builder credentials pointed at the fake relayer
+func newTestClient(t testing.TB, f *fakeRelayer) *RelayClient {
+	t.Helper()
+
+	secret := base64.URLEncoding.EncodeToString([]byte("test-secret"))
+	builderConfig := config.NewBuilderConfig("test-key", secret, "test-pass")
+
+	c, err := NewRelayClient(f.URL(), 137, testPrivateKey, builderConfig)
+	if err != nil {
+		t.Fatalf("NewRelayClient failed: %v", err)
+	}
+	c.logger = log.New(io.Discard, "", 0)
+	c.clock = clock.NewAutoFake(testClockStart)
+	return c
+}
+
+// testClockStart is the time the test clients' fake clocks start at
+var testClockStart = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// testClock returns the fake clock of a client created by newTestClient
+func testClock(c *RelayClient) *clock.Fake {
+	return c.clock.(*clock.Fake)
+}
@@ -0,0 +1,184 @@
+package client
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/davidt58/go-builder-relayer-client/config"
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+// DefaultNonceReservationTTL is how long ReserveNonces holds a range that is
+// neither committed nor released
+const DefaultNonceReservationTTL = 2 * time.Minute
+
+// WithNonceStore coordinates ReserveNonces through store, e.g. one shared
+// by several services. The default is a MemoryNonceStore on the client's clock.
+func WithNonceStore(store NonceStore) Option {
+	return func(c *RelayClient) error {
+		if store == nil {
+			return errors.ErrMissingRequiredField("store")
+		}
+		c.nonceStore = store
+		return nil
+	}
+}
+
+// WithNonceReservationTTL sets how long a reservation is held before its
+// nonces are reclaimed (default DefaultNonceReservationTTL)
+func WithNonceReservationTTL(ttl time.Duration) Option {
+	return func(c *RelayClient) error {
+		if ttl <= 0 {
+			return errors.ErrInvalidConfiguration("nonce reservation TTL must be positive")
+		}
+		c.nonceTTL = ttl
+		return nil
+	}
+}
+
+// WithNonceGapWarning calls onGap for every nonce gap the NonceStore reports,
+// in addition to logging it
+func WithNonceGapWarning(onGap func(NonceGap)) Option {
+	return func(c *RelayClient) error {
+		if onGap == nil {
+			return errors.ErrMissingRequiredField("onGap")
+		}
+		c.onNonceGap = onGap
+		return nil
+	}
+}
+
+// NonceReservation is a range of the derived Safe's nonces reserved with
+// ReserveNonces. Submit with Execute, then Commit the range once its
+// transactions are submitted or Release it if they will not be; otherwise
+// it expires at ExpiresAt.
+type NonceReservation struct {
+	NonceRange
+
+	client *RelayClient
+	safe   string
+
+	mu sync.Mutex
+	// done reports whether the reservation was committed or released
+	done bool
+}
+
+// ReserveNonces reserves count consecutive nonces of the derived Safe in
+// the client's NonceStore, starting no lower than the relayer's next nonce.
+// Other submitters using the same store get the following range. Submissions
+// that bypass the store (Execute, EnqueueExecute) are not coordinated.
+func (c *RelayClient) ReserveNonces(count int) (*NonceReservation, error) {
+	if err := c.assertSignerNeeded(); err != nil {
+		return nil, err
+	}
+
+	nonceResp, err := c.GetNonceWithOptions(c.signer.AddressHex(), string(c.nonceSignerType), ReadOptions{SkipCache: true})
+	if err != nil {
+		return nil, err
+	}
+	floor, err := strconv.ParseUint(nonceResp.Nonce, 10, 64)
+	if err != nil {
+		return nil, errors.ErrInvalidResponse("nonce is not a valid integer: " + nonceResp.Nonce)
+	}
+
+	safe, err := c.GetExpectedSafe()
+	if err != nil {
+		return nil, err
+	}
+
+	nonceRange, gaps, err := c.nonceReservations().Reserve(safe, floor, count, c.nonceTTL)
+	c.warnNonceGaps(gaps)
+	if err != nil {
+		return nil, err
+	}
+	return &NonceReservation{NonceRange: nonceRange, client: c, safe: safe}, nil
+}
+
+// Nonces returns the reserved nonces in order
+func (r *NonceReservation) Nonces() []string {
+	nonces := make([]string, 0, r.Count())
+	for nonce := r.Start; nonce <= r.End; nonce++ {
+		nonces = append(nonces, strconv.FormatUint(nonce, 10))
+	}
+	return nonces
+}
+
+// Execute builds, signs and submits transactions on the derived Safe with
+// one of the reserved nonces. It fails once the reservation is committed,
+// released or expired, since its nonces may then belong to another submitter.
+func (r *NonceReservation) Execute(nonce uint64, transactions []models.SafeTransaction, metadata string) (*models.ClientRelayerTransactionResponse, error) {
+	if !r.active() {
+		return nil, errors.ErrNonceReservationInactive(r.ID)
+	}
+	if nonce < r.Start || nonce > r.End {
+		return nil, errors.NewRelayerClientError(fmt.Sprintf("nonce %d is outside reservation %s (%d-%d)", nonce, r.ID, r.Start, r.End), nil)
+	}
+	if err := r.client.assertBuilderCredsNeeded(); err != nil {
+		return nil, err
+	}
+	if err := r.client.checkExecuteLimits(transactions, models.MetadataOf(metadata), config.MultisendStandard); err != nil {
+		return nil, err
+	}
+
+	return r.client.executeWithNonce(transactions, metadata, strconv.FormatUint(nonce, 10))
+}
+
+// Commit records the reserved nonces as used
+func (r *NonceReservation) Commit() error {
+	gaps, err := r.client.nonceReservations().Commit(r.safe, r.ID)
+	r.client.warnNonceGaps(gaps)
+	r.finish(err)
+	return err
+}
+
+// Release hands the reserved nonces back for the next reservation. If a
+// higher nonce has been committed meanwhile, they are reported as a gap.
+func (r *NonceReservation) Release() error {
+	gaps, err := r.client.nonceReservations().Release(r.safe, r.ID)
+	r.client.warnNonceGaps(gaps)
+	r.finish(err)
+	return err
+}
+
+// active reports whether the reservation is neither finished nor expired
+func (r *NonceReservation) active() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return !r.done && r.client.clock.Now().Before(r.ExpiresAt)
+}
+
+// finish marks the reservation finished once the store has committed or
+// released it
+func (r *NonceReservation) finish(err error) {
+	if err != nil {
+		return
+	}
+	r.mu.Lock()
+	r.done = true
+	r.mu.Unlock()
+}
+
+// nonceReservations returns the client's NonceStore, creating the default
+// MemoryNonceStore on first use
+func (c *RelayClient) nonceReservations() NonceStore {
+	c.nonceStoreMu.Lock()
+	defer c.nonceStoreMu.Unlock()
+	if c.nonceStore == nil {
+		c.nonceStore = NewMemoryNonceStore(c.clock)
+	}
+	return c.nonceStore
+}
+
+// warnNonceGaps logs the gaps and passes them to the gap warning callback
+func (c *RelayClient) warnNonceGaps(gaps []NonceGap) {
+	for _, gap := range gaps {
+		c.logger.Printf("WARNING: nonces %d-%d of Safe %s were not used but nonce %d was committed; the Safe is blocked until they are",
+			gap.Start, gap.End, gap.Safe, gap.HighestCommitted)
+		if c.onNonceGap != nil {
+			c.onNonceGap(gap)
+		}
+	}
+}
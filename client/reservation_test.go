@@ -0,0 +1,165 @@
+package client
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReserveNonces(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	relayer.SetNonce(4)
+	var gaps []NonceGap
+	c := newTestClient(t, relayer)
+	for _, opt := range []Option{
+		WithNonceReservationTTL(time.Minute),
+		WithNonceGapWarning(func(gap NonceGap) { gaps = append(gaps, gap) }),
+	} {
+		if err := opt(c); err != nil {
+			t.Fatalf("option failed: %v", err)
+		}
+	}
+
+	// Service A takes a burst, service B the following range
+	a, err := c.ReserveNonces(3)
+	if err != nil {
+		t.Fatalf("ReserveNonces failed: %v", err)
+	}
+	b, err := c.ReserveNonces(2)
+	if err != nil {
+		t.Fatalf("ReserveNonces failed: %v", err)
+	}
+	if got := a.Nonces(); len(got) != 3 || got[0] != "4" || got[2] != "6" {
+		t.Errorf("A nonces = %v, want 4-6", got)
+	}
+	if b.Start != 7 || b.End != 8 {
+		t.Errorf("B range = %d-%d, want 7-8", b.Start, b.End)
+	}
+
+	// A submits with its nonces and commits
+	for _, nonce := range []uint64{a.Start, a.Start + 1, a.End} {
+		if _, err := a.Execute(nonce, testTransactions(), ""); err != nil {
+			t.Fatalf("Execute(%d) failed: %v", nonce, err)
+		}
+	}
+	if _, err := a.Execute(b.Start, testTransactions(), ""); err == nil {
+		t.Error("Execute should refuse a nonce outside the reservation")
+	}
+	if err := a.Commit(); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	submitted := relayer.Submissions()
+	if len(submitted) != 3 || stringValue(submitted[0].Nonce) != "4" || stringValue(submitted[2].Nonce) != "6" {
+		t.Fatalf("submitted %d requests, want nonces 4-6", len(submitted))
+	}
+
+	// C commits above B, which never submits and expires
+	cRange, err := c.ReserveNonces(1)
+	if err != nil {
+		t.Fatalf("ReserveNonces failed: %v", err)
+	}
+	if err := cRange.Commit(); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	testClock(c).Advance(2 * time.Minute)
+	if _, err := c.ReserveNonces(1); err != nil {
+		t.Fatalf("ReserveNonces failed: %v", err)
+	}
+	if len(gaps) != 1 || gaps[0].Start != 7 || gaps[0].End != 8 || gaps[0].HighestCommitted != 9 {
+		t.Errorf("gaps = %+v, want 7-8 below committed 9", gaps)
+	}
+	if err := b.Release(); err == nil {
+		t.Error("Release of an expired reservation should fail")
+	}
+}
+
+func TestNonceReservation_ExecuteWhenInactive(t *testing.T) {
+	tests := []struct {
+		name   string
+		finish func(c *RelayClient, r *NonceReservation) error
+	}{
+		{name: "released", finish: func(c *RelayClient, r *NonceReservation) error { return r.Release() }},
+		{name: "committed", finish: func(c *RelayClient, r *NonceReservation) error { return r.Commit() }},
+		{name: "expired", finish: func(c *RelayClient, r *NonceReservation) error {
+			testClock(c).Advance(DefaultNonceReservationTTL)
+			return nil
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			relayer := newFakeRelayer(t)
+			c := newTestClient(t, relayer)
+			r, err := c.ReserveNonces(1)
+			if err != nil {
+				t.Fatalf("ReserveNonces failed: %v", err)
+			}
+			if err := tt.finish(c, r); err != nil {
+				t.Fatalf("finishing the reservation failed: %v", err)
+			}
+
+			if _, err := r.Execute(r.Start, testTransactions(), ""); err == nil {
+				t.Error("Execute succeeded on an inactive reservation")
+			}
+			if n := len(relayer.Submissions()); n != 0 {
+				t.Errorf("submitted %d requests, want none", n)
+			}
+		})
+	}
+}
+
+func TestReserveNonces_SharedStore(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	serviceA := newTestClient(t, relayer)
+	serviceB := newTestClient(t, relayer)
+	store := NewMemoryNonceStore(testClock(serviceA))
+	for _, c := range []*RelayClient{serviceA, serviceB} {
+		if err := WithNonceStore(store)(c); err != nil {
+			t.Fatalf("WithNonceStore failed: %v", err)
+		}
+	}
+
+	a, err := serviceA.ReserveNonces(5)
+	if err != nil {
+		t.Fatalf("ReserveNonces failed: %v", err)
+	}
+	b, err := serviceB.ReserveNonces(5)
+	if err != nil {
+		t.Fatalf("ReserveNonces failed: %v", err)
+	}
+	if b.Start != a.End+1 {
+		t.Errorf("B range starts at %d, want %d after A's range", b.Start, a.End+1)
+	}
+
+	// A's released range goes to the next reservation
+	if err := a.Release(); err != nil {
+		t.Fatalf("Release failed: %v", err)
+	}
+	next, err := serviceB.ReserveNonces(5)
+	if err != nil {
+		t.Fatalf("ReserveNonces failed: %v", err)
+	}
+	if next.Start != a.Start {
+		t.Errorf("next range starts at %d, want A's released %d", next.Start, a.Start)
+	}
+}
+
+func TestNonceReservationOptions(t *testing.T) {
+	c := newTestClient(t, newFakeRelayer(t))
+
+	tests := []struct {
+		name string
+		opt  Option
+	}{
+		{"nil store", WithNonceStore(nil)},
+		{"zero TTL", WithNonceReservationTTL(0)},
+		{"nil gap callback", WithNonceGapWarning(nil)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.opt(c); err == nil {
+				t.Error("Expected error but got none")
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/davidt58/go-builder-relayer-client/builder"
+	"github.com/davidt58/go-builder-relayer-client/config"
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+// retryOfTag prefixes the metadata tag linking a retry to the original transaction
+const retryOfTag = "retryOf:"
+
+// RetryOptions configures RetryTransaction
+type RetryOptions struct {
+	// Force retries a transaction that is not in STATE_FAILED. The original
+	// may still execute, so only use it for transactions known to be dead.
+	Force bool
+	// Timeout bounds the resubmission (see ExecuteOptions.Timeout)
+	Timeout time.Duration
+}
+
+// RetryTransaction resubmits the transactions of a failed SAFE transaction
+// with a fresh nonce and signature. The batch is rebuilt from the call the
+// relayer returns for the transaction, or else from the submission store
+// record (see WithSubmissionStore and SubmissionFinder); if neither has it
+// an OriginalPayloadUnavailableError is returned. The new transaction's
+// metadata is the original's with a "retryOf:<id>" tag.
+func (c *RelayClient) RetryTransaction(transactionID string, opts RetryOptions) (*models.ClientRelayerTransactionResponse, error) {
+	if err := c.assertSignerNeeded(); err != nil {
+		return nil, err
+	}
+	if err := c.assertBuilderCredsNeeded(); err != nil {
+		return nil, err
+	}
+
+	original, err := c.getTransaction(context.Background(), transactionID)
+	if err != nil {
+		return nil, err
+	}
+	if original.Type != models.SAFE {
+		return nil, errors.NewRelayerClientError(fmt.Sprintf("cannot retry %s transaction %s; only SAFE transactions can be retried", original.Type, transactionID), nil)
+	}
+	if original.State != models.STATE_FAILED && !opts.Force {
+		return nil, errors.NewRelayerClientError(fmt.Sprintf("transaction %s is %s, not %s; set Force to retry it anyway", transactionID, original.State, models.STATE_FAILED), nil)
+	}
+
+	signed, metadata, err := c.originalPayload(original)
+	if err != nil {
+		return nil, err
+	}
+	transactions, variant, err := c.unbatch(signed)
+	if err != nil {
+		return nil, err
+	}
+
+	// Retry through the same Safe; the derived Safe keeps its nonce under the signer
+	nonceAddress, safeAddress := c.signer.AddressHex(), ""
+	if derived, _ := c.GetExpectedSafe(); !strings.EqualFold(derived, original.SafeAddress) {
+		safeAddress = common.HexToAddress(original.SafeAddress).Hex()
+		nonceAddress = safeAddress
+	}
+
+	c.logger.Printf("Retrying transaction %s (%d transactions)", transactionID, len(transactions))
+	return c.execute(nonceAddress, safeAddress, transactions, "", ExecuteOptions{
+		Timeout:           opts.Timeout,
+		Multisend:         variant,
+		SkipDeployedCheck: true,
+		Metadata:          models.ExplicitMetadata(retryMetadata(metadata, transactionID)),
+	})
+}
+
+// originalPayload returns the signed Safe transaction and metadata of
+// original, from the relayer's response or else the submission store
+func (c *RelayClient) originalPayload(original *models.RelayerTransaction) (*models.SafeTransaction, *string, error) {
+	// The client signs one call per request, batching through MultiSend
+	echoed, err := original.RequestTransactions()
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(echoed) == 1 && len(original.Data) > 0 {
+		signed := &models.SafeTransaction{
+			To:        echoed[0].To,
+			Value:     echoed[0].Value,
+			Data:      echoed[0].Data,
+			Operation: echoed[0].Operation,
+		}
+		if c.isMultisend(signed.To) {
+			signed.Operation = models.DelegateCall
+		}
+		return signed, original.Metadata, nil
+	}
+
+	if finder, ok := c.submissionStore.(SubmissionFinder); ok {
+		record, found, err := finder.FindSubmission(original.TransactionID)
+		if err != nil {
+			return nil, nil, errors.ErrSubmissionStoreFailed("find", err)
+		}
+		if found && record.Transaction != nil {
+			metadata := original.Metadata
+			if metadata == nil {
+				metadata = record.Metadata
+			}
+			return record.Transaction, metadata, nil
+		}
+	}
+	return nil, nil, errors.ErrOriginalPayloadUnavailable(original.TransactionID)
+}
+
+// unbatch returns the transactions a signed Safe transaction executes and
+// the MultiSend variant they were batched through
+func (c *RelayClient) unbatch(signed *models.SafeTransaction) ([]models.SafeTransaction, config.MultisendVariant, error) {
+	if !c.isMultisend(signed.To) || signed.Operation != models.DelegateCall {
+		return []models.SafeTransaction{*signed}, config.MultisendStandard, nil
+	}
+
+	callData, err := hexutil.Decode(signed.Data)
+	if err != nil {
+		return nil, "", errors.NewRelayerClientError("invalid multisend data", err)
+	}
+	transactions, err := builder.DecodeMultiSendCallData(callData)
+	if err != nil {
+		return nil, "", err
+	}
+	return transactions, config.MultisendVariantOf(signed.To), nil
+}
+
+// isMultisend reports whether address is one of the client's MultiSend contracts
+func (c *RelayClient) isMultisend(address string) bool {
+	contractConfig := c.currentContractConfig()
+	for _, multisend := range []string{contractConfig.SafeMultisend, contractConfig.SafeMultisendCallOnly} {
+		if multisend != "" && strings.EqualFold(multisend, address) {
+			return true
+		}
+	}
+	return false
+}
+
+// retryMetadata returns metadata tagged with retryOf:<transactionID>,
+// replacing the tag of an earlier retry
+func retryMetadata(metadata *string, transactionID string) string {
+	var parts []string
+	if metadata != nil && *metadata != "" {
+		for _, part := range strings.Split(*metadata, ";") {
+			if !strings.HasPrefix(part, retryOfTag) {
+				parts = append(parts, part)
+			}
+		}
+	}
+	return strings.Join(append(parts, retryOfTag+transactionID), ";")
+}
+
+// signedSafeTransaction returns the Safe transaction a single-call SAFE
+// request signs, or nil for other requests
+func signedSafeTransaction(request *models.TransactionRequest) *models.SafeTransaction {
+	if request.Type != string(models.SAFE) {
+		return nil
+	}
+
+	var signed models.SafeTransaction
+	if json.Unmarshal(request.To, &signed.To) != nil || json.Unmarshal(request.Data, &signed.Data) != nil {
+		return nil
+	}
+	if len(request.Value) > 0 && json.Unmarshal(request.Value, &signed.Value) != nil {
+		return nil
+	}
+	if params := request.SignatureParams; params != nil {
+		if params.Operation != nil && *params.Operation == "1" {
+			signed.Operation = models.DelegateCall
+		}
+		if params.SafeTxGas != nil && *params.SafeTxGas != "0" {
+			signed.GasLimit = *params.SafeTxGas
+		}
+	}
+	return &signed
+}
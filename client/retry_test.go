@@ -0,0 +1,234 @@
+package client
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/davidt58/go-builder-relayer-client/builder"
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+// retryBatch is the batch the retry tests resubmit
+func retryBatch() []models.SafeTransaction {
+	return []models.SafeTransaction{
+		*models.NewSafeTransaction("0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174", "0", "0xa9059cbb"),
+		*models.NewSafeTransaction("0x4D97DCd97eC945f40cF65F87097ACe5EA0476045", "5", "0x"),
+	}
+}
+
+// requestCall returns the to and data of a submitted single-call request
+func requestCall(t *testing.T, request models.TransactionRequest) (to, data string) {
+	t.Helper()
+	if err := json.Unmarshal(request.To, &to); err != nil {
+		t.Fatalf("to is not a single address: %s", request.To)
+	}
+	if err := json.Unmarshal(request.Data, &data); err != nil {
+		t.Fatalf("data is not a single value: %s", request.Data)
+	}
+	return to, data
+}
+
+func TestRetryTransaction_FromRelayer(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	c := newTestClient(t, relayer)
+
+	aggregated, err := builder.AggregateSafeTransaction(retryBatch(), c.contractConfig.SafeMultisend)
+	if err != nil {
+		t.Fatalf("AggregateSafeTransaction failed: %v", err)
+	}
+	derived, _ := c.GetExpectedSafe()
+	serveTransaction(relayer, models.RelayerTransaction{
+		TransactionID: "tx-failed",
+		State:         models.STATE_FAILED,
+		Type:          models.SAFE,
+		SafeAddress:   derived,
+		To:            jsonString(aggregated.To),
+		Data:          jsonString(aggregated.Data),
+		Metadata:      models.ExplicitMetadata("order 42"),
+	})
+
+	if _, err := c.RetryTransaction("tx-failed", RetryOptions{}); err != nil {
+		t.Fatalf("RetryTransaction failed: %v", err)
+	}
+
+	submitted := relayer.Submissions()
+	if len(submitted) != 1 {
+		t.Fatalf("got %d submissions, want 1", len(submitted))
+	}
+	to, data := requestCall(t, submitted[0])
+	if to != aggregated.To || data != aggregated.Data {
+		t.Errorf("retried call = %s %s, want the original batch %s %s", to, data, aggregated.To, aggregated.Data)
+	}
+	if got := stringValue(submitted[0].Metadata); got != "order 42;retryOf:tx-failed" {
+		t.Errorf("metadata = %q, want the original tagged with retryOf", got)
+	}
+	if submitted[0].ProxyWallet != derived {
+		t.Errorf("ProxyWallet = %s, want %s", submitted[0].ProxyWallet, derived)
+	}
+}
+
+func TestRetryTransaction_FromStore(t *testing.T) {
+	tests := []struct {
+		name         string
+		transactions []models.SafeTransaction
+		resolved     bool
+	}{
+		{name: "batch", transactions: retryBatch()},
+		{name: "single call", transactions: retryBatch()[:1]},
+		{name: "resolved record", transactions: retryBatch(), resolved: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			relayer := newFakeRelayer(t)
+			store, err := NewFileSubmissionStore(filepath.Join(t.TempDir(), "submissions.jsonl"))
+			if err != nil {
+				t.Fatalf("NewFileSubmissionStore failed: %v", err)
+			}
+			c := newTestClient(t, relayer)
+			if err := WithSubmissionStore(store)(c); err != nil {
+				t.Fatalf("WithSubmissionStore failed: %v", err)
+			}
+
+			response, err := c.Execute(tt.transactions, "orig")
+			if err != nil {
+				t.Fatalf("Execute failed: %v", err)
+			}
+			if tt.resolved {
+				if err := store.MarkResolved(response.OperationID); err != nil {
+					t.Fatalf("MarkResolved failed: %v", err)
+				}
+			}
+
+			// The relayer does not echo the call
+			derived, _ := c.GetExpectedSafe()
+			serveTransaction(relayer, models.RelayerTransaction{
+				TransactionID: response.TransactionID,
+				State:         models.STATE_FAILED,
+				Type:          models.SAFE,
+				SafeAddress:   derived,
+			})
+
+			if _, err := c.RetryTransaction(response.TransactionID, RetryOptions{}); err != nil {
+				t.Fatalf("RetryTransaction failed: %v", err)
+			}
+
+			submitted := relayer.Submissions()
+			if len(submitted) != 2 {
+				t.Fatalf("got %d submissions, want 2", len(submitted))
+			}
+			originalTo, originalData := requestCall(t, submitted[0])
+			to, data := requestCall(t, submitted[1])
+			if to != originalTo || data != originalData {
+				t.Errorf("retried call = %s %s, want the original %s %s", to, data, originalTo, originalData)
+			}
+			if stringValue(submitted[1].Nonce) == stringValue(submitted[0].Nonce) {
+				t.Errorf("retry reused nonce %s", stringValue(submitted[1].Nonce))
+			}
+			if got, want := stringValue(submitted[1].Metadata), "orig;retryOf:"+response.TransactionID; got != want {
+				t.Errorf("metadata = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestRetryTransaction_Refusals(t *testing.T) {
+	multisend := "0xA238CBeb142c10Ef7Ad8442C6D1f9E89e07e7761"
+	data := "0x8d80ff0a"
+
+	tests := []struct {
+		name        string
+		txn         models.RelayerTransaction
+		opts        RetryOptions
+		wantPayload bool
+		shouldErr   bool
+	}{
+		{
+			name:      "mined",
+			txn:       models.RelayerTransaction{State: models.STATE_MINED, Type: models.SAFE, To: jsonString(multisend), Data: jsonString(data)},
+			shouldErr: true,
+		},
+		{
+			name:      "executing",
+			txn:       models.RelayerTransaction{State: models.STATE_EXECUTED, Type: models.SAFE, To: jsonString(multisend), Data: jsonString(data)},
+			shouldErr: true,
+		},
+		{
+			name: "mined with force",
+			txn:  models.RelayerTransaction{State: models.STATE_MINED, Type: models.SAFE, To: jsonString(multisend), Data: jsonString(data)},
+			opts: RetryOptions{Force: true},
+		},
+		{
+			name:      "deployment",
+			txn:       models.RelayerTransaction{State: models.STATE_FAILED, Type: models.SAFE_CREATE},
+			shouldErr: true,
+		},
+		{
+			name:        "payload unavailable",
+			txn:         models.RelayerTransaction{State: models.STATE_FAILED, Type: models.SAFE},
+			wantPayload: true,
+			shouldErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			relayer := newFakeRelayer(t)
+			c := newTestClient(t, relayer)
+			tt.txn.TransactionID = "tx-original"
+			tt.txn.SafeAddress, _ = c.GetExpectedSafe()
+			if tt.txn.To != nil {
+				// A plain call rather than a batch
+				tt.txn.To = jsonString("0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174")
+			}
+			serveTransaction(relayer, tt.txn)
+
+			_, err := c.RetryTransaction("tx-original", tt.opts)
+			if (err != nil) != tt.shouldErr {
+				t.Fatalf("RetryTransaction() error = %v, shouldErr %v", err, tt.shouldErr)
+			}
+			var payloadErr *errors.OriginalPayloadUnavailableError
+			if got := stderrors.As(err, &payloadErr); got != tt.wantPayload {
+				t.Errorf("OriginalPayloadUnavailableError = %v, want %v (error: %v)", got, tt.wantPayload, err)
+			}
+
+			wantSubmissions := 0
+			if !tt.shouldErr {
+				wantSubmissions = 1
+			}
+			if n := len(relayer.Submissions()); n != wantSubmissions {
+				t.Errorf("got %d submissions, want %d", n, wantSubmissions)
+			}
+		})
+	}
+}
+
+func TestRetryMetadata(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata *string
+		want     string
+	}{
+		{name: "none", want: "retryOf:tx-1"},
+		{name: "empty", metadata: models.ExplicitMetadata(""), want: "retryOf:tx-1"},
+		{name: "value", metadata: models.ExplicitMetadata("order 42"), want: "order 42;retryOf:tx-1"},
+		{name: "retry of a retry", metadata: models.ExplicitMetadata("order 42;retryOf:tx-0"), want: "order 42;retryOf:tx-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retryMetadata(tt.metadata, "tx-1"); got != tt.want {
+				t.Errorf("retryMetadata() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// jsonString returns s as a JSON string
+func jsonString(s string) json.RawMessage {
+	raw, _ := json.Marshal(s)
+	return raw
+}
@@ -0,0 +1,222 @@
+package client
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/davidt58/go-builder-relayer-client/config"
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+// rotatingRelayer accepts submissions signed with the secrets of its current
+// key IDs and rejects other key IDs with CodeUnknownKey
+type rotatingRelayer struct {
+	mu       sync.Mutex
+	secrets  map[string]string
+	accepted map[string]bool
+	attempts []string
+}
+
+func (r *rotatingRelayer) accept(keyIDs ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.accepted = make(map[string]bool)
+	for _, id := range keyIDs {
+		r.accepted[id] = true
+	}
+	r.attempts = nil
+}
+
+func (r *rotatingRelayer) keyAttempts() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.attempts...)
+}
+
+// wrap checks the key ID and signature before passing the request on to the fake relayer
+func (r *rotatingRelayer) wrap(f *fakeRelayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		req.Body = io.NopCloser(bytes.NewReader(body))
+
+		keyID := req.Header.Get("POLY_BUILDER_KEY_ID")
+		r.mu.Lock()
+		r.attempts = append(r.attempts, keyID)
+		accepted := r.accepted[keyID]
+		secret := r.secrets[keyID]
+		r.mu.Unlock()
+
+		code := errors.CodeUnknownKey
+		if !accepted {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(models.ErrorResponse{Error: "unknown key", Code: &code})
+			return
+		}
+
+		key, _ := base64.URLEncoding.DecodeString(secret)
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(req.Header.Get("POLY_BUILDER_TIMESTAMP") + req.Method + req.URL.Path + string(body)))
+		if req.Header.Get("POLY_BUILDER_SIGNATURE") != base64.URLEncoding.EncodeToString(mac.Sum(nil)) {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(models.ErrorResponse{Error: "bad signature"})
+			return
+		}
+		f.ServeDefault(w, req)
+	}
+}
+
+func rotationCredential(keyID string, notAfter time.Time) config.Credential {
+	return config.Credential{
+		APIKey:     "api-" + keyID,
+		Secret:     base64.URLEncoding.EncodeToString([]byte("secret-" + keyID)),
+		Passphrase: "pass-" + keyID,
+		KeyID:      keyID,
+		NotAfter:   notAfter,
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	future := time.Now().Add(time.Hour)
+	past := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name         string
+		credentials  []config.Credential
+		accepted     []string
+		wantAttempts []string
+		shouldErr    bool
+	}{
+		{
+			name:         "new key not propagated yet",
+			credentials:  []config.Credential{rotationCredential("old", future), rotationCredential("new", time.Time{})},
+			accepted:     []string{"old"},
+			wantAttempts: []string{"new", "old"},
+		},
+		{
+			name:         "overlap window",
+			credentials:  []config.Credential{rotationCredential("old", future), rotationCredential("new", time.Time{})},
+			accepted:     []string{"old", "new"},
+			wantAttempts: []string{"new"},
+		},
+		{
+			name:         "old key retired",
+			credentials:  []config.Credential{rotationCredential("old", past), rotationCredential("new", time.Time{})},
+			accepted:     []string{"new"},
+			wantAttempts: []string{"new"},
+		},
+		{
+			name:         "new key expired locally",
+			credentials:  []config.Credential{rotationCredential("old", time.Time{}), rotationCredential("new", past)},
+			accepted:     []string{"old"},
+			wantAttempts: []string{"old"},
+		},
+		{
+			name:         "no previous key to fall back to",
+			credentials:  []config.Credential{rotationCredential("new", time.Time{})},
+			accepted:     []string{"old"},
+			wantAttempts: []string{"new"},
+			shouldErr:    true,
+		},
+		{
+			name:         "neither key accepted",
+			credentials:  []config.Credential{rotationCredential("old", future), rotationCredential("new", time.Time{})},
+			accepted:     []string{"other"},
+			wantAttempts: []string{"new", "old"},
+			shouldErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			relayer := newFakeRelayer(t)
+			rotating := &rotatingRelayer{secrets: make(map[string]string)}
+			for _, cred := range tt.credentials {
+				rotating.secrets[cred.KeyID] = cred.Secret
+			}
+			rotating.accept(tt.accepted...)
+			relayer.Handle(SUBMIT_TRANSACTION, rotating.wrap(relayer))
+
+			c := newTestClient(t, relayer)
+			c.builderConfig = &config.BuilderConfig{Credentials: tt.credentials}
+
+			_, err := c.Execute(testTransactions(), "")
+			if tt.shouldErr {
+				if !errors.IsUnknownKey(err) {
+					t.Errorf("error = %v, want an unknown key error", err)
+				}
+			} else if err != nil {
+				t.Fatalf("Execute failed: %v", err)
+			}
+
+			if got := rotating.keyAttempts(); !reflect.DeepEqual(got, tt.wantAttempts) {
+				t.Errorf("key attempts = %v, want %v", got, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestKeyRotation_Phases(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	old := rotationCredential("old", time.Now().Add(time.Hour))
+	next := rotationCredential("new", time.Time{})
+	rotating := &rotatingRelayer{secrets: map[string]string{"old": old.Secret, "new": next.Secret}}
+	relayer.Handle(SUBMIT_TRANSACTION, rotating.wrap(relayer))
+
+	c := newTestClient(t, relayer)
+	c.builderConfig = &config.BuilderConfig{Credentials: []config.Credential{old}}
+
+	phases := []struct {
+		name         string
+		credentials  []config.Credential
+		accepted     []string
+		wantAttempts []string
+	}{
+		{name: "before rotation", credentials: []config.Credential{old}, accepted: []string{"old"}, wantAttempts: []string{"old"}},
+		{name: "client rotated first", credentials: []config.Credential{old, next}, accepted: []string{"old"}, wantAttempts: []string{"new", "old"}},
+		{name: "overlap", credentials: []config.Credential{old, next}, accepted: []string{"old", "new"}, wantAttempts: []string{"new"}},
+		{name: "old key removed", credentials: []config.Credential{next}, accepted: []string{"new"}, wantAttempts: []string{"new"}},
+	}
+
+	for _, phase := range phases {
+		c.builderConfig.Credentials = phase.credentials
+		rotating.accept(phase.accepted...)
+
+		if _, err := c.Execute(testTransactions(), ""); err != nil {
+			t.Fatalf("%s: Execute failed: %v", phase.name, err)
+		}
+		if got := rotating.keyAttempts(); !reflect.DeepEqual(got, phase.wantAttempts) {
+			t.Errorf("%s: key attempts = %v, want %v", phase.name, got, phase.wantAttempts)
+		}
+	}
+}
+
+func TestKeyRotation_AllExpiredWarnsOnce(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	past := time.Now().Add(-time.Hour)
+	builderConfig := &config.BuilderConfig{Credentials: []config.Credential{rotationCredential("old", past), rotationCredential("new", past)}}
+
+	var logs bytes.Buffer
+	c, err := NewRelayClient(relayer.URL(), 137, testPrivateKey, builderConfig, WithLogOutput(&logs))
+	if err != nil {
+		t.Fatalf("NewRelayClient failed: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := c.Execute(testTransactions(), ""); err != nil {
+			t.Fatalf("Execute failed: %v", err)
+		}
+	}
+	if n := strings.Count(logs.String(), "builder credentials have expired"); n != 1 {
+		t.Errorf("expiry warned %d times, want once", n)
+	}
+}
@@ -0,0 +1,264 @@
+package client
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/davidt58/go-builder-relayer-client/builder"
+	"github.com/davidt58/go-builder-relayer-client/config"
+	"github.com/davidt58/go-builder-relayer-client/models"
+	"github.com/davidt58/go-builder-relayer-client/signer"
+)
+
+// otherSafe is a Safe the test signer owns that is not its derived Safe
+const otherSafe = "0x5FbDB2315678afecb367f032d93F642f64180aa3"
+
+func TestExecuteOnSafe(t *testing.T) {
+	relayer := newFakeRelayer(t)
+
+	var mu sync.Mutex
+	var nonceAddress string
+	relayer.Handle(GET_NONCE, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		nonceAddress = r.URL.Query().Get("address")
+		mu.Unlock()
+		relayer.ServeDefault(w, r)
+	})
+	c := newTestClient(t, relayer)
+
+	if _, err := c.ExecuteOnSafe("0x5fbdb2315678afecb367f032d93f642f64180aa3", testTransactions(), "on other safe"); err != nil {
+		t.Fatalf("ExecuteOnSafe failed: %v", err)
+	}
+
+	mu.Lock()
+	if nonceAddress != otherSafe {
+		t.Errorf("nonce fetched for %s, want %s", nonceAddress, otherSafe)
+	}
+	mu.Unlock()
+
+	submitted := relayer.Submissions()
+	if len(submitted) != 1 {
+		t.Fatalf("got %d submissions, want 1", len(submitted))
+	}
+	request := submitted[0]
+	if request.ProxyWallet != otherSafe {
+		t.Errorf("ProxyWallet = %s, want %s", request.ProxyWallet, otherSafe)
+	}
+
+	// The signature must be over the struct hash with the provided Safe as verifying contract
+	args := &models.SafeTransactionArgs{
+		SafeAddress:  otherSafe,
+		Transactions: testTransactions(),
+		Nonce:        "0",
+		Metadata:     models.MetadataOf("on other safe"),
+	}
+	expected, err := builder.BuildSafeTransactionRequest(args, c.signer, c.chainID)
+	if err != nil {
+		t.Fatalf("BuildSafeTransactionRequest failed: %v", err)
+	}
+	if request.Signature != expected.Signature {
+		t.Error("signature was not computed with the provided Safe as verifying contract")
+	}
+
+	derived, _ := c.GetExpectedSafe()
+	args.SafeAddress = derived
+	derivedRequest, err := builder.BuildSafeTransactionRequest(args, c.signer, c.chainID)
+	if err != nil {
+		t.Fatalf("BuildSafeTransactionRequest failed: %v", err)
+	}
+	if request.Signature == derivedRequest.Signature {
+		t.Error("signature matches the derived Safe; the provided address was ignored")
+	}
+}
+
+func TestExecuteOnSafe_InvalidAddress(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	c := newTestClient(t, relayer)
+
+	for _, address := range []string{"", "0x1234", "not an address"} {
+		if _, err := c.ExecuteOnSafe(address, testTransactions(), ""); err == nil {
+			t.Errorf("ExecuteOnSafe(%q) should fail", address)
+		}
+	}
+	if len(relayer.OperationIDs()) != 0 {
+		t.Error("no request should be made for an invalid Safe address")
+	}
+}
+
+func TestDeploy_UsesClientContractConfig(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	relayer.SetDeployed(false)
+	c := newTestClient(t, relayer)
+
+	// Swap in an ad-hoc contract set without touching the global registry
+	custom := *c.contractConfig
+	custom.SafeFactory = "0x1234567890123456789012345678901234567890"
+	c.contractConfig = &custom
+
+	expected, err := builder.DeriveSafeAddressWithConfig(c.signer.Address(), &custom)
+	if err != nil {
+		t.Fatalf("DeriveSafeAddressWithConfig failed: %v", err)
+	}
+	safeAddress, err := c.GetExpectedSafe()
+	if err != nil {
+		t.Fatalf("GetExpectedSafe failed: %v", err)
+	}
+	if safeAddress != expected.Hex() {
+		t.Errorf("GetExpectedSafe() = %s, want %s", safeAddress, expected.Hex())
+	}
+
+	if _, err := c.Deploy(); err != nil {
+		t.Fatalf("Deploy failed: %v", err)
+	}
+	submissions := relayer.Submissions()
+	if len(submissions) != 1 {
+		t.Fatalf("submissions = %d, want 1", len(submissions))
+	}
+	if got := string(submissions[0].To); got != `"`+custom.SafeFactory+`"` {
+		t.Errorf("To = %s, want custom factory %s", got, custom.SafeFactory)
+	}
+	if submissions[0].ProxyWallet != expected.Hex() {
+		t.Errorf("ProxyWallet = %s, want %s", submissions[0].ProxyWallet, expected.Hex())
+	}
+}
+
+func TestWithSignatureScheme(t *testing.T) {
+	tests := []struct {
+		name      string
+		scheme    signer.SignatureScheme
+		validV    []byte
+		shouldErr bool
+	}{
+		{name: "default", validV: []byte{31, 32}},
+		{name: "eth_sign", scheme: signer.SchemeEthSign, validV: []byte{31, 32}},
+		{name: "eip712", scheme: signer.SchemeEIP712, validV: []byte{27, 28}},
+		{name: "unknown", scheme: "personal_sign", shouldErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			relayer := newFakeRelayer(t)
+			secret := base64.URLEncoding.EncodeToString([]byte("test-secret"))
+			builderConfig := config.NewBuilderConfig("test-key", secret, "test-pass")
+
+			var opts []Option
+			if tt.scheme != "" {
+				opts = append(opts, WithSignatureScheme(tt.scheme))
+			}
+			c, err := NewRelayClient(relayer.URL(), 137, testPrivateKey, builderConfig, opts...)
+			if tt.shouldErr {
+				if err == nil {
+					t.Fatal("Expected error for unknown signature scheme")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewRelayClient failed: %v", err)
+			}
+			c.logger = log.New(io.Discard, "", 0)
+
+			if _, err := c.Execute(testTransactions(), ""); err != nil {
+				t.Fatalf("Execute failed: %v", err)
+			}
+			submissions := relayer.Submissions()
+			if len(submissions) != 1 {
+				t.Fatalf("Expected 1 submission, got %d", len(submissions))
+			}
+			v := hexutil.MustDecode(submissions[0].Signature)[64]
+			if v != tt.validV[0] && v != tt.validV[1] {
+				t.Errorf("v = %d, want one of %v", v, tt.validV)
+			}
+
+			// The global registry keeps its scheme
+			registered, err := config.GetContractConfig(137)
+			if err != nil {
+				t.Fatalf("GetContractConfig failed: %v", err)
+			}
+			if registered.SignatureScheme != "" {
+				t.Errorf("global contract config scheme changed to %q", registered.SignatureScheme)
+			}
+		})
+	}
+}
+
+func TestExecuteWithOptions_MultisendCallOnly(t *testing.T) {
+	call := *models.NewSafeTransaction("0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174", "0", "0x")
+	delegateCall := call
+	delegateCall.Operation = models.DelegateCall
+
+	tests := []struct {
+		name         string
+		transactions []models.SafeTransaction
+		variant      config.MultisendVariant
+		wantTo       string
+		shouldErr    bool
+	}{
+		{name: "standard", transactions: []models.SafeTransaction{call, delegateCall}, wantTo: "0xA238CBeb142c10Ef7Ad8442C6D1f9E89e07e7761"},
+		{name: "call-only", transactions: []models.SafeTransaction{call, call}, variant: config.MultisendCallOnly, wantTo: "0x40A2aCCbd92BCA938b02010E17A5b8929b49130D"},
+		{name: "call-only rejects delegatecall", transactions: []models.SafeTransaction{call, delegateCall}, variant: config.MultisendCallOnly, shouldErr: true},
+		{name: "unknown variant", transactions: []models.SafeTransaction{call, call}, variant: "delegate_only", shouldErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			relayer := newFakeRelayer(t)
+			c := newTestClient(t, relayer)
+
+			_, err := c.ExecuteWithOptions(tt.transactions, "", ExecuteOptions{Multisend: tt.variant})
+			submissions := relayer.Submissions()
+			if tt.shouldErr {
+				if err == nil {
+					t.Fatal("Expected error")
+				}
+				if len(submissions) != 0 {
+					t.Errorf("Expected nothing submitted, got %d submissions", len(submissions))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ExecuteWithOptions failed: %v", err)
+			}
+			if len(submissions) != 1 {
+				t.Fatalf("Expected 1 submission, got %d", len(submissions))
+			}
+			var to string
+			if err := json.Unmarshal(submissions[0].To, &to); err != nil {
+				t.Fatalf("To is not a single address: %v", err)
+			}
+			if to != tt.wantTo {
+				t.Errorf("To = %s, want %s", to, tt.wantTo)
+			}
+		})
+	}
+}
+
+func TestExecuteWithOptions_SelfExec(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	c := newTestClient(t, relayer)
+	safeAddress, err := c.GetExpectedSafe()
+	if err != nil {
+		t.Fatalf("GetExpectedSafe failed: %v", err)
+	}
+	transactions := append(testTransactions(), *models.NewSafeTransaction(safeAddress, "0", "0x6a761202"))
+
+	if _, err := c.ExecuteWithOptions(transactions, "", ExecuteOptions{}); err == nil {
+		t.Fatal("Expected a nested execTransaction to be rejected")
+	}
+	if len(relayer.Submissions()) != 0 {
+		t.Fatalf("Expected nothing submitted, got %d submissions", len(relayer.Submissions()))
+	}
+
+	if _, err := c.ExecuteWithOptions(transactions, "", ExecuteOptions{AllowSelfExec: true}); err != nil {
+		t.Fatalf("ExecuteWithOptions with AllowSelfExec failed: %v", err)
+	}
+	if len(relayer.Submissions()) != 1 {
+		t.Errorf("Expected 1 submission, got %d", len(relayer.Submissions()))
+	}
+}
@@ -0,0 +1,112 @@
+package client
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/davidt58/go-builder-relayer-client/errors"
+)
+
+// ContractCaller makes read-only contract calls. *ethclient.Client satisfies
+// this interface; an RPC client passed to WithRPCClient needs it for
+// GetSafeVersion and for ExecuteOnSafe's version detection.
+type ContractCaller interface {
+	CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error)
+}
+
+// safeVersionSelector is the selector of the Safe's VERSION() getter
+var safeVersionSelector = crypto.Keccak256([]byte("VERSION()"))[:4]
+
+// GetSafeVersion returns the version a Safe reports through VERSION(), e.g.
+// "1.3.0". Results are cached for the life of the client. Requires an RPC
+// client that can call contracts (see WithRPCClient and ContractCaller).
+func (c *RelayClient) GetSafeVersion(safeAddress string) (string, error) {
+	if !common.IsHexAddress(safeAddress) {
+		return "", errors.ErrInvalidAddress(safeAddress)
+	}
+	return c.safeVersion(context.Background(), common.HexToAddress(safeAddress))
+}
+
+// safeVersion returns the cached version of safe, reading VERSION() on a miss
+func (c *RelayClient) safeVersion(ctx context.Context, safe common.Address) (string, error) {
+	c.safeVersionMu.Lock()
+	version, ok := c.safeVersions[safe]
+	c.safeVersionMu.Unlock()
+	if ok {
+		return version, nil
+	}
+
+	caller, ok := c.rpcClient.(ContractCaller)
+	if !ok {
+		return "", errors.ErrInvalidConfiguration("GetSafeVersion requires an RPC client that can call contracts")
+	}
+
+	output, err := caller.CallContract(ctx, ethereum.CallMsg{To: &safe, Data: safeVersionSelector}, nil)
+	if err != nil {
+		return "", err
+	}
+	version, err = decodeSafeVersion(output)
+	if err != nil {
+		return "", err
+	}
+
+	c.safeVersionMu.Lock()
+	defer c.safeVersionMu.Unlock()
+	if c.safeVersions == nil {
+		c.safeVersions = make(map[common.Address]string)
+	}
+	c.safeVersions[safe] = version
+	return version, nil
+}
+
+// decodeSafeVersion decodes the ABI-encoded string returned by VERSION()
+func decodeSafeVersion(output []byte) (string, error) {
+	stringType, err := abi.NewType("string", "", nil)
+	if err != nil {
+		return "", err
+	}
+	values, err := abi.Arguments{{Type: stringType}}.Unpack(output)
+	if err != nil || len(values) != 1 {
+		return "", errors.NewRelayerClientError("invalid VERSION() response", err)
+	}
+	version, _ := values[0].(string)
+	if version == "" {
+		return "", errors.NewRelayerClientError("empty VERSION() response", nil)
+	}
+	return version, nil
+}
+
+// detectSafeVersion returns the version to sign for when executing on
+// safeAddress. The derived Safe, and any Safe when there is no RPC client
+// that can call contracts, is assumed to be builder.CurrentSafeVersion,
+// reported as "", as is every Safe under Flags.UseEIP712DomainChainID. A
+// failed read is logged and treated the same way; the relayer still rejects
+// a signature made for the wrong layout.
+func (c *RelayClient) detectSafeVersion(op *operation, safeAddress string) (string, error) {
+	if safeAddress == "" || c.flags.UseEIP712DomainChainID {
+		return "", nil
+	}
+	if _, ok := c.rpcClient.(ContractCaller); !ok {
+		return "", nil
+	}
+	if derived, err := c.GetExpectedSafe(); err == nil && derived == common.HexToAddress(safeAddress).Hex() {
+		return "", nil
+	}
+
+	var version string
+	err := op.run(stepSafeVersion, func(ctx context.Context) error {
+		var versionErr error
+		version, versionErr = c.safeVersion(ctx, common.HexToAddress(safeAddress))
+		if versionErr != nil && ctx.Err() == nil {
+			c.logger.Printf("Safe version check for %s failed, assuming the current layout: %v", safeAddress, versionErr)
+			version, versionErr = "", nil
+		}
+		return versionErr
+	})
+	return version, err
+}
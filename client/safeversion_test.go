@@ -0,0 +1,139 @@
+package client
+
+import (
+	"context"
+	stderrors "errors"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/davidt58/go-builder-relayer-client/builder"
+	"github.com/davidt58/go-builder-relayer-client/config"
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+// versionRPC answers VERSION() calls with version, or fails with err
+type versionRPC struct {
+	receiptOnly
+
+	mu      sync.Mutex
+	version string
+	err     error
+	calls   int
+}
+
+func (v *versionRPC) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.calls++
+	if v.err != nil {
+		return nil, v.err
+	}
+	stringType, _ := abi.NewType("string", "", nil)
+	return abi.Arguments{{Type: stringType}}.Pack(v.version)
+}
+
+func (v *versionRPC) callCount() int {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.calls
+}
+
+func TestGetSafeVersion(t *testing.T) {
+	tests := []struct {
+		name      string
+		rpc       ReceiptFetcher
+		address   string
+		want      string
+		shouldErr bool
+	}{
+		{name: "version", rpc: &versionRPC{version: "1.1.1"}, address: otherSafe, want: "1.1.1"},
+		{name: "call fails", rpc: &versionRPC{err: stderrors.New("execution reverted")}, address: otherSafe, shouldErr: true},
+		{name: "no rpc", address: otherSafe, shouldErr: true},
+		{name: "rpc cannot call", rpc: receiptOnly{}, address: otherSafe, shouldErr: true},
+		{name: "invalid address", rpc: &versionRPC{version: "1.3.0"}, address: "0x1234", shouldErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(t, newFakeRelayer(t))
+			c.rpcClient = tt.rpc
+
+			got, err := c.GetSafeVersion(tt.address)
+			if (err != nil) != tt.shouldErr {
+				t.Fatalf("GetSafeVersion() error = %v, shouldErr %v", err, tt.shouldErr)
+			}
+			if got != tt.want {
+				t.Errorf("GetSafeVersion() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecuteOnSafe_SafeVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		version string
+		err     error
+		want    string
+	}{
+		{name: "legacy 1.1.1", version: "1.1.1", want: "1.1.1"},
+		{name: "legacy 0.1.0", version: "0.1.0", want: "0.1.0"},
+		{name: "current", version: "1.3.0", want: "1.3.0"},
+		{name: "version read fails", err: stderrors.New("execution reverted"), want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			relayer := newFakeRelayer(t)
+			rpc := &versionRPC{version: tt.version, err: tt.err}
+			c := newTestClient(t, relayer)
+			c.rpcClient = rpc
+
+			for i := 0; i < 2; i++ {
+				if _, err := c.ExecuteOnSafe(otherSafe, testTransactions(), ""); err != nil {
+					t.Fatalf("ExecuteOnSafe failed: %v", err)
+				}
+			}
+			if tt.err == nil && rpc.callCount() != 1 {
+				t.Errorf("VERSION() called %d times, want 1 (cached)", rpc.callCount())
+			}
+
+			submitted := relayer.Submissions()
+			args := &models.SafeTransactionArgs{
+				SafeAddress:  otherSafe,
+				Transactions: testTransactions(),
+				Nonce:        "0",
+			}
+			expected, err := builder.BuildSafeTransactionRequestForSafeVersion(args, c.signer, c.contractConfig, config.MultisendStandard, tt.want)
+			if err != nil {
+				t.Fatalf("BuildSafeTransactionRequestForSafeVersion failed: %v", err)
+			}
+			if submitted[0].Signature != expected.Signature {
+				t.Errorf("signature was not made for Safe version %q", tt.want)
+			}
+		})
+	}
+}
+
+func TestExecute_DerivedSafeSkipsVersionCheck(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	rpc := &versionRPC{version: "1.1.1"}
+	c := newTestClient(t, relayer)
+	c.rpcClient = rpc
+
+	derived, _ := c.GetExpectedSafe()
+	if _, err := c.Execute(testTransactions(), ""); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if _, err := c.ExecuteOnSafe(common.HexToAddress(derived).Hex(), testTransactions(), ""); err != nil {
+		t.Fatalf("ExecuteOnSafe failed: %v", err)
+	}
+	if n := rpc.callCount(); n != 0 {
+		t.Errorf("VERSION() called %d times for the derived Safe, want 0", n)
+	}
+}
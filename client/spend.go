@@ -0,0 +1,365 @@
+package client
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/davidt58/go-builder-relayer-client/builder"
+	"github.com/davidt58/go-builder-relayer-client/constants"
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+// defaultSpendWindow is the rolling window of SpendRule.MaxPerWindow by default
+const defaultSpendWindow = 24 * time.Hour
+
+// SpendRule limits the amount of one token a Safe's batches may move
+type SpendRule struct {
+	// Token is the token contract address
+	Token string
+	// MaxPerTransaction caps the amount one batch moves; nil means no cap
+	MaxPerTransaction *big.Int
+	// MaxPerWindow caps the amount moved within the policy's rolling
+	// window, this batch included; nil means no cap
+	MaxPerWindow *big.Int
+}
+
+// UnknownSpend is a call to a rule's token that the spend policy could not
+// count: calldata that is not transfer, transferFrom or approve, or that
+// does not decode, and DelegateCalls
+type UnknownSpend struct {
+	// Safe is the Safe executing the batch
+	Safe string
+	// Token is the token contract address
+	Token string
+	// Index is the transaction's position in the batch; for a call batched
+	// in a MultiSend transaction, the position of that transaction
+	Index int
+	// Data is the transaction's calldata
+	Data string
+}
+
+// SpendPolicy is a circuit breaker on the tokens a Safe moves. Before a batch
+// is signed, the amounts of its transfer, transferFrom and approve calls to
+// each rule's token, including those batched in a DelegateCall of the
+// client's MultiSend contracts, are summed and checked against the rule;
+// approvals count at their full allowance, so an unlimited (MaxUint256)
+// approval exceeds any limit. A batch over a limit fails with a
+// SpendLimitExceededError unless the call's ExecuteOptions.OverrideToken
+// matches the policy's OverrideToken.
+type SpendPolicy struct {
+	// Rules are the limits, at most one per token
+	Rules []SpendRule
+	// Window is the length of the rolling window of MaxPerWindow (default 24h)
+	Window time.Duration
+	// Store records submitted spends for the window (default a new
+	// MemorySpendStore). A shared store enforces one window across clients.
+	Store SpendStore
+	// OnUnknown is called for each call to a rule's token that does not
+	// count against the limits; nil logs them
+	OnUnknown func(UnknownSpend)
+	// OverrideToken is the secret a call passes as ExecuteOptions.OverrideToken
+	// to exceed the limits. Empty means the limits cannot be overridden.
+	OverrideToken string
+}
+
+// SpendStore records the token amounts Safes moved for rolling spend limits.
+// Reserve must check and record atomically per Safe and token, so that
+// concurrent batches, from this client or others sharing the store, cannot
+// together exceed a window limit.
+type SpendStore interface {
+	// Spent returns the amount of token that safe moved at or after since
+	Spent(safe, token string, since time.Time) (*big.Int, error)
+	// Reserve records amount of token moved by safe at at, unless the amount
+	// moved at or after since plus amount exceeds max (nil means no cap). It
+	// returns the reservation's ID, empty when nothing was recorded, and the
+	// amount moved since since with amount included.
+	Reserve(safe, token string, amount, max *big.Int, since, at time.Time) (string, *big.Int, error)
+	// Release removes reservation id of a batch that was not submitted
+	Release(safe, token, id string) error
+}
+
+// WithSpendPolicy enforces policy on every Safe transaction the client
+// builds, from Execute, ExecuteOnSafe, the submission queue and nonce
+// reservations
+func WithSpendPolicy(policy SpendPolicy) Option {
+	return func(c *RelayClient) error {
+		if len(policy.Rules) == 0 {
+			return errors.ErrMissingRequiredField("spend policy rules")
+		}
+		if policy.Window < 0 {
+			return errors.ErrInvalidConfiguration("spend window must not be negative")
+		}
+		if policy.Window == 0 {
+			policy.Window = defaultSpendWindow
+		}
+		if policy.Store == nil {
+			policy.Store = NewMemorySpendStore()
+		}
+
+		rules := make([]SpendRule, len(policy.Rules))
+		seen := make(map[common.Address]bool, len(policy.Rules))
+		for i, rule := range policy.Rules {
+			if !common.IsHexAddress(rule.Token) {
+				return errors.ErrInvalidAddress(rule.Token)
+			}
+			token := common.HexToAddress(rule.Token)
+			if seen[token] {
+				return errors.ErrInvalidConfiguration("more than one spend rule for token " + token.Hex())
+			}
+			seen[token] = true
+			for _, max := range []*big.Int{rule.MaxPerTransaction, rule.MaxPerWindow} {
+				if max != nil && max.Sign() < 0 {
+					return errors.ErrInvalidConfiguration("spend limits for token " + token.Hex() + " must not be negative")
+				}
+			}
+			rule.Token = token.Hex()
+			rules[i] = rule
+		}
+		policy.Rules = rules
+
+		c.spendPolicy = &policy
+		return nil
+	}
+}
+
+// spendRule returns the policy's rule for token
+func (p *SpendPolicy) spendRule(token string) (SpendRule, bool) {
+	if !common.IsHexAddress(token) {
+		return SpendRule{}, false
+	}
+	token = common.HexToAddress(token).Hex()
+	for _, rule := range p.Rules {
+		if rule.Token == token {
+			return rule, true
+		}
+	}
+	return SpendRule{}, false
+}
+
+// spendHold is the window reservations a batch holds in the spend store
+type spendHold struct {
+	safe string
+	// ids are the reservation IDs by token
+	ids map[string]string
+}
+
+// checkSpendPolicy sums the outflows of transactions per policy token and
+// reserves them in the spend store, checking the window limits as it does.
+// A limit that is exceeded fails the batch unless override matches the
+// policy's OverrideToken, in which case it is logged. The returned hold must
+// be passed to releaseSpends if the batch is not submitted.
+func (c *RelayClient) checkSpendPolicy(safe string, transactions []models.SafeTransaction, override string) (*spendHold, error) {
+	policy := c.spendPolicy
+	if policy == nil {
+		return nil, nil
+	}
+
+	outflows := make(map[string]*big.Int)
+	for i, tx := range transactions {
+		if err := c.sumOutflows(safe, i, tx, outflows); err != nil {
+			return nil, err
+		}
+	}
+
+	tokens := make([]string, 0, len(outflows))
+	for token := range outflows {
+		tokens = append(tokens, token)
+	}
+	sort.Strings(tokens)
+
+	overridden := override != "" && policy.OverrideToken != "" &&
+		subtle.ConstantTimeCompare([]byte(override), []byte(policy.OverrideToken)) == 1
+	now := c.clock.Now()
+	since := now.Add(-policy.Window)
+	hold := &spendHold{safe: safe, ids: make(map[string]string, len(tokens))}
+	for _, token := range tokens {
+		rule, _ := policy.spendRule(token)
+		attempted := outflows[token]
+		if rule.MaxPerTransaction != nil && attempted.Cmp(rule.MaxPerTransaction) > 0 {
+			exceeded := errors.ErrSpendLimitExceeded(token, errors.SpendLimitPerTransaction, attempted, attempted, rule.MaxPerTransaction, 0)
+			if !overridden {
+				c.releaseSpends(hold)
+				return nil, exceeded
+			}
+			c.logger.Printf("Spend limit overridden: %v", exceeded)
+		}
+
+		// An overridden batch is recorded whatever the window holds
+		max := rule.MaxPerWindow
+		if overridden {
+			max = nil
+		}
+		id, total, err := policy.Store.Reserve(safe, token, attempted, max, since, now)
+		if err != nil {
+			c.releaseSpends(hold)
+			return nil, errors.NewRelayerClientError("spend store reservation failed", err)
+		}
+		if id == "" {
+			c.releaseSpends(hold)
+			return nil, errors.ErrSpendLimitExceeded(token, errors.SpendLimitWindow, attempted, total, rule.MaxPerWindow, policy.Window)
+		}
+		hold.ids[token] = id
+		if rule.MaxPerWindow != nil && total.Cmp(rule.MaxPerWindow) > 0 {
+			c.logger.Printf("Spend limit overridden: %v", errors.ErrSpendLimitExceeded(token, errors.SpendLimitWindow, attempted, total, rule.MaxPerWindow, policy.Window))
+		}
+	}
+	return hold, nil
+}
+
+// sumOutflows adds the amount tx moves of its policy token to outflows. A
+// DelegateCall of multiSend(bytes) on one of the client's MultiSend
+// contracts is unwrapped and each call it batches summed in turn; one that
+// does not decode fails the batch, as its transfers could not be counted.
+// index is the position in the batch of the outermost transaction.
+func (c *RelayClient) sumOutflows(safe string, index int, tx models.SafeTransaction, outflows map[string]*big.Int) error {
+	// The data is normalized first, as it is for signing, so unprefixed or
+	// uppercase calldata is counted like any other
+	data, dataErr := models.NormalizeHexData(tx.Data)
+	if dataErr == nil && tx.Operation == models.DelegateCall && c.isMultisend(tx.To) && strings.HasPrefix(data, constants.MULTISEND_FUNCTION_SELECTOR) {
+		batched, err := builder.DecodeMultiSendCallData(hexutil.MustDecode(data))
+		if err != nil {
+			return errors.NewRelayerClientError(fmt.Sprintf("spend policy cannot decode the multisend call of transaction %d", index), err)
+		}
+		for _, inner := range batched {
+			if err := c.sumOutflows(safe, index, inner, outflows); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	rule, ok := c.spendPolicy.spendRule(tx.To)
+	if !ok {
+		return nil
+	}
+	// A DelegateCall runs the token's code as the Safe, moving nothing
+	// of the token's; calldata that fails to decode is not counted either
+	var call *builder.ERC20Call
+	if dataErr == nil && tx.Operation == models.Call {
+		call, _, _ = builder.DecodeERC20Call(data)
+	}
+	if call == nil {
+		c.reportUnknownSpend(UnknownSpend{Safe: safe, Token: rule.Token, Index: index, Data: tx.Data})
+		return nil
+	}
+	if outflows[rule.Token] == nil {
+		outflows[rule.Token] = new(big.Int)
+	}
+	outflows[rule.Token].Add(outflows[rule.Token], call.Amount)
+	return nil
+}
+
+// releaseSpends removes the reservations of a batch that was not submitted.
+// A store failure is only logged; the amounts then count until they leave
+// the window.
+func (c *RelayClient) releaseSpends(hold *spendHold) {
+	if hold == nil {
+		return
+	}
+	for token, id := range hold.ids {
+		if err := c.spendPolicy.Store.Release(hold.safe, token, id); err != nil {
+			c.logger.Printf("Warning: failed to release spend reservation %s of token %s: %v", id, token, err)
+		}
+	}
+}
+
+// reportUnknownSpend passes spend to the policy's OnUnknown, or logs it
+func (c *RelayClient) reportUnknownSpend(spend UnknownSpend) {
+	if c.spendPolicy.OnUnknown != nil {
+		c.spendPolicy.OnUnknown(spend)
+		return
+	}
+	c.logger.Printf("Spend policy: transaction %d calls token %s with calldata it cannot count", spend.Index, spend.Token)
+}
+
+// MemorySpendStore is a SpendStore for clients in the same process. Spends
+// older than the since of a Spent call are discarded, so a store should only
+// be shared by policies with the same window.
+type MemorySpendStore struct {
+	mu     sync.Mutex
+	spends map[string][]spendEntry
+}
+
+// spendEntry is one recorded spend
+type spendEntry struct {
+	id     string
+	amount *big.Int
+	at     time.Time
+}
+
+// NewMemorySpendStore returns an empty MemorySpendStore
+func NewMemorySpendStore() *MemorySpendStore {
+	return &MemorySpendStore{spends: make(map[string][]spendEntry)}
+}
+
+// Spent implements SpendStore
+func (s *MemorySpendStore) Spent(safe, token string, since time.Time) (*big.Int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.spent(spendKey(safe, token), since), nil
+}
+
+// Reserve implements SpendStore
+func (s *MemorySpendStore) Reserve(safe, token string, amount, max *big.Int, since, at time.Time) (string, *big.Int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := spendKey(safe, token)
+	total := s.spent(key, since)
+	total.Add(total, amount)
+	if max != nil && total.Cmp(max) > 0 {
+		return "", total, nil
+	}
+
+	id := newReservationID()
+	s.spends[key] = append(s.spends[key], spendEntry{id: id, amount: new(big.Int).Set(amount), at: at})
+	return id, total, nil
+}
+
+// Release implements SpendStore. An unknown id, such as one already
+// discarded by Spent, is not an error.
+func (s *MemorySpendStore) Release(safe, token, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := spendKey(safe, token)
+	entries := s.spends[key]
+	for i, entry := range entries {
+		if entry.id == id {
+			s.spends[key] = append(entries[:i], entries[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+// spent sums the spends of key at or after since, discarding older ones;
+// s.mu must be held
+func (s *MemorySpendStore) spent(key string, since time.Time) *big.Int {
+	entries := s.spends[key]
+	kept := entries[:0]
+	total := new(big.Int)
+	for _, entry := range entries {
+		if entry.at.Before(since) {
+			continue
+		}
+		kept = append(kept, entry)
+		total.Add(total, entry.amount)
+	}
+	s.spends[key] = kept
+	return total
+}
+
+// spendKey identifies the spends of token by safe
+func spendKey(safe, token string) string {
+	return strings.ToLower(safe) + "/" + strings.ToLower(token)
+}
@@ -0,0 +1,357 @@
+package client
+
+import (
+	stderrors "errors"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+
+	"github.com/davidt58/go-builder-relayer-client/builder"
+	"github.com/davidt58/go-builder-relayer-client/config"
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+const (
+	spendUSDC      = "0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174"
+	spendRecipient = "0x4D97DCd97eC945f40cF65F87097ACe5EA0476045"
+)
+
+// tokenCall returns a call of an ERC20ABI method on token
+func tokenCall(t *testing.T, token, method string, args ...interface{}) models.SafeTransaction {
+	t.Helper()
+	tx, err := models.NewTxBuilder().To(token).FromABICall(builder.ERC20ABI, method, args...).Build()
+	if err != nil {
+		t.Fatalf("building %s failed: %v", method, err)
+	}
+	return tx
+}
+
+// multiSendCall returns the DelegateCall of the test client's MultiSend
+// contract batching transactions
+func multiSendCall(t *testing.T, transactions ...models.SafeTransaction) models.SafeTransaction {
+	t.Helper()
+	payload, err := builder.EncodeMultiSendData(transactions)
+	if err != nil {
+		t.Fatalf("EncodeMultiSendData failed: %v", err)
+	}
+	contractConfig, err := config.GetContractConfig(137)
+	if err != nil {
+		t.Fatalf("GetContractConfig failed: %v", err)
+	}
+	tx, err := builder.WrapMultiSendPayload(payload, contractConfig.SafeMultisend)
+	if err != nil {
+		t.Fatalf("WrapMultiSendPayload failed: %v", err)
+	}
+	return *tx
+}
+
+// newSpendClient returns a test client enforcing policy
+func newSpendClient(t *testing.T, relayer *fakeRelayer, policy SpendPolicy) *RelayClient {
+	t.Helper()
+	c := newTestClient(t, relayer)
+	if err := WithSpendPolicy(policy)(c); err != nil {
+		t.Fatalf("WithSpendPolicy failed: %v", err)
+	}
+	return c
+}
+
+func TestSpendPolicy_PerTransaction(t *testing.T) {
+	recipient := common.HexToAddress(spendRecipient)
+	owner := common.HexToAddress("0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266")
+
+	tests := []struct {
+		name        string
+		batch       func(t *testing.T) []models.SafeTransaction
+		override    string
+		wantErr     bool
+		wantAmount  int64
+		wantUnknown int
+	}{
+		{
+			name: "single transfer within the limit",
+			batch: func(t *testing.T) []models.SafeTransaction {
+				return []models.SafeTransaction{tokenCall(t, spendUSDC, "transfer", recipient, big.NewInt(100))}
+			},
+		},
+		{
+			name: "single transfer over the limit",
+			batch: func(t *testing.T) []models.SafeTransaction {
+				return []models.SafeTransaction{tokenCall(t, spendUSDC, "transfer", recipient, big.NewInt(101))}
+			},
+			wantErr:    true,
+			wantAmount: 101,
+		},
+		{
+			name: "batch sums transfer, transferFrom and approve",
+			batch: func(t *testing.T) []models.SafeTransaction {
+				return []models.SafeTransaction{
+					tokenCall(t, spendUSDC, "transfer", recipient, big.NewInt(40)),
+					*models.NewSafeTransaction(spendRecipient, "0", "0x"),
+					tokenCall(t, spendUSDC, "transferFrom", owner, recipient, big.NewInt(30)),
+					tokenCall(t, spendUSDC, "approve", recipient, big.NewInt(31)),
+				}
+			},
+			wantErr:    true,
+			wantAmount: 101,
+		},
+		{
+			name: "transfer batched in a MultiSend payload over the limit",
+			batch: func(t *testing.T) []models.SafeTransaction {
+				return []models.SafeTransaction{
+					tokenCall(t, spendUSDC, "transfer", recipient, big.NewInt(60)),
+					multiSendCall(t,
+						*models.NewSafeTransaction(spendRecipient, "0", "0x"),
+						tokenCall(t, spendUSDC, "transfer", recipient, big.NewInt(41)),
+					),
+				}
+			},
+			wantErr:    true,
+			wantAmount: 101,
+		},
+		{
+			name: "unknown calldata is reported, not counted",
+			batch: func(t *testing.T) []models.SafeTransaction {
+				return []models.SafeTransaction{
+					tokenCall(t, spendUSDC, "transfer", recipient, big.NewInt(100)),
+					*models.NewSafeTransaction(spendUSDC, "0", "0x70a08231"),
+					*models.NewSafeTransaction(spendUSDC, "0", "0xa9059cbb"),
+				}
+			},
+			wantUnknown: 2,
+		},
+		{
+			name: "override",
+			batch: func(t *testing.T) []models.SafeTransaction {
+				return []models.SafeTransaction{tokenCall(t, spendUSDC, "transfer", recipient, big.NewInt(500))}
+			},
+			override: "treasury rebalance",
+		},
+		{
+			name: "override with the wrong token",
+			batch: func(t *testing.T) []models.SafeTransaction {
+				return []models.SafeTransaction{tokenCall(t, spendUSDC, "transfer", recipient, big.NewInt(500))}
+			},
+			override:   "anything",
+			wantErr:    true,
+			wantAmount: 500,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			relayer := newFakeRelayer(t)
+			var unknown []UnknownSpend
+			c := newSpendClient(t, relayer, SpendPolicy{
+				Rules:         []SpendRule{{Token: spendUSDC, MaxPerTransaction: big.NewInt(100)}},
+				OnUnknown:     func(spend UnknownSpend) { unknown = append(unknown, spend) },
+				OverrideToken: "treasury rebalance",
+			})
+
+			_, err := c.ExecuteWithOptions(tt.batch(t), "", ExecuteOptions{OverrideToken: tt.override})
+			var exceeded *errors.SpendLimitExceededError
+			if tt.wantErr {
+				if !stderrors.As(err, &exceeded) {
+					t.Fatalf("error = %v, want a SpendLimitExceededError", err)
+				}
+				if exceeded.Limit != errors.SpendLimitPerTransaction || exceeded.Attempted.Int64() != tt.wantAmount || exceeded.Max.Int64() != 100 {
+					t.Errorf("error = %+v, want %d over the per-transaction limit of 100", exceeded, tt.wantAmount)
+				}
+				if exceeded.Token != spendUSDC {
+					t.Errorf("Token = %s, want %s", exceeded.Token, spendUSDC)
+				}
+				if n := len(relayer.Submissions()); n != 0 {
+					t.Errorf("got %d submissions, want none", n)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Execute failed: %v", err)
+			}
+			if len(unknown) != tt.wantUnknown {
+				t.Errorf("reported %d unknown calls, want %d", len(unknown), tt.wantUnknown)
+			}
+			for _, spend := range unknown {
+				if spend.Token != spendUSDC || spend.Index == 0 {
+					t.Errorf("unknown spend = %+v", spend)
+				}
+			}
+		})
+	}
+}
+
+func TestSpendPolicy_UndecodableMultiSend(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	c := newSpendClient(t, relayer, SpendPolicy{
+		Rules: []SpendRule{{Token: spendUSDC, MaxPerTransaction: big.NewInt(100)}},
+	})
+
+	batched := multiSendCall(t, tokenCall(t, spendUSDC, "transfer", common.HexToAddress(spendRecipient), big.NewInt(1)))
+	// Declare more payload than the calldata holds
+	batched.Data = batched.Data[:10+64] + strings.Repeat("f", 64) + batched.Data[10+128:]
+
+	if _, err := c.Execute([]models.SafeTransaction{batched}, ""); err == nil {
+		t.Fatal("Execute of an undecodable MultiSend call succeeded")
+	}
+	if n := len(relayer.Submissions()); n != 0 {
+		t.Errorf("got %d submissions, want none", n)
+	}
+}
+
+func TestSpendPolicy_RollingWindow(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	store := NewMemorySpendStore()
+	c := newSpendClient(t, relayer, SpendPolicy{
+		Rules:  []SpendRule{{Token: spendUSDC, MaxPerWindow: big.NewInt(250)}},
+		Window: 24 * time.Hour,
+		Store:  store,
+	})
+	transfer := func(amount int64) []models.SafeTransaction {
+		return []models.SafeTransaction{tokenCall(t, spendUSDC, "transfer", common.HexToAddress(spendRecipient), big.NewInt(amount))}
+	}
+
+	if _, err := c.Execute(transfer(100), ""); err != nil {
+		t.Fatalf("first Execute failed: %v", err)
+	}
+	testClock(c).Advance(12 * time.Hour)
+	if _, err := c.Execute(transfer(100), ""); err != nil {
+		t.Fatalf("second Execute failed: %v", err)
+	}
+
+	// 100 + 100 + 60 is over the window's 250
+	_, err := c.Execute(transfer(60), "")
+	var exceeded *errors.SpendLimitExceededError
+	if !stderrors.As(err, &exceeded) {
+		t.Fatalf("error = %v, want a SpendLimitExceededError", err)
+	}
+	if exceeded.Limit != errors.SpendLimitWindow || exceeded.Attempted.Int64() != 60 || exceeded.Total.Int64() != 260 || exceeded.Window != 24*time.Hour {
+		t.Errorf("error = %+v, want 60 bringing the 24h total to 260", exceeded)
+	}
+
+	// Once the first spend leaves the window there is room again
+	testClock(c).Advance(12*time.Hour + time.Second)
+	if _, err := c.Execute(transfer(60), ""); err != nil {
+		t.Fatalf("Execute after the window moved failed: %v", err)
+	}
+	safe, err := c.GetExpectedSafe()
+	if err != nil {
+		t.Fatalf("GetExpectedSafe failed: %v", err)
+	}
+	spent, err := store.Spent(safe, spendUSDC, testClock(c).Now().Add(-24*time.Hour))
+	if err != nil || spent.Int64() != 160 {
+		t.Errorf("Spent() = %v, %v, want 160", spent, err)
+	}
+	if n := len(relayer.Submissions()); n != 3 {
+		t.Errorf("got %d submissions, want 3", n)
+	}
+}
+
+func TestSpendPolicy_ReleasedWhenNotSubmitted(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	relayer.Handle(SUBMIT_TRANSACTION, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":"invalid request"}`))
+	})
+	store := NewMemorySpendStore()
+	c := newSpendClient(t, relayer, SpendPolicy{
+		Rules: []SpendRule{{Token: spendUSDC, MaxPerWindow: big.NewInt(250)}},
+		Store: store,
+	})
+	transfer := []models.SafeTransaction{tokenCall(t, spendUSDC, "transfer", common.HexToAddress(spendRecipient), big.NewInt(200))}
+
+	if _, err := c.Execute(transfer, ""); err == nil {
+		t.Fatal("Execute succeeded although the relayer rejected the batch")
+	}
+	safe, err := c.GetExpectedSafe()
+	if err != nil {
+		t.Fatalf("GetExpectedSafe failed: %v", err)
+	}
+	spent, err := store.Spent(safe, spendUSDC, testClock(c).Now().Add(-time.Hour))
+	if err != nil || spent.Sign() != 0 {
+		t.Errorf("Spent() = %v, %v, want the rejected batch released", spent, err)
+	}
+}
+
+func TestMemorySpendStore_ReserveIsAtomic(t *testing.T) {
+	store := NewMemorySpendStore()
+	now := time.Unix(1_700_000_000, 0)
+	max := big.NewInt(100)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var ids []string
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			id, _, err := store.Reserve(spendRecipient, spendUSDC, big.NewInt(10), max, now.Add(-time.Hour), now)
+			if err != nil {
+				t.Errorf("Reserve failed: %v", err)
+			}
+			if id != "" {
+				mu.Lock()
+				ids = append(ids, id)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(ids) != 10 {
+		t.Fatalf("%d reservations of 10 fit under 100, want 10", len(ids))
+	}
+	if err := store.Release(spendRecipient, spendUSDC, ids[0]); err != nil {
+		t.Fatalf("Release failed: %v", err)
+	}
+	spent, _ := store.Spent(spendRecipient, spendUSDC, now.Add(-time.Hour))
+	if spent.Int64() != 90 {
+		t.Errorf("Spent() after a release = %v, want 90", spent)
+	}
+}
+
+func TestSpendPolicy_UnlimitedApproval(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	c := newSpendClient(t, relayer, SpendPolicy{
+		Rules: []SpendRule{{Token: spendUSDC, MaxPerTransaction: new(big.Int).Lsh(big.NewInt(1), 200), MaxPerWindow: big.NewInt(1_000_000)}},
+	})
+	approve := []models.SafeTransaction{tokenCall(t, spendUSDC, "approve", common.HexToAddress(spendRecipient), math.MaxBig256)}
+
+	_, err := c.Execute(approve, "")
+	var exceeded *errors.SpendLimitExceededError
+	if !stderrors.As(err, &exceeded) || exceeded.Attempted.Cmp(math.MaxBig256) != 0 {
+		t.Fatalf("error = %v, want a SpendLimitExceededError for MaxUint256", err)
+	}
+
+	// Revoking the approval moves nothing
+	revoke := []models.SafeTransaction{tokenCall(t, spendUSDC, "approve", common.HexToAddress(spendRecipient), big.NewInt(0))}
+	if _, err := c.Execute(revoke, ""); err != nil {
+		t.Fatalf("revoking Execute failed: %v", err)
+	}
+}
+
+func TestWithSpendPolicy_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy SpendPolicy
+	}{
+		{name: "no rules"},
+		{name: "invalid token", policy: SpendPolicy{Rules: []SpendRule{{Token: "usdc"}}}},
+		{name: "duplicate token", policy: SpendPolicy{Rules: []SpendRule{{Token: spendUSDC}, {Token: "0x2791bca1f2de4661ed88a30c99a7a9449aa84174"}}}},
+		{name: "negative limit", policy: SpendPolicy{Rules: []SpendRule{{Token: spendUSDC, MaxPerWindow: big.NewInt(-1)}}}},
+		{name: "negative window", policy: SpendPolicy{Rules: []SpendRule{{Token: spendUSDC}}, Window: -time.Hour}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := WithSpendPolicy(tt.policy)(newTestClient(t, newFakeRelayer(t))); err == nil {
+				t.Error("WithSpendPolicy should reject the policy")
+			}
+		})
+	}
+}
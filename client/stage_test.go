@@ -0,0 +1,131 @@
+package client
+
+import (
+	stderrors "errors"
+	"net/http"
+	"testing"
+
+	"github.com/davidt58/go-builder-relayer-client/config"
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/models"
+	"github.com/davidt58/go-builder-relayer-client/signer"
+)
+
+// failPath makes the fake relayer answer a path with a server error
+func failPath(relayer *fakeRelayer, path string) {
+	relayer.Handle(path, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+}
+
+func TestStagedErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(relayer *fakeRelayer, c *RelayClient)
+		run   func(c *RelayClient) error
+		stage errors.Stage
+	}{
+		{
+			name:  "execute nonce",
+			setup: func(relayer *fakeRelayer, c *RelayClient) { failPath(relayer, GET_NONCE) },
+			run:   execute(testTransactions()),
+			stage: errors.StageNonce,
+		},
+		{
+			name:  "execute derive",
+			setup: func(relayer *fakeRelayer, c *RelayClient) { c.contractConfig = &config.ContractConfig{ChainID: 137} },
+			run:   execute(testTransactions()),
+			stage: errors.StageDerive,
+		},
+		{
+			name:  "execute not deployed",
+			setup: func(relayer *fakeRelayer, c *RelayClient) { relayer.SetDeployed(false) },
+			run:   execute(testTransactions()),
+			stage: errors.StageDeployedCheck,
+		},
+		{
+			name:  "execute build",
+			setup: func(relayer *fakeRelayer, c *RelayClient) {},
+			run: execute([]models.SafeTransaction{
+				*models.NewSafeTransaction("0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174", "0", "0xzz"),
+			}),
+			stage: errors.StageBuild,
+		},
+		{
+			name:  "execute sign",
+			setup: func(relayer *fakeRelayer, c *RelayClient) { c.signer = &signer.Signer{} },
+			run:   execute(testTransactions()),
+			stage: errors.StageSign,
+		},
+		{
+			name:  "execute submit",
+			setup: func(relayer *fakeRelayer, c *RelayClient) { failPath(relayer, SUBMIT_TRANSACTION) },
+			run:   execute(testTransactions()),
+			stage: errors.StageSubmit,
+		},
+		{
+			name:  "deploy derive",
+			setup: func(relayer *fakeRelayer, c *RelayClient) { c.contractConfig = &config.ContractConfig{ChainID: 137} },
+			run:   deploy,
+			stage: errors.StageDerive,
+		},
+		{
+			name:  "deploy already deployed",
+			setup: func(relayer *fakeRelayer, c *RelayClient) { relayer.SetDeployed(true) },
+			run:   deploy,
+			stage: errors.StageDeployedCheck,
+		},
+		{
+			name: "deploy sign",
+			setup: func(relayer *fakeRelayer, c *RelayClient) {
+				relayer.SetDeployed(false)
+				c.signer = &signer.Signer{}
+			},
+			run:   deploy,
+			stage: errors.StageSign,
+		},
+		{
+			name: "deploy submit",
+			setup: func(relayer *fakeRelayer, c *RelayClient) {
+				relayer.SetDeployed(false)
+				failPath(relayer, SUBMIT_TRANSACTION)
+			},
+			run:   deploy,
+			stage: errors.StageSubmit,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			relayer := newFakeRelayer(t)
+			c := newTestClient(t, relayer)
+			tt.setup(relayer, c)
+
+			err := tt.run(c)
+			var staged *errors.StagedError
+			if !stderrors.As(err, &staged) {
+				t.Fatalf("error = %v, want StagedError", err)
+			}
+			if staged.Stage != tt.stage {
+				t.Errorf("Stage = %s, want %s (error: %v)", staged.Stage, tt.stage, err)
+			}
+			if staged.OperationID == "" {
+				t.Error("StagedError should carry the operation ID")
+			}
+		})
+	}
+}
+
+// execute returns a test step running Execute with the given transactions
+func execute(transactions []models.SafeTransaction) func(c *RelayClient) error {
+	return func(c *RelayClient) error {
+		_, err := c.Execute(transactions, "")
+		return err
+	}
+}
+
+// deploy is a test step running Deploy
+func deploy(c *RelayClient) error {
+	_, err := c.Deploy()
+	return err
+}
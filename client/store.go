@@ -0,0 +1,327 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+// SubmissionRecord is a persisted submission. It is saved just before the
+// request is POSTed and saved again with the transaction ID once the relayer
+// accepts it, so a crash in between leaves a record RecoverPending can
+// reconcile against the relayer.
+type SubmissionRecord struct {
+	// OperationID identifies the submitting operation and keys the record
+	OperationID string `json:"operationId"`
+	// Type is the transaction type (SAFE or SAFE-CREATE)
+	Type string `json:"type"`
+	// SafeAddress is the Safe the transaction targets
+	SafeAddress string `json:"safeAddress"`
+	// Nonce is the Safe nonce the transaction was signed with (empty for SAFE-CREATE)
+	Nonce string `json:"nonce,omitempty"`
+	// ContentHash is the keccak256 of the submitted request body
+	ContentHash string `json:"contentHash"`
+	// TransactionID is the relayer's ID, known once the POST has succeeded
+	TransactionID string `json:"transactionId,omitempty"`
+	// CreatedAt is when the record was saved before the POST
+	CreatedAt time.Time `json:"createdAt"`
+	// SubmittedAt is when the relayer accepted the submission
+	SubmittedAt *time.Time `json:"submittedAt,omitempty"`
+	// ResolvedAt is when the submission reached a final state
+	ResolvedAt *time.Time `json:"resolvedAt,omitempty"`
+	// Transaction is the signed Safe transaction of a SAFE submission (a
+	// MultiSend call for batches), kept so RetryTransaction can rebuild it
+	Transaction *models.SafeTransaction `json:"transaction,omitempty"`
+	// Metadata is the submitted metadata
+	Metadata *string `json:"metadata,omitempty"`
+}
+
+// SubmissionStore persists in-flight submissions for crash recovery. The
+// client calls it synchronously around every submit; implementations must
+// have made a write durable before returning.
+type SubmissionStore interface {
+	// SaveSubmission inserts or replaces the record with the same OperationID
+	SaveSubmission(record SubmissionRecord) error
+	// MarkResolved marks the record with the given OperationID as resolved
+	MarkResolved(operationID string) error
+	// ListPending returns the unresolved records, oldest first
+	ListPending() ([]SubmissionRecord, error)
+}
+
+// SubmissionFinder is implemented by SubmissionStores that keep resolved
+// records and can look them up by transaction ID. RetryTransaction uses it
+// to recover the original payload when the relayer does not return it.
+type SubmissionFinder interface {
+	// FindSubmission returns the record of transactionID, if there is one
+	FindSubmission(transactionID string) (*SubmissionRecord, bool, error)
+}
+
+// SubmissionQuerier is implemented by SubmissionStores that keep resolved
+// records and can search them. The submission index (see Submissions) uses
+// it; other stores can only be searched for pending records.
+type SubmissionQuerier interface {
+	// QuerySubmissions returns the records matching filter, oldest first
+	QuerySubmissions(filter SubmissionFilter) ([]SubmissionRecord, error)
+}
+
+// SubmissionCompactor is implemented by SubmissionStores that can drop old
+// resolved records
+type SubmissionCompactor interface {
+	// CompactSubmissions removes the records resolved before resolvedBefore
+	// and returns how many were removed. Pending records are always kept.
+	CompactSubmissions(resolvedBefore time.Time) (int, error)
+}
+
+// SubmissionFlusher is implemented by SubmissionStores that buffer writes;
+// Close flushes them
+type SubmissionFlusher interface {
+	// FlushSubmissions writes out buffered records
+	FlushSubmissions() error
+}
+
+// FileSubmissionStore is a SubmissionStore backed by an append-only JSONL
+// file. Every save or resolution appends one line and syncs the file; the
+// last line for an operation wins. A torn final line left by a crash
+// mid-write is ignored.
+type FileSubmissionStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileSubmissionStore opens (creating if needed) a JSONL submission store at path
+func NewFileSubmissionStore(path string) (*FileSubmissionStore, error) {
+	if path == "" {
+		return nil, errors.ErrMissingRequiredField("path")
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDONLY, 0o600)
+	if err != nil {
+		return nil, errors.ErrSubmissionStoreFailed("open", err)
+	}
+	f.Close()
+	return &FileSubmissionStore{path: path}, nil
+}
+
+// SaveSubmission appends record
+func (s *FileSubmissionStore) SaveSubmission(record SubmissionRecord) error {
+	if record.OperationID == "" {
+		return errors.ErrMissingRequiredField("OperationID")
+	}
+	return s.append(record)
+}
+
+// MarkResolved appends a resolution for operationID
+func (s *FileSubmissionStore) MarkResolved(operationID string) error {
+	if operationID == "" {
+		return errors.ErrMissingRequiredField("operationID")
+	}
+	now := time.Now().UTC()
+	return s.append(SubmissionRecord{OperationID: operationID, ResolvedAt: &now})
+}
+
+// ListPending replays the file and returns the unresolved records
+func (s *FileSubmissionStore) ListPending() ([]SubmissionRecord, error) {
+	records, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	pending := make([]SubmissionRecord, 0, len(records))
+	for _, record := range records {
+		if record.ResolvedAt == nil {
+			pending = append(pending, record)
+		}
+	}
+	sortByCreation(pending)
+	return pending, nil
+}
+
+// FindSubmission replays the file and returns the record of transactionID,
+// resolved or not
+func (s *FileSubmissionStore) FindSubmission(transactionID string) (*SubmissionRecord, bool, error) {
+	records, err := s.load()
+	if err != nil {
+		return nil, false, err
+	}
+	for _, record := range records {
+		if record.TransactionID == transactionID {
+			return &record, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+// QuerySubmissions replays the file and returns the matching records,
+// resolved or not
+func (s *FileSubmissionStore) QuerySubmissions(filter SubmissionFilter) ([]SubmissionRecord, error) {
+	records, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	var matched []SubmissionRecord
+	for _, record := range records {
+		if filter.Matches(record) {
+			matched = append(matched, record)
+		}
+	}
+	sortByCreation(matched)
+	return matched, nil
+}
+
+// CompactSubmissions rewrites the file without the records resolved before
+// resolvedBefore. The new file is synced before it replaces the old one, so
+// a crash leaves one or the other.
+func (s *FileSubmissionStore) CompactSubmissions(resolvedBefore time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	records, err := s.loadLocked()
+	if err != nil {
+		return 0, err
+	}
+
+	kept := make([]SubmissionRecord, 0, len(records))
+	for _, record := range records {
+		if record.ResolvedAt == nil || !record.ResolvedAt.Before(resolvedBefore) {
+			kept = append(kept, record)
+		}
+	}
+	sortByCreation(kept)
+
+	var buf bytes.Buffer
+	for _, record := range kept {
+		// Resolved records are written back as a save and a resolution line
+		resolvedAt := record.ResolvedAt
+		record.ResolvedAt = nil
+		lines := []SubmissionRecord{record}
+		if resolvedAt != nil {
+			lines = append(lines, SubmissionRecord{OperationID: record.OperationID, ResolvedAt: resolvedAt})
+		}
+		for _, line := range lines {
+			encoded, err := json.Marshal(line)
+			if err != nil {
+				return 0, errors.ErrJSONMarshalFailed(err)
+			}
+			buf.Write(append(encoded, '\n'))
+		}
+	}
+
+	tmp := s.path + ".compact"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return 0, errors.ErrSubmissionStoreFailed("open", err)
+	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		f.Close()
+		return 0, errors.ErrSubmissionStoreFailed("write", err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return 0, errors.ErrSubmissionStoreFailed("sync", err)
+	}
+	if err := f.Close(); err != nil {
+		return 0, errors.ErrSubmissionStoreFailed("close", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return 0, errors.ErrSubmissionStoreFailed("rename", err)
+	}
+	return len(records) - len(kept), nil
+}
+
+// load replays the file into the latest record per operation, with the
+// resolution time of resolved operations
+func (s *FileSubmissionStore) load() (map[string]SubmissionRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.loadLocked()
+}
+
+// loadLocked is load with s.mu held
+func (s *FileSubmissionStore) loadLocked() (map[string]SubmissionRecord, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, errors.ErrSubmissionStoreFailed("read", err)
+	}
+
+	records := make(map[string]SubmissionRecord)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var record SubmissionRecord
+		if err := json.Unmarshal(line, &record); err != nil || record.OperationID == "" {
+			// Torn write from a crash; later lines are still usable
+			continue
+		}
+		if record.ResolvedAt != nil {
+			// Resolution lines only carry the operation ID
+			if saved, ok := records[record.OperationID]; ok {
+				saved.ResolvedAt = record.ResolvedAt
+				records[record.OperationID] = saved
+			}
+			continue
+		}
+		records[record.OperationID] = record
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.ErrSubmissionStoreFailed("read", err)
+	}
+	return records, nil
+}
+
+// append writes record as one line and syncs the file
+func (s *FileSubmissionStore) append(record SubmissionRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return errors.ErrJSONMarshalFailed(err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return errors.ErrSubmissionStoreFailed("open", err)
+	}
+	defer f.Close()
+
+	// Terminate a torn line left by a previous crash before appending
+	info, err := f.Stat()
+	if err != nil {
+		return errors.ErrSubmissionStoreFailed("stat", err)
+	}
+	if size := info.Size(); size > 0 {
+		last := make([]byte, 1)
+		if _, err := f.ReadAt(last, size-1); err != nil {
+			return errors.ErrSubmissionStoreFailed("read", err)
+		}
+		if last[0] != '\n' {
+			line = append([]byte{'\n'}, line...)
+		}
+	}
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return errors.ErrSubmissionStoreFailed("write", err)
+	}
+	if err := f.Sync(); err != nil {
+		return errors.ErrSubmissionStoreFailed("sync", err)
+	}
+	return nil
+}
+
+// sortByCreation sorts records oldest first
+func sortByCreation(records []SubmissionRecord) {
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].CreatedAt.Before(records[j].CreatedAt)
+	})
+}
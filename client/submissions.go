@@ -0,0 +1,149 @@
+package client
+
+import (
+	"strings"
+	"time"
+
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+// SubmissionFilter selects submission records. Empty fields match every
+// record; set fields must all match.
+type SubmissionFilter struct {
+	// SafeAddress matches the Safe the transaction targets, case-insensitively
+	SafeAddress string
+	// Nonce matches the Safe nonce the transaction was signed with
+	Nonce string
+	// ContentHash matches the keccak256 of the submitted request body
+	ContentHash string
+	// OperationID matches the submitting operation
+	OperationID string
+	// TransactionID matches the relayer's transaction ID
+	TransactionID string
+	// Since matches records created at or after it
+	Since time.Time
+	// Until matches records created before it
+	Until time.Time
+	// Refresh fetches the current state of each matching record that has a
+	// transaction ID with GetTransaction. It does not affect matching.
+	Refresh bool
+}
+
+// Matches reports whether record satisfies the filter
+func (f SubmissionFilter) Matches(record SubmissionRecord) bool {
+	switch {
+	case f.SafeAddress != "" && !strings.EqualFold(f.SafeAddress, record.SafeAddress):
+		return false
+	case f.Nonce != "" && f.Nonce != record.Nonce:
+		return false
+	case f.ContentHash != "" && !strings.EqualFold(f.ContentHash, record.ContentHash):
+		return false
+	case f.OperationID != "" && f.OperationID != record.OperationID:
+		return false
+	case f.TransactionID != "" && f.TransactionID != record.TransactionID:
+		return false
+	case !f.Since.IsZero() && record.CreatedAt.Before(f.Since):
+		return false
+	case !f.Until.IsZero() && !record.CreatedAt.Before(f.Until):
+		return false
+	}
+	return true
+}
+
+// IndexedSubmission is a submission record found in the index
+type IndexedSubmission struct {
+	// Record is the stored submission
+	Record SubmissionRecord
+	// Transaction is the relayer's current view of the submission, set when
+	// the filter asked for a refresh and the relayer knows the transaction
+	Transaction *models.RelayerTransaction
+}
+
+// SubmissionIndex searches the submissions recorded in the client's
+// submission store
+type SubmissionIndex struct {
+	client *RelayClient
+}
+
+// Submissions returns the index of locally recorded submissions (see
+// WithSubmissionStore)
+func (c *RelayClient) Submissions() *SubmissionIndex {
+	return &SubmissionIndex{client: c}
+}
+
+// Find returns the recorded submissions matching filter, oldest first. A
+// store that is not a SubmissionQuerier is only searched for pending records.
+func (i *SubmissionIndex) Find(filter SubmissionFilter) ([]IndexedSubmission, error) {
+	c := i.client
+	if c.submissionStore == nil {
+		return nil, errors.ErrSubmissionStoreNotConfigured
+	}
+
+	records, err := i.query(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	op := newOperation("FindSubmissions", 0)
+	defer op.cancel()
+
+	found := make([]IndexedSubmission, 0, len(records))
+	for _, record := range records {
+		result := IndexedSubmission{Record: record}
+		if filter.Refresh && record.TransactionID != "" {
+			txn, err := c.getTransaction(op.ctx, record.TransactionID)
+			if err != nil && !errors.IsNotFound(err) {
+				return found, op.tag(err)
+			}
+			result.Transaction = txn
+		}
+		found = append(found, result)
+	}
+	return found, nil
+}
+
+// Compact removes the records resolved longer than retention ago from a
+// store that is a SubmissionCompactor and returns how many were removed
+func (i *SubmissionIndex) Compact(retention time.Duration) (int, error) {
+	c := i.client
+	if c.submissionStore == nil {
+		return 0, errors.ErrSubmissionStoreNotConfigured
+	}
+	if retention <= 0 {
+		return 0, errors.ErrInvalidConfiguration("submission retention must be positive")
+	}
+	compactor, ok := c.submissionStore.(SubmissionCompactor)
+	if !ok {
+		return 0, errors.ErrInvalidConfiguration("submission store does not support compaction")
+	}
+
+	removed, err := compactor.CompactSubmissions(c.clock.Now().Add(-retention))
+	if err != nil {
+		return 0, err
+	}
+	if removed > 0 {
+		c.logger.Printf("Compacted %d resolved submissions older than %s", removed, retention)
+	}
+	return removed, nil
+}
+
+// query returns the store's records matching filter
+func (i *SubmissionIndex) query(filter SubmissionFilter) ([]SubmissionRecord, error) {
+	store := i.client.submissionStore
+	if querier, ok := store.(SubmissionQuerier); ok {
+		return querier.QuerySubmissions(filter)
+	}
+
+	pending, err := store.ListPending()
+	if err != nil {
+		return nil, err
+	}
+	var matched []SubmissionRecord
+	for _, record := range pending {
+		if filter.Matches(record) {
+			matched = append(matched, record)
+		}
+	}
+	return matched, nil
+}
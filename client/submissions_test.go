@@ -0,0 +1,223 @@
+package client
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+// pendingOnlyStore hides the query and compaction support of a store
+type pendingOnlyStore struct {
+	SubmissionStore
+}
+
+// indexStart is when the first indexed test record was created
+var indexStart = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// newIndexedStore returns a file store holding four records: op-1 and op-2
+// for Safe A, op-3 and op-4 for Safe B, created an hour apart, with op-1
+// resolved at resolvedAt
+func newIndexedStore(t *testing.T, resolvedAt time.Time) *FileSubmissionStore {
+	t.Helper()
+
+	store, err := NewFileSubmissionStore(filepath.Join(t.TempDir(), "submissions.jsonl"))
+	if err != nil {
+		t.Fatalf("NewFileSubmissionStore failed: %v", err)
+	}
+	records := []SubmissionRecord{
+		{OperationID: "op-1", SafeAddress: "0xAAAA000000000000000000000000000000000001", Nonce: "4", ContentHash: "0x01aa", TransactionID: "tx-1"},
+		{OperationID: "op-2", SafeAddress: "0xAAAA000000000000000000000000000000000001", Nonce: "5", ContentHash: "0x02bb", TransactionID: "tx-2"},
+		{OperationID: "op-3", SafeAddress: "0xBBBB000000000000000000000000000000000002", Nonce: "4", ContentHash: "0x03cc"},
+		{OperationID: "op-4", SafeAddress: "0xBBBB000000000000000000000000000000000002", Nonce: "5", ContentHash: "0x01aa", TransactionID: "tx-4"},
+	}
+	for i, record := range records {
+		record.Type = string(models.SAFE)
+		record.CreatedAt = indexStart.Add(time.Duration(i) * time.Hour)
+		if err := store.SaveSubmission(record); err != nil {
+			t.Fatalf("SaveSubmission failed: %v", err)
+		}
+	}
+	// A resolution line, as MarkResolved writes at a chosen time
+	if err := store.SaveSubmission(SubmissionRecord{OperationID: "op-1", ResolvedAt: &resolvedAt}); err != nil {
+		t.Fatalf("SaveSubmission failed: %v", err)
+	}
+	return store
+}
+
+// operationIDs returns the operation IDs of found, comma-separated
+func operationIDs(found []IndexedSubmission) string {
+	ids := make([]string, len(found))
+	for i, submission := range found {
+		ids[i] = submission.Record.OperationID
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestSubmissionIndex_Find(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter SubmissionFilter
+		want   string
+	}{
+		{name: "everything", want: "op-1,op-2,op-3,op-4"},
+		{name: "safe address", filter: SubmissionFilter{SafeAddress: "0xaaaa000000000000000000000000000000000001"}, want: "op-1,op-2"},
+		{name: "nonce", filter: SubmissionFilter{Nonce: "4"}, want: "op-1,op-3"},
+		{name: "safe and nonce", filter: SubmissionFilter{SafeAddress: "0xBBBB000000000000000000000000000000000002", Nonce: "5"}, want: "op-4"},
+		{name: "content hash", filter: SubmissionFilter{ContentHash: "0x01AA"}, want: "op-1,op-4"},
+		{name: "operation ID", filter: SubmissionFilter{OperationID: "op-3"}, want: "op-3"},
+		{name: "transaction ID", filter: SubmissionFilter{TransactionID: "tx-2"}, want: "op-2"},
+		{name: "since", filter: SubmissionFilter{Since: indexStart.Add(2 * time.Hour)}, want: "op-3,op-4"},
+		{name: "until", filter: SubmissionFilter{Until: indexStart.Add(time.Hour)}, want: "op-1"},
+		{name: "time range", filter: SubmissionFilter{Since: indexStart.Add(30 * time.Minute), Until: indexStart.Add(3 * time.Hour)}, want: "op-2,op-3"},
+		{name: "no match", filter: SubmissionFilter{ContentHash: "0xffff"}, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(t, newFakeRelayer(t))
+			c.submissionStore = newIndexedStore(t, indexStart.Add(time.Minute))
+
+			found, err := c.Submissions().Find(tt.filter)
+			if err != nil {
+				t.Fatalf("Find failed: %v", err)
+			}
+			if got := operationIDs(found); got != tt.want {
+				t.Errorf("Find() = %q, want %q", got, tt.want)
+			}
+			for _, submission := range found {
+				if submission.Transaction != nil {
+					t.Errorf("%s was refreshed without Refresh", submission.Record.OperationID)
+				}
+			}
+		})
+	}
+}
+
+func TestSubmissionIndex_Refresh(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	ledger := newRelayerLedger(relayer)
+	c := newStoreClient(t, relayer, filepath.Join(t.TempDir(), "submissions.jsonl"), nil)
+
+	response, err := c.Execute(testTransactions(), "")
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	ledger.setState(response.TransactionID, models.STATE_CONFIRMED)
+
+	found, err := c.Submissions().Find(SubmissionFilter{OperationID: response.OperationID})
+	if err != nil || len(found) != 1 {
+		t.Fatalf("Find() = %d records (%v), want 1", len(found), err)
+	}
+	if found[0].Record.ContentHash == "" {
+		t.Error("record has no content hash")
+	}
+	if found[0].Transaction != nil {
+		t.Error("Transaction should only be set with Refresh")
+	}
+
+	byHash, err := c.Submissions().Find(SubmissionFilter{ContentHash: found[0].Record.ContentHash, Refresh: true})
+	if err != nil || len(byHash) != 1 {
+		t.Fatalf("Find() by content hash = %d records (%v), want 1", len(byHash), err)
+	}
+	if txn := byHash[0].Transaction; txn == nil || txn.State != models.STATE_CONFIRMED {
+		t.Errorf("refreshed transaction = %+v, want %s", txn, models.STATE_CONFIRMED)
+	}
+
+	// A transaction the relayer no longer knows is returned unrefreshed
+	unknown := found[0].Record
+	unknown.OperationID, unknown.TransactionID = "op-unknown", "tx-unknown"
+	if err := c.submissionStore.SaveSubmission(unknown); err != nil {
+		t.Fatalf("SaveSubmission failed: %v", err)
+	}
+	gone, err := c.Submissions().Find(SubmissionFilter{OperationID: "op-unknown", Refresh: true})
+	if err != nil || len(gone) != 1 {
+		t.Fatalf("Find() = %d records (%v), want 1", len(gone), err)
+	}
+	if gone[0].Transaction != nil {
+		t.Errorf("Transaction = %+v, want nil for an unknown transaction", gone[0].Transaction)
+	}
+}
+
+func TestSubmissionIndex_PendingOnlyStore(t *testing.T) {
+	c := newTestClient(t, newFakeRelayer(t))
+	c.submissionStore = pendingOnlyStore{newIndexedStore(t, indexStart.Add(time.Minute))}
+
+	found, err := c.Submissions().Find(SubmissionFilter{Nonce: "4"})
+	if err != nil {
+		t.Fatalf("Find failed: %v", err)
+	}
+	if got := operationIDs(found); got != "op-3" {
+		t.Errorf("Find() = %q, want only the pending op-3", got)
+	}
+	if _, err := c.Submissions().Compact(time.Hour); err == nil {
+		t.Error("Compact should fail for a store without compaction")
+	}
+}
+
+func TestSubmissionIndex_Compact(t *testing.T) {
+	tests := []struct {
+		name        string
+		resolvedAt  time.Time
+		retention   time.Duration
+		wantRemoved int
+		shouldErr   bool
+	}{
+		{name: "resolved before retention", resolvedAt: indexStart, retention: 24 * time.Hour, wantRemoved: 1},
+		{name: "resolved within retention", resolvedAt: indexStart.Add(47 * time.Hour), retention: 24 * time.Hour},
+		{name: "non-positive retention", resolvedAt: indexStart, shouldErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(t, newFakeRelayer(t))
+			store := newIndexedStore(t, tt.resolvedAt)
+			c.submissionStore = store
+			testClock(c).Set(indexStart.Add(48 * time.Hour))
+
+			removed, err := c.Submissions().Compact(tt.retention)
+			if (err != nil) != tt.shouldErr {
+				t.Fatalf("Compact() error = %v, shouldErr %v", err, tt.shouldErr)
+			}
+			if removed != tt.wantRemoved {
+				t.Errorf("Compact() = %d, want %d", removed, tt.wantRemoved)
+			}
+
+			found, err := c.Submissions().Find(SubmissionFilter{})
+			if err != nil {
+				t.Fatalf("Find failed: %v", err)
+			}
+			if got := len(found); got != 4-tt.wantRemoved {
+				t.Errorf("%d records left, want %d", got, 4-tt.wantRemoved)
+			}
+			pending, err := store.ListPending()
+			if err != nil {
+				t.Fatalf("ListPending failed: %v", err)
+			}
+			if len(pending) != 3 {
+				t.Errorf("%d pending records, want the 3 unresolved ones", len(pending))
+			}
+
+			// The compacted file still accepts appends
+			if err := store.MarkResolved("op-2"); err != nil {
+				t.Fatalf("MarkResolved failed: %v", err)
+			}
+			if pending, _ := store.ListPending(); len(pending) != 2 {
+				t.Errorf("%d pending records after resolving op-2, want 2", len(pending))
+			}
+		})
+	}
+}
+
+func TestSubmissionIndex_NoStore(t *testing.T) {
+	c := newTestClient(t, newFakeRelayer(t))
+	if _, err := c.Submissions().Find(SubmissionFilter{}); err != errors.ErrSubmissionStoreNotConfigured {
+		t.Errorf("Find() error = %v, want ErrSubmissionStoreNotConfigured", err)
+	}
+	if _, err := c.Submissions().Compact(time.Hour); err != errors.ErrSubmissionStoreNotConfigured {
+		t.Errorf("Compact() error = %v, want ErrSubmissionStoreNotConfigured", err)
+	}
+}
@@ -0,0 +1,296 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+// trackerCheckpointVersion is the format version written by SaveCheckpoint
+const trackerCheckpointVersion = 1
+
+// defaultTrackStates are the target states of a transaction tracked without any
+var defaultTrackStates = []models.RelayerTransactionState{models.STATE_MINED, models.STATE_CONFIRMED}
+
+// TrackOptions configures how a TransactionTracker watches a transaction
+type TrackOptions struct {
+	// States are the target states that complete the tracking; empty means
+	// STATE_MINED or STATE_CONFIRMED
+	States []models.RelayerTransactionState `json:"states,omitempty"`
+	// FailState completes the tracking with an error, in addition to the
+	// failed and cancelled states
+	FailState models.RelayerTransactionState `json:"failState,omitempty"`
+	// Label is caller data kept with the transaction, e.g. an order ID
+	Label string `json:"label,omitempty"`
+}
+
+// TrackedTransaction is a transaction a TransactionTracker is watching
+type TrackedTransaction struct {
+	TransactionID string `json:"transactionId"`
+	// LastState is the last state observed, empty before the first poll
+	LastState models.RelayerTransactionState `json:"lastState,omitempty"`
+	// TrackedAt orders the tracked transactions; ResumeFromStore uses the
+	// submission time
+	TrackedAt time.Time    `json:"trackedAt"`
+	Options   TrackOptions `json:"options"`
+}
+
+// TrackerHandlers receive what a TransactionTracker observes. Both are
+// optional and are called synchronously from Poll.
+type TrackerHandlers struct {
+	// OnStateChange is called when a tracked transaction is observed in a
+	// new state. The first observation is not a change, so a transaction
+	// resumed from a checkpoint reports one change from its checkpointed
+	// state to its current one, not every transition in between.
+	OnStateChange func(TransactionStateChange)
+	// OnComplete is called once when the tracking of a transaction ends,
+	// with the last transaction observed and, unless it reached a target
+	// state, the error that ended it
+	OnComplete func(transactionID string, txn *models.RelayerTransaction, err error)
+}
+
+// TransactionTracker watches many transactions with one poll loop, oldest
+// tracked first. Its state can be checkpointed and loaded into a new
+// tracker, so a restarted process resumes watching where it left off.
+type TransactionTracker struct {
+	client   *RelayClient
+	handlers TrackerHandlers
+
+	// pollMu serializes Poll, which owns the entries' polls
+	pollMu sync.Mutex
+
+	mu      sync.Mutex
+	tracked map[string]*trackedEntry
+}
+
+// trackedEntry is a tracked transaction and its poll progress
+type trackedEntry struct {
+	// TrackedTransaction is the snapshot checkpoints are made of; guarded by
+	// the tracker's mu
+	TrackedTransaction
+	// poll is only used by Poll
+	poll *transactionPoll
+	// store and operationID resolve the submission record of a transaction
+	// resumed from a store once it reaches a terminal state
+	store       SubmissionStore
+	operationID string
+}
+
+// NewTransactionTracker creates a tracker polling through client
+func NewTransactionTracker(client *RelayClient, handlers TrackerHandlers) *TransactionTracker {
+	return &TransactionTracker{
+		client:   client,
+		handlers: handlers,
+		tracked:  make(map[string]*trackedEntry),
+	}
+}
+
+// Track starts watching transactionID. Tracking a transaction already
+// tracked does nothing.
+func (t *TransactionTracker) Track(transactionID string, opts TrackOptions) error {
+	if transactionID == "" {
+		return errors.ErrMissingRequiredField("transactionID")
+	}
+	t.add(TrackedTransaction{TransactionID: transactionID, TrackedAt: t.client.clock.Now(), Options: opts}, nil, "")
+	return nil
+}
+
+// add registers tracked, unless its transaction is already tracked
+func (t *TransactionTracker) add(tracked TrackedTransaction, store SubmissionStore, operationID string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.tracked[tracked.TransactionID]; ok {
+		return false
+	}
+	states := tracked.Options.States
+	if len(states) == 0 {
+		states = defaultTrackStates
+	}
+	poll := newTransactionPoll(tracked.TransactionID, states, tracked.Options.FailState)
+	poll.lastState = tracked.LastState
+	t.tracked[tracked.TransactionID] = &trackedEntry{
+		TrackedTransaction: tracked,
+		poll:               poll,
+		store:              store,
+		operationID:        operationID,
+	}
+	return true
+}
+
+// Tracked returns the tracked transactions, oldest tracked first
+func (t *TransactionTracker) Tracked() []TrackedTransaction {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tracked := make([]TrackedTransaction, 0, len(t.tracked))
+	for _, entry := range t.tracked {
+		tracked = append(tracked, entry.TrackedTransaction)
+	}
+	sortTracked(tracked)
+	return tracked
+}
+
+// sortTracked orders tracked oldest tracked first
+func sortTracked(tracked []TrackedTransaction) {
+	sort.Slice(tracked, func(i, j int) bool { return trackedBefore(tracked[i], tracked[j]) })
+}
+
+// trackedBefore orders tracked transactions by TrackedAt, then transaction ID
+func trackedBefore(a, b TrackedTransaction) bool {
+	if !a.TrackedAt.Equal(b.TrackedAt) {
+		return a.TrackedAt.Before(b.TrackedAt)
+	}
+	return a.TransactionID < b.TransactionID
+}
+
+// Poll fetches every tracked transaction once, oldest tracked first,
+// reporting state changes and completions to the handlers, and returns how
+// many transactions are still tracked. Transient errors leave a transaction
+// tracked; when ctx is done the remaining transactions are not polled.
+func (t *TransactionTracker) Poll(ctx context.Context) int {
+	t.pollMu.Lock()
+	defer t.pollMu.Unlock()
+
+	t.mu.Lock()
+	entries := make([]*trackedEntry, 0, len(t.tracked))
+	for _, entry := range t.tracked {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return trackedBefore(entries[i].TrackedTransaction, entries[j].TrackedTransaction)
+	})
+	t.mu.Unlock()
+
+	for _, entry := range entries {
+		if ctx.Err() != nil {
+			break
+		}
+		t.pollEntry(ctx, entry)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.tracked)
+}
+
+// Run polls every interval until no transaction is tracked or ctx is done
+func (t *TransactionTracker) Run(ctx context.Context, interval time.Duration) error {
+	for t.Poll(ctx) > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.client.clock.After(interval):
+		}
+	}
+	return ctx.Err()
+}
+
+// pollEntry polls one tracked transaction and reports what changed
+func (t *TransactionTracker) pollEntry(ctx context.Context, entry *trackedEntry) {
+	p := entry.poll
+	previous := p.lastState
+	done, txn, err := t.client.pollOnce(ctx, p)
+	if ctx.Err() != nil {
+		// The poll was cut short, not the transaction's tracking
+		return
+	}
+
+	t.mu.Lock()
+	entry.LastState = p.lastState
+	if done {
+		delete(t.tracked, entry.TransactionID)
+	}
+	t.mu.Unlock()
+
+	if previous != "" && p.lastState != previous && t.handlers.OnStateChange != nil {
+		t.handlers.OnStateChange(t.client.stateChangeEvent(StateChanged, p, previous, p.lastTxn, nil))
+	}
+	if entry.store != nil && p.lastState.IsTerminal() {
+		if resolveErr := entry.store.MarkResolved(entry.operationID); resolveErr != nil {
+			t.client.logger.Printf("Failed to resolve submission for operation %s: %v", entry.operationID, resolveErr)
+		}
+		entry.store = nil
+	}
+	if done && t.handlers.OnComplete != nil {
+		t.handlers.OnComplete(entry.TransactionID, txn, err)
+	}
+}
+
+// trackerCheckpoint is the serialized state of a TransactionTracker
+type trackerCheckpoint struct {
+	Version      int                  `json:"version"`
+	Transactions []TrackedTransaction `json:"transactions"`
+}
+
+// SaveCheckpoint writes the tracked transactions, with their last observed
+// states and options, to w as JSON
+func (t *TransactionTracker) SaveCheckpoint(w io.Writer) error {
+	checkpoint := trackerCheckpoint{Version: trackerCheckpointVersion, Transactions: t.Tracked()}
+	if err := json.NewEncoder(w).Encode(checkpoint); err != nil {
+		return errors.ErrJSONMarshalFailed(err)
+	}
+	return nil
+}
+
+// LoadCheckpoint tracks the transactions of a checkpoint written by
+// SaveCheckpoint, keeping their last observed states so the next Poll
+// reconciles each against the relayer at once: a transaction that finished
+// in the meantime completes with a single state change. Transactions already
+// tracked are kept as they are.
+func (t *TransactionTracker) LoadCheckpoint(r io.Reader) error {
+	var checkpoint trackerCheckpoint
+	if err := json.NewDecoder(r).Decode(&checkpoint); err != nil {
+		return errors.ErrJSONUnmarshalFailed(err)
+	}
+	if checkpoint.Version != trackerCheckpointVersion {
+		return errors.NewRelayerClientError(fmt.Sprintf("unsupported tracker checkpoint version %d", checkpoint.Version), nil)
+	}
+	for _, tracked := range checkpoint.Transactions {
+		if tracked.TransactionID == "" {
+			return errors.ErrMissingRequiredField("transactionId")
+		}
+	}
+
+	for _, tracked := range checkpoint.Transactions {
+		t.add(tracked, nil, "")
+	}
+	return nil
+}
+
+// ResumeFromStore tracks every unresolved submission in store that has a
+// transaction ID, with the default TrackOptions, and returns how many were
+// added. Records that lost their ID in a crash are left to RecoverPending.
+// A submission whose transaction reaches a terminal state is marked
+// resolved in store.
+func (t *TransactionTracker) ResumeFromStore(store SubmissionStore) (int, error) {
+	if store == nil {
+		return 0, errors.ErrMissingRequiredField("store")
+	}
+	pending, err := store.ListPending()
+	if err != nil {
+		return 0, err
+	}
+
+	added := 0
+	for _, record := range pending {
+		if record.TransactionID == "" {
+			continue
+		}
+		trackedAt := record.CreatedAt
+		if record.SubmittedAt != nil {
+			trackedAt = *record.SubmittedAt
+		}
+		if t.add(TrackedTransaction{TransactionID: record.TransactionID, TrackedAt: trackedAt}, store, record.OperationID) {
+			added++
+		}
+	}
+	return added, nil
+}
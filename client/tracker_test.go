@@ -0,0 +1,231 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/davidt58/go-builder-relayer-client/clock"
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+// transactionStates serves each transaction in the state set for its ID
+type transactionStates struct {
+	mu     sync.Mutex
+	states map[string]models.RelayerTransactionState
+}
+
+// serveTransactionStates makes the relayer report the states of the
+// returned transactionStates
+func serveTransactionStates(f *fakeRelayer) *transactionStates {
+	s := &transactionStates{states: make(map[string]models.RelayerTransactionState)}
+	f.Handle(GET_TRANSACTION, func(w http.ResponseWriter, r *http.Request) {
+		id := r.URL.Query().Get("id")
+		s.mu.Lock()
+		state := s.states[id]
+		s.mu.Unlock()
+		json.NewEncoder(w).Encode([]models.RelayerTransaction{{TransactionID: id, State: state}})
+	})
+	return s
+}
+
+func (s *transactionStates) set(id string, state models.RelayerTransactionState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.states[id] = state
+}
+
+// trackerRecorder records the handler calls of a TransactionTracker
+type trackerRecorder struct {
+	changes     []TransactionStateChange
+	completions map[string]int
+	states      map[string]models.RelayerTransactionState
+}
+
+func newTrackerRecorder() *trackerRecorder {
+	return &trackerRecorder{completions: make(map[string]int), states: make(map[string]models.RelayerTransactionState)}
+}
+
+func (r *trackerRecorder) handlers() TrackerHandlers {
+	return TrackerHandlers{
+		OnStateChange: func(change TransactionStateChange) { r.changes = append(r.changes, change) },
+		OnComplete: func(transactionID string, txn *models.RelayerTransaction, err error) {
+			r.completions[transactionID]++
+			if txn != nil {
+				r.states[transactionID] = txn.State
+			}
+		},
+	}
+}
+
+func TestTransactionTracker_ResumeFromCheckpoint(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	states := serveTransactionStates(relayer)
+	c := newTestClient(t, relayer)
+	ctx := context.Background()
+
+	before := newTrackerRecorder()
+	tracker := NewTransactionTracker(c, before.handlers())
+	for i, id := range []string{"tx-finished", "tx-executing", "tx-new"} {
+		states.set(id, models.STATE_NEW)
+		if err := tracker.Track(id, TrackOptions{Label: id + "-label"}); err != nil {
+			t.Fatalf("Track failed: %v", err)
+		}
+		testClock(c).Advance(time.Duration(i+1) * time.Second)
+	}
+	if remaining := tracker.Poll(ctx); remaining != 3 {
+		t.Fatalf("Poll left %d tracked, want 3", remaining)
+	}
+
+	var checkpoint bytes.Buffer
+	if err := tracker.SaveCheckpoint(&checkpoint); err != nil {
+		t.Fatalf("SaveCheckpoint failed: %v", err)
+	}
+
+	// While the process is down one transaction runs to completion and
+	// another moves on
+	states.set("tx-finished", models.STATE_CONFIRMED)
+	states.set("tx-executing", models.STATE_EXECUTED)
+
+	after := newTrackerRecorder()
+	resumed := NewTransactionTracker(c, after.handlers())
+	if err := resumed.LoadCheckpoint(&checkpoint); err != nil {
+		t.Fatalf("LoadCheckpoint failed: %v", err)
+	}
+	tracked := resumed.Tracked()
+	if len(tracked) != 3 || tracked[0].TransactionID != "tx-finished" || tracked[2].TransactionID != "tx-new" ||
+		tracked[0].LastState != models.STATE_NEW || tracked[1].Options.Label != "tx-executing-label" {
+		t.Fatalf("resumed tracking %+v", tracked)
+	}
+
+	if remaining := resumed.Poll(ctx); remaining != 2 {
+		t.Fatalf("Poll left %d tracked, want 2", remaining)
+	}
+	if after.completions["tx-finished"] != 1 || after.states["tx-finished"] != models.STATE_CONFIRMED || len(after.completions) != 1 {
+		t.Errorf("completions %v, want only tx-finished once", after.completions)
+	}
+	// One reconciling change per moved transaction, not a replay
+	if len(after.changes) != 2 || after.changes[0].TransactionID != "tx-finished" || after.changes[0].PreviousState != models.STATE_NEW ||
+		after.changes[0].State != models.STATE_CONFIRMED || after.changes[1].State != models.STATE_EXECUTED {
+		t.Errorf("changes %+v", after.changes)
+	}
+
+	// The pending transactions continue to their completion
+	states.set("tx-executing", models.STATE_MINED)
+	states.set("tx-new", models.STATE_CONFIRMED)
+	if remaining := resumed.Poll(ctx); remaining != 0 {
+		t.Fatalf("Poll left %d tracked, want 0", remaining)
+	}
+	for _, id := range []string{"tx-finished", "tx-executing", "tx-new"} {
+		if after.completions[id] != 1 {
+			t.Errorf("%s completed %d times, want 1", id, after.completions[id])
+		}
+	}
+	if len(before.completions) != 0 {
+		t.Errorf("the first tracker completed %v", before.completions)
+	}
+}
+
+func TestTransactionTracker_RunStopsWhenCancelled(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	states := serveTransactionStates(relayer)
+	c := newTestClient(t, relayer)
+	fake := clock.NewFake(testClockStart)
+	c.clock = fake
+
+	tracker := NewTransactionTracker(c, TrackerHandlers{})
+	states.set("tx-pending", models.STATE_NEW)
+	if err := tracker.Track("tx-pending", TrackOptions{}); err != nil {
+		t.Fatalf("Track failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- tracker.Run(ctx, time.Hour) }()
+
+	// Run polls, then waits out the interval on the clock
+	fake.BlockUntil(1)
+	cancel()
+	select {
+	case err := <-done:
+		if err != context.Canceled {
+			t.Errorf("Run returned %v, want context.Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run kept waiting for the interval after ctx was cancelled")
+	}
+	if tracked := tracker.Tracked(); len(tracked) != 1 {
+		t.Errorf("tracking %d transactions after cancellation, want 1", len(tracked))
+	}
+}
+
+func TestTransactionTracker_LoadCheckpointInvalid(t *testing.T) {
+	tracker := NewTransactionTracker(newTestClient(t, newFakeRelayer(t)), TrackerHandlers{})
+	for name, checkpoint := range map[string]string{
+		"not JSON":        "{",
+		"unknown version": `{"version":9,"transactions":[]}`,
+		"missing ID":      `{"version":1,"transactions":[{"lastState":"STATE_NEW"}]}`,
+	} {
+		if err := tracker.LoadCheckpoint(bytes.NewBufferString(checkpoint)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+	if n := len(tracker.Tracked()); n != 0 {
+		t.Errorf("tracking %d transactions after invalid checkpoints", n)
+	}
+}
+
+func TestTransactionTracker_ResumeFromStore(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	states := serveTransactionStates(relayer)
+	c := newTestClient(t, relayer)
+
+	store, err := NewFileSubmissionStore(filepath.Join(t.TempDir(), "submissions.jsonl"))
+	if err != nil {
+		t.Fatalf("NewFileSubmissionStore failed: %v", err)
+	}
+	// Transactions are tracked in submission order, not record order
+	submittedAt := testClockStart.Add(-time.Minute)
+	lateSubmittedAt := testClockStart.Add(-30 * time.Second)
+	records := []SubmissionRecord{
+		{OperationID: "op-late", TransactionID: "tx-late", CreatedAt: testClockStart.Add(-4 * time.Minute), SubmittedAt: &lateSubmittedAt},
+		{OperationID: "op-early", TransactionID: "tx-early", CreatedAt: testClockStart.Add(-3 * time.Minute), SubmittedAt: &submittedAt},
+		{OperationID: "op-lost", CreatedAt: testClockStart.Add(-2 * time.Minute)},
+	}
+	for _, record := range records {
+		if err := store.SaveSubmission(record); err != nil {
+			t.Fatalf("SaveSubmission failed: %v", err)
+		}
+	}
+
+	recorder := newTrackerRecorder()
+	tracker := NewTransactionTracker(c, recorder.handlers())
+	added, err := tracker.ResumeFromStore(store)
+	if err != nil {
+		t.Fatalf("ResumeFromStore failed: %v", err)
+	}
+	tracked := tracker.Tracked()
+	if added != 2 || len(tracked) != 2 || tracked[0].TransactionID != "tx-early" || !tracked[0].TrackedAt.Equal(submittedAt) {
+		t.Fatalf("ResumeFromStore added %d: %+v", added, tracked)
+	}
+
+	states.set("tx-early", models.STATE_CONFIRMED)
+	states.set("tx-late", models.STATE_EXECUTED)
+	tracker.Poll(context.Background())
+	if recorder.completions["tx-early"] != 1 {
+		t.Errorf("completions %v", recorder.completions)
+	}
+	pending, err := store.ListPending()
+	if err != nil {
+		t.Fatalf("ListPending failed: %v", err)
+	}
+	if len(pending) != 2 || pending[0].OperationID != "op-late" || pending[1].OperationID != "op-lost" {
+		t.Errorf("pending %+v, want the lost and late submissions", pending)
+	}
+}
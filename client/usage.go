@@ -0,0 +1,179 @@
+package client
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	nethttp "net/http"
+	"strconv"
+	"time"
+
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+// Rate-limit headers the relayer sets on authenticated responses
+const (
+	usageLimitHeader     = "X-RateLimit-Limit"
+	usageRemainingHeader = "X-RateLimit-Remaining"
+	usageResetHeader     = "X-RateLimit-Reset"
+)
+
+// resetEpochThreshold separates X-RateLimit-Reset values that are Unix
+// timestamps from ones that are seconds until the reset
+const resetEpochThreshold = 1_000_000_000
+
+// WithQuotaWarning calls onLow when the builder's remaining quota drops below
+// threshold, a fraction of the limit in (0, 1]. It fires once per crossing
+// and again only after usage has recovered above the threshold.
+func WithQuotaWarning(threshold float64, onLow func(models.Usage)) Option {
+	return func(c *RelayClient) error {
+		if threshold <= 0 || threshold > 1 {
+			return errors.ErrInvalidConfiguration(fmt.Sprintf("quota warning threshold %v must be in (0, 1]", threshold))
+		}
+		if onLow == nil {
+			return errors.ErrMissingRequiredField("onLow")
+		}
+		c.quotaThreshold = threshold
+		c.onQuotaLow = onLow
+		return nil
+	}
+}
+
+// GetUsage returns the builder's quota usage. It asks the relayer's usage
+// endpoint when there is one, and otherwise returns the snapshot taken from
+// the rate-limit headers of the latest authenticated response.
+func (c *RelayClient) GetUsage() (*models.Usage, error) {
+	return c.getUsage(context.Background())
+}
+
+// getUsage returns the builder's quota usage, aborting when ctx is done
+func (c *RelayClient) getUsage(ctx context.Context) (*models.Usage, error) {
+	if c.builderConfig != nil && !c.usageEndpointMissing() {
+		usage, err := c.fetchUsage(ctx)
+		if err == nil {
+			return usage, nil
+		}
+		if !errors.IsNotFound(err) {
+			return nil, err
+		}
+		c.usageMu.Lock()
+		c.noUsageEndpoint = true
+		c.usageMu.Unlock()
+	}
+
+	c.usageMu.Lock()
+	defer c.usageMu.Unlock()
+	if c.usage == nil {
+		return nil, errors.ErrUsageUnavailable
+	}
+	snapshot := *c.usage
+	return &snapshot, nil
+}
+
+// fetchUsage reads and records the usage endpoint
+func (c *RelayClient) fetchUsage(ctx context.Context) (*models.Usage, error) {
+	headers, err := c.generateBuilderHeaders("GET", GET_USAGE, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var usage models.Usage
+	send := func(headers map[string]string) error {
+		return c.httpClient.GetJSONContext(ctx, GET_USAGE, headers, &usage)
+	}
+	if err := c.retryUnknownKey(send(headers), "GET", GET_USAGE, nil, send); err != nil {
+		return nil, err
+	}
+
+	usage.UpdatedAt = c.clock.Now()
+	c.recordUsage(usage)
+	return &usage, nil
+}
+
+// usageEndpointMissing reports whether the relayer answered GET_USAGE with a 404
+func (c *RelayClient) usageEndpointMissing() bool {
+	c.usageMu.Lock()
+	defer c.usageMu.Unlock()
+	return c.noUsageEndpoint
+}
+
+// observeResponse records the usage carried by a response's rate-limit headers
+func (c *RelayClient) observeResponse(resp *nethttp.Response) {
+	if usage, ok := parseUsageHeaders(resp.Header, c.clock.Now()); ok {
+		c.recordUsage(usage)
+	}
+}
+
+// parseUsageHeaders reads a usage snapshot taken at now from rate-limit
+// headers. ok is false unless both the limit and remaining count are present.
+func parseUsageHeaders(header nethttp.Header, now time.Time) (usage models.Usage, ok bool) {
+	limit, err := strconv.ParseInt(header.Get(usageLimitHeader), 10, 64)
+	if err != nil || limit < 0 {
+		return models.Usage{}, false
+	}
+	remaining, err := strconv.ParseInt(header.Get(usageRemainingHeader), 10, 64)
+	if err != nil || remaining < 0 {
+		return models.Usage{}, false
+	}
+
+	usage = models.Usage{Limit: limit, Remaining: remaining, UpdatedAt: now}
+	if reset, err := strconv.ParseInt(header.Get(usageResetHeader), 10, 64); err == nil && reset >= 0 {
+		var resetAt time.Time
+		if reset >= resetEpochThreshold {
+			resetAt = time.Unix(reset, 0)
+		} else {
+			resetAt = now.Add(time.Duration(reset) * time.Second)
+		}
+		usage.ResetAt = &resetAt
+	}
+	return usage, true
+}
+
+// recordUsage stores usage as the latest snapshot and fires the quota
+// warning if usage has just dropped below the threshold
+func (c *RelayClient) recordUsage(usage models.Usage) {
+	c.usageMu.Lock()
+	c.usage = &usage
+	fire := false
+	if c.onQuotaLow != nil {
+		low := usage.Limit > 0 && usage.RemainingFraction() < c.quotaThreshold
+		fire = low && !c.quotaLow
+		c.quotaLow = low
+	}
+	onLow := c.onQuotaLow
+	c.usageMu.Unlock()
+
+	if fire {
+		onLow(usage)
+	}
+}
+
+// quotaError returns err as a QuotaExceededError when the relayer rejected
+// the request because the builder's quota is used up: either with
+// CodeQuotaExceeded, or with a 429 whose rate-limit headers show nothing
+// remaining. Other errors are returned unchanged.
+func (c *RelayClient) quotaError(err error) error {
+	var apiErr *errors.RelayerApiError
+	if !stderrors.As(err, &apiErr) {
+		return err
+	}
+
+	c.usageMu.Lock()
+	var usage *models.Usage
+	if c.usage != nil {
+		snapshot := *c.usage
+		usage = &snapshot
+	}
+	c.usageMu.Unlock()
+
+	exhausted := apiErr.Code == errors.CodeQuotaExceeded ||
+		(apiErr.StatusCode == 429 && usage != nil && usage.Remaining == 0)
+	if !exhausted {
+		return err
+	}
+	if usage == nil {
+		return errors.ErrQuotaExceeded(0, nil, err)
+	}
+	return errors.ErrQuotaExceeded(usage.Limit, usage.ResetAt, err)
+}
@@ -0,0 +1,281 @@
+package client
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+// usageHeaders returns a handler wrapper setting the rate-limit headers;
+// reset is omitted when empty
+func usageHeaders(relayer *fakeRelayer, limit, remaining int64, reset string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(usageLimitHeader, strconv.FormatInt(limit, 10))
+		w.Header().Set(usageRemainingHeader, strconv.FormatInt(remaining, 10))
+		if reset != "" {
+			w.Header().Set(usageResetHeader, reset)
+		}
+		relayer.ServeDefault(w, r)
+	}
+}
+
+// serveRemaining sets the rate-limit headers on nonce responses, counting
+// down remaining through the given values and repeating the last one
+func serveRemaining(relayer *fakeRelayer, limit int64, remaining ...int64) {
+	var mu sync.Mutex
+	calls := 0
+	relayer.Handle(GET_NONCE, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		i := calls
+		calls++
+		mu.Unlock()
+		if i >= len(remaining) {
+			i = len(remaining) - 1
+		}
+		usageHeaders(relayer, limit, remaining[i], "")(w, r)
+	})
+}
+
+func TestGetUsage_Headers(t *testing.T) {
+	epoch := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	inAnHour := testClockStart.Add(time.Hour)
+
+	tests := []struct {
+		name      string
+		handler   func(relayer *fakeRelayer) http.HandlerFunc
+		want      models.Usage
+		wantReset *time.Time
+		shouldErr bool
+	}{
+		{
+			name: "reset as timestamp",
+			handler: func(r *fakeRelayer) http.HandlerFunc {
+				return usageHeaders(r, 1000, 750, strconv.FormatInt(epoch.Unix(), 10))
+			},
+			want:      models.Usage{Limit: 1000, Remaining: 750},
+			wantReset: &epoch,
+		},
+		{
+			name:      "reset as seconds",
+			handler:   func(r *fakeRelayer) http.HandlerFunc { return usageHeaders(r, 1000, 10, "3600") },
+			want:      models.Usage{Limit: 1000, Remaining: 10},
+			wantReset: &inAnHour,
+		},
+		{
+			name:    "no reset",
+			handler: func(r *fakeRelayer) http.HandlerFunc { return usageHeaders(r, 50, 0, "") },
+			want:    models.Usage{Limit: 50, Remaining: 0},
+		},
+		{
+			name:      "no headers",
+			shouldErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			relayer := newFakeRelayer(t)
+			if tt.handler != nil {
+				relayer.Handle(SUBMIT_TRANSACTION, tt.handler(relayer))
+			}
+			c := newTestClient(t, relayer)
+
+			if _, err := c.Execute(testTransactions(), ""); err != nil {
+				t.Fatalf("Execute failed: %v", err)
+			}
+
+			usage, err := c.GetUsage()
+			if (err != nil) != tt.shouldErr {
+				t.Fatalf("GetUsage() error = %v, shouldErr %v", err, tt.shouldErr)
+			}
+			if tt.shouldErr {
+				if err != errors.ErrUsageUnavailable {
+					t.Errorf("error = %v, want ErrUsageUnavailable", err)
+				}
+				return
+			}
+			if usage.Limit != tt.want.Limit || usage.Remaining != tt.want.Remaining {
+				t.Errorf("usage = %d/%d, want %d/%d", usage.Remaining, usage.Limit, tt.want.Remaining, tt.want.Limit)
+			}
+			if !usage.UpdatedAt.Equal(testClockStart) {
+				t.Errorf("UpdatedAt = %v, want %v", usage.UpdatedAt, testClockStart)
+			}
+			switch {
+			case tt.wantReset == nil && usage.ResetAt != nil:
+				t.Errorf("ResetAt = %v, want none", usage.ResetAt)
+			case tt.wantReset != nil && (usage.ResetAt == nil || !usage.ResetAt.Equal(*tt.wantReset)):
+				t.Errorf("ResetAt = %v, want %v", usage.ResetAt, tt.wantReset)
+			}
+		})
+	}
+}
+
+func TestGetUsage_Endpoint(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	reset := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	var authenticated bool
+	relayer.Handle(GET_USAGE, func(w http.ResponseWriter, r *http.Request) {
+		authenticated = r.Header.Get("POLY_BUILDER_API_KEY") != ""
+		json.NewEncoder(w).Encode(models.Usage{Limit: 1000, Remaining: 400, ResetAt: &reset})
+	})
+	c := newTestClient(t, relayer)
+
+	usage, err := c.GetUsage()
+	if err != nil {
+		t.Fatalf("GetUsage failed: %v", err)
+	}
+	if !authenticated {
+		t.Error("usage request was not authenticated")
+	}
+	if usage.Limit != 1000 || usage.Remaining != 400 || usage.ResetAt == nil || !usage.ResetAt.Equal(reset) {
+		t.Errorf("usage = %+v", usage)
+	}
+	if got := usage.RemainingFraction(); got != 0.4 {
+		t.Errorf("RemainingFraction() = %v, want 0.4", got)
+	}
+}
+
+func TestGetUsage_EndpointMissingIsRemembered(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	var calls int
+	relayer.Handle(GET_USAGE, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		relayer.ServeDefault(w, r)
+	})
+	relayer.Handle(GET_NONCE, usageHeaders(relayer, 100, 99, ""))
+	c := newTestClient(t, relayer)
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.GetUsage(); err != errors.ErrUsageUnavailable {
+			t.Fatalf("GetUsage() error = %v, want ErrUsageUnavailable", err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("usage endpoint called %d times, want 1", calls)
+	}
+
+	if _, err := c.Execute(testTransactions(), ""); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	usage, err := c.GetUsage()
+	if err != nil || usage.Remaining != 99 {
+		t.Errorf("GetUsage() = %+v, %v; want the header snapshot", usage, err)
+	}
+}
+
+func TestQuotaWarning(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	// Drops below 20% at 15, stays low, recovers at 80, drops again at 2
+	serveRemaining(relayer, 100, 50, 15, 5, 80, 2)
+	c := newTestClient(t, relayer)
+
+	var warnings []models.Usage
+	if err := WithQuotaWarning(0.2, func(u models.Usage) { warnings = append(warnings, u) })(c); err != nil {
+		t.Fatalf("WithQuotaWarning failed: %v", err)
+	}
+
+	for i := 0; i < 5; i++ {
+		if _, err := c.Execute(testTransactions(), ""); err != nil {
+			t.Fatalf("Execute %d failed: %v", i, err)
+		}
+	}
+
+	if len(warnings) != 2 {
+		t.Fatalf("got %d warnings, want 2: %+v", len(warnings), warnings)
+	}
+	if warnings[0].Remaining != 15 || warnings[1].Remaining != 2 {
+		t.Errorf("warnings at remaining %d and %d, want 15 and 2", warnings[0].Remaining, warnings[1].Remaining)
+	}
+}
+
+func TestWithQuotaWarning_Validation(t *testing.T) {
+	onLow := func(models.Usage) {}
+
+	tests := []struct {
+		name      string
+		threshold float64
+		onLow     func(models.Usage)
+		shouldErr bool
+	}{
+		{name: "valid", threshold: 0.1, onLow: onLow},
+		{name: "whole quota", threshold: 1, onLow: onLow},
+		{name: "zero", threshold: 0, onLow: onLow, shouldErr: true},
+		{name: "above one", threshold: 1.5, onLow: onLow, shouldErr: true},
+		{name: "no callback", threshold: 0.1, shouldErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := WithQuotaWarning(tt.threshold, tt.onLow)(&RelayClient{})
+			if (err != nil) != tt.shouldErr {
+				t.Errorf("WithQuotaWarning() error = %v, shouldErr %v", err, tt.shouldErr)
+			}
+		})
+	}
+}
+
+func TestQuotaExceeded(t *testing.T) {
+	quotaCode := errors.CodeQuotaExceeded
+	reject := func(status int, code *string, headers map[string]string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			for k, v := range headers {
+				w.Header().Set(k, v)
+			}
+			w.WriteHeader(status)
+			json.NewEncoder(w).Encode(models.ErrorResponse{Error: "rejected", Code: code})
+		}
+	}
+	exhausted := map[string]string{usageLimitHeader: "100", usageRemainingHeader: "0", usageResetHeader: "60"}
+
+	tests := []struct {
+		name      string
+		handler   http.HandlerFunc
+		wantQuota bool
+		wantLimit int64
+	}{
+		{name: "quota code", handler: reject(http.StatusTooManyRequests, &quotaCode, nil), wantQuota: true},
+		{name: "quota code on 403", handler: reject(http.StatusForbidden, &quotaCode, nil), wantQuota: true},
+		{name: "429 with nothing remaining", handler: reject(http.StatusTooManyRequests, nil, exhausted), wantQuota: true, wantLimit: 100},
+		{name: "429 rate limit", handler: reject(http.StatusTooManyRequests, nil, nil)},
+		{name: "429 with quota remaining", handler: reject(http.StatusTooManyRequests, nil, map[string]string{usageLimitHeader: "100", usageRemainingHeader: "3"})},
+		{name: "other error", handler: reject(http.StatusBadRequest, nil, exhausted)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			relayer := newFakeRelayer(t)
+			relayer.Handle(SUBMIT_TRANSACTION, tt.handler)
+			c := newTestClient(t, relayer)
+
+			_, err := c.Execute(testTransactions(), "")
+			if err == nil {
+				t.Fatal("Execute should fail")
+			}
+
+			var quotaErr *errors.QuotaExceededError
+			if got := stderrors.As(err, &quotaErr); got != tt.wantQuota {
+				t.Fatalf("QuotaExceededError = %v, want %v (error: %v)", got, tt.wantQuota, err)
+			}
+			if !tt.wantQuota {
+				return
+			}
+			if quotaErr.Limit != tt.wantLimit {
+				t.Errorf("Limit = %d, want %d", quotaErr.Limit, tt.wantLimit)
+			}
+			if tt.wantLimit > 0 && (quotaErr.ResetAt == nil || !quotaErr.ResetAt.Equal(testClockStart.Add(time.Minute))) {
+				t.Errorf("ResetAt = %v, want %v", quotaErr.ResetAt, testClockStart.Add(time.Minute))
+			}
+			var apiErr *errors.RelayerApiError
+			if !stderrors.As(err, &apiErr) {
+				t.Error("QuotaExceededError should wrap the relayer's error response")
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+// ProxyCreationEventTopic is the log topic of the Safe factory's
+// ProxyCreation(address proxy, address singleton) event
+var ProxyCreationEventTopic = crypto.Keccak256Hash([]byte("ProxyCreation(address,address)"))
+
+// ReceiptFetcher fetches transaction receipts from a chain RPC endpoint.
+// *ethclient.Client satisfies this interface.
+type ReceiptFetcher interface {
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+}
+
+// WithRPCClient configures an RPC client used for on-chain checks such as VerifyDeployment
+func WithRPCClient(rpc ReceiptFetcher) Option {
+	return func(c *RelayClient) error {
+		c.rpcClient = rpc
+		return nil
+	}
+}
+
+// VerifyDeployment fetches the receipt of a mined SAFE-CREATE transaction,
+// decodes the factory's ProxyCreation event and checks that the emitted proxy
+// address matches GetExpectedSafe. A DeployedAddressMismatchError is returned
+// when they differ. Requires an RPC client (see WithRPCClient).
+func (c *RelayClient) VerifyDeployment(transactionID string) error {
+	if c.rpcClient == nil {
+		return errors.ErrInvalidConfiguration("VerifyDeployment requires an RPC client")
+	}
+
+	txn, err := c.GetTransaction(transactionID)
+	if err != nil {
+		return err
+	}
+
+	return c.verifyDeployedTransaction(txn)
+}
+
+// verifyDeployedTransaction checks the ProxyCreation event of a mined SAFE-CREATE transaction
+func (c *RelayClient) verifyDeployedTransaction(txn *models.RelayerTransaction) error {
+	if !txn.IsMined() {
+		return errors.NewRelayerClientError(fmt.Sprintf("transaction %s has not been mined", txn.TransactionID), nil)
+	}
+
+	expected, err := c.GetExpectedSafe()
+	if err != nil {
+		return err
+	}
+
+	receipt, err := c.rpcClient.TransactionReceipt(context.Background(), common.HexToHash(*txn.Hash))
+	if err != nil {
+		return errors.NewRelayerClientError("failed to fetch deployment receipt", err)
+	}
+
+	proxy, err := DecodeProxyCreation(receipt, common.HexToAddress(c.currentContractConfig().SafeFactory))
+	if err != nil {
+		return err
+	}
+
+	if proxy != common.HexToAddress(expected) {
+		return errors.ErrDeployedAddressMismatch(expected, proxy.Hex())
+	}
+	return nil
+}
+
+// DecodeProxyCreation returns the proxy address from the ProxyCreation event
+// emitted by factory in the receipt. Both the indexed (Safe >= 1.4) and
+// non-indexed (Safe 1.3) event layouts are supported.
+func DecodeProxyCreation(receipt *types.Receipt, factory common.Address) (common.Address, error) {
+	for _, entry := range receipt.Logs {
+		if entry.Address != factory || len(entry.Topics) == 0 || entry.Topics[0] != ProxyCreationEventTopic {
+			continue
+		}
+
+		if len(entry.Topics) > 1 {
+			return common.BytesToAddress(entry.Topics[1].Bytes()), nil
+		}
+		if len(entry.Data) < 32 {
+			return common.Address{}, errors.ErrInvalidResponse("ProxyCreation event data too short")
+		}
+		return common.BytesToAddress(entry.Data[:32]), nil
+	}
+
+	return common.Address{}, errors.ErrInvalidResponse("no ProxyCreation event found in receipt")
+}
@@ -0,0 +1,148 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+const testCreationTxHash = "0x5f2a1c7e6b1d0e4c3a8f9b2d7c6e5a4b3c2d1e0f9a8b7c6d5e4f3a2b1c0d9e8f"
+
+// fixtureReceiptFetcher serves a canned receipt loaded from testdata
+type fixtureReceiptFetcher struct {
+	receipt *types.Receipt
+}
+
+func (f *fixtureReceiptFetcher) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	if txHash != f.receipt.TxHash {
+		return nil, stderrors.New("not found")
+	}
+	return f.receipt, nil
+}
+
+func loadReceiptFixture(t *testing.T) *types.Receipt {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join("testdata", "safe_create_receipt.json"))
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+
+	var receipt types.Receipt
+	if err := json.Unmarshal(data, &receipt); err != nil {
+		t.Fatalf("Failed to decode fixture: %v", err)
+	}
+	return &receipt
+}
+
+// serveMinedTransaction makes the fake relayer report a mined transaction
+func serveMinedTransaction(relayer *fakeRelayer) {
+	relayer.Handle(GET_TRANSACTION, func(w http.ResponseWriter, r *http.Request) {
+		hash := testCreationTxHash
+		json.NewEncoder(w).Encode([]models.RelayerTransaction{{
+			TransactionID: r.URL.Query().Get("id"),
+			State:         models.STATE_CONFIRMED,
+			Type:          models.SAFE_CREATE,
+			Hash:          &hash,
+		}})
+	})
+}
+
+func TestDecodeProxyCreation(t *testing.T) {
+	receipt := loadReceiptFixture(t)
+
+	proxy, err := DecodeProxyCreation(receipt, common.HexToAddress("0xaacFeEa03eb1561C4e67d661e40682Bd20E3541b"))
+	if err != nil {
+		t.Fatalf("DecodeProxyCreation failed: %v", err)
+	}
+	if proxy != common.HexToAddress("0xd93B25cb943D14d0d34FBaF01Fc93a0f8b5F6E47") {
+		t.Errorf("proxy = %s, want 0xd93B25cb943D14d0d34FBaF01Fc93a0f8b5F6E47", proxy.Hex())
+	}
+
+	if _, err := DecodeProxyCreation(receipt, common.HexToAddress("0x0000000000000000000000000000000000000001")); err == nil {
+		t.Error("expected error when the factory emitted no ProxyCreation event")
+	}
+}
+
+func TestVerifyDeployment(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	serveMinedTransaction(relayer)
+
+	c := newTestClient(t, relayer)
+	c.rpcClient = &fixtureReceiptFetcher{receipt: loadReceiptFixture(t)}
+
+	if err := c.VerifyDeployment("tx-1"); err != nil {
+		t.Fatalf("VerifyDeployment failed: %v", err)
+	}
+}
+
+func TestVerifyDeployment_Mismatch(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	serveMinedTransaction(relayer)
+
+	receipt := loadReceiptFixture(t)
+	wrongProxy := common.HexToAddress("0x000000000000000000000000000000000000dEaD")
+	copy(receipt.Logs[1].Data[:32], common.LeftPadBytes(wrongProxy.Bytes(), 32))
+
+	c := newTestClient(t, relayer)
+	c.rpcClient = &fixtureReceiptFetcher{receipt: receipt}
+
+	err := c.VerifyDeployment("tx-1")
+	var mismatch *errors.DeployedAddressMismatchError
+	if !stderrors.As(err, &mismatch) {
+		t.Fatalf("error = %v, want DeployedAddressMismatchError", err)
+	}
+	expected, _ := c.GetExpectedSafe()
+	if mismatch.Expected != expected || mismatch.Actual != wrongProxy.Hex() {
+		t.Errorf("mismatch = %+v, want expected %s actual %s", mismatch, expected, wrongProxy.Hex())
+	}
+}
+
+func TestVerifyDeployment_RequiresRPC(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	c := newTestClient(t, relayer)
+
+	if err := c.VerifyDeployment("tx-1"); err == nil {
+		t.Error("expected error without an RPC client")
+	}
+	if _, err := c.DeployWithOptions(DeployOptions{VerifyDeployment: true}); err == nil {
+		t.Error("expected DeployWithOptions to reject VerifyDeployment without an RPC client")
+	}
+	if len(relayer.Submissions()) != 0 {
+		t.Error("nothing should be submitted when verification cannot run")
+	}
+}
+
+func TestDeployWithOptions_VerifyOnWait(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	relayer.SetDeployed(false)
+	serveMinedTransaction(relayer)
+
+	receipt := loadReceiptFixture(t)
+	c := newTestClient(t, relayer)
+	c.rpcClient = &fixtureReceiptFetcher{receipt: receipt}
+
+	response, err := c.DeployWithOptions(DeployOptions{VerifyDeployment: true})
+	if err != nil {
+		t.Fatalf("DeployWithOptions failed: %v", err)
+	}
+	if _, err := response.WaitWithOptions(models.WaitOptions{MaxPolls: 1}); err != nil {
+		t.Fatalf("Wait failed: %v", err)
+	}
+
+	copy(receipt.Logs[1].Data[:32], make([]byte, 32))
+	var mismatch *errors.DeployedAddressMismatchError
+	if _, err := response.WaitWithOptions(models.WaitOptions{MaxPolls: 1}); !stderrors.As(err, &mismatch) {
+		t.Errorf("Wait error = %v, want DeployedAddressMismatchError", err)
+	}
+}
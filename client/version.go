@@ -0,0 +1,70 @@
+package client
+
+import (
+	"context"
+
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+// WithRequestVersion selects the submission payload schema. The default is
+// models.RequestVersionV1.
+func WithRequestVersion(version models.RequestVersion) Option {
+	return func(c *RelayClient) error {
+		if !version.IsValid() {
+			return errors.ErrUnsupportedRequestVersion(int(version))
+		}
+		c.requestVersion = version
+		c.negotiateVersion = false
+		return nil
+	}
+}
+
+// WithRequestVersionNegotiation makes the client ask the relayer's
+// capabilities endpoint for the payload versions it accepts before the first
+// submission and use the newest one both sides support. Relayers without the
+// endpoint are assumed to accept only v1.
+func WithRequestVersionNegotiation() Option {
+	return func(c *RelayClient) error {
+		c.negotiateVersion = true
+		return nil
+	}
+}
+
+// RequestVersion returns the submission payload schema currently in use
+func (c *RelayClient) RequestVersion() models.RequestVersion {
+	c.versionMu.Lock()
+	defer c.versionMu.Unlock()
+	return c.requestVersion
+}
+
+// resolveRequestVersion returns the payload version, negotiating it with the
+// relayer the first time if negotiation is enabled
+func (c *RelayClient) resolveRequestVersion(ctx context.Context) (models.RequestVersion, error) {
+	c.versionMu.Lock()
+	defer c.versionMu.Unlock()
+
+	if !c.negotiateVersion {
+		return c.requestVersion, nil
+	}
+
+	var capabilities models.CapabilitiesResponse
+	if err := c.httpClient.GetJSONContext(ctx, GET_CAPABILITIES, nil, &capabilities); err != nil {
+		if !errors.IsNotFound(err) {
+			return 0, err
+		}
+		capabilities.RequestVersions = []models.RequestVersion{models.RequestVersionV1}
+	}
+
+	version := models.RequestVersionV1
+	for _, v := range capabilities.RequestVersions {
+		if v.IsValid() && v > version {
+			version = v
+		}
+	}
+
+	c.logger.Printf("Negotiated request version %s", version)
+	c.requestVersion = version
+	c.negotiateVersion = false
+	return version, nil
+}
@@ -0,0 +1,134 @@
+package client
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+// verifySubmitHMAC checks the builder signature of every submission against
+// the exact body received, then delegates to the default handler
+func verifySubmitHMAC(t *testing.T, relayer *fakeRelayer) {
+	relayer.Handle(SUBMIT_TRANSACTION, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		message := r.Header.Get("POLY_BUILDER_TIMESTAMP") + r.Method + r.URL.Path + string(body)
+		h := hmac.New(sha256.New, []byte("test-secret"))
+		h.Write([]byte(message))
+		if want := base64.URLEncoding.EncodeToString(h.Sum(nil)); r.Header.Get("POLY_BUILDER_SIGNATURE") != want {
+			t.Errorf("HMAC signature does not cover the submitted body")
+		}
+
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		relayer.ServeDefault(w, r)
+	})
+}
+
+func TestRequestVersion(t *testing.T) {
+	tests := []struct {
+		name         string
+		opts         []Option
+		capabilities []models.RequestVersion
+		wantVersions []models.RequestVersion
+		wantQueries  int
+	}{
+		{
+			name:         "default is v1 without negotiation",
+			capabilities: []models.RequestVersion{models.RequestVersionV1, models.RequestVersionV2},
+			wantVersions: []models.RequestVersion{models.RequestVersionV1, models.RequestVersionV1},
+		},
+		{
+			name:         "explicit v2",
+			opts:         []Option{WithRequestVersion(models.RequestVersionV2)},
+			wantVersions: []models.RequestVersion{models.RequestVersionV2, models.RequestVersionV2},
+		},
+		{
+			name:         "negotiated v2",
+			opts:         []Option{WithRequestVersionNegotiation()},
+			capabilities: []models.RequestVersion{models.RequestVersionV1, models.RequestVersionV2},
+			wantVersions: []models.RequestVersion{models.RequestVersionV2, models.RequestVersionV2},
+			wantQueries:  1,
+		},
+		{
+			name:         "negotiation ignores unknown versions",
+			opts:         []Option{WithRequestVersionNegotiation()},
+			capabilities: []models.RequestVersion{models.RequestVersionV2, 7},
+			wantVersions: []models.RequestVersion{models.RequestVersionV2, models.RequestVersionV2},
+			wantQueries:  1,
+		},
+		{
+			name:         "relayer without capabilities endpoint",
+			opts:         []Option{WithRequestVersionNegotiation()},
+			wantVersions: []models.RequestVersion{models.RequestVersionV1, models.RequestVersionV1},
+			wantQueries:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			relayer := newFakeRelayer(t)
+			verifySubmitHMAC(t, relayer)
+
+			var mu sync.Mutex
+			queries := 0
+			if tt.capabilities != nil {
+				relayer.Handle(GET_CAPABILITIES, func(w http.ResponseWriter, r *http.Request) {
+					mu.Lock()
+					queries++
+					mu.Unlock()
+					json.NewEncoder(w).Encode(models.CapabilitiesResponse{RequestVersions: tt.capabilities})
+				})
+			} else {
+				relayer.Handle(GET_CAPABILITIES, func(w http.ResponseWriter, r *http.Request) {
+					mu.Lock()
+					queries++
+					mu.Unlock()
+					relayer.ServeDefault(w, r)
+				})
+			}
+
+			c := newTestClient(t, relayer)
+			for _, opt := range tt.opts {
+				if err := opt(c); err != nil {
+					t.Fatalf("option failed: %v", err)
+				}
+			}
+
+			for i := 0; i < 2; i++ {
+				if _, err := c.Execute(testTransactions(), ""); err != nil {
+					t.Fatalf("Execute failed: %v", err)
+				}
+			}
+
+			if got := relayer.RequestVersions(); !reflect.DeepEqual(got, tt.wantVersions) {
+				t.Errorf("versions = %v, want %v", got, tt.wantVersions)
+			}
+			if c.RequestVersion() != tt.wantVersions[0] {
+				t.Errorf("RequestVersion() = %v, want %v", c.RequestVersion(), tt.wantVersions[0])
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if queries != tt.wantQueries {
+				t.Errorf("capabilities queries = %d, want %d", queries, tt.wantQueries)
+			}
+		})
+	}
+}
+
+func TestWithRequestVersion_Invalid(t *testing.T) {
+	c := &RelayClient{}
+	for _, version := range []models.RequestVersion{0, 3} {
+		if err := WithRequestVersion(version)(c); err == nil {
+			t.Errorf("Expected error for version %s", version)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package client
+
+import (
+	"time"
+
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+// WaitAll waits until every response's transaction reaches one of
+// opts.TargetStates. One loop polls the transactions round-robin, each at
+// most once per interval, for up to opts.MaxPolls rounds and opts.Timeout.
+// Failures do not stop the others: the results are indexed like responses
+// (nil where the wait failed), and if any failed the error is a
+// MultiWaitError holding the individual errors.
+func (c *RelayClient) WaitAll(responses []*models.ClientRelayerTransactionResponse, opts models.WaitOptions) ([]*models.RelayerTransaction, error) {
+	w, err := c.newMultiWait("WaitAll", responses, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer w.op.cancel()
+
+	w.run(func(int) bool { return false })
+	return w.results, w.err()
+}
+
+// WaitAny waits until one of the responses' transactions reaches one of
+// opts.TargetStates and returns its index and transaction. The others are
+// no longer polled. If every transaction fails or times out, the index is
+// -1 and the error is a MultiWaitError.
+func (c *RelayClient) WaitAny(responses []*models.ClientRelayerTransactionResponse, opts models.WaitOptions) (int, *models.RelayerTransaction, error) {
+	w, err := c.newMultiWait("WaitAny", responses, opts)
+	if err != nil {
+		return -1, nil, err
+	}
+	defer w.op.cancel()
+
+	winner := -1
+	w.run(func(i int) bool {
+		winner = i
+		return true
+	})
+	if winner < 0 {
+		return -1, nil, w.err()
+	}
+	return winner, w.results[winner], nil
+}
+
+// multiWait is the shared polling loop of WaitAll and WaitAny
+type multiWait struct {
+	client    *RelayClient
+	op        *operation
+	opts      models.WaitOptions
+	responses []*models.ClientRelayerTransactionResponse
+	polls     []*transactionPoll
+	results   []*models.RelayerTransaction
+	errs      []error
+}
+
+// newMultiWait validates responses and starts an operation for waiting on them
+func (c *RelayClient) newMultiWait(name string, responses []*models.ClientRelayerTransactionResponse, opts models.WaitOptions) (*multiWait, error) {
+	if len(responses) == 0 {
+		return nil, errors.ErrMissingRequiredField("responses")
+	}
+	opts = opts.WithDefaults()
+
+	polls := make([]*transactionPoll, len(responses))
+	for i, response := range responses {
+		if response == nil || response.TransactionID == "" {
+			return nil, errors.NewRelayerClientError("every response must have a transaction ID", nil)
+		}
+		polls[i] = newTransactionPoll(response.TransactionID, opts.TargetStates, opts.FailState)
+	}
+
+	return &multiWait{
+		client:    c,
+		op:        newOperation(name, opts.Timeout),
+		opts:      opts,
+		responses: responses,
+		polls:     polls,
+		results:   make([]*models.RelayerTransaction, len(responses)),
+		errs:      make([]error, len(responses)),
+	}, nil
+}
+
+// run polls the pending transactions once per round until none is left,
+// the rounds or the timeout run out, or stop returns true for a transaction
+// that succeeded. Transactions still pending at the end time out.
+func (w *multiWait) run(stop func(i int) bool) {
+	c := w.client
+	interval := w.opts.PollInterval()
+	var deadline time.Time
+	if w.opts.Timeout > 0 {
+		deadline = c.clock.Now().Add(w.opts.Timeout)
+	}
+
+	pending := make([]int, len(w.polls))
+	for i := range pending {
+		pending[i] = i
+	}
+
+	for round := 0; round < w.opts.MaxPolls && len(pending) > 0; round++ {
+		if round > 0 {
+			wait := interval
+			if !deadline.IsZero() {
+				remaining := deadline.Sub(c.clock.Now())
+				if remaining <= 0 {
+					break
+				}
+				if remaining < wait {
+					wait = remaining
+				}
+			}
+			c.clock.Sleep(wait)
+		}
+
+		stillPending := pending[:0]
+		for _, i := range pending {
+			done, txn, err := c.pollOnce(w.op.ctx, w.polls[i])
+			if !done {
+				stillPending = append(stillPending, i)
+				continue
+			}
+			c.emitWaitEnd(w.polls[i], txn, err)
+			if err == nil {
+				err = w.responses[i].RunWaitHook(txn)
+			}
+			if err != nil {
+				w.errs[i] = w.op.tag(err)
+				continue
+			}
+			w.results[i] = txn
+			if stop(i) {
+				return
+			}
+		}
+		pending = stillPending
+	}
+
+	for _, i := range pending {
+		err := errors.ErrPollingTimeout(w.polls[i].transactionID)
+		c.emitWaitTimeout(w.polls[i], err)
+		w.errs[i] = w.op.tag(err)
+	}
+}
+
+// err returns a MultiWaitError if any transaction failed
+func (w *multiWait) err() error {
+	for _, err := range w.errs {
+		if err != nil {
+			ids := make([]string, len(w.polls))
+			for i, p := range w.polls {
+				ids[i] = p.transactionID
+			}
+			return errors.ErrMultiWait(ids, w.errs)
+		}
+	}
+	return nil
+}
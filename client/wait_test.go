@@ -0,0 +1,262 @@
+package client
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+// scriptedTransactions makes the fake relayer report successive states per
+// transaction ID, repeating the last one, and counts the polls of each
+type scriptedTransactions struct {
+	mu     sync.Mutex
+	states map[string][]models.RelayerTransactionState
+	polls  map[string]int
+}
+
+func scriptTransactions(relayer *fakeRelayer, states map[string][]models.RelayerTransactionState) *scriptedTransactions {
+	s := &scriptedTransactions{states: states, polls: make(map[string]int)}
+	relayer.Handle(GET_TRANSACTION, func(w http.ResponseWriter, r *http.Request) {
+		id := r.URL.Query().Get("id")
+		s.mu.Lock()
+		script := s.states[id]
+		state := script[len(script)-1]
+		if n := s.polls[id]; n < len(script) {
+			state = script[n]
+		}
+		s.polls[id]++
+		s.mu.Unlock()
+
+		json.NewEncoder(w).Encode([]models.RelayerTransaction{{TransactionID: id, State: state}})
+	})
+	return s
+}
+
+func (s *scriptedTransactions) pollCounts() map[string]int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	counts := make(map[string]int, len(s.polls))
+	for id, n := range s.polls {
+		counts[id] = n
+	}
+	return counts
+}
+
+// waitResponses returns a response for each transaction ID
+func waitResponses(ids ...string) []*models.ClientRelayerTransactionResponse {
+	responses := make([]*models.ClientRelayerTransactionResponse, len(ids))
+	for i, id := range ids {
+		responses[i] = models.NewClientRelayerTransactionResponse(id)
+	}
+	return responses
+}
+
+func TestWaitAll(t *testing.T) {
+	const (
+		newState  = models.STATE_NEW
+		confirmed = models.STATE_CONFIRMED
+		failed    = models.STATE_FAILED
+	)
+
+	tests := []struct {
+		name       string
+		states     map[string][]models.RelayerTransactionState
+		opts       models.WaitOptions
+		wantOK     []bool
+		wantPolls  map[string]int
+		wantSlept  []time.Duration
+		wantFailed int
+	}{
+		{
+			name: "all succeed",
+			states: map[string][]models.RelayerTransactionState{
+				"tx-a": {newState, confirmed},
+				"tx-b": {confirmed},
+				"tx-c": {newState, newState, confirmed},
+			},
+			opts:      models.WaitOptions{Interval: time.Second},
+			wantOK:    []bool{true, true, true},
+			wantPolls: map[string]int{"tx-a": 2, "tx-b": 1, "tx-c": 3},
+			wantSlept: []time.Duration{time.Second, time.Second},
+		},
+		{
+			name: "failure and max polls",
+			states: map[string][]models.RelayerTransactionState{
+				"tx-a": {newState, confirmed},
+				"tx-b": {failed},
+				"tx-c": {newState},
+			},
+			opts:       models.WaitOptions{Interval: time.Second, MaxPolls: 3},
+			wantOK:     []bool{true, false, false},
+			wantPolls:  map[string]int{"tx-a": 2, "tx-b": 1, "tx-c": 3},
+			wantSlept:  []time.Duration{time.Second, time.Second},
+			wantFailed: 2,
+		},
+		{
+			name: "overall timeout",
+			states: map[string][]models.RelayerTransactionState{
+				"tx-a": {newState},
+				"tx-b": {newState, newState, confirmed},
+				"tx-c": {newState},
+			},
+			opts:       models.WaitOptions{Interval: 2 * time.Second, Timeout: 5 * time.Second},
+			wantOK:     []bool{false, true, false},
+			wantPolls:  map[string]int{"tx-a": 4, "tx-b": 3, "tx-c": 4},
+			wantSlept:  []time.Duration{2 * time.Second, 2 * time.Second, time.Second},
+			wantFailed: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			relayer := newFakeRelayer(t)
+			script := scriptTransactions(relayer, tt.states)
+			c := newTestClient(t, relayer)
+
+			results, err := c.WaitAll(waitResponses("tx-a", "tx-b", "tx-c"), tt.opts)
+
+			for i, ok := range tt.wantOK {
+				if got := results[i] != nil; got != ok {
+					t.Errorf("result %d = %+v, want success %v", i, results[i], ok)
+				}
+			}
+			var multiErr *errors.MultiWaitError
+			if tt.wantFailed == 0 {
+				if err != nil {
+					t.Fatalf("WaitAll failed: %v", err)
+				}
+			} else if !stderrors.As(err, &multiErr) || multiErr.Failed() != tt.wantFailed {
+				t.Fatalf("error = %v, want a MultiWaitError with %d failures", err, tt.wantFailed)
+			} else {
+				for i, ok := range tt.wantOK {
+					if (multiErr.Errors[i] == nil) != ok {
+						t.Errorf("error %d = %v, want success %v", i, multiErr.Errors[i], ok)
+					}
+				}
+			}
+			if got := script.pollCounts(); !reflect.DeepEqual(got, tt.wantPolls) {
+				t.Errorf("polls = %v, want %v", got, tt.wantPolls)
+			}
+			if got := testClock(c).Slept(); !reflect.DeepEqual(got, tt.wantSlept) {
+				t.Errorf("slept %v, want one shared sleep per round %v", got, tt.wantSlept)
+			}
+		})
+	}
+}
+
+func TestWaitAll_ErrorKinds(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	scriptTransactions(relayer, map[string][]models.RelayerTransactionState{
+		"tx-failed":    {models.STATE_FAILED},
+		"tx-cancelled": {models.STATE_CANCELLED},
+		"tx-hooked":    {models.STATE_CONFIRMED},
+	})
+	c := newTestClient(t, relayer)
+
+	responses := waitResponses("tx-failed", "tx-cancelled", "tx-hooked")
+	hookErr := stderrors.New("deployed Safe does not match")
+	responses[2].SetWaitHook(func(*models.RelayerTransaction) error { return hookErr })
+
+	_, err := c.WaitAll(responses, models.WaitOptions{})
+	var cancelled *errors.CancelledError
+	if !stderrors.As(err, &cancelled) {
+		t.Errorf("error = %v, want it to wrap a CancelledError", err)
+	}
+	if !stderrors.Is(err, hookErr) {
+		t.Errorf("error = %v, want it to wrap the wait hook error", err)
+	}
+	var multiErr *errors.MultiWaitError
+	if !stderrors.As(err, &multiErr) || multiErr.Failed() != 3 {
+		t.Fatalf("error = %v, want a MultiWaitError with 3 failures", err)
+	}
+}
+
+func TestWaitAny(t *testing.T) {
+	tests := []struct {
+		name      string
+		states    map[string][]models.RelayerTransactionState
+		opts      models.WaitOptions
+		want      int
+		wantPolls map[string]int
+	}{
+		{
+			name: "first success stops",
+			states: map[string][]models.RelayerTransactionState{
+				"tx-a": {models.STATE_NEW},
+				"tx-b": {models.STATE_NEW, models.STATE_CONFIRMED},
+				"tx-c": {models.STATE_FAILED},
+			},
+			want:      1,
+			wantPolls: map[string]int{"tx-a": 2, "tx-b": 2, "tx-c": 1},
+		},
+		{
+			name: "all fail",
+			states: map[string][]models.RelayerTransactionState{
+				"tx-a": {models.STATE_FAILED},
+				"tx-b": {models.STATE_NEW, models.STATE_CANCELLED},
+				"tx-c": {models.STATE_NEW},
+			},
+			opts:      models.WaitOptions{MaxPolls: 3},
+			want:      -1,
+			wantPolls: map[string]int{"tx-a": 1, "tx-b": 2, "tx-c": 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			relayer := newFakeRelayer(t)
+			script := scriptTransactions(relayer, tt.states)
+			c := newTestClient(t, relayer)
+
+			index, txn, err := c.WaitAny(waitResponses("tx-a", "tx-b", "tx-c"), tt.opts)
+			if index != tt.want {
+				t.Errorf("WaitAny() index = %d, want %d", index, tt.want)
+			}
+			if tt.want >= 0 {
+				if err != nil || txn == nil || txn.State != models.STATE_CONFIRMED {
+					t.Errorf("WaitAny() = %+v, %v, want a confirmed transaction", txn, err)
+				}
+			} else {
+				var multiErr *errors.MultiWaitError
+				if !stderrors.As(err, &multiErr) || multiErr.Failed() != 3 {
+					t.Errorf("error = %v, want a MultiWaitError with 3 failures", err)
+				}
+			}
+			if got := script.pollCounts(); !reflect.DeepEqual(got, tt.wantPolls) {
+				t.Errorf("polls = %v, want %v", got, tt.wantPolls)
+			}
+		})
+	}
+}
+
+func TestWaitAll_InvalidResponses(t *testing.T) {
+	c := newTestClient(t, newFakeRelayer(t))
+
+	tests := []struct {
+		name      string
+		responses []*models.ClientRelayerTransactionResponse
+	}{
+		{name: "none"},
+		{name: "nil response", responses: []*models.ClientRelayerTransactionResponse{nil}},
+		{name: "no transaction ID", responses: waitResponses("")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := c.WaitAll(tt.responses, models.WaitOptions{}); err == nil {
+				t.Error("WaitAll should reject the responses")
+			}
+			if _, _, err := c.WaitAny(tt.responses, models.WaitOptions{}); err == nil {
+				t.Error("WaitAny should reject the responses")
+			}
+		})
+	}
+}
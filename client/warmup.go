@@ -0,0 +1,137 @@
+package client
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	nethttp "net/http"
+	"time"
+
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/http"
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+const (
+	// defaultWarmUpBudget bounds a warm-up when no budget is configured
+	defaultWarmUpBudget = time.Minute
+
+	// warmUpInitialPatience is how long the first warm-up ping may take and
+	// the wait after it fails; both double per attempt up to warmUpMaxPatience
+	warmUpInitialPatience = time.Second
+	warmUpMaxPatience     = 16 * time.Second
+)
+
+// WithColdStartTolerance makes Deploy and Execute ride out a relayer that is
+// cold-starting, e.g. after scaling to zero. When a call fails with a
+// connection error before the relayer has ever responded to the client, the
+// client runs one WarmUp cycle bounded by budget (one minute if budget is not
+// positive) and then retries the call once. The warm-up happens at most once
+// per client; later failures are returned as usual.
+func WithColdStartTolerance(budget time.Duration) Option {
+	return func(c *RelayClient) error {
+		c.coldStartTolerance = true
+		c.warmUpBudget = budget
+		return nil
+	}
+}
+
+// WarmUp pings a cheap relayer endpoint until the relayer responds. Each ping
+// may take longer than the one before, and the waits between pings grow with
+// it, from one second up to 16s. Any response other than a server error
+// counts. WarmUp gives up with a RelayerUnavailableError once the budget set
+// with WithColdStartTolerance (one minute by default) is spent.
+func (c *RelayClient) WarmUp() error {
+	return c.warmUp(context.Background())
+}
+
+// warmUp is WarmUp bound to ctx
+func (c *RelayClient) warmUp(ctx context.Context) error {
+	budget := c.warmUpBudget
+	if budget <= 0 {
+		budget = defaultWarmUpBudget
+	}
+	start := c.clock.Now()
+	deadline := start.Add(budget)
+
+	patience := warmUpInitialPatience
+	for attempt := 1; ; attempt++ {
+		err := c.ping(ctx, patience)
+		if err == nil {
+			c.logger.Printf("Relayer responded to warm-up attempt %d after %s", attempt, c.clock.Now().Sub(start))
+			return nil
+		}
+		if ctx.Err() != nil {
+			return err
+		}
+
+		remaining := deadline.Sub(c.clock.Now())
+		if remaining <= 0 {
+			return errors.ErrRelayerUnavailable(nil, fmt.Sprintf("no response to %d warm-up attempts within %s", attempt, budget), err)
+		}
+		wait := patience
+		if wait > remaining {
+			wait = remaining
+		}
+		c.logger.Printf("Warm-up attempt %d failed, retrying in %s: %v", attempt, wait, err)
+		c.clock.Sleep(wait)
+
+		if patience *= 2; patience > warmUpMaxPatience {
+			patience = warmUpMaxPatience
+		}
+	}
+}
+
+// ping sends the probe request, allowing it timeout to complete. Only
+// connection errors and server errors count as no response.
+func (c *RelayClient) ping(ctx context.Context, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var nonce models.NonceResponse
+	if err := c.getEndpoint(ctx, probeRequest, &nonce); err != nil && isServerError(err) {
+		return err
+	}
+	return nil
+}
+
+// observeLiveness records that the relayer has responded to the client
+func (c *RelayClient) observeLiveness(*nethttp.Response) {
+	c.warmMu.Lock()
+	c.responded = true
+	c.warmMu.Unlock()
+}
+
+// warmUpOnColdStart runs the client's one warm-up cycle if err, the result of
+// an operation, is a connection error and the relayer has never responded.
+// retry reports that the relayer is now up and the operation should be run
+// again; otherwise err is returned, or the error the warm-up failed with.
+func (c *RelayClient) warmUpOnColdStart(op *operation, err error) (retry bool, _ error) {
+	if !c.coldStartTolerance || err == nil || op.ctx.Err() != nil || !isConnectionError(err) {
+		return false, err
+	}
+
+	c.warmMu.Lock()
+	if c.responded || c.warmedUp {
+		c.warmMu.Unlock()
+		return false, err
+	}
+	c.warmedUp = true
+	c.warmMu.Unlock()
+
+	c.logger.Printf("Relayer unreachable, warming up: %v", err)
+	if warmErr := op.run(stepWarmUp, c.warmUp); warmErr != nil {
+		return false, warmErr
+	}
+	return true, nil
+}
+
+// isConnectionError reports whether err is a failure to reach the relayer,
+// such as a refused connection or a timeout, rather than a relayer response
+func isConnectionError(err error) bool {
+	var apiErr *errors.RelayerApiError
+	if stderrors.As(err, &apiErr) {
+		return false
+	}
+	return http.RetryableError(err)
+}
@@ -0,0 +1,165 @@
+package client
+
+import (
+	"encoding/base64"
+	stderrors "errors"
+	"io"
+	"log"
+	nethttp "net/http"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/davidt58/go-builder-relayer-client/clock"
+	"github.com/davidt58/go-builder-relayer-client/config"
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/http"
+	"github.com/davidt58/go-builder-relayer-client/relayertest"
+)
+
+// coldStartTransport refuses connections until the clock reaches upAt
+type coldStartTransport struct {
+	clock *clock.Fake
+	mu    sync.Mutex
+	upAt  time.Time
+}
+
+func (t *coldStartTransport) RoundTrip(req *nethttp.Request) (*nethttp.Response, error) {
+	t.mu.Lock()
+	up := !t.clock.Now().Before(t.upAt)
+	t.mu.Unlock()
+	if !up {
+		return nil, stderrors.New("dial tcp 127.0.0.1:443: connect: connection refused")
+	}
+	return nethttp.DefaultTransport.RoundTrip(req)
+}
+
+// coldFor makes the transport refuse connections for d from now
+func (t *coldStartTransport) coldFor(d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.upAt = t.clock.Now().Add(d)
+}
+
+// newColdClient creates a client whose relayer refuses connections for the
+// first coldFor of fake time
+func newColdClient(t *testing.T, relayer *fakeRelayer, coldFor time.Duration, opts ...Option) (*RelayClient, *coldStartTransport) {
+	t.Helper()
+	clk := clock.NewAutoFake(testClockStart)
+	transport := &coldStartTransport{clock: clk}
+	transport.coldFor(coldFor)
+
+	secret := base64.URLEncoding.EncodeToString([]byte("test-secret"))
+	opts = append([]Option{WithClock(clk), WithHTTPOptions(http.WithTransport(transport))}, opts...)
+	c, err := NewRelayClient(relayer.URL(), 137, testPrivateKey, config.NewBuilderConfig("test-key", secret, "test-pass"), opts...)
+	if err != nil {
+		t.Fatalf("NewRelayClient failed: %v", err)
+	}
+	c.logger = log.New(io.Discard, "", 0)
+	return c, transport
+}
+
+func TestDeploy_ColdStartWarmsUpOnce(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	relayer.SetDeployed(false)
+	c, transport := newColdClient(t, relayer, 5*time.Second, WithColdStartTolerance(30*time.Second))
+
+	if _, err := c.Deploy(); err != nil {
+		t.Fatalf("Deploy failed: %v", err)
+	}
+	// Pings at 0s, 1s and 3s are refused; the one at 7s gets through
+	if slept := transport.clock.Slept(); !reflect.DeepEqual(slept, []time.Duration{time.Second, 2 * time.Second, 4 * time.Second}) {
+		t.Errorf("slept %v, want one warm-up cycle of 1s, 2s, 4s", slept)
+	}
+	if n := len(relayer.Submissions()); n != 1 {
+		t.Errorf("submitted %d transactions, want 1", n)
+	}
+
+	// The relayer has responded, so a later outage is not warmed up
+	transport.coldFor(time.Hour)
+	if _, err := c.Execute(relayertest.SampleApprovalBatch(), ""); err == nil || !isConnectionError(err) {
+		t.Fatalf("error = %v, want the connection error", err)
+	}
+	if n := len(transport.clock.Slept()); n != 3 {
+		t.Errorf("slept %d times, want no second warm-up", n)
+	}
+}
+
+func TestExecute_ColdStartWarmsUpOnce(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	c, transport := newColdClient(t, relayer, 2*time.Second, WithColdStartTolerance(30*time.Second))
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.Execute(relayertest.SampleApprovalBatch(), ""); err != nil {
+			t.Fatalf("Execute %d failed: %v", i, err)
+		}
+	}
+	if slept := transport.clock.Slept(); !reflect.DeepEqual(slept, []time.Duration{time.Second, 2 * time.Second}) {
+		t.Errorf("slept %v, want one warm-up cycle of 1s, 2s", slept)
+	}
+	if n := len(relayer.Submissions()); n != 2 {
+		t.Errorf("submitted %d transactions, want 2", n)
+	}
+}
+
+func TestExecute_ColdStartWarmUpExhausted(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	c, transport := newColdClient(t, relayer, time.Hour, WithColdStartTolerance(10*time.Second))
+
+	_, err := c.Execute(relayertest.SampleApprovalBatch(), "")
+	var staged *errors.StagedError
+	var unavailable *errors.RelayerUnavailableError
+	if !stderrors.As(err, &staged) || staged.Stage != errors.StageWarmUp || !stderrors.As(err, &unavailable) {
+		t.Fatalf("error = %v, want a warm-up RelayerUnavailableError", err)
+	}
+	// The last wait is cut short by the budget
+	if slept := transport.clock.Slept(); !reflect.DeepEqual(slept, []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 3 * time.Second}) {
+		t.Errorf("slept %v, want 1s, 2s, 4s, 3s", slept)
+	}
+
+	// The warm-up is not repeated on the next call
+	if _, err := c.Execute(relayertest.SampleApprovalBatch(), ""); err == nil || !isConnectionError(err) {
+		t.Fatalf("error = %v, want the connection error", err)
+	}
+	if n := len(transport.clock.Slept()); n != 4 {
+		t.Errorf("slept %d times, want no second warm-up", n)
+	}
+}
+
+func TestDeploy_ColdStartWithoutTolerance(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	relayer.SetDeployed(false)
+	c, transport := newColdClient(t, relayer, 5*time.Second)
+
+	if _, err := c.Deploy(); err == nil || !isConnectionError(err) {
+		t.Fatalf("error = %v, want the connection error", err)
+	}
+	if slept := transport.clock.Slept(); len(slept) != 0 {
+		t.Errorf("slept %v, want no warm-up", slept)
+	}
+}
+
+func TestWarmUp(t *testing.T) {
+	relayer := newFakeRelayer(t)
+	c, transport := newColdClient(t, relayer, 20*time.Second)
+
+	if err := c.WarmUp(); err != nil {
+		t.Fatalf("WarmUp failed: %v", err)
+	}
+	// Pings at 0s, 1s, 3s, 7s and 15s are refused; the one at 31s gets through
+	if slept := transport.clock.Slept(); !reflect.DeepEqual(slept, []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 16 * time.Second}) {
+		t.Errorf("slept %v", slept)
+	}
+
+	// A relayer answering with a client error is up
+	relayer.Handle(GET_NONCE, func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		w.WriteHeader(nethttp.StatusBadRequest)
+	})
+	if err := c.WarmUp(); err != nil {
+		t.Fatalf("WarmUp failed: %v", err)
+	}
+	if n := len(transport.clock.Slept()); n != 5 {
+		t.Errorf("slept %d times, want no waits for a responding relayer", n)
+	}
+}
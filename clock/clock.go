@@ -0,0 +1,33 @@
+// Package clock abstracts the passage of time so that polling, backoff and
+// HMAC timestamps can be driven by a Fake clock in tests
+package clock
+
+import "time"
+
+// Clock tells the time and waits for durations to pass
+type Clock interface {
+	// Now returns the current time
+	Now() time.Time
+	// Sleep blocks until d has passed
+	Sleep(d time.Duration)
+	// After returns a channel that receives the time once d has passed
+	After(d time.Duration) <-chan time.Time
+}
+
+// Real is the system clock
+var Real Clock = realClock{}
+
+// OrReal returns c, or Real when c is nil
+func OrReal(c Clock) Clock {
+	if c == nil {
+		return Real
+	}
+	return c
+}
+
+// realClock delegates to the time package
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) Sleep(d time.Duration)                  { time.Sleep(d) }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
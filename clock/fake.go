@@ -0,0 +1,148 @@
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Fake is a Clock that only moves when told to. Sleep and After wait until
+// Advance or Set moves the clock past their deadline; waiters fire in
+// deadline order, so the sequence is deterministic. With auto-advance on,
+// Sleep moves the clock forward itself and returns immediately.
+type Fake struct {
+	mu          sync.Mutex
+	now         time.Time
+	waiters     []*fakeWaiter
+	autoAdvance bool
+	slept       []time.Duration
+	changed     chan struct{}
+}
+
+// fakeWaiter is a pending Sleep or After
+type fakeWaiter struct {
+	deadline time.Time
+	ch       chan time.Time
+}
+
+// NewFake returns a Fake clock set to start
+func NewFake(start time.Time) *Fake {
+	return &Fake{now: start, changed: make(chan struct{})}
+}
+
+// NewAutoFake returns a Fake clock set to start whose Sleep advances the
+// clock instead of blocking, for code that only needs its waits skipped
+func NewAutoFake(start time.Time) *Fake {
+	f := NewFake(start)
+	f.autoAdvance = true
+	return f
+}
+
+// Now returns the fake time
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// Sleep blocks until the clock has advanced by d, or advances it by d
+// itself when auto-advance is on
+func (f *Fake) Sleep(d time.Duration) {
+	f.mu.Lock()
+	f.slept = append(f.slept, d)
+	if f.autoAdvance {
+		f.mu.Unlock()
+		f.Advance(d)
+		return
+	}
+	f.mu.Unlock()
+	<-f.After(d)
+}
+
+// After returns a channel that receives the fake time once the clock has
+// advanced by d. A non-positive d fires immediately.
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- f.now
+		return ch
+	}
+	f.waiters = append(f.waiters, &fakeWaiter{deadline: f.now.Add(d), ch: ch})
+	f.notify()
+	return ch
+}
+
+// Advance moves the clock forward by d, firing every waiter whose deadline
+// has been reached
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.set(f.now.Add(d))
+}
+
+// Set moves the clock to t, firing every waiter whose deadline has been
+// reached. The clock never moves backwards.
+func (f *Fake) Set(t time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if t.After(f.now) {
+		f.set(t)
+	}
+}
+
+// set moves the clock to t and fires due waiters in deadline order; f.mu must be held
+func (f *Fake) set(t time.Time) {
+	f.now = t
+
+	sort.SliceStable(f.waiters, func(i, j int) bool {
+		return f.waiters[i].deadline.Before(f.waiters[j].deadline)
+	})
+	remaining := f.waiters[:0]
+	for _, w := range f.waiters {
+		if w.deadline.After(t) {
+			remaining = append(remaining, w)
+			continue
+		}
+		w.ch <- w.deadline
+	}
+	f.waiters = remaining
+	f.notify()
+}
+
+// notify wakes BlockUntil callers; f.mu must be held
+func (f *Fake) notify() {
+	close(f.changed)
+	f.changed = make(chan struct{})
+}
+
+// Waiters returns the number of pending Sleep and After calls
+func (f *Fake) Waiters() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.waiters)
+}
+
+// BlockUntil blocks until at least n Sleep or After calls are pending, so a
+// test can advance the clock knowing the code under test is waiting on it
+func (f *Fake) BlockUntil(n int) {
+	for {
+		f.mu.Lock()
+		if len(f.waiters) >= n {
+			f.mu.Unlock()
+			return
+		}
+		changed := f.changed
+		f.mu.Unlock()
+		<-changed
+	}
+}
+
+// Slept returns the durations passed to Sleep, in call order
+func (f *Fake) Slept() []time.Duration {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]time.Duration(nil), f.slept...)
+}
@@ -0,0 +1,136 @@
+package clock
+
+import (
+	"sort"
+	"testing"
+	"time"
+)
+
+var testStart = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func TestFake_After(t *testing.T) {
+	tests := []struct {
+		name      string
+		waits     []time.Duration
+		advance   []time.Duration
+		wantFired []int
+	}{
+		{name: "not yet due", waits: []time.Duration{time.Second}, advance: []time.Duration{999 * time.Millisecond}},
+		{name: "due exactly", waits: []time.Duration{time.Second}, advance: []time.Duration{time.Second}, wantFired: []int{0}},
+		{name: "immediate", waits: []time.Duration{0, -time.Second}, wantFired: []int{0, 1}},
+		{name: "deadline order", waits: []time.Duration{3 * time.Second, time.Second, 2 * time.Second}, advance: []time.Duration{5 * time.Second}, wantFired: []int{1, 2, 0}},
+		{name: "in steps", waits: []time.Duration{3 * time.Second, time.Second}, advance: []time.Duration{2 * time.Second, 2 * time.Second}, wantFired: []int{1, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clk := NewFake(testStart)
+			channels := make([]<-chan time.Time, len(tt.waits))
+			for i, d := range tt.waits {
+				channels[i] = clk.After(d)
+			}
+
+			// collect drains the channels that have fired, in deadline order
+			var fired []int
+			collect := func() {
+				var due []int
+				times := make(map[int]time.Time)
+				for i, ch := range channels {
+					select {
+					case at := <-ch:
+						due = append(due, i)
+						times[i] = at
+					default:
+					}
+				}
+				sort.SliceStable(due, func(a, b int) bool { return times[due[a]].Before(times[due[b]]) })
+				fired = append(fired, due...)
+			}
+
+			collect()
+			for _, d := range tt.advance {
+				clk.Advance(d)
+				collect()
+			}
+
+			if len(fired) != len(tt.wantFired) {
+				t.Fatalf("fired %v, want %v", fired, tt.wantFired)
+			}
+			for i := range fired {
+				if fired[i] != tt.wantFired[i] {
+					t.Fatalf("fired %v, want %v", fired, tt.wantFired)
+				}
+			}
+			if clk.Waiters() != len(tt.waits)-len(tt.wantFired) {
+				t.Errorf("Waiters() = %d, want %d", clk.Waiters(), len(tt.waits)-len(tt.wantFired))
+			}
+		})
+	}
+}
+
+func TestFake_SleepBlocksUntilAdvanced(t *testing.T) {
+	clk := NewFake(testStart)
+	woke := make(chan time.Time)
+	go func() {
+		clk.Sleep(time.Minute)
+		woke <- clk.Now()
+	}()
+
+	clk.BlockUntil(1)
+	clk.Advance(30 * time.Second)
+	select {
+	case <-woke:
+		t.Fatal("Sleep returned before its deadline")
+	default:
+	}
+
+	clk.Advance(30 * time.Second)
+	if at := <-woke; !at.Equal(testStart.Add(time.Minute)) {
+		t.Errorf("woke at %s, want %s", at, testStart.Add(time.Minute))
+	}
+	if slept := clk.Slept(); len(slept) != 1 || slept[0] != time.Minute {
+		t.Errorf("Slept() = %v, want [1m0s]", slept)
+	}
+}
+
+func TestFake_AutoAdvance(t *testing.T) {
+	clk := NewAutoFake(testStart)
+	timer := clk.After(3 * time.Second)
+
+	clk.Sleep(2 * time.Second)
+	clk.Sleep(2 * time.Second)
+
+	if got := clk.Now(); !got.Equal(testStart.Add(4 * time.Second)) {
+		t.Errorf("Now() = %s, want start+4s", got)
+	}
+	select {
+	case at := <-timer:
+		if !at.Equal(testStart.Add(3 * time.Second)) {
+			t.Errorf("After fired with %s, want its deadline", at)
+		}
+	default:
+		t.Error("After did not fire when auto-advance passed its deadline")
+	}
+}
+
+func TestFake_SetNeverMovesBack(t *testing.T) {
+	clk := NewFake(testStart)
+	clk.Set(testStart.Add(-time.Hour))
+	if !clk.Now().Equal(testStart) {
+		t.Errorf("Now() = %s after setting an earlier time, want %s", clk.Now(), testStart)
+	}
+	clk.Set(testStart.Add(time.Hour))
+	if !clk.Now().Equal(testStart.Add(time.Hour)) {
+		t.Errorf("Now() = %s, want start+1h", clk.Now())
+	}
+}
+
+func TestOrReal(t *testing.T) {
+	if OrReal(nil) != Real {
+		t.Error("OrReal(nil) should be Real")
+	}
+	clk := NewFake(testStart)
+	if OrReal(clk) != Clock(clk) {
+		t.Error("OrReal should return a non-nil clock unchanged")
+	}
+}
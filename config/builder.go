@@ -4,11 +4,12 @@ import (
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/base64"
-	"encoding/json"
 	"fmt"
 	"strconv"
+	"sync"
 	"time"
 
+	"github.com/davidt58/go-builder-relayer-client/clock"
 	"github.com/davidt58/go-builder-relayer-client/errors"
 )
 
@@ -20,9 +21,49 @@ type BuilderConfig struct {
 	Secret string
 	// Passphrase is the Builder API passphrase
 	Passphrase string
+
+	// Credentials, when set, replaces APIKey, Secret and Passphrase with a
+	// set of keys ordered oldest to newest. Requests are signed with the
+	// newest credential that has not expired.
+	Credentials []Credential
+
+	// Clock supplies HMAC timestamps and credential expiry checks; nil
+	// means the system clock
+	Clock clock.Clock
+
+	// Environment, when set, is the relayer environment the credentials were
+	// issued for, e.g. EnvStaging. A client fails to construct when the
+	// credentials are pointed at a registered relayer of another environment.
+	Environment Environment
+
+	// keyMu guards the HMAC keys decoded from secrets, keyed by secret
+	keyMu sync.Mutex
+	keys  map[string][]byte
 }
 
-// NewBuilderConfig creates a new BuilderConfig
+// Credential is one Builder API key. During a rotation window the relayer
+// accepts both the old and the new key and tells them apart by KeyID.
+type Credential struct {
+	// APIKey is the Builder API key
+	APIKey string
+	// Secret is the Builder API secret for HMAC signing
+	Secret string
+	// Passphrase is the Builder API passphrase
+	Passphrase string
+	// KeyID is sent as POLY_BUILDER_KEY_ID so the relayer picks the matching secret
+	KeyID string
+	// NotAfter is when the credential expires; zero means it does not
+	NotAfter time.Time
+}
+
+// expired reports whether the credential has expired at t
+func (c Credential) expired(t time.Time) bool {
+	return !c.NotAfter.IsZero() && !t.Before(c.NotAfter)
+}
+
+// NewBuilderConfig creates a new BuilderConfig. The secret is decoded on first
+// use, so an invalid secret is only reported when headers are generated
+// (see NewCheckedBuilderConfig and Precompute).
 func NewBuilderConfig(apiKey, secret, passphrase string) *BuilderConfig {
 	return &BuilderConfig{
 		APIKey:     apiKey,
@@ -31,50 +72,201 @@ func NewBuilderConfig(apiKey, secret, passphrase string) *BuilderConfig {
 	}
 }
 
-// Validate checks if the builder configuration is valid
+// NewCheckedBuilderConfig creates a BuilderConfig, validating it and decoding
+// the secret immediately so bad credentials are reported at construction
+func NewCheckedBuilderConfig(apiKey, secret, passphrase string) (*BuilderConfig, error) {
+	b := NewBuilderConfig(apiKey, secret, passphrase)
+	if err := b.Precompute(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Precompute validates the configuration and decodes the secrets into the
+// cached HMAC keys used by GenerateBuilderHeaders
+func (b *BuilderConfig) Precompute() error {
+	if err := b.Validate(); err != nil {
+		return err
+	}
+	for _, cred := range b.credentials() {
+		if _, err := b.signingKey(cred.Secret); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// signingKey returns the decoded secret, decoding each secret only once
+func (b *BuilderConfig) signingKey(secret string) ([]byte, error) {
+	b.keyMu.Lock()
+	defer b.keyMu.Unlock()
+
+	if key, ok := b.keys[secret]; ok {
+		return key, nil
+	}
+
+	// Decode the secret from URL-safe base64 (matching Python implementation)
+	key, err := base64.URLEncoding.DecodeString(secret)
+	if err != nil {
+		return nil, errors.NewRelayerClientError("failed to decode secret", err)
+	}
+
+	if b.keys == nil {
+		b.keys = make(map[string][]byte)
+	}
+	b.keys[secret] = key
+	return key, nil
+}
+
+// credentials returns Credentials, or the single credential made of APIKey,
+// Secret and Passphrase when Credentials is empty
+func (b *BuilderConfig) credentials() []Credential {
+	if len(b.Credentials) > 0 {
+		return b.Credentials
+	}
+	return []Credential{{APIKey: b.APIKey, Secret: b.Secret, Passphrase: b.Passphrase}}
+}
+
+// activeIndex returns the index of the newest unexpired credential, or of the
+// newest credential when all have expired
+func (b *BuilderConfig) activeIndex(creds []Credential) int {
+	t := clock.OrReal(b.Clock).Now()
+	for i := len(creds) - 1; i >= 0; i-- {
+		if !creds[i].expired(t) {
+			return i
+		}
+	}
+	return len(creds) - 1
+}
+
+// ActiveCredential returns the credential requests are signed with
+func (b *BuilderConfig) ActiveCredential() Credential {
+	creds := b.credentials()
+	return creds[b.activeIndex(creds)]
+}
+
+// PreviousCredential returns the credential before the active one, used to
+// retry a request the relayer rejected because it does not know the new key yet
+func (b *BuilderConfig) PreviousCredential() (Credential, bool) {
+	creds := b.credentials()
+	i := b.activeIndex(creds)
+	if i == 0 {
+		return Credential{}, false
+	}
+	return creds[i-1], true
+}
+
+// Validate checks if the builder configuration is valid. Expired credentials
+// are not an error, since the relayer may still accept them; see
+// AllCredentialsExpired.
 func (b *BuilderConfig) Validate() error {
-	if b.APIKey == "" {
-		return errors.ErrMissingRequiredField("APIKey")
+	if len(b.Credentials) == 0 {
+		return validateCredential("", Credential{APIKey: b.APIKey, Secret: b.Secret, Passphrase: b.Passphrase})
+	}
+
+	for i, cred := range b.Credentials {
+		if err := validateCredential(fmt.Sprintf("Credentials[%d].", i), cred); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AllCredentialsExpired reports whether every entry of Credentials is past
+// its NotAfter. Requests are then signed with the newest one anyway, which
+// the relayer may still accept, so callers should warn rather than fail.
+func (b *BuilderConfig) AllCredentialsExpired() bool {
+	if len(b.Credentials) == 0 {
+		return false
+	}
+	t := clock.OrReal(b.Clock).Now()
+	for _, cred := range b.Credentials {
+		if !cred.expired(t) {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidatePartial reports an error listing the set and missing fields when
+// some but not all of APIKey, Secret and Passphrase (or of the fields of any
+// entry of Credentials) are set. A config with no credentials at all passes;
+// use Validate to require them.
+func (b *BuilderConfig) ValidatePartial() error {
+	if len(b.Credentials) == 0 {
+		return checkPartial(credentialFields(""), b.APIKey, b.Secret, b.Passphrase)
+	}
+	for i, cred := range b.Credentials {
+		if err := checkPartial(credentialFields(fmt.Sprintf("Credentials[%d].", i)), cred.APIKey, cred.Secret, cred.Passphrase); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// credentialFields returns the names of a credential's fields, qualified by prefix
+func credentialFields(prefix string) []string {
+	return []string{prefix + "APIKey", prefix + "Secret", prefix + "Passphrase"}
+}
+
+// checkPartial returns ErrPartialBuilderCredentials when some but not all
+// values are set; names are the matching field or variable names
+func checkPartial(names []string, values ...string) error {
+	var set, missing []string
+	for i, value := range values {
+		if value != "" {
+			set = append(set, names[i])
+		} else {
+			missing = append(missing, names[i])
+		}
 	}
-	if b.Secret == "" {
-		return errors.ErrMissingRequiredField("Secret")
+	if len(set) > 0 && len(missing) > 0 {
+		return errors.ErrPartialBuilderCredentials(set, missing)
 	}
-	if b.Passphrase == "" {
-		return errors.ErrMissingRequiredField("Passphrase")
+	return nil
+}
+
+// validateCredential checks that a credential has all its fields; prefix
+// qualifies the field names in the error
+func validateCredential(prefix string, cred Credential) error {
+	if err := checkPartial(credentialFields(prefix), cred.APIKey, cred.Secret, cred.Passphrase); err != nil {
+		return err
+	}
+	if cred.APIKey == "" {
+		return errors.ErrMissingRequiredField(prefix + "APIKey")
 	}
 	return nil
 }
 
 // GenerateBuilderHeaders creates the authentication headers for Builder API requests
-// This implements HMAC-SHA256 signature as per Builder API authentication requirements
-func (b *BuilderConfig) GenerateBuilderHeaders(method, requestPath string, body interface{}) (map[string]string, error) {
+// This implements HMAC-SHA256 signature as per Builder API authentication requirements.
+// body is the exact request body sent, nil or empty for a request without
+// one, which signs the empty string as the relayer does.
+func (b *BuilderConfig) GenerateBuilderHeaders(method, requestPath string, body []byte) (map[string]string, error) {
 	if err := b.Validate(); err != nil {
 		return nil, err
 	}
+	return b.GenerateHeadersWith(b.ActiveCredential(), method, requestPath, body)
+}
+
+// GenerateHeadersWith creates the authentication headers signed with a
+// specific credential, adding POLY_BUILDER_KEY_ID when it has a KeyID. body
+// is signed as for GenerateBuilderHeaders.
+func (b *BuilderConfig) GenerateHeadersWith(cred Credential, method, requestPath string, body []byte) (map[string]string, error) {
+	if err := validateCredential("", cred); err != nil {
+		return nil, err
+	}
 
 	// Generate timestamp
-	timestamp := time.Now().Unix()
+	timestamp := clock.OrReal(b.Clock).Now().Unix()
 	timestampStr := strconv.FormatInt(timestamp, 10)
 
-	// Prepare body string
-	var bodyStr string
-	if body != nil {
-		bodyBytes, err := json.Marshal(body)
-		if err != nil {
-			return nil, errors.ErrJSONMarshalFailed(err)
-		}
-		bodyStr = string(bodyBytes)
-	} else {
-		bodyStr = ""
-	}
-
 	// Create signature message: timestamp + method + requestPath + body
-	message := fmt.Sprintf("%s%s%s%s", timestampStr, method, requestPath, bodyStr)
+	message := fmt.Sprintf("%s%s%s%s", timestampStr, method, requestPath, body)
 
-	// Decode the secret from URL-safe base64 (matching Python implementation)
-	secretBytes, err := base64.URLEncoding.DecodeString(b.Secret)
+	secretBytes, err := b.signingKey(cred.Secret)
 	if err != nil {
-		return nil, errors.NewRelayerClientError("failed to decode secret", err)
+		return nil, err
 	}
 
 	// Generate HMAC-SHA256 signature
@@ -85,20 +277,24 @@ func (b *BuilderConfig) GenerateBuilderHeaders(method, requestPath string, body
 
 	// Return headers (note: underscores, not hyphens, and BUILDER not just API)
 	headers := map[string]string{
-		"POLY_BUILDER_API_KEY":    b.APIKey,
+		"POLY_BUILDER_API_KEY":    cred.APIKey,
 		"POLY_BUILDER_SIGNATURE":  signature,
 		"POLY_BUILDER_TIMESTAMP":  timestampStr,
-		"POLY_BUILDER_PASSPHRASE": b.Passphrase,
+		"POLY_BUILDER_PASSPHRASE": cred.Passphrase,
 		"Content-Type":             "application/json",
 	}
+	if cred.KeyID != "" {
+		headers["POLY_BUILDER_KEY_ID"] = cred.KeyID
+	}
 
 	return headers, nil
 }
 
 // String returns a string representation (without exposing secrets)
 func (b *BuilderConfig) String() string {
+	cred := b.ActiveCredential()
 	return fmt.Sprintf("BuilderConfig{APIKey: %s..., Passphrase: %s...}",
-		truncate(b.APIKey, 8), truncate(b.Passphrase, 8))
+		truncate(cred.APIKey, 8), truncate(cred.Passphrase, 8))
 }
 
 // truncate helper function to safely display partial values
@@ -1,8 +1,15 @@
 package config
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
 	"encoding/base64"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/davidt58/go-builder-relayer-client/clock"
 )
 
 func TestNewBuilderConfig(t *testing.T) {
@@ -64,12 +71,49 @@ func TestBuilderConfig_Validate(t *testing.T) {
 	}
 }
 
+func TestBuilderConfig_ValidatePartial(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  *BuilderConfig
+		wantErr string
+	}{
+		{"no credentials", NewBuilderConfig("", "", ""), ""},
+		{"complete", NewBuilderConfig("key", "secret", "pass"), ""},
+		{"only API key", NewBuilderConfig("key", "", ""), "APIKey set but Secret, Passphrase missing"},
+		{"missing passphrase", NewBuilderConfig("key", "secret", ""), "APIKey, Secret set but Passphrase missing"},
+		{
+			"partial rotation credential",
+			&BuilderConfig{Credentials: []Credential{{APIKey: "old", Secret: "s", Passphrase: "p"}, {APIKey: "new"}}},
+			"Credentials[1].APIKey set but Credentials[1].Secret, Credentials[1].Passphrase missing",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.ValidatePartial()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ValidatePartial() = %v, want an error containing %q", err, tt.wantErr)
+			}
+			// Validate reports the same error
+			if validateErr := tt.config.Validate(); validateErr == nil || validateErr.Error() != err.Error() {
+				t.Errorf("Validate() = %v, want %v", validateErr, err)
+			}
+		})
+	}
+}
+
 func TestBuilderConfig_GenerateBuilderHeaders(t *testing.T) {
 	// Use a valid URL-safe base64 encoded secret (matching Python implementation)
 	secret := base64.URLEncoding.EncodeToString([]byte("test-secret-key"))
 	config := NewBuilderConfig("test-key", secret, "test-pass")
 
-	headers, err := config.GenerateBuilderHeaders("POST", "/api/v1/test", map[string]string{"test": "data"})
+	headers, err := config.GenerateBuilderHeaders("POST", "/api/v1/test", []byte(`{"test":"data"}`))
 	if err != nil {
 		t.Fatalf("GenerateBuilderHeaders failed: %v", err)
 	}
@@ -93,3 +137,229 @@ func TestBuilderConfig_GenerateBuilderHeaders(t *testing.T) {
 		t.Errorf("Content-Type = %s, want application/json", headers["Content-Type"])
 	}
 }
+
+// The body is the bytes sent, so no "empty" value (an empty struct or map
+// marshalling to "{}") can be signed in place of no body
+var _ func(method, requestPath string, body []byte) (map[string]string, error) = (&BuilderConfig{}).GenerateBuilderHeaders
+
+func TestBuilderConfig_EmptyBodySignsEmptyString(t *testing.T) {
+	secret := []byte("test-secret-key")
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	config := NewBuilderConfig("test-key", base64.URLEncoding.EncodeToString(secret), "test-pass")
+	config.Clock = clock.NewFake(start)
+
+	// What the relayer computes for a request without a body
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(strconv.FormatInt(start.Unix(), 10) + "GET/transactions"))
+	want := base64.URLEncoding.EncodeToString(mac.Sum(nil))
+
+	for name, body := range map[string][]byte{"nil": nil, "empty": {}} {
+		headers, err := config.GenerateBuilderHeaders("GET", "/transactions", body)
+		if err != nil {
+			t.Fatalf("%s: GenerateBuilderHeaders failed: %v", name, err)
+		}
+		if headers["POLY_BUILDER_SIGNATURE"] != want {
+			t.Errorf("%s body signature = %s, want %s", name, headers["POLY_BUILDER_SIGNATURE"], want)
+		}
+	}
+
+	// A body is signed byte for byte
+	headers, err := config.GenerateBuilderHeaders("POST", "/submit", []byte(`{}`))
+	if err != nil {
+		t.Fatalf("GenerateBuilderHeaders failed: %v", err)
+	}
+	mac = hmac.New(sha256.New, secret)
+	mac.Write([]byte(strconv.FormatInt(start.Unix(), 10) + "POST/submit{}"))
+	if want := base64.URLEncoding.EncodeToString(mac.Sum(nil)); headers["POLY_BUILDER_SIGNATURE"] != want {
+		t.Errorf("body signature = %s, want %s", headers["POLY_BUILDER_SIGNATURE"], want)
+	}
+}
+
+func TestNewCheckedBuilderConfig(t *testing.T) {
+	validSecret := base64.URLEncoding.EncodeToString([]byte("test-secret-key"))
+
+	tests := []struct {
+		name      string
+		secret    string
+		apiKey    string
+		shouldErr bool
+	}{
+		{"valid", validSecret, "key", false},
+		{"invalid base64 secret", "not base64!", "key", true},
+		{"missing api key", validSecret, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewCheckedBuilderConfig(tt.apiKey, tt.secret, "pass")
+			if tt.shouldErr && err == nil {
+				t.Error("Expected error but got none")
+			}
+			if !tt.shouldErr && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestBuilderConfig_SigningKeyFollowsSecret(t *testing.T) {
+	config := NewBuilderConfig("key", base64.URLEncoding.EncodeToString([]byte("first")), "pass")
+	if err := config.Precompute(); err != nil {
+		t.Fatalf("Precompute failed: %v", err)
+	}
+
+	config.Secret = "not base64!"
+	if _, err := config.GenerateBuilderHeaders("GET", "/", nil); err == nil {
+		t.Error("expected the changed secret to be decoded again and rejected")
+	}
+}
+
+func testCredential(keyID string, notAfter time.Time) Credential {
+	return Credential{
+		APIKey:     "key-" + keyID,
+		Secret:     base64.URLEncoding.EncodeToString([]byte("secret-" + keyID)),
+		Passphrase: "pass-" + keyID,
+		KeyID:      keyID,
+		NotAfter:   notAfter,
+	}
+}
+
+func TestBuilderConfig_Credentials(t *testing.T) {
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	future := start.Add(time.Hour)
+	past := start.Add(-time.Hour)
+
+	tests := []struct {
+		name           string
+		credentials    []Credential
+		wantActive     string
+		wantPrevious   string
+		wantAllExpired bool
+		shouldErr      bool
+	}{
+		{
+			name:        "single credential",
+			credentials: []Credential{testCredential("a", time.Time{})},
+			wantActive:  "a",
+		},
+		{
+			name:         "newest signs",
+			credentials:  []Credential{testCredential("a", future), testCredential("b", time.Time{})},
+			wantActive:   "b",
+			wantPrevious: "a",
+		},
+		{
+			name:         "newest expired",
+			credentials:  []Credential{testCredential("a", time.Time{}), testCredential("b", future), testCredential("c", past)},
+			wantActive:   "b",
+			wantPrevious: "a",
+		},
+		{
+			name:           "all expired",
+			credentials:    []Credential{testCredential("a", past), testCredential("b", past)},
+			wantActive:     "b",
+			wantPrevious:   "a",
+			wantAllExpired: true,
+		},
+		{
+			name:        "missing secret",
+			credentials: []Credential{testCredential("a", time.Time{}), {APIKey: "key", Passphrase: "pass", KeyID: "b"}},
+			shouldErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &BuilderConfig{Credentials: tt.credentials, Clock: clock.NewFake(start)}
+			err := config.Precompute()
+			if tt.shouldErr {
+				if err == nil || !strings.Contains(err.Error(), "Credentials[1].Secret") {
+					t.Errorf("error = %v, want missing Credentials[1].Secret", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Precompute failed: %v", err)
+			}
+			if got := config.AllCredentialsExpired(); got != tt.wantAllExpired {
+				t.Errorf("AllCredentialsExpired = %v, want %v", got, tt.wantAllExpired)
+			}
+
+			if got := config.ActiveCredential().KeyID; got != tt.wantActive {
+				t.Errorf("active = %s, want %s", got, tt.wantActive)
+			}
+			previous, ok := config.PreviousCredential()
+			if ok != (tt.wantPrevious != "") || previous.KeyID != tt.wantPrevious {
+				t.Errorf("previous = %q (%v), want %q", previous.KeyID, ok, tt.wantPrevious)
+			}
+
+			headers, err := config.GenerateBuilderHeaders("POST", "/submit", nil)
+			if err != nil {
+				t.Fatalf("GenerateBuilderHeaders failed: %v", err)
+			}
+			if headers["POLY_BUILDER_KEY_ID"] != tt.wantActive || headers["POLY_BUILDER_API_KEY"] != "key-"+tt.wantActive {
+				t.Errorf("headers = %v, want key %s", headers, tt.wantActive)
+			}
+		})
+	}
+}
+
+func TestBuilderConfig_Clock(t *testing.T) {
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	clk := clock.NewFake(start)
+	config := &BuilderConfig{
+		Credentials: []Credential{testCredential("old", time.Time{}), testCredential("new", start.Add(time.Minute))},
+		Clock:       clk,
+	}
+
+	first, err := config.GenerateBuilderHeaders("POST", "/submit", []byte(`{"a":"b"}`))
+	if err != nil {
+		t.Fatalf("GenerateBuilderHeaders failed: %v", err)
+	}
+	if want := strconv.FormatInt(start.Unix(), 10); first["POLY_BUILDER_TIMESTAMP"] != want {
+		t.Errorf("timestamp = %s, want %s", first["POLY_BUILDER_TIMESTAMP"], want)
+	}
+	again, err := config.GenerateBuilderHeaders("POST", "/submit", []byte(`{"a":"b"}`))
+	if err != nil {
+		t.Fatalf("GenerateBuilderHeaders failed: %v", err)
+	}
+	if again["POLY_BUILDER_SIGNATURE"] != first["POLY_BUILDER_SIGNATURE"] {
+		t.Error("signature changed without the clock moving")
+	}
+
+	// The new key expires after a minute and signing falls back to the old one
+	clk.Advance(time.Minute)
+	later, err := config.GenerateBuilderHeaders("POST", "/submit", []byte(`{"a":"b"}`))
+	if err != nil {
+		t.Fatalf("GenerateBuilderHeaders failed: %v", err)
+	}
+	if want := strconv.FormatInt(start.Add(time.Minute).Unix(), 10); later["POLY_BUILDER_TIMESTAMP"] != want {
+		t.Errorf("timestamp = %s, want %s", later["POLY_BUILDER_TIMESTAMP"], want)
+	}
+	if later["POLY_BUILDER_KEY_ID"] != "old" {
+		t.Errorf("key ID = %s after the new key expired, want old", later["POLY_BUILDER_KEY_ID"])
+	}
+}
+
+func TestBuilderConfig_NoKeyIDHeader(t *testing.T) {
+	config := NewBuilderConfig("key", base64.URLEncoding.EncodeToString([]byte("secret")), "pass")
+	headers, err := config.GenerateBuilderHeaders("GET", "/", nil)
+	if err != nil {
+		t.Fatalf("GenerateBuilderHeaders failed: %v", err)
+	}
+	if _, ok := headers["POLY_BUILDER_KEY_ID"]; ok {
+		t.Error("POLY_BUILDER_KEY_ID sent without a KeyID")
+	}
+}
+
+func BenchmarkGenerateBuilderHeaders(b *testing.B) {
+	config := NewBuilderConfig("test-key", base64.URLEncoding.EncodeToString([]byte("test-secret-key")), "test-pass")
+	body := []byte(`{"test":"data"}`)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := config.GenerateBuilderHeaders("POST", "/submit", body); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -2,8 +2,13 @@ package config
 
 import (
 	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+	"sync/atomic"
 
 	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/signer"
 )
 
 // ContractConfig holds the contract addresses for a specific chain
@@ -16,8 +21,19 @@ type ContractConfig struct {
 	SafeFallbackHandler string
 	// SafeMultisend is the Safe MultiSend contract address
 	SafeMultisend string
+	// SafeMultisendCallOnly is the Safe MultiSendCallOnly contract address,
+	// which rejects DelegateCall subcalls (optional)
+	SafeMultisendCallOnly string
 	// ChainID is the blockchain chain ID
 	ChainID int64
+	// SignatureScheme is how the relayer deployment expects SAFE transactions
+	// to be signed; empty means signer.DefaultSignatureScheme
+	SignatureScheme signer.SignatureScheme
+	// SaltStrategy is how the factory computes the CREATE2 salt; empty means
+	// SaltInitializerHash
+	SaltStrategy SaltStrategy
+	// SaltNonce is the salt nonce used by SaltInitializerHashNonce; nil means 0
+	SaltNonce *big.Int
 }
 
 // Polygon Amoy testnet (chainId: 80002) contract addresses
@@ -31,39 +47,76 @@ var polygonAmoyConfig = &ContractConfig{
 
 // Polygon mainnet (chainId: 137) contract addresses
 var polygonMainnetConfig = &ContractConfig{
-	ChainID:             137,
-	SafeFactory:         "0xaacFeEa03eb1561C4e67d661e40682Bd20E3541b",
-	SafeSingleton:       "0x3E5c63644E683549055b9Be8653de26E0B4CD36E",
-	SafeFallbackHandler: "0xf48f2B2d2a534e402487b3ee7C18c33Aec0Fe5e4",
-	SafeMultisend:       "0xA238CBeb142c10Ef7Ad8442C6D1f9E89e07e7761",
+	ChainID:               137,
+	SafeFactory:           "0xaacFeEa03eb1561C4e67d661e40682Bd20E3541b",
+	SafeSingleton:         "0x3E5c63644E683549055b9Be8653de26E0B4CD36E",
+	SafeFallbackHandler:   "0xf48f2B2d2a534e402487b3ee7C18c33Aec0Fe5e4",
+	SafeMultisend:         "0xA238CBeb142c10Ef7Ad8442C6D1f9E89e07e7761",
+	SafeMultisendCallOnly: "0x40A2aCCbd92BCA938b02010E17A5b8929b49130D",
 }
 
-// chainConfigs maps chain IDs to their contract configurations
-var chainConfigs = map[int64]*ContractConfig{
-	80002: polygonAmoyConfig,
-	137:   polygonMainnetConfig,
-}
+// chainConfigs maps chain IDs to their contract configurations;
+// configVersion counts the changes made to it
+var (
+	chainConfigsMu sync.RWMutex
+	chainConfigs   = map[int64]*ContractConfig{
+		80002: polygonAmoyConfig,
+		137:   polygonMainnetConfig,
+	}
+	configVersion uint64
+)
 
 // GetContractConfig returns the contract configuration for a given chain ID
 func GetContractConfig(chainID int64) (*ContractConfig, error) {
+	config, _, err := GetContractConfigVersion(chainID)
+	return config, err
+}
+
+// GetContractConfigVersion returns the contract configuration for a given
+// chain ID and the ConfigVersion it was read at
+func GetContractConfigVersion(chainID int64) (*ContractConfig, uint64, error) {
+	chainConfigsMu.RLock()
+	defer chainConfigsMu.RUnlock()
+
 	config, exists := chainConfigs[chainID]
 	if !exists {
-		return nil, errors.ErrInvalidChainID(chainID)
+		return nil, 0, errors.ErrUnsupportedChain(chainID, supportedChainIDsLocked())
 	}
-	return config, nil
+	return config, atomic.LoadUint64(&configVersion), nil
 }
 
-// AddChainConfig adds or updates a contract configuration for a chain ID
+// AddChainConfig adds or updates a contract configuration for a chain ID.
+// Clients pick up the change before their next request (see ConfigVersion).
+// Pass a new ContractConfig rather than modifying a registered one in place.
 func AddChainConfig(config *ContractConfig) {
+	chainConfigsMu.Lock()
+	defer chainConfigsMu.Unlock()
+
 	chainConfigs[config.ChainID] = config
+	atomic.AddUint64(&configVersion, 1)
+}
+
+// ConfigVersion returns the version of the contract configuration registry,
+// which increases with every AddChainConfig. It is a single atomic load, so
+// clients check it before every request.
+func ConfigVersion() uint64 {
+	return atomic.LoadUint64(&configVersion)
 }
 
-// GetSupportedChainIDs returns a list of all supported chain IDs
+// GetSupportedChainIDs returns the supported chain IDs in ascending order
 func GetSupportedChainIDs() []int64 {
+	chainConfigsMu.RLock()
+	defer chainConfigsMu.RUnlock()
+	return supportedChainIDsLocked()
+}
+
+// supportedChainIDsLocked is GetSupportedChainIDs with chainConfigsMu held
+func supportedChainIDsLocked() []int64 {
 	chainIDs := make([]int64, 0, len(chainConfigs))
 	for chainID := range chainConfigs {
 		chainIDs = append(chainIDs, chainID)
 	}
+	sort.Slice(chainIDs, func(i, j int) bool { return chainIDs[i] < chainIDs[j] })
 	return chainIDs
 }
 
@@ -84,7 +137,31 @@ func (c *ContractConfig) Validate() error {
 	if c.ChainID <= 0 {
 		return errors.ErrInvalidConfiguration("chain ID must be positive")
 	}
-	return nil
+	if err := c.SignatureScheme.Validate(); err != nil {
+		return err
+	}
+	if c.SaltNonce != nil && c.SaltNonce.Sign() < 0 {
+		return errors.ErrInvalidConfiguration("salt nonce must not be negative")
+	}
+	return c.SaltStrategy.Validate()
+}
+
+// MultisendAddress returns the MultiSend contract address for variant
+func (c *ContractConfig) MultisendAddress(variant MultisendVariant) (string, error) {
+	switch variant.OrDefault() {
+	case MultisendStandard:
+		if c.SafeMultisend == "" {
+			return "", errors.ErrMissingRequiredField("SafeMultisend")
+		}
+		return c.SafeMultisend, nil
+	case MultisendCallOnly:
+		if c.SafeMultisendCallOnly == "" {
+			return "", errors.ErrInvalidConfiguration(fmt.Sprintf("no MultiSendCallOnly address configured for chain %d", c.ChainID))
+		}
+		return c.SafeMultisendCallOnly, nil
+	default:
+		return "", variant.Validate()
+	}
 }
 
 // String returns a string representation of the contract configuration
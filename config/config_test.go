@@ -1,7 +1,12 @@
 package config
 
 import (
+	stderrors "errors"
+	"math/big"
+	"sort"
 	"testing"
+
+	"github.com/davidt58/go-builder-relayer-client/errors"
 )
 
 func TestGetContractConfig(t *testing.T) {
@@ -75,6 +80,43 @@ func TestContractConfig_Validate(t *testing.T) {
 			},
 			shouldErr: true,
 		},
+		{
+			name: "chain ID salt strategy",
+			config: &ContractConfig{
+				ChainID:             80002,
+				SafeFactory:         "0x123",
+				SafeSingleton:       "0x456",
+				SafeFallbackHandler: "0x789",
+				SafeMultisend:       "0xabc",
+				SaltStrategy:        SaltInitializerHashChainID,
+			},
+			shouldErr: false,
+		},
+		{
+			name: "unknown salt strategy",
+			config: &ContractConfig{
+				ChainID:             80002,
+				SafeFactory:         "0x123",
+				SafeSingleton:       "0x456",
+				SafeFallbackHandler: "0x789",
+				SafeMultisend:       "0xabc",
+				SaltStrategy:        "owner_only",
+			},
+			shouldErr: true,
+		},
+		{
+			name: "negative salt nonce",
+			config: &ContractConfig{
+				ChainID:             80002,
+				SafeFactory:         "0x123",
+				SafeSingleton:       "0x456",
+				SafeFallbackHandler: "0x789",
+				SafeMultisend:       "0xabc",
+				SaltStrategy:        SaltInitializerHashNonce,
+				SaltNonce:           big.NewInt(-1),
+			},
+			shouldErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -95,4 +137,147 @@ func TestGetSupportedChainIDs(t *testing.T) {
 	if len(chainIDs) < 2 {
 		t.Errorf("Expected at least 2 supported chains, got %d", len(chainIDs))
 	}
+	if !sort.SliceIsSorted(chainIDs, func(i, j int) bool { return chainIDs[i] < chainIDs[j] }) {
+		t.Errorf("GetSupportedChainIDs() = %v, want ascending order", chainIDs)
+	}
+}
+
+func TestGetContractConfig_UnsupportedChain(t *testing.T) {
+	_, err := GetContractConfig(1)
+
+	var chainErr *errors.UnsupportedChainError
+	if !stderrors.As(err, &chainErr) {
+		t.Fatalf("error = %v, want an UnsupportedChainError", err)
+	}
+	if chainErr.ChainID != 1 {
+		t.Errorf("ChainID = %d, want 1", chainErr.ChainID)
+	}
+	supported := GetSupportedChainIDs()
+	if len(chainErr.SupportedChains) != len(supported) {
+		t.Fatalf("SupportedChains = %v, want %v", chainErr.SupportedChains, supported)
+	}
+	for i := range supported {
+		if chainErr.SupportedChains[i] != supported[i] {
+			t.Errorf("SupportedChains = %v, want %v", chainErr.SupportedChains, supported)
+			break
+		}
+	}
+}
+
+func TestContractConfig_MultisendAddress(t *testing.T) {
+	polygon, _ := GetContractConfig(137)
+	amoy, _ := GetContractConfig(80002)
+
+	tests := []struct {
+		name      string
+		config    *ContractConfig
+		variant   MultisendVariant
+		want      string
+		shouldErr bool
+	}{
+		{"unset variant", polygon, "", polygon.SafeMultisend, false},
+		{"standard", polygon, MultisendStandard, polygon.SafeMultisend, false},
+		{"call-only", polygon, MultisendCallOnly, "0x40A2aCCbd92BCA938b02010E17A5b8929b49130D", false},
+		{"call-only not configured", amoy, MultisendCallOnly, "", true},
+		{"unknown variant", polygon, "delegate_only", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.config.MultisendAddress(tt.variant)
+			if (err != nil) != tt.shouldErr {
+				t.Fatalf("MultisendAddress() error = %v, shouldErr %v", err, tt.shouldErr)
+			}
+			if got != tt.want {
+				t.Errorf("MultisendAddress() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMultisendVariantOf(t *testing.T) {
+	polygon, _ := GetContractConfig(137)
+
+	tests := []struct {
+		name    string
+		address string
+		want    MultisendVariant
+	}{
+		{"standard multisend", polygon.SafeMultisend, MultisendStandard},
+		{"call-only multisend", polygon.SafeMultisendCallOnly, MultisendCallOnly},
+		{"call-only lowercase", "0x40a2accbd92bca938b02010e17a5b8929b49130d", MultisendCallOnly},
+		{"unknown address", "0x1234567890123456789012345678901234567890", MultisendStandard},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MultisendVariantOf(tt.address); got != tt.want {
+				t.Errorf("MultisendVariantOf(%s) = %s, want %s", tt.address, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSaltStrategy_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		strategy  SaltStrategy
+		want      SaltStrategy
+		shouldErr bool
+	}{
+		{"unset", "", SaltInitializerHash, false},
+		{"initializer hash", SaltInitializerHash, SaltInitializerHash, false},
+		{"chain ID", SaltInitializerHashChainID, SaltInitializerHashChainID, false},
+		{"salt nonce", SaltInitializerHashNonce, SaltInitializerHashNonce, false},
+		{"unknown", "owner_only", "owner_only", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.strategy.Validate()
+			if (err != nil) != tt.shouldErr {
+				t.Fatalf("Validate() error = %v, shouldErr %v", err, tt.shouldErr)
+			}
+			if got := tt.strategy.OrDefault(); got != tt.want {
+				t.Errorf("OrDefault() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegisteredChains_DefaultSaltStrategy(t *testing.T) {
+	// Changing the strategy would change every Polymarket Safe address
+	for _, chainID := range []int64{137, 80002} {
+		config, err := GetContractConfig(chainID)
+		if err != nil {
+			t.Fatalf("GetContractConfig(%d) failed: %v", chainID, err)
+		}
+		if got := config.SaltStrategy.OrDefault(); got != SaltInitializerHash {
+			t.Errorf("chain %d salt strategy = %q, want %q", chainID, got, SaltInitializerHash)
+		}
+	}
+}
+
+func TestAddChainConfig_Version(t *testing.T) {
+	original, err := GetContractConfig(137)
+	if err != nil {
+		t.Fatalf("GetContractConfig failed: %v", err)
+	}
+	t.Cleanup(func() { AddChainConfig(original) })
+
+	before := ConfigVersion()
+	updated := *original
+	updated.SafeMultisend = "0x9641d764fc13c8B624c04430C7356C1C7C8102e2"
+	AddChainConfig(&updated)
+
+	got, version, err := GetContractConfigVersion(137)
+	if err != nil {
+		t.Fatalf("GetContractConfigVersion failed: %v", err)
+	}
+	if version != before+1 || ConfigVersion() != version {
+		t.Errorf("version = %d (ConfigVersion %d), want %d", version, ConfigVersion(), before+1)
+	}
+	if got.SafeMultisend != updated.SafeMultisend {
+		t.Errorf("SafeMultisend = %s, want %s", got.SafeMultisend, updated.SafeMultisend)
+	}
 }
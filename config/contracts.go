@@ -0,0 +1,171 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/davidt58/go-builder-relayer-client/errors"
+)
+
+// KnownContracts is the address book of Polymarket contracts for a chain
+type KnownContracts struct {
+	// ChainID is the blockchain chain ID
+	ChainID int64
+	// USDC is the collateral token (USDC.e on Polygon)
+	USDC string
+	// CTFExchange is the CTF Exchange contract
+	CTFExchange string
+	// NegRiskCTFExchange is the NegRisk CTF Exchange contract
+	NegRiskCTFExchange string
+	// NegRiskAdapter is the NegRisk Adapter contract
+	NegRiskAdapter string
+	// ConditionalTokens is the Gnosis Conditional Tokens Framework contract
+	ConditionalTokens string
+	// Extra holds additional named contracts
+	Extra map[string]string
+}
+
+// Polygon mainnet (chainId: 137) Polymarket contracts
+var polygonMainnetContracts = &KnownContracts{
+	ChainID:            137,
+	USDC:               "0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174",
+	CTFExchange:        "0x4bFb41d5B3570DeFd03C39a9A4D8dE6Bd8B8982E",
+	NegRiskCTFExchange: "0xC5d563A36AE78145C45a50134d48A1215220f80a",
+	NegRiskAdapter:     "0xd91E80cF2E7be2e162c6513ceD06f1dD0dA35296",
+	ConditionalTokens:  "0x4D97DCd97eC945f40cF65F87097ACe5EA0476045",
+}
+
+// Polygon Amoy testnet (chainId: 80002) Polymarket contracts
+var polygonAmoyContracts = &KnownContracts{
+	ChainID:            80002,
+	USDC:               "0x9c4E1703476E875070EE25b56A58B008CFb8FA78",
+	CTFExchange:        "0xdFE02Eb6733538f8Ea35D585af8DE5958AD99E40",
+	NegRiskCTFExchange: "0xC5d563A36AE78145C45a50134d48A1215220f80a",
+	NegRiskAdapter:     "0xd91E80cF2E7be2e162c6513ceD06f1dD0dA35296",
+	ConditionalTokens:  "0x69308FB512518e39F9b16112fA8d994F4e2Bf8bB",
+}
+
+// knownContracts maps chain IDs to their Polymarket address books
+var (
+	knownContractsMu sync.RWMutex
+	knownContracts   = map[int64]*KnownContracts{
+		137:   polygonMainnetContracts,
+		80002: polygonAmoyContracts,
+	}
+)
+
+// GetKnownContracts returns a copy of the Polymarket address book for a
+// given chain ID; changing it does not affect other callers
+func GetKnownContracts(chainID int64) (*KnownContracts, error) {
+	knownContractsMu.RLock()
+	defer knownContractsMu.RUnlock()
+
+	contracts, exists := knownContracts[chainID]
+	if !exists {
+		return nil, errors.ErrInvalidChainID(chainID)
+	}
+	return contracts.clone(), nil
+}
+
+// AddKnownContracts adds or updates the address book for a chain ID.
+// Every address is validated (including its EIP-55 checksum) before registration.
+func AddKnownContracts(contracts *KnownContracts) error {
+	if contracts == nil {
+		return errors.ErrMissingRequiredField("contracts")
+	}
+	if err := contracts.Validate(); err != nil {
+		return err
+	}
+
+	knownContractsMu.Lock()
+	defer knownContractsMu.Unlock()
+	knownContracts[contracts.ChainID] = contracts.clone()
+	return nil
+}
+
+// clone returns a deep copy of k
+func (k *KnownContracts) clone() *KnownContracts {
+	clone := *k
+	if k.Extra != nil {
+		clone.Extra = make(map[string]string, len(k.Extra))
+		for name, address := range k.Extra {
+			clone.Extra[name] = address
+		}
+	}
+	return &clone
+}
+
+// Validate checks that the chain ID is positive, the core contracts are set,
+// and every address is a valid, correctly checksummed address
+func (k *KnownContracts) Validate() error {
+	if k.ChainID <= 0 {
+		return errors.ErrInvalidConfiguration("chain ID must be positive")
+	}
+
+	fields := []struct {
+		name    string
+		address string
+	}{
+		{"USDC", k.USDC},
+		{"CTFExchange", k.CTFExchange},
+		{"NegRiskCTFExchange", k.NegRiskCTFExchange},
+		{"NegRiskAdapter", k.NegRiskAdapter},
+		{"ConditionalTokens", k.ConditionalTokens},
+	}
+	for _, field := range fields {
+		if field.address == "" {
+			return errors.ErrMissingRequiredField(field.name)
+		}
+		if err := validateChecksumAddress(field.name, field.address); err != nil {
+			return err
+		}
+	}
+
+	for name, address := range k.Extra {
+		if err := validateChecksumAddress(name, address); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Get returns a contract address by name, checking the named fields first
+// and then Extra
+func (k *KnownContracts) Get(name string) (string, bool) {
+	switch name {
+	case "USDC":
+		return k.USDC, true
+	case "CTFExchange":
+		return k.CTFExchange, true
+	case "NegRiskCTFExchange":
+		return k.NegRiskCTFExchange, true
+	case "NegRiskAdapter":
+		return k.NegRiskAdapter, true
+	case "ConditionalTokens":
+		return k.ConditionalTokens, true
+	}
+
+	address, exists := k.Extra[name]
+	return address, exists
+}
+
+// validateChecksumAddress checks that address is a hex address and, if it uses
+// mixed case, that it matches its EIP-55 checksum
+func validateChecksumAddress(name, address string) error {
+	if !common.IsHexAddress(address) {
+		return errors.ErrInvalidConfiguration(fmt.Sprintf("%s: invalid address %s", name, address))
+	}
+
+	hexPart := strings.TrimPrefix(strings.TrimPrefix(address, "0x"), "0X")
+	allLower := hexPart == strings.ToLower(hexPart)
+	allUpper := hexPart == strings.ToUpper(hexPart)
+	if !allLower && !allUpper && common.HexToAddress(address).Hex() != address {
+		return errors.ErrInvalidConfiguration(fmt.Sprintf("%s: address %s has an invalid checksum", name, address))
+	}
+
+	return nil
+}
@@ -0,0 +1,119 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestGetKnownContracts(t *testing.T) {
+	contracts, err := GetKnownContracts(137)
+	if err != nil {
+		t.Fatalf("GetKnownContracts failed: %v", err)
+	}
+
+	// Canonical Polymarket deployments on Polygon mainnet
+	expected := map[string]string{
+		"USDC":               "0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174",
+		"CTFExchange":        "0x4bFb41d5B3570DeFd03C39a9A4D8dE6Bd8B8982E",
+		"NegRiskCTFExchange": "0xC5d563A36AE78145C45a50134d48A1215220f80a",
+		"NegRiskAdapter":     "0xd91E80cF2E7be2e162c6513ceD06f1dD0dA35296",
+		"ConditionalTokens":  "0x4D97DCd97eC945f40cF65F87097ACe5EA0476045",
+	}
+	for name, address := range expected {
+		got, ok := contracts.Get(name)
+		if !ok || got != address {
+			t.Errorf("%s = %s, want %s", name, got, address)
+		}
+	}
+
+	amoy, err := GetKnownContracts(80002)
+	if err != nil {
+		t.Fatalf("GetKnownContracts(80002) failed: %v", err)
+	}
+	if amoy.USDC == contracts.USDC {
+		t.Error("Amoy USDC should differ from mainnet USDC")
+	}
+	if err := amoy.Validate(); err != nil {
+		t.Errorf("Amoy address book is invalid: %v", err)
+	}
+
+	if _, err := GetKnownContracts(999); err == nil {
+		t.Error("Expected error for unknown chain")
+	}
+}
+
+func TestAddKnownContracts(t *testing.T) {
+	valid := &KnownContracts{
+		ChainID:            31337,
+		USDC:               "0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174",
+		CTFExchange:        "0x4bFb41d5B3570DeFd03C39a9A4D8dE6Bd8B8982E",
+		NegRiskCTFExchange: "0xc5d563a36ae78145c45a50134d48a1215220f80a",
+		NegRiskAdapter:     "0xd91E80cF2E7be2e162c6513ceD06f1dD0dA35296",
+		ConditionalTokens:  "0x4D97DCd97eC945f40cF65F87097ACe5EA0476045",
+		Extra:              map[string]string{"Router": "0x4D97DCd97eC945f40cF65F87097ACe5EA0476045"},
+	}
+
+	tests := []struct {
+		name      string
+		mutate    func(k KnownContracts) *KnownContracts
+		shouldErr bool
+	}{
+		{"valid", func(k KnownContracts) *KnownContracts { return &k }, false},
+		{"bad checksum", func(k KnownContracts) *KnownContracts {
+			k.USDC = "0x2791bca1f2de4661ED88A30C99A7a9449Aa84174"
+			return &k
+		}, true},
+		{"not an address", func(k KnownContracts) *KnownContracts {
+			k.CTFExchange = "0x1234"
+			return &k
+		}, true},
+		{"missing field", func(k KnownContracts) *KnownContracts {
+			k.ConditionalTokens = ""
+			return &k
+		}, true},
+		{"bad extra", func(k KnownContracts) *KnownContracts {
+			k.Extra = map[string]string{"Router": "not-an-address"}
+			return &k
+		}, true},
+		{"invalid chain", func(k KnownContracts) *KnownContracts {
+			k.ChainID = 0
+			return &k
+		}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := AddKnownContracts(tt.mutate(*valid))
+			if tt.shouldErr && err == nil {
+				t.Error("Expected error but got none")
+			}
+			if !tt.shouldErr && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+
+	contracts, err := GetKnownContracts(31337)
+	if err != nil {
+		t.Fatalf("GetKnownContracts failed after registration: %v", err)
+	}
+	if router, ok := contracts.Get("Router"); !ok || router == "" {
+		t.Error("Extra contract should be retrievable by name")
+	}
+}
+
+func TestGetKnownContracts_ReturnsCopy(t *testing.T) {
+	contracts, err := GetKnownContracts(137)
+	if err != nil {
+		t.Fatalf("GetKnownContracts failed: %v", err)
+	}
+	contracts.USDC = "0x0000000000000000000000000000000000000001"
+	contracts.Extra = map[string]string{"Router": "0x0000000000000000000000000000000000000002"}
+
+	again, err := GetKnownContracts(137)
+	if err != nil {
+		t.Fatalf("GetKnownContracts failed: %v", err)
+	}
+	if again.USDC != polygonMainnetContracts.USDC || again.Extra != nil {
+		t.Errorf("a caller's change leaked into the address book: %+v", again)
+	}
+}
@@ -1,8 +1,10 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/davidt58/go-builder-relayer-client/errors"
 )
@@ -17,15 +19,22 @@ type EnvConfig struct {
 	PrivateKey string
 	// BuilderConfig contains Builder API credentials
 	BuilderConfig *BuilderConfig
+	// Environment is the BUILDER_ENV tag, also set on BuilderConfig; empty
+	// when untagged
+	Environment Environment
 }
 
-// LoadFromEnv loads configuration from environment variables
-func LoadFromEnv() (*EnvConfig, error) {
-	relayerURL := os.Getenv("RELAYER_URL")
-	if relayerURL == "" {
-		return nil, errors.ErrMissingRequiredField("RELAYER_URL")
-	}
+// builderVars are the variables holding the builder credentials, in the
+// order of the BuilderConfig fields
+var builderVars = []string{"BUILDER_API_KEY", "BUILDER_SECRET", "BUILDER_PASS_PHRASE"}
 
+// LoadFromEnv loads configuration from environment variables.
+// PK, BUILDER_API_KEY, BUILDER_SECRET and BUILDER_PASS_PHRASE may instead be
+// read from the file named by the matching *_FILE variable. Without
+// RELAYER_URL the relayer registered for CHAIN_ID is used (see LookupRelayerURL).
+// The builder credentials must be all set or all unset. BUILDER_ENV, e.g.
+// "production" or "staging", tags the credentials with their environment.
+func LoadFromEnv() (*EnvConfig, error) {
 	chainIDStr := os.Getenv("CHAIN_ID")
 	if chainIDStr == "" {
 		return nil, errors.ErrMissingRequiredField("CHAIN_ID")
@@ -36,17 +45,42 @@ func LoadFromEnv() (*EnvConfig, error) {
 		return nil, errors.NewRelayerClientError("invalid CHAIN_ID", err)
 	}
 
-	privateKey := os.Getenv("PK")
+	relayerURL := os.Getenv("RELAYER_URL")
+	if relayerURL == "" {
+		if relayerURL, _, err = LookupRelayerURL(chainID); err != nil {
+			return nil, errors.ErrMissingRequiredField("RELAYER_URL")
+		}
+	}
+
 	// Private key is optional for some operations
+	privateKey, err := lookupSecret("PK")
+	if err != nil {
+		return nil, err
+	}
 
 	// Load builder credentials (optional)
 	var builderConfig *BuilderConfig
-	apiKey := os.Getenv("BUILDER_API_KEY")
-	secret := os.Getenv("BUILDER_SECRET")
-	passphrase := os.Getenv("BUILDER_PASS_PHRASE")
+	apiKey, err := lookupSecret("BUILDER_API_KEY")
+	if err != nil {
+		return nil, err
+	}
+	secret, err := lookupSecret("BUILDER_SECRET")
+	if err != nil {
+		return nil, err
+	}
+	passphrase, err := lookupSecret("BUILDER_PASS_PHRASE")
+	if err != nil {
+		return nil, err
+	}
 
-	if apiKey != "" && secret != "" && passphrase != "" {
+	// Some but not all credentials is a mistake, not a read-only setup
+	if err := checkPartial(builderVars, apiKey, secret, passphrase); err != nil {
+		return nil, err
+	}
+	environment := Environment(strings.TrimSpace(os.Getenv("BUILDER_ENV")))
+	if apiKey != "" {
 		builderConfig = NewBuilderConfig(apiKey, secret, passphrase)
+		builderConfig.Environment = environment
 	}
 
 	return &EnvConfig{
@@ -54,9 +88,38 @@ func LoadFromEnv() (*EnvConfig, error) {
 		ChainID:       chainID,
 		PrivateKey:    privateKey,
 		BuilderConfig: builderConfig,
+		Environment:   environment,
 	}, nil
 }
 
+// lookupSecret reads a secret from the name environment variable or, following
+// the *_FILE convention, from the file named by name_FILE. Setting both is an
+// error. Surrounding whitespace is trimmed since mounted secrets often end in a
+// newline, which would otherwise break the HMAC signature.
+func lookupSecret(name string) (string, error) {
+	fileVar := name + "_FILE"
+	value := os.Getenv(name)
+	path := os.Getenv(fileVar)
+
+	if path == "" {
+		return strings.TrimSpace(value), nil
+	}
+	if value != "" {
+		return "", errors.ErrInvalidConfiguration(fmt.Sprintf("both %s and %s are set; use only one", name, fileVar))
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", errors.NewRelayerClientError(fmt.Sprintf("failed to read %s", fileVar), err)
+	}
+
+	secret := strings.TrimSpace(string(data))
+	if secret == "" {
+		return "", errors.ErrInvalidConfiguration(fmt.Sprintf("%s points to an empty file: %s", fileVar, path))
+	}
+	return secret, nil
+}
+
 // Validate checks if the environment configuration is valid
 func (e *EnvConfig) Validate() error {
 	if e.RelayerURL == "" {
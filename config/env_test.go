@@ -0,0 +1,240 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// secretVars are the variables that support the *_FILE convention
+var secretVars = []string{"PK", "BUILDER_API_KEY", "BUILDER_SECRET", "BUILDER_PASS_PHRASE"}
+
+// setBaseEnv sets the required variables and clears every secret variable
+func setBaseEnv(t *testing.T) {
+	t.Helper()
+	t.Setenv("RELAYER_URL", "https://relayer.example.com")
+	t.Setenv("CHAIN_ID", "137")
+	t.Setenv("BUILDER_ENV", "")
+	for _, name := range secretVars {
+		t.Setenv(name, "")
+		t.Setenv(name+"_FILE", "")
+	}
+}
+
+// writeSecret writes contents to a temp file and returns its path
+func writeSecret(t *testing.T, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+		t.Fatalf("Failed to write secret: %v", err)
+	}
+	return path
+}
+
+// secretValue returns the loaded value for a secret variable
+func secretValue(cfg *EnvConfig, name string) string {
+	switch name {
+	case "PK":
+		return cfg.PrivateKey
+	case "BUILDER_API_KEY":
+		return cfg.BuilderConfig.APIKey
+	case "BUILDER_SECRET":
+		return cfg.BuilderConfig.Secret
+	case "BUILDER_PASS_PHRASE":
+		return cfg.BuilderConfig.Passphrase
+	}
+	return ""
+}
+
+func TestLoadFromEnv_SecretFiles(t *testing.T) {
+	for _, name := range secretVars {
+		t.Run(name, func(t *testing.T) {
+			setBaseEnv(t)
+			for _, other := range secretVars {
+				t.Setenv(other, "value-"+other)
+			}
+			t.Setenv(name, "")
+			t.Setenv(name+"_FILE", writeSecret(t, "from-file\n"))
+
+			cfg, err := LoadFromEnv()
+			if err != nil {
+				t.Fatalf("LoadFromEnv failed: %v", err)
+			}
+			if got := secretValue(cfg, name); got != "from-file" {
+				t.Errorf("%s = %q, want %q", name, got, "from-file")
+			}
+		})
+	}
+}
+
+func TestLoadFromEnv_SecretErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(t *testing.T)
+	}{
+		{
+			name: "plain and file both set",
+			setup: func(t *testing.T) {
+				t.Setenv("BUILDER_SECRET", "plain")
+				t.Setenv("BUILDER_SECRET_FILE", writeSecret(t, "file"))
+			},
+		},
+		{
+			name: "unreadable file",
+			setup: func(t *testing.T) {
+				t.Setenv("PK_FILE", filepath.Join(t.TempDir(), "missing"))
+			},
+		},
+		{
+			name: "empty file",
+			setup: func(t *testing.T) {
+				t.Setenv("BUILDER_PASS_PHRASE_FILE", writeSecret(t, " \n"))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setBaseEnv(t)
+			tt.setup(t)
+
+			if _, err := LoadFromEnv(); err == nil {
+				t.Error("expected error but got none")
+			}
+		})
+	}
+}
+
+func TestLoadFromEnv_TrimsSecrets(t *testing.T) {
+	setBaseEnv(t)
+	t.Setenv("PK", "0xabc\n")
+	t.Setenv("BUILDER_API_KEY", " key\n")
+	t.Setenv("BUILDER_SECRET", "c2VjcmV0\r\n")
+	t.Setenv("BUILDER_PASS_PHRASE", "pass\n")
+
+	cfg, err := LoadFromEnv()
+	if err != nil {
+		t.Fatalf("LoadFromEnv failed: %v", err)
+	}
+
+	want := map[string]string{
+		"PK":                  "0xabc",
+		"BUILDER_API_KEY":     "key",
+		"BUILDER_SECRET":      "c2VjcmV0",
+		"BUILDER_PASS_PHRASE": "pass",
+	}
+	for name, value := range want {
+		if got := secretValue(cfg, name); got != value {
+			t.Errorf("%s = %q, want %q", name, got, value)
+		}
+	}
+}
+
+func TestLoadFromEnv_RelayerURL(t *testing.T) {
+	tests := []struct {
+		name       string
+		relayerURL string
+		chainID    string
+		want       string
+		shouldErr  bool
+	}{
+		{"explicit URL wins", "https://relayer.example.com", "137", "https://relayer.example.com", false},
+		{"registered chain", "", "137", "https://relayer-v2.polymarket.com", false},
+		{"staging chain", "", "80002", "https://relayer-v2-staging.polymarket.dev", false},
+		{"unregistered chain", "", "999", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setBaseEnv(t)
+			t.Setenv("RELAYER_URL", tt.relayerURL)
+			t.Setenv("CHAIN_ID", tt.chainID)
+
+			cfg, err := LoadFromEnv()
+			if tt.shouldErr {
+				if err == nil {
+					t.Errorf("Expected error but got relayer %q", cfg.RelayerURL)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadFromEnv failed: %v", err)
+			}
+			if cfg.RelayerURL != tt.want {
+				t.Errorf("RelayerURL = %q, want %q", cfg.RelayerURL, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadFromEnv_BuilderEnv(t *testing.T) {
+	setBaseEnv(t)
+	t.Setenv("BUILDER_API_KEY", "key")
+	t.Setenv("BUILDER_SECRET", "c2VjcmV0")
+	t.Setenv("BUILDER_PASS_PHRASE", "pass")
+
+	cfg, err := LoadFromEnv()
+	if err != nil {
+		t.Fatalf("LoadFromEnv failed: %v", err)
+	}
+	if cfg.Environment != "" || cfg.BuilderConfig.Environment != "" {
+		t.Errorf("untagged environment = %q, %q", cfg.Environment, cfg.BuilderConfig.Environment)
+	}
+
+	t.Setenv("BUILDER_ENV", " staging\n")
+	if cfg, err = LoadFromEnv(); err != nil {
+		t.Fatalf("LoadFromEnv failed: %v", err)
+	}
+	if cfg.Environment != EnvStaging || cfg.BuilderConfig.Environment != EnvStaging {
+		t.Errorf("environment = %q, %q, want %q", cfg.Environment, cfg.BuilderConfig.Environment, EnvStaging)
+	}
+}
+
+func TestLoadFromEnv_PartialBuilderCredentials(t *testing.T) {
+	values := map[string]string{
+		"BUILDER_API_KEY":     "key",
+		"BUILDER_SECRET":      "c2VjcmV0",
+		"BUILDER_PASS_PHRASE": "pass",
+	}
+
+	// Every presence combination of the three variables
+	for mask := 0; mask < 1<<len(builderVars); mask++ {
+		var set, missing []string
+		for i, name := range builderVars {
+			if mask&(1<<i) != 0 {
+				set = append(set, name)
+			} else {
+				missing = append(missing, name)
+			}
+		}
+
+		t.Run(fmt.Sprintf("set=%v", set), func(t *testing.T) {
+			setBaseEnv(t)
+			for _, name := range set {
+				t.Setenv(name, values[name])
+			}
+
+			cfg, err := LoadFromEnv()
+			switch len(set) {
+			case 0:
+				if err != nil || cfg.BuilderConfig != nil {
+					t.Errorf("LoadFromEnv = %+v, %v, want no builder config", cfg, err)
+				}
+			case len(builderVars):
+				if err != nil || cfg.BuilderConfig == nil {
+					t.Errorf("LoadFromEnv = %+v, %v, want a builder config", cfg, err)
+				}
+			default:
+				if err == nil {
+					t.Fatal("Expected error for partial builder credentials")
+				}
+				want := fmt.Sprintf("%s set but %s missing", strings.Join(set, ", "), strings.Join(missing, ", "))
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not contain %q", err.Error(), want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/davidt58/go-builder-relayer-client/errors"
+)
+
+// RelayerLimits are the request size limits a relayer deployment enforces.
+// Requests over a limit are rejected with a generic 400, so the client checks
+// them before fetching a nonce. Zero means no limit.
+type RelayerLimits struct {
+	// MaxCalldataBytes caps the data of the Safe transaction that is signed,
+	// i.e. the multiSend calldata for batches
+	MaxCalldataBytes int
+	// MaxMetadataBytes caps the metadata string
+	MaxMetadataBytes int
+	// MaxBatchCount caps the number of transactions batched into one request
+	MaxBatchCount int
+}
+
+// DefaultRelayerLimits returns the limits of the public Polymarket relayer
+func DefaultRelayerLimits() RelayerLimits {
+	return RelayerLimits{
+		MaxCalldataBytes: 128 * 1024,
+		MaxMetadataBytes: 1024,
+		MaxBatchCount:    100,
+	}
+}
+
+// Validate returns an error if a limit is negative
+func (l RelayerLimits) Validate() error {
+	for _, limit := range []struct {
+		name  string
+		value int
+	}{
+		{"MaxCalldataBytes", l.MaxCalldataBytes},
+		{"MaxMetadataBytes", l.MaxMetadataBytes},
+		{"MaxBatchCount", l.MaxBatchCount},
+	} {
+		if limit.value < 0 {
+			return errors.ErrInvalidConfiguration(fmt.Sprintf("%s must not be negative, got %d", limit.name, limit.value))
+		}
+	}
+	return nil
+}
+
+// CheckBatchCount returns a LimitExceededError if count transactions exceed MaxBatchCount
+func (l RelayerLimits) CheckBatchCount(count int) error {
+	return checkLimit(errors.LimitBatchCount, l.MaxBatchCount, count)
+}
+
+// CheckMetadata returns a LimitExceededError if metadata exceeds MaxMetadataBytes
+func (l RelayerLimits) CheckMetadata(metadata string) error {
+	return checkLimit(errors.LimitMetadata, l.MaxMetadataBytes, len(metadata))
+}
+
+// CheckCalldata returns a LimitExceededError if size bytes of calldata exceed MaxCalldataBytes
+func (l RelayerLimits) CheckCalldata(size int) error {
+	return checkLimit(errors.LimitCalldata, l.MaxCalldataBytes, size)
+}
+
+// checkLimit returns a LimitExceededError if actual exceeds a non-zero max
+func checkLimit(limit errors.Limit, max, actual int) error {
+	if max > 0 && actual > max {
+		return errors.ErrLimitExceeded(limit, max, actual)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package config
+
+import (
+	stderrors "errors"
+	"strings"
+	"testing"
+
+	"github.com/davidt58/go-builder-relayer-client/errors"
+)
+
+func TestRelayerLimits_Check(t *testing.T) {
+	limits := RelayerLimits{MaxCalldataBytes: 64, MaxMetadataBytes: 8, MaxBatchCount: 3}
+
+	tests := []struct {
+		name      string
+		check     func(RelayerLimits) error
+		limit     errors.Limit
+		shouldErr bool
+	}{
+		{name: "calldata at limit", check: func(l RelayerLimits) error { return l.CheckCalldata(64) }},
+		{name: "calldata over limit", check: func(l RelayerLimits) error { return l.CheckCalldata(65) }, limit: errors.LimitCalldata, shouldErr: true},
+		{name: "metadata at limit", check: func(l RelayerLimits) error { return l.CheckMetadata(strings.Repeat("m", 8)) }},
+		{name: "metadata over limit", check: func(l RelayerLimits) error { return l.CheckMetadata(strings.Repeat("m", 9)) }, limit: errors.LimitMetadata, shouldErr: true},
+		{name: "multi-byte metadata counts bytes", check: func(l RelayerLimits) error { return l.CheckMetadata("ééééé") }, limit: errors.LimitMetadata, shouldErr: true},
+		{name: "batch at limit", check: func(l RelayerLimits) error { return l.CheckBatchCount(3) }},
+		{name: "batch over limit", check: func(l RelayerLimits) error { return l.CheckBatchCount(4) }, limit: errors.LimitBatchCount, shouldErr: true},
+		{name: "zero disables", check: func(RelayerLimits) error { return RelayerLimits{}.CheckCalldata(1 << 30) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.check(limits)
+			if (err != nil) != tt.shouldErr {
+				t.Fatalf("error = %v, shouldErr %v", err, tt.shouldErr)
+			}
+			if !tt.shouldErr {
+				return
+			}
+			var limitErr *errors.LimitExceededError
+			if !stderrors.As(err, &limitErr) || limitErr.Limit != tt.limit {
+				t.Errorf("error = %v, want a LimitExceededError for %s", err, tt.limit)
+			}
+		})
+	}
+}
+
+func TestRelayerLimits_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		limits    RelayerLimits
+		shouldErr bool
+	}{
+		{name: "defaults", limits: DefaultRelayerLimits()},
+		{name: "unlimited", limits: RelayerLimits{}},
+		{name: "negative calldata", limits: RelayerLimits{MaxCalldataBytes: -1}, shouldErr: true},
+		{name: "negative batch count", limits: RelayerLimits{MaxBatchCount: -1}, shouldErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.limits.Validate(); (err != nil) != tt.shouldErr {
+				t.Errorf("Validate() error = %v, shouldErr %v", err, tt.shouldErr)
+			}
+		})
+	}
+}
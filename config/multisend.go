@@ -0,0 +1,52 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/davidt58/go-builder-relayer-client/errors"
+)
+
+// MultisendVariant selects which MultiSend contract batches are sent through
+type MultisendVariant string
+
+const (
+	// MultisendStandard is Safe's MultiSend, which allows both Call and
+	// DelegateCall subcalls
+	MultisendStandard MultisendVariant = "standard"
+	// MultisendCallOnly is Safe's MultiSendCallOnly, which reverts on any
+	// DelegateCall subcall
+	MultisendCallOnly MultisendVariant = "call_only"
+)
+
+// OrDefault returns the variant, or MultisendStandard if it is unset
+func (v MultisendVariant) OrDefault() MultisendVariant {
+	if v == "" {
+		return MultisendStandard
+	}
+	return v
+}
+
+// Validate returns an error if the variant is not a known variant
+func (v MultisendVariant) Validate() error {
+	switch v.OrDefault() {
+	case MultisendStandard, MultisendCallOnly:
+		return nil
+	default:
+		return errors.ErrInvalidConfiguration(fmt.Sprintf("unknown multisend variant %q", string(v)))
+	}
+}
+
+// MultisendVariantOf returns the variant of a registered MultiSend address;
+// unknown addresses are treated as MultisendStandard
+func MultisendVariantOf(address string) MultisendVariant {
+	chainConfigsMu.RLock()
+	defer chainConfigsMu.RUnlock()
+
+	for _, config := range chainConfigs {
+		if config.SafeMultisendCallOnly != "" && strings.EqualFold(config.SafeMultisendCallOnly, address) {
+			return MultisendCallOnly
+		}
+	}
+	return MultisendStandard
+}
@@ -0,0 +1,112 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/davidt58/go-builder-relayer-client/errors"
+)
+
+// Environment names a relayer deployment environment
+type Environment string
+
+const (
+	// EnvProduction is the production relayer deployment
+	EnvProduction Environment = "production"
+	// EnvStaging is the staging relayer deployment
+	EnvStaging Environment = "staging"
+)
+
+// environments are searched in this order by LookupRelayerURL
+var environments = []Environment{EnvProduction, EnvStaging}
+
+// knownRelayers maps each environment's chain IDs to their default relayer URL
+var (
+	knownRelayersMu sync.RWMutex
+	knownRelayers   = map[Environment]map[int64]string{
+		EnvProduction: {
+			137: "https://relayer-v2.polymarket.com",
+		},
+		EnvStaging: {
+			80002: "https://relayer-v2-staging.polymarket.dev",
+		},
+	}
+)
+
+// KnownRelayers returns a copy of the default relayer URLs of env by chain ID
+func KnownRelayers(env Environment) map[int64]string {
+	knownRelayersMu.RLock()
+	defer knownRelayersMu.RUnlock()
+
+	relayers := make(map[int64]string, len(knownRelayers[env]))
+	for chainID, relayerURL := range knownRelayers[env] {
+		relayers[chainID] = relayerURL
+	}
+	return relayers
+}
+
+// DefaultRelayerURL returns the default relayer URL for chainID in env
+func DefaultRelayerURL(chainID int64, env Environment) (string, error) {
+	knownRelayersMu.RLock()
+	defer knownRelayersMu.RUnlock()
+
+	relayerURL, exists := knownRelayers[env][chainID]
+	if !exists {
+		return "", errors.ErrInvalidConfiguration(fmt.Sprintf("no %s relayer is registered for chain %d", env, chainID))
+	}
+	return relayerURL, nil
+}
+
+// LookupRelayerURL returns the default relayer URL for chainID and the
+// environment it is registered in, preferring production over staging
+func LookupRelayerURL(chainID int64) (string, Environment, error) {
+	knownRelayersMu.RLock()
+	defer knownRelayersMu.RUnlock()
+
+	for _, env := range environments {
+		if relayerURL, exists := knownRelayers[env][chainID]; exists {
+			return relayerURL, env, nil
+		}
+	}
+	return "", "", errors.ErrInvalidConfiguration(fmt.Sprintf("no relayer is registered for chain %d; pass a relayer URL", chainID))
+}
+
+// AddKnownRelayer adds or updates the default relayer URL for a chain ID in
+// env. Clients already created keep the URL they were created with.
+func AddKnownRelayer(env Environment, chainID int64, relayerURL string) error {
+	if env == "" {
+		return errors.ErrMissingRequiredField("env")
+	}
+	if relayerURL == "" {
+		return errors.ErrMissingRequiredField("relayerURL")
+	}
+	if chainID <= 0 {
+		return errors.ErrInvalidChainID(chainID)
+	}
+
+	knownRelayersMu.Lock()
+	defer knownRelayersMu.Unlock()
+	if knownRelayers[env] == nil {
+		knownRelayers[env] = make(map[int64]string)
+	}
+	knownRelayers[env][chainID] = relayerURL
+	return nil
+}
+
+// RelayerEnvironment returns the environment relayerURL is registered in.
+// URLs are compared case-insensitively, ignoring a trailing slash.
+func RelayerEnvironment(relayerURL string) (Environment, bool) {
+	knownRelayersMu.RLock()
+	defer knownRelayersMu.RUnlock()
+
+	relayerURL = strings.TrimRight(relayerURL, "/")
+	for env, relayers := range knownRelayers {
+		for _, known := range relayers {
+			if strings.EqualFold(strings.TrimRight(known, "/"), relayerURL) {
+				return env, true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,102 @@
+package config
+
+import "testing"
+
+func TestDefaultRelayerURL(t *testing.T) {
+	tests := []struct {
+		name      string
+		chainID   int64
+		env       Environment
+		want      string
+		shouldErr bool
+	}{
+		{"Polygon production", 137, EnvProduction, "https://relayer-v2.polymarket.com", false},
+		{"Amoy staging", 80002, EnvStaging, "https://relayer-v2-staging.polymarket.dev", false},
+		{"Amoy production", 80002, EnvProduction, "", true},
+		{"Unknown chain", 999, EnvProduction, "", true},
+		{"Unknown environment", 137, Environment("qa"), "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DefaultRelayerURL(tt.chainID, tt.env)
+			if tt.shouldErr {
+				if err == nil {
+					t.Errorf("Expected error but got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("DefaultRelayerURL = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLookupRelayerURL(t *testing.T) {
+	relayerURL, env, err := LookupRelayerURL(80002)
+	if err != nil {
+		t.Fatalf("LookupRelayerURL failed: %v", err)
+	}
+	if env != EnvStaging || relayerURL != "https://relayer-v2-staging.polymarket.dev" {
+		t.Errorf("LookupRelayerURL(80002) = %q, %q, want the staging relayer", relayerURL, env)
+	}
+
+	if _, _, err := LookupRelayerURL(999); err == nil {
+		t.Error("Expected error for a chain without a relayer")
+	}
+}
+
+func TestRelayerEnvironment(t *testing.T) {
+	tests := []struct {
+		relayerURL string
+		want       Environment
+		wantOK     bool
+	}{
+		{"https://relayer-v2.polymarket.com", EnvProduction, true},
+		{"https://Relayer-V2.polymarket.com/", EnvProduction, true},
+		{"https://relayer-v2-staging.polymarket.dev", EnvStaging, true},
+		{"https://relayer.internal.example", "", false},
+	}
+	for _, tt := range tests {
+		if env, ok := RelayerEnvironment(tt.relayerURL); env != tt.want || ok != tt.wantOK {
+			t.Errorf("RelayerEnvironment(%q) = %q, %v, want %q, %v", tt.relayerURL, env, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestAddKnownRelayer(t *testing.T) {
+	if err := AddKnownRelayer(EnvStaging, 31337, "http://localhost:8080"); err != nil {
+		t.Fatalf("AddKnownRelayer failed: %v", err)
+	}
+	if got, err := DefaultRelayerURL(31337, EnvStaging); err != nil || got != "http://localhost:8080" {
+		t.Errorf("DefaultRelayerURL = %q, %v, want the added relayer", got, err)
+	}
+
+	// Production wins once the chain is registered there too
+	if err := AddKnownRelayer(EnvProduction, 31337, "https://relayer.example.com"); err != nil {
+		t.Fatalf("AddKnownRelayer failed: %v", err)
+	}
+	if got, env, _ := LookupRelayerURL(31337); env != EnvProduction || got != "https://relayer.example.com" {
+		t.Errorf("LookupRelayerURL = %q, %q, want the production relayer", got, env)
+	}
+
+	// The returned map is a copy
+	KnownRelayers(EnvProduction)[31337] = "https://mutated.example.com"
+	if got, _ := DefaultRelayerURL(31337, EnvProduction); got != "https://relayer.example.com" {
+		t.Errorf("KnownRelayers leaked the registry: %q", got)
+	}
+
+	for name, add := range map[string]func() error{
+		"no environment": func() error { return AddKnownRelayer("", 31337, "http://localhost:8080") },
+		"no URL":         func() error { return AddKnownRelayer(EnvStaging, 31337, "") },
+		"bad chain ID":   func() error { return AddKnownRelayer(EnvStaging, 0, "http://localhost:8080") },
+	} {
+		if err := add(); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
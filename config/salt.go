@@ -0,0 +1,43 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/davidt58/go-builder-relayer-client/errors"
+)
+
+// SaltStrategy selects how the Safe factory computes the CREATE2 salt, and
+// so how Safe addresses are derived. The initializer hash is
+// keccak256(abi.encode(owner)).
+type SaltStrategy string
+
+const (
+	// SaltInitializerHash uses the initializer hash as the salt, so a signer
+	// gets the same Safe address on every chain sharing a factory
+	// (Polymarket's factory)
+	SaltInitializerHash SaltStrategy = "initializer_hash"
+	// SaltInitializerHashChainID uses keccak256(initializerHash ++ chainId),
+	// giving a different Safe address per chain
+	SaltInitializerHashChainID SaltStrategy = "initializer_hash_chain_id"
+	// SaltInitializerHashNonce uses keccak256(initializerHash ++ saltNonce)
+	// with the config's SaltNonce, as Safe's createProxyWithNonce does
+	SaltInitializerHashNonce SaltStrategy = "initializer_hash_salt_nonce"
+)
+
+// OrDefault returns the strategy, or SaltInitializerHash if it is unset
+func (s SaltStrategy) OrDefault() SaltStrategy {
+	if s == "" {
+		return SaltInitializerHash
+	}
+	return s
+}
+
+// Validate returns an error if the strategy is not a known strategy
+func (s SaltStrategy) Validate() error {
+	switch s.OrDefault() {
+	case SaltInitializerHash, SaltInitializerHashChainID, SaltInitializerHashNonce:
+		return nil
+	default:
+		return errors.ErrInvalidConfiguration(fmt.Sprintf("unknown salt strategy %q", string(s)))
+	}
+}
@@ -54,7 +54,7 @@ func main() {
 			},
 		},
 		Nonce:    "0",
-		Metadata: "withdrawal",
+		Metadata: models.MetadataOf("withdrawal"),
 	}
 
 	// Create struct hash
@@ -1,7 +1,14 @@
 package errors
 
 import (
+	"context"
+	stderrors "errors"
 	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // RelayerClientError represents a client-side error
@@ -12,6 +19,9 @@ type RelayerClientError struct {
 	Code string
 	// Err is the underlying error
 	Err error
+	// OperationID identifies the Deploy/Execute/PollUntilState call that
+	// produced the error (see the X-Client-Operation-Id header)
+	OperationID string
 }
 
 // Error implements the error interface
@@ -54,14 +64,120 @@ type RelayerApiError struct {
 	Code string
 	// Details contains additional error details
 	Details interface{}
+
+	// fieldErrors are the validation errors parsed from Details
+	fieldErrors []FieldError
 }
 
 // Error implements the error interface
 func (e *RelayerApiError) Error() string {
+	var msg string
 	if e.Code != "" {
-		return fmt.Sprintf("relayer api error (status %d, code %s): %s", e.StatusCode, e.Code, e.Message)
+		msg = fmt.Sprintf("relayer api error (status %d, code %s): %s", e.StatusCode, e.Code, e.Message)
+	} else {
+		msg = fmt.Sprintf("relayer api error (status %d): %s", e.StatusCode, e.Message)
+	}
+
+	if len(e.fieldErrors) > 0 {
+		parts := make([]string, len(e.fieldErrors))
+		for i, fe := range e.fieldErrors {
+			parts[i] = fe.String()
+		}
+		msg += " [" + strings.Join(parts, "; ") + "]"
 	}
-	return fmt.Sprintf("relayer api error (status %d): %s", e.StatusCode, e.Message)
+	return msg
+}
+
+// FieldErrors returns the field-level validation errors reported by the relayer
+func (e *RelayerApiError) FieldErrors() []FieldError {
+	return e.fieldErrors
+}
+
+// FieldError is a single field-level validation error reported by the relayer
+type FieldError struct {
+	// Field is the path of the offending field (e.g. "signatures[0].data")
+	Field string `json:"field"`
+	// Code is the machine-readable error code (optional)
+	Code string `json:"code,omitempty"`
+	// Message is the human-readable error message
+	Message string `json:"message"`
+}
+
+// String returns a compact representation: "field: message (code)"
+func (f FieldError) String() string {
+	var b strings.Builder
+	if f.Field != "" {
+		b.WriteString(f.Field)
+		b.WriteString(": ")
+	}
+	b.WriteString(f.Message)
+	if f.Code != "" {
+		b.WriteString(" (")
+		b.WriteString(f.Code)
+		b.WriteString(")")
+	}
+	return b.String()
+}
+
+// ParseFieldErrors extracts field errors from a decoded JSON details value.
+// Supported shapes are a list of {field|path, code, message} objects, an
+// object wrapping such a list under "errors", and a map of field to message.
+// Unknown shapes yield nil.
+func ParseFieldErrors(details interface{}) []FieldError {
+	switch v := details.(type) {
+	case []interface{}:
+		var result []FieldError
+		for _, item := range v {
+			switch entry := item.(type) {
+			case map[string]interface{}:
+				if fe, ok := fieldErrorFromMap(entry); ok {
+					result = append(result, fe)
+				}
+			case string:
+				result = append(result, FieldError{Message: entry})
+			}
+		}
+		return result
+
+	case map[string]interface{}:
+		if nested, ok := v["errors"]; ok {
+			return ParseFieldErrors(nested)
+		}
+		if fe, ok := fieldErrorFromMap(v); ok {
+			return []FieldError{fe}
+		}
+
+		var result []FieldError
+		for field, message := range v {
+			if msg, ok := message.(string); ok {
+				result = append(result, FieldError{Field: field, Message: msg})
+			}
+		}
+		sort.Slice(result, func(i, j int) bool { return result[i].Field < result[j].Field })
+		return result
+
+	default:
+		return nil
+	}
+}
+
+// fieldErrorFromMap reads a single {field|path, code, message} object
+func fieldErrorFromMap(m map[string]interface{}) (FieldError, bool) {
+	message, _ := m["message"].(string)
+	if message == "" {
+		message, _ = m["msg"].(string)
+	}
+	if message == "" {
+		return FieldError{}, false
+	}
+
+	field, _ := m["field"].(string)
+	if field == "" {
+		field, _ = m["path"].(string)
+	}
+	code, _ := m["code"].(string)
+
+	return FieldError{Field: field, Code: code, Message: message}, true
 }
 
 // NewRelayerApiError creates a new RelayerApiError
@@ -82,13 +198,656 @@ func NewRelayerApiErrorWithCode(statusCode int, message, code string) *RelayerAp
 }
 
 // NewRelayerApiErrorWithDetails creates a new RelayerApiError with details
+// Field-level validation errors are parsed from details when present
 func NewRelayerApiErrorWithDetails(statusCode int, message, code string, details interface{}) *RelayerApiError {
 	return &RelayerApiError{
-		StatusCode: statusCode,
-		Message:    message,
-		Code:       code,
-		Details:    details,
+		StatusCode:  statusCode,
+		Message:     message,
+		Code:        code,
+		Details:     details,
+		fieldErrors: ParseFieldErrors(details),
+	}
+}
+
+// OperationTimeoutError is returned when a multi-step operation (Deploy, Execute)
+// exceeds its overall deadline
+type OperationTimeoutError struct {
+	// Operation is the name of the operation that timed out
+	Operation string
+	// Step is the step that was in flight when the deadline expired
+	Step string
+	// CompletedSteps lists the steps that finished before the deadline
+	CompletedSteps []string
+	// Timeout is the configured budget for the whole operation
+	Timeout time.Duration
+	// OperationID identifies the operation that timed out
+	OperationID string
+}
+
+// Error implements the error interface
+func (e *OperationTimeoutError) Error() string {
+	completed := "none"
+	if len(e.CompletedSteps) > 0 {
+		completed = strings.Join(e.CompletedSteps, ", ")
+	}
+	return fmt.Sprintf("relayer client error: %s timed out after %s during step %q (completed: %s)",
+		e.Operation, e.Timeout, e.Step, completed)
+}
+
+// Unwrap returns context.DeadlineExceeded so callers can use errors.Is
+func (e *OperationTimeoutError) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+// ErrOperationTimeout is returned when an operation exceeds its overall deadline
+func ErrOperationTimeout(operation, step string, completedSteps []string, timeout time.Duration) *OperationTimeoutError {
+	return &OperationTimeoutError{
+		Operation:      operation,
+		Step:           step,
+		CompletedSteps: completedSteps,
+		Timeout:        timeout,
+	}
+}
+
+// DeployedAddressMismatchError is returned when the Safe proxy emitted by the
+// factory differs from the locally derived address
+type DeployedAddressMismatchError struct {
+	// Expected is the locally derived Safe address
+	Expected string
+	// Actual is the proxy address emitted in the ProxyCreation event
+	Actual string
+}
+
+// Error implements the error interface
+func (e *DeployedAddressMismatchError) Error() string {
+	return fmt.Sprintf("relayer client error: deployed Safe address %s does not match expected %s", e.Actual, e.Expected)
+}
+
+// ErrDeployedAddressMismatch is returned when the deployed proxy address differs from the derived one
+func ErrDeployedAddressMismatch(expected, actual string) *DeployedAddressMismatchError {
+	return &DeployedAddressMismatchError{
+		Expected: expected,
+		Actual:   actual,
+	}
+}
+
+// SafeAddressMismatchError is returned when a SAFE-CREATE request declares a
+// Safe address that is not the one derived from its signer
+type SafeAddressMismatchError struct {
+	// Signer is the owner the Safe address is derived from
+	Signer string
+	// Declared is the Safe address given in the creation arguments
+	Declared string
+	// Derived is the Safe address the factory will create for Signer
+	Derived string
+}
+
+// Error implements the error interface
+func (e *SafeAddressMismatchError) Error() string {
+	return fmt.Sprintf("relayer client error: Safe address %s does not match %s derived for signer %s", e.Declared, e.Derived, e.Signer)
+}
+
+// ErrSafeAddressMismatch is returned when a declared Safe address differs from the derived one
+func ErrSafeAddressMismatch(signer, declared, derived string) *SafeAddressMismatchError {
+	return &SafeAddressMismatchError{
+		Signer:   signer,
+		Declared: declared,
+		Derived:  derived,
+	}
+}
+
+// ChainIDMismatchError is returned when a signer was created for a different
+// chain than the one a request is built for
+type ChainIDMismatchError struct {
+	// SignerChainID is the chain ID the signer signs for
+	SignerChainID int64
+	// ChainID is the chain ID the request or client targets
+	ChainID int64
+}
+
+// Error implements the error interface
+func (e *ChainIDMismatchError) Error() string {
+	return fmt.Sprintf("relayer client error: signer chain ID %d does not match chain ID %d", e.SignerChainID, e.ChainID)
+}
+
+// ErrChainIDMismatch is returned when the signer's chain ID differs from the target chain ID
+func ErrChainIDMismatch(signerChainID, chainID int64) *ChainIDMismatchError {
+	return &ChainIDMismatchError{
+		SignerChainID: signerChainID,
+		ChainID:       chainID,
+	}
+}
+
+// EnvironmentMismatchError is returned when something tagged with one
+// environment, e.g. builder credentials tagged "staging", is used with
+// something of another, e.g. the production relayer
+type EnvironmentMismatchError struct {
+	// Tagged names what carries the tag, e.g. "builder credentials"
+	Tagged string
+	// Environment is the tag
+	Environment string
+	// Target names what it is used with, e.g. "relayer <url>"
+	Target string
+	// TargetEnvironment is the target's environment
+	TargetEnvironment string
+}
+
+// Error implements the error interface
+func (e *EnvironmentMismatchError) Error() string {
+	return fmt.Sprintf("relayer client error: environment mismatch: %s (%s) used with %s (%s)", e.Tagged, e.Environment, e.Target, e.TargetEnvironment)
+}
+
+// ErrEnvironmentMismatch is returned when the tagged environment of the
+// credentials or client differs from the relayer's or each other's
+func ErrEnvironmentMismatch(tagged, environment, target, targetEnvironment string) *EnvironmentMismatchError {
+	return &EnvironmentMismatchError{
+		Tagged:            tagged,
+		Environment:       environment,
+		Target:            target,
+		TargetEnvironment: targetEnvironment,
+	}
+}
+
+// TransactionChainIDMismatchError is returned when the relayer returns a
+// transaction for a different chain than the client's
+type TransactionChainIDMismatchError struct {
+	// TransactionID is the transaction that was read
+	TransactionID string
+	// ChainID is the client's chain ID
+	ChainID int64
+	// TransactionChainID is the chain ID the relayer reported for the transaction
+	TransactionChainID int64
+}
+
+// Error implements the error interface
+func (e *TransactionChainIDMismatchError) Error() string {
+	return fmt.Sprintf("relayer client error: transaction %s is on chain ID %d, not the client's chain ID %d",
+		e.TransactionID, e.TransactionChainID, e.ChainID)
+}
+
+// ErrTransactionChainIDMismatch is returned when a transaction read from the relayer is on another chain
+func ErrTransactionChainIDMismatch(transactionID string, chainID, transactionChainID int64) *TransactionChainIDMismatchError {
+	return &TransactionChainIDMismatchError{
+		TransactionID:      transactionID,
+		ChainID:            chainID,
+		TransactionChainID: transactionChainID,
+	}
+}
+
+// UnsupportedVError is returned when a signature's v value is not one of the
+// recovery encodings the signer understands
+type UnsupportedVError struct {
+	// V is the v value found in the signature
+	V int
+}
+
+// Error implements the error interface
+func (e *UnsupportedVError) Error() string {
+	return fmt.Sprintf("relayer client error: invalid signature: unsupported v value %d", e.V)
+}
+
+// ErrUnsupportedV is returned when a signature carries an unsupported v value
+func ErrUnsupportedV(v int) *UnsupportedVError {
+	return &UnsupportedVError{V: v}
+}
+
+// SignatureTypeMismatchError is returned when a signature's v value is not
+// the v range of its declared signature type
+type SignatureTypeMismatchError struct {
+	// Type is the declared signature type
+	Type string
+	// V is the v value found in the signature
+	V int
+}
+
+// Error implements the error interface
+func (e *SignatureTypeMismatchError) Error() string {
+	return fmt.Sprintf("relayer client error: invalid signature: v %d does not match signature type %s", e.V, e.Type)
+}
+
+// ErrSignatureTypeMismatch is returned when a signature's v contradicts its signature type
+func ErrSignatureTypeMismatch(signatureType string, v int) *SignatureTypeMismatchError {
+	return &SignatureTypeMismatchError{Type: signatureType, V: v}
+}
+
+// SignatureMismatchError is returned when a signature supplied from
+// elsewhere does not recover to the expected signer
+type SignatureMismatchError struct {
+	// Expected is the address the signature was supplied for
+	Expected string
+	// Recovered is the address the signature recovers to over the computed hash
+	Recovered string
+}
+
+// Error implements the error interface
+func (e *SignatureMismatchError) Error() string {
+	return fmt.Sprintf("relayer client error: invalid signature: recovers to %s, not %s", e.Recovered, e.Expected)
+}
+
+// ErrSignatureMismatch is returned when a supplied signature was made by another key or over another payload
+func ErrSignatureMismatch(expected, recovered string) *SignatureMismatchError {
+	return &SignatureMismatchError{Expected: expected, Recovered: recovered}
+}
+
+// ResponseTooLargeError is returned when a response body exceeds the HTTP
+// client's maximum response size
+type ResponseTooLargeError struct {
+	// Limit is the maximum response size in bytes
+	Limit int64
+}
+
+// Error implements the error interface
+func (e *ResponseTooLargeError) Error() string {
+	return fmt.Sprintf("relayer client error: response body exceeds %d bytes", e.Limit)
+}
+
+// ErrResponseTooLarge is returned when a response body is larger than limit bytes
+func ErrResponseTooLarge(limit int64) *ResponseTooLargeError {
+	return &ResponseTooLargeError{Limit: limit}
+}
+
+// RedirectError is returned when the relayer answers with a redirect the HTTP
+// client will not follow. Signed requests are never replayed to another
+// origin.
+type RedirectError struct {
+	// From is the URL that was requested
+	From string
+	// To is the redirect target
+	To string
+}
+
+// Error implements the error interface
+func (e *RedirectError) Error() string {
+	return fmt.Sprintf("relayer client error: refusing redirect from %s to %s", e.From, e.To)
+}
+
+// ErrRedirectRefused is returned when a redirect from one URL to another is not followed
+func ErrRedirectRefused(from, to string) *RedirectError {
+	return &RedirectError{From: from, To: to}
+}
+
+// SafeNotDeployedError is returned when Execute targets a Safe that has not
+// been deployed yet
+type SafeNotDeployedError struct {
+	// SafeAddress is the Safe that is not deployed
+	SafeAddress string
+}
+
+// Error implements the error interface
+func (e *SafeNotDeployedError) Error() string {
+	return fmt.Sprintf("relayer client error: Safe %s is not deployed", e.SafeAddress)
+}
+
+// ErrSafeNotDeployed is returned when an execution targets an undeployed Safe
+func ErrSafeNotDeployed(safeAddress string) *SafeNotDeployedError {
+	return &SafeNotDeployedError{SafeAddress: safeAddress}
+}
+
+// DeploymentPendingError is returned by Deploy when an earlier deployment of
+// the same Safe is still in flight at the relayer, which would reject a
+// second one. Wait on TransactionID (see RelayClient.ResumeDeploy) instead of
+// submitting again.
+type DeploymentPendingError struct {
+	// SafeAddress is the Safe being deployed
+	SafeAddress string
+	// TransactionID is the ID of the pending SAFE-CREATE transaction
+	TransactionID string
+	// State is the pending transaction's state
+	State string
+}
+
+// Error implements the error interface
+func (e *DeploymentPendingError) Error() string {
+	return fmt.Sprintf("relayer client error: deployment of Safe %s already pending as transaction %s (%s)", e.SafeAddress, e.TransactionID, e.State)
+}
+
+// ErrDeploymentAlreadyPending is returned when a deployment of safeAddress is already in flight
+func ErrDeploymentAlreadyPending(safeAddress, transactionID, state string) *DeploymentPendingError {
+	return &DeploymentPendingError{
+		SafeAddress:   safeAddress,
+		TransactionID: transactionID,
+		State:         state,
+	}
+}
+
+// RelayerUnavailableError is returned when the relayer reports itself
+// unhealthy or in maintenance and a submission is not attempted
+type RelayerUnavailableError struct {
+	// MaintenanceUntil is the end of the announced maintenance window, if any
+	MaintenanceUntil *time.Time
+	// Message is the relayer's status message
+	Message string
+	// Err is the error that prevented the status check, if any
+	Err error
+}
+
+// Error implements the error interface
+func (e *RelayerUnavailableError) Error() string {
+	msg := "relayer client error: relayer unavailable"
+	if e.MaintenanceUntil != nil {
+		msg += fmt.Sprintf(" (maintenance until %s)", e.MaintenanceUntil.UTC().Format(time.RFC3339))
+	}
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+// Unwrap returns the underlying error
+func (e *RelayerUnavailableError) Unwrap() error {
+	return e.Err
+}
+
+// ErrRelayerUnavailable is returned when the health gate blocks a submission
+func ErrRelayerUnavailable(maintenanceUntil *time.Time, message string, err error) *RelayerUnavailableError {
+	return &RelayerUnavailableError{
+		MaintenanceUntil: maintenanceUntil,
+		Message:          message,
+		Err:              err,
+	}
+}
+
+// Stage identifies the step of a Deploy or Execute call that failed
+type Stage string
+
+const (
+	// StageDerive is the Safe address derivation
+	StageDerive Stage = "derive"
+	// StageDeployedCheck is the check whether the Safe is already deployed
+	StageDeployedCheck Stage = "deployed-check"
+	// StageHealthCheck is the relayer health gate (see client.WithHealthGate)
+	StageHealthCheck Stage = "health-check"
+	// StageAutoDeploy is deploying the Safe ahead of an Execute and waiting
+	// for the deployment to be mined (see client.ExecuteOptions.AutoDeploy)
+	StageAutoDeploy Stage = "auto-deploy"
+	// StageSafeVersion is reading the version of an existing Safe
+	StageSafeVersion Stage = "safe-version"
+	// StageWarmUp is waiting for a cold-starting relayer to respond (see
+	// client.WithColdStartTolerance)
+	StageWarmUp Stage = "warm-up"
+	// StageNonce is the nonce fetch
+	StageNonce Stage = "nonce"
+	// StageBuild is building the transaction request
+	StageBuild Stage = "build"
+	// StageSign is signing the transaction
+	StageSign Stage = "sign"
+	// StageSubmit is submitting the request to the relayer
+	StageSubmit Stage = "submit"
+)
+
+// StagedError tags a Deploy or Execute failure with the stage it happened in
+type StagedError struct {
+	// Stage is the step that failed
+	Stage Stage
+	// OperationID identifies the operation that failed
+	OperationID string
+	// Err is the underlying error
+	Err error
+}
+
+// Error implements the error interface
+func (e *StagedError) Error() string {
+	return fmt.Sprintf("%s stage failed: %v", e.Stage, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *StagedError) Unwrap() error {
+	return e.Err
+}
+
+// ErrStaged wraps err with the stage it happened in. Errors that already
+// carry a stage are returned unchanged so the innermost stage wins.
+func ErrStaged(stage Stage, err error) error {
+	if err == nil {
+		return nil
+	}
+	var staged *StagedError
+	if stderrors.As(err, &staged) {
+		return err
+	}
+	return &StagedError{
+		Stage: stage,
+		Err:   err,
+	}
+}
+
+// StageOf returns the stage recorded on err, or "" if none
+func StageOf(err error) Stage {
+	var staged *StagedError
+	if stderrors.As(err, &staged) {
+		return staged.Stage
 	}
+	return ""
+}
+
+// TransitionError is returned when a transaction state change violates the
+// allowed state machine (e.g. CONFIRMED → NEW)
+type TransitionError struct {
+	// TransactionID is the transaction whose state changed
+	TransactionID string
+	// From is the previously observed state
+	From string
+	// To is the newly observed state
+	To string
+}
+
+// Error implements the error interface
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("relayer client error: invalid state transition for transaction %s: %s -> %s", e.TransactionID, e.From, e.To)
+}
+
+// ErrInvalidTransition is returned when a transaction moves between states that are not connected
+func ErrInvalidTransition(transactionID, from, to string) *TransitionError {
+	return &TransitionError{
+		TransactionID: transactionID,
+		From:          from,
+		To:            to,
+	}
+}
+
+// PartialExecutionError is returned by ExecuteIndependent when a submission
+// fails part way through a sequence. Transactions before FailedIndex were
+// submitted; FailedIndex and everything after it were not, so the caller can
+// resume from FailedIndex.
+type PartialExecutionError struct {
+	// Total is the number of transactions in the sequence
+	Total int
+	// FailedIndex is the index of the transaction whose submission failed
+	FailedIndex int
+	// FailedNonce is the Safe nonce the failed transaction was submitted with
+	// (empty if no nonce could be assigned)
+	FailedNonce string
+	// Err is the submission error
+	Err error
+}
+
+// Error implements the error interface
+func (e *PartialExecutionError) Error() string {
+	nonce := e.FailedNonce
+	if nonce == "" {
+		nonce = "n/a"
+	}
+	return fmt.Sprintf("relayer client error: independent execution stopped at transaction %d of %d (nonce %s), %d submitted: %v",
+		e.FailedIndex, e.Total, nonce, e.FailedIndex, e.Err)
+}
+
+// Unwrap returns the submission error
+func (e *PartialExecutionError) Unwrap() error {
+	return e.Err
+}
+
+// ErrPartialExecution is returned when an independent execution sequence stops at failedIndex
+func ErrPartialExecution(total, failedIndex int, failedNonce string, err error) *PartialExecutionError {
+	return &PartialExecutionError{
+		Total:       total,
+		FailedIndex: failedIndex,
+		FailedNonce: failedNonce,
+		Err:         err,
+	}
+}
+
+// CancelledError is returned when a watched transaction was cancelled or
+// replaced instead of executing
+type CancelledError struct {
+	// TransactionID is the cancelled transaction
+	TransactionID string
+	// State is STATE_CANCELLED or STATE_REPLACED
+	State string
+	// ReplacedBy is the ID of the replacement transaction, if any
+	ReplacedBy string
+}
+
+// Error implements the error interface
+func (e *CancelledError) Error() string {
+	if e.ReplacedBy != "" {
+		return fmt.Sprintf("relayer client error: transaction %s was replaced by %s", e.TransactionID, e.ReplacedBy)
+	}
+	return fmt.Sprintf("relayer client error: transaction %s was cancelled (%s)", e.TransactionID, e.State)
+}
+
+// ErrTransactionCancelled is returned when a transaction is cancelled or replaced
+func ErrTransactionCancelled(transactionID, state, replacedBy string) *CancelledError {
+	return &CancelledError{
+		TransactionID: transactionID,
+		State:         state,
+		ReplacedBy:    replacedBy,
+	}
+}
+
+// PollErrorBudgetError is returned when PollUntilState sees too many
+// consecutive transient errors while fetching a transaction
+type PollErrorBudgetError struct {
+	// TransactionID is the transaction being polled
+	TransactionID string
+	// ConsecutiveErrors is the number of failed polls in a row
+	ConsecutiveErrors int
+	// LastState is the last successfully observed state ("" if none was seen)
+	LastState string
+	// Err is the most recent poll error
+	Err error
+}
+
+// Error implements the error interface
+func (e *PollErrorBudgetError) Error() string {
+	state := e.LastState
+	if state == "" {
+		state = "never observed"
+	}
+	return fmt.Sprintf("relayer client error: polling transaction %s gave up after %d consecutive errors (last state: %s): %v",
+		e.TransactionID, e.ConsecutiveErrors, state, e.Err)
+}
+
+// Unwrap returns the most recent poll error
+func (e *PollErrorBudgetError) Unwrap() error {
+	return e.Err
+}
+
+// ErrPollErrorBudgetExhausted is returned when polling hits its consecutive-error budget
+func ErrPollErrorBudgetExhausted(transactionID string, consecutiveErrors int, lastState string, err error) *PollErrorBudgetError {
+	return &PollErrorBudgetError{
+		TransactionID:     transactionID,
+		ConsecutiveErrors: consecutiveErrors,
+		LastState:         lastState,
+		Err:               err,
+	}
+}
+
+// MultiWaitError is returned by WaitAll and WaitAny when transactions did
+// not reach a target state. Errors is indexed like the waited responses,
+// with nil for the transactions that succeeded or were not waited on.
+type MultiWaitError struct {
+	// TransactionIDs are the IDs of the waited transactions
+	TransactionIDs []string
+	// Errors are the individual wait errors
+	Errors []error
+}
+
+// Error implements the error interface
+func (e *MultiWaitError) Error() string {
+	var failed []string
+	for i, err := range e.Errors {
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", e.TransactionIDs[i], err))
+		}
+	}
+	return fmt.Sprintf("relayer client error: %d of %d transactions did not reach a target state: %s",
+		len(failed), len(e.TransactionIDs), strings.Join(failed, "; "))
+}
+
+// Unwrap returns the individual wait errors
+func (e *MultiWaitError) Unwrap() []error {
+	var errs []error
+	for _, err := range e.Errors {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// Failed returns the number of transactions with an error
+func (e *MultiWaitError) Failed() int {
+	return len(e.Unwrap())
+}
+
+// ErrMultiWait is returned when some of several waited transactions failed;
+// errs is indexed like transactionIDs
+func ErrMultiWait(transactionIDs []string, errs []error) *MultiWaitError {
+	return &MultiWaitError{TransactionIDs: transactionIDs, Errors: errs}
+}
+
+// WithOperationID records operationID on the first RelayerClientError,
+// OperationTimeoutError and StagedError in err's chain and returns err. The package-level
+// sentinel errors are shared and therefore never tagged.
+func WithOperationID(err error, operationID string) error {
+	if err == nil || operationID == "" {
+		return err
+	}
+
+	var clientErr *RelayerClientError
+	if stderrors.As(err, &clientErr) && !isSentinel(clientErr) {
+		clientErr.OperationID = operationID
+	}
+
+	var timeoutErr *OperationTimeoutError
+	if stderrors.As(err, &timeoutErr) {
+		timeoutErr.OperationID = operationID
+	}
+
+	var staged *StagedError
+	if stderrors.As(err, &staged) {
+		staged.OperationID = operationID
+	}
+
+	return err
+}
+
+// OperationIDOf returns the operation ID recorded on err, if any
+func OperationIDOf(err error) string {
+	var timeoutErr *OperationTimeoutError
+	if stderrors.As(err, &timeoutErr) && timeoutErr.OperationID != "" {
+		return timeoutErr.OperationID
+	}
+
+	var clientErr *RelayerClientError
+	if stderrors.As(err, &clientErr) {
+		return clientErr.OperationID
+	}
+	return ""
+}
+
+// isSentinel reports whether err is one of the shared package-level errors
+func isSentinel(err *RelayerClientError) bool {
+	switch err {
+	case ErrSignerNotConfigured, ErrBuilderCredsNotConfigured, ErrSubmissionStoreNotConfigured,
+		ErrUsageUnavailable, ErrKeyInfoUnavailable, ErrNoPendingDeployment,
+		ErrClientClosed, ErrQueueClosed, ErrCertificatePinMismatch:
+		return true
+	}
+	return false
 }
 
 // Common error constructors
@@ -99,6 +858,71 @@ var ErrSignerNotConfigured = NewRelayerClientError("signer not configured", nil)
 // ErrBuilderCredsNotConfigured is returned when builder credentials are required but not configured
 var ErrBuilderCredsNotConfigured = NewRelayerClientError("builder credentials not configured", nil)
 
+// EndpointUnsupportedError is returned when the relayer does not serve an
+// optional endpoint (it answered 404)
+type EndpointUnsupportedError struct {
+	// Endpoint is the path that was requested
+	Endpoint string
+}
+
+// Error implements the error interface
+func (e *EndpointUnsupportedError) Error() string {
+	return fmt.Sprintf("relayer client error: the relayer does not support %s", e.Endpoint)
+}
+
+// ErrEndpointUnsupported is returned when the relayer has no endpoint at path
+func ErrEndpointUnsupported(endpoint string) *EndpointUnsupportedError {
+	return &EndpointUnsupportedError{Endpoint: endpoint}
+}
+
+// ErrSubmissionStoreNotConfigured is returned when recovery is requested without a submission store
+var ErrSubmissionStoreNotConfigured = NewRelayerClientError("submission store not configured", nil)
+
+// OriginalPayloadUnavailableError is returned by RetryTransaction when neither
+// the relayer nor the local submission store has the original transactions
+type OriginalPayloadUnavailableError struct {
+	// TransactionID is the transaction that could not be rebuilt
+	TransactionID string
+}
+
+// Error implements the error interface
+func (e *OriginalPayloadUnavailableError) Error() string {
+	return fmt.Sprintf("relayer client error: original payload of transaction %s is unavailable", e.TransactionID)
+}
+
+// ErrOriginalPayloadUnavailable is returned when a transaction's original payload cannot be recovered
+func ErrOriginalPayloadUnavailable(transactionID string) *OriginalPayloadUnavailableError {
+	return &OriginalPayloadUnavailableError{TransactionID: transactionID}
+}
+
+// ErrNonceReservationInactive is returned when committing or releasing a
+// nonce reservation that expired or was already committed or released
+func ErrNonceReservationInactive(id string) *RelayerClientError {
+	return NewRelayerClientError(fmt.Sprintf("nonce reservation %s is not active", id), nil)
+}
+
+// ErrUsageUnavailable is returned by GetUsage when the relayer has no usage
+// endpoint and no response has carried usage headers yet
+var ErrUsageUnavailable = NewRelayerClientError("usage not available", nil)
+
+// ErrKeyInfoUnavailable is returned by GetKeyCapabilities when the relayer
+// has no key-info endpoint
+var ErrKeyInfoUnavailable = NewRelayerClientError("key capabilities not available", nil)
+
+// ErrNoPendingDeployment is returned by ResumeDeploy when no deployment of
+// the signer's Safe is in flight
+var ErrNoPendingDeployment = NewRelayerClientError("no pending deployment", nil)
+
+// ErrClientClosed is returned by every request of a client after Close
+var ErrClientClosed = NewRelayerClientError("client is closed", nil)
+
+// ErrQueueClosed is returned when enqueueing into a submission queue that
+// has been shut down
+var ErrQueueClosed = NewRelayerClientError("submission queue is closed", nil)
+
+// ErrCertificatePinMismatch is returned when the relayer's certificate does not match a pinned SPKI hash
+var ErrCertificatePinMismatch = NewRelayerClientError("certificate pin mismatch", nil)
+
 // ErrInvalidPrivateKey is returned when the private key is invalid
 func ErrInvalidPrivateKey(err error) *RelayerClientError {
 	return NewRelayerClientError("invalid private key", err)
@@ -109,11 +933,44 @@ func ErrInvalidAddress(address string) *RelayerClientError {
 	return NewRelayerClientError(fmt.Sprintf("invalid address: %s", address), nil)
 }
 
+// ErrUnsupportedSafeVersion is returned when a Safe version string cannot be parsed
+func ErrUnsupportedSafeVersion(version string) *RelayerClientError {
+	return NewRelayerClientError(fmt.Sprintf("unsupported Safe version: %q", version), nil)
+}
+
 // ErrInvalidChainID is returned when a chain ID is not supported
 func ErrInvalidChainID(chainID int64) *RelayerClientError {
 	return NewRelayerClientError(fmt.Sprintf("unsupported chain ID: %d", chainID), nil)
 }
 
+// UnsupportedChainError is returned when no contract configuration is
+// registered for a chain ID
+type UnsupportedChainError struct {
+	// ChainID is the requested chain ID
+	ChainID int64
+	// SupportedChains are the chain IDs with a registered configuration, sorted
+	SupportedChains []int64
+}
+
+// Error implements the error interface
+func (e *UnsupportedChainError) Error() string {
+	supported := "none"
+	if len(e.SupportedChains) > 0 {
+		ids := make([]string, len(e.SupportedChains))
+		for i, chainID := range e.SupportedChains {
+			ids[i] = strconv.FormatInt(chainID, 10)
+		}
+		supported = strings.Join(ids, ", ")
+	}
+	return fmt.Sprintf("relayer client error: unsupported chain ID: %d (supported: %s; register other chains with config.AddChainConfig)",
+		e.ChainID, supported)
+}
+
+// ErrUnsupportedChain is returned when chainID has no registered contract configuration
+func ErrUnsupportedChain(chainID int64, supportedChains []int64) *UnsupportedChainError {
+	return &UnsupportedChainError{ChainID: chainID, SupportedChains: supportedChains}
+}
+
 // ErrSigningFailed is returned when signature generation fails
 func ErrSigningFailed(err error) *RelayerClientError {
 	return NewRelayerClientError("signature generation failed", err)
@@ -139,9 +996,283 @@ func ErrJSONUnmarshalFailed(err error) *RelayerClientError {
 	return NewRelayerClientError("JSON unmarshal failed", err)
 }
 
+// CodeTransactionNotFound is the Code of errors returned by ErrTransactionNotFound
+const CodeTransactionNotFound = "TRANSACTION_NOT_FOUND"
+
 // ErrTransactionNotFound is returned when a transaction is not found
 func ErrTransactionNotFound(transactionID string) *RelayerClientError {
-	return NewRelayerClientError(fmt.Sprintf("transaction not found: %s", transactionID), nil)
+	return NewRelayerClientErrorWithCode(fmt.Sprintf("transaction not found: %s", transactionID), CodeTransactionNotFound, nil)
+}
+
+// IsNotFound reports whether err is a relayer 404 or an ErrTransactionNotFound
+func IsNotFound(err error) bool {
+	var apiErr *RelayerApiError
+	if stderrors.As(err, &apiErr) {
+		return apiErr.StatusCode == 404
+	}
+	var clientErr *RelayerClientError
+	if stderrors.As(err, &clientErr) {
+		return clientErr.Code == CodeTransactionNotFound
+	}
+	return false
+}
+
+// CodeUnknownKey is the API error code of a 401 whose key ID the relayer does not recognise
+const CodeUnknownKey = "UNKNOWN_KEY"
+
+// IsUnknownKey reports whether err is a relayer 401 rejecting the request's key ID
+func IsUnknownKey(err error) bool {
+	var apiErr *RelayerApiError
+	return stderrors.As(err, &apiErr) && apiErr.StatusCode == 401 && apiErr.Code == CodeUnknownKey
+}
+
+// Limit names a relayer request size limit
+type Limit string
+
+const (
+	// LimitCalldata is the size in bytes of the signed Safe transaction's data
+	LimitCalldata Limit = "calldata"
+	// LimitMetadata is the size in bytes of the request metadata
+	LimitMetadata Limit = "metadata"
+	// LimitBatchCount is the number of transactions batched into one request
+	LimitBatchCount Limit = "batch count"
+)
+
+// LimitExceededError is returned before anything is sent when a request
+// exceeds one of the relayer's size limits
+type LimitExceededError struct {
+	// Limit is the limit that was exceeded
+	Limit Limit
+	// Max is the configured maximum
+	Max int
+	// Actual is the request's size
+	Actual int
+}
+
+// Error implements the error interface
+func (e *LimitExceededError) Error() string {
+	unit := " bytes"
+	if e.Limit == LimitBatchCount {
+		unit = ""
+	}
+	return fmt.Sprintf("relayer client error: %s is %d%s, over the relayer limit of %d%s", e.Limit, e.Actual, unit, e.Max, unit)
+}
+
+// ErrLimitExceeded is returned when a request exceeds a relayer size limit
+func ErrLimitExceeded(limit Limit, max, actual int) *LimitExceededError {
+	return &LimitExceededError{
+		Limit:  limit,
+		Max:    max,
+		Actual: actual,
+	}
+}
+
+// CodeQuotaExceeded is the API error code of a request rejected because the
+// builder's relayed-transaction quota is used up
+const CodeQuotaExceeded = "QUOTA_EXCEEDED"
+
+// QuotaExceededError is returned when the relayer rejects a request because
+// the builder's relayed-transaction quota is exhausted
+type QuotaExceededError struct {
+	// Limit is the quota, if the relayer reported it
+	Limit int64
+	// ResetAt is when the quota resets, if the relayer reported it
+	ResetAt *time.Time
+	// Err is the relayer's error response
+	Err error
+}
+
+// Error implements the error interface
+func (e *QuotaExceededError) Error() string {
+	msg := "relayer client error: builder quota exceeded"
+	if e.Limit > 0 {
+		msg += fmt.Sprintf(" (limit %d)", e.Limit)
+	}
+	if e.ResetAt != nil {
+		msg += fmt.Sprintf(", resets at %s", e.ResetAt.UTC().Format(time.RFC3339))
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+// Unwrap returns the relayer's error response
+func (e *QuotaExceededError) Unwrap() error {
+	return e.Err
+}
+
+// ErrQuotaExceeded is returned when a request fails because the builder quota is used up
+func ErrQuotaExceeded(limit int64, resetAt *time.Time, err error) *QuotaExceededError {
+	return &QuotaExceededError{
+		Limit:   limit,
+		ResetAt: resetAt,
+		Err:     err,
+	}
+}
+
+// CodeInsufficientPermissions is the API error code of a 403 rejecting a
+// request the builder API key has no scope for
+const CodeInsufficientPermissions = "INSUFFICIENT_PERMISSIONS"
+
+// InsufficientKeyPermissionsError is returned when the builder API key lacks
+// the scope a request needs, e.g. a read-only key submitting a transaction
+type InsufficientKeyPermissionsError struct {
+	// KeyID is the API key that was rejected
+	KeyID string
+	// Scope is the missing scope
+	Scope string
+	// Err is the relayer's error response, nil when the scope was reported
+	// missing by the key-info endpoint
+	Err error
+}
+
+// Error implements the error interface
+func (e *InsufficientKeyPermissionsError) Error() string {
+	msg := fmt.Sprintf("relayer client error: builder API key %q lacks the %q scope", e.KeyID, e.Scope)
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+// Unwrap returns the relayer's error response
+func (e *InsufficientKeyPermissionsError) Unwrap() error {
+	return e.Err
+}
+
+// ErrInsufficientKeyPermissions is returned when the builder API key lacks scope
+func ErrInsufficientKeyPermissions(keyID, scope string, err error) *InsufficientKeyPermissionsError {
+	return &InsufficientKeyPermissionsError{
+		KeyID: keyID,
+		Scope: scope,
+		Err:   err,
+	}
+}
+
+// SpendLimit names a spend policy limit
+type SpendLimit string
+
+const (
+	// SpendLimitPerTransaction caps the amount of a token one batch moves
+	SpendLimitPerTransaction SpendLimit = "per-transaction"
+	// SpendLimitWindow caps the amount of a token moved within the rolling window
+	SpendLimitWindow SpendLimit = "rolling window"
+)
+
+// SpendLimitExceededError is returned before signing when a batch would move
+// more of a token than the client's spend policy allows
+type SpendLimitExceededError struct {
+	// Token is the token contract address
+	Token string
+	// Limit is the limit that was exceeded
+	Limit SpendLimit
+	// Attempted is the amount the batch moves
+	Attempted *big.Int
+	// Total is the amount counted against the limit: Attempted for the
+	// per-transaction limit, Attempted plus earlier spends for the window
+	Total *big.Int
+	// Max is the configured limit
+	Max *big.Int
+	// Window is the length of the rolling window, for SpendLimitWindow
+	Window time.Duration
+}
+
+// Error implements the error interface
+func (e *SpendLimitExceededError) Error() string {
+	if e.Limit == SpendLimitWindow {
+		return fmt.Sprintf("relayer client error: batch moves %s of token %s, bringing the %s total to %s, over the rolling spend limit of %s", e.Attempted, e.Token, e.Window, e.Total, e.Max)
+	}
+	return fmt.Sprintf("relayer client error: batch moves %s of token %s, over the per-transaction spend limit of %s", e.Attempted, e.Token, e.Max)
+}
+
+// ErrSpendLimitExceeded is returned when a batch exceeds a spend policy limit
+func ErrSpendLimitExceeded(token string, limit SpendLimit, attempted, total, max *big.Int, window time.Duration) *SpendLimitExceededError {
+	return &SpendLimitExceededError{
+		Token:     token,
+		Limit:     limit,
+		Attempted: attempted,
+		Total:     total,
+		Max:       max,
+		Window:    window,
+	}
+}
+
+// PolicyDeniedError is returned before signing when the client's operation
+// policy denies an operation
+type PolicyDeniedError struct {
+	// Operation is the denied transaction type (SAFE or SAFE-CREATE)
+	Operation string
+	// Rule names the policy rule that denied it
+	Rule string
+	// Reason explains the denial
+	Reason string
+}
+
+// Error implements the error interface
+func (e *PolicyDeniedError) Error() string {
+	return fmt.Sprintf("relayer client error: policy denied %s operation (rule %s): %s", e.Operation, e.Rule, e.Reason)
+}
+
+// ErrPolicyDenied is returned when the operation policy denies an operation
+func ErrPolicyDenied(operation, rule, reason string) *PolicyDeniedError {
+	return &PolicyDeniedError{
+		Operation: operation,
+		Rule:      rule,
+		Reason:    reason,
+	}
+}
+
+// StaleNonceError is returned when a pre-built request's nonce no longer
+// matches the Safe's next nonce, so the relayer would reject it. The request
+// must be rebuilt and signed again with the current nonce.
+type StaleNonceError struct {
+	// RequestNonce is the nonce the request was signed with
+	RequestNonce string
+	// CurrentNonce is the Safe's next nonce as the relayer reports it
+	CurrentNonce string
+	// Delta is CurrentNonce minus RequestNonce: the number of transactions
+	// that consumed nonces since the request was built, negative when the
+	// request is more than one nonce ahead
+	Delta int64
+}
+
+// Error implements the error interface
+func (e *StaleNonceError) Error() string {
+	return fmt.Sprintf("relayer client error: stale nonce %s, the Safe's next nonce is %s (delta %d)", e.RequestNonce, e.CurrentNonce, e.Delta)
+}
+
+// ErrStaleNonce is returned when a request's nonce is not the Safe's next nonce
+func ErrStaleNonce(requestNonce, currentNonce string, delta int64) *StaleNonceError {
+	return &StaleNonceError{
+		RequestNonce: requestNonce,
+		CurrentNonce: currentNonce,
+		Delta:        delta,
+	}
+}
+
+// NonceNotYetCurrentError is returned when a pre-built request's nonce is
+// one ahead of the Safe's next nonce, as when another submission is still
+// pending. The request is valid once that submission is mined, so callers
+// can wait and retry instead of rebuilding it.
+type NonceNotYetCurrentError struct {
+	// RequestNonce is the nonce the request was signed with
+	RequestNonce string
+	// CurrentNonce is the Safe's next nonce as the relayer reports it
+	CurrentNonce string
+}
+
+// Error implements the error interface
+func (e *NonceNotYetCurrentError) Error() string {
+	return fmt.Sprintf("relayer client error: nonce %s is not yet current, the Safe's next nonce is %s", e.RequestNonce, e.CurrentNonce)
+}
+
+// ErrNonceNotYetCurrent is returned when a request's nonce is one ahead of the Safe's next nonce
+func ErrNonceNotYetCurrent(requestNonce, currentNonce string) *NonceNotYetCurrentError {
+	return &NonceNotYetCurrentError{
+		RequestNonce: requestNonce,
+		CurrentNonce: currentNonce,
+	}
 }
 
 // ErrTransactionFailed is returned when a transaction fails
@@ -149,6 +1280,25 @@ func ErrTransactionFailed(transactionID string, reason string) *RelayerClientErr
 	return NewRelayerClientError(fmt.Sprintf("transaction %s failed: %s", transactionID, reason), nil)
 }
 
+// ErrSubmissionFailed wraps a failed submission with the locally computed
+// request context so a single log line identifies the attempt
+func ErrSubmissionFailed(requestType, safeAddress, nonce string, err error) *RelayerClientError {
+	if nonce == "" {
+		nonce = "n/a"
+	}
+	return NewRelayerClientError(fmt.Sprintf("submit %s transaction failed (safe %s, nonce %s)", requestType, safeAddress, nonce), err)
+}
+
+// ErrSubmissionStoreFailed is returned when the submission store cannot record a submission
+func ErrSubmissionStoreFailed(action string, err error) *RelayerClientError {
+	return NewRelayerClientError(fmt.Sprintf("submission store %s failed", action), err)
+}
+
+// ErrNotCancellable is returned when a transaction has progressed past the point where it can be cancelled
+func ErrNotCancellable(transactionID, state string) *RelayerClientError {
+	return NewRelayerClientError(fmt.Sprintf("transaction %s cannot be cancelled in state %s", transactionID, state), nil)
+}
+
 // ErrPollingTimeout is returned when polling times out
 func ErrPollingTimeout(transactionID string) *RelayerClientError {
 	return NewRelayerClientError(fmt.Sprintf("polling timeout for transaction: %s", transactionID), nil)
@@ -164,6 +1314,60 @@ func ErrMissingRequiredField(fieldName string) *RelayerClientError {
 	return NewRelayerClientError(fmt.Sprintf("missing required field: %s", fieldName), nil)
 }
 
+// ErrInvalidTypedData is returned when EIP-712 typed data cannot be encoded
+func ErrInvalidTypedData(reason string) *RelayerClientError {
+	return NewRelayerClientError(fmt.Sprintf("invalid typed data: %s", reason), nil)
+}
+
+// ErrInvalidSafeSetup is returned when Safe.setup() parameters are invalid
+func ErrInvalidSafeSetup(reason string) *RelayerClientError {
+	return NewRelayerClientError(fmt.Sprintf("invalid Safe setup: %s", reason), nil)
+}
+
+// ErrInvalidGasLimit is returned when a SafeTransaction GasLimit cannot be applied
+func ErrInvalidGasLimit(reason string) *RelayerClientError {
+	return NewRelayerClientError(fmt.Sprintf("invalid gas limit: %s", reason), nil)
+}
+
+// ErrDelegateCallNotAllowed is returned when a call-only batch contains DelegateCall transactions
+func ErrDelegateCallNotAllowed(indices []int) *RelayerClientError {
+	return NewRelayerClientError(fmt.Sprintf("MultiSendCallOnly does not allow DelegateCall: transactions at indices %v use DelegateCall", indices), nil)
+}
+
+// ErrSelfExecNotAllowed is returned when a transaction calls one of its own Safe's execution or setup functions
+func ErrSelfExecNotAllowed(index int, selector, method string) *RelayerClientError {
+	return NewRelayerClientError(fmt.Sprintf("transaction at index %d calls %s (%s) on the Safe itself; set AllowSelfExec to allow it", index, method, selector), nil)
+}
+
+// ErrUnsupportedRequestVersion is returned for a submission payload version the client cannot handle
+func ErrUnsupportedRequestVersion(version int) *RelayerClientError {
+	return NewRelayerClientError(fmt.Sprintf("unsupported request version: v%d", version), nil)
+}
+
+// ErrBodyNotAllowed is returned when a request whose method carries no body,
+// such as GET, is given one
+func ErrBodyNotAllowed(method, path string) *RelayerClientError {
+	return NewRelayerClientError(fmt.Sprintf("%s %s must not have a body", method, path), nil)
+}
+
+// ErrSafeTxHashMismatch is returned when an imported safeTxHash is not the hash of the transaction's fields
+func ErrSafeTxHashMismatch(declared, computed string) *RelayerClientError {
+	return NewRelayerClientError(fmt.Sprintf("safeTxHash %s does not match the recomputed %s", declared, computed), nil)
+}
+
+// ErrPartialBuilderCredentials is returned when some but not all builder
+// credentials are configured
+func ErrPartialBuilderCredentials(set, missing []string) *RelayerClientError {
+	return NewRelayerClientError(fmt.Sprintf("builder credentials are partially configured: %s set but %s missing",
+		strings.Join(set, ", "), strings.Join(missing, ", ")), nil)
+}
+
+// ErrTypedValueMismatch is returned when a string field and its typed
+// counterpart (such as Value and ValueInt) are both set but disagree
+func ErrTypedValueMismatch(field, text, typed string) *RelayerClientError {
+	return NewRelayerClientError(fmt.Sprintf("%s %q does not match its typed value %s", field, text, typed), nil)
+}
+
 // ErrInvalidConfiguration is returned when configuration is invalid
 func ErrInvalidConfiguration(reason string) *RelayerClientError {
 	return NewRelayerClientError(fmt.Sprintf("invalid configuration: %s", reason), nil)
@@ -1,8 +1,12 @@
 package errors
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"math/big"
 	"testing"
+	"time"
 )
 
 func TestRelayerClientError_Error(t *testing.T) {
@@ -96,3 +100,174 @@ func TestCommonErrors(t *testing.T) {
 		})
 	}
 }
+
+func TestUnsupportedChainError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{
+			name:     "with supported chains",
+			err:      ErrUnsupportedChain(1, []int64{137, 80002}),
+			expected: "relayer client error: unsupported chain ID: 1 (supported: 137, 80002; register other chains with config.AddChainConfig)",
+		},
+		{
+			name:     "none registered",
+			err:      ErrUnsupportedChain(1, nil),
+			expected: "relayer client error: unsupported chain ID: 1 (supported: none; register other chains with config.AddChainConfig)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.err.Error(); got != tt.expected {
+				t.Errorf("Error() = %v, want %v", got, tt.expected)
+			}
+			var chainErr *UnsupportedChainError
+			if !errors.As(fmt.Errorf("wrapped: %w", tt.err), &chainErr) || chainErr.ChainID != 1 {
+				t.Errorf("errors.As() did not extract the UnsupportedChainError")
+			}
+		})
+	}
+}
+
+func TestMultiWaitError(t *testing.T) {
+	failed := ErrTransactionFailed("tx-b", "STATE_FAILED")
+	err := ErrMultiWait([]string{"tx-a", "tx-b", "tx-c"}, []error{nil, failed, ErrPollingTimeout("tx-c")})
+
+	expected := "relayer client error: 2 of 3 transactions did not reach a target state: " +
+		"tx-b: " + failed.Error() + "; tx-c: relayer client error: polling timeout for transaction: tx-c"
+	if got := err.Error(); got != expected {
+		t.Errorf("Error() = %v, want %v", got, expected)
+	}
+	if err.Failed() != 2 {
+		t.Errorf("Failed() = %d, want 2", err.Failed())
+	}
+	if !errors.Is(err, failed) {
+		t.Error("errors.Is should find the individual errors")
+	}
+}
+
+func TestSpendLimitExceededError(t *testing.T) {
+	const usdc = "0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174"
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{
+			name:     "per transaction",
+			err:      ErrSpendLimitExceeded(usdc, SpendLimitPerTransaction, big.NewInt(150), big.NewInt(150), big.NewInt(100), 0),
+			expected: "relayer client error: batch moves 150 of token " + usdc + ", over the per-transaction spend limit of 100",
+		},
+		{
+			name:     "rolling window",
+			err:      ErrSpendLimitExceeded(usdc, SpendLimitWindow, big.NewInt(60), big.NewInt(260), big.NewInt(250), 24*time.Hour),
+			expected: "relayer client error: batch moves 60 of token " + usdc + ", bringing the 24h0m0s total to 260, over the rolling spend limit of 250",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.err.Error(); got != tt.expected {
+				t.Errorf("Error() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestOperationTimeoutError(t *testing.T) {
+	err := ErrOperationTimeout("Execute", "submit", []string{"nonce", "build"}, 0)
+
+	expected := `relayer client error: Execute timed out after 0s during step "submit" (completed: nonce, build)`
+	if got := err.Error(); got != expected {
+		t.Errorf("Error() = %v, want %v", got, expected)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Error("OperationTimeoutError should unwrap to context.DeadlineExceeded")
+	}
+}
+
+func TestWithOperationID(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"client error", NewRelayerClientError("boom", nil), "op-1"},
+		{"wrapped client error", fmt.Errorf("context: %w", ErrSubmissionFailed("SAFE", "0xabc", "1", nil)), "op-1"},
+		{"timeout error", ErrOperationTimeout("Execute", "nonce", nil, time.Second), "op-1"},
+		{"api error", NewRelayerApiError(500, "boom"), ""},
+		{"sentinel", ErrSignerNotConfigured, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := WithOperationID(tt.err, "op-1")
+			if err != tt.err {
+				t.Error("WithOperationID should return the same error")
+			}
+			if got := OperationIDOf(err); got != tt.want {
+				t.Errorf("OperationIDOf() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	sentinels := []*RelayerClientError{
+		ErrSignerNotConfigured, ErrBuilderCredsNotConfigured, ErrSubmissionStoreNotConfigured,
+		ErrUsageUnavailable, ErrKeyInfoUnavailable, ErrNoPendingDeployment,
+		ErrClientClosed, ErrQueueClosed, ErrCertificatePinMismatch,
+	}
+	for _, sentinel := range sentinels {
+		WithOperationID(fmt.Errorf("context: %w", sentinel), "op-1")
+		if sentinel.OperationID != "" {
+			t.Errorf("sentinel %q was tagged", sentinel.Message)
+		}
+	}
+	if WithOperationID(nil, "op-1") != nil {
+		t.Error("WithOperationID(nil) should be nil")
+	}
+}
+
+func TestPartialExecutionError(t *testing.T) {
+	cause := NewRelayerApiError(503, "unavailable")
+	err := ErrPartialExecution(5, 2, "7", cause)
+
+	if !errors.Is(err, cause) {
+		t.Error("PartialExecutionError should unwrap to the submission error")
+	}
+	expected := "relayer client error: independent execution stopped at transaction 2 of 5 (nonce 7), 2 submitted: " + cause.Error()
+	if err.Error() != expected {
+		t.Errorf("Error() = %q, want %q", err.Error(), expected)
+	}
+}
+
+func TestErrStaged(t *testing.T) {
+	cause := NewRelayerClientError("boom", nil)
+
+	tests := []struct {
+		name string
+		err  error
+		want Stage
+	}{
+		{"wraps", ErrStaged(StageSubmit, cause), StageSubmit},
+		{"innermost stage wins", ErrStaged(StageBuild, fmt.Errorf("context: %w", ErrStaged(StageSign, cause))), StageSign},
+		{"unstaged", cause, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StageOf(tt.err); got != tt.want {
+				t.Errorf("StageOf() = %q, want %q", got, tt.want)
+			}
+			if !errors.Is(tt.err, cause) {
+				t.Error("staged error should unwrap to the cause")
+			}
+		})
+	}
+
+	if ErrStaged(StageNonce, nil) != nil {
+		t.Error("ErrStaged(nil) should be nil")
+	}
+}
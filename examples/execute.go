@@ -10,7 +10,6 @@ import (
 	"github.com/davidt58/go-builder-relayer-client/client"
 	"github.com/davidt58/go-builder-relayer-client/config"
 	"github.com/davidt58/go-builder-relayer-client/models"
-	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/joho/godotenv"
 )
@@ -18,14 +17,6 @@ import (
 // MaxUint256 is the maximum value for uint256
 var MaxUint256 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
 
-// Contract addresses for Polygon mainnet
-var (
-	USDC            = common.HexToAddress("0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174")
-	CTFExchange     = common.HexToAddress("0x4bFb41d5B3570DeFd03C39a9A4D8dE6Bd8B8982E")
-	NegRiskCTF      = common.HexToAddress("0xC5d563A36AE78145C45a50134d48A1215220f80a")
-	NegRiskAdapter  = common.HexToAddress("0xd91E80cF2E7be2e162c6513ceD06f1dD0dA35296")
-)
-
 func parseInt64(s string) int64 {
 	val, err := strconv.ParseInt(s, 10, 64)
 	if err != nil {
@@ -34,49 +25,16 @@ func parseInt64(s string) int64 {
 	return val
 }
 
-// encodeApprove encodes an ERC20 approve(address,uint256) function call
-func encodeApprove(spender common.Address, amount *big.Int) (string, error) {
-	// Define the approve function ABI
-	addressType, _ := abi.NewType("address", "", nil)
-	uint256Type, _ := abi.NewType("uint256", "", nil)
-
-	approveMethod := abi.NewMethod(
-		"approve",
-		"approve",
-		abi.Function,
-		"nonpayable",
-		false,
-		false,
-		abi.Arguments{
-			{Name: "spender", Type: addressType},
-			{Name: "amount", Type: uint256Type},
-		},
-		nil,
-	)
-
-	// Pack the function call
-	data, err := approveMethod.Inputs.Pack(spender, amount)
-	if err != nil {
-		return "", fmt.Errorf("failed to pack approve arguments: %w", err)
-	}
-
-	// Prepend function selector (first 4 bytes of keccak256("approve(address,uint256)"))
-	return fmt.Sprintf("0x%x%x", approveMethod.ID, data), nil
-}
+// erc20ApproveABI is the ABI fragment for ERC20 approve(address,uint256)
+const erc20ApproveABI = `[{"name":"approve","type":"function","inputs":[{"name":"spender","type":"address"},{"name":"amount","type":"uint256"}],"outputs":[{"name":"","type":"bool"}]}]`
 
 // createUSDCApproveTxn creates a SafeTransaction for approving USDC spending
 func createUSDCApproveTxn(token, spender common.Address) (models.SafeTransaction, error) {
-	data, err := encodeApprove(spender, MaxUint256)
-	if err != nil {
-		return models.SafeTransaction{}, err
-	}
-
-	return models.SafeTransaction{
-		To:        token.Hex(),
-		Operation: models.Call,
-		Data:      data,
-		Value:     "0",
-	}, nil
+	return models.NewTxBuilder().
+		ToAddress(token).
+		FromABICall(erc20ApproveABI, "approve", spender, MaxUint256).
+		Call().
+		Build()
 }
 
 func main() {
@@ -86,10 +44,8 @@ func main() {
 		log.Println("Warning: .env file not found")
 	}
 
+	// Empty uses the relayer registered for the chain
 	relayerURL := os.Getenv("RELAYER_URL")
-	if relayerURL == "" {
-		relayerURL = "https://relayer-v2.polymarket.com"
-	}
 
 	chainIDStr := os.Getenv("CHAIN_ID")
 	if chainIDStr == "" {
@@ -113,20 +69,27 @@ func main() {
 		log.Fatal(err)
 	}
 
+	// Look up the Polymarket contracts for this chain
+	contracts, err := config.GetKnownContracts(chainID)
+	if err != nil {
+		log.Fatal(err)
+	}
+	usdc := common.HexToAddress(contracts.USDC)
+
 	// Create approval transactions for all 3 contracts
-	txn1, err := createUSDCApproveTxn(USDC, CTFExchange)
+	txn1, err := createUSDCApproveTxn(usdc, common.HexToAddress(contracts.CTFExchange))
 	if err != nil {
 		log.Fatalf("Failed to create CTF Exchange approval: %v", err)
 	}
 	fmt.Printf("CTF Exchange approval data: %s\n", txn1.Data)
 
-	txn2, err := createUSDCApproveTxn(USDC, NegRiskCTF)
+	txn2, err := createUSDCApproveTxn(usdc, common.HexToAddress(contracts.NegRiskCTFExchange))
 	if err != nil {
 		log.Fatalf("Failed to create NegRisk CTF approval: %v", err)
 	}
 	fmt.Printf("NegRisk CTF approval data: %s\n", txn2.Data)
 
-	txn3, err := createUSDCApproveTxn(USDC, NegRiskAdapter)
+	txn3, err := createUSDCApproveTxn(usdc, common.HexToAddress(contracts.NegRiskAdapter))
 	if err != nil {
 		log.Fatalf("Failed to create NegRisk Adapter approval: %v", err)
 	}
@@ -2,65 +2,116 @@ package http
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"io"
 	"net/http"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/davidt58/go-builder-relayer-client/errors"
 	"github.com/davidt58/go-builder-relayer-client/models"
+	"github.com/davidt58/go-builder-relayer-client/redact"
 )
 
+// DefaultMaxResponseSize is the largest response body read unless overridden
+const DefaultMaxResponseSize int64 = 4 << 20
+
 // Client is a wrapper around http.Client with custom error handling
 type Client struct {
 	httpClient *http.Client
 	baseURL    string
+	// maxResponseSize caps how much of a response body is read
+	maxResponseSize int64
+	// responseHooks see every response before its body is read
+	responseHooks []func(*http.Response)
+	// envelope is how response payloads are wrapped
+	envelope ResponseEnvelope
+	// closed makes every request fail with errors.ErrClientClosed (see Close)
+	closed atomic.Bool
 }
 
 // NewClient creates a new HTTP client
 func NewClient(baseURL string) *Client {
-	return &Client{
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
-		baseURL: baseURL,
-	}
+	return NewClientWithTimeout(baseURL, 30*time.Second)
 }
 
 // NewClientWithTimeout creates a new HTTP client with a custom timeout
 func NewClientWithTimeout(baseURL string, timeout time.Duration) *Client {
 	return &Client{
 		httpClient: &http.Client{
-			Timeout: timeout,
+			Timeout:       timeout,
+			Transport:     newPooledTransport(),
+			CheckRedirect: refuseRedirects,
 		},
-		baseURL: baseURL,
+		baseURL:         NormalizeURL(baseURL),
+		maxResponseSize: DefaultMaxResponseSize,
 	}
 }
 
 // Request performs an HTTP request with the given parameters
 func (c *Client) Request(method, path string, headers map[string]string, body interface{}) ([]byte, error) {
+	return c.RequestContext(context.Background(), method, path, headers, body)
+}
+
+// RequestContext performs an HTTP request that is aborted when ctx is done
+func (c *Client) RequestContext(ctx context.Context, method, path string, headers map[string]string, body interface{}) ([]byte, error) {
+	resp, respBody, err := c.do(ctx, method, path, headers, body)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check for error status codes and unwrap any envelope
+	return c.payload(resp.StatusCode, respBody)
+}
+
+// do sends a request and reads the response body, whatever its status
+func (c *Client) do(ctx context.Context, method, path string, headers map[string]string, body interface{}) (*http.Response, []byte, error) {
+	resp, buf, err := c.exchange(ctx, method, path, headers, body)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer releaseBuffer(buf)
+
+	// The caller keeps the body, so it gets a copy of the pooled buffer
+	respBody := make([]byte, buf.Len())
+	copy(respBody, buf.Bytes())
+	return resp, respBody, nil
+}
+
+// exchange is do reading the body into a pooled buffer, which the caller
+// must pass to releaseBuffer once done with it
+func (c *Client) exchange(ctx context.Context, method, path string, headers map[string]string, body interface{}) (*http.Response, *bytes.Buffer, error) {
+	if c.closed.Load() {
+		return nil, nil, errors.ErrClientClosed
+	}
+
 	// Construct full URL
-	url := c.baseURL + path
+	url := JoinURL(c.baseURL, path)
 
 	// Marshal body if present
 	var bodyReader io.Reader
 	if body != nil {
 		bodyBytes, err := json.Marshal(body)
 		if err != nil {
-			return nil, errors.ErrJSONMarshalFailed(err)
+			return nil, nil, errors.ErrJSONMarshalFailed(err)
 		}
 		bodyReader = bytes.NewReader(bodyBytes)
 	}
 
 	// Create request
-	req, err := http.NewRequest(method, url, bodyReader)
+	req, err := http.NewRequestWithContext(ctx, method, url, bodyReader)
 	if err != nil {
-		return nil, errors.ErrHTTPRequestFailed(err)
+		return nil, nil, errors.ErrHTTPRequestFailed(err)
 	}
 
 	// Set default headers
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json")
+	if operationID := OperationIDFromContext(ctx); operationID != "" {
+		req.Header.Set(OperationIDHeader, operationID)
+	}
 
 	// Set custom headers
 	for key, value := range headers {
@@ -70,22 +121,21 @@ func (c *Client) Request(method, path string, headers map[string]string, body in
 	// Execute request
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, errors.ErrHTTPRequestFailed(err)
+		return nil, nil, errors.ErrHTTPRequestFailed(err)
 	}
-	defer resp.Body.Close()
+	defer c.drainAndClose(resp.Body)
 
-	// Read response body
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, errors.ErrHTTPRequestFailed(err)
+	for _, hook := range c.responseHooks {
+		hook(resp)
 	}
 
-	// Check for error status codes
-	if resp.StatusCode >= 400 {
-		return nil, parseAPIError(resp.StatusCode, respBody)
+	// Read response body, refusing anything over the size limit
+	buf, err := c.readBody(resp)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	return respBody, nil
+	return resp, buf, nil
 }
 
 // Get performs a GET request
@@ -110,29 +160,207 @@ func (c *Client) Delete(path string, headers map[string]string) ([]byte, error)
 
 // GetJSON performs a GET request and unmarshals the response into the target
 func (c *Client) GetJSON(path string, headers map[string]string, target interface{}) error {
-	data, err := c.Get(path, headers)
+	return c.GetJSONContext(context.Background(), path, headers, target)
+}
+
+// GetJSONContext performs a GET request bound to ctx and unmarshals the response into the target
+func (c *Client) GetJSONContext(ctx context.Context, path string, headers map[string]string, target interface{}) error {
+	return c.requestJSON(ctx, http.MethodGet, path, headers, nil, target)
+}
+
+// requestJSON performs a request and unmarshals the response into target
+// straight from a pooled buffer
+func (c *Client) requestJSON(ctx context.Context, method, path string, headers map[string]string, body interface{}, target interface{}) error {
+	resp, buf, err := c.exchange(ctx, method, path, headers, body)
 	if err != nil {
 		return err
 	}
+	defer releaseBuffer(buf)
 
-	if err := json.Unmarshal(data, target); err != nil {
+	payload, err := c.payload(resp.StatusCode, buf.Bytes())
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(payload, target); err != nil {
 		return errors.ErrJSONUnmarshalFailed(err)
 	}
 
 	return nil
 }
 
+// Validators are the cache validators of a response, sent back in a
+// conditional request as If-None-Match and If-Modified-Since
+type Validators struct {
+	// ETag is the response's ETag header
+	ETag string
+	// LastModified is the response's Last-Modified header
+	LastModified string
+}
+
+// IsZero reports whether there are no validators to send
+func (v Validators) IsZero() bool {
+	return v.ETag == "" && v.LastModified == ""
+}
+
+// ConditionalResponse is the result of GetConditionalContext
+type ConditionalResponse struct {
+	// Body is the response body; empty when NotModified
+	Body []byte
+	// Validators are the response's validators; on a 304 without them, the
+	// ones that were sent
+	Validators Validators
+	// NotModified reports a 304: the resource still matches the validators
+	NotModified bool
+}
+
+// GetConditionalContext performs a GET bound to ctx that sends the non-empty
+// validators as If-None-Match and If-Modified-Since. A 304 is reported as
+// NotModified rather than as an error.
+func (c *Client) GetConditionalContext(ctx context.Context, path string, headers map[string]string, validators Validators) (*ConditionalResponse, error) {
+	conditional := MergeHeaders(headers)
+	if validators.ETag != "" {
+		conditional["If-None-Match"] = validators.ETag
+	}
+	if validators.LastModified != "" {
+		conditional["If-Modified-Since"] = validators.LastModified
+	}
+
+	resp, body, err := c.do(ctx, http.MethodGet, path, conditional, nil)
+	if err != nil {
+		return nil, err
+	}
+	result := &ConditionalResponse{}
+	result.Validators, result.Body, result.NotModified, err = c.conditionalResult(resp, body, validators)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetConditionalJSONContext is GetConditionalContext unmarshalling the
+// response into target, which is left untouched on a 304. The body is read
+// into a pooled buffer, so polling loops do not allocate one per response.
+func (c *Client) GetConditionalJSONContext(ctx context.Context, path string, headers map[string]string, validators Validators, target interface{}) (Validators, bool, error) {
+	conditional := MergeHeaders(headers)
+	if validators.ETag != "" {
+		conditional["If-None-Match"] = validators.ETag
+	}
+	if validators.LastModified != "" {
+		conditional["If-Modified-Since"] = validators.LastModified
+	}
+
+	resp, buf, err := c.exchange(ctx, http.MethodGet, path, conditional, nil)
+	if err != nil {
+		return Validators{}, false, err
+	}
+	defer releaseBuffer(buf)
+
+	received, payload, notModified, err := c.conditionalResult(resp, buf.Bytes(), validators)
+	if err != nil || notModified {
+		return received, notModified, err
+	}
+	if err := json.Unmarshal(payload, target); err != nil {
+		return Validators{}, false, errors.ErrJSONUnmarshalFailed(err)
+	}
+	return received, false, nil
+}
+
+// conditionalResult returns the validators and payload of the response to
+// a conditional request and whether it was a 304, which has no payload. On
+// a 304 without an ETag, the validators sent are kept.
+func (c *Client) conditionalResult(resp *http.Response, body []byte, sent Validators) (Validators, []byte, bool, error) {
+	if resp.StatusCode == http.StatusNotModified {
+		if etag := resp.Header.Get("ETag"); etag != "" {
+			sent.ETag = etag
+		}
+		return sent, nil, true, nil
+	}
+	payload, err := c.payload(resp.StatusCode, body)
+	if err != nil {
+		return Validators{}, nil, false, err
+	}
+	return Validators{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}, payload, false, nil
+}
+
 // PostJSON performs a POST request and unmarshals the response into the target
 func (c *Client) PostJSON(path string, headers map[string]string, body interface{}, target interface{}) error {
-	data, err := c.Post(path, headers, body)
-	if err != nil {
-		return err
+	return c.PostJSONContext(context.Background(), path, headers, body, target)
+}
+
+// PostJSONContext performs a POST request bound to ctx and unmarshals the response into the target
+func (c *Client) PostJSONContext(ctx context.Context, path string, headers map[string]string, body interface{}, target interface{}) error {
+	return c.requestJSON(ctx, http.MethodPost, path, headers, body, target)
+}
+
+// maxPooledBuffer is the largest response buffer returned to the pool, so
+// one large response does not pin its memory for every later request
+const maxPooledBuffer = 64 << 10
+
+// responseBuffers pools the buffers response bodies are read into
+var responseBuffers = sync.Pool{
+	New: func() interface{} { return new(bytes.Buffer) },
+}
+
+// releaseBuffer returns a buffer from readBody to the pool
+func releaseBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBuffer {
+		return
 	}
+	buf.Reset()
+	responseBuffers.Put(buf)
+}
 
-	if err := json.Unmarshal(data, target); err != nil {
-		return errors.ErrJSONUnmarshalFailed(err)
+// readBody reads resp's body into a pooled buffer, failing with a
+// ResponseTooLargeError once it exceeds the client's maximum response size
+func (c *Client) readBody(resp *http.Response) (*bytes.Buffer, error) {
+	limit := c.responseLimit()
+	if resp.ContentLength > limit {
+		return nil, errors.ErrResponseTooLarge(limit)
+	}
+
+	buf := responseBuffers.Get().(*bytes.Buffer)
+	if _, err := buf.ReadFrom(io.LimitReader(resp.Body, limit+1)); err != nil {
+		releaseBuffer(buf)
+		return nil, errors.ErrHTTPRequestFailed(err)
+	}
+	if int64(buf.Len()) > limit {
+		releaseBuffer(buf)
+		return nil, errors.ErrResponseTooLarge(limit)
 	}
+	return buf, nil
+}
+
+// drainAndClose discards what is left of a response body, up to the size
+// limit, so the connection can be reused, then closes it
+func (c *Client) drainAndClose(body io.ReadCloser) {
+	io.Copy(io.Discard, io.LimitReader(body, c.responseLimit()))
+	body.Close()
+}
+
+// responseLimit returns the maximum response size
+func (c *Client) responseLimit() int64 {
+	if c.maxResponseSize <= 0 {
+		return DefaultMaxResponseSize
+	}
+	return c.maxResponseSize
+}
 
+// refuseRedirects is the default redirect policy: builder headers are signed
+// for the relayer, so requests are never replayed to another URL
+func refuseRedirects(req *http.Request, via []*http.Request) error {
+	return errors.ErrRedirectRefused(via[len(via)-1].URL.String(), req.URL.String())
+}
+
+// sameHostRedirects follows up to 10 redirects that stay on the original
+// scheme and host
+func sameHostRedirects(req *http.Request, via []*http.Request) error {
+	origin := via[0].URL
+	if req.URL.Scheme != origin.Scheme || req.URL.Host != origin.Host || len(via) >= 10 {
+		return errors.ErrRedirectRefused(via[len(via)-1].URL.String(), req.URL.String())
+	}
 	return nil
 }
 
@@ -140,16 +368,22 @@ func (c *Client) PostJSON(path string, headers map[string]string, body interface
 func parseAPIError(statusCode int, body []byte) error {
 	var errorResp models.ErrorResponse
 	if err := json.Unmarshal(body, &errorResp); err != nil {
-		// If we can't parse the error response, return a generic error
-		return errors.NewRelayerApiError(statusCode, string(body))
+		// If we can't parse the error response, return a generic error; the
+		// body may echo the request, so it is scrubbed
+		return errors.NewRelayerApiError(statusCode, redact.Default().Scrub(string(body)))
 	}
 
-	// Create a detailed error from the parsed response
-	if errorResp.Code != nil {
-		return errors.NewRelayerApiErrorWithDetails(statusCode, errorResp.Error, *errorResp.Code, errorResp.Details)
+	// Create a detailed error from the parsed response, including any
+	// field-level validation errors found in details
+	if errorResp.Code != nil || errorResp.Details != nil {
+		code := ""
+		if errorResp.Code != nil {
+			code = *errorResp.Code
+		}
+		return errors.NewRelayerApiErrorWithDetails(statusCode, redact.Default().Scrub(errorResp.Error), code, errorResp.Details)
 	}
 
-	return errors.NewRelayerApiError(statusCode, errorResp.Error)
+	return errors.NewRelayerApiError(statusCode, redact.Default().Scrub(errorResp.Error))
 }
 
 // SetTimeout sets the HTTP client timeout
@@ -157,6 +391,19 @@ func (c *Client) SetTimeout(timeout time.Duration) {
 	c.httpClient.Timeout = timeout
 }
 
+// CloseIdleConnections closes the connections kept open for reuse, e.g.
+// on shutdown. Connections in use are not interrupted.
+func (c *Client) CloseIdleConnections() {
+	c.httpClient.CloseIdleConnections()
+}
+
+// Close makes every later request fail with errors.ErrClientClosed and
+// closes the idle connections. Requests in flight are not interrupted.
+func (c *Client) Close() {
+	c.closed.Store(true)
+	c.httpClient.CloseIdleConnections()
+}
+
 // GetBaseURL returns the base URL
 func (c *Client) GetBaseURL() string {
 	return c.baseURL
@@ -164,5 +411,16 @@ func (c *Client) GetBaseURL() string {
 
 // SetBaseURL sets the base URL
 func (c *Client) SetBaseURL(baseURL string) {
-	c.baseURL = baseURL
+	c.baseURL = NormalizeURL(baseURL)
+}
+
+// RequestPath returns the path and query string a request for path is sent
+// to, including any path prefix of the base URL. Request signatures must
+// cover this path rather than path itself.
+func (c *Client) RequestPath(path string) string {
+	u, err := resolveURL(c.baseURL, path)
+	if err != nil {
+		return path
+	}
+	return u.RequestURI()
 }
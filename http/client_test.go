@@ -1,11 +1,22 @@
 package http
 
 import (
+	"context"
 	"encoding/json"
+	stderrors "errors"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/davidt58/go-builder-relayer-client/errors"
 )
 
 func TestNewClient(t *testing.T) {
@@ -147,6 +158,76 @@ func TestClient_PostJSON(t *testing.T) {
 	}
 }
 
+func TestClient_GetConditional(t *testing.T) {
+	const lastModified = "Wed, 14 Oct 2026 08:00:00 GMT"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/etag":
+			w.Header().Set("ETag", `"v1"`)
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		case "/last-modified":
+			w.Header().Set("Last-Modified", lastModified)
+			if r.Header.Get("If-Modified-Since") == lastModified {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]string{"error": "not found"})
+			return
+		}
+		w.Write([]byte(`{"state":"STATE_NEW"}`))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name            string
+		path            string
+		validators      Validators
+		wantNotModified bool
+		wantValidators  Validators
+		shouldErr       bool
+	}{
+		{name: "no validators", path: "/etag", wantValidators: Validators{ETag: `"v1"`}},
+		{name: "matching ETag", path: "/etag", validators: Validators{ETag: `"v1"`}, wantNotModified: true, wantValidators: Validators{ETag: `"v1"`}},
+		{name: "stale ETag", path: "/etag", validators: Validators{ETag: `"v0"`}, wantValidators: Validators{ETag: `"v1"`}},
+		{
+			name:            "matching Last-Modified",
+			path:            "/last-modified",
+			validators:      Validators{LastModified: lastModified},
+			wantNotModified: true,
+			wantValidators:  Validators{LastModified: lastModified},
+		},
+		{name: "unsupported", path: "/plain", validators: Validators{ETag: `"v1"`}},
+		{name: "error status", path: "/missing", shouldErr: true},
+	}
+
+	client := NewClient(server.URL)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := client.GetConditionalContext(context.Background(), tt.path, nil, tt.validators)
+			if (err != nil) != tt.shouldErr {
+				t.Fatalf("GetConditionalContext() error = %v, shouldErr %v", err, tt.shouldErr)
+			}
+			if err != nil {
+				return
+			}
+			if resp.NotModified != tt.wantNotModified {
+				t.Errorf("NotModified = %v, want %v", resp.NotModified, tt.wantNotModified)
+			}
+			if resp.NotModified == (len(resp.Body) > 0) {
+				t.Errorf("Body = %q with NotModified %v", resp.Body, resp.NotModified)
+			}
+			if resp.Validators != tt.wantValidators {
+				t.Errorf("Validators = %+v, want %+v", resp.Validators, tt.wantValidators)
+			}
+		})
+	}
+}
+
 func TestClient_ErrorHandling(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusBadRequest)
@@ -196,6 +277,28 @@ func TestClient_SetTimeout(t *testing.T) {
 	}
 }
 
+func TestClient_Close(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	if _, err := client.Get("/test", nil); err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	client.Close()
+
+	if _, err := client.Get("/test", nil); !stderrors.Is(err, errors.ErrClientClosed) {
+		t.Errorf("Get after Close = %v, want ErrClientClosed", err)
+	}
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Errorf("server got %d requests, want 1", n)
+	}
+}
+
 func TestClient_SetBaseURL(t *testing.T) {
 	client := NewClient("https://api.example.com")
 	newURL := "https://new-api.example.com"
@@ -205,3 +308,264 @@ func TestClient_SetBaseURL(t *testing.T) {
 		t.Errorf("BaseURL = %s, want %s", client.GetBaseURL(), newURL)
 	}
 }
+
+func TestClient_BaseURLJoining(t *testing.T) {
+	var gotURI string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotURI = r.URL.RequestURI()
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		base string
+		path string
+		want string
+	}{
+		{server.URL, "/nonce", "/nonce"},
+		{server.URL + "/", "/nonce", "/nonce"},
+		{server.URL + "/", "nonce", "/nonce"},
+		{server.URL + "/api", "/nonce", "/api/nonce"},
+		{server.URL + "/api/", "nonce", "/api/nonce"},
+		{server.URL + "/api/", "/nonce?address=0xabc&type=SAFE", "/api/nonce?address=0xabc&type=SAFE"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.base+" "+tt.path, func(t *testing.T) {
+			client := NewClient(tt.base)
+			if _, err := client.Get(tt.path, nil); err != nil {
+				t.Fatalf("Get failed: %v", err)
+			}
+			if gotURI != tt.want {
+				t.Errorf("request sent to %s, want %s", gotURI, tt.want)
+			}
+			if got := client.RequestPath(tt.path); got != gotURI {
+				t.Errorf("RequestPath() = %s, want the path sent %s", got, gotURI)
+			}
+		})
+	}
+}
+
+func TestClient_SetBaseURLNormalizes(t *testing.T) {
+	client := NewClient("https://api.example.com/")
+	if got := client.GetBaseURL(); got != "https://api.example.com" {
+		t.Errorf("BaseURL = %s, want the trailing slash trimmed", got)
+	}
+
+	client.SetBaseURL("new-api.example.com/v2/")
+	if got := client.GetBaseURL(); got != "https://new-api.example.com/v2" {
+		t.Errorf("BaseURL = %s, want https://new-api.example.com/v2", got)
+	}
+}
+
+func TestParseAPIError_FieldErrorFixtures(t *testing.T) {
+	tests := []struct {
+		fixture  string
+		expected []errors.FieldError
+		message  string
+	}{
+		{
+			fixture: "validation_list.json",
+			expected: []errors.FieldError{
+				{Field: "signatures[0].data", Code: "INVALID", Message: "invalid"},
+				{Field: "nonce", Code: "NONCE_TOO_LOW", Message: "nonce too low"},
+			},
+			message: "relayer api error (status 400, code VALIDATION_ERROR): invalid request [signatures[0].data: invalid (INVALID); nonce: nonce too low (NONCE_TOO_LOW)]",
+		},
+		{
+			fixture: "validation_wrapped.json",
+			expected: []errors.FieldError{
+				{Field: "proxyWallet", Message: "does not match signer"},
+			},
+			message: "relayer api error (status 400): invalid request [proxyWallet: does not match signer]",
+		},
+		{
+			fixture: "validation_map.json",
+			expected: []errors.FieldError{
+				{Field: "nonce", Message: "nonce too low"},
+				{Field: "signature", Message: "invalid signature"},
+			},
+			message: "relayer api error (status 400): invalid request [nonce: nonce too low; signature: invalid signature]",
+		},
+		{
+			fixture:  "no_details.json",
+			expected: nil,
+			message:  "relayer api error (status 400): invalid request",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			body, err := os.ReadFile(filepath.Join("testdata", tt.fixture))
+			if err != nil {
+				t.Fatalf("Failed to read fixture: %v", err)
+			}
+
+			err = parseAPIError(http.StatusBadRequest, body)
+			var apiErr *errors.RelayerApiError
+			if !stderrors.As(err, &apiErr) {
+				t.Fatalf("error = %v, want RelayerApiError", err)
+			}
+
+			if !reflect.DeepEqual(apiErr.FieldErrors(), tt.expected) {
+				t.Errorf("FieldErrors() = %v, want %v", apiErr.FieldErrors(), tt.expected)
+			}
+			if apiErr.Error() != tt.message {
+				t.Errorf("Error() = %s, want %s", apiErr.Error(), tt.message)
+			}
+		})
+	}
+}
+
+func TestClient_MaxResponseSize(t *testing.T) {
+	const limit = 64
+
+	tests := []struct {
+		name      string
+		status    int
+		body      string
+		chunked   bool
+		shouldErr bool
+	}{
+		{name: "under limit", status: http.StatusOK, body: `{"ok":true}`},
+		{name: "at limit", status: http.StatusOK, body: `"` + strings.Repeat("a", limit-2) + `"`},
+		{name: "over limit", status: http.StatusOK, body: strings.Repeat("a", limit+1), shouldErr: true},
+		{name: "over limit without content length", status: http.StatusOK, body: strings.Repeat("a", 10*limit), chunked: true, shouldErr: true},
+		{name: "oversized error response", status: http.StatusInternalServerError, body: strings.Repeat("a", 10*limit), shouldErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if !tt.chunked {
+					w.Header().Set("Content-Length", strconv.Itoa(len(tt.body)))
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client, err := NewClientWithOptions(server.URL, WithMaxResponseSize(limit))
+			if err != nil {
+				t.Fatalf("NewClientWithOptions failed: %v", err)
+			}
+
+			_, err = client.Get("/test", nil)
+			var tooLarge *errors.ResponseTooLargeError
+			if got := stderrors.As(err, &tooLarge); got != tt.shouldErr {
+				t.Fatalf("Get() error = %v, want ResponseTooLargeError: %v", err, tt.shouldErr)
+			}
+			if tt.shouldErr && tooLarge.Limit != limit {
+				t.Errorf("Limit = %d, want %d", tooLarge.Limit, limit)
+			}
+		})
+	}
+
+	if _, err := NewClientWithOptions("http://localhost", WithMaxResponseSize(0)); err == nil {
+		t.Error("a non-positive max response size should be rejected")
+	}
+}
+
+func TestClient_Redirects(t *testing.T) {
+	var otherHits int32
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&otherHits, 1)
+		w.Write([]byte(`{"message":"other"}`))
+	}))
+	defer other.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/same":
+			http.Redirect(w, r, "/target", http.StatusFound)
+		case "/cross":
+			http.Redirect(w, r, other.URL+"/target", http.StatusTemporaryRedirect)
+		case "/target":
+			w.Write([]byte(`{"message":"target"}`))
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		opts      []ClientOption
+		path      string
+		shouldErr bool
+	}{
+		{name: "same host refused by default", path: "/same", shouldErr: true},
+		{name: "cross host refused by default", path: "/cross", shouldErr: true},
+		{name: "same host allowed", opts: []ClientOption{WithSameHostRedirects()}, path: "/same"},
+		{name: "cross host refused", opts: []ClientOption{WithSameHostRedirects()}, path: "/cross", shouldErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewClientWithOptions(server.URL, tt.opts...)
+			if err != nil {
+				t.Fatalf("NewClientWithOptions failed: %v", err)
+			}
+
+			_, err = client.Post(tt.path, map[string]string{"POLY_BUILDER_SIGNATURE": "sig"}, map[string]string{"a": "b"})
+			if (err != nil) != tt.shouldErr {
+				t.Fatalf("Post() error = %v, shouldErr %v", err, tt.shouldErr)
+			}
+			var redirectErr *errors.RedirectError
+			if tt.shouldErr && !stderrors.As(err, &redirectErr) {
+				t.Errorf("error = %v, want RedirectError", err)
+			}
+		})
+	}
+
+	if hits := atomic.LoadInt32(&otherHits); hits != 0 {
+		t.Errorf("other origin received %d requests, want 0", hits)
+	}
+
+	// The legacy constructors refuse redirects too
+	if _, err := NewClient(server.URL).Get("/same", nil); err == nil {
+		t.Error("NewClient should not follow redirects")
+	}
+}
+
+func TestClient_ConnectionReuse(t *testing.T) {
+	var conns int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/error":
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"bad request"}`))
+		case "/not-json":
+			w.Write([]byte(strings.Repeat("x", 4096)))
+		default:
+			w.Write([]byte(`{"message":"success"}`))
+		}
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&conns, 1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	client, err := NewClientWithOptions(server.URL)
+	if err != nil {
+		t.Fatalf("NewClientWithOptions failed: %v", err)
+	}
+
+	var target map[string]string
+	for i := 0; i < 3; i++ {
+		if _, err := client.Get("/ok", nil); err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		if _, err := client.Get("/error", nil); err == nil {
+			t.Fatal("expected an API error")
+		}
+		if err := client.GetJSON("/not-json", nil, &target); err == nil {
+			t.Fatal("expected an unmarshal error")
+		}
+	}
+
+	if got := atomic.LoadInt32(&conns); got != 1 {
+		t.Errorf("opened %d connections, want 1", got)
+	}
+}
@@ -0,0 +1,159 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/redact"
+)
+
+// ResponseEnvelope is how a relayer wraps its response payloads. Some
+// deployments answer {"success": true, "data": ...} where the public relayer
+// answers with the bare payload.
+type ResponseEnvelope int
+
+const (
+	// EnvelopeNone reads responses as bare payloads (the default)
+	EnvelopeNone ResponseEnvelope = iota
+	// EnvelopeDataWrapper reads every response as an envelope: the payload
+	// is its data, and success=false or an error object is an API error
+	EnvelopeDataWrapper
+	// EnvelopeAuto reads a response as an envelope when it is a JSON object
+	// with a boolean success key, and as a bare payload otherwise
+	EnvelopeAuto
+)
+
+// String returns the mode's name
+func (e ResponseEnvelope) String() string {
+	switch e {
+	case EnvelopeNone:
+		return "none"
+	case EnvelopeDataWrapper:
+		return "data-wrapper"
+	case EnvelopeAuto:
+		return "auto"
+	default:
+		return fmt.Sprintf("ResponseEnvelope(%d)", int(e))
+	}
+}
+
+// WithResponseEnvelope sets how response payloads are unwrapped (EnvelopeNone by default)
+func WithResponseEnvelope(mode ResponseEnvelope) ClientOption {
+	return func(s *clientSettings) {
+		if mode < EnvelopeNone || mode > EnvelopeAuto {
+			s.setErr(errors.ErrInvalidConfiguration(fmt.Sprintf("unknown response envelope mode %d", int(mode))))
+			return
+		}
+		s.envelope = mode
+	}
+}
+
+// envelope is the JSON form of an enveloped response
+type envelope struct {
+	Success *bool           `json:"success"`
+	Data    json.RawMessage `json:"data"`
+	Error   json.RawMessage `json:"error"`
+}
+
+// envelopeError is the error object of a failed envelope. The message may
+// be under message or error; status, when set, overrides the HTTP status.
+type envelopeError struct {
+	Message string      `json:"message"`
+	Error   string      `json:"error"`
+	Code    *string     `json:"code"`
+	Details interface{} `json:"details"`
+	Status  int         `json:"status"`
+}
+
+// failed reports whether the envelope reports a failure
+func (e *envelope) failed() bool {
+	if e.Success != nil {
+		return !*e.Success
+	}
+	return hasValue(e.Error)
+}
+
+// apiError returns the RelayerApiError a failed envelope reports, for a
+// response with statusCode
+func (e *envelope) apiError(statusCode int) error {
+	var message string
+	var detail envelopeError
+	if err := json.Unmarshal(e.Error, &message); err != nil && hasValue(e.Error) {
+		if err := json.Unmarshal(e.Error, &detail); err != nil {
+			message = string(e.Error)
+		} else {
+			message = detail.Message
+			if message == "" {
+				message = detail.Error
+			}
+		}
+	}
+	if detail.Status >= 400 {
+		statusCode = detail.Status
+	}
+	if message == "" {
+		message = "relayer reported failure"
+	}
+	message = redact.Default().Scrub(message)
+
+	if detail.Code != nil || detail.Details != nil {
+		code := ""
+		if detail.Code != nil {
+			code = *detail.Code
+		}
+		return errors.NewRelayerApiErrorWithDetails(statusCode, message, code, detail.Details)
+	}
+	return errors.NewRelayerApiError(statusCode, message)
+}
+
+// openEnvelope decodes body as an envelope if mode calls for it, reporting
+// whether it is one
+func openEnvelope(mode ResponseEnvelope, body []byte) (*envelope, bool) {
+	if mode == EnvelopeNone {
+		return nil, false
+	}
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		return nil, false
+	}
+	var env envelope
+	if err := json.Unmarshal(trimmed, &env); err != nil {
+		return nil, false
+	}
+	if env.Success == nil && (mode == EnvelopeAuto || (env.Data == nil && env.Error == nil)) {
+		return nil, false
+	}
+	return &env, true
+}
+
+// payload returns the payload of a response with statusCode and body,
+// unwrapping its envelope per the client's mode, or the API error it
+// reports. An enveloped failure is an error even with a 2xx status.
+func (c *Client) payload(statusCode int, body []byte) ([]byte, error) {
+	env, enveloped := openEnvelope(c.envelope, body)
+	if !enveloped {
+		if statusCode >= 400 {
+			return nil, parseAPIError(statusCode, body)
+		}
+		if c.envelope == EnvelopeDataWrapper {
+			return nil, errors.ErrInvalidResponse("response is not enveloped")
+		}
+		return body, nil
+	}
+
+	if statusCode >= 400 || env.failed() {
+		return nil, env.apiError(statusCode)
+	}
+	if !hasValue(env.Data) {
+		return []byte("null"), nil
+	}
+	return env.Data, nil
+}
+
+// hasValue reports whether raw holds a JSON value other than null
+func hasValue(raw json.RawMessage) bool {
+	trimmed := bytes.TrimSpace(raw)
+	return len(trimmed) > 0 && !bytes.Equal(trimmed, []byte("null"))
+}
@@ -0,0 +1,114 @@
+package http
+
+import (
+	"context"
+	stderrors "errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/davidt58/go-builder-relayer-client/errors"
+)
+
+func TestClient_Payload(t *testing.T) {
+	tests := []struct {
+		name        string
+		mode        ResponseEnvelope
+		status      int
+		body        string
+		wantPayload string
+		wantStatus  int // of the RelayerApiError; 0 for success
+		wantCode    string
+		wantErr     bool // any other error
+	}{
+		{"none leaves envelopes", EnvelopeNone, 200, `{"success":true,"data":{"nonce":"1"}}`, `{"success":true,"data":{"nonce":"1"}}`, 0, "", false},
+		{"auto unwraps", EnvelopeAuto, 200, `{"success":true,"data":{"nonce":"1"}}`, `{"nonce":"1"}`, 0, "", false},
+		{"auto passes bare objects", EnvelopeAuto, 200, `{"nonce":"1","data":"x"}`, `{"nonce":"1","data":"x"}`, 0, "", false},
+		{"auto passes bare arrays", EnvelopeAuto, 200, `[{"transactionId":"tx-1"}]`, `[{"transactionId":"tx-1"}]`, 0, "", false},
+		{"wrapper unwraps", EnvelopeDataWrapper, 200, `{"data":[1,2]}`, `[1,2]`, 0, "", false},
+		{"wrapper without data", EnvelopeDataWrapper, 200, `{"success":true}`, `null`, 0, "", false},
+		{"wrapper rejects bare payloads", EnvelopeDataWrapper, 200, `{"nonce":"1"}`, "", 0, "", true},
+		{"failure with 200", EnvelopeAuto, 200, `{"success":false,"error":{"message":"bad nonce","code":"INVALID_NONCE"}}`, "", 200, "INVALID_NONCE", false},
+		{"failure status from error object", EnvelopeAuto, 200, `{"success":false,"error":{"message":"not found","status":404}}`, "", 404, "", false},
+		{"failure with error string", EnvelopeDataWrapper, 200, `{"success":false,"error":"quota exceeded"}`, "", 200, "", false},
+		{"wrapper error without success", EnvelopeDataWrapper, 200, `{"error":{"error":"denied","code":"FORBIDDEN"}}`, "", 200, "FORBIDDEN", false},
+		{"enveloped error status", EnvelopeAuto, 429, `{"success":false,"error":{"message":"slow down"}}`, "", 429, "", false},
+		{"bare error in wrapper mode", EnvelopeDataWrapper, 502, `<html>bad gateway</html>`, "", 502, "", false},
+		{"bare error in auto mode", EnvelopeAuto, 400, `{"error":"invalid","code":"INVALID_REQUEST"}`, "", 400, "INVALID_REQUEST", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Client{envelope: tt.mode}
+			payload, err := c.payload(tt.status, []byte(tt.body))
+
+			switch {
+			case tt.wantErr:
+				if err == nil {
+					t.Fatalf("payload = %s, want an error", payload)
+				}
+			case tt.wantStatus != 0:
+				var apiErr *errors.RelayerApiError
+				if !stderrors.As(err, &apiErr) {
+					t.Fatalf("error = %v, want a RelayerApiError", err)
+				}
+				if apiErr.StatusCode != tt.wantStatus || apiErr.Code != tt.wantCode {
+					t.Errorf("error status %d code %q, want %d %q", apiErr.StatusCode, apiErr.Code, tt.wantStatus, tt.wantCode)
+				}
+			default:
+				if err != nil {
+					t.Fatalf("payload failed: %v", err)
+				}
+				if string(payload) != tt.wantPayload {
+					t.Errorf("payload = %s, want %s", payload, tt.wantPayload)
+				}
+			}
+		})
+	}
+}
+
+func TestClient_EnvelopedRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/nonce":
+			w.Write([]byte(`{"success":true,"data":{"nonce":"7"}}`))
+		case "/transactions":
+			w.Header().Set("ETag", `"v1"`)
+			w.Write([]byte(`{"success":true,"data":{"transactions":[]}}`))
+		default:
+			w.Write([]byte(`{"success":false,"error":{"message":"no such endpoint","status":404}}`))
+		}
+	}))
+	defer server.Close()
+
+	c, err := NewClientWithOptions(server.URL, WithResponseEnvelope(EnvelopeAuto))
+	if err != nil {
+		t.Fatalf("NewClientWithOptions failed: %v", err)
+	}
+
+	var nonce struct {
+		Nonce string `json:"nonce"`
+	}
+	if err := c.GetJSON("/nonce", nil, &nonce); err != nil || nonce.Nonce != "7" {
+		t.Fatalf("GetJSON = %+v, %v", nonce, err)
+	}
+	if body, err := c.Get("/nonce", nil); err != nil || string(body) != `{"nonce":"7"}` {
+		t.Fatalf("Get = %s, %v", body, err)
+	}
+	conditional, err := c.GetConditionalContext(context.Background(), "/transactions", nil, Validators{})
+	if err != nil || string(conditional.Body) != `{"transactions":[]}` || conditional.Validators.ETag != `"v1"` {
+		t.Fatalf("GetConditionalContext = %+v, %v", conditional, err)
+	}
+	if err := c.GetJSON("/missing", nil, &nonce); !errors.IsNotFound(err) {
+		t.Errorf("error = %v, want a 404 RelayerApiError", err)
+	}
+}
+
+func TestWithResponseEnvelope_Invalid(t *testing.T) {
+	if _, err := NewClientWithOptions("https://relayer.example", WithResponseEnvelope(ResponseEnvelope(7))); err == nil {
+		t.Error("Expected error for an unknown envelope mode")
+	}
+	if got := ResponseEnvelope(7).String(); got != "ResponseEnvelope(7)" {
+		t.Errorf("String() = %q", got)
+	}
+}
@@ -8,7 +8,7 @@ import (
 
 // BuildURL constructs a URL with query parameters
 func BuildURL(baseURL, path string, params map[string]string) string {
-	u, err := url.Parse(baseURL + path)
+	u, err := resolveURL(baseURL, path)
 	if err != nil {
 		return baseURL + path
 	}
@@ -24,6 +24,46 @@ func BuildURL(baseURL, path string, params map[string]string) string {
 	return u.String()
 }
 
+// JoinURL joins path, which may carry a query string, onto baseURL. Any path
+// prefix of baseURL is kept and exactly one slash separates the two, whether
+// or not baseURL ends with one or path starts with one.
+func JoinURL(baseURL, path string) string {
+	u, err := resolveURL(baseURL, path)
+	if err != nil {
+		return baseURL + path
+	}
+	return u.String()
+}
+
+// resolveURL parses baseURL and joins path and its query string onto it
+func resolveURL(baseURL, path string) (*url.URL, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	path, query, _ := strings.Cut(path, "?")
+	u.Path = joinPath(u.Path, path)
+	u.RawPath = ""
+	switch {
+	case query == "":
+	case u.RawQuery == "":
+		u.RawQuery = query
+	default:
+		u.RawQuery += "&" + query
+	}
+	return u, nil
+}
+
+// joinPath appends path to prefix with a single slash between them
+func joinPath(prefix, path string) string {
+	prefix = strings.TrimSuffix(prefix, "/")
+	if path == "" {
+		return prefix
+	}
+	return prefix + FormatPath(path)
+}
+
 // NormalizeURL ensures the URL has proper format
 func NormalizeURL(rawURL string) string {
 	// Remove trailing slash
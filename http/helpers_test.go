@@ -42,6 +42,47 @@ func TestBuildURL(t *testing.T) {
 	}
 }
 
+func TestJoinURL(t *testing.T) {
+	bases := []struct {
+		base   string
+		prefix string
+	}{
+		{"https://relayer.example.com", ""},
+		{"https://relayer.example.com/", ""},
+		{"https://relayer.example.com/api", "/api"},
+		{"https://relayer.example.com/api/", "/api"},
+	}
+	paths := []struct {
+		path string
+		want string
+	}{
+		{"/nonce", "/nonce"},
+		{"nonce", "/nonce"},
+		{"/debug/validate", "/debug/validate"},
+		{"/nonce?address=0xabc&type=SAFE", "/nonce?address=0xabc&type=SAFE"},
+		{"nonce?address=0xabc", "/nonce?address=0xabc"},
+		{"/transaction?id=tx%2F1", "/transaction?id=tx%2F1"},
+	}
+
+	for _, b := range bases {
+		for _, p := range paths {
+			t.Run(b.base+" "+p.path, func(t *testing.T) {
+				want := "https://relayer.example.com" + b.prefix + p.want
+				if got := JoinURL(b.base, p.path); got != want {
+					t.Errorf("JoinURL(%q, %q) = %s, want %s", b.base, p.path, got, want)
+				}
+			})
+		}
+	}
+}
+
+func TestJoinURL_BaseQuery(t *testing.T) {
+	got := JoinURL("https://relayer.example.com/api?key=1", "/nonce?address=0xabc")
+	if want := "https://relayer.example.com/api/nonce?key=1&address=0xabc"; got != want {
+		t.Errorf("JoinURL() = %s, want %s", got, want)
+	}
+}
+
 func TestNormalizeURL(t *testing.T) {
 	tests := []struct {
 		name     string
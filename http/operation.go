@@ -0,0 +1,38 @@
+package http
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+)
+
+// OperationIDHeader carries the client-generated operation ID on every
+// request belonging to one Deploy, Execute or PollUntilState call
+const OperationIDHeader = "X-Client-Operation-Id"
+
+// operationIDKey is the context key holding the operation ID
+type operationIDKey struct{}
+
+// NewOperationID generates a random (version 4) UUID
+func NewOperationID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(fmt.Sprintf("crypto/rand failed: %v", err))
+	}
+	b[6] = (b[6] & 0x0f) | 0x40 // version 4
+	b[8] = (b[8] & 0x3f) | 0x80 // RFC 4122 variant
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// ContextWithOperationID returns a context whose requests carry operationID
+// in the X-Client-Operation-Id header
+func ContextWithOperationID(ctx context.Context, operationID string) context.Context {
+	return context.WithValue(ctx, operationIDKey{}, operationID)
+}
+
+// OperationIDFromContext returns the operation ID attached to ctx, if any
+func OperationIDFromContext(ctx context.Context) string {
+	operationID, _ := ctx.Value(operationIDKey{}).(string)
+	return operationID
+}
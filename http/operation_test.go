@@ -0,0 +1,59 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+)
+
+var uuidV4Pattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func TestNewOperationID(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		id := NewOperationID()
+		if !uuidV4Pattern.MatchString(id) {
+			t.Fatalf("NewOperationID() = %q, want a UUIDv4", id)
+		}
+		if seen[id] {
+			t.Fatalf("NewOperationID() repeated %q", id)
+		}
+		seen[id] = true
+	}
+}
+
+func TestRequestContext_OperationIDHeader(t *testing.T) {
+	var got []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Header.Get(OperationIDHeader))
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	ctx := ContextWithOperationID(context.Background(), "op-123")
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want string
+	}{
+		{"with operation ID", ctx, "op-123"},
+		{"without operation ID", context.Background(), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got = nil
+			var target map[string]interface{}
+			if err := client.GetJSONContext(tt.ctx, "/", nil, &target); err != nil {
+				t.Fatalf("GetJSONContext failed: %v", err)
+			}
+			if len(got) != 1 || got[0] != tt.want {
+				t.Errorf("%s header = %v, want %q", OperationIDHeader, got, tt.want)
+			}
+		})
+	}
+}
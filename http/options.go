@@ -0,0 +1,321 @@
+package http
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/davidt58/go-builder-relayer-client/errors"
+)
+
+// DefaultTimeout is the default HTTP request timeout
+const DefaultTimeout = 30 * time.Second
+
+// DefaultMinTLSVersion is the minimum TLS version used unless overridden
+const DefaultMinTLSVersion = tls.VersionTLS12
+
+// Connection pool defaults, tuned for a client talking to a single relayer
+// host. net/http keeps only 2 idle connections per host, so polling at a
+// high rate would otherwise open (and TLS handshake) a new connection for
+// most requests.
+const (
+	// DefaultMaxIdleConns is the maximum number of idle connections kept
+	DefaultMaxIdleConns = 128
+	// DefaultMaxIdleConnsPerHost is the maximum number of idle connections kept to the relayer
+	DefaultMaxIdleConnsPerHost = 64
+	// DefaultIdleConnTimeout is how long an idle connection is kept open
+	DefaultIdleConnTimeout = 90 * time.Second
+)
+
+// clientSettings collects the options applied by NewClientWithOptions
+type clientSettings struct {
+	timeout       time.Duration
+	transport     http.RoundTripper
+	minTLSVersion uint16
+	pins          map[[sha256.Size]byte]bool
+	maxResponse   int64
+	redirects     func(*http.Request, []*http.Request) error
+	hooks         []func(*http.Response)
+	envelope      ResponseEnvelope
+	pool          poolSettings
+	err           error
+}
+
+// poolSettings are the connection pool overrides; zero fields keep the
+// transport's value, or the package default if the transport has none
+type poolSettings struct {
+	maxIdleConns        int
+	maxIdleConnsPerHost int
+	idleConnTimeout     time.Duration
+}
+
+// ClientOption configures a Client created with NewClientWithOptions
+type ClientOption func(*clientSettings)
+
+// WithTimeout sets the per-request timeout
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(s *clientSettings) {
+		s.timeout = timeout
+	}
+}
+
+// WithTransport uses a custom transport. If certificate pinning or a minimum
+// TLS version is also requested, the transport must be an *http.Transport
+// whose TLS verification callback is not already set. An *http.Transport
+// gets the default connection pool limits for any limit it leaves unset.
+func WithTransport(transport http.RoundTripper) ClientOption {
+	return func(s *clientSettings) {
+		s.transport = transport
+	}
+}
+
+// WithMinTLSVersion sets the minimum accepted TLS version (default TLS 1.2)
+func WithMinTLSVersion(version uint16) ClientOption {
+	return func(s *clientSettings) {
+		s.minTLSVersion = version
+	}
+}
+
+// WithPinnedCertificates restricts connections to servers whose leaf
+// certificate SubjectPublicKeyInfo SHA-256 hash is in the given list. Hashes
+// may be hex or standard base64 encoded (the HPKP "pin-sha256" format). At
+// least one pin is required; an empty list is a configuration error rather
+// than pinning nothing.
+func WithPinnedCertificates(spkiSHA256 ...string) ClientOption {
+	return func(s *clientSettings) {
+		if len(spkiSHA256) == 0 {
+			s.setErr(errors.ErrInvalidConfiguration("no certificate pins given"))
+			return
+		}
+		if s.pins == nil {
+			s.pins = make(map[[sha256.Size]byte]bool)
+		}
+		for _, pin := range spkiSHA256 {
+			hash, err := decodePin(pin)
+			if err != nil {
+				s.setErr(err)
+				return
+			}
+			s.pins[hash] = true
+		}
+	}
+}
+
+// WithMaxResponseSize caps the response body size in bytes (default
+// DefaultMaxResponseSize); larger responses fail with a ResponseTooLargeError
+func WithMaxResponseSize(bytes int64) ClientOption {
+	return func(s *clientSettings) {
+		if bytes <= 0 {
+			s.setErr(errors.ErrInvalidConfiguration("max response size must be positive"))
+			return
+		}
+		s.maxResponse = bytes
+	}
+}
+
+// WithSameHostRedirects follows redirects that keep the original scheme and
+// host. By default no redirect is followed and a RedirectError is returned.
+func WithSameHostRedirects() ClientOption {
+	return func(s *clientSettings) {
+		s.redirects = sameHostRedirects
+	}
+}
+
+// WithMaxIdleConns sets the number of idle connections kept open in total
+// and to the relayer host (defaults DefaultMaxIdleConns and
+// DefaultMaxIdleConnsPerHost). perHost is capped at total.
+func WithMaxIdleConns(total, perHost int) ClientOption {
+	return func(s *clientSettings) {
+		if total <= 0 || perHost <= 0 {
+			s.setErr(errors.ErrInvalidConfiguration("idle connection limits must be positive"))
+			return
+		}
+		if perHost > total {
+			perHost = total
+		}
+		s.pool.maxIdleConns = total
+		s.pool.maxIdleConnsPerHost = perHost
+	}
+}
+
+// WithIdleConnTimeout sets how long an idle connection is kept open
+// (default DefaultIdleConnTimeout)
+func WithIdleConnTimeout(timeout time.Duration) ClientOption {
+	return func(s *clientSettings) {
+		if timeout <= 0 {
+			s.setErr(errors.ErrInvalidConfiguration("idle connection timeout must be positive"))
+			return
+		}
+		s.pool.idleConnTimeout = timeout
+	}
+}
+
+// WithResponseHook calls hook with every response received, before its body
+// is read. Hooks run in the order they were added and must not consume the body.
+func WithResponseHook(hook func(*http.Response)) ClientOption {
+	return func(s *clientSettings) {
+		if hook != nil {
+			s.hooks = append(s.hooks, hook)
+		}
+	}
+}
+
+// setErr records the first option error
+func (s *clientSettings) setErr(err error) {
+	if s.err == nil {
+		s.err = err
+	}
+}
+
+// NewClientWithOptions creates a new HTTP client configured by the given options
+func NewClientWithOptions(baseURL string, opts ...ClientOption) (*Client, error) {
+	settings := &clientSettings{
+		timeout:       DefaultTimeout,
+		minTLSVersion: DefaultMinTLSVersion,
+		maxResponse:   DefaultMaxResponseSize,
+		redirects:     refuseRedirects,
+	}
+	for _, opt := range opts {
+		opt(settings)
+	}
+	if settings.err != nil {
+		return nil, settings.err
+	}
+
+	transport, err := settings.buildTransport()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{
+		httpClient: &http.Client{
+			Timeout:       settings.timeout,
+			Transport:     transport,
+			CheckRedirect: settings.redirects,
+		},
+		baseURL:         NormalizeURL(baseURL),
+		maxResponseSize: settings.maxResponse,
+		responseHooks:   settings.hooks,
+		envelope:        settings.envelope,
+	}, nil
+}
+
+// buildTransport applies the TLS settings to a clone of the configured
+// (or default) transport
+func (s *clientSettings) buildTransport() (http.RoundTripper, error) {
+	var base *http.Transport
+	switch t := s.transport.(type) {
+	case nil:
+		base = newPooledTransport()
+	case *http.Transport:
+		base = t.Clone()
+	default:
+		if len(s.pins) > 0 {
+			return nil, errors.ErrInvalidConfiguration("certificate pinning requires an *http.Transport; got a custom RoundTripper")
+		}
+		// Opaque transports own their TLS configuration
+		return t, nil
+	}
+	s.pool.apply(base)
+
+	tlsConfig := base.TLSClientConfig
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{}
+	}
+
+	if len(s.pins) > 0 && tlsConfig.VerifyPeerCertificate != nil {
+		return nil, errors.ErrInvalidConfiguration("certificate pinning conflicts with the transport's VerifyPeerCertificate callback")
+	}
+	if tlsConfig.MinVersion < s.minTLSVersion {
+		tlsConfig.MinVersion = s.minTLSVersion
+	}
+	if len(s.pins) > 0 {
+		tlsConfig.VerifyPeerCertificate = verifyPinnedCertificate(s.pins)
+	}
+
+	base.TLSClientConfig = tlsConfig
+	return base, nil
+}
+
+// apply sets the pool overrides on t and fills its unset limits with the
+// package defaults
+func (p poolSettings) apply(t *http.Transport) {
+	t.MaxIdleConns = firstPositive(p.maxIdleConns, t.MaxIdleConns, DefaultMaxIdleConns)
+	t.MaxIdleConnsPerHost = firstPositive(p.maxIdleConnsPerHost, t.MaxIdleConnsPerHost, DefaultMaxIdleConnsPerHost)
+	t.IdleConnTimeout = time.Duration(firstPositive(int(p.idleConnTimeout), int(t.IdleConnTimeout), int(DefaultIdleConnTimeout)))
+}
+
+// newPooledTransport returns a clone of the default transport with the
+// default connection pool limits
+func newPooledTransport() *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.MaxIdleConns = DefaultMaxIdleConns
+	t.MaxIdleConnsPerHost = DefaultMaxIdleConnsPerHost
+	t.IdleConnTimeout = DefaultIdleConnTimeout
+	t.ForceAttemptHTTP2 = true
+	return t
+}
+
+// firstPositive returns the first positive value
+func firstPositive(values ...int) int {
+	for _, v := range values {
+		if v > 0 {
+			return v
+		}
+	}
+	return 0
+}
+
+// verifyPinnedCertificate returns a callback rejecting chains whose leaf
+// SPKI hash is not pinned
+func verifyPinnedCertificate(pins map[[sha256.Size]byte]bool) func([][]byte, [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		if len(rawCerts) == 0 {
+			return errors.NewRelayerClientError("server presented no certificates", errors.ErrCertificatePinMismatch)
+		}
+
+		leaf, err := x509.ParseCertificate(rawCerts[0])
+		if err != nil {
+			return errors.NewRelayerClientError("failed to parse server certificate", errors.ErrCertificatePinMismatch)
+		}
+
+		hash := sha256.Sum256(leaf.RawSubjectPublicKeyInfo)
+		if !pins[hash] {
+			return errors.NewRelayerClientError(
+				fmt.Sprintf("leaf SPKI sha256 %s is not pinned", base64.StdEncoding.EncodeToString(hash[:])),
+				errors.ErrCertificatePinMismatch,
+			)
+		}
+		return nil
+	}
+}
+
+// SPKIHash returns the base64-encoded SHA-256 hash of a certificate's
+// SubjectPublicKeyInfo, suitable for WithPinnedCertificates
+func SPKIHash(cert *x509.Certificate) string {
+	hash := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return base64.StdEncoding.EncodeToString(hash[:])
+}
+
+// decodePin parses a hex or base64 encoded SHA-256 pin
+func decodePin(pin string) ([sha256.Size]byte, error) {
+	var hash [sha256.Size]byte
+	pin = strings.TrimPrefix(strings.TrimSpace(pin), "sha256/")
+
+	decoded, err := hex.DecodeString(pin)
+	if err != nil || len(decoded) != sha256.Size {
+		decoded, err = base64.StdEncoding.DecodeString(pin)
+	}
+	if err != nil || len(decoded) != sha256.Size {
+		return hash, errors.ErrInvalidConfiguration(fmt.Sprintf("invalid SPKI SHA-256 pin: %s", pin))
+	}
+
+	copy(hash[:], decoded)
+	return hash, nil
+}
@@ -0,0 +1,286 @@
+package http
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	stderrors "errors"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/davidt58/go-builder-relayer-client/errors"
+)
+
+// roundTripperFunc adapts a function to http.RoundTripper
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func newPinningTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"message":"success"}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestWithPinnedCertificates_Match(t *testing.T) {
+	server := newPinningTestServer(t)
+	pin := SPKIHash(server.Certificate())
+
+	client, err := NewClientWithOptions(server.URL,
+		WithTransport(server.Client().Transport),
+		WithPinnedCertificates(pin),
+	)
+	if err != nil {
+		t.Fatalf("NewClientWithOptions failed: %v", err)
+	}
+
+	if _, err := client.Get("/test", nil); err != nil {
+		t.Fatalf("Get with correct pin failed: %v", err)
+	}
+}
+
+func TestWithPinnedCertificates_HexPin(t *testing.T) {
+	server := newPinningTestServer(t)
+	hash := sha256.Sum256(server.Certificate().RawSubjectPublicKeyInfo)
+
+	client, err := NewClientWithOptions(server.URL,
+		WithTransport(server.Client().Transport),
+		WithPinnedCertificates(hex.EncodeToString(hash[:])),
+	)
+	if err != nil {
+		t.Fatalf("NewClientWithOptions failed: %v", err)
+	}
+
+	if _, err := client.Get("/test", nil); err != nil {
+		t.Fatalf("Get with correct hex pin failed: %v", err)
+	}
+}
+
+func TestWithPinnedCertificates_Mismatch(t *testing.T) {
+	server := newPinningTestServer(t)
+	wrongPin := sha256.Sum256([]byte("some other key"))
+
+	client, err := NewClientWithOptions(server.URL,
+		WithTransport(server.Client().Transport),
+		WithPinnedCertificates(hex.EncodeToString(wrongPin[:])),
+	)
+	if err != nil {
+		t.Fatalf("NewClientWithOptions failed: %v", err)
+	}
+
+	_, err = client.Get("/test", nil)
+	if err == nil {
+		t.Fatal("Expected connection to fail with a wrong pin")
+	}
+	if !stderrors.Is(err, errors.ErrCertificatePinMismatch) {
+		t.Errorf("error = %v, want ErrCertificatePinMismatch", err)
+	}
+}
+
+func TestNewClientWithOptions_Validation(t *testing.T) {
+	opaque := roundTripperFunc(func(r *http.Request) (*http.Response, error) { return nil, nil })
+	conflicting := &http.Transport{TLSClientConfig: &tls.Config{
+		VerifyPeerCertificate: verifyPinnedCertificate(nil),
+	}}
+	validPin := hex.EncodeToString(make([]byte, sha256.Size))
+
+	tests := []struct {
+		name      string
+		opts      []ClientOption
+		shouldErr bool
+	}{
+		{"defaults", nil, false},
+		{"opaque transport without pins", []ClientOption{WithTransport(opaque)}, false},
+		{"opaque transport with pins", []ClientOption{WithTransport(opaque), WithPinnedCertificates(validPin)}, true},
+		{"conflicting verify callback", []ClientOption{WithTransport(conflicting), WithPinnedCertificates(validPin)}, true},
+		{"invalid pin", []ClientOption{WithPinnedCertificates("not-a-pin")}, true},
+		{"no pins", []ClientOption{WithPinnedCertificates()}, true},
+		{"idle connections", []ClientOption{WithMaxIdleConns(10, 20), WithIdleConnTimeout(time.Minute)}, false},
+		{"zero idle connections", []ClientOption{WithMaxIdleConns(0, 1)}, true},
+		{"zero idle timeout", []ClientOption{WithIdleConnTimeout(0)}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewClientWithOptions("https://api.example.com", tt.opts...)
+			if tt.shouldErr && err == nil {
+				t.Error("Expected error but got none")
+			}
+			if !tt.shouldErr && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestNewClientWithOptions_MinTLSVersion(t *testing.T) {
+	client, err := NewClientWithOptions("https://api.example.com", WithTimeout(5*time.Second))
+	if err != nil {
+		t.Fatalf("NewClientWithOptions failed: %v", err)
+	}
+
+	transport := client.httpClient.Transport.(*http.Transport)
+	if transport.TLSClientConfig.MinVersion != tls.VersionTLS12 {
+		t.Errorf("MinVersion = %x, want TLS 1.2", transport.TLSClientConfig.MinVersion)
+	}
+	if client.httpClient.Timeout != 5*time.Second {
+		t.Errorf("Timeout = %v, want 5s", client.httpClient.Timeout)
+	}
+
+	client, err = NewClientWithOptions("https://api.example.com", WithMinTLSVersion(tls.VersionTLS13))
+	if err != nil {
+		t.Fatalf("NewClientWithOptions failed: %v", err)
+	}
+	transport = client.httpClient.Transport.(*http.Transport)
+	if transport.TLSClientConfig.MinVersion != tls.VersionTLS13 {
+		t.Errorf("MinVersion = %x, want TLS 1.3", transport.TLSClientConfig.MinVersion)
+	}
+}
+
+func TestNewClientWithOptions_ConnectionPool(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []ClientOption
+		wantIdle    int
+		wantPerHost int
+		wantTimeout time.Duration
+	}{
+		{
+			name:        "defaults",
+			wantIdle:    DefaultMaxIdleConns,
+			wantPerHost: DefaultMaxIdleConnsPerHost,
+			wantTimeout: DefaultIdleConnTimeout,
+		},
+		{
+			name:        "overrides",
+			opts:        []ClientOption{WithMaxIdleConns(10, 20), WithIdleConnTimeout(time.Minute)},
+			wantIdle:    10,
+			wantPerHost: 10,
+			wantTimeout: time.Minute,
+		},
+		{
+			name:        "injected transport keeps its limits",
+			opts:        []ClientOption{WithTransport(&http.Transport{MaxIdleConnsPerHost: 8})},
+			wantIdle:    DefaultMaxIdleConns,
+			wantPerHost: 8,
+			wantTimeout: DefaultIdleConnTimeout,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewClientWithOptions("https://api.example.com", tt.opts...)
+			if err != nil {
+				t.Fatalf("NewClientWithOptions failed: %v", err)
+			}
+
+			transport := client.httpClient.Transport.(*http.Transport)
+			if transport.MaxIdleConns != tt.wantIdle || transport.MaxIdleConnsPerHost != tt.wantPerHost || transport.IdleConnTimeout != tt.wantTimeout {
+				t.Errorf("pool = %d/%d/%v, want %d/%d/%v",
+					transport.MaxIdleConns, transport.MaxIdleConnsPerHost, transport.IdleConnTimeout,
+					tt.wantIdle, tt.wantPerHost, tt.wantTimeout)
+			}
+		})
+	}
+
+	legacy := NewClient("https://api.example.com").httpClient.Transport.(*http.Transport)
+	if legacy.MaxIdleConnsPerHost != DefaultMaxIdleConnsPerHost || !legacy.ForceAttemptHTTP2 {
+		t.Errorf("NewClient pool = %d per host (HTTP/2 %v), want %d", legacy.MaxIdleConnsPerHost, legacy.ForceAttemptHTTP2, DefaultMaxIdleConnsPerHost)
+	}
+}
+
+// countHandshakes issues rounds of concurrent GETs against a TLS server and
+// returns the number of TLS handshakes it saw
+func countHandshakes(t *testing.T, opts ...ClientOption) int32 {
+	t.Helper()
+	const rounds, concurrency = 3, 8
+
+	var handshakes int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(10 * time.Millisecond)
+		w.Write([]byte(`{"message":"success"}`))
+	}))
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.TLS = &tls.Config{GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		atomic.AddInt32(&handshakes, 1)
+		return nil, nil
+	}}
+	server.StartTLS()
+	defer server.Close()
+
+	// server.Client's transport trusts the test certificate; it leaves the pool limits unset
+	opts = append([]ClientOption{WithTransport(server.Client().Transport)}, opts...)
+	client, err := NewClientWithOptions(server.URL, opts...)
+	if err != nil {
+		t.Fatalf("NewClientWithOptions failed: %v", err)
+	}
+	defer client.CloseIdleConnections()
+
+	for round := 0; round < rounds; round++ {
+		var wg sync.WaitGroup
+		for i := 0; i < concurrency; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := client.Get("/status", nil); err != nil {
+					t.Errorf("Get failed: %v", err)
+				}
+			}()
+		}
+		wg.Wait()
+	}
+
+	return atomic.LoadInt32(&handshakes)
+}
+
+func TestClient_ConnectionPoolReuse(t *testing.T) {
+	// net/http's default of 2 idle connections per host re-handshakes most
+	// connections of every burst
+	churn := countHandshakes(t, WithMaxIdleConns(DefaultMaxIdleConns, 2))
+	if churn <= 8 {
+		t.Fatalf("got %d handshakes with 2 idle connections per host, want churn above 8", churn)
+	}
+
+	// The default pool keeps every connection of the first burst
+	if pooled := countHandshakes(t); pooled > 8 {
+		t.Errorf("got %d handshakes with the default pool, want at most 8", pooled)
+	}
+}
+
+func TestClient_CloseIdleConnections(t *testing.T) {
+	var conns int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"message":"success"}`))
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&conns, 1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	for i := 0; i < 2; i++ {
+		if _, err := client.Get("/status", nil); err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		client.CloseIdleConnections()
+	}
+
+	if got := atomic.LoadInt32(&conns); got != 2 {
+		t.Errorf("opened %d connections, want a new one after CloseIdleConnections", got)
+	}
+}
@@ -0,0 +1,178 @@
+package models
+
+import (
+	"sync"
+	"time"
+)
+
+// LatencyPhase names a phase of a transaction's end-to-end latency
+type LatencyPhase string
+
+const (
+	// PhaseBuild runs from the Deploy or Execute call until the request is
+	// signed, including the nonce and deployed checks before signing
+	PhaseBuild LatencyPhase = "build"
+	// PhaseSubmit runs from signing until the relayer accepted the submission
+	PhaseSubmit LatencyPhase = "submit"
+	// PhaseQueue runs from the submission until the relayer executed the
+	// transaction (STATE_EXECUTED)
+	PhaseQueue LatencyPhase = "queue"
+	// PhaseMining runs from STATE_EXECUTED until STATE_MINED
+	PhaseMining LatencyPhase = "mining"
+	// PhaseConfirmation runs from STATE_MINED until STATE_CONFIRMED
+	PhaseConfirmation LatencyPhase = "confirmation"
+	// PhaseTotal is the sum of the other phases
+	PhaseTotal LatencyPhase = "total"
+)
+
+// PhaseLatency is the duration of one latency phase
+type PhaseLatency struct {
+	// Phase is the phase measured
+	Phase LatencyPhase
+	// Duration is how long the phase took; zero when unknown
+	Duration time.Duration
+	// Known is false when a timestamp the phase is computed from is missing,
+	// e.g. because no poll saw the state that ends it
+	Known bool
+}
+
+// LatencyBreakdown splits a transaction's latency from the Deploy or
+// Execute call to its confirmation into phases. Client phases are timed by
+// the client's clock and relayer phases by the relayer's timestamps, so
+// clock skew between the two does not distort any phase.
+type LatencyBreakdown struct {
+	Build        PhaseLatency
+	Submit       PhaseLatency
+	Queue        PhaseLatency
+	Mining       PhaseLatency
+	Confirmation PhaseLatency
+	// Total is known only when every other phase is
+	Total PhaseLatency
+}
+
+// Phases returns the phases in order, Total last
+func (b LatencyBreakdown) Phases() []PhaseLatency {
+	return []PhaseLatency{b.Build, b.Submit, b.Queue, b.Mining, b.Confirmation, b.Total}
+}
+
+// between returns the phase from start to end, unknown if either is zero or
+// end precedes start
+func between(phase LatencyPhase, start, end time.Time) PhaseLatency {
+	if start.IsZero() || end.IsZero() || end.Before(start) {
+		return PhaseLatency{Phase: phase}
+	}
+	return PhaseLatency{Phase: phase, Duration: end.Sub(start), Known: true}
+}
+
+// StateTimeline records when a transaction entered each relayer state,
+// from the relayer's timestamps in the snapshots a client polled. It is
+// safe for concurrent use.
+type StateTimeline struct {
+	mu        sync.Mutex
+	createdAt time.Time
+	entered   map[RelayerTransactionState]time.Time
+}
+
+// NewStateTimeline returns an empty timeline
+func NewStateTimeline() *StateTimeline {
+	return &StateTimeline{entered: make(map[RelayerTransactionState]time.Time)}
+}
+
+// Observe records the timestamps of a polled snapshot. Per-state
+// timestamps reported by the relayer are taken as they are; otherwise a
+// state is taken to have been entered at the updatedAt of the first
+// snapshot seen in it, and states skipped between polls stay unknown.
+func (t *StateTimeline) Observe(txn *RelayerTransaction) {
+	if t == nil || txn == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if created, ok := parseTimestamp(txn.CreatedAt); ok && t.createdAt.IsZero() {
+		t.createdAt = created
+		if _, seen := t.entered[STATE_NEW]; !seen {
+			t.entered[STATE_NEW] = created
+		}
+	}
+	for state, timestamp := range txn.StateTimestamps {
+		if entered, ok := parseTimestamp(timestamp); ok {
+			t.entered[state] = entered
+		}
+	}
+	if _, seen := t.entered[txn.State]; !seen {
+		if updated, ok := parseTimestamp(txn.UpdatedAt); ok {
+			t.entered[txn.State] = updated
+		}
+	}
+}
+
+// Entered returns when the transaction entered state, if known
+func (t *StateTimeline) Entered(state RelayerTransactionState) (time.Time, bool) {
+	if t == nil {
+		return time.Time{}, false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	entered, ok := t.entered[state]
+	return entered, ok
+}
+
+// CreatedAt returns when the relayer recorded the submission, if known
+func (t *StateTimeline) CreatedAt() (time.Time, bool) {
+	if t == nil {
+		return time.Time{}, false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.createdAt, !t.createdAt.IsZero()
+}
+
+// parseTimestamp parses an RFC 3339 relayer timestamp
+func parseTimestamp(value string) (time.Time, bool) {
+	if value == "" {
+		return time.Time{}, false
+	}
+	parsed, err := time.Parse(time.RFC3339Nano, value)
+	return parsed, err == nil
+}
+
+// RecordState records the relayer timestamps of a polled snapshot of the
+// transaction for LatencyBreakdown. Clients call it for every snapshot
+// their polls accept.
+func (r *ClientRelayerTransactionResponse) RecordState(txn *RelayerTransaction) {
+	r.timeline.Observe(txn)
+}
+
+// LatencyBreakdown returns the latency of the transaction so far, phase by
+// phase. Phases whose timestamps are missing are reported as unknown: the
+// client phases of a response not produced by Deploy or Execute, and the
+// relayer phases whose states no wait has seen yet.
+func (r *ClientRelayerTransactionResponse) LatencyBreakdown() LatencyBreakdown {
+	executed, _ := r.timeline.Entered(STATE_EXECUTED)
+	mined, _ := r.timeline.Entered(STATE_MINED)
+	confirmed, _ := r.timeline.Entered(STATE_CONFIRMED)
+	// The relayer's own record of the submission keeps the queue phase on
+	// the relayer's clock
+	queued, ok := r.timeline.CreatedAt()
+	if !ok {
+		queued = r.SubmittedAt
+	}
+
+	b := LatencyBreakdown{
+		Build:        between(PhaseBuild, r.StartedAt, r.SignedAt),
+		Submit:       between(PhaseSubmit, r.SignedAt, r.SubmittedAt),
+		Queue:        between(PhaseQueue, queued, executed),
+		Mining:       between(PhaseMining, executed, mined),
+		Confirmation: between(PhaseConfirmation, mined, confirmed),
+		Total:        PhaseLatency{Phase: PhaseTotal, Known: true},
+	}
+	for _, phase := range b.Phases()[:5] {
+		b.Total.Duration += phase.Duration
+		b.Total.Known = b.Total.Known && phase.Known
+	}
+	if !b.Total.Known {
+		b.Total.Duration = 0
+	}
+	return b
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStateTimeline_Observe(t *testing.T) {
+	timeline := NewStateTimeline()
+	timeline.Observe(&RelayerTransaction{State: STATE_NEW, CreatedAt: "2026-03-01T12:00:00Z", UpdatedAt: "2026-03-01T12:00:00Z"})
+	timeline.Observe(&RelayerTransaction{State: STATE_EXECUTED, CreatedAt: "2026-03-01T12:00:00Z", UpdatedAt: "2026-03-01T12:00:03.5Z"})
+	// A later snapshot in the same state does not move its entry time
+	timeline.Observe(&RelayerTransaction{State: STATE_EXECUTED, UpdatedAt: "2026-03-01T12:00:04Z"})
+	// Unparseable timestamps are ignored
+	timeline.Observe(&RelayerTransaction{State: STATE_MINED, UpdatedAt: "yesterday"})
+
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	if created, ok := timeline.CreatedAt(); !ok || !created.Equal(base) {
+		t.Errorf("CreatedAt = %v, %v", created, ok)
+	}
+	if executed, ok := timeline.Entered(STATE_EXECUTED); !ok || executed.Sub(base) != 3500*time.Millisecond {
+		t.Errorf("STATE_EXECUTED entered %v, %v", executed, ok)
+	}
+	if _, ok := timeline.Entered(STATE_MINED); ok {
+		t.Error("STATE_MINED known from an unparseable timestamp")
+	}
+
+	// Reported per-state timestamps override the inferred ones
+	timeline.Observe(&RelayerTransaction{State: STATE_MINED, StateTimestamps: map[RelayerTransactionState]string{
+		STATE_EXECUTED: "2026-03-01T12:00:02Z",
+		STATE_MINED:    "2026-03-01T12:00:09Z",
+	}})
+	if executed, _ := timeline.Entered(STATE_EXECUTED); executed.Sub(base) != 2*time.Second {
+		t.Errorf("STATE_EXECUTED entered %v, want the reported time", executed)
+	}
+	if mined, ok := timeline.Entered(STATE_MINED); !ok || mined.Sub(base) != 9*time.Second {
+		t.Errorf("STATE_MINED entered %v, %v", mined, ok)
+	}
+}
+
+func TestLatencyBreakdown_Unknowns(t *testing.T) {
+	// A response built without a timeline or client timestamps knows nothing
+	response := &ClientRelayerTransactionResponse{TransactionID: "tx-1"}
+	response.RecordState(&RelayerTransaction{State: STATE_CONFIRMED, UpdatedAt: "2026-03-01T12:00:00Z"})
+	for _, phase := range response.LatencyBreakdown().Phases() {
+		if phase.Known || phase.Duration != 0 {
+			t.Errorf("%s = %v (known %v), want unknown", phase.Phase, phase.Duration, phase.Known)
+		}
+	}
+
+	// A phase ending before it starts, e.g. from clock skew, is unknown
+	response = NewClientRelayerTransactionResponse("tx-2")
+	response.StartedAt = time.Date(2026, 3, 1, 12, 0, 1, 0, time.UTC)
+	response.SignedAt = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	if build := response.LatencyBreakdown().Build; build.Known {
+		t.Errorf("build = %v, want unknown", build.Duration)
+	}
+}
@@ -0,0 +1,60 @@
+package models
+
+// OnChainStatus is the status of a transaction's receipt
+type OnChainStatus string
+
+const (
+	// OnChainStatusUnknown means the receipt was not checked, e.g. without an RPC client
+	OnChainStatusUnknown OnChainStatus = "unknown"
+	// OnChainStatusSuccess means the receipt has status 1
+	OnChainStatusSuccess OnChainStatus = "success"
+	// OnChainStatusReverted means the receipt has status 0
+	OnChainStatusReverted OnChainStatus = "reverted"
+)
+
+// SafeExecutionStatus is what the Safe reported for its inner execution
+type SafeExecutionStatus string
+
+const (
+	// SafeExecutionUnknown means no Safe execution event was checked or found
+	SafeExecutionUnknown SafeExecutionStatus = "unknown"
+	// SafeExecutionSucceeded means the Safe emitted ExecutionSuccess
+	SafeExecutionSucceeded SafeExecutionStatus = "success"
+	// SafeExecutionFailed means the Safe emitted ExecutionFailure, or the
+	// transaction reverted so the Safe executed nothing
+	SafeExecutionFailed SafeExecutionStatus = "failure"
+)
+
+// ExecutionOutcome tells a relayed transaction's relayer state apart from
+// what happened on chain. The relayer reports a transaction mined or
+// confirmed even when the Safe's inner call failed and emitted
+// ExecutionFailure, so only Succeeded means the transaction took effect.
+type ExecutionOutcome struct {
+	// Transaction is the last relayer view of the transaction
+	Transaction *RelayerTransaction
+	// RelayerState is the state the relayer reported
+	RelayerState RelayerTransactionState
+	// OnChainStatus is the receipt status; unknown without an RPC client
+	OnChainStatus OnChainStatus
+	// SafeExecutionSuccess is the Safe's ExecutionSuccess or
+	// ExecutionFailure event; unknown without an RPC client
+	SafeExecutionSuccess SafeExecutionStatus
+	// TxHash is the on-chain transaction hash, empty if not known
+	TxHash string
+	// BlockNumber is the inclusion block, 0 if not known
+	BlockNumber uint64
+	// GasUsed is the gas the transaction used, 0 if not known
+	GasUsed uint64
+}
+
+// Succeeded reports whether the transaction is known to have taken effect:
+// the receipt succeeded and the Safe reported ExecutionSuccess
+func (o *ExecutionOutcome) Succeeded() bool {
+	return o.OnChainStatus == OnChainStatusSuccess && o.SafeExecutionSuccess == SafeExecutionSucceeded
+}
+
+// OutcomeWaiter is implemented by clients that can wait for a transaction's
+// on-chain outcome (see ClientRelayerTransactionResponse.WaitForOutcome)
+type OutcomeWaiter interface {
+	WaitForOutcomeWithOptions(transactionID string, maxPolls, pollFrequency int) (*ExecutionOutcome, error)
+}
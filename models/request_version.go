@@ -0,0 +1,279 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/davidt58/go-builder-relayer-client/errors"
+)
+
+// RequestVersion identifies the submission payload schema
+type RequestVersion int
+
+const (
+	// RequestVersionV1 is the original schema: to/value/data/operation as a
+	// single value or parallel arrays and a flat signature string
+	RequestVersionV1 RequestVersion = 1
+	// RequestVersionV2 carries one object per transaction and a signature
+	// envelope holding the signer, signature and parameters
+	RequestVersionV2 RequestVersion = 2
+
+	// LatestRequestVersion is the newest schema this client can emit
+	LatestRequestVersion = RequestVersionV2
+)
+
+// String returns "v1", "v2", ...
+func (v RequestVersion) String() string {
+	return fmt.Sprintf("v%d", int(v))
+}
+
+// IsValid returns true if the client can emit this version
+func (v RequestVersion) IsValid() bool {
+	return v >= RequestVersionV1 && v <= LatestRequestVersion
+}
+
+// RequestTransaction is a single call in a v2 request
+type RequestTransaction struct {
+	// To is the destination address
+	To string `json:"to"`
+	// Value is the amount of native token to send (in wei, as string)
+	Value string `json:"value"`
+	// Data is the calldata (hex string)
+	Data string `json:"data"`
+	// Operation is the operation type (0 = Call, 1 = DelegateCall)
+	Operation OperationType `json:"operation"`
+}
+
+// SignatureEnvelope is the v2 signature block
+type SignatureEnvelope struct {
+	// Signer is the address that produced the signature
+	Signer string `json:"signer"`
+	// Data is the packed signature (hex string)
+	Data string `json:"data"`
+	// Type is how Data is verified (optional)
+	Type SignatureType `json:"type,omitempty"`
+	// Params are the signed Safe or SafeProxyFactory parameters
+	Params *SignatureParams `json:"params,omitempty"`
+}
+
+// TransactionRequestV2 is the v2 submission payload
+type TransactionRequestV2 struct {
+	// Version is always 2
+	Version RequestVersion `json:"version"`
+	// Type is the transaction type (SAFE or SAFE-CREATE)
+	Type string `json:"type"`
+	// From is the signer address
+	From string `json:"from"`
+	// ProxyWallet is the Safe wallet address
+	ProxyWallet string `json:"proxyWallet"`
+	// Transactions are the calls, one object each
+	Transactions []RequestTransaction `json:"transactions"`
+	// Signature is the signature envelope
+	Signature SignatureEnvelope `json:"signature"`
+	// Nonce is the transaction nonce (optional)
+	Nonce *string `json:"nonce,omitempty"`
+	// Metadata is optional metadata for the transaction
+	Metadata *string `json:"metadata,omitempty"`
+}
+
+// Versioned returns the payload to submit for version: the request itself
+// for v1, or its v2 conversion
+func (r *TransactionRequest) Versioned(version RequestVersion) (interface{}, error) {
+	switch version {
+	case RequestVersionV1:
+		return r, nil
+	case RequestVersionV2:
+		return r.ToV2()
+	default:
+		return nil, errors.ErrUnsupportedRequestVersion(int(version))
+	}
+}
+
+// ToV2 converts a v1 request into the v2 shape. Single values and parallel
+// arrays of to/value/data/operation both become per-transaction objects;
+// a missing operation falls back to signatureParams.operation, then Call.
+func (r *TransactionRequest) ToV2() (*TransactionRequestV2, error) {
+	transactions, err := requestTransactions(r.To, r.Value, r.Data, r.Operation, r.SignatureParams)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TransactionRequestV2{
+		Version:      RequestVersionV2,
+		Type:         r.Type,
+		From:         r.From,
+		ProxyWallet:  r.ProxyWallet,
+		Transactions: transactions,
+		Signature: SignatureEnvelope{
+			Signer: r.From,
+			Data:   r.Signature,
+			Type:   r.SignatureType,
+			Params: r.SignatureParams,
+		},
+		Nonce:    r.Nonce,
+		Metadata: r.Metadata,
+	}, nil
+}
+
+// ToV1 converts a v2 request back into the v1 shape. One transaction becomes
+// single to/value/data values (the operation is carried by signatureParams);
+// several become parallel arrays including operation.
+func (r *TransactionRequestV2) ToV1() (*TransactionRequest, error) {
+	if len(r.Transactions) == 0 {
+		return nil, errors.ErrMissingRequiredField("transactions")
+	}
+
+	request := &TransactionRequest{
+		Type:            r.Type,
+		From:            r.From,
+		ProxyWallet:     r.ProxyWallet,
+		Signature:       r.Signature.Data,
+		SignatureType:   r.Signature.Type,
+		SignatureParams: r.Signature.Params,
+		Nonce:           r.Nonce,
+		Metadata:        r.Metadata,
+	}
+
+	var to, value, data, operation interface{}
+	if len(r.Transactions) == 1 {
+		txn := r.Transactions[0]
+		to, value, data = txn.To, txn.Value, txn.Data
+	} else {
+		tos := make([]string, len(r.Transactions))
+		values := make([]string, len(r.Transactions))
+		datas := make([]string, len(r.Transactions))
+		operations := make([]OperationType, len(r.Transactions))
+		for i, txn := range r.Transactions {
+			tos[i], values[i], datas[i], operations[i] = txn.To, txn.Value, txn.Data, txn.Operation
+		}
+		to, value, data, operation = tos, values, datas, operations
+	}
+
+	var err error
+	if request.To, err = json.Marshal(to); err != nil {
+		return nil, errors.ErrJSONMarshalFailed(err)
+	}
+	if request.Value, err = json.Marshal(value); err != nil {
+		return nil, errors.ErrJSONMarshalFailed(err)
+	}
+	if request.Data, err = json.Marshal(data); err != nil {
+		return nil, errors.ErrJSONMarshalFailed(err)
+	}
+	if operation != nil {
+		if request.Operation, err = json.Marshal(operation); err != nil {
+			return nil, errors.ErrJSONMarshalFailed(err)
+		}
+	}
+	return request, nil
+}
+
+// DecodeTransactionRequest decodes a submission payload of either version,
+// returning it in the v1 shape together with the version it was sent as
+func DecodeTransactionRequest(data []byte) (*TransactionRequest, RequestVersion, error) {
+	var probe struct {
+		Version *RequestVersion `json:"version"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, 0, errors.ErrJSONUnmarshalFailed(err)
+	}
+
+	version := RequestVersionV1
+	if probe.Version != nil {
+		version = *probe.Version
+	}
+
+	switch version {
+	case RequestVersionV1:
+		var request TransactionRequest
+		if err := json.Unmarshal(data, &request); err != nil {
+			return nil, 0, errors.ErrJSONUnmarshalFailed(err)
+		}
+		return &request, version, nil
+	case RequestVersionV2:
+		var v2 TransactionRequestV2
+		if err := json.Unmarshal(data, &v2); err != nil {
+			return nil, 0, errors.ErrJSONUnmarshalFailed(err)
+		}
+		request, err := v2.ToV1()
+		return request, version, err
+	default:
+		return nil, 0, errors.ErrUnsupportedRequestVersion(int(version))
+	}
+}
+
+// requestTransactions splits v1 to/value/data/operation fields, each a
+// single value or parallel arrays, into one RequestTransaction per to. A
+// single call's operation is carried by params.
+func requestTransactions(to, value, data, operation json.RawMessage, params *SignatureParams) ([]RequestTransaction, error) {
+	tos, err := rawStrings("to", to)
+	if err != nil {
+		return nil, err
+	}
+	values, err := rawStrings("value", value)
+	if err != nil {
+		return nil, err
+	}
+	datas, err := rawStrings("data", data)
+	if err != nil {
+		return nil, err
+	}
+	operations, err := rawOperations(operation)
+	if err != nil {
+		return nil, err
+	}
+
+	defaultOperation := Call
+	if params != nil && params.Operation != nil && *params.Operation == "1" {
+		defaultOperation = DelegateCall
+	}
+
+	transactions := make([]RequestTransaction, len(tos))
+	for i, to := range tos {
+		txn := RequestTransaction{To: to, Operation: defaultOperation}
+		if i < len(values) {
+			txn.Value = values[i]
+		}
+		if i < len(datas) {
+			txn.Data = datas[i]
+		}
+		if i < len(operations) {
+			txn.Operation = operations[i]
+		}
+		transactions[i] = txn
+	}
+	return transactions, nil
+}
+
+// rawStrings decodes a v1 field holding a string or an array of strings
+func rawStrings(field string, raw json.RawMessage) ([]string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+
+	var single string
+	if err := json.Unmarshal(raw, &single); err == nil {
+		return []string{single}, nil
+	}
+	var many []string
+	if err := json.Unmarshal(raw, &many); err != nil {
+		return nil, errors.NewRelayerClientError(fmt.Sprintf("%s must be a string or array of strings", field), err)
+	}
+	return many, nil
+}
+
+// rawOperations decodes a v1 operation field holding an int or an array of ints
+func rawOperations(raw json.RawMessage) ([]OperationType, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+
+	var single OperationType
+	if err := json.Unmarshal(raw, &single); err == nil {
+		return []OperationType{single}, nil
+	}
+	var many []OperationType
+	if err := json.Unmarshal(raw, &many); err != nil {
+		return nil, errors.NewRelayerClientError("operation must be an integer or array of integers", err)
+	}
+	return many, nil
+}
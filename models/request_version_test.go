@@ -0,0 +1,142 @@
+package models
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestTransactionRequest_ToV2(t *testing.T) {
+	delegate := "1"
+	nonce := "3"
+
+	tests := []struct {
+		name    string
+		request TransactionRequest
+		want    []RequestTransaction
+	}{
+		{
+			name: "single transaction",
+			request: TransactionRequest{
+				To:    json.RawMessage(`"0xaa"`),
+				Value: json.RawMessage(`"5"`),
+				Data:  json.RawMessage(`"0x01"`),
+			},
+			want: []RequestTransaction{{To: "0xaa", Value: "5", Data: "0x01", Operation: Call}},
+		},
+		{
+			name: "operation from signature params",
+			request: TransactionRequest{
+				To:              json.RawMessage(`"0xaa"`),
+				Data:            json.RawMessage(`"0x"`),
+				SignatureParams: &SignatureParams{Operation: &delegate},
+			},
+			want: []RequestTransaction{{To: "0xaa", Data: "0x", Operation: DelegateCall}},
+		},
+		{
+			name: "parallel arrays",
+			request: TransactionRequest{
+				To:        json.RawMessage(`["0xaa","0xbb"]`),
+				Value:     json.RawMessage(`["1","2"]`),
+				Data:      json.RawMessage(`["0x01","0x02"]`),
+				Operation: json.RawMessage(`[0,1]`),
+				Nonce:     &nonce,
+			},
+			want: []RequestTransaction{
+				{To: "0xaa", Value: "1", Data: "0x01", Operation: Call},
+				{To: "0xbb", Value: "2", Data: "0x02", Operation: DelegateCall},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v2, err := tt.request.ToV2()
+			if err != nil {
+				t.Fatalf("ToV2 failed: %v", err)
+			}
+			if v2.Version != RequestVersionV2 {
+				t.Errorf("Version = %v, want v2", v2.Version)
+			}
+			if !reflect.DeepEqual(v2.Transactions, tt.want) {
+				t.Errorf("Transactions = %+v, want %+v", v2.Transactions, tt.want)
+			}
+
+			// Encoding as v2 and decoding gives back an equivalent v1 request
+			data, err := json.Marshal(v2)
+			if err != nil {
+				t.Fatalf("Marshal failed: %v", err)
+			}
+			decoded, version, err := DecodeTransactionRequest(data)
+			if err != nil {
+				t.Fatalf("DecodeTransactionRequest failed: %v", err)
+			}
+			if version != RequestVersionV2 {
+				t.Errorf("version = %v, want v2", version)
+			}
+			again, err := decoded.ToV2()
+			if err != nil {
+				t.Fatalf("ToV2 failed: %v", err)
+			}
+			if !reflect.DeepEqual(again, v2) {
+				t.Errorf("round trip = %+v, want %+v", again, v2)
+			}
+		})
+	}
+}
+
+func TestDecodeTransactionRequest(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        string
+		wantVersion RequestVersion
+		shouldErr   bool
+	}{
+		{
+			name:        "v1 without version field",
+			data:        `{"type":"SAFE","to":"0xaa","data":"0x"}`,
+			wantVersion: RequestVersionV1,
+		},
+		{
+			name:        "v2",
+			data:        `{"version":2,"type":"SAFE","transactions":[{"to":"0xaa","value":"0","data":"0x","operation":0}]}`,
+			wantVersion: RequestVersionV2,
+		},
+		{
+			name:      "v2 without transactions",
+			data:      `{"version":2,"type":"SAFE","transactions":[]}`,
+			shouldErr: true,
+		},
+		{
+			name:      "unknown version",
+			data:      `{"version":9}`,
+			shouldErr: true,
+		},
+		{
+			name:      "malformed",
+			data:      `{`,
+			shouldErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, version, err := DecodeTransactionRequest([]byte(tt.data))
+			if tt.shouldErr {
+				if err == nil {
+					t.Fatal("Expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DecodeTransactionRequest failed: %v", err)
+			}
+			if version != tt.wantVersion {
+				t.Errorf("version = %v, want %v", version, tt.wantVersion)
+			}
+			if string(request.To) != `"0xaa"` {
+				t.Errorf("To = %s, want \"0xaa\"", request.To)
+			}
+		})
+	}
+}
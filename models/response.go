@@ -1,5 +1,14 @@
 package models
 
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/davidt58/go-builder-relayer-client/clock"
+	"github.com/davidt58/go-builder-relayer-client/errors"
+)
+
 // SubmitTransactionResponse represents the response from submitting a transaction
 type SubmitTransactionResponse struct {
 	// TransactionID is the unique identifier for the submitted transaction
@@ -33,8 +42,33 @@ type ErrorResponse struct {
 type ClientRelayerTransactionResponse struct {
 	// TransactionID is the unique identifier for the transaction
 	TransactionID string
+	// OperationID is the client-generated ID sent as X-Client-Operation-Id on
+	// every request of the Deploy/Execute call that produced this response
+	OperationID string
+	// ChainID is the chain the transaction was submitted for
+	ChainID int64
+	// Type is the submitted transaction's type
+	Type TransactionType
+	// SafeAddress is the Safe the transaction was submitted for
+	SafeAddress string
+	// Nonce is the Safe nonce the transaction was signed with, if known
+	Nonce string
+	// StartedAt is when the Deploy or Execute call producing the response
+	// started; zero if unknown
+	StartedAt time.Time
+	// SignedAt is when the request was signed; zero if unknown, e.g. for a
+	// request signed elsewhere
+	SignedAt time.Time
+	// SubmittedAt is when the relayer accepted the submission
+	SubmittedAt time.Time
+	// timeline records the relayer states seen by polls, for LatencyBreakdown
+	timeline *StateTimeline
 	// client reference for making API calls
 	client RelayClientInterface
+	// waitHook runs after a successful Wait and can veto the result
+	waitHook func(*RelayerTransaction) error
+	// clock times the waits of WaitForHash; nil means the system clock
+	clock clock.Clock
 }
 
 // RelayClientInterface defines the interface needed by ClientRelayerTransactionResponse
@@ -43,11 +77,134 @@ type RelayClientInterface interface {
 	PollUntilState(transactionID string, states []RelayerTransactionState, failState RelayerTransactionState, maxPolls, pollFrequency int) (*RelayerTransaction, error)
 }
 
+// ConfirmationWaiter is implemented by clients that can wait for a
+// confirmation depth on chain (see WithConfirmations)
+type ConfirmationWaiter interface {
+	WaitForConfirmationsWithOptions(transactionID string, n uint64, maxPolls, pollFrequency int) (*ConfirmationResult, error)
+}
+
+// ConfirmationResult is the outcome of waiting for a confirmation depth
+type ConfirmationResult struct {
+	// Transaction is the last relayer view of the transaction
+	Transaction *RelayerTransaction
+	// BlockNumber is the block the transaction was included in
+	BlockNumber uint64
+	// Confirmations is the block depth reached, counting the inclusion block
+	Confirmations uint64
+	// Reorgs is the number of times the transaction was reorged out while waiting
+	Reorgs int
+}
+
+// WaitOption configures Wait
+type WaitOption func(*waitOptions)
+
+// waitOptions holds the settings applied by WaitOption
+type waitOptions struct {
+	confirmations uint64
+}
+
+// WithConfirmations makes Wait return only once the transaction's block is
+// n blocks deep on chain, regardless of the relayer's confirmation policy.
+// The client must implement ConfirmationWaiter and have an RPC client.
+func WithConfirmations(n uint64) WaitOption {
+	return func(o *waitOptions) {
+		o.confirmations = n
+	}
+}
+
 // NewClientRelayerTransactionResponse creates a new response wrapper
 func NewClientRelayerTransactionResponse(transactionID string) *ClientRelayerTransactionResponse {
 	return &ClientRelayerTransactionResponse{
 		TransactionID: transactionID,
+		timeline:      NewStateTimeline(),
+	}
+}
+
+// ResponseDocumentVersion is the version of the document MarshalJSON writes
+const ResponseDocumentVersion = 1
+
+// responseDocument is the JSON form of a ClientRelayerTransactionResponse
+type responseDocument struct {
+	Version       int             `json:"version"`
+	TransactionID string          `json:"transactionId"`
+	OperationID   string          `json:"operationId,omitempty"`
+	ChainID       int64           `json:"chainId"`
+	Type          TransactionType `json:"type,omitempty"`
+	SafeAddress   string          `json:"safeAddress,omitempty"`
+	Nonce         string          `json:"nonce,omitempty"`
+	StartedAt     *time.Time      `json:"startedAt,omitempty"`
+	SignedAt      *time.Time      `json:"signedAt,omitempty"`
+	SubmittedAt   *time.Time      `json:"submittedAt,omitempty"`
+}
+
+// timestampOf returns t in UTC for a responseDocument, or nil if it is zero
+func timestampOf(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	utc := t.UTC()
+	return &utc
+}
+
+// timeValue dereferences a responseDocument timestamp, returning the zero time for nil
+func timeValue(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
 	}
+	return *t
+}
+
+// MarshalJSON implements json.Marshaler, writing a versioned document of
+// the response's identifying fields that can be stored or queued and later
+// reattached to a client (see client.RelayClient.AttachResponse). The
+// client reference, clock and wait hook are not part of it.
+func (r ClientRelayerTransactionResponse) MarshalJSON() ([]byte, error) {
+	doc := responseDocument{
+		Version:       ResponseDocumentVersion,
+		TransactionID: r.TransactionID,
+		OperationID:   r.OperationID,
+		ChainID:       r.ChainID,
+		Type:          r.Type,
+		SafeAddress:   r.SafeAddress,
+		Nonce:         r.Nonce,
+		StartedAt:     timestampOf(r.StartedAt),
+		SignedAt:      timestampOf(r.SignedAt),
+		SubmittedAt:   timestampOf(r.SubmittedAt),
+	}
+	return json.Marshal(doc)
+}
+
+// UnmarshalJSON implements json.Unmarshaler for documents written by
+// MarshalJSON. The result has no client until one is attached; documents
+// of a newer version or without a transaction or chain ID are rejected.
+func (r *ClientRelayerTransactionResponse) UnmarshalJSON(data []byte) error {
+	var doc responseDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return errors.ErrJSONUnmarshalFailed(err)
+	}
+	if doc.Version < 1 || doc.Version > ResponseDocumentVersion {
+		return errors.NewRelayerClientError(fmt.Sprintf("unsupported response document version %d", doc.Version), nil)
+	}
+	if doc.TransactionID == "" {
+		return errors.ErrMissingRequiredField("transactionId")
+	}
+	if doc.ChainID == 0 {
+		return errors.ErrMissingRequiredField("chainId")
+	}
+
+	*r = ClientRelayerTransactionResponse{
+		TransactionID: doc.TransactionID,
+		OperationID:   doc.OperationID,
+		ChainID:       doc.ChainID,
+		Type:          doc.Type,
+		SafeAddress:   doc.SafeAddress,
+		Nonce:         doc.Nonce,
+		StartedAt:     timeValue(doc.StartedAt),
+		SignedAt:      timeValue(doc.SignedAt),
+		SubmittedAt:   timeValue(doc.SubmittedAt),
+		timeline:      NewStateTimeline(),
+	}
+	return nil
 }
 
 // SetClient sets the client reference for making API calls
@@ -55,6 +212,34 @@ func (r *ClientRelayerTransactionResponse) SetClient(client RelayClientInterface
 	r.client = client
 }
 
+// SetClock sets the clock WaitForHash waits on between polls
+func (r *ClientRelayerTransactionResponse) SetClock(c clock.Clock) {
+	r.clock = c
+}
+
+// SetWaitHook registers a check that runs after Wait, WaitWithOptions or
+// WaitUntilMined succeed; its error is returned alongside the transaction
+func (r *ClientRelayerTransactionResponse) SetWaitHook(hook func(*RelayerTransaction) error) {
+	r.waitHook = hook
+}
+
+// RunWaitHook applies the wait hook, if any, to a transaction that reached
+// its target state. Clients waiting on several responses at once use it.
+func (r *ClientRelayerTransactionResponse) RunWaitHook(txn *RelayerTransaction) error {
+	if r.waitHook == nil {
+		return nil
+	}
+	return r.waitHook(txn)
+}
+
+// runWaitHook applies the wait hook to a successful poll result
+func (r *ClientRelayerTransactionResponse) runWaitHook(txn *RelayerTransaction, err error) (*RelayerTransaction, error) {
+	if err != nil {
+		return txn, err
+	}
+	return txn, r.RunWaitHook(txn)
+}
+
 // GetTransaction fetches the current transaction details
 func (r *ClientRelayerTransactionResponse) GetTransaction() (*RelayerTransaction, error) {
 	if r.client == nil {
@@ -63,42 +248,214 @@ func (r *ClientRelayerTransactionResponse) GetTransaction() (*RelayerTransaction
 	return r.client.GetTransaction(r.TransactionID)
 }
 
-// Wait polls until the transaction reaches a terminal state (confirmed, failed, or invalid)
-// Default polling: max 100 polls, every 2 seconds
-func (r *ClientRelayerTransactionResponse) Wait() (*RelayerTransaction, error) {
+// WaitOptions configures how Wait, WaitWithOptions and WaitUntilMined poll
+// the relayer. Zero fields take the values of DefaultWaitOptions.
+type WaitOptions struct {
+	// TargetStates are the states that end the wait successfully
+	TargetStates []RelayerTransactionState
+	// FailState is the state that ends the wait with an error
+	FailState RelayerTransactionState
+	// MaxPolls is the number of polls before giving up
+	MaxPolls int
+	// Interval is the wait between polls, rounded up to whole seconds
+	Interval time.Duration
+	// Timeout bounds a whole multi-transaction wait (WaitAll, WaitAny);
+	// zero means only MaxPolls does. Single-transaction waits ignore it.
+	Timeout time.Duration
+}
+
+// DefaultWaitOptions returns the options Wait uses: wait for STATE_CONFIRMED,
+// fail on STATE_FAILED, and poll up to 100 times every 2 seconds. These
+// defaults are stable; use WaitUntilMined or TargetStates to also accept
+// STATE_MINED.
+func DefaultWaitOptions() WaitOptions {
+	return WaitOptions{
+		TargetStates: []RelayerTransactionState{STATE_CONFIRMED},
+		FailState:    STATE_FAILED,
+		MaxPolls:     100,
+		Interval:     2 * time.Second,
+	}
+}
+
+// WithDefaults fills the zero fields of o from DefaultWaitOptions
+func (o WaitOptions) WithDefaults() WaitOptions {
+	defaults := DefaultWaitOptions()
+	if len(o.TargetStates) == 0 {
+		o.TargetStates = defaults.TargetStates
+	}
+	if o.FailState == "" {
+		o.FailState = defaults.FailState
+	}
+	if o.MaxPolls <= 0 {
+		o.MaxPolls = defaults.MaxPolls
+	}
+	if o.Interval <= 0 {
+		o.Interval = defaults.Interval
+	}
+	return o
+}
+
+// PollInterval returns the interval rounded up to whole seconds
+func (o WaitOptions) PollInterval() time.Duration {
+	return time.Duration(o.pollFrequency()) * time.Second
+}
+
+// pollFrequency returns the interval in whole seconds, rounded up
+func (o WaitOptions) pollFrequency() int {
+	return int((o.Interval + time.Second - 1) / time.Second)
+}
+
+// Wait polls until the transaction reaches a terminal state with
+// DefaultWaitOptions (STATE_CONFIRMED). The returned transaction's State is
+// the target state that matched, and its WaitStatus tells mined from confirmed.
+// WithConfirmations waits for a block depth instead of STATE_CONFIRMED.
+func (r *ClientRelayerTransactionResponse) Wait(opts ...WaitOption) (*RelayerTransaction, error) {
 	if r.client == nil {
 		return nil, &ClientError{Message: "client not configured"}
 	}
 
-	// Poll until confirmed, failed, or invalid
-	targetStates := []RelayerTransactionState{STATE_CONFIRMED}
-	failState := STATE_FAILED
+	var options waitOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.confirmations > 0 {
+		result, err := r.WaitForConfirmations(options.confirmations)
+		if err != nil {
+			return nil, err
+		}
+		return r.runWaitHook(result.Transaction, nil)
+	}
 
-	return r.client.PollUntilState(r.TransactionID, targetStates, failState, 100, 2)
+	return r.WaitWithOptions(DefaultWaitOptions())
 }
 
-// WaitWithOptions polls until the transaction reaches a terminal state with custom options
-func (r *ClientRelayerTransactionResponse) WaitWithOptions(maxPolls, pollFrequency int) (*RelayerTransaction, error) {
+// WaitWithOptions polls until the transaction reaches one of opts.TargetStates.
+// Zero fields of opts take the defaults, so WaitWithOptions(WaitOptions{})
+// behaves exactly like Wait(). The returned transaction's State is the
+// target state that matched.
+func (r *ClientRelayerTransactionResponse) WaitWithOptions(opts WaitOptions) (*RelayerTransaction, error) {
 	if r.client == nil {
 		return nil, &ClientError{Message: "client not configured"}
 	}
 
-	targetStates := []RelayerTransactionState{STATE_CONFIRMED}
-	failState := STATE_FAILED
-
-	return r.client.PollUntilState(r.TransactionID, targetStates, failState, maxPolls, pollFrequency)
+	opts = opts.WithDefaults()
+	return r.runWaitHook(r.client.PollUntilState(r.TransactionID, opts.TargetStates, opts.FailState, opts.MaxPolls, opts.pollFrequency()))
 }
 
-// WaitUntilMined polls until the transaction is mined (may not be confirmed yet)
+// WaitUntilMined polls until the transaction is mined (may not be confirmed yet).
+// Use the returned transaction's State or WaitStatus to tell mined from confirmed.
 func (r *ClientRelayerTransactionResponse) WaitUntilMined() (*RelayerTransaction, error) {
+	return r.WaitWithOptions(WaitOptions{
+		TargetStates: []RelayerTransactionState{STATE_MINED, STATE_CONFIRMED},
+	})
+}
+
+// WaitForConfirmations waits until the transaction's block is n blocks deep
+// and reports the depth reached and any reorgs seen on the way. The wait
+// hook is not run; use Wait with WithConfirmations for that.
+func (r *ClientRelayerTransactionResponse) WaitForConfirmations(n uint64) (*ConfirmationResult, error) {
+	if r.client == nil {
+		return nil, &ClientError{Message: "client not configured"}
+	}
+	waiter, ok := r.client.(ConfirmationWaiter)
+	if !ok {
+		return nil, &ClientError{Message: "client cannot wait for confirmations"}
+	}
+	return waiter.WaitForConfirmationsWithOptions(r.TransactionID, n, 100, 2)
+}
+
+// WaitForOutcome waits until the transaction is mined and reports its relayer
+// state together with its on-chain and Safe execution status, which are
+// unknown unless the client has an RPC client. The wait hook is not run.
+func (r *ClientRelayerTransactionResponse) WaitForOutcome() (*ExecutionOutcome, error) {
 	if r.client == nil {
 		return nil, &ClientError{Message: "client not configured"}
 	}
+	waiter, ok := r.client.(OutcomeWaiter)
+	if !ok {
+		return nil, &ClientError{Message: "client cannot wait for outcomes"}
+	}
+	return waiter.WaitForOutcomeWithOptions(r.TransactionID, 100, 2)
+}
+
+// WaitForHash polls until the relayer reports the transaction hash, whatever
+// the state (the hash is often known before STATE_MINED). It fails fast on
+// STATE_FAILED or STATE_INVALID, and with a CancelledError on STATE_CANCELLED
+// or STATE_REPLACED. The wait hook is not run since the
+// transaction may not be mined yet.
+// Default polling: max 100 polls, every 2 seconds
+func (r *ClientRelayerTransactionResponse) WaitForHash() (*RelayerTransaction, error) {
+	return r.WaitForHashWithOptions(100, 2)
+}
+
+// WaitForHashWithOptions polls until the transaction hash is known with custom options
+func (r *ClientRelayerTransactionResponse) WaitForHashWithOptions(maxPolls, pollFrequency int) (*RelayerTransaction, error) {
+	if r.client == nil {
+		return nil, &ClientError{Message: "client not configured"}
+	}
+	if maxPolls <= 0 {
+		maxPolls = 100
+	}
+	if pollFrequency <= 0 {
+		pollFrequency = 2
+	}
 
-	targetStates := []RelayerTransactionState{STATE_MINED, STATE_CONFIRMED}
-	failState := STATE_FAILED
+	for i := 0; i < maxPolls; i++ {
+		txn, err := r.client.GetTransaction(r.TransactionID)
+		if err != nil {
+			return nil, err
+		}
+
+		if txn.IsFailed() {
+			return txn, errors.ErrTransactionFailed(r.TransactionID, string(txn.State))
+		}
+		if txn.IsCancelled() {
+			return txn, errors.ErrTransactionCancelled(r.TransactionID, string(txn.State), stringValue(txn.ReplacedBy))
+		}
+		if txn.IsMined() {
+			return txn, nil
+		}
+
+		if i < maxPolls-1 {
+			clock.OrReal(r.clock).Sleep(time.Duration(pollFrequency) * time.Second)
+		}
+	}
 
-	return r.client.PollUntilState(r.TransactionID, targetStates, failState, 100, 2)
+	return nil, errors.ErrPollingTimeout(r.TransactionID)
+}
+
+// CancelTransactionRequest asks the relayer to cancel a transaction that has
+// not executed yet, optionally marking it superseded by a replacement
+type CancelTransactionRequest struct {
+	// TransactionID is the transaction to cancel
+	TransactionID string `json:"transactionId"`
+	// ReplacedBy is the ID of the replacement transaction, if any
+	ReplacedBy string `json:"replacedBy,omitempty"`
+}
+
+// CancelTransactionResponse is the response from the cancel endpoint
+type CancelTransactionResponse struct {
+	// TransactionID is the cancelled transaction
+	TransactionID string `json:"transactionId"`
+	// State is the new state (STATE_CANCELLED or STATE_REPLACED)
+	State RelayerTransactionState `json:"state"`
+}
+
+// CapabilitiesResponse is the response from the capabilities endpoint
+type CapabilitiesResponse struct {
+	// RequestVersions are the submission payload versions the relayer accepts
+	RequestVersions []RequestVersion `json:"requestVersions"`
+	// LongPoll reports that GET_TRANSACTION accepts a wait parameter and
+	// holds the request until the transaction changes
+	LongPoll bool `json:"longPoll,omitempty"`
+}
+
+// stringValue dereferences s, returning "" for nil
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
 }
 
 // ClientError represents an error from the client helper methods
@@ -110,3 +467,79 @@ type ClientError struct {
 func (e *ClientError) Error() string {
 	return e.Message
 }
+
+// RelayerStatus is the relayer's health, from the status endpoint or inferred
+type RelayerStatus struct {
+	// Healthy is false during maintenance or when submissions are failing
+	Healthy bool `json:"healthy"`
+	// MaintenanceUntil is the end of an announced maintenance window
+	MaintenanceUntil *time.Time `json:"maintenanceUntil,omitempty"`
+	// Message is a human-readable status message
+	Message string `json:"message,omitempty"`
+	// Inferred is true when the relayer has no status endpoint and health was
+	// inferred from a probe and recent submission errors
+	Inferred bool `json:"-"`
+}
+
+// Usage is a snapshot of the builder's relayed-transaction quota, from the
+// usage endpoint or the rate-limit headers of the latest response
+type Usage struct {
+	// Limit is the number of transactions allowed in the current period
+	Limit int64 `json:"limit"`
+	// Remaining is the number of transactions left in the current period
+	Remaining int64 `json:"remaining"`
+	// ResetAt is when the current period ends, if known
+	ResetAt *time.Time `json:"resetAt,omitempty"`
+	// UpdatedAt is when the snapshot was taken
+	UpdatedAt time.Time `json:"-"`
+}
+
+// RemainingFraction returns Remaining as a fraction of Limit, or 1 when the
+// limit is unknown
+func (u Usage) RemainingFraction() float64 {
+	if u.Limit <= 0 {
+		return 1
+	}
+	return float64(u.Remaining) / float64(u.Limit)
+}
+
+// KeyScope names a permission a builder API key can hold
+type KeyScope string
+
+const (
+	// KeyScopeRead allows the authenticated read endpoints
+	KeyScopeRead KeyScope = "read"
+	// KeyScopeSubmit allows submitting transactions
+	KeyScopeSubmit KeyScope = "submit"
+)
+
+// KeyCapabilities are the scopes the relayer grants a builder API key
+type KeyCapabilities struct {
+	// KeyID is the API key the scopes belong to
+	KeyID string `json:"keyId"`
+	// Scopes are the permissions the key holds
+	Scopes []KeyScope `json:"scopes"`
+}
+
+// Has reports whether the key holds scope
+func (k KeyCapabilities) Has(scope KeyScope) bool {
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// RemoteValidation is the relayer's signature debug endpoint's view of a
+// submission payload
+type RemoteValidation struct {
+	// ComputedHash is the SafeTx hash the relayer computed from the payload
+	ComputedHash string `json:"computedHash"`
+	// RecoveredSigners are the addresses the relayer recovered from the signature
+	RecoveredSigners []string `json:"recoveredSigners"`
+	// Valid reports whether the relayer would accept the signature
+	Valid bool `json:"valid"`
+	// Reason explains why the signature is invalid
+	Reason string `json:"reason,omitempty"`
+}
@@ -0,0 +1,339 @@
+package models
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/davidt58/go-builder-relayer-client/clock"
+	"github.com/davidt58/go-builder-relayer-client/errors"
+)
+
+// scriptedClient returns a fixed sequence of transaction snapshots
+type scriptedClient struct {
+	script []RelayerTransaction
+	polls  int
+}
+
+func (s *scriptedClient) GetTransaction(transactionID string) (*RelayerTransaction, error) {
+	i := s.polls
+	if i >= len(s.script) {
+		i = len(s.script) - 1
+	}
+	s.polls++
+	txn := s.script[i]
+	txn.TransactionID = transactionID
+	return &txn, nil
+}
+
+func (s *scriptedClient) PollUntilState(transactionID string, states []RelayerTransactionState, failState RelayerTransactionState, maxPolls, pollFrequency int) (*RelayerTransaction, error) {
+	return nil, stderrors.New("not scripted")
+}
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func TestWaitForHash(t *testing.T) {
+	hash := strPtr("0xabc")
+
+	tests := []struct {
+		name      string
+		script    []RelayerTransaction
+		wantPolls int
+		wantState WaitStatus
+		shouldErr bool
+	}{
+		{
+			name: "hash before mined",
+			script: []RelayerTransaction{
+				{State: STATE_NEW},
+				{State: STATE_EXECUTED, Hash: hash},
+				{State: STATE_MINED, Hash: hash},
+			},
+			wantPolls: 2,
+			wantState: WaitStatusHashKnown,
+		},
+		{
+			name: "hash at mined",
+			script: []RelayerTransaction{
+				{State: STATE_NEW},
+				{State: STATE_MINED, Hash: hash},
+			},
+			wantPolls: 2,
+			wantState: WaitStatusMined,
+		},
+		{
+			name: "fails fast",
+			script: []RelayerTransaction{
+				{State: STATE_NEW},
+				{State: STATE_FAILED},
+				{State: STATE_FAILED},
+			},
+			wantPolls: 2,
+			wantState: WaitStatusFailed,
+			shouldErr: true,
+		},
+		{
+			name: "invalid",
+			script: []RelayerTransaction{
+				{State: STATE_INVALID},
+			},
+			wantPolls: 1,
+			wantState: WaitStatusFailed,
+			shouldErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &scriptedClient{script: tt.script}
+			clk := clock.NewAutoFake(time.Time{})
+			response := NewClientRelayerTransactionResponse("tx-1")
+			response.SetClient(client)
+			response.SetClock(clk)
+
+			txn, err := response.WaitForHash()
+			if tt.shouldErr {
+				var clientErr *errors.RelayerClientError
+				if !stderrors.As(err, &clientErr) {
+					t.Fatalf("error = %v, want RelayerClientError", err)
+				}
+			} else if err != nil {
+				t.Fatalf("WaitForHash failed: %v", err)
+			}
+
+			if client.polls != tt.wantPolls {
+				t.Errorf("polls = %d, want %d", client.polls, tt.wantPolls)
+			}
+			if slept := clk.Slept(); len(slept) != tt.wantPolls-1 {
+				t.Errorf("slept %v, want %d waits between polls", slept, tt.wantPolls-1)
+			}
+			if got := txn.WaitStatus(); got != tt.wantState {
+				t.Errorf("WaitStatus() = %s, want %s", got, tt.wantState)
+			}
+		})
+	}
+}
+
+func TestWaitForHash_Timeout(t *testing.T) {
+	client := &scriptedClient{script: []RelayerTransaction{{State: STATE_NEW}}}
+	clk := clock.NewAutoFake(time.Time{})
+	response := NewClientRelayerTransactionResponse("tx-1")
+	response.SetClient(client)
+	response.SetClock(clk)
+
+	if _, err := response.WaitForHashWithOptions(3, 1); err == nil {
+		t.Error("expected polling timeout")
+	}
+	if client.polls != 3 {
+		t.Errorf("polls = %d, want 3", client.polls)
+	}
+	if elapsed := clk.Now().Sub(time.Time{}); elapsed != 2*time.Second {
+		t.Errorf("waited %s between polls, want 2s", elapsed)
+	}
+}
+
+func TestRelayerTransaction_WaitStatus(t *testing.T) {
+	hash := strPtr("0xabc")
+	tests := []struct {
+		txn  RelayerTransaction
+		want WaitStatus
+	}{
+		{RelayerTransaction{State: STATE_NEW}, WaitStatusPending},
+		{RelayerTransaction{State: STATE_EXECUTED, Hash: hash}, WaitStatusHashKnown},
+		{RelayerTransaction{State: STATE_MINED, Hash: hash}, WaitStatusMined},
+		{RelayerTransaction{State: STATE_CONFIRMED, Hash: hash}, WaitStatusConfirmed},
+		{RelayerTransaction{State: STATE_INVALID}, WaitStatusFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.txn.State), func(t *testing.T) {
+			if got := tt.txn.WaitStatus(); got != tt.want {
+				t.Errorf("WaitStatus() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// pollCall records the arguments of a PollUntilState call
+type pollCall struct {
+	states        []RelayerTransactionState
+	failState     RelayerTransactionState
+	maxPolls      int
+	pollFrequency int
+}
+
+// recordingClient records PollUntilState calls and reports the first target state
+type recordingClient struct {
+	calls []pollCall
+}
+
+func (c *recordingClient) GetTransaction(transactionID string) (*RelayerTransaction, error) {
+	return nil, stderrors.New("not scripted")
+}
+
+func (c *recordingClient) PollUntilState(transactionID string, states []RelayerTransactionState, failState RelayerTransactionState, maxPolls, pollFrequency int) (*RelayerTransaction, error) {
+	c.calls = append(c.calls, pollCall{states, failState, maxPolls, pollFrequency})
+	return &RelayerTransaction{TransactionID: transactionID, State: states[0]}, nil
+}
+
+func TestWait_Variants(t *testing.T) {
+	defaultCall := pollCall{[]RelayerTransactionState{STATE_CONFIRMED}, STATE_FAILED, 100, 2}
+
+	tests := []struct {
+		name      string
+		wait      func(r *ClientRelayerTransactionResponse) (*RelayerTransaction, error)
+		want      pollCall
+		wantState WaitStatus
+	}{
+		{
+			name:      "Wait",
+			wait:      func(r *ClientRelayerTransactionResponse) (*RelayerTransaction, error) { return r.Wait() },
+			want:      defaultCall,
+			wantState: WaitStatusConfirmed,
+		},
+		{
+			name: "WaitWithOptions zero options",
+			wait: func(r *ClientRelayerTransactionResponse) (*RelayerTransaction, error) {
+				return r.WaitWithOptions(WaitOptions{})
+			},
+			want:      defaultCall,
+			wantState: WaitStatusConfirmed,
+		},
+		{
+			name: "WaitWithOptions default options",
+			wait: func(r *ClientRelayerTransactionResponse) (*RelayerTransaction, error) {
+				return r.WaitWithOptions(DefaultWaitOptions())
+			},
+			want:      defaultCall,
+			wantState: WaitStatusConfirmed,
+		},
+		{
+			name: "WaitWithOptions more polls keeps targets",
+			wait: func(r *ClientRelayerTransactionResponse) (*RelayerTransaction, error) {
+				return r.WaitWithOptions(WaitOptions{MaxPolls: 500})
+			},
+			want:      pollCall{[]RelayerTransactionState{STATE_CONFIRMED}, STATE_FAILED, 500, 2},
+			wantState: WaitStatusConfirmed,
+		},
+		{
+			name: "WaitWithOptions custom",
+			wait: func(r *ClientRelayerTransactionResponse) (*RelayerTransaction, error) {
+				return r.WaitWithOptions(WaitOptions{
+					TargetStates: []RelayerTransactionState{STATE_MINED},
+					FailState:    STATE_INVALID,
+					MaxPolls:     5,
+					Interval:     1500 * time.Millisecond,
+				})
+			},
+			want:      pollCall{[]RelayerTransactionState{STATE_MINED}, STATE_INVALID, 5, 2},
+			wantState: WaitStatusMined,
+		},
+		{
+			name:      "WaitUntilMined",
+			wait:      func(r *ClientRelayerTransactionResponse) (*RelayerTransaction, error) { return r.WaitUntilMined() },
+			want:      pollCall{[]RelayerTransactionState{STATE_MINED, STATE_CONFIRMED}, STATE_FAILED, 100, 2},
+			wantState: WaitStatusMined,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &recordingClient{}
+			response := NewClientRelayerTransactionResponse("tx-1")
+			response.SetClient(client)
+
+			txn, err := tt.wait(response)
+			if err != nil {
+				t.Fatalf("wait failed: %v", err)
+			}
+			if len(client.calls) != 1 {
+				t.Fatalf("got %d PollUntilState calls, want 1", len(client.calls))
+			}
+			if got := client.calls[0]; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PollUntilState called with %+v, want %+v", got, tt.want)
+			}
+			if txn.WaitStatus() != tt.wantState {
+				t.Errorf("WaitStatus = %s, want %s", txn.WaitStatus(), tt.wantState)
+			}
+		})
+	}
+}
+
+func TestWait_HookRunsForEveryVariant(t *testing.T) {
+	waits := map[string]func(r *ClientRelayerTransactionResponse) (*RelayerTransaction, error){
+		"Wait": func(r *ClientRelayerTransactionResponse) (*RelayerTransaction, error) { return r.Wait() },
+		"WaitWithOptions": func(r *ClientRelayerTransactionResponse) (*RelayerTransaction, error) {
+			return r.WaitWithOptions(WaitOptions{})
+		},
+		"WaitUntilMined": func(r *ClientRelayerTransactionResponse) (*RelayerTransaction, error) { return r.WaitUntilMined() },
+	}
+
+	for name, wait := range waits {
+		t.Run(name, func(t *testing.T) {
+			response := NewClientRelayerTransactionResponse("tx-1")
+			response.SetClient(&recordingClient{})
+			hookErr := stderrors.New("vetoed")
+			response.SetWaitHook(func(*RelayerTransaction) error { return hookErr })
+
+			if _, err := wait(response); err != hookErr {
+				t.Errorf("error = %v, want the hook's error", err)
+			}
+		})
+	}
+}
+
+func TestClientRelayerTransactionResponse_JSON(t *testing.T) {
+	response := NewClientRelayerTransactionResponse("tx-1")
+	response.OperationID = "op-1"
+	response.ChainID = 137
+	response.Type = SAFE
+	response.SafeAddress = "0xd93B25cb943D14d0d34FBaF01Fc93a0f8b5F6E47"
+	response.Nonce = "7"
+	response.SubmittedAt = time.Date(2026, 3, 1, 12, 0, 0, 0, time.FixedZone("CET", 3600))
+	response.SetClient(&scriptedClient{})
+
+	data, err := json.Marshal(response)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	want := `{"version":1,"transactionId":"tx-1","operationId":"op-1","chainId":137,"type":"SAFE",` +
+		`"safeAddress":"0xd93B25cb943D14d0d34FBaF01Fc93a0f8b5F6E47","nonce":"7","submittedAt":"2026-03-01T11:00:00Z"}`
+	if string(data) != want {
+		t.Errorf("document = %s\nwant %s", data, want)
+	}
+
+	var decoded ClientRelayerTransactionResponse
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if decoded.TransactionID != "tx-1" || decoded.OperationID != "op-1" || decoded.ChainID != 137 || decoded.Type != SAFE ||
+		decoded.SafeAddress != response.SafeAddress || decoded.Nonce != "7" || !decoded.SubmittedAt.Equal(response.SubmittedAt) {
+		t.Errorf("decoded = %+v", decoded)
+	}
+	// A decoded response is detached until a client is attached
+	if _, err := decoded.Wait(); err == nil {
+		t.Error("Wait on a detached response succeeded")
+	}
+}
+
+func TestClientRelayerTransactionResponse_UnmarshalInvalid(t *testing.T) {
+	tests := map[string]string{
+		"newer version":     `{"version":2,"transactionId":"tx-1","chainId":137}`,
+		"no version":        `{"transactionId":"tx-1","chainId":137}`,
+		"no transaction ID": `{"version":1,"chainId":137}`,
+		"no chain ID":       `{"version":1,"transactionId":"tx-1"}`,
+		"not an object":     `"tx-1"`,
+	}
+	for name, document := range tests {
+		t.Run(name, func(t *testing.T) {
+			var response ClientRelayerTransactionResponse
+			if err := json.Unmarshal([]byte(document), &response); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+}
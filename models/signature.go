@@ -2,6 +2,10 @@ package models
 
 import (
 	"encoding/json"
+	"fmt"
+
+	"github.com/davidt58/go-builder-relayer-client/constants"
+	"github.com/davidt58/go-builder-relayer-client/errors"
 )
 
 // SignatureParams contains the parameters for signing a Safe transaction
@@ -39,6 +43,41 @@ func NewSplitSig(r, s string, v int) *SplitSig {
 	}
 }
 
+// SignatureType tells the relayer how a signature is verified: which hash
+// was signed and which v range the packed signature carries
+type SignatureType string
+
+const (
+	// SignatureTypeEthSign signs the EIP-191 prefixed hash; v is 31/32
+	SignatureTypeEthSign SignatureType = "eth_sign"
+	// SignatureTypeEIP712 signs the EIP-712 hash itself; v is 27/28
+	SignatureTypeEIP712 SignatureType = "eip712"
+	// SignatureTypeContract is a Safe contract (EIP-1271) signature; v is 0
+	SignatureTypeContract SignatureType = "contract"
+)
+
+// CheckV returns a SignatureTypeMismatchError unless v is the v range of
+// the type. An empty type accepts any v.
+func (t SignatureType) CheckV(v int) error {
+	var ok bool
+	switch t {
+	case "":
+		return nil
+	case SignatureTypeEthSign:
+		ok = v == 31 || v == 32
+	case SignatureTypeEIP712:
+		ok = v == 27 || v == 28
+	case SignatureTypeContract:
+		ok = v == 0
+	default:
+		return errors.NewRelayerClientError(fmt.Sprintf("unknown signature type %q", string(t)), nil)
+	}
+	if !ok {
+		return errors.ErrSignatureTypeMismatch(string(t), v)
+	}
+	return nil
+}
+
 // Signature represents a complete signature with all components
 type Signature struct {
 	// Signer is the address of the signer
@@ -47,6 +86,8 @@ type Signature struct {
 	Data string `json:"data,omitempty"`
 	// Split contains the split signature components (r, s, v)
 	Split *SplitSig `json:"split,omitempty"`
+	// SignatureType is how the signature is verified; omitted when unknown
+	SignatureType SignatureType `json:"signatureType,omitempty"`
 }
 
 // NewSignature creates a new Signature
@@ -71,6 +112,9 @@ type TransactionRequest struct {
 	Data json.RawMessage `json:"data"`
 	// Signature is the transaction signature
 	Signature string `json:"signature"`
+	// SignatureType is how Signature is verified (optional; omitted for
+	// relayers that infer it from v)
+	SignatureType SignatureType `json:"signatureType,omitempty"`
 	// SignatureParams contains additional signature parameters
 	SignatureParams *SignatureParams `json:"signatureParams,omitempty"`
 	// Value is the value(s) to send - can be string or array (optional)
@@ -107,6 +151,21 @@ type SafeTransactionData struct {
 	Nonce string `json:"nonce"`
 }
 
+// NewSignatureParams creates the SafeTransactionData signed for a Call at
+// nonce, with no gas refund: zero gas values and the zero address as gas
+// token and refund receiver
+func NewSignatureParams(nonce string) *SafeTransactionData {
+	return &SafeTransactionData{
+		Operation:      Call,
+		SafeTxGas:      "0",
+		BaseGas:        "0",
+		GasPrice:       "0",
+		GasToken:       constants.ZERO_ADDRESS,
+		RefundReceiver: constants.ZERO_ADDRESS,
+		Nonce:          nonce,
+	}
+}
+
 // EIP712Domain represents the EIP-712 domain separator
 type EIP712Domain struct {
 	// ChainID is the chain ID
@@ -0,0 +1,121 @@
+package models
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"testing"
+
+	"github.com/davidt58/go-builder-relayer-client/errors"
+)
+
+func TestSignatureType_CheckV(t *testing.T) {
+	tests := []struct {
+		sigType   SignatureType
+		v         int
+		shouldErr bool
+	}{
+		{SignatureTypeEthSign, 31, false},
+		{SignatureTypeEthSign, 32, false},
+		{SignatureTypeEthSign, 27, true},
+		{SignatureTypeEthSign, 1, true},
+		{SignatureTypeEIP712, 27, false},
+		{SignatureTypeEIP712, 28, false},
+		{SignatureTypeEIP712, 32, true},
+		{SignatureTypeEIP712, 0, true},
+		{SignatureTypeContract, 0, false},
+		{SignatureTypeContract, 27, true},
+		{"", 5, false},
+	}
+
+	for _, tt := range tests {
+		err := tt.sigType.CheckV(tt.v)
+		if (err != nil) != tt.shouldErr {
+			t.Errorf("SignatureType(%q).CheckV(%d) error = %v, shouldErr %v", tt.sigType, tt.v, err, tt.shouldErr)
+		}
+		var mismatch *errors.SignatureTypeMismatchError
+		if tt.shouldErr && (!stderrors.As(err, &mismatch) || mismatch.V != tt.v) {
+			t.Errorf("CheckV(%d) error = %v, want a SignatureTypeMismatchError", tt.v, err)
+		}
+	}
+
+	if err := SignatureType("personal_sign").CheckV(27); err == nil {
+		t.Error("CheckV should reject an unknown signature type")
+	}
+}
+
+func TestSignature_SignatureTypeWire(t *testing.T) {
+	tests := []struct {
+		name      string
+		signature Signature
+		want      string
+	}{
+		{
+			name:      "untyped",
+			signature: Signature{Signer: "0x01", Data: "0xaa"},
+			want:      `{"signer":"0x01","data":"0xaa"}`,
+		},
+		{
+			name:      "eth_sign",
+			signature: Signature{Signer: "0x01", Data: "0xaa", SignatureType: SignatureTypeEthSign},
+			want:      `{"signer":"0x01","data":"0xaa","signatureType":"eth_sign"}`,
+		},
+		{
+			name:      "eip712",
+			signature: Signature{Signer: "0x01", Data: "0xaa", SignatureType: SignatureTypeEIP712},
+			want:      `{"signer":"0x01","data":"0xaa","signatureType":"eip712"}`,
+		},
+		{
+			name:      "contract",
+			signature: Signature{Signer: "0x01", Data: "0xaa", SignatureType: SignatureTypeContract},
+			want:      `{"signer":"0x01","data":"0xaa","signatureType":"contract"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.signature)
+			if err != nil {
+				t.Fatalf("Marshal failed: %v", err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("Marshal = %s, want %s", data, tt.want)
+			}
+
+			var decoded Signature
+			if err := json.Unmarshal([]byte(tt.want), &decoded); err != nil {
+				t.Fatalf("Unmarshal failed: %v", err)
+			}
+			if decoded.SignatureType != tt.signature.SignatureType {
+				t.Errorf("decoded SignatureType = %q, want %q", decoded.SignatureType, tt.signature.SignatureType)
+			}
+		})
+	}
+}
+
+func TestTransactionRequest_SignatureTypeVersions(t *testing.T) {
+	for _, sigType := range []SignatureType{"", SignatureTypeEthSign, SignatureTypeEIP712, SignatureTypeContract} {
+		request := &TransactionRequest{
+			Type:          string(SAFE),
+			From:          "0x01",
+			To:            json.RawMessage(`"0x02"`),
+			Data:          json.RawMessage(`"0x"`),
+			Signature:     "0xaa",
+			SignatureType: sigType,
+		}
+
+		v2, err := request.ToV2()
+		if err != nil {
+			t.Fatalf("ToV2 failed: %v", err)
+		}
+		if v2.Signature.Type != sigType {
+			t.Errorf("v2 signature type = %q, want %q", v2.Signature.Type, sigType)
+		}
+		v1, err := v2.ToV1()
+		if err != nil {
+			t.Fatalf("ToV1 failed: %v", err)
+		}
+		if v1.SignatureType != sigType {
+			t.Errorf("round-tripped signature type = %q, want %q", v1.SignatureType, sigType)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"github.com/davidt58/go-builder-relayer-client/errors"
+)
+
+// stateRank orders the states along the NEW → EXECUTED → MINED → CONFIRMED
+// progression; FAILED, INVALID, CANCELLED and REPLACED rank as terminal
+var stateRank = map[RelayerTransactionState]int{
+	STATE_NEW:       0,
+	STATE_EXECUTED:  1,
+	STATE_MINED:     2,
+	STATE_CONFIRMED: 3,
+	STATE_FAILED:    3,
+	STATE_INVALID:   3,
+	STATE_CANCELLED: 3,
+	STATE_REPLACED:  3,
+}
+
+// ValidTransition reports whether a transaction may move from one state to
+// another. States only move forward along NEW → EXECUTED → MINED → CONFIRMED
+// (intermediate states may be skipped between observations), any non-terminal
+// state may move to FAILED or INVALID, only NEW may move to CANCELLED or
+// REPLACED, and staying in the same state is allowed.
+func ValidTransition(from, to RelayerTransactionState) bool {
+	fromRank, fromKnown := stateRank[from]
+	toRank, toKnown := stateRank[to]
+	if !fromKnown || !toKnown {
+		return false
+	}
+
+	switch {
+	case from == to:
+		return true
+	case from.IsTerminal():
+		return false
+	case to == STATE_FAILED || to == STATE_INVALID:
+		return true
+	case to.IsCancelled():
+		return from == STATE_NEW
+	default:
+		return toRank > fromRank
+	}
+}
+
+// IsRegression reports whether to is an earlier state than from, e.g. a stale
+// read from a lagging replica reporting EXECUTED after MINED was observed
+func IsRegression(from, to RelayerTransactionState) bool {
+	fromRank, fromKnown := stateRank[from]
+	toRank, toKnown := stateRank[to]
+	return fromKnown && toKnown && toRank < fromRank
+}
+
+// UpdateState moves the transaction to a new state, returning a
+// TransitionError (and leaving the state unchanged) if the move is not allowed
+func (t *RelayerTransaction) UpdateState(to RelayerTransactionState) error {
+	if !ValidTransition(t.State, to) {
+		return errors.ErrInvalidTransition(t.TransactionID, string(t.State), string(to))
+	}
+	t.State = to
+	return nil
+}
@@ -0,0 +1,76 @@
+package models
+
+import (
+	stderrors "errors"
+	"testing"
+
+	"github.com/davidt58/go-builder-relayer-client/errors"
+)
+
+func TestValidTransition(t *testing.T) {
+	const (
+		N = STATE_NEW
+		E = STATE_EXECUTED
+		M = STATE_MINED
+		C = STATE_CONFIRMED
+		F = STATE_FAILED
+		I = STATE_INVALID
+		X = STATE_CANCELLED
+		R = STATE_REPLACED
+	)
+
+	tests := []struct {
+		from, to   RelayerTransactionState
+		valid      bool
+		regression bool
+	}{
+		{N, N, true, false}, {N, E, true, false}, {N, M, true, false}, {N, C, true, false}, {N, F, true, false}, {N, I, true, false},
+		{E, N, false, true}, {E, E, true, false}, {E, M, true, false}, {E, C, true, false}, {E, F, true, false}, {E, I, true, false},
+		{M, N, false, true}, {M, E, false, true}, {M, M, true, false}, {M, C, true, false}, {M, F, true, false}, {M, I, true, false},
+		{C, N, false, true}, {C, E, false, true}, {C, M, false, true}, {C, C, true, false}, {C, F, false, false}, {C, I, false, false},
+		{F, N, false, true}, {F, E, false, true}, {F, M, false, true}, {F, C, false, false}, {F, F, true, false}, {F, I, false, false},
+		{I, N, false, true}, {I, E, false, true}, {I, M, false, true}, {I, C, false, false}, {I, F, false, false}, {I, I, true, false},
+		{N, X, true, false}, {N, R, true, false},
+		{E, X, false, false}, {E, R, false, false},
+		{M, X, false, false}, {M, R, false, false},
+		{C, X, false, false}, {C, R, false, false},
+		{F, X, false, false}, {I, R, false, false},
+		{X, N, false, true}, {X, M, false, true}, {X, C, false, false}, {X, X, true, false}, {X, R, false, false},
+		{R, N, false, true}, {R, E, false, true}, {R, F, false, false}, {R, X, false, false}, {R, R, true, false},
+		{N, "STATE_UNKNOWN", false, false},
+		{"STATE_UNKNOWN", N, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+"->"+string(tt.to), func(t *testing.T) {
+			if got := ValidTransition(tt.from, tt.to); got != tt.valid {
+				t.Errorf("ValidTransition() = %v, want %v", got, tt.valid)
+			}
+			if got := IsRegression(tt.from, tt.to); got != tt.regression {
+				t.Errorf("IsRegression() = %v, want %v", got, tt.regression)
+			}
+		})
+	}
+}
+
+func TestRelayerTransaction_UpdateState(t *testing.T) {
+	txn := &RelayerTransaction{TransactionID: "tx-1", State: STATE_NEW}
+
+	for _, state := range []RelayerTransactionState{STATE_EXECUTED, STATE_MINED, STATE_CONFIRMED} {
+		if err := txn.UpdateState(state); err != nil {
+			t.Fatalf("UpdateState(%s) failed: %v", state, err)
+		}
+	}
+
+	err := txn.UpdateState(STATE_NEW)
+	var transitionErr *errors.TransitionError
+	if !stderrors.As(err, &transitionErr) {
+		t.Fatalf("error = %v, want TransitionError", err)
+	}
+	if transitionErr.From != string(STATE_CONFIRMED) || transitionErr.To != string(STATE_NEW) {
+		t.Errorf("TransitionError = %+v, want CONFIRMED -> NEW", transitionErr)
+	}
+	if txn.State != STATE_CONFIRMED {
+		t.Errorf("State = %s, want it unchanged after a rejected transition", txn.State)
+	}
+}
@@ -1,6 +1,18 @@
 package models
 
-import "encoding/json"
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/davidt58/go-builder-relayer-client/errors"
+)
 
 // OperationType represents the type of operation for a Safe transaction
 type OperationType int
@@ -70,6 +82,11 @@ const (
 	STATE_FAILED RelayerTransactionState = "STATE_FAILED"
 	// STATE_INVALID indicates the transaction is invalid
 	STATE_INVALID RelayerTransactionState = "STATE_INVALID"
+	// STATE_CANCELLED indicates the transaction was cancelled before execution
+	STATE_CANCELLED RelayerTransactionState = "STATE_CANCELLED"
+	// STATE_REPLACED indicates the transaction was superseded by a replacement
+	// with the same Safe nonce
+	STATE_REPLACED RelayerTransactionState = "STATE_REPLACED"
 )
 
 // String returns the string representation of RelayerTransactionState
@@ -77,10 +94,15 @@ func (s RelayerTransactionState) String() string {
 	return string(s)
 }
 
+// IsCancelled returns true if the state is STATE_CANCELLED or STATE_REPLACED
+func (s RelayerTransactionState) IsCancelled() bool {
+	return s == STATE_CANCELLED || s == STATE_REPLACED
+}
+
 // IsTerminal returns true if the state is a terminal state
 func (s RelayerTransactionState) IsTerminal() bool {
 	switch s {
-	case STATE_CONFIRMED, STATE_FAILED, STATE_INVALID:
+	case STATE_CONFIRMED, STATE_FAILED, STATE_INVALID, STATE_CANCELLED, STATE_REPLACED:
 		return true
 	default:
 		return false
@@ -97,10 +119,21 @@ type SafeTransaction struct {
 	Data string `json:"data"`
 	// Operation is the type of operation (Call or DelegateCall)
 	Operation OperationType `json:"operation"`
-	// GasLimit is the gas limit for this transaction
+	// GasLimit is the safeTxGas for this transaction (decimal or 0x hex).
+	// Safe guarantees the call at least this much gas and, with a non-zero
+	// value, a failed call no longer reverts the whole execution. It is only
+	// honoured for a single transaction; batches routed through MultiSend
+	// execute as one call and reject a non-empty GasLimit.
 	GasLimit string `json:"gasLimit,omitempty"`
+	// ValueInt is Value as an integer. When set the builder uses it instead
+	// of parsing Value, which must then be empty or the same amount. It is
+	// not serialized; SetValueBig keeps Value in sync for the wire.
+	ValueInt *big.Int `json:"-"`
 }
 
+// maxUint256 is the largest value a uint256 field can hold
+var maxUint256 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+
 // NewSafeTransaction creates a new SafeTransaction with default values
 func NewSafeTransaction(to, value, data string) *SafeTransaction {
 	return &SafeTransaction{
@@ -111,6 +144,108 @@ func NewSafeTransaction(to, value, data string) *SafeTransaction {
 	}
 }
 
+// NewSafeTransactionBig creates a Call transaction from typed values; a nil
+// value means 0
+func NewSafeTransactionBig(to common.Address, value *big.Int, data []byte) *SafeTransaction {
+	txn := &SafeTransaction{
+		To:        to.Hex(),
+		Data:      hexutil.Encode(data),
+		Operation: Call,
+	}
+	if value == nil {
+		value = new(big.Int)
+	}
+	txn.SetValueBig(value)
+	return txn
+}
+
+// SetValueBig sets ValueInt to a copy of value and Value to its decimal form
+func (t *SafeTransaction) SetValueBig(value *big.Int) {
+	t.ValueInt = new(big.Int).Set(value)
+	t.Value = value.String()
+}
+
+// ValueBig returns the value in wei: ValueInt when set, otherwise Value
+// parsed as decimal or 0x hex, with empty meaning 0. It is an error for the
+// value to be outside uint256 or for Value and ValueInt to disagree.
+func (t *SafeTransaction) ValueBig() (*big.Int, error) {
+	var parsed *big.Int
+	if t.Value != "" {
+		parsed = new(big.Int)
+		var ok bool
+		if strings.HasPrefix(t.Value, "0x") || strings.HasPrefix(t.Value, "0X") {
+			_, ok = parsed.SetString(t.Value[2:], 16)
+		} else {
+			_, ok = parsed.SetString(t.Value, 10)
+		}
+		if !ok || parsed.Sign() < 0 || parsed.Cmp(maxUint256) > 0 {
+			return nil, errors.NewRelayerClientError(fmt.Sprintf("invalid value: %s", t.Value), nil)
+		}
+	}
+
+	if t.ValueInt == nil {
+		if parsed == nil {
+			return new(big.Int), nil
+		}
+		return parsed, nil
+	}
+	if t.ValueInt.Sign() < 0 || t.ValueInt.Cmp(maxUint256) > 0 {
+		return nil, errors.NewRelayerClientError(fmt.Sprintf("invalid value: %s", t.ValueInt), nil)
+	}
+	if parsed != nil && parsed.Cmp(t.ValueInt) != 0 {
+		return nil, errors.ErrTypedValueMismatch("value", t.Value, t.ValueInt.String())
+	}
+	return new(big.Int).Set(t.ValueInt), nil
+}
+
+// Validate checks that the transaction has a valid destination address,
+// a non-negative decimal value, hex-encoded data and a known operation
+func (t *SafeTransaction) Validate() error {
+	if t.To == "" {
+		return errors.ErrMissingRequiredField("to")
+	}
+	if !common.IsHexAddress(t.To) {
+		return errors.ErrInvalidAddress(t.To)
+	}
+
+	if t.Value != "" {
+		value, ok := new(big.Int).SetString(t.Value, 10)
+		if !ok || value.Sign() < 0 {
+			return errors.NewRelayerClientError(fmt.Sprintf("invalid value: %s", t.Value), nil)
+		}
+	}
+	if _, err := t.ValueBig(); err != nil {
+		return err
+	}
+
+	if _, err := NormalizeHexData(t.Data); err != nil {
+		return err
+	}
+
+	if t.Operation != Call && t.Operation != DelegateCall {
+		return errors.NewRelayerClientError(fmt.Sprintf("invalid operation: %d", t.Operation), nil)
+	}
+
+	return nil
+}
+
+// NormalizeHexData returns data as 0x-prefixed lowercase hex, the form
+// requests carry and signatures cover. Empty data becomes "0x"; a 0X prefix,
+// no prefix and uppercase digits are accepted.
+func NormalizeHexData(data string) (string, error) {
+	digits := data
+	if len(digits) >= 2 && digits[0] == '0' && (digits[1] == 'x' || digits[1] == 'X') {
+		digits = digits[2:]
+	}
+	if len(digits)%2 != 0 {
+		return "", errors.NewRelayerClientError("invalid transaction data", hexutil.ErrOddLength)
+	}
+	if _, err := hex.DecodeString(digits); err != nil {
+		return "", errors.NewRelayerClientError("invalid transaction data", err)
+	}
+	return "0x" + strings.ToLower(digits), nil
+}
+
 // SafeTransactionArgs represents arguments for building a Safe transaction request
 type SafeTransactionArgs struct {
 	// SafeAddress is the address of the Safe wallet
@@ -119,8 +254,102 @@ type SafeTransactionArgs struct {
 	Transactions []SafeTransaction
 	// Nonce is the Safe transaction nonce
 	Nonce string
-	// Metadata is optional metadata for the transaction
-	Metadata string
+	// NonceInt is Nonce as an integer. When set the builder uses it instead
+	// of parsing Nonce, which must then be empty or the same nonce.
+	NonceInt *big.Int
+	// Metadata is optional metadata for the transaction. nil omits the field
+	// from the request; a pointer to "" sends it empty (see MetadataOf)
+	Metadata *string
+	// AllowSelfExec allows transactions that call the Safe's own execution
+	// or setup functions (see CheckSelfCalls), for deliberately nested
+	// executions
+	AllowSelfExec bool
+}
+
+// SetNonceBig sets NonceInt to a copy of nonce and Nonce to its decimal form
+func (a *SafeTransactionArgs) SetNonceBig(nonce *big.Int) {
+	a.NonceInt = new(big.Int).Set(nonce)
+	a.Nonce = nonce.String()
+}
+
+// NonceBig returns the Safe nonce: NonceInt when set, otherwise Nonce parsed
+// with its base prefix (decimal without one), with empty meaning 0. It is an
+// error for the nonce to be negative or for Nonce and NonceInt to disagree.
+func (a *SafeTransactionArgs) NonceBig() (*big.Int, error) {
+	var parsed *big.Int
+	if a.Nonce != "" {
+		var ok bool
+		parsed, ok = new(big.Int).SetString(a.Nonce, 0)
+		if !ok || parsed.Sign() < 0 {
+			return nil, errors.NewRelayerClientError(fmt.Sprintf("invalid nonce: %s", a.Nonce), nil)
+		}
+	}
+
+	if a.NonceInt == nil {
+		if parsed == nil {
+			return new(big.Int), nil
+		}
+		return parsed, nil
+	}
+	if a.NonceInt.Sign() < 0 {
+		return nil, errors.NewRelayerClientError(fmt.Sprintf("invalid nonce: %s", a.NonceInt), nil)
+	}
+	if parsed != nil && parsed.Cmp(a.NonceInt) != 0 {
+		return nil, errors.ErrTypedValueMismatch("nonce", a.Nonce, a.NonceInt.String())
+	}
+	return new(big.Int).Set(a.NonceInt), nil
+}
+
+// selfExecSelectors are the Safe functions a transaction may not call on its
+// own Safe: a nested execution with stale parameters reverts the whole
+// execution on-chain, and setup always reverts on a deployed Safe.
+// Administration such as addOwnerWithThreshold is not listed.
+var selfExecSelectors = map[string]string{
+	"0x6a761202": "execTransaction",
+	"0x468721a7": "execTransactionFromModule",
+	"0x5229073f": "execTransactionFromModuleReturnData",
+	"0xb63e800d": "setup",
+}
+
+// CheckSelfCalls rejects the first transaction that calls execTransaction,
+// a module execution or setup on the Safe at SafeAddress itself, naming its
+// index and selector, unless AllowSelfExec is set
+func (a *SafeTransactionArgs) CheckSelfCalls() error {
+	if a.AllowSelfExec || !common.IsHexAddress(a.SafeAddress) {
+		return nil
+	}
+	safeAddress := common.HexToAddress(a.SafeAddress)
+
+	for i, txn := range a.Transactions {
+		if !common.IsHexAddress(txn.To) || common.HexToAddress(txn.To) != safeAddress {
+			continue
+		}
+		data, err := NormalizeHexData(txn.Data)
+		if err != nil || len(data) < 10 {
+			continue
+		}
+		if method, ok := selfExecSelectors[data[:10]]; ok {
+			return errors.ErrSelfExecNotAllowed(i, data[:10], method)
+		}
+	}
+	return nil
+}
+
+// MetadataOf converts a metadata string to an args Metadata value the way
+// the string-based APIs (Execute, EnqueueExecute, ...) do: an empty string
+// means no metadata and omits the field. Use ExplicitMetadata to send an
+// empty string.
+func MetadataOf(metadata string) *string {
+	if metadata == "" {
+		return nil
+	}
+	return &metadata
+}
+
+// ExplicitMetadata returns an args Metadata value that is always sent, even
+// when metadata is empty
+func ExplicitMetadata(metadata string) *string {
+	return &metadata
 }
 
 // SafeCreateTransactionArgs represents arguments for building a Safe creation request
@@ -131,8 +360,13 @@ type SafeCreateTransactionArgs struct {
 	SafeAddress string
 	// Nonce is the nonce for the creation transaction
 	Nonce string
-	// Metadata is optional metadata for the transaction
-	Metadata string
+	// Metadata is optional metadata for the transaction. nil omits the field
+	// from the request; a pointer to "" sends it empty (see MetadataOf)
+	Metadata *string
+	// SkipSafeAddressCheck disables the check that SafeAddress is the Safe
+	// derived from SignerAddress, for flows that create Safes at other
+	// addresses (e.g. a custom salt nonce)
+	SkipSafeAddressCheck bool
 }
 
 // RelayerTransaction represents a transaction in the relayer system
@@ -155,8 +389,42 @@ type RelayerTransaction struct {
 	CreatedAt string `json:"createdAt"`
 	// UpdatedAt is the timestamp when the transaction was last updated
 	UpdatedAt string `json:"updatedAt"`
+	// StateTimestamps are when the transaction entered each state, for
+	// relayers that report them
+	StateTimestamps map[RelayerTransactionState]string `json:"stateTimestamps,omitempty"`
 	// Metadata is optional metadata attached to the transaction
 	Metadata *string `json:"metadata,omitempty"`
+	// Nonce is the Safe nonce the transaction was submitted with
+	Nonce *string `json:"nonce,omitempty"`
+	// ReplacedBy is the ID of the replacement transaction (STATE_REPLACED only)
+	ReplacedBy *string `json:"replacedBy,omitempty"`
+	// To, Value, Data and Operation echo the submitted request, in the same
+	// shapes as TransactionRequest's: a single value, or parallel arrays for
+	// a batch. They are empty for relayers that do not echo the request; use
+	// RequestTransactions to read them. For a batch signed through MultiSend,
+	// To is the MultiSend contract.
+	To        json.RawMessage `json:"to,omitempty"`
+	Value     json.RawMessage `json:"value,omitempty"`
+	Data      json.RawMessage `json:"data,omitempty"`
+	Operation json.RawMessage `json:"operation,omitempty"`
+	// Signature and SignatureParams echo the submitted request's signature
+	Signature       *string          `json:"signature,omitempty"`
+	SignatureParams *SignatureParams `json:"signatureParams,omitempty"`
+}
+
+// HasRequestEcho reports whether the relayer echoed the submitted request's calls
+func (t *RelayerTransaction) HasRequestEcho() bool {
+	return len(t.To) > 0 && string(t.To) != "null"
+}
+
+// RequestTransactions returns the calls of the echoed request, one per to,
+// with the operation from the echoed operation or signatureParams as for
+// TransactionRequest.ToV2. It returns nil when the request was not echoed.
+func (t *RelayerTransaction) RequestTransactions() ([]RequestTransaction, error) {
+	if !t.HasRequestEcho() {
+		return nil, nil
+	}
+	return requestTransactions(t.To, t.Value, t.Data, t.Operation, t.SignatureParams)
 }
 
 // IsMined returns true if the transaction has been mined
@@ -169,11 +437,54 @@ func (t *RelayerTransaction) IsConfirmed() bool {
 	return t.State == STATE_CONFIRMED
 }
 
-// IsFailed returns true if the transaction has failed
+// IsFailed returns true if the transaction has failed. Cancelled and
+// replaced transactions are not failures; see IsCancelled.
 func (t *RelayerTransaction) IsFailed() bool {
 	return t.State == STATE_FAILED || t.State == STATE_INVALID
 }
 
+// IsCancelled returns true if the transaction was cancelled or replaced
+func (t *RelayerTransaction) IsCancelled() bool {
+	return t.State.IsCancelled()
+}
+
+// WaitStatus describes how far a transaction had progressed when a Wait call returned
+type WaitStatus string
+
+const (
+	// WaitStatusPending means no hash is known yet
+	WaitStatusPending WaitStatus = "PENDING"
+	// WaitStatusHashKnown means the hash is known but the relayer has not reported it mined
+	WaitStatusHashKnown WaitStatus = "HASH_KNOWN"
+	// WaitStatusMined means the transaction is mined but not yet confirmed
+	WaitStatusMined WaitStatus = "MINED"
+	// WaitStatusConfirmed means the transaction is confirmed
+	WaitStatusConfirmed WaitStatus = "CONFIRMED"
+	// WaitStatusFailed means the transaction failed or is invalid
+	WaitStatusFailed WaitStatus = "FAILED"
+	// WaitStatusCancelled means the transaction was cancelled or replaced
+	WaitStatusCancelled WaitStatus = "CANCELLED"
+)
+
+// WaitStatus classifies the transaction's progress, e.g. to tell a
+// transaction returned by WaitUntilMined that is mined but unconfirmed
+// (WaitStatusMined) from a confirmed one (WaitStatusConfirmed)
+func (t *RelayerTransaction) WaitStatus() WaitStatus {
+	switch {
+	case t.IsFailed():
+		return WaitStatusFailed
+	case t.IsCancelled():
+		return WaitStatusCancelled
+	case t.State == STATE_CONFIRMED:
+		return WaitStatusConfirmed
+	case t.State == STATE_MINED:
+		return WaitStatusMined
+	case t.IsMined():
+		return WaitStatusHashKnown
+	}
+	return WaitStatusPending
+}
+
 // SignerType represents the type of signer
 type SignerType string
 
@@ -189,6 +500,43 @@ func (s SignerType) String() string {
 	return string(s)
 }
 
+// signerTypes holds the signer types accepted by the client. EOA and SAFE are
+// always present; partner integrations can add more via RegisterSignerType.
+var (
+	signerTypesMu sync.RWMutex
+	signerTypes   = map[SignerType]bool{
+		EOA:         true,
+		SAFE_SIGNER: true,
+	}
+)
+
+// RegisterSignerType adds a signer type (e.g. "PROXY") to the set of values
+// accepted by the client. Types that have not been registered are rejected.
+func RegisterSignerType(signerType string) SignerType {
+	st := SignerType(signerType)
+	signerTypesMu.Lock()
+	defer signerTypesMu.Unlock()
+	signerTypes[st] = true
+	return st
+}
+
+// IsValid returns true if the signer type is built in or has been registered
+func (s SignerType) IsValid() bool {
+	signerTypesMu.RLock()
+	defer signerTypesMu.RUnlock()
+	return signerTypes[s]
+}
+
+// ParseSignerType converts a raw string into a SignerType, returning an error
+// if the value is neither built in nor registered
+func ParseSignerType(signerType string) (SignerType, error) {
+	st := SignerType(signerType)
+	if !st.IsValid() {
+		return "", errors.NewRelayerClientError(fmt.Sprintf("unknown signer type: %q (register it with RegisterSignerType)", signerType), nil)
+	}
+	return st, nil
+}
+
 // NonceResponse represents the response from get-nonce endpoint
 type NonceResponse struct {
 	// Nonce is the current nonce value as a string
@@ -2,7 +2,12 @@ package models
 
 import (
 	"encoding/json"
+	"math/big"
+	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
 )
 
 func TestOperationType_String(t *testing.T) {
@@ -119,6 +124,110 @@ func TestNewSafeTransaction(t *testing.T) {
 	}
 }
 
+func TestNewSafeTransactionBig(t *testing.T) {
+	to := common.HexToAddress("0x1234567890123456789012345678901234567890")
+	tx := NewSafeTransactionBig(to, big.NewInt(1000), []byte{0xab, 0xcd})
+
+	if tx.To != to.Hex() || tx.Value != "1000" || tx.Data != "0xabcd" || tx.Operation != Call {
+		t.Errorf("NewSafeTransactionBig = %+v", tx)
+	}
+
+	// The wire form carries only the string fields
+	encoded, err := json.Marshal(tx)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if strings.Contains(string(encoded), "ValueInt") || !strings.Contains(string(encoded), `"value":"1000"`) {
+		t.Errorf("JSON = %s, want the string value only", encoded)
+	}
+
+	if empty := NewSafeTransactionBig(to, nil, nil); empty.Value != "0" || empty.Data != "0x" {
+		t.Errorf("nil value and data = %+v, want 0 and 0x", empty)
+	}
+}
+
+func TestSafeTransaction_ValueBig(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     string
+		valueInt  *big.Int
+		want      string
+		shouldErr bool
+	}{
+		{"empty", "", nil, "0", false},
+		{"decimal string", "1000", nil, "1000", false},
+		{"hex string", "0x3e8", nil, "1000", false},
+		{"typed only", "", big.NewInt(1000), "1000", false},
+		{"consistent", "0x3e8", big.NewInt(1000), "1000", false},
+		{"inconsistent", "999", big.NewInt(1000), "", true},
+		{"negative typed", "", big.NewInt(-1), "", true},
+		{"invalid string", "ten", nil, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := SafeTransaction{Value: tt.value, ValueInt: tt.valueInt}
+			got, err := tx.ValueBig()
+			if tt.shouldErr {
+				if err == nil {
+					t.Errorf("Expected error but got %s", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("ValueBig() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	// The result is a copy
+	tx := NewSafeTransactionBig(common.Address{}, big.NewInt(5), nil)
+	value, _ := tx.ValueBig()
+	value.SetInt64(6)
+	if tx.ValueInt.Int64() != 5 {
+		t.Errorf("ValueBig exposed ValueInt: %s", tx.ValueInt)
+	}
+}
+
+func TestSafeTransactionArgs_NonceBig(t *testing.T) {
+	tests := []struct {
+		name      string
+		nonce     string
+		nonceInt  *big.Int
+		want      string
+		shouldErr bool
+	}{
+		{"empty", "", nil, "0", false},
+		{"decimal string", "7", nil, "7", false},
+		{"typed only", "", big.NewInt(7), "7", false},
+		{"consistent", "7", big.NewInt(7), "7", false},
+		{"inconsistent", "8", big.NewInt(7), "", true},
+		{"invalid string", "seven", nil, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := SafeTransactionArgs{Nonce: tt.nonce, NonceInt: tt.nonceInt}
+			got, err := args.NonceBig()
+			if tt.shouldErr {
+				if err == nil {
+					t.Errorf("Expected error but got %s", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("NonceBig() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestRelayerTransaction_IsMined(t *testing.T) {
 	hash := "0xabc123"
 	tests := []struct {
@@ -146,3 +255,103 @@ func TestRelayerTransaction_IsMined(t *testing.T) {
 		})
 	}
 }
+
+func TestSignerType_Registration(t *testing.T) {
+	if !EOA.IsValid() || !SAFE_SIGNER.IsValid() {
+		t.Error("built-in signer types should be valid")
+	}
+
+	if _, err := ParseSignerType("FORWARDER"); err == nil {
+		t.Error("expected error for unregistered signer type")
+	}
+
+	forwarder := RegisterSignerType("FORWARDER")
+	if forwarder != SignerType("FORWARDER") {
+		t.Errorf("RegisterSignerType() = %s, want FORWARDER", forwarder)
+	}
+
+	parsed, err := ParseSignerType("FORWARDER")
+	if err != nil {
+		t.Fatalf("ParseSignerType failed after registration: %v", err)
+	}
+	if parsed != forwarder {
+		t.Errorf("ParseSignerType() = %s, want %s", parsed, forwarder)
+	}
+}
+
+func TestNormalizeHexData(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		want      string
+		shouldErr bool
+	}{
+		{name: "empty", data: "", want: "0x"},
+		{name: "prefix only", data: "0x", want: "0x"},
+		{name: "uppercase prefix only", data: "0X", want: "0x"},
+		{name: "lowercase", data: "0xa9059cbb", want: "0xa9059cbb"},
+		{name: "unprefixed", data: "a9059cbb", want: "0xa9059cbb"},
+		{name: "uppercase", data: "0XA9059CBB", want: "0xa9059cbb"},
+		{name: "mixed case", data: "0xA9059cBb", want: "0xa9059cbb"},
+		{name: "odd length", data: "0xa9059cb", shouldErr: true},
+		{name: "not hex", data: "0xzz", shouldErr: true},
+		{name: "unprefixed not hex", data: "zz", shouldErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeHexData(tt.data)
+			if (err != nil) != tt.shouldErr {
+				t.Fatalf("NormalizeHexData() error = %v, shouldErr %v", err, tt.shouldErr)
+			}
+			if got != tt.want {
+				t.Errorf("NormalizeHexData() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRelayerTransaction_RequestTransactions(t *testing.T) {
+	delegate := "1"
+	tests := []struct {
+		name      string
+		txn       RelayerTransaction
+		want      []RequestTransaction
+		shouldErr bool
+	}{
+		{name: "no echo"},
+		{name: "null echo", txn: RelayerTransaction{To: json.RawMessage(`null`)}},
+		{
+			name: "single call with signatureParams operation",
+			txn: RelayerTransaction{
+				To:              json.RawMessage(`"0xA238CBeb142c10Ef7Ad8442C6D1f9E89e07e7761"`),
+				Data:            json.RawMessage(`"0x8d80ff0a"`),
+				SignatureParams: &SignatureParams{Operation: &delegate},
+			},
+			want: []RequestTransaction{{To: "0xA238CBeb142c10Ef7Ad8442C6D1f9E89e07e7761", Data: "0x8d80ff0a", Operation: DelegateCall}},
+		},
+		{
+			name: "arrays",
+			txn: RelayerTransaction{
+				To:        json.RawMessage(`["0x1","0x2"]`),
+				Value:     json.RawMessage(`["0","5"]`),
+				Data:      json.RawMessage(`["0x","0xab"]`),
+				Operation: json.RawMessage(`[0,1]`),
+			},
+			want: []RequestTransaction{{To: "0x1", Value: "0", Data: "0x"}, {To: "0x2", Value: "5", Data: "0xab", Operation: DelegateCall}},
+		},
+		{name: "malformed", txn: RelayerTransaction{To: json.RawMessage(`42`)}, shouldErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.txn.RequestTransactions()
+			if (err != nil) != tt.shouldErr {
+				t.Fatalf("RequestTransactions() error = %v, shouldErr %v", err, tt.shouldErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("RequestTransactions() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package models
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/davidt58/go-builder-relayer-client/errors"
+)
+
+// Decimal places of the native token units
+const (
+	EtherDecimals = 18
+	GweiDecimals  = 9
+)
+
+// ParseEther converts a decimal ether amount (e.g. "1.5") to wei
+func ParseEther(amount string) (*big.Int, error) {
+	return ParseUnits(amount, EtherDecimals)
+}
+
+// ParseGwei converts a decimal gwei amount (e.g. "30.5") to wei
+func ParseGwei(amount string) (*big.Int, error) {
+	return ParseUnits(amount, GweiDecimals)
+}
+
+// ParseUnits converts a non-negative decimal string with up to `decimals`
+// fractional digits into an integer scaled by 10^decimals. Amounts with more
+// fractional digits than the unit supports are rejected rather than rounded.
+func ParseUnits(amount string, decimals int) (*big.Int, error) {
+	amount = strings.TrimSpace(amount)
+	if amount == "" {
+		return nil, errors.NewRelayerClientError("amount cannot be empty", nil)
+	}
+
+	whole, frac, _ := strings.Cut(amount, ".")
+	if whole == "" && frac == "" {
+		return nil, errors.NewRelayerClientError(fmt.Sprintf("invalid amount: %s", amount), nil)
+	}
+	if len(frac) > decimals {
+		return nil, errors.NewRelayerClientError(fmt.Sprintf("amount %s has more than %d decimal places", amount, decimals), nil)
+	}
+	if !isDigits(whole) || !isDigits(frac) {
+		return nil, errors.NewRelayerClientError(fmt.Sprintf("invalid amount: %s", amount), nil)
+	}
+
+	digits := whole + frac + strings.Repeat("0", decimals-len(frac))
+	value, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return nil, errors.NewRelayerClientError(fmt.Sprintf("invalid amount: %s", amount), nil)
+	}
+	return value, nil
+}
+
+// isDigits reports whether s consists only of ASCII digits (empty is allowed)
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// TxBuilder builds a SafeTransaction step by step. Errors from individual
+// steps are deferred and reported by Build.
+type TxBuilder struct {
+	txn SafeTransaction
+	err error
+}
+
+// NewTxBuilder creates a builder for a Call with zero value and empty data
+func NewTxBuilder() *TxBuilder {
+	return &TxBuilder{
+		txn: SafeTransaction{
+			Value:     "0",
+			Data:      "0x",
+			Operation: Call,
+		},
+	}
+}
+
+// To sets the destination address
+func (b *TxBuilder) To(address string) *TxBuilder {
+	b.txn.To = address
+	return b
+}
+
+// ToAddress sets the destination address from a common.Address
+func (b *TxBuilder) ToAddress(address common.Address) *TxBuilder {
+	b.txn.To = address.Hex()
+	return b
+}
+
+// ValueWei sets the value in wei
+func (b *TxBuilder) ValueWei(value *big.Int) *TxBuilder {
+	if value == nil {
+		b.setErr(errors.ErrMissingRequiredField("value"))
+		return b
+	}
+	b.txn.SetValueBig(value)
+	return b
+}
+
+// ValueEther sets the value from a decimal ether amount such as "1.5"
+func (b *TxBuilder) ValueEther(amount string) *TxBuilder {
+	value, err := ParseEther(amount)
+	if err != nil {
+		b.setErr(err)
+		return b
+	}
+	b.txn.SetValueBig(value)
+	return b
+}
+
+// ValueGwei sets the value from a decimal gwei amount
+func (b *TxBuilder) ValueGwei(amount string) *TxBuilder {
+	value, err := ParseGwei(amount)
+	if err != nil {
+		b.setErr(err)
+		return b
+	}
+	b.txn.SetValueBig(value)
+	return b
+}
+
+// Data sets the calldata from raw bytes or a hex string
+func (b *TxBuilder) Data(data interface{}) *TxBuilder {
+	switch v := data.(type) {
+	case []byte:
+		b.txn.Data = hexutil.Encode(v)
+	case string:
+		if v == "" {
+			v = "0x"
+		}
+		if !strings.HasPrefix(v, "0x") {
+			v = "0x" + v
+		}
+		b.txn.Data = v
+	default:
+		b.setErr(errors.NewRelayerClientError(fmt.Sprintf("unsupported data type %T", data), nil))
+	}
+	return b
+}
+
+// FromABICall packs calldata for method using the given contract ABI JSON
+func (b *TxBuilder) FromABICall(abiJSON, method string, args ...interface{}) *TxBuilder {
+	parsed, err := abi.JSON(strings.NewReader(abiJSON))
+	if err != nil {
+		b.setErr(errors.NewRelayerClientError("failed to parse ABI", err))
+		return b
+	}
+
+	data, err := parsed.Pack(method, args...)
+	if err != nil {
+		b.setErr(errors.NewRelayerClientError(fmt.Sprintf("failed to pack %s call", method), err))
+		return b
+	}
+
+	b.txn.Data = hexutil.Encode(data)
+	return b
+}
+
+// Call sets the operation to Call
+func (b *TxBuilder) Call() *TxBuilder {
+	b.txn.Operation = Call
+	return b
+}
+
+// DelegateCall sets the operation to DelegateCall
+func (b *TxBuilder) DelegateCall() *TxBuilder {
+	b.txn.Operation = DelegateCall
+	return b
+}
+
+// Build validates and returns the transaction
+func (b *TxBuilder) Build() (SafeTransaction, error) {
+	if b.err != nil {
+		return SafeTransaction{}, b.err
+	}
+	if err := b.txn.Validate(); err != nil {
+		return SafeTransaction{}, err
+	}
+	return b.txn, nil
+}
+
+// setErr records the first error encountered while building
+func (b *TxBuilder) setErr(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
@@ -0,0 +1,126 @@
+package models
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestParseUnits(t *testing.T) {
+	tests := []struct {
+		name      string
+		amount    string
+		decimals  int
+		expected  string
+		shouldErr bool
+	}{
+		{"whole ether", "1", EtherDecimals, "1000000000000000000", false},
+		{"fractional ether", "1.5", EtherDecimals, "1500000000000000000", false},
+		{"one wei in ether", "0.000000000000000001", EtherDecimals, "1", false},
+		{"leading dot", ".25", EtherDecimals, "250000000000000000", false},
+		{"trailing dot", "2.", EtherDecimals, "2000000000000000000", false},
+		{"gwei", "30.5", GweiDecimals, "30500000000", false},
+		{"zero", "0", EtherDecimals, "0", false},
+		{"too many decimals", "0.0000000000000000001", EtherDecimals, "", true},
+		{"too many gwei decimals", "1.0000000001", GweiDecimals, "", true},
+		{"negative", "-1", EtherDecimals, "", true},
+		{"empty", "", EtherDecimals, "", true},
+		{"lone dot", ".", EtherDecimals, "", true},
+		{"not a number", "abc", EtherDecimals, "", true},
+		{"exponent", "1e18", EtherDecimals, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := ParseUnits(tt.amount, tt.decimals)
+			if tt.shouldErr {
+				if err == nil {
+					t.Errorf("Expected error for %q but got %s", tt.amount, value)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if value.String() != tt.expected {
+				t.Errorf("ParseUnits(%q) = %s, want %s", tt.amount, value, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTxBuilder_Build(t *testing.T) {
+	to := "0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174"
+
+	tests := []struct {
+		name      string
+		builder   *TxBuilder
+		shouldErr bool
+	}{
+		{"defaults", NewTxBuilder().To(to), false},
+		{"wei value", NewTxBuilder().To(to).ValueWei(big.NewInt(42)), false},
+		{"ether value", NewTxBuilder().To(to).ValueEther("0.5"), false},
+		{"byte data", NewTxBuilder().To(to).Data([]byte{0xde, 0xad}), false},
+		{"delegate call", NewTxBuilder().To(to).DelegateCall(), false},
+		{"missing to", NewTxBuilder(), true},
+		{"invalid to", NewTxBuilder().To("0x1234"), true},
+		{"invalid ether", NewTxBuilder().To(to).ValueEther("1.2.3"), true},
+		{"nil wei", NewTxBuilder().To(to).ValueWei(nil), true},
+		{"invalid hex data", NewTxBuilder().To(to).Data("0xzz"), true},
+		{"unsupported data type", NewTxBuilder().To(to).Data(42), true},
+		{"unknown ABI method", NewTxBuilder().To(to).FromABICall(erc20ABI, "burn"), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.builder.Build()
+			if tt.shouldErr && err == nil {
+				t.Error("Expected error but got none")
+			}
+			if !tt.shouldErr && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+const erc20ABI = `[{"name":"approve","type":"function","inputs":[{"name":"spender","type":"address"},{"name":"amount","type":"uint256"}],"outputs":[{"name":"","type":"bool"}]}]`
+
+func ExampleTxBuilder() {
+	txn, err := NewTxBuilder().
+		To("0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174").
+		ValueEther("1.5").
+		Call().
+		Build()
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(txn.Value, txn.Data, txn.Operation)
+	// Output: 1500000000000000000 0x Call
+}
+
+func ExampleTxBuilder_FromABICall() {
+	txn, err := NewTxBuilder().
+		To("0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174").
+		FromABICall(erc20ABI, "approve", common.HexToAddress("0x4bFb41d5B3570DeFd03C39a9A4D8dE6Bd8B8982E"), big.NewInt(1000000)).
+		Build()
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(txn.Data[:10])
+	// Output: 0x095ea7b3
+}
+
+func ExampleParseEther() {
+	wei, err := ParseEther("0.000000000000000001")
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(wei)
+	// Output: 1
+}
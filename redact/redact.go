@@ -0,0 +1,210 @@
+// Package redact keeps signatures, secrets and oversized calldata out of
+// log output and error messages
+package redact
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Placeholder replaces secret values
+const Placeholder = "[REDACTED]"
+
+// Defaults of Rules
+const (
+	DefaultSignatureEdgeBytes = 4
+	DefaultMaxCalldataBytes   = 64
+)
+
+// signatureLength is the length of one packed ECDSA signature
+const signatureLength = 65
+
+// minSecretLength is the shortest secret that is matched; shorter values
+// would redact unrelated text
+const minSecretLength = 8
+
+// DefaultSensitiveEnv are the environment variables config.LoadFromEnv reads
+// secrets from
+var DefaultSensitiveEnv = []string{"PK", "BUILDER_API_KEY", "BUILDER_SECRET", "BUILDER_PASS_PHRASE"}
+
+// Rules configures a Redactor. Zero fields take their defaults.
+type Rules struct {
+	// SignatureEdgeBytes is how many bytes are kept at each end of a
+	// signature (default DefaultSignatureEdgeBytes)
+	SignatureEdgeBytes int
+	// MaxCalldataBytes truncates longer hex data (default
+	// DefaultMaxCalldataBytes; negative disables truncation)
+	MaxCalldataBytes int
+	// SensitiveEnv are environment variables whose values are never logged
+	// (default DefaultSensitiveEnv)
+	SensitiveEnv []string
+}
+
+// withDefaults returns the rules with zero fields defaulted
+func (r Rules) withDefaults() Rules {
+	if r.SignatureEdgeBytes <= 0 {
+		r.SignatureEdgeBytes = DefaultSignatureEdgeBytes
+	}
+	if r.MaxCalldataBytes == 0 {
+		r.MaxCalldataBytes = DefaultMaxCalldataBytes
+	}
+	if r.SensitiveEnv == nil {
+		r.SensitiveEnv = DefaultSensitiveEnv
+	}
+	return r
+}
+
+// Redactor applies Rules to values and free text. It is immutable and safe
+// for concurrent use.
+type Redactor struct {
+	rules   Rules
+	secrets []string
+	strict  bool
+}
+
+// hexPattern matches 0x-prefixed hex blobs
+var hexPattern = regexp.MustCompile(`0x[0-9a-fA-F]+`)
+
+var (
+	defaultOnce     sync.Once
+	defaultRedactor *Redactor
+)
+
+// New creates a Redactor for rules
+func New(rules Rules) *Redactor {
+	return &Redactor{rules: rules.withDefaults()}
+}
+
+// Default returns the Redactor with the default Rules
+func Default() *Redactor {
+	defaultOnce.Do(func() { defaultRedactor = New(Rules{}) })
+	return defaultRedactor
+}
+
+// WithSecrets returns a copy of r that also replaces the given values (e.g.
+// a private key) with Placeholder. Values shorter than 8 characters are ignored.
+func (r *Redactor) WithSecrets(secrets ...string) *Redactor {
+	copied := *r
+	copied.secrets = append([]string(nil), r.secrets...)
+	for _, secret := range secrets {
+		if len(secret) >= minSecretLength {
+			copied.secrets = append(copied.secrets, secret)
+		}
+	}
+	return &copied
+}
+
+// Strict returns a copy of r whose Writer panics when it is given text that
+// still contains a value the rules redact, i.e. a log call that skipped
+// redaction. Use it in tests and debug builds.
+func (r *Redactor) Strict() *Redactor {
+	copied := *r
+	copied.strict = true
+	return &copied
+}
+
+// IsStrict reports whether r was created with Strict
+func (r *Redactor) IsStrict() bool {
+	return r.strict
+}
+
+// Signature shortens a hex signature to its first and last bytes, e.g.
+// "0x1a2b3c4d…5e6f7a1c (65 bytes)"
+func (r *Redactor) Signature(signature string) string {
+	digits := strings.TrimPrefix(strings.TrimPrefix(signature, "0x"), "0X")
+	edge := 2 * r.rules.SignatureEdgeBytes
+	if len(digits) <= 2*edge {
+		return signature
+	}
+	return fmt.Sprintf("0x%s…%s (%d bytes)", digits[:edge], digits[len(digits)-edge:], len(digits)/2)
+}
+
+// Calldata truncates hex data longer than MaxCalldataBytes, e.g.
+// "0xa9059cbb…(+36 bytes)"
+func (r *Redactor) Calldata(data string) string {
+	max := r.rules.MaxCalldataBytes
+	digits := strings.TrimPrefix(strings.TrimPrefix(data, "0x"), "0X")
+	if max < 0 || len(digits) <= 2*max {
+		return data
+	}
+	return fmt.Sprintf("0x%s…(+%d bytes)", digits[:2*max], (len(digits)-2*max)/2)
+}
+
+// Label returns a short stable stand-in for an address, for metric labels
+// and other places where raw addresses would be unbounded
+func (r *Redactor) Label(address string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(address)))
+	return "addr-" + hex.EncodeToString(sum[:4])
+}
+
+// JSON renders v (e.g. a transaction request) as indented JSON with
+// Scrub applied
+func (r *Redactor) JSON(v interface{}) string {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Sprintf("<unprintable %T: %v>", v, err)
+	}
+	return r.Scrub(string(data))
+}
+
+// Scrub redacts free text such as log lines and error messages: secrets
+// and sensitive environment values are replaced, signature-sized hex is
+// shortened and other long hex is truncated
+func (r *Redactor) Scrub(text string) string {
+	for _, secret := range r.allSecrets() {
+		text = strings.ReplaceAll(text, secret, Placeholder)
+	}
+	return hexPattern.ReplaceAllStringFunc(text, func(blob string) string {
+		bytesLen := (len(blob) - 2) / 2
+		if bytesLen >= signatureLength && bytesLen%signatureLength == 0 && len(blob)%2 == 0 {
+			return r.Signature(blob)
+		}
+		return r.Calldata(blob)
+	})
+}
+
+// allSecrets returns the explicit secrets and the values of the sensitive
+// environment variables that are set
+func (r *Redactor) allSecrets() []string {
+	secrets := r.secrets
+	for _, name := range r.rules.SensitiveEnv {
+		if value := os.Getenv(name); len(value) >= minSecretLength {
+			secrets = append(secrets, value)
+		}
+	}
+	return secrets
+}
+
+// Writer returns a writer that scrubs everything written to w, for use as
+// a log.Logger's output. Under Strict it panics instead when the text
+// needed redaction.
+func (r *Redactor) Writer(w io.Writer) io.Writer {
+	return &writer{redactor: r, out: w}
+}
+
+// writer is the io.Writer returned by Redactor.Writer
+type writer struct {
+	redactor *Redactor
+	out      io.Writer
+}
+
+// Write scrubs p and writes it to the underlying writer, reporting len(p)
+// as written
+func (w *writer) Write(p []byte) (int, error) {
+	scrubbed := w.redactor.Scrub(string(p))
+	if w.redactor.strict && scrubbed != string(p) {
+		panic(fmt.Sprintf("redact: unredacted sensitive value in log output: %q", bytes.TrimSpace([]byte(scrubbed))))
+	}
+	if _, err := io.WriteString(w.out, scrubbed); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,124 @@
+package redact
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"testing"
+)
+
+// testSignature is a 65-byte signature
+var testSignature = "0x1a2b3c4d" + strings.Repeat("00", 57) + "5e6f7a1f"
+
+func TestRedactor_Scrub(t *testing.T) {
+	calldata := "0xa9059cbb" + strings.Repeat("ab", 96)
+	hash := "0x" + strings.Repeat("cd", 32)
+
+	tests := []struct {
+		name  string
+		rules Rules
+		text  string
+		want  string
+	}{
+		{
+			name: "signature",
+			text: "signature: " + testSignature,
+			want: "signature: 0x1a2b3c4d…5e6f7a1f (65 bytes)",
+		},
+		{
+			name: "two packed signatures",
+			text: testSignature + testSignature[2:],
+			want: "0x1a2b3c4d…5e6f7a1f (130 bytes)",
+		},
+		{
+			name: "long calldata",
+			text: "data " + calldata,
+			want: "data 0xa9059cbb" + strings.Repeat("ab", 60) + "…(+36 bytes)",
+		},
+		{
+			name:  "calldata under a custom limit",
+			rules: Rules{MaxCalldataBytes: 4},
+			text:  calldata,
+			want:  "0xa9059cbb…(+96 bytes)",
+		},
+		{
+			name:  "truncation disabled",
+			rules: Rules{MaxCalldataBytes: -1},
+			text:  calldata,
+			want:  calldata,
+		},
+		{
+			name: "hashes and addresses are kept",
+			text: hash + " 0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266",
+			want: hash + " 0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := New(tt.rules)
+			got := r.Scrub(tt.text)
+			if got != tt.want {
+				t.Errorf("Scrub() = %s\nwant %s", got, tt.want)
+			}
+			if again := r.Scrub(got); again != got {
+				t.Errorf("Scrub is not idempotent: %s", again)
+			}
+		})
+	}
+}
+
+func TestRedactor_Secrets(t *testing.T) {
+	t.Setenv("BUILDER_SECRET", "env-builder-secret")
+	t.Setenv("PK", "short")
+
+	r := Default().WithSecrets("my-private-key", "tiny")
+	got := r.Scrub("key=my-private-key secret=env-builder-secret pk=short tiny")
+	want := "key=[REDACTED] secret=[REDACTED] pk=short tiny"
+	if got != want {
+		t.Errorf("Scrub() = %s, want %s", got, want)
+	}
+	if Default().Scrub("my-private-key") != "my-private-key" {
+		t.Error("WithSecrets modified the default redactor")
+	}
+}
+
+func TestRedactor_Label(t *testing.T) {
+	r := Default()
+	a := r.Label("0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266")
+	if a != r.Label("0xf39fd6e51aad88f6f4ce6ab8827279cfffb92266") {
+		t.Error("Label depends on address case")
+	}
+	if strings.Contains(strings.ToLower(a), "f39fd6e5") || len(a) != len("addr-")+8 {
+		t.Errorf("Label() = %s, want a short hash", a)
+	}
+	if a == r.Label("0x6e0c80c90ea6c15917308F820Eac91Ce2724B5b5") {
+		t.Error("different addresses share a label")
+	}
+}
+
+func TestRedactor_JSON(t *testing.T) {
+	got := Default().JSON(map[string]string{"signature": testSignature, "nonce": "7"})
+	if strings.Contains(got, testSignature) || !strings.Contains(got, "0x1a2b3c4d…5e6f7a1f") || !strings.Contains(got, `"nonce": "7"`) {
+		t.Errorf("JSON() = %s", got)
+	}
+}
+
+func TestRedactor_Writer(t *testing.T) {
+	var buf bytes.Buffer
+	logger := log.New(Default().Writer(&buf), "", 0)
+	logger.Printf("signed %s", testSignature)
+	if got := buf.String(); got != "signed 0x1a2b3c4d…5e6f7a1f (65 bytes)\n" {
+		t.Errorf("logged %q", got)
+	}
+
+	strict := log.New(Default().Strict().Writer(&buf), "", 0)
+	strict.Printf("signed %s", Default().Signature(testSignature))
+
+	defer func() {
+		if recover() == nil {
+			t.Error("strict writer did not panic on an unredacted signature")
+		}
+	}()
+	strict.Printf("signed %s", testSignature)
+}
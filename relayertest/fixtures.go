@@ -0,0 +1,161 @@
+// Package relayertest provides deterministic fixtures and a scripted relayer
+// server for testing code built on the relayer client. It is for tests
+// only: its key is public and must never hold funds.
+//
+// A test needs only a few lines:
+//
+//	relayer := relayertest.NewScriptedRelayer(t)
+//	c, err := client.NewRelayClient(relayer.URL(), relayertest.ChainID, relayertest.TestPrivateKey, builderConfig)
+//	...
+//	_, err = c.Execute(relayertest.SampleApprovalBatch(), "approve")
+//	if got := len(relayer.Submissions()); got != 1 { ... }
+package relayertest
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/davidt58/go-builder-relayer-client/builder"
+	"github.com/davidt58/go-builder-relayer-client/config"
+	"github.com/davidt58/go-builder-relayer-client/models"
+	"github.com/davidt58/go-builder-relayer-client/signer"
+)
+
+const (
+	// TestPrivateKey is the well-known Anvil and Hardhat account #0 key.
+	// It is public; never use it outside tests.
+	TestPrivateKey = "0xac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80"
+	// TestAddress is the address of TestPrivateKey
+	TestAddress = "0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266"
+	// ChainID is the chain the fixtures target unless overridden (Polygon mainnet)
+	ChainID int64 = 137
+	// SampleNonce is the Safe nonce of SampleTransactionRequest unless overridden
+	SampleNonce = "0"
+)
+
+// TestSigner returns a signer for TestPrivateKey on ChainID
+func TestSigner() *signer.Signer {
+	return testSignerFor(ChainID)
+}
+
+// testSignerFor returns a signer for TestPrivateKey on chainID
+func testSignerFor(chainID int64) *signer.Signer {
+	sig, err := signer.NewSigner(TestPrivateKey, chainID)
+	if err != nil {
+		panic("relayertest: " + err.Error())
+	}
+	return sig
+}
+
+// TestSafeAddress returns the Safe derived for TestAddress on chainID,
+// which must be a supported chain
+func TestSafeAddress(chainID int64) string {
+	safeAddress, err := builder.DeriveSafeAddress(common.HexToAddress(TestAddress), chainID)
+	if err != nil {
+		panic("relayertest: " + err.Error())
+	}
+	return safeAddress.Hex()
+}
+
+// SampleApprovalBatch returns a batch approving the CTF Exchange and the
+// NegRisk CTF Exchange to spend unlimited USDC on ChainID
+func SampleApprovalBatch() []models.SafeTransaction {
+	contracts, err := config.GetKnownContracts(ChainID)
+	if err != nil {
+		panic("relayertest: " + err.Error())
+	}
+	maxUint256 := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+
+	batch := make([]models.SafeTransaction, 0, 2)
+	for _, spender := range []string{contracts.CTFExchange, contracts.NegRiskCTFExchange} {
+		txn, err := models.NewTxBuilder().
+			To(contracts.USDC).
+			FromABICall(builder.ERC20ABI, "approve", common.HexToAddress(spender), maxUint256).
+			Call().
+			Build()
+		if err != nil {
+			panic("relayertest: " + err.Error())
+		}
+		batch = append(batch, txn)
+	}
+	return batch
+}
+
+// RequestOption overrides a default of SampleTransactionRequest
+type RequestOption func(*requestOptions)
+
+// requestOptions are the inputs of SampleTransactionRequest
+type requestOptions struct {
+	chainID      int64
+	nonce        string
+	transactions []models.SafeTransaction
+	metadata     string
+	signer       *signer.Signer
+}
+
+// WithChainID builds the request for chainID instead of ChainID
+func WithChainID(chainID int64) RequestOption {
+	return func(o *requestOptions) { o.chainID = chainID }
+}
+
+// WithNonce signs the request with nonce instead of SampleNonce
+func WithNonce(nonce string) RequestOption {
+	return func(o *requestOptions) { o.nonce = nonce }
+}
+
+// WithTransactions batches transactions instead of SampleApprovalBatch
+func WithTransactions(transactions ...models.SafeTransaction) RequestOption {
+	return func(o *requestOptions) { o.transactions = transactions }
+}
+
+// WithMetadata sets the request's metadata (none by default)
+func WithMetadata(metadata string) RequestOption {
+	return func(o *requestOptions) { o.metadata = metadata }
+}
+
+// WithSigner signs the request with sig, for the Safe derived for its
+// address, instead of the test key
+func WithSigner(sig *signer.Signer) RequestOption {
+	return func(o *requestOptions) { o.signer = sig }
+}
+
+// SampleTransactionRequest returns a signed SAFE transaction request, by
+// default for the test key's Safe on ChainID at SampleNonce batching
+// SampleApprovalBatch. It fails the test if the request cannot be built.
+func SampleTransactionRequest(t testing.TB, opts ...RequestOption) *models.TransactionRequest {
+	t.Helper()
+
+	o := requestOptions{chainID: ChainID, nonce: SampleNonce}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.transactions == nil {
+		o.transactions = SampleApprovalBatch()
+	}
+	if o.signer == nil {
+		o.signer = testSignerFor(o.chainID)
+	}
+
+	contractConfig, err := config.GetContractConfig(o.chainID)
+	if err != nil {
+		t.Fatalf("relayertest: %v", err)
+	}
+	safeAddress, err := builder.DeriveSafeAddressWithConfig(o.signer.Address(), contractConfig)
+	if err != nil {
+		t.Fatalf("relayertest: %v", err)
+	}
+
+	args := &models.SafeTransactionArgs{
+		SafeAddress:  safeAddress.Hex(),
+		Transactions: o.transactions,
+		Nonce:        o.nonce,
+		Metadata:     models.MetadataOf(o.metadata),
+	}
+	request, err := builder.BuildSafeTransactionRequestWithConfig(args, o.signer, contractConfig)
+	if err != nil {
+		t.Fatalf("relayertest: %v", err)
+	}
+	return request
+}
@@ -0,0 +1,286 @@
+package relayertest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	relayerhttp "github.com/davidt58/go-builder-relayer-client/http"
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+// Relayer API paths the ScriptedRelayer serves by default; they match the
+// endpoint constants of the client package
+const (
+	noncePath       = "/nonce"
+	deployedPath    = "/deployed"
+	transactionPath = "/transaction"
+	submitPath      = "/submit"
+)
+
+// ScriptedRelayer is an in-memory relayer server for tests. By default it
+// tracks the Safe nonce, reports the Safe as deployed and records every
+// submission; any path can be scripted with Handle. It is safe for
+// concurrent use.
+type ScriptedRelayer struct {
+	mu    sync.Mutex
+	nonce int64
+	// deployed is what the deployed endpoint reports
+	deployed  bool
+	submitted []models.TransactionRequest
+	versions  []models.RequestVersion
+	opIDs     []string
+	handlers  map[string]http.HandlerFunc
+	latency   map[string]time.Duration
+	envelope  Envelope
+
+	server *httptest.Server
+	// closed releases delayed handlers when the test ends
+	closed chan struct{}
+}
+
+// NewScriptedRelayer starts a ScriptedRelayer that is shut down when the test ends
+func NewScriptedRelayer(t testing.TB) *ScriptedRelayer {
+	t.Helper()
+
+	r := &ScriptedRelayer{
+		deployed: true,
+		handlers: make(map[string]http.HandlerFunc),
+		latency:  make(map[string]time.Duration),
+		closed:   make(chan struct{}),
+	}
+	r.server = httptest.NewServer(http.HandlerFunc(r.serve))
+	t.Cleanup(r.server.Close)
+	// Runs before Close, which waits for handlers still sleeping on latency
+	t.Cleanup(func() { close(r.closed) })
+	return r
+}
+
+// URL returns the base URL to point a client at
+func (r *ScriptedRelayer) URL() string {
+	return r.server.URL
+}
+
+// Handle overrides the default behaviour for a path; handler may delegate
+// to ServeDefault
+func (r *ScriptedRelayer) Handle(path string, handler http.HandlerFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.handlers[path] = handler
+}
+
+// Delay injects latency before responding on a path
+func (r *ScriptedRelayer) Delay(path string, d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.latency[path] = d
+}
+
+// SetDeployed changes whether the deployed endpoint reports the Safe as
+// deployed (true by default)
+func (r *ScriptedRelayer) SetDeployed(deployed bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deployed = deployed
+}
+
+// SetNonce changes the Safe nonce the relayer reports and expects on the
+// next submission (0 by default)
+func (r *ScriptedRelayer) SetNonce(nonce int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nonce = nonce
+}
+
+// Nonce returns the Safe nonce the relayer expects on the next submission
+func (r *ScriptedRelayer) Nonce() int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.nonce
+}
+
+// ScriptStates makes the transaction endpoint report states in order, one
+// per poll, repeating the last state once they are used up
+func (r *ScriptedRelayer) ScriptStates(states ...models.RelayerTransactionState) {
+	var mu sync.Mutex
+	poll := 0
+	r.Handle(transactionPath, func(w http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		state := states[len(states)-1]
+		if poll < len(states) {
+			state = states[poll]
+		}
+		poll++
+		mu.Unlock()
+
+		json.NewEncoder(w).Encode([]models.RelayerTransaction{{
+			TransactionID: req.URL.Query().Get("id"),
+			State:         state,
+		}})
+	})
+}
+
+// Envelope is how a ScriptedRelayer wraps its responses
+type Envelope int
+
+const (
+	// NoEnvelope sends bare responses (the default)
+	NoEnvelope Envelope = iota
+	// DataEnvelope wraps payloads as {"success": true, "data": ...} and
+	// errors as {"success": false, "error": {...}}, keeping their status
+	DataEnvelope
+	// DataEnvelopeOK is DataEnvelope sending errors with a 200, their
+	// status only in the error object
+	DataEnvelopeOK
+)
+
+// SetEnvelope makes the relayer wrap every response, including those of
+// handlers set with Handle, as envelope says
+func (r *ScriptedRelayer) SetEnvelope(envelope Envelope) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.envelope = envelope
+}
+
+// Submissions returns a copy of the recorded submit requests
+func (r *ScriptedRelayer) Submissions() []models.TransactionRequest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]models.TransactionRequest(nil), r.submitted...)
+}
+
+// RequestVersions returns the payload version of every recorded submission
+func (r *ScriptedRelayer) RequestVersions() []models.RequestVersion {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]models.RequestVersion(nil), r.versions...)
+}
+
+// OperationIDs returns the X-Client-Operation-Id header of every request received
+func (r *ScriptedRelayer) OperationIDs() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.opIDs...)
+}
+
+func (r *ScriptedRelayer) serve(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	r.opIDs = append(r.opIDs, req.Header.Get(relayerhttp.OperationIDHeader))
+	handler, ok := r.handlers[req.URL.Path]
+	latency := r.latency[req.URL.Path]
+	envelope := r.envelope
+	r.mu.Unlock()
+
+	if latency > 0 {
+		select {
+		case <-time.After(latency):
+		case <-req.Context().Done():
+			return
+		case <-r.closed:
+			return
+		}
+	}
+
+	if !ok {
+		handler = r.ServeDefault
+	}
+	if envelope == NoEnvelope {
+		handler(w, req)
+		return
+	}
+	recorder := httptest.NewRecorder()
+	handler(recorder, req)
+	writeEnveloped(w, recorder, envelope)
+}
+
+// writeEnveloped writes a recorded response to w wrapped as envelope says.
+// Empty bodies, such as a 304's, are written unchanged.
+func writeEnveloped(w http.ResponseWriter, recorded *httptest.ResponseRecorder, envelope Envelope) {
+	for key, values := range recorded.Header() {
+		w.Header()[key] = values
+	}
+	status, body := recorded.Code, bytes.TrimSpace(recorded.Body.Bytes())
+	if len(body) == 0 {
+		w.WriteHeader(status)
+		return
+	}
+
+	if status < 400 {
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": json.RawMessage(body)})
+		return
+	}
+	var failure models.ErrorResponse
+	json.Unmarshal(body, &failure)
+	if failure.Error == "" {
+		failure.Error = string(body)
+	}
+	errorObject := map[string]interface{}{"message": failure.Error, "status": status}
+	if failure.Code != nil {
+		errorObject["code"] = *failure.Code
+	}
+	if failure.Details != nil {
+		errorObject["details"] = failure.Details
+	}
+	if envelope == DataEnvelopeOK {
+		status = http.StatusOK
+	}
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{"success": false, "error": errorObject})
+}
+
+// ServeDefault implements the built-in relayer behaviour: the nonce and
+// deployed endpoints report the scripted state, and a submission must carry
+// the current nonce, which it then increments
+func (r *ScriptedRelayer) ServeDefault(w http.ResponseWriter, req *http.Request) {
+	switch req.URL.Path {
+	case noncePath:
+		json.NewEncoder(w).Encode(models.NonceResponse{Nonce: strconv.FormatInt(r.Nonce(), 10)})
+
+	case deployedPath:
+		r.mu.Lock()
+		deployed := r.deployed
+		r.mu.Unlock()
+		json.NewEncoder(w).Encode(models.DeployedResponse{Deployed: deployed})
+
+	case submitPath:
+		body, _ := io.ReadAll(req.Body)
+		request, version, err := models.DecodeTransactionRequest(body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		r.mu.Lock()
+		if request.Nonce != nil {
+			if *request.Nonce != strconv.FormatInt(r.nonce, 10) {
+				r.mu.Unlock()
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(models.ErrorResponse{Error: "invalid nonce"})
+				return
+			}
+			r.nonce++
+		}
+		r.submitted = append(r.submitted, *request)
+		r.versions = append(r.versions, version)
+		id := fmt.Sprintf("tx-%d", len(r.submitted))
+		if request.Nonce != nil {
+			id = "tx-nonce-" + *request.Nonce
+		}
+		r.mu.Unlock()
+
+		json.NewEncoder(w).Encode(models.SubmitTransactionResponse{TransactionID: id, State: models.STATE_NEW})
+
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(models.ErrorResponse{Error: "not found"})
+	}
+}
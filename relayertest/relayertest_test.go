@@ -0,0 +1,93 @@
+package relayertest_test
+
+import (
+	"encoding/base64"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/davidt58/go-builder-relayer-client/builder"
+	"github.com/davidt58/go-builder-relayer-client/client"
+	"github.com/davidt58/go-builder-relayer-client/clock"
+	"github.com/davidt58/go-builder-relayer-client/config"
+	"github.com/davidt58/go-builder-relayer-client/models"
+	"github.com/davidt58/go-builder-relayer-client/relayertest"
+)
+
+func TestFixturesAreDeterministic(t *testing.T) {
+	if got := relayertest.TestSigner().AddressHex(); got != relayertest.TestAddress {
+		t.Errorf("TestSigner address = %s, want %s", got, relayertest.TestAddress)
+	}
+	if relayertest.TestSafeAddress(137) != relayertest.TestSafeAddress(137) {
+		t.Error("TestSafeAddress is not deterministic")
+	}
+
+	batch := relayertest.SampleApprovalBatch()
+	if len(batch) != 2 {
+		t.Fatalf("SampleApprovalBatch has %d transactions, want 2", len(batch))
+	}
+	for i, txn := range batch {
+		call, found, err := builder.DecodeERC20Call(txn.Data)
+		if err != nil || !found || call.Method != "approve" {
+			t.Errorf("batch[%d] is not an approve: %+v, %v", i, call, err)
+		}
+	}
+
+	first, second := relayertest.SampleTransactionRequest(t), relayertest.SampleTransactionRequest(t)
+	if !reflect.DeepEqual(first, second) {
+		t.Error("SampleTransactionRequest is not deterministic")
+	}
+	if first.Nonce == nil || *first.Nonce != relayertest.SampleNonce || first.ProxyWallet != relayertest.TestSafeAddress(137) {
+		t.Errorf("SampleTransactionRequest = %+v, want nonce %s from the test Safe", first, relayertest.SampleNonce)
+	}
+}
+
+func TestSampleTransactionRequest_Overrides(t *testing.T) {
+	transfer := *models.NewSafeTransaction("0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174", "0", "0x")
+	request := relayertest.SampleTransactionRequest(t,
+		relayertest.WithChainID(80002),
+		relayertest.WithNonce("9"),
+		relayertest.WithTransactions(transfer),
+		relayertest.WithMetadata("fixture"),
+	)
+
+	if *request.Nonce != "9" || request.Metadata == nil || *request.Metadata != "fixture" {
+		t.Errorf("overrides not applied: %+v", request)
+	}
+	if string(request.To) != `"`+transfer.To+`"` {
+		t.Errorf("To = %s, want the single transaction's %s", request.To, transfer.To)
+	}
+	if request.ProxyWallet != relayertest.TestSafeAddress(80002) {
+		t.Errorf("ProxyWallet = %s, want the Amoy test Safe", request.ProxyWallet)
+	}
+}
+
+func TestScriptedRelayer_WithClient(t *testing.T) {
+	relayer := relayertest.NewScriptedRelayer(t)
+	relayer.SetNonce(5)
+	relayer.ScriptStates(models.STATE_NEW, models.STATE_MINED)
+
+	secret := base64.URLEncoding.EncodeToString([]byte("test-secret"))
+	c, err := client.NewRelayClient(relayer.URL(), relayertest.ChainID, relayertest.TestPrivateKey,
+		config.NewBuilderConfig("test-key", secret, "test-pass"), client.WithLogOutput(io.Discard), client.WithClock(clock.NewAutoFake(time.Now())))
+	if err != nil {
+		t.Fatalf("NewRelayClient failed: %v", err)
+	}
+
+	response, err := c.Execute(relayertest.SampleApprovalBatch(), "approve")
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if _, err := response.WaitUntilMined(); err != nil {
+		t.Fatalf("WaitUntilMined failed: %v", err)
+	}
+
+	submissions := relayer.Submissions()
+	if len(submissions) != 1 || *submissions[0].Nonce != "5" || submissions[0].ProxyWallet != relayertest.TestSafeAddress(relayertest.ChainID) {
+		t.Errorf("submissions = %+v, want one at nonce 5 from the test Safe", submissions)
+	}
+	if relayer.Nonce() != 6 {
+		t.Errorf("relayer nonce = %d, want 6", relayer.Nonce())
+	}
+}
@@ -0,0 +1,40 @@
+package signer
+
+import (
+	"crypto/ecdsa"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// SignerBackend produces the 65-byte r ‖ s ‖ v signatures behind a Signer.
+// v may be returned as 0/1 or 27/28; Signer normalizes it to 27/28.
+type SignerBackend interface {
+	// SignHash signs a 32-byte digest as-is
+	SignHash(hash []byte) ([]byte, error)
+	// SignPersonal signs message under the EIP-191 personal prefix
+	// "\x19Ethereum Signed Message:\n{len}", as eth_sign and personal_sign do
+	SignPersonal(message []byte) ([]byte, error)
+	// SignTypedData signs the EIP-712 digest of typedData, as
+	// eth_signTypedData_v4 does; digest is HashTypedData(typedData)
+	SignTypedData(typedData *TypedData, digest []byte) ([]byte, error)
+}
+
+// keyBackend signs with a private key held in memory
+type keyBackend struct {
+	privateKey *ecdsa.PrivateKey
+}
+
+// SignHash implements SignerBackend
+func (b *keyBackend) SignHash(hash []byte) ([]byte, error) {
+	return crypto.Sign(hash, b.privateKey)
+}
+
+// SignPersonal implements SignerBackend
+func (b *keyBackend) SignPersonal(message []byte) ([]byte, error) {
+	return crypto.Sign(ethSignHash(message), b.privateKey)
+}
+
+// SignTypedData implements SignerBackend
+func (b *keyBackend) SignTypedData(typedData *TypedData, digest []byte) ([]byte, error) {
+	return crypto.Sign(digest, b.privateKey)
+}
@@ -1,10 +1,10 @@
 package signer
 
 import (
-	"encoding/json"
 	"fmt"
 	"math/big"
 	"reflect"
+	"sort"
 	"strings"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -39,49 +39,49 @@ type TypedData struct {
 
 // HashTypedData computes the EIP-712 hash of typed data
 func HashTypedData(typedData *TypedData) (common.Hash, error) {
+	_, _, digest, err := HashTypedDataParts(typedData)
+	return digest, err
+}
+
+// HashTypedDataParts returns the domain separator, the hash of the primary
+// struct and the final digest keccak256("\x19\x01" ‖ domainSeparator ‖ structHash)
+// that HashTypedData returns. As in eth_signTypedData_v4, an EIP712Domain
+// primary type hashes the message as an EIP712Domain struct, so the struct
+// hash equals the domain separator only when the message is the domain.
+func HashTypedDataParts(typedData *TypedData) (domainSeparator, structHash, digest common.Hash, err error) {
 	// Hash the domain separator
-	domainSeparator, err := hashDomain(typedData.Domain, typedData.Types)
+	domainSeparator, err = hashDomain(typedData.Domain, typedData.Types)
 	if err != nil {
-		return common.Hash{}, err
+		return common.Hash{}, common.Hash{}, common.Hash{}, err
 	}
 
-	// If the primary type is EIP712Domain, just return the domain hash wrapped
+	types := typedData.Types
 	if typedData.PrimaryType == "EIP712Domain" {
-		// Special case: just hashing the domain itself
-		rawData := []byte{0x19, 0x01}
-		rawData = append(rawData, domainSeparator[:]...)
-		// For domain-only, we hash it with itself
-		rawData = append(rawData, domainSeparator[:]...)
-		return crypto.Keccak256Hash(rawData), nil
+		// The message is hashed with the same domain type as the separator
+		types = withDomainType(types)
 	}
 
 	// Hash the message
-	messageHash, err := hashStruct(typedData.PrimaryType, typedData.Message, typedData.Types)
+	structHash, err = hashStruct(typedData.PrimaryType, typedData.Message, types)
 	if err != nil {
-		return common.Hash{}, err
+		return common.Hash{}, common.Hash{}, common.Hash{}, err
 	}
 
-	// Compute final hash: keccak256("\x19\x01" ‖ domainSeparator ‖ messageHash)
+	return domainSeparator, structHash, TypedDataDigest(domainSeparator, structHash), nil
+}
+
+// TypedDataDigest computes keccak256("\x19\x01" ‖ domainSeparator ‖ structHash)
+func TypedDataDigest(domainSeparator, structHash common.Hash) common.Hash {
 	rawData := []byte{0x19, 0x01}
 	rawData = append(rawData, domainSeparator[:]...)
-	rawData = append(rawData, messageHash[:]...)
-
-	return crypto.Keccak256Hash(rawData), nil
+	rawData = append(rawData, structHash[:]...)
+	return crypto.Keccak256Hash(rawData)
 }
 
 // hashDomain hashes the EIP712Domain according to EIP-712
 func hashDomain(domain EIP712Domain, types map[string][]EIP712Type) (common.Hash, error) {
 	// Get the EIP712Domain type definition
-	domainTypes, exists := types["EIP712Domain"]
-	if !exists {
-		// Use default EIP712Domain type
-		domainTypes = []EIP712Type{
-			{Name: "name", Type: "string"},
-			{Name: "version", Type: "string"},
-			{Name: "chainId", Type: "uint256"},
-			{Name: "verifyingContract", Type: "address"},
-		}
-	}
+	domainTypes := withDomainType(types)["EIP712Domain"]
 
 	// Compute type hash
 	typeHash, err := hashType("EIP712Domain", domainTypes)
@@ -94,7 +94,10 @@ func hashDomain(domain EIP712Domain, types map[string][]EIP712Type) (common.Hash
 	encoded = append(encoded, typeHash[:]...)
 
 	// Convert domain to map
-	domainMap := structToMap(domain)
+	domainMap, err := structToMap(domain)
+	if err != nil {
+		return common.Hash{}, err
+	}
 
 	// Encode each field in the domain type
 	for _, field := range domainTypes {
@@ -114,15 +117,30 @@ func hashDomain(domain EIP712Domain, types map[string][]EIP712Type) (common.Hash
 	return crypto.Keccak256Hash(encoded), nil
 }
 
-// hashStruct hashes a struct according to EIP-712
-func hashStruct(primaryType string, data interface{}, types map[string][]EIP712Type) (common.Hash, error) {
-	typeFields, exists := types[primaryType]
-	if !exists {
-		return common.Hash{}, errors.NewRelayerClientError(fmt.Sprintf("type %s not found", primaryType), nil)
+// withDomainType returns types with the default EIP712Domain type added if
+// it has none
+func withDomainType(types map[string][]EIP712Type) map[string][]EIP712Type {
+	if _, exists := types["EIP712Domain"]; exists {
+		return types
 	}
 
-	// Compute type hash
-	typeHash, err := hashType(primaryType, typeFields)
+	withDomain := make(map[string][]EIP712Type, len(types)+1)
+	for name, fields := range types {
+		withDomain[name] = fields
+	}
+	withDomain["EIP712Domain"] = []EIP712Type{
+		{Name: "name", Type: "string"},
+		{Name: "version", Type: "string"},
+		{Name: "chainId", Type: "uint256"},
+		{Name: "verifyingContract", Type: "address"},
+	}
+	return withDomain
+}
+
+// hashStruct hashes a struct according to EIP-712
+func hashStruct(primaryType string, data interface{}, types map[string][]EIP712Type) (common.Hash, error) {
+	// Compute type hash, including the struct types it references
+	typeHash, err := typeHash(primaryType, types)
 	if err != nil {
 		return common.Hash{}, err
 	}
@@ -163,17 +181,51 @@ func encodeTypeString(typeName string, typeFields []EIP712Type) string {
 	return result.String()
 }
 
-// encodeType encodes a type name according to EIP-712 (kept for compatibility)
+// encodeType encodes a type according to EIP-712: the type itself followed
+// by the struct types it references, sorted by name
 func encodeType(primaryType string, types map[string][]EIP712Type) (string, error) {
 	typeFields, exists := types[primaryType]
 	if !exists {
 		return "", errors.NewRelayerClientError(fmt.Sprintf("type %s not found", primaryType), nil)
 	}
 
-	return encodeTypeString(primaryType, typeFields), nil
+	deps := make(map[string]bool)
+	collectTypeDeps(primaryType, types, deps)
+	delete(deps, primaryType)
+	names := make([]string, 0, len(deps))
+	for name := range deps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	encoded := encodeTypeString(primaryType, typeFields)
+	for _, name := range names {
+		encoded += encodeTypeString(name, types[name])
+	}
+	return encoded, nil
 }
 
-// typeHash computes the type hash for a given type name (kept for compatibility)
+// collectTypeDeps adds typeName and the struct types it references,
+// directly or through arrays, to deps
+func collectTypeDeps(typeName string, types map[string][]EIP712Type, deps map[string]bool) {
+	if deps[typeName] {
+		return
+	}
+	typeFields, exists := types[typeName]
+	if !exists {
+		return
+	}
+	deps[typeName] = true
+	for _, field := range typeFields {
+		fieldType := field.Type
+		if i := strings.Index(fieldType, "["); i >= 0 {
+			fieldType = fieldType[:i]
+		}
+		collectTypeDeps(fieldType, types, deps)
+	}
+}
+
+// typeHash computes the type hash for a given type name
 func typeHash(primaryType string, types map[string][]EIP712Type) (common.Hash, error) {
 	typeStr, err := encodeType(primaryType, types)
 	if err != nil {
@@ -246,6 +298,13 @@ func encodeValue(fieldType string, value interface{}, types map[string][]EIP712T
 			bytes, _ = hexutil.Decode(v)
 		case []byte:
 			bytes = v
+		case common.Hash:
+			bytes = v[:]
+		case *common.Hash:
+			if v == nil {
+				return nil, errors.ErrInvalidTypedData("nil " + fieldType)
+			}
+			bytes = v[:]
 		default:
 			return nil, errors.NewRelayerClientError(fmt.Sprintf("expected bytes, got %T", value), nil)
 		}
@@ -261,6 +320,11 @@ func encodeValue(fieldType string, value interface{}, types map[string][]EIP712T
 			addr = common.HexToAddress(v)
 		case common.Address:
 			addr = v
+		case *common.Address:
+			if v == nil {
+				return nil, errors.ErrInvalidTypedData("nil address")
+			}
+			addr = *v
 		default:
 			return nil, errors.NewRelayerClientError(fmt.Sprintf("expected address, got %T", value), nil)
 		}
@@ -276,11 +340,18 @@ func encodeValue(fieldType string, value interface{}, types map[string][]EIP712T
 			bigInt = new(big.Int)
 			bigInt.SetString(v, 0)
 		case *big.Int:
+			if v == nil {
+				return nil, errors.ErrInvalidTypedData("nil " + fieldType)
+			}
 			bigInt = v
+		case big.Int:
+			bigInt = &v
 		case int64:
 			bigInt = big.NewInt(v)
 		case int:
 			bigInt = big.NewInt(int64(v))
+		case uint64:
+			bigInt = new(big.Int).SetUint64(v)
 		case float64:
 			bigInt = big.NewInt(int64(v))
 		default:
@@ -317,46 +388,92 @@ func encodeValue(fieldType string, value interface{}, types map[string][]EIP712T
 	}
 }
 
-// toMap converts various data types to a map[string]interface{}
+// toMap converts typed data (a map with string keys, a struct, or a pointer to
+// either) to a map[string]interface{}. Values are kept as-is so *big.Int and
+// common.Address fields reach encodeValue unchanged.
 func toMap(data interface{}) (map[string]interface{}, error) {
-	switch v := data.(type) {
-	case map[string]interface{}:
+	if v, ok := data.(map[string]interface{}); ok {
 		return v, nil
-	case EIP712Domain:
-		return structToMap(v), nil
-	default:
-		// Try JSON marshaling/unmarshaling
-		jsonData, err := json.Marshal(data)
-		if err != nil {
-			return nil, errors.ErrJSONMarshalFailed(err)
+	}
+
+	val, err := derefValue(data)
+	if err != nil {
+		return nil, err
+	}
+
+	switch val.Kind() {
+	case reflect.Struct:
+		return structToMap(val.Interface())
+	case reflect.Map:
+		if val.Type().Key().Kind() != reflect.String {
+			return nil, errors.ErrInvalidTypedData(fmt.Sprintf("map keys must be strings, got %s", val.Type().Key()))
 		}
-		var result map[string]interface{}
-		err = json.Unmarshal(jsonData, &result)
-		if err != nil {
-			return nil, errors.ErrJSONUnmarshalFailed(err)
+		result := make(map[string]interface{}, val.Len())
+		iter := val.MapRange()
+		for iter.Next() {
+			result[iter.Key().String()] = iter.Value().Interface()
 		}
 		return result, nil
+	default:
+		return nil, errors.ErrInvalidTypedData(fmt.Sprintf("expected a struct or map, got %T", data))
 	}
 }
 
-// structToMap converts a struct to a map using reflection
-func structToMap(data interface{}) map[string]interface{} {
+// structToMap converts a struct (or pointer to a struct) to a map keyed by
+// JSON field name. Untagged exported fields use their Go name, fields tagged
+// "-" and unexported fields are skipped, zero omitempty fields are omitted,
+// and embedded structs are flattened, mirroring encoding/json.
+func structToMap(data interface{}) (map[string]interface{}, error) {
+	val, err := derefValue(data)
+	if err != nil {
+		return nil, err
+	}
+	if val.Kind() != reflect.Struct {
+		return nil, errors.ErrInvalidTypedData(fmt.Sprintf("expected a struct, got %T", data))
+	}
+
 	result := make(map[string]interface{})
-	val := reflect.ValueOf(data)
-	typ := reflect.TypeOf(data)
+	addStructFields(val, result)
+	return result, nil
+}
 
+// addStructFields copies the fields of a struct value into result
+func addStructFields(val reflect.Value, result map[string]interface{}) {
+	typ := val.Type()
 	for i := 0; i < val.NumField(); i++ {
 		field := typ.Field(i)
 		value := val.Field(i)
 
 		// Get JSON tag name
 		jsonTag := field.Tag.Get("json")
-		if jsonTag == "" || jsonTag == "-" {
+		if jsonTag == "-" {
 			continue
 		}
 		// Remove options from tag
 		tagName := strings.Split(jsonTag, ",")[0]
 
+		// Flatten untagged embedded structs
+		if field.Anonymous && tagName == "" {
+			embedded := value
+			if embedded.Kind() == reflect.Ptr {
+				if embedded.IsNil() {
+					continue
+				}
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				addStructFields(embedded, result)
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+		if tagName == "" {
+			tagName = field.Name
+		}
+
 		// Skip omitempty fields that are zero values
 		if strings.Contains(jsonTag, "omitempty") && value.IsZero() {
 			continue
@@ -364,6 +481,19 @@ func structToMap(data interface{}) map[string]interface{} {
 
 		result[tagName] = value.Interface()
 	}
+}
 
-	return result
+// derefValue follows pointers to the underlying value, rejecting nil
+func derefValue(data interface{}) (reflect.Value, error) {
+	val := reflect.ValueOf(data)
+	for val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			return reflect.Value{}, errors.ErrInvalidTypedData(fmt.Sprintf("nil %T", data))
+		}
+		val = val.Elem()
+	}
+	if !val.IsValid() {
+		return reflect.Value{}, errors.ErrInvalidTypedData("nil data")
+	}
+	return val, nil
 }
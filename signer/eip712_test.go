@@ -2,9 +2,11 @@ package signer
 
 import (
 	"math/big"
+	"reflect"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 )
 
 func TestHashTypedData_SimpleDomain(t *testing.T) {
@@ -24,7 +26,12 @@ func TestHashTypedData_SimpleDomain(t *testing.T) {
 			ChainId:           big.NewInt(1),
 			VerifyingContract: common.HexToAddress("0x1234567890123456789012345678901234567890"),
 		},
-		Message: map[string]interface{}{},
+		Message: map[string]interface{}{
+			"name":              "Test",
+			"version":           "1",
+			"chainId":           big.NewInt(1),
+			"verifyingContract": "0x1234567890123456789012345678901234567890",
+		},
 	}
 
 	hash, err := HashTypedData(typedData)
@@ -82,6 +89,193 @@ func TestHashTypedData_WithMessage(t *testing.T) {
 	}
 }
 
+func TestHashTypedDataParts(t *testing.T) {
+	domain := EIP712Domain{
+		Name:              "Test App",
+		ChainId:           big.NewInt(137),
+		VerifyingContract: common.HexToAddress("0x1234567890123456789012345678901234567890"),
+	}
+	domainTypes := []EIP712Type{
+		{Name: "name", Type: "string"},
+		{Name: "chainId", Type: "uint256"},
+		{Name: "verifyingContract", Type: "address"},
+	}
+
+	tests := []struct {
+		name       string
+		typedData  *TypedData
+		domainOnly bool
+		shouldErr  bool
+	}{
+		{
+			name: "message",
+			typedData: &TypedData{
+				Types: map[string][]EIP712Type{
+					"EIP712Domain": domainTypes,
+					"Person":       {{Name: "name", Type: "string"}, {Name: "wallet", Type: "address"}},
+				},
+				PrimaryType: "Person",
+				Domain:      domain,
+				Message:     map[string]interface{}{"name": "Alice", "wallet": "0x0000000000000000000000000000000000000001"},
+			},
+		},
+		{
+			name: "domain only",
+			typedData: &TypedData{
+				Types:       map[string][]EIP712Type{"EIP712Domain": domainTypes},
+				PrimaryType: "EIP712Domain",
+				Domain:      domain,
+				Message: map[string]interface{}{
+					"name":              "Test App",
+					"chainId":           big.NewInt(137),
+					"verifyingContract": "0x1234567890123456789012345678901234567890",
+				},
+			},
+			domainOnly: true,
+		},
+		{
+			name: "domain type with another message",
+			typedData: &TypedData{
+				Types:       map[string][]EIP712Type{"EIP712Domain": domainTypes},
+				PrimaryType: "EIP712Domain",
+				Domain:      domain,
+				Message: map[string]interface{}{
+					"name":              "Other App",
+					"chainId":           big.NewInt(137),
+					"verifyingContract": "0x1234567890123456789012345678901234567890",
+				},
+			},
+		},
+		{
+			name: "domain type without a message",
+			typedData: &TypedData{
+				Types:       map[string][]EIP712Type{"EIP712Domain": domainTypes},
+				PrimaryType: "EIP712Domain",
+				Domain:      domain,
+			},
+			shouldErr: true,
+		},
+		{
+			name: "unknown primary type",
+			typedData: &TypedData{
+				Types:       map[string][]EIP712Type{"EIP712Domain": domainTypes},
+				PrimaryType: "Missing",
+				Domain:      domain,
+			},
+			shouldErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			domainSeparator, structHash, digest, err := HashTypedDataParts(tt.typedData)
+			if tt.shouldErr {
+				if err == nil {
+					t.Error("Expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("HashTypedDataParts failed: %v", err)
+			}
+
+			if want := crypto.Keccak256Hash([]byte{0x19, 0x01}, domainSeparator[:], structHash[:]); digest != want {
+				t.Errorf("digest = %s, want %s", digest.Hex(), want.Hex())
+			}
+			if (structHash == domainSeparator) != tt.domainOnly {
+				t.Errorf("struct hash %s, domain separator %s", structHash.Hex(), domainSeparator.Hex())
+			}
+			hash, err := HashTypedData(tt.typedData)
+			if err != nil || hash != digest {
+				t.Errorf("HashTypedData = %s (%v), want %s", hash.Hex(), err, digest.Hex())
+			}
+		})
+	}
+}
+
+func TestHashTypedData_Vectors(t *testing.T) {
+	mailDomain := EIP712Domain{
+		Name:              "Ether Mail",
+		Version:           "1",
+		ChainId:           big.NewInt(1),
+		VerifyingContract: common.HexToAddress("0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"),
+	}
+	domainTypes := []EIP712Type{
+		{Name: "name", Type: "string"},
+		{Name: "version", Type: "string"},
+		{Name: "chainId", Type: "uint256"},
+		{Name: "verifyingContract", Type: "address"},
+	}
+
+	// Digests as produced by ethers.js TypedDataEncoder and eth_signTypedData_v4
+	tests := []struct {
+		name      string
+		typedData *TypedData
+		want      string
+	}{
+		{
+			name: "EIP-712 Mail example",
+			typedData: &TypedData{
+				Types: map[string][]EIP712Type{
+					"EIP712Domain": domainTypes,
+					"Person":       {{Name: "name", Type: "string"}, {Name: "wallet", Type: "address"}},
+					"Mail":         {{Name: "from", Type: "Person"}, {Name: "to", Type: "Person"}, {Name: "contents", Type: "string"}},
+				},
+				PrimaryType: "Mail",
+				Domain:      mailDomain,
+				Message: map[string]interface{}{
+					"from":     map[string]interface{}{"name": "Cow", "wallet": "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"},
+					"to":       map[string]interface{}{"name": "Bob", "wallet": "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB"},
+					"contents": "Hello, Bob!",
+				},
+			},
+			want: "0xbe609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2",
+		},
+		{
+			name: "domain only",
+			typedData: &TypedData{
+				Types:       map[string][]EIP712Type{"EIP712Domain": domainTypes},
+				PrimaryType: "EIP712Domain",
+				Domain:      mailDomain,
+				Message: map[string]interface{}{
+					"name":              "Ether Mail",
+					"version":           "1",
+					"chainId":           big.NewInt(1),
+					"verifyingContract": "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC",
+				},
+			},
+			want: "0xe1ba4fc016818240ce4e274d2bf25bf03ae0058f9f577687258ec57e7b2b73d7",
+		},
+		{
+			name: "domain type with another message",
+			typedData: &TypedData{
+				Types:       map[string][]EIP712Type{"EIP712Domain": domainTypes},
+				PrimaryType: "EIP712Domain",
+				Domain:      mailDomain,
+				Message: map[string]interface{}{
+					"name":              "Other Mail",
+					"version":           "2",
+					"chainId":           big.NewInt(137),
+					"verifyingContract": "0x1234567890123456789012345678901234567890",
+				},
+			},
+			want: "0xb78c47996a75942bb8d25f8cb06564896ccd8cf29360fedf270df5a710506ccd",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hash, err := HashTypedData(tt.typedData)
+			if err != nil {
+				t.Fatalf("HashTypedData failed: %v", err)
+			}
+			if hash.Hex() != tt.want {
+				t.Errorf("HashTypedData() = %s, want %s", hash.Hex(), tt.want)
+			}
+		})
+	}
+}
+
 func TestEncodeType(t *testing.T) {
 	types := map[string][]EIP712Type{
 		"Person": {
@@ -101,6 +295,24 @@ func TestEncodeType(t *testing.T) {
 	}
 }
 
+func TestEncodeType_References(t *testing.T) {
+	types := map[string][]EIP712Type{
+		"Mail":   {{Name: "from", Type: "Person"}, {Name: "to", Type: "Person"}, {Name: "attachments", Type: "Asset[]"}},
+		"Person": {{Name: "name", Type: "string"}, {Name: "wallet", Type: "address"}},
+		"Asset":  {{Name: "hash", Type: "bytes32"}},
+	}
+
+	encoded, err := encodeType("Mail", types)
+	if err != nil {
+		t.Fatalf("encodeType failed: %v", err)
+	}
+
+	expected := "Mail(Person from,Person to,Asset[] attachments)Asset(bytes32 hash)Person(string name,address wallet)"
+	if encoded != expected {
+		t.Errorf("Encoded type = %s, want %s", encoded, expected)
+	}
+}
+
 func TestEncodeType_NotFound(t *testing.T) {
 	types := map[string][]EIP712Type{}
 
@@ -221,6 +433,148 @@ func TestToMap(t *testing.T) {
 	}
 }
 
+func TestToMap_Inputs(t *testing.T) {
+	type inner struct {
+		Value *big.Int `json:"value"`
+	}
+	type Embedded struct {
+		Owner common.Address `json:"owner"`
+	}
+	type outer struct {
+		Embedded
+		Nested  inner    `json:"nested"`
+		Amount  *big.Int `json:"amount"`
+		Skipped string   `json:"-"`
+		Plain   string
+		hidden  string
+	}
+
+	amount := new(big.Int).Lsh(big.NewInt(1), 200)
+	owner := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	value := outer{
+		Embedded: Embedded{Owner: owner},
+		Nested:   inner{Value: big.NewInt(7)},
+		Amount:   amount,
+		Skipped:  "skip",
+		Plain:    "plain",
+		hidden:   "hidden",
+	}
+	var nilDomain *EIP712Domain
+
+	tests := []struct {
+		name      string
+		input     interface{}
+		shouldErr bool
+		want      map[string]interface{}
+	}{
+		{
+			name:  "pointer to struct",
+			input: &EIP712Domain{Name: "Test", Version: "1"},
+			want:  map[string]interface{}{"name": "Test", "version": "1"},
+		},
+		{
+			name:  "pointer to pointer to map",
+			input: func() interface{} { m := map[string]interface{}{"a": 1}; p := &m; return &p }(),
+			want:  map[string]interface{}{"a": 1},
+		},
+		{
+			name:  "typed map",
+			input: map[string]string{"a": "b"},
+			want:  map[string]interface{}{"a": "b"},
+		},
+		{
+			name:  "nested and embedded struct",
+			input: value,
+			want: map[string]interface{}{
+				"owner":  owner,
+				"nested": inner{Value: big.NewInt(7)},
+				"amount": amount,
+				"Plain":  "plain",
+			},
+		},
+		{
+			name:      "non-string map keys",
+			input:     map[int]string{1: "a"},
+			shouldErr: true,
+		},
+		{
+			name:      "non-struct",
+			input:     42,
+			shouldErr: true,
+		},
+		{
+			name:      "slice",
+			input:     []string{"a"},
+			shouldErr: true,
+		},
+		{
+			name:      "nil pointer",
+			input:     nilDomain,
+			shouldErr: true,
+		},
+		{
+			name:      "nil",
+			input:     nil,
+			shouldErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := toMap(tt.input)
+			if tt.shouldErr {
+				if err == nil {
+					t.Fatalf("Expected error, got %v", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("toMap failed: %v", err)
+			}
+			if !reflect.DeepEqual(result, tt.want) {
+				t.Errorf("Expected %#v, got %#v", tt.want, result)
+			}
+		})
+	}
+}
+
+func TestToMap_PreservesBigInt(t *testing.T) {
+	type message struct {
+		Amount *big.Int `json:"amount"`
+	}
+	// 2^64 + 1 cannot be represented exactly as a float64
+	amount := new(big.Int).Add(new(big.Int).Lsh(big.NewInt(1), 64), big.NewInt(1))
+
+	result, err := toMap(&message{Amount: amount})
+	if err != nil {
+		t.Fatalf("toMap failed: %v", err)
+	}
+	got, ok := result["amount"].(*big.Int)
+	if !ok {
+		t.Fatalf("Expected *big.Int, got %T", result["amount"])
+	}
+	if got.Cmp(amount) != 0 {
+		t.Errorf("Expected %s, got %s", amount, got)
+	}
+}
+
+func TestHashTypedData_InvalidMessage(t *testing.T) {
+	typedData := &TypedData{
+		Types: map[string][]EIP712Type{
+			"EIP712Domain": {{Name: "name", Type: "string"}},
+			"Message":      {{Name: "inner", Type: "Inner"}},
+			"Inner":        {{Name: "value", Type: "uint256"}},
+		},
+		PrimaryType: "Message",
+		Domain:      EIP712Domain{Name: "Test"},
+		Message:     map[string]interface{}{"inner": map[int]string{1: "a"}},
+	}
+
+	if _, err := HashTypedData(typedData); err == nil {
+		t.Error("Expected error for nested struct with non-string keys")
+	}
+}
+
 func TestHashDomain(t *testing.T) {
 	types := map[string][]EIP712Type{
 		"EIP712Domain": {
@@ -0,0 +1,147 @@
+package signer
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+// ownerSignature is one owner's part of a packed Safe signature
+type ownerSignature struct {
+	owner common.Address
+	// static is the 65-byte r ‖ s ‖ v; for a contract signature s is the
+	// offset of dynamic, filled in once the order is known
+	static  []byte
+	dynamic []byte
+}
+
+// PackSignaturesForSafe packs the signatures of several owners over dataHash
+// the way the Safe contract's checkSignatures requires: ordered by owner
+// address ascending, each owner once. ECDSA signatures are mapped to scheme's
+// v range and recovered, and each must recover to one of the signers named
+// in sigs. Contract (EIP-1271) signatures, with SignatureTypeContract, carry
+// their owner in Signer and the bytes its isValidSignature checks in Data;
+// their static part is sorted with the rest and the data is appended after
+// all static parts. The result does not depend on the order of sigs.
+// PackSignatures concatenates signatures as given.
+func PackSignaturesForSafe(dataHash common.Hash, sigs []models.Signature, scheme SignatureScheme) (string, error) {
+	if len(sigs) == 0 {
+		return "", errors.NewRelayerClientError("no signatures provided", nil)
+	}
+	if err := scheme.Validate(); err != nil {
+		return "", err
+	}
+
+	hints := make(map[common.Address]bool, len(sigs))
+	for _, sig := range sigs {
+		if sig.Signer == "" {
+			continue
+		}
+		if !common.IsHexAddress(sig.Signer) {
+			return "", errors.ErrInvalidAddress(sig.Signer)
+		}
+		hints[common.HexToAddress(sig.Signer)] = true
+	}
+
+	owners := make([]ownerSignature, 0, len(sigs))
+	seen := make(map[common.Address]bool, len(sigs))
+	for i, sig := range sigs {
+		owner, err := ownerSignatureFor(dataHash, sig, scheme, hints)
+		if err != nil {
+			return "", errors.NewRelayerClientError(fmt.Sprintf("signature %d", i), err)
+		}
+		if seen[owner.owner] {
+			return "", errors.ErrInvalidSignature(fmt.Errorf("more than one signature by %s", owner.owner.Hex()))
+		}
+		seen[owner.owner] = true
+		owners = append(owners, owner)
+	}
+
+	sort.Slice(owners, func(i, j int) bool {
+		return bytes.Compare(owners[i].owner.Bytes(), owners[j].owner.Bytes()) < 0
+	})
+
+	// Dynamic parts start after the static parts, in the owners' order
+	offset := 65 * len(owners)
+	packed := make([]byte, 0, offset)
+	var dynamic []byte
+	for _, owner := range owners {
+		if owner.dynamic != nil {
+			copy(owner.static[32:64], math.U256Bytes(big.NewInt(int64(offset+len(dynamic)))))
+			dynamic = append(dynamic, owner.dynamic...)
+		}
+		packed = append(packed, owner.static...)
+	}
+	return hexutil.Encode(append(packed, dynamic...)), nil
+}
+
+// ownerSignatureFor returns the static and dynamic parts of sig. An ECDSA
+// signature must recover to one of hints.
+func ownerSignatureFor(dataHash common.Hash, sig models.Signature, scheme SignatureScheme, hints map[common.Address]bool) (ownerSignature, error) {
+	if sig.SignatureType == models.SignatureTypeContract {
+		return contractSignature(sig)
+	}
+	if sig.SignatureType != "" && sig.SignatureType != scheme.SignatureType() {
+		return ownerSignature{}, errors.ErrInvalidSignature(fmt.Errorf("signature type %s does not match scheme %s", sig.SignatureType, scheme.OrDefault()))
+	}
+
+	signatureHex := sig.Data
+	if signatureHex == "" && sig.Split != nil {
+		var err error
+		if signatureHex, err = packSignature(sig.Split.R, sig.Split.S, sig.Split.V); err != nil {
+			return ownerSignature{}, err
+		}
+	}
+	packedHex, err := scheme.PackSignature(signatureHex)
+	if err != nil {
+		return ownerSignature{}, err
+	}
+	packed := hexutil.MustDecode(packedHex)
+
+	recovered, err := RecoverSafeSignature(dataHash.Bytes(), packed)
+	if err != nil {
+		return ownerSignature{}, err
+	}
+	if !hints[recovered] {
+		return ownerSignature{}, errors.ErrSignatureMismatch(signerHints(hints), recovered.Hex())
+	}
+	return ownerSignature{owner: recovered, static: packed}, nil
+}
+
+// contractSignature returns the parts of an EIP-1271 signature: r is the
+// owner contract, v is 0 and the dynamic part is the data, prefixed with its
+// length as a uint256 and not padded, as the Safe reference encoder does
+func contractSignature(sig models.Signature) (ownerSignature, error) {
+	if !common.IsHexAddress(sig.Signer) {
+		return ownerSignature{}, errors.ErrInvalidAddress(sig.Signer)
+	}
+	data, err := hexutil.Decode("0x" + strings.TrimPrefix(sig.Data, "0x"))
+	if err != nil {
+		return ownerSignature{}, errors.ErrInvalidSignature(err)
+	}
+
+	owner := common.HexToAddress(sig.Signer)
+	static := make([]byte, 65)
+	copy(static[0:32], common.LeftPadBytes(owner.Bytes(), 32))
+	dynamic := append(math.U256Bytes(big.NewInt(int64(len(data)))), data...)
+	return ownerSignature{owner: owner, static: static, dynamic: dynamic}, nil
+}
+
+// signerHints lists hints sorted, for error messages
+func signerHints(hints map[common.Address]bool) string {
+	list := make([]string, 0, len(hints))
+	for hint := range hints {
+		list = append(list, hint.Hex())
+	}
+	sort.Strings(list)
+	return strings.Join(list, ", ")
+}
@@ -0,0 +1,202 @@
+package signer
+
+import (
+	"bytes"
+	stderrors "errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+// multisigKeys are three owners whose addresses sort differently from the keys
+var multisigKeys = []string{
+	"ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80",
+	"59c6995e998f97a5a0044966f0945389dc9e86dae88c7a8412f4603b6b78690d",
+	"5de4111afa1a4b94908f83103eb1f1706367c2e68ca870fc3fb9a804cdab365a",
+}
+
+// ownerSignatures signs dataHash with each of multisigKeys under scheme
+func ownerSignatures(t *testing.T, dataHash common.Hash, scheme SignatureScheme) []models.Signature {
+	t.Helper()
+	sigs := make([]models.Signature, len(multisigKeys))
+	for i, key := range multisigKeys {
+		s, err := NewSigner(key, 137)
+		if err != nil {
+			t.Fatalf("NewSigner failed: %v", err)
+		}
+		signature, err := s.SignSafeTxHash(dataHash.Bytes(), scheme)
+		if err != nil {
+			t.Fatalf("SignSafeTxHash failed: %v", err)
+		}
+		sigs[i] = models.Signature{Signer: s.AddressHex(), Data: signature}
+	}
+	return sigs
+}
+
+// permutations returns every ordering of sigs
+func permutations(sigs []models.Signature) [][]models.Signature {
+	if len(sigs) <= 1 {
+		return [][]models.Signature{append([]models.Signature(nil), sigs...)}
+	}
+	var all [][]models.Signature
+	for i := range sigs {
+		rest := append(append([]models.Signature(nil), sigs[:i]...), sigs[i+1:]...)
+		for _, p := range permutations(rest) {
+			all = append(all, append([]models.Signature{sigs[i]}, p...))
+		}
+	}
+	return all
+}
+
+func TestPackSignaturesForSafe_Order(t *testing.T) {
+	dataHash := Keccak256Hash([]byte("safe tx"))
+
+	for _, scheme := range []SignatureScheme{SchemeEthSign, SchemeEIP712} {
+		t.Run(string(scheme), func(t *testing.T) {
+			sigs := ownerSignatures(t, dataHash, scheme)
+
+			var first string
+			for i, order := range permutations(sigs) {
+				packed, err := PackSignaturesForSafe(dataHash, order, scheme)
+				if err != nil {
+					t.Fatalf("order %d: PackSignaturesForSafe failed: %v", i, err)
+				}
+				if i == 0 {
+					first = packed
+				} else if packed != first {
+					t.Fatalf("order %d packed %s, want %s", i, packed, first)
+				}
+			}
+
+			raw := hexutil.MustDecode(first)
+			if len(raw) != 65*len(sigs) {
+				t.Fatalf("packed %d bytes, want %d", len(raw), 65*len(sigs))
+			}
+			var previous common.Address
+			for i := 0; i < len(sigs); i++ {
+				owner, err := RecoverSafeSignature(dataHash.Bytes(), raw[65*i:65*(i+1)])
+				if err != nil {
+					t.Fatalf("signature %d does not recover: %v", i, err)
+				}
+				if i > 0 && bytes.Compare(previous.Bytes(), owner.Bytes()) >= 0 {
+					t.Errorf("signature %d by %s is not after %s", i, owner.Hex(), previous.Hex())
+				}
+				previous = owner
+			}
+		})
+	}
+}
+
+func TestPackSignaturesForSafe_Invalid(t *testing.T) {
+	dataHash := Keccak256Hash([]byte("safe tx"))
+	sigs := ownerSignatures(t, dataHash, SchemeEthSign)
+	otherHash := ownerSignatures(t, Keccak256Hash([]byte("other tx")), SchemeEthSign)
+
+	wrongType := append([]models.Signature(nil), sigs...)
+	wrongType[0].SignatureType = models.SignatureTypeEIP712
+
+	tests := []struct {
+		name         string
+		sigs         []models.Signature
+		scheme       SignatureScheme
+		wantMismatch bool
+	}{
+		{name: "none"},
+		{name: "duplicate owner", sigs: []models.Signature{sigs[0], sigs[1], sigs[0]}},
+		{name: "ECDSA and contract signature by one owner", sigs: []models.Signature{sigs[0], {Signer: sigs[0].Signer, SignatureType: models.SignatureTypeContract}}},
+		{name: "signed another hash", sigs: []models.Signature{sigs[0], otherHash[1]}, wantMismatch: true},
+		{name: "signer not among the hints", sigs: []models.Signature{sigs[0], {Data: sigs[1].Data}}, wantMismatch: true},
+		{name: "invalid signature", sigs: []models.Signature{sigs[0], {Signer: sigs[1].Signer, Data: "0x1234"}}},
+		{name: "invalid hint", sigs: []models.Signature{sigs[0], {Signer: "owner", Data: sigs[1].Data}}},
+		{name: "type does not match the scheme", sigs: wrongType},
+		{name: "unknown scheme", sigs: sigs, scheme: "personal_sign"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := PackSignaturesForSafe(dataHash, tt.sigs, tt.scheme)
+			if err == nil {
+				t.Fatal("Expected error")
+			}
+			var mismatch *errors.SignatureMismatchError
+			if stderrors.As(err, &mismatch) != tt.wantMismatch {
+				t.Errorf("error = %v, want a SignatureMismatchError: %v", err, tt.wantMismatch)
+			}
+		})
+	}
+
+}
+
+func TestPackSignaturesForSafe_ContractSignatures(t *testing.T) {
+	dataHash := Keccak256Hash([]byte("safe tx"))
+	sigs := ownerSignatures(t, dataHash, SchemeEthSign)
+
+	// Owner contracts sorting first, between the ECDSA owners and last
+	low := models.Signature{Signer: "0x0000000000000000000000000000000000000001", Data: "0xaabbcc", SignatureType: models.SignatureTypeContract}
+	mid := models.Signature{Signer: "0x8000000000000000000000000000000000000000", Data: "0x", SignatureType: models.SignatureTypeContract}
+	all := append(append([]models.Signature(nil), sigs...), mid, low)
+
+	var first string
+	for i, order := range permutations(all) {
+		packed, err := PackSignaturesForSafe(dataHash, order, SchemeEthSign)
+		if err != nil {
+			t.Fatalf("order %d: PackSignaturesForSafe failed: %v", i, err)
+		}
+		if i == 0 {
+			first = packed
+		} else if packed != first {
+			t.Fatalf("order %d packed %s, want %s", i, packed, first)
+		}
+	}
+
+	raw := hexutil.MustDecode(first)
+	staticLen := 65 * len(all)
+	// Two dynamic parts: 32-byte length + 3 bytes, then 32-byte length + 0 bytes
+	if want := staticLen + 32 + 3 + 32; len(raw) != want {
+		t.Fatalf("packed %d bytes, want %d", len(raw), want)
+	}
+
+	var previous common.Address
+	var contracts int
+	for i := 0; i < len(all); i++ {
+		static := raw[65*i : 65*(i+1)]
+		var owner common.Address
+		if static[64] == 0 {
+			owner = common.BytesToAddress(static[12:32])
+			offset := new(big.Int).SetBytes(static[32:64]).Int64()
+			length := new(big.Int).SetBytes(raw[offset : offset+32]).Int64()
+			data := raw[offset+32 : offset+32+length]
+
+			switch owner.Hex() {
+			case low.Signer:
+				if offset != int64(staticLen) || !bytes.Equal(data, []byte{0xaa, 0xbb, 0xcc}) {
+					t.Errorf("low contract: offset %d data %x, want %d and aabbcc", offset, data, staticLen)
+				}
+			case mid.Signer:
+				if offset != int64(staticLen+32+3) || len(data) != 0 {
+					t.Errorf("mid contract: offset %d data %x, want %d and none", offset, data, staticLen+32+3)
+				}
+			default:
+				t.Errorf("unexpected contract owner %s", owner.Hex())
+			}
+			contracts++
+		} else {
+			var err error
+			if owner, err = RecoverSafeSignature(dataHash.Bytes(), static); err != nil {
+				t.Fatalf("signature %d does not recover: %v", i, err)
+			}
+		}
+		if i > 0 && bytes.Compare(previous.Bytes(), owner.Bytes()) >= 0 {
+			t.Errorf("signature %d by %s is not after %s", i, owner.Hex(), previous.Hex())
+		}
+		previous = owner
+	}
+	if contracts != 2 {
+		t.Errorf("found %d contract signatures, want 2", contracts)
+	}
+}
@@ -0,0 +1,305 @@
+package signer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/davidt58/go-builder-relayer-client/clock"
+	"github.com/davidt58/go-builder-relayer-client/errors"
+)
+
+const (
+	// DefaultRemoteSignerTimeout bounds each JSON-RPC request of a remote signer
+	DefaultRemoteSignerTimeout = 10 * time.Second
+	// DefaultRemoteSignerRetries is how often a remote signer retries a
+	// request that failed transiently
+	DefaultRemoteSignerRetries = 2
+
+	// remoteRetryDelay is the delay before the first retry; it doubles with
+	// every further retry
+	remoteRetryDelay = 200 * time.Millisecond
+	// maxRemoteResponseSize caps JSON-RPC response bodies
+	maxRemoteResponseSize = 1 << 20
+)
+
+// RemoteSignerOption configures a remote signer
+type RemoteSignerOption func(*remoteBackend)
+
+// WithRemoteTimeout bounds each JSON-RPC request (default
+// DefaultRemoteSignerTimeout). The timeout must be positive.
+func WithRemoteTimeout(timeout time.Duration) RemoteSignerOption {
+	return func(b *remoteBackend) {
+		if timeout <= 0 {
+			b.setErr(errors.ErrInvalidConfiguration("remote signer timeout must be positive"))
+			return
+		}
+		b.httpClient.Timeout = timeout
+	}
+}
+
+// WithRemoteContext bounds every JSON-RPC request and retry backoff of the
+// remote signer by ctx: once it is done, signing fails with its error
+func WithRemoteContext(ctx context.Context) RemoteSignerOption {
+	return func(b *remoteBackend) {
+		if ctx == nil {
+			b.setErr(errors.ErrMissingRequiredField("context"))
+			return
+		}
+		b.ctx = ctx
+	}
+}
+
+// WithRemoteClock sets the clock the retry backoff waits on (default clock.Real)
+func WithRemoteClock(c clock.Clock) RemoteSignerOption {
+	return func(b *remoteBackend) {
+		b.clock = clock.OrReal(c)
+	}
+}
+
+// WithRemoteRetries sets how often a request that failed transiently, with
+// a network error, a 429 or a 5xx status, is retried (default
+// DefaultRemoteSignerRetries). JSON-RPC errors are not retried.
+func WithRemoteRetries(retries int) RemoteSignerOption {
+	return func(b *remoteBackend) {
+		b.retries = retries
+	}
+}
+
+// WithRemoteHeaders adds headers, such as an Authorization header, to every
+// JSON-RPC request
+func WithRemoteHeaders(headers map[string]string) RemoteSignerOption {
+	return func(b *remoteBackend) {
+		for name, value := range headers {
+			b.headers.Set(name, value)
+		}
+	}
+}
+
+// NewRemoteSigner creates a Signer for address that delegates signing to a
+// JSON-RPC signing service at rpcURL instead of holding a key. EIP-191
+// signatures are requested with personal_sign and signatures of EIP-712
+// typed data with eth_signTypedData_v4; bare digests cannot be signed
+// remotely. Every returned signature must recover to address, or signing
+// fails with a SignatureMismatchError.
+func NewRemoteSigner(rpcURL string, address common.Address, chainID int64, opts ...RemoteSignerOption) (*Signer, error) {
+	parsed, err := url.Parse(rpcURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, errors.ErrInvalidConfiguration(fmt.Sprintf("remote signer URL %q must be an http or https URL", rpcURL))
+	}
+	if address == (common.Address{}) {
+		return nil, errors.ErrMissingRequiredField("address")
+	}
+
+	backend := &remoteBackend{
+		rpcURL:     rpcURL,
+		address:    address,
+		httpClient: &http.Client{Timeout: DefaultRemoteSignerTimeout},
+		retries:    DefaultRemoteSignerRetries,
+		headers:    make(http.Header),
+		ctx:        context.Background(),
+		clock:      clock.Real,
+	}
+	for _, opt := range opts {
+		opt(backend)
+	}
+	if backend.err != nil {
+		return nil, backend.err
+	}
+
+	return NewSignerWithBackend(backend, address, chainID), nil
+}
+
+// remoteBackend signs through a JSON-RPC signing service
+type remoteBackend struct {
+	rpcURL     string
+	address    common.Address
+	httpClient *http.Client
+	retries    int
+	headers    http.Header
+	ctx        context.Context
+	clock      clock.Clock
+	// lastID is the ID of the last JSON-RPC request
+	lastID int64
+	// err is the first error an option reported
+	err error
+}
+
+// setErr records the first option error
+func (b *remoteBackend) setErr(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
+
+// errRemoteRawDigest is returned when a bare digest is to be signed remotely
+var errRemoteRawDigest = fmt.Errorf("remote signer cannot sign a bare digest; sign the typed data instead")
+
+// SignHash implements SignerBackend. JSON-RPC signers only sign prefixed
+// messages and typed data, so it always fails.
+func (b *remoteBackend) SignHash(hash []byte) ([]byte, error) {
+	return nil, errRemoteRawDigest
+}
+
+// SignPersonal implements SignerBackend with personal_sign
+func (b *remoteBackend) SignPersonal(message []byte) ([]byte, error) {
+	signature, err := b.sign("personal_sign", hexutil.Encode(message), b.address.Hex())
+	if err != nil {
+		return nil, err
+	}
+	return signature, b.verify(ethSignHash(message), signature)
+}
+
+// SignTypedData implements SignerBackend with eth_signTypedData_v4
+func (b *remoteBackend) SignTypedData(typedData *TypedData, digest []byte) ([]byte, error) {
+	// Remote signers take the domain's fields from its type, so send the
+	// default one HashTypedData assumes when it is not declared
+	payload := *typedData
+	payload.Types = withDomainType(typedData.Types)
+	encoded, err := json.Marshal(&payload)
+	if err != nil {
+		return nil, errors.ErrJSONMarshalFailed(err)
+	}
+
+	signature, err := b.sign("eth_signTypedData_v4", b.address.Hex(), string(encoded))
+	if err != nil {
+		return nil, err
+	}
+	return signature, b.verify(digest, signature)
+}
+
+// verify checks that signature over hash recovers to the backend's address
+func (b *remoteBackend) verify(hash, signature []byte) error {
+	recovered, err := RecoverAddress(hash, signature)
+	if err != nil {
+		return err
+	}
+	if recovered != b.address {
+		return errors.ErrSignatureMismatch(b.address.Hex(), recovered.Hex())
+	}
+	return nil
+}
+
+// rpcRequest is a JSON-RPC 2.0 request
+type rpcRequest struct {
+	JSONRPC string        `json:"jsonrpc"`
+	ID      int64         `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+// rpcResponse is a JSON-RPC 2.0 response
+type rpcResponse struct {
+	ID     *int64          `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *rpcError       `json:"error"`
+}
+
+// rpcError is the error of a JSON-RPC 2.0 response
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// sign calls a JSON-RPC signing method and decodes the 65-byte signature it returns
+func (b *remoteBackend) sign(method string, params ...interface{}) ([]byte, error) {
+	result, err := b.call(method, params...)
+	if err != nil {
+		return nil, err
+	}
+
+	var signatureHex string
+	if err := json.Unmarshal(result, &signatureHex); err != nil {
+		return nil, errors.ErrInvalidResponse(fmt.Sprintf("%s result is not a hex string", method))
+	}
+	signature, err := decodeSignature(signatureHex)
+	if err != nil {
+		return nil, err
+	}
+	return signature, nil
+}
+
+// call performs a JSON-RPC call, retrying transient failures with backoff
+// until the backend's context is done
+func (b *remoteBackend) call(method string, params ...interface{}) (json.RawMessage, error) {
+	id := atomic.AddInt64(&b.lastID, 1)
+	body, err := json.Marshal(rpcRequest{
+		JSONRPC: "2.0",
+		ID:      id,
+		Method:  method,
+		Params:  params,
+	})
+	if err != nil {
+		return nil, errors.ErrJSONMarshalFailed(err)
+	}
+
+	delay := remoteRetryDelay
+	for attempt := 0; ; attempt++ {
+		result, retry, err := b.post(method, id, body)
+		if err == nil || !retry || attempt >= b.retries {
+			return result, err
+		}
+		select {
+		case <-b.ctx.Done():
+			return nil, errors.ErrHTTPRequestFailed(fmt.Errorf("%s: %w", method, b.ctx.Err()))
+		case <-b.clock.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// post sends one JSON-RPC request with the given id and reports whether a
+// failure is transient
+func (b *remoteBackend) post(method string, id int64, body []byte) (result json.RawMessage, retry bool, err error) {
+	req, err := http.NewRequestWithContext(b.ctx, http.MethodPost, b.rpcURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, false, errors.ErrHTTPRequestFailed(err)
+	}
+	for name, values := range b.headers {
+		req.Header[name] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := b.httpClient.Do(req)
+	if err != nil {
+		// A request cut short by the context is not retried
+		return nil, b.ctx.Err() == nil, errors.ErrHTTPRequestFailed(err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, maxRemoteResponseSize))
+	if err != nil {
+		return nil, true, errors.ErrHTTPRequestFailed(err)
+	}
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		return nil, true, errors.ErrHTTPRequestFailed(fmt.Errorf("%s: remote signer returned status %d", method, resp.StatusCode))
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, false, errors.ErrHTTPRequestFailed(fmt.Errorf("%s: remote signer returned status %d", method, resp.StatusCode))
+	}
+
+	var rpcResp rpcResponse
+	if err := json.Unmarshal(respBody, &rpcResp); err != nil {
+		return nil, false, errors.ErrJSONUnmarshalFailed(err)
+	}
+	// An error the service could not attribute to a request has a null id
+	if (rpcResp.ID == nil && rpcResp.Error == nil) || (rpcResp.ID != nil && *rpcResp.ID != id) {
+		return nil, false, errors.ErrInvalidResponse(fmt.Sprintf("%s: response does not answer request %d", method, id))
+	}
+	if rpcResp.Error != nil {
+		return nil, false, errors.NewRelayerClientError(fmt.Sprintf("%s: remote signer error %d: %s", method, rpcResp.Error.Code, rpcResp.Error.Message), nil)
+	}
+	if len(rpcResp.Result) == 0 {
+		return nil, false, errors.ErrInvalidResponse(fmt.Sprintf("%s returned no result", method))
+	}
+	return rpcResp.Result, false, nil
+}
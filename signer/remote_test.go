@@ -0,0 +1,276 @@
+package signer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	stderrors "errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/davidt58/go-builder-relayer-client/clock"
+	"github.com/davidt58/go-builder-relayer-client/errors"
+)
+
+// rpcStub is a JSON-RPC signing service that signs with key
+type rpcStub struct {
+	key *ecdsa.PrivateKey
+	// failures is how many requests fail with 503 before one is served
+	failures int32
+	// idOffset is added to the id of every response
+	idOffset int64
+	calls    map[string]*int32
+}
+
+func newRPCStub(t *testing.T, keyHex string) (*rpcStub, *httptest.Server) {
+	key, err := crypto.HexToECDSA(keyHex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stub := &rpcStub{key: key, calls: map[string]*int32{"personal_sign": new(int32), "eth_signTypedData_v4": new(int32)}}
+	server := httptest.NewServer(http.HandlerFunc(stub.serve))
+	t.Cleanup(server.Close)
+	return stub, server
+}
+
+func (s *rpcStub) serve(w http.ResponseWriter, r *http.Request) {
+	if atomic.AddInt32(&s.failures, -1) >= 0 {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	var req struct {
+		ID     int64             `json:"id"`
+		Method string            `json:"method"`
+		Params []json.RawMessage `json:"params"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.Params) != 2 {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	if counter, ok := s.calls[req.Method]; ok {
+		atomic.AddInt32(counter, 1)
+	}
+
+	var hash []byte
+	switch req.Method {
+	case "personal_sign":
+		var messageHex string
+		_ = json.Unmarshal(req.Params[0], &messageHex)
+		hash = ethSignHash(common.FromHex(messageHex))
+	case "eth_signTypedData_v4":
+		var encoded string
+		_ = json.Unmarshal(req.Params[1], &encoded)
+		var typedData TypedData
+		if err := json.Unmarshal([]byte(encoded), &typedData); err != nil {
+			http.Error(w, "bad typed data", http.StatusBadRequest)
+			return
+		}
+		digest, err := HashTypedData(&typedData)
+		if err != nil {
+			http.Error(w, "bad typed data", http.StatusBadRequest)
+			return
+		}
+		hash = digest.Bytes()
+	default:
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"jsonrpc": "2.0", "id": req.ID, "error": map[string]interface{}{"code": -32601, "message": "method not found"},
+		})
+		return
+	}
+
+	signature, _ := crypto.Sign(hash, s.key)
+	signature[64] += 27
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID + s.idOffset, "result": hexutil.Encode(signature)})
+}
+
+// testTypedData is a SafeTx typed data as the builder produces it
+func testTypedData() *TypedData {
+	return &TypedData{
+		Types: map[string][]EIP712Type{
+			"EIP712Domain": {
+				{Name: "chainId", Type: "uint256"},
+				{Name: "verifyingContract", Type: "address"},
+			},
+			"SafeTx": {
+				{Name: "to", Type: "address"},
+				{Name: "value", Type: "uint256"},
+				{Name: "data", Type: "bytes"},
+				{Name: "operation", Type: "uint8"},
+				{Name: "nonce", Type: "uint256"},
+			},
+		},
+		PrimaryType: "SafeTx",
+		Domain: EIP712Domain{
+			ChainId:           big.NewInt(137),
+			VerifyingContract: common.HexToAddress("0x1111111111111111111111111111111111111111"),
+		},
+		Message: map[string]interface{}{
+			"to":        "0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174",
+			"value":     "0",
+			"data":      "0xa9059cbb",
+			"operation": 0,
+			"nonce":     "7",
+		},
+	}
+}
+
+func TestRemoteSigner(t *testing.T) {
+	local, err := NewSigner(testPrivateKey, 137)
+	if err != nil {
+		t.Fatalf("NewSigner failed: %v", err)
+	}
+	stub, server := newRPCStub(t, testPrivateKey)
+	remote, err := NewRemoteSigner(server.URL, common.HexToAddress(testAddress), 137)
+	if err != nil {
+		t.Fatalf("NewRemoteSigner failed: %v", err)
+	}
+	hash := crypto.Keccak256([]byte("safe tx"))
+
+	// personal_sign
+	want, _ := local.SignEIP712StructHash(hash)
+	got, err := remote.SignEIP712StructHash(hash)
+	if err != nil {
+		t.Fatalf("SignEIP712StructHash failed: %v", err)
+	}
+	if got != want {
+		t.Errorf("SignEIP712StructHash = %s, want %s", got, want)
+	}
+
+	// eth_signTypedData_v4, for the EIP-712 scheme
+	want, _ = local.SignSafeTx(testTypedData(), SchemeEIP712)
+	got, err = remote.SignSafeTx(testTypedData(), SchemeEIP712)
+	if err != nil {
+		t.Fatalf("SignSafeTx failed: %v", err)
+	}
+	if got != want {
+		t.Errorf("SignSafeTx = %s, want %s", got, want)
+	}
+
+	if n := atomic.LoadInt32(stub.calls["personal_sign"]); n != 1 {
+		t.Errorf("personal_sign called %d times, want 1", n)
+	}
+	if n := atomic.LoadInt32(stub.calls["eth_signTypedData_v4"]); n != 1 {
+		t.Errorf("eth_signTypedData_v4 called %d times, want 1", n)
+	}
+
+	// A bare digest can only be signed locally
+	if _, err := remote.Sign(hash); err == nil {
+		t.Error("Sign of a bare digest succeeded remotely")
+	}
+}
+
+func TestRemoteSigner_RecoveryMismatch(t *testing.T) {
+	// The service signs with another key than the configured address
+	_, server := newRPCStub(t, "59c6995e998f97a5a0044966f0945389dc9e86dae88c7a8412f4603b6b78690d")
+	remote, err := NewRemoteSigner(server.URL, common.HexToAddress(testAddress), 137)
+	if err != nil {
+		t.Fatalf("NewRemoteSigner failed: %v", err)
+	}
+
+	for name, sign := range map[string]func() (string, error){
+		"personal_sign":        func() (string, error) { return remote.SignMessage([]byte("hello")) },
+		"eth_signTypedData_v4": func() (string, error) { return remote.SignTypedData(testTypedData()) },
+	} {
+		_, err := sign()
+		var mismatch *errors.SignatureMismatchError
+		if !stderrors.As(err, &mismatch) {
+			t.Fatalf("%s: err = %v, want a SignatureMismatchError", name, err)
+		}
+		if mismatch.Expected != testAddress {
+			t.Errorf("%s: expected address = %s, want %s", name, mismatch.Expected, testAddress)
+		}
+	}
+}
+
+func TestRemoteSigner_Retries(t *testing.T) {
+	stub, server := newRPCStub(t, testPrivateKey)
+	fake := clock.NewFake(time.Unix(0, 0))
+	remote, err := NewRemoteSigner(server.URL, common.HexToAddress(testAddress), 137, WithRemoteClock(fake))
+	if err != nil {
+		t.Fatalf("NewRemoteSigner failed: %v", err)
+	}
+
+	// signAsync signs in the background, advancing the fake clock through
+	// the given number of backoff waits
+	signAsync := func(remote *Signer, waits ...time.Duration) error {
+		done := make(chan error, 1)
+		go func() {
+			_, err := remote.SignMessage([]byte("hello"))
+			done <- err
+		}()
+		for _, wait := range waits {
+			fake.BlockUntil(1)
+			fake.Advance(wait)
+		}
+		return <-done
+	}
+
+	stub.failures = 1
+	if err := signAsync(remote, remoteRetryDelay); err != nil {
+		t.Fatalf("SignMessage after a transient failure: %v", err)
+	}
+
+	stub.failures = 2
+	remote, _ = NewRemoteSigner(server.URL, common.HexToAddress(testAddress), 137, WithRemoteRetries(1), WithRemoteClock(fake))
+	if err := signAsync(remote, remoteRetryDelay); err == nil {
+		t.Error("SignMessage succeeded although every attempt failed")
+	}
+}
+
+func TestRemoteSigner_ContextCancelsBackoff(t *testing.T) {
+	stub, server := newRPCStub(t, testPrivateKey)
+	stub.failures = 1
+	fake := clock.NewFake(time.Unix(0, 0))
+	ctx, cancel := context.WithCancel(context.Background())
+	remote, err := NewRemoteSigner(server.URL, common.HexToAddress(testAddress), 137, WithRemoteClock(fake), WithRemoteContext(ctx))
+	if err != nil {
+		t.Fatalf("NewRemoteSigner failed: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := remote.SignMessage([]byte("hello"))
+		done <- err
+	}()
+	fake.BlockUntil(1)
+	cancel()
+
+	if err := <-done; !stderrors.Is(err, context.Canceled) {
+		t.Errorf("SignMessage after cancel = %v, want context.Canceled", err)
+	}
+}
+
+func TestRemoteSigner_ResponseIDMismatch(t *testing.T) {
+	stub, server := newRPCStub(t, testPrivateKey)
+	stub.idOffset = 1
+	remote, err := NewRemoteSigner(server.URL, common.HexToAddress(testAddress), 137)
+	if err != nil {
+		t.Fatalf("NewRemoteSigner failed: %v", err)
+	}
+	if _, err := remote.SignMessage([]byte("hello")); err == nil {
+		t.Error("SignMessage accepted a response to another request")
+	}
+}
+
+func TestNewRemoteSigner_Invalid(t *testing.T) {
+	if _, err := NewRemoteSigner("localhost:8545", common.HexToAddress(testAddress), 137); err == nil {
+		t.Error("NewRemoteSigner accepted a URL without scheme")
+	}
+	if _, err := NewRemoteSigner("http://localhost:8545", common.Address{}, 137); err == nil {
+		t.Error("NewRemoteSigner accepted the zero address")
+	}
+	for _, timeout := range []time.Duration{0, -time.Second} {
+		if _, err := NewRemoteSigner("http://localhost:8545", common.HexToAddress(testAddress), 137, WithRemoteTimeout(timeout)); err == nil {
+			t.Errorf("NewRemoteSigner accepted timeout %v", timeout)
+		}
+	}
+}
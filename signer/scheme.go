@@ -0,0 +1,115 @@
+package signer
+
+import (
+	"fmt"
+
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+// SignatureScheme selects how a SafeTx hash is signed and which v range the
+// packed signature uses. Hashing and v encoding are always paired: Safe's
+// checkSignatures applies the EIP-191 prefix itself for v 31/32 and recovers
+// against the bare hash for v 27/28.
+type SignatureScheme string
+
+const (
+	// SchemeEthSign signs keccak256("\x19Ethereum Signed Message:\n32" ‖ hash)
+	// and encodes v as 31/32. This is what the Polymarket relayer expects.
+	SchemeEthSign SignatureScheme = "eth_sign"
+	// SchemeEIP712 signs the SafeTx hash directly and encodes v as 27/28
+	SchemeEIP712 SignatureScheme = "eip712"
+
+	// DefaultSignatureScheme is used when no scheme is configured
+	DefaultSignatureScheme = SchemeEthSign
+)
+
+// OrDefault returns the scheme, or DefaultSignatureScheme if it is unset
+func (s SignatureScheme) OrDefault() SignatureScheme {
+	if s == "" {
+		return DefaultSignatureScheme
+	}
+	return s
+}
+
+// Validate returns an error if the scheme is not a known scheme
+func (s SignatureScheme) Validate() error {
+	switch s.OrDefault() {
+	case SchemeEthSign, SchemeEIP712:
+		return nil
+	default:
+		return errors.ErrInvalidConfiguration(fmt.Sprintf("unknown signature scheme %q", string(s)))
+	}
+}
+
+// SignatureType returns the signature type declared for signatures packed
+// under the scheme, or "" for an unknown scheme
+func (s SignatureScheme) SignatureType() models.SignatureType {
+	switch s.OrDefault() {
+	case SchemeEthSign:
+		return models.SignatureTypeEthSign
+	case SchemeEIP712:
+		return models.SignatureTypeEIP712
+	default:
+		return ""
+	}
+}
+
+// PackSignature maps the v value of a 65-byte signature to the scheme's range
+func (s SignatureScheme) PackSignature(signatureHex string) (string, error) {
+	switch s.OrDefault() {
+	case SchemeEthSign:
+		return PackSignatureForSafeEthSign(signatureHex)
+	case SchemeEIP712:
+		return PackSignatureForSafeEIP712(signatureHex)
+	default:
+		return "", s.Validate()
+	}
+}
+
+// SignSafeTxHash signs a SafeTx hash under scheme and returns the packed
+// signature ready for the relayer, with the v range matching the hashing
+func (s *Signer) SignSafeTxHash(safeTxHash []byte, scheme SignatureScheme) (string, error) {
+	if err := scheme.Validate(); err != nil {
+		return "", err
+	}
+
+	var signature string
+	var err error
+	switch scheme.OrDefault() {
+	case SchemeEthSign:
+		signature, err = s.SignEIP712StructHash(safeTxHash)
+	case SchemeEIP712:
+		signature, err = s.Sign(safeTxHash)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return scheme.PackSignature(signature)
+}
+
+// SignSafeTx is SignSafeTxHash for the SafeTx typed data whose digest is
+// the SafeTx hash, so that under SchemeEIP712 a backend that cannot sign
+// bare digests is handed the full typed data
+func (s *Signer) SignSafeTx(typedData *TypedData, scheme SignatureScheme) (string, error) {
+	if err := scheme.Validate(); err != nil {
+		return "", err
+	}
+	if scheme.OrDefault() != SchemeEIP712 {
+		if typedData == nil {
+			return "", errors.ErrMissingRequiredField("typedData")
+		}
+		safeTxHash, err := HashTypedData(typedData)
+		if err != nil {
+			return "", err
+		}
+		return s.SignSafeTxHash(safeTxHash.Bytes(), scheme)
+	}
+
+	signature, err := s.SignTypedData(typedData)
+	if err != nil {
+		return "", err
+	}
+	return scheme.PackSignature(signature)
+}
@@ -0,0 +1,150 @@
+package signer
+
+import (
+	stderrors "errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+func TestSignatureScheme_Validate(t *testing.T) {
+	tests := []struct {
+		scheme    SignatureScheme
+		shouldErr bool
+	}{
+		{scheme: ""},
+		{scheme: SchemeEthSign},
+		{scheme: SchemeEIP712},
+		{scheme: "personal_sign", shouldErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.scheme), func(t *testing.T) {
+			err := tt.scheme.Validate()
+			if (err != nil) != tt.shouldErr {
+				t.Errorf("Validate() error = %v, shouldErr %v", err, tt.shouldErr)
+			}
+		})
+	}
+}
+
+func TestSignatureScheme_PackSignature(t *testing.T) {
+	// v = 28 (parity 1)
+	raw := "0xad62657208a0d885f91bba7490de238741bf7c51eb792f00856171aafc9e012373156fb672e55d840733c8bf723ec458545fcd5749aa5e547f808c222e7e11701c"
+
+	tests := []struct {
+		scheme    SignatureScheme
+		wantV     byte
+		shouldErr bool
+	}{
+		{scheme: "", wantV: 32},
+		{scheme: SchemeEthSign, wantV: 32},
+		{scheme: SchemeEIP712, wantV: 28},
+		{scheme: "unknown", shouldErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.scheme), func(t *testing.T) {
+			packed, err := tt.scheme.PackSignature(raw)
+			if tt.shouldErr {
+				if err == nil {
+					t.Fatal("Expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("PackSignature failed: %v", err)
+			}
+			if v := hexutil.MustDecode(packed)[64]; v != tt.wantV {
+				t.Errorf("v = %d, want %d", v, tt.wantV)
+			}
+		})
+	}
+}
+
+func TestSigner_SignSafeTxHash_UnknownScheme(t *testing.T) {
+	s, err := NewSigner("ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80", 137)
+	if err != nil {
+		t.Fatalf("NewSigner failed: %v", err)
+	}
+	if _, err := s.SignSafeTxHash(make([]byte, 32), "unknown"); err == nil {
+		t.Error("Expected error for unknown scheme")
+	}
+}
+
+func TestSignatureScheme_SignatureType(t *testing.T) {
+	tests := []struct {
+		scheme SignatureScheme
+		want   models.SignatureType
+	}{
+		{"", models.SignatureTypeEthSign},
+		{SchemeEthSign, models.SignatureTypeEthSign},
+		{SchemeEIP712, models.SignatureTypeEIP712},
+		{"personal_sign", ""},
+	}
+
+	for _, tt := range tests {
+		if got := tt.scheme.SignatureType(); got != tt.want {
+			t.Errorf("SignatureScheme(%q).SignatureType() = %q, want %q", tt.scheme, got, tt.want)
+		}
+	}
+}
+
+func TestRecoverTypedSignature(t *testing.T) {
+	signer, err := NewSigner(testPrivateKey, 80002)
+	if err != nil {
+		t.Fatalf("NewSigner failed: %v", err)
+	}
+	hash := crypto.Keccak256([]byte("safe tx"))
+
+	packed := make(map[SignatureScheme][]byte)
+	for _, scheme := range []SignatureScheme{SchemeEthSign, SchemeEIP712} {
+		signature, err := signer.SignSafeTxHash(hash, scheme)
+		if err != nil {
+			t.Fatalf("SignSafeTxHash failed: %v", err)
+		}
+		packed[scheme] = hexutil.MustDecode(signature)
+	}
+
+	tests := []struct {
+		name         string
+		signature    []byte
+		sigType      models.SignatureType
+		wantMismatch bool
+		shouldErr    bool
+	}{
+		{name: "eth_sign", signature: packed[SchemeEthSign], sigType: models.SignatureTypeEthSign},
+		{name: "eip712", signature: packed[SchemeEIP712], sigType: models.SignatureTypeEIP712},
+		{name: "untyped eth_sign", signature: packed[SchemeEthSign]},
+		{name: "untyped eip712", signature: packed[SchemeEIP712]},
+		{name: "eth_sign type with v 27/28", signature: packed[SchemeEIP712], sigType: models.SignatureTypeEthSign, wantMismatch: true, shouldErr: true},
+		{name: "eip712 type with v 31/32", signature: packed[SchemeEthSign], sigType: models.SignatureTypeEIP712, wantMismatch: true, shouldErr: true},
+		{name: "contract", signature: withV(packed[SchemeEIP712], 0), sigType: models.SignatureTypeContract, shouldErr: true},
+		{name: "unknown type", signature: packed[SchemeEIP712], sigType: "personal_sign", shouldErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recovered, err := RecoverTypedSignature(hash, tt.signature, tt.sigType)
+			if (err != nil) != tt.shouldErr {
+				t.Fatalf("RecoverTypedSignature() error = %v, shouldErr %v", err, tt.shouldErr)
+			}
+			var mismatch *errors.SignatureTypeMismatchError
+			if got := stderrors.As(err, &mismatch); got != tt.wantMismatch {
+				t.Errorf("SignatureTypeMismatchError = %v, want %v (error: %v)", got, tt.wantMismatch, err)
+			}
+			if !tt.shouldErr && recovered != signer.Address() {
+				t.Errorf("Recovered address = %s, want %s", recovered.Hex(), signer.AddressHex())
+			}
+		})
+	}
+
+	valid, err := signer.VerifyTypedSignature(hash, hexutil.Encode(packed[SchemeEthSign]), models.SignatureTypeEthSign)
+	if err != nil || !valid {
+		t.Errorf("VerifyTypedSignature() = %v, %v, want true", valid, err)
+	}
+}
@@ -11,23 +11,32 @@ import (
 	"github.com/ethereum/go-ethereum/crypto"
 
 	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/models"
 )
 
-// Signer handles cryptographic signing operations for Ethereum transactions
+// Signer handles cryptographic signing operations for Ethereum transactions.
+// The signatures come from its backend: a local private key for NewSigner,
+// a remote signing service for NewRemoteSigner.
 type Signer struct {
-	privateKey *ecdsa.PrivateKey
-	address    common.Address
-	chainID    *big.Int
+	backend SignerBackend
+	address common.Address
+	chainID *big.Int
 }
 
+// privateKeyHexLength is the number of hex characters in a secp256k1 private key
+const privateKeyHexLength = 64
+
 // NewSigner creates a new Signer from a private key hex string
-// privateKeyHex should not include the "0x" prefix
+// privateKeyHex is 64 hex characters, optionally prefixed with "0x";
+// surrounding whitespace is ignored
 func NewSigner(privateKeyHex string, chainID int64) (*Signer, error) {
-	// Remove "0x" prefix if present
-	privateKeyHex = strings.TrimPrefix(privateKeyHex, "0x")
+	keyBytes, err := parsePrivateKeyHex(privateKeyHex)
+	if err != nil {
+		return nil, err
+	}
 
 	// Parse the private key
-	privateKey, err := crypto.HexToECDSA(privateKeyHex)
+	privateKey, err := crypto.ToECDSA(keyBytes)
 	if err != nil {
 		return nil, errors.ErrInvalidPrivateKey(err)
 	}
@@ -42,12 +51,63 @@ func NewSigner(privateKeyHex string, chainID int64) (*Signer, error) {
 	address := crypto.PubkeyToAddress(*publicKeyECDSA)
 
 	return &Signer{
-		privateKey: privateKey,
-		address:    address,
-		chainID:    big.NewInt(chainID),
+		backend: &keyBackend{privateKey: privateKey},
+		address: address,
+		chainID: big.NewInt(chainID),
 	}, nil
 }
 
+// NewSignerWithBackend creates a Signer for address whose signatures come
+// from backend
+func NewSignerWithBackend(backend SignerBackend, address common.Address, chainID int64) *Signer {
+	return &Signer{
+		backend: backend,
+		address: address,
+		chainID: big.NewInt(chainID),
+	}
+}
+
+// parsePrivateKeyHex decodes a hex private key, reporting what is wrong with
+// malformed input without echoing the key material
+func parsePrivateKeyHex(privateKeyHex string) ([]byte, error) {
+	privateKeyHex = strings.TrimSpace(privateKeyHex)
+	if strings.HasPrefix(privateKeyHex, "0x") || strings.HasPrefix(privateKeyHex, "0X") {
+		privateKeyHex = privateKeyHex[2:]
+	}
+
+	if privateKeyHex == "" {
+		return nil, errors.ErrInvalidPrivateKey(fmt.Errorf("key is empty; expected %d hex characters", privateKeyHexLength))
+	}
+
+	for i, r := range privateKeyHex {
+		if !isHexDigit(r) {
+			return nil, errors.ErrInvalidPrivateKey(fmt.Errorf("non-hex character at position %d; expected only 0-9 and a-f", i+1))
+		}
+	}
+
+	if len(privateKeyHex) != privateKeyHexLength {
+		hint := ""
+		if len(privateKeyHex) == privateKeyHexLength-1 {
+			hint = " (a leading zero may have been dropped)"
+		}
+		return nil, errors.ErrInvalidPrivateKey(fmt.Errorf("wrong length: got %d hex characters, expected %d%s",
+			len(privateKeyHex), privateKeyHexLength, hint))
+	}
+
+	keyBytes := common.FromHex(privateKeyHex)
+	d := new(big.Int).SetBytes(keyBytes)
+	if d.Sign() == 0 || d.Cmp(crypto.S256().Params().N) >= 0 {
+		return nil, errors.ErrInvalidPrivateKey(fmt.Errorf("out of range: key must be greater than zero and less than the secp256k1 curve order"))
+	}
+
+	return keyBytes, nil
+}
+
+// isHexDigit reports whether r is a hexadecimal digit
+func isHexDigit(r rune) bool {
+	return ('0' <= r && r <= '9') || ('a' <= r && r <= 'f') || ('A' <= r && r <= 'F')
+}
+
 // Address returns the Ethereum address associated with the signer's private key
 func (s *Signer) Address() common.Address {
 	return s.address
@@ -60,30 +120,32 @@ func (s *Signer) AddressHex() string {
 
 // GetChainID returns the chain ID
 func (s *Signer) GetChainID() *big.Int {
+	if s.chainID == nil {
+		return new(big.Int)
+	}
 	return new(big.Int).Set(s.chainID)
 }
 
-// Sign signs a message hash using EIP-191 personal sign format
+// errNoPrivateKey is returned when signing with a Signer not created by
+// NewSigner, NewSignerWithBackend or NewRemoteSigner
+var errNoPrivateKey = fmt.Errorf("signer has no private key")
+
+// Sign signs a 32-byte message hash as-is, without the EIP-191 prefix
 // messageHash should be the 32-byte hash of the message
 // Returns the signature as a hex string with "0x" prefix
 func (s *Signer) Sign(messageHash []byte) (string, error) {
+	if s.backend == nil {
+		return "", errors.ErrSigningFailed(errNoPrivateKey)
+	}
 	if len(messageHash) != 32 {
 		return "", errors.NewRelayerClientError("message hash must be 32 bytes", nil)
 	}
 
-	// Sign using Ethereum's personal sign format
-	// This adds the "\x19Ethereum Signed Message:\n32" prefix
-	signature, err := crypto.Sign(messageHash, s.privateKey)
+	signature, err := s.backend.SignHash(messageHash)
 	if err != nil {
 		return "", errors.ErrSigningFailed(err)
 	}
-
-	// Adjust V value for Ethereum (add 27)
-	if signature[64] < 27 {
-		signature[64] += 27
-	}
-
-	return hexutil.Encode(signature), nil
+	return encodeSignature(signature), nil
 }
 
 // SignEIP712StructHash signs an EIP-712 struct hash
@@ -93,69 +155,149 @@ func (s *Signer) Sign(messageHash []byte) (string, error) {
 // NOTE: This applies EIP-191 prefixing to the EIP-712 hash, matching Python's encode_defunct flow
 // The final message signed is: keccak256("\x19Ethereum Signed Message:\n32" + messageHash)
 func (s *Signer) SignEIP712StructHash(messageHash []byte) (string, error) {
+	if s.backend == nil {
+		return "", errors.ErrSigningFailed(errNoPrivateKey)
+	}
 	if len(messageHash) != 32 {
 		return "", errors.NewRelayerClientError("message hash must be 32 bytes", nil)
 	}
 
-	// Apply EIP-191 prefix and hash (matching Python's encode_defunct + sign_message)
 	// Python: encode_defunct creates "\x19Ethereum Signed Message:\n{len}" + message
 	// Then sign_message hashes it with keccak256 before signing
-	prefix := []byte(fmt.Sprintf("\x19Ethereum Signed Message:\n%d", len(messageHash)))
-	prefixedMessage := append(prefix, messageHash...)
-	finalHash := crypto.Keccak256(prefixedMessage)
-
-	// Sign the final hash
-	signature, err := crypto.Sign(finalHash, s.privateKey)
+	signature, err := s.backend.SignPersonal(messageHash)
 	if err != nil {
 		return "", errors.ErrSigningFailed(err)
 	}
-
-	// Adjust V value for Ethereum (add 27)
-	if signature[64] < 27 {
-		signature[64] += 27
-	}
-
-	return hexutil.Encode(signature), nil
+	return encodeSignature(signature), nil
 }
 
 // SignMessage signs an arbitrary message using EIP-191 personal sign
 // The message will be prefixed with "\x19Ethereum Signed Message:\n{length}"
 func (s *Signer) SignMessage(message []byte) (string, error) {
-	// Create the hash with EIP-191 prefix
-	hash := crypto.Keccak256Hash(
-		[]byte(fmt.Sprintf("\x19Ethereum Signed Message:\n%d", len(message))),
-		message,
-	)
+	if s.backend == nil {
+		return "", errors.ErrSigningFailed(errNoPrivateKey)
+	}
 
-	// Sign the hash
-	signature, err := crypto.Sign(hash.Bytes(), s.privateKey)
+	signature, err := s.backend.SignPersonal(message)
 	if err != nil {
 		return "", errors.ErrSigningFailed(err)
 	}
+	return encodeSignature(signature), nil
+}
+
+// SignTypedData signs the EIP-712 digest of typedData without the EIP-191
+// prefix. The result equals Sign(HashTypedData(typedData)), but a backend
+// that cannot sign bare digests is handed the full typed data.
+func (s *Signer) SignTypedData(typedData *TypedData) (string, error) {
+	if s.backend == nil {
+		return "", errors.ErrSigningFailed(errNoPrivateKey)
+	}
+	if typedData == nil {
+		return "", errors.ErrMissingRequiredField("typedData")
+	}
 
-	// Adjust V value for Ethereum (add 27)
-	if signature[64] < 27 {
-		signature[64] += 27
+	digest, err := HashTypedData(typedData)
+	if err != nil {
+		return "", err
 	}
+	signature, err := s.backend.SignTypedData(typedData, digest.Bytes())
+	if err != nil {
+		return "", errors.ErrSigningFailed(err)
+	}
+	return encodeSignature(signature), nil
+}
 
-	return hexutil.Encode(signature), nil
+// encodeSignature hex-encodes a 65-byte signature with v adjusted to 27/28
+func encodeSignature(signature []byte) string {
+	sig := make([]byte, len(signature))
+	copy(sig, signature)
+	if len(sig) == 65 && sig[64] < 27 {
+		sig[64] += 27
+	}
+	return hexutil.Encode(sig)
 }
 
 // RecoverAddress recovers the Ethereum address from a signature
-// This can be used to verify signatures
+// This can be used to verify signatures. v may be 0/1, 27/28, or Safe's
+// eth_sign 31/32, in which case the EIP-191 prefix is applied to messageHash
+// before recovering. Any other v is rejected with an UnsupportedVError; use
+// RecoverAddressForChain for EIP-155 encoded v. signature is not modified.
 func RecoverAddress(messageHash []byte, signature []byte) (common.Address, error) {
+	return RecoverAddressForChain(messageHash, signature, 0)
+}
+
+// RecoverAddressForChain is RecoverAddress also accepting the EIP-155 v of
+// chainID (chainId*2 + 35 or 36), which fits the v byte for chain IDs up to
+// 109. A chainID of 0 accepts no EIP-155 v.
+func RecoverAddressForChain(messageHash []byte, signature []byte, chainID int64) (common.Address, error) {
 	if len(signature) != 65 {
 		return common.Address{}, errors.ErrInvalidSignature(fmt.Errorf("signature must be 65 bytes"))
 	}
 
-	// Adjust V value (subtract 27 if needed)
-	v := signature[64]
-	if v >= 27 {
-		signature[64] -= 27
+	v := int(signature[64])
+	parity, err := recoveryParity(v, chainID)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if v == 31 || v == 32 {
+		messageHash = ethSignHash(messageHash)
+	}
+
+	return ecrecover(messageHash, signature, parity)
+}
+
+// RecoverSafeSignature recovers the owner of a 65-byte Safe signature over
+// dataHash the way the Safe contract's checkSignatures does for ECDSA
+// signatures: v above 30 is an eth_sign signature recovered with v - 4 over
+// the EIP-191 prefixed hash, otherwise v is used as-is over dataHash. Contract
+// signatures (v 0) and approved hashes (v 1) have no ECDSA signer and are
+// rejected, as is any v the contract's ecrecover would not accept.
+func RecoverSafeSignature(dataHash []byte, packedSig []byte) (common.Address, error) {
+	if len(packedSig) != 65 {
+		return common.Address{}, errors.ErrInvalidSignature(fmt.Errorf("signature must be 65 bytes"))
+	}
+
+	v := int(packedSig[64])
+	recoveryV, hash := v, dataHash
+	if v > 30 {
+		recoveryV, hash = v-4, ethSignHash(dataHash)
+	}
+	if recoveryV != 27 && recoveryV != 28 {
+		return common.Address{}, errors.ErrUnsupportedV(v)
 	}
 
-	// Recover the public key
-	pubKey, err := crypto.SigToPub(messageHash, signature)
+	return ecrecover(hash, packedSig, recoveryV-27)
+}
+
+// RecoverTypedSignature recovers the signer of a 65-byte signature over hash
+// as declared by sigType instead of inferring the scheme from v: eth_sign
+// signatures must carry v 31/32 and are recovered over the EIP-191 prefixed
+// hash, eip712 signatures must carry v 27/28. Contract signatures have no
+// ECDSA signer. An empty sigType falls back to RecoverAddress.
+func RecoverTypedSignature(hash []byte, signature []byte, sigType models.SignatureType) (common.Address, error) {
+	if sigType == "" {
+		return RecoverAddress(hash, signature)
+	}
+	if len(signature) != 65 {
+		return common.Address{}, errors.ErrInvalidSignature(fmt.Errorf("signature must be 65 bytes"))
+	}
+	if err := sigType.CheckV(int(signature[64])); err != nil {
+		return common.Address{}, err
+	}
+	if sigType == models.SignatureTypeContract {
+		return common.Address{}, errors.ErrInvalidSignature(fmt.Errorf("contract signatures have no ECDSA signer"))
+	}
+	return RecoverSafeSignature(hash, signature)
+}
+
+// ecrecover recovers the signer of hash from a copy of signature with v
+// replaced by the recovery parity
+func ecrecover(hash []byte, signature []byte, parity int) (common.Address, error) {
+	sig := make([]byte, 65)
+	copy(sig, signature)
+	sig[64] = byte(parity)
+
+	pubKey, err := crypto.SigToPub(hash, sig)
 	if err != nil {
 		return common.Address{}, errors.ErrInvalidSignature(err)
 	}
@@ -163,49 +305,246 @@ func RecoverAddress(messageHash []byte, signature []byte) (common.Address, error
 	return crypto.PubkeyToAddress(*pubKey), nil
 }
 
-// VerifySignature verifies that a signature was created by this signer
-func (s *Signer) VerifySignature(messageHash []byte, signatureHex string) (bool, error) {
-	// Decode the signature
+// ethSignHash returns keccak256("\x19Ethereum Signed Message:\n{len}" ‖ hash)
+func ethSignHash(hash []byte) []byte {
+	prefix := []byte(fmt.Sprintf("\x19Ethereum Signed Message:\n%d", len(hash)))
+	return crypto.Keccak256(prefix, hash)
+}
+
+// VerifyTypedSignature verifies that a signature of the given type was
+// created by this signer (see RecoverTypedSignature)
+func (s *Signer) VerifyTypedSignature(messageHash []byte, signatureHex string, sigType models.SignatureType) (bool, error) {
 	signature, err := hexutil.Decode(signatureHex)
 	if err != nil {
 		return false, errors.ErrInvalidSignature(err)
 	}
 
-	// Recover the address
-	recoveredAddr, err := RecoverAddress(messageHash, signature)
+	recoveredAddr, err := RecoverTypedSignature(messageHash, signature, sigType)
 	if err != nil {
 		return false, err
 	}
+	return recoveredAddr == s.address, nil
+}
 
-	// Compare with signer's address
+// VerifySignatureRaw reports whether signatureHex is this signer's signature
+// over hash itself, without any prefix. It pairs with Sign and SignTypedData
+// (over the typed data's digest). A v of 31/32 marks an EIP-191 prefixed
+// signature and never verifies here.
+func (s *Signer) VerifySignatureRaw(hash []byte, signatureHex string) (bool, error) {
+	signature, err := hexutil.Decode(signatureHex)
+	if err != nil {
+		return false, errors.ErrInvalidSignature(err)
+	}
+	if len(signature) != 65 {
+		return false, errors.ErrInvalidSignature(fmt.Errorf("signature must be 65 bytes"))
+	}
+	if v := int(signature[64]); v == 31 || v == 32 {
+		return false, nil
+	}
+	return s.verifyOver(hash, signature)
+}
+
+// VerifySignaturePersonal reports whether signatureHex is this signer's
+// signature over message with the EIP-191 prefix applied. It pairs with
+// SignMessage.
+func (s *Signer) VerifySignaturePersonal(message []byte, signatureHex string) (bool, error) {
+	signature, err := hexutil.Decode(signatureHex)
+	if err != nil {
+		return false, errors.ErrInvalidSignature(err)
+	}
+	if len(signature) != 65 {
+		return false, errors.ErrInvalidSignature(fmt.Errorf("signature must be 65 bytes"))
+	}
+	return s.verifyOver(ethSignHash(message), signature)
+}
+
+// VerifySignatureOverHashPersonal reports whether signatureHex is this
+// signer's signature over the 32-byte hash with the EIP-191 prefix applied.
+// It pairs with SignEIP712StructHash, and accepts Safe's eth_sign v 31/32.
+func (s *Signer) VerifySignatureOverHashPersonal(hash []byte, signatureHex string) (bool, error) {
+	if len(hash) != 32 {
+		return false, errors.NewRelayerClientError("message hash must be 32 bytes", nil)
+	}
+	return s.VerifySignaturePersonal(hash, signatureHex)
+}
+
+// verifyOver reports whether the 65-byte signature over digest recovers to
+// the signer's address
+func (s *Signer) verifyOver(digest []byte, signature []byte) (bool, error) {
+	parity, err := recoveryParity(int(signature[64]), s.GetChainID().Int64())
+	if err != nil {
+		return false, err
+	}
+	recoveredAddr, err := ecrecover(digest, signature, parity)
+	if err != nil {
+		return false, err
+	}
 	return recoveredAddr == s.address, nil
 }
 
+// VerifySignatureScheme reports which scheme, if any, signatureHex verifies
+// under as this signer's signature of messageHash: SchemeEIP712 for a raw
+// signature (VerifySignatureRaw), SchemeEthSign for an EIP-191 prefixed one
+// (VerifySignatureOverHashPersonal). It returns "" and false when neither
+// matches.
+func (s *Signer) VerifySignatureScheme(messageHash []byte, signatureHex string) (SignatureScheme, bool, error) {
+	valid, err := s.VerifySignatureRaw(messageHash, signatureHex)
+	if err != nil {
+		return "", false, err
+	}
+	if valid {
+		return SchemeEIP712, true, nil
+	}
+
+	valid, err = s.VerifySignatureOverHashPersonal(messageHash, signatureHex)
+	if err != nil || !valid {
+		return "", false, err
+	}
+	return SchemeEthSign, true, nil
+}
+
+// VerifySignature verifies that a signature of messageHash was created by
+// this signer, whether it was signed raw (Sign) or with the EIP-191 prefix
+// (SignEIP712StructHash). Use VerifySignatureScheme to learn which matched,
+// or the scheme-specific verifiers to accept only one.
+func (s *Signer) VerifySignature(messageHash []byte, signatureHex string) (bool, error) {
+	_, valid, err := s.VerifySignatureScheme(messageHash, signatureHex)
+	return valid, err
+}
+
 // SplitSignature splits a signature into r, s, v components
+// v is returned exactly as encoded in the signature; it is equivalent to SplitSignatureRaw
 func SplitSignature(signatureHex string) (r, s string, v int, err error) {
-	// Remove "0x" prefix if present
-	signatureHex = strings.TrimPrefix(signatureHex, "0x")
+	return SplitSignatureRaw(signatureHex)
+}
 
-	// Decode the signature
-	signature, err := hexutil.Decode("0x" + signatureHex)
+// SplitSignatureRaw splits a 65-byte signature into r, s (0x-prefixed hex) and v.
+// v is returned unchanged: inputs with v 0, 1, 27 or 28 yield 0, 1, 27 or 28.
+func SplitSignatureRaw(signatureHex string) (r, s string, v int, err error) {
+	signature, err := decodeSignature(signatureHex)
 	if err != nil {
-		return "", "", 0, errors.ErrInvalidSignature(err)
+		return "", "", 0, err
+	}
+
+	r = hexutil.Encode(signature[0:32])
+	s = hexutil.Encode(signature[32:64])
+	v = int(signature[64])
+
+	return r, s, v, nil
+}
+
+// SplitSignatureForSafeEthSign splits a signature and maps v to the values the
+// Safe contract expects for eth_sign (EIP-191 prefixed) signatures:
+// v 0 or 27 becomes 31, v 1 or 28 becomes 32, and 31/32 are kept as-is. Any
+// other v is rejected with an UnsupportedVError.
+func SplitSignatureForSafeEthSign(signatureHex string) (r, s string, v int, err error) {
+	r, s, v, err = SplitSignatureRaw(signatureHex)
+	if err != nil {
+		return "", "", 0, err
+	}
+
+	parity, err := recoveryParity(v, 0)
+	if err != nil {
+		return "", "", 0, err
+	}
+
+	return r, s, 31 + parity, nil
+}
+
+// SplitSignatureForSafeEIP712 splits a signature and maps v to the values the
+// Safe contract expects for raw EIP-712 signatures over the SafeTx hash:
+// v 0 or 27 becomes 27, v 1 or 28 becomes 28 (31/32 are mapped back to 27/28).
+// Any other v is rejected with an UnsupportedVError.
+func SplitSignatureForSafeEIP712(signatureHex string) (r, s string, v int, err error) {
+	r, s, v, err = SplitSignatureRaw(signatureHex)
+	if err != nil {
+		return "", "", 0, err
+	}
+
+	parity, err := recoveryParity(v, 0)
+	if err != nil {
+		return "", "", 0, err
+	}
+
+	return r, s, 27 + parity, nil
+}
+
+// PackSignatureForSafeEthSign returns the 65-byte r ‖ s ‖ v signature with v
+// set to 31/32, as used by the relayer for SAFE transactions signed via eth_sign
+func PackSignatureForSafeEthSign(signatureHex string) (string, error) {
+	r, s, v, err := SplitSignatureForSafeEthSign(signatureHex)
+	if err != nil {
+		return "", err
+	}
+	return packSignature(r, s, v)
+}
+
+// PackSignatureForSafeEIP712 returns the 65-byte r ‖ s ‖ v signature with v
+// set to 27/28, as used for raw EIP-712 signatures of a SafeTx
+func PackSignatureForSafeEIP712(signatureHex string) (string, error) {
+	r, s, v, err := SplitSignatureForSafeEIP712(signatureHex)
+	if err != nil {
+		return "", err
+	}
+	return packSignature(r, s, v)
+}
+
+// decodeSignature decodes a 65-byte signature given with or without 0x prefix
+func decodeSignature(signatureHex string) ([]byte, error) {
+	signature, err := hexutil.Decode("0x" + strings.TrimPrefix(signatureHex, "0x"))
+	if err != nil {
+		return nil, errors.ErrInvalidSignature(err)
 	}
 
 	if len(signature) != 65 {
-		return "", "", 0, errors.ErrInvalidSignature(fmt.Errorf("signature must be 65 bytes"))
+		return nil, errors.ErrInvalidSignature(fmt.Errorf("signature must be 65 bytes"))
 	}
 
-	// Extract r, s, v components
-	r = "0x" + hexutil.Encode(signature[0:32])[2:]
-	s = "0x" + hexutil.Encode(signature[32:64])[2:]
-	v = int(signature[64])
+	return signature, nil
+}
 
-	return r, s, v, nil
+// recoveryParity extracts the recovery id (0 or 1) from a v value in one of
+// the supported encodings: 0/1, 27/28, Safe's eth_sign 31/32, or, when
+// chainID is positive, exactly chainID's EIP-155 chainId*2 + 35/36. Any other
+// v is rejected rather than normalised.
+func recoveryParity(v int, chainID int64) (int, error) {
+	switch {
+	case v == 0, v == 1:
+		return v, nil
+	case v == 27, v == 28:
+		return v - 27, nil
+	case v == 31, v == 32:
+		return v - 31, nil
+	case chainID > 0 && (int64(v) == chainID*2+35 || int64(v) == chainID*2+36):
+		return int(int64(v) - chainID*2 - 35), nil
+	default:
+		return 0, errors.ErrUnsupportedV(v)
+	}
+}
+
+// packSignature encodes r ‖ s ‖ v into a 0x-prefixed 65-byte hex string
+func packSignature(r, s string, v int) (string, error) {
+	rBytes, err := hexutil.Decode(r)
+	if err != nil {
+		return "", errors.ErrInvalidSignature(err)
+	}
+	sBytes, err := hexutil.Decode(s)
+	if err != nil {
+		return "", errors.ErrInvalidSignature(err)
+	}
+
+	packed := make([]byte, 65)
+	copy(packed[0:32], rBytes)
+	copy(packed[32:64], sBytes)
+	packed[64] = byte(v)
+
+	return hexutil.Encode(packed), nil
 }
 
 // PackSignatures packs multiple signatures into a single byte array
-// This is used for Safe multi-signature transactions
+// This is used for Safe multi-signature transactions. Signatures are
+// concatenated in the order given; see PackSignaturesForSafe for the order
+// the Safe contract checks.
 func PackSignatures(signatures []string) (string, error) {
 	if len(signatures) == 0 {
 		return "", errors.NewRelayerClientError("no signatures provided", nil)
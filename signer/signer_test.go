@@ -1,13 +1,18 @@
 package signer
 
 import (
+	"bytes"
 	"encoding/hex"
+	stderrors "errors"
+	"fmt"
 	"strings"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/davidt58/go-builder-relayer-client/errors"
 )
 
 const (
@@ -57,6 +62,61 @@ func TestNewSigner_InvalidKey(t *testing.T) {
 	}
 }
 
+func TestNewSigner_Whitespace(t *testing.T) {
+	inputs := []string{
+		" " + testPrivateKey,
+		testPrivateKey + "\n",
+		"\t0x" + testPrivateKey + "\r\n",
+		"0X" + strings.ToUpper(testPrivateKey),
+	}
+
+	for _, input := range inputs {
+		signer, err := NewSigner(input, 80002)
+		if err != nil {
+			t.Fatalf("NewSigner(%q) failed: %v", input, err)
+		}
+		if !strings.EqualFold(signer.AddressHex(), testAddress) {
+			t.Errorf("Address = %s, want %s", signer.AddressHex(), testAddress)
+		}
+	}
+}
+
+func TestNewSigner_MalformedKeys(t *testing.T) {
+	curveOrder := "fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364141"
+
+	tests := []struct {
+		name     string
+		key      string
+		category string
+	}{
+		{"empty", "  ", "empty"},
+		{"prefix only", "0x", "empty"},
+		{"missing leading zero", testPrivateKey[1:], "got 63 hex characters, expected 64"},
+		{"too long", testPrivateKey + "00", "got 66 hex characters, expected 64"},
+		{"non-hex character", testPrivateKey[:10] + "g" + testPrivateKey[11:], "non-hex character at position 11"},
+		{"inner whitespace", testPrivateKey[:32] + " " + testPrivateKey[32:], "non-hex character at position 33"},
+		{"zero", strings.Repeat("0", 64), "out of range"},
+		{"curve order", curveOrder, "out of range"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewSigner(tt.key, 80002)
+			if err == nil {
+				t.Fatal("Expected error but got none")
+			}
+
+			msg := err.Error()
+			if !strings.Contains(msg, tt.category) {
+				t.Errorf("error %q does not contain %q", msg, tt.category)
+			}
+			if secret := strings.TrimSpace(tt.key); len(secret) > 8 && strings.Contains(msg, secret[:8]) {
+				t.Errorf("error %q leaks key material", msg)
+			}
+		})
+	}
+}
+
 func TestSigner_SignAndVerify(t *testing.T) {
 	signer, err := NewSigner(testPrivateKey, 80002)
 	if err != nil {
@@ -92,6 +152,93 @@ func TestSigner_SignAndVerify(t *testing.T) {
 	}
 }
 
+func TestSigner_VerifyPairsWithSign(t *testing.T) {
+	signer, err := NewSigner(testPrivateKey, 137)
+	if err != nil {
+		t.Fatalf("NewSigner failed: %v", err)
+	}
+	message := []byte("test message")
+	hash := crypto.Keccak256(message)
+	typedData := &TypedData{
+		Types: map[string][]EIP712Type{
+			"EIP712Domain": {{Name: "chainId", Type: "uint256"}},
+			"Mail":         {{Name: "contents", Type: "string"}},
+		},
+		PrimaryType: "Mail",
+		Domain:      EIP712Domain{ChainId: signer.GetChainID()},
+		Message:     map[string]interface{}{"contents": "hello"},
+	}
+	digest, err := HashTypedData(typedData)
+	if err != nil {
+		t.Fatalf("HashTypedData failed: %v", err)
+	}
+
+	raw, _ := signer.Sign(hash)
+	typed, _ := signer.SignTypedData(typedData)
+	personal, _ := signer.SignMessage(message)
+	overHash, _ := signer.SignEIP712StructHash(hash)
+	safeEthSign, _ := PackSignatureForSafeEthSign(overHash)
+
+	verifiers := map[string]func(signature string) (bool, error){
+		"VerifySignatureRaw": func(signature string) (bool, error) { return signer.VerifySignatureRaw(hash, signature) },
+		"VerifySignaturePersonal": func(signature string) (bool, error) {
+			return signer.VerifySignaturePersonal(message, signature)
+		},
+		"VerifySignatureOverHashPersonal": func(signature string) (bool, error) {
+			return signer.VerifySignatureOverHashPersonal(hash, signature)
+		},
+	}
+
+	// Each signature verifies with its paired verifier only
+	tests := []struct {
+		name      string
+		signature string
+		verifier  string
+	}{
+		{"Sign", raw, "VerifySignatureRaw"},
+		{"SignMessage", personal, "VerifySignaturePersonal"},
+		{"SignEIP712StructHash", overHash, "VerifySignatureOverHashPersonal"},
+		{"SignEIP712StructHash packed for eth_sign", safeEthSign, "VerifySignatureOverHashPersonal"},
+	}
+	for _, tt := range tests {
+		for name, verify := range verifiers {
+			valid, err := verify(tt.signature)
+			if err != nil {
+				t.Fatalf("%s with %s: %v", tt.name, name, err)
+			}
+			if want := name == tt.verifier; valid != want {
+				t.Errorf("%s with %s = %v, want %v", tt.name, name, valid, want)
+			}
+		}
+	}
+
+	// SignTypedData signs the digest raw
+	if valid, err := signer.VerifySignatureRaw(digest.Bytes(), typed); err != nil || !valid {
+		t.Errorf("SignTypedData with VerifySignatureRaw = %v, %v, want true", valid, err)
+	}
+	if valid, _ := signer.VerifySignatureOverHashPersonal(digest.Bytes(), typed); valid {
+		t.Error("SignTypedData verified with VerifySignatureOverHashPersonal")
+	}
+
+	// VerifySignature accepts both hash schemes and VerifySignatureScheme names them
+	for signature, want := range map[string]SignatureScheme{raw: SchemeEIP712, overHash: SchemeEthSign, safeEthSign: SchemeEthSign} {
+		scheme, valid, err := signer.VerifySignatureScheme(hash, signature)
+		if err != nil || !valid || scheme != want {
+			t.Errorf("VerifySignatureScheme(%s) = %q, %v, %v, want %q", signature, scheme, valid, err, want)
+		}
+		if valid, _ := signer.VerifySignature(hash, signature); !valid {
+			t.Errorf("VerifySignature(%s) = false", signature)
+		}
+	}
+	if scheme, valid, err := signer.VerifySignatureScheme(hash, personal); err != nil || valid || scheme != "" {
+		t.Errorf("VerifySignatureScheme of a message signature = %q, %v, %v, want no match", scheme, valid, err)
+	}
+
+	if _, err := signer.VerifySignatureOverHashPersonal(message, overHash); err == nil {
+		t.Error("VerifySignatureOverHashPersonal accepted a non-32-byte hash")
+	}
+}
+
 func TestSigner_SignMessage(t *testing.T) {
 	signer, err := NewSigner(testPrivateKey, 80002)
 	if err != nil {
@@ -145,6 +292,95 @@ func TestSplitSignature(t *testing.T) {
 	}
 }
 
+// signatureWithV builds a dummy 65-byte signature with the given v byte
+func signatureWithV(v byte) string {
+	signature := make([]byte, 65)
+	for i := 0; i < 64; i++ {
+		signature[i] = byte(i + 1)
+	}
+	signature[64] = v
+	return hexutil.Encode(signature)
+}
+
+func TestSignatureVariants_VValues(t *testing.T) {
+	tests := []struct {
+		inputV  byte
+		raw     int
+		ethSign int
+		eip712  int
+	}{
+		{0, 0, 31, 27},
+		{1, 1, 32, 28},
+		{27, 27, 31, 27},
+		{28, 28, 32, 28},
+		{31, 31, 31, 27},
+		{32, 32, 32, 28},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("v=%d", tt.inputV), func(t *testing.T) {
+			sigHex := signatureWithV(tt.inputV)
+
+			_, _, v, err := SplitSignatureRaw(sigHex)
+			if err != nil || v != tt.raw {
+				t.Errorf("SplitSignatureRaw v = %d (err %v), want %d", v, err, tt.raw)
+			}
+
+			_, _, v, err = SplitSignatureForSafeEthSign(sigHex)
+			if err != nil || v != tt.ethSign {
+				t.Errorf("SplitSignatureForSafeEthSign v = %d (err %v), want %d", v, err, tt.ethSign)
+			}
+
+			_, _, v, err = SplitSignatureForSafeEIP712(sigHex)
+			if err != nil || v != tt.eip712 {
+				t.Errorf("SplitSignatureForSafeEIP712 v = %d (err %v), want %d", v, err, tt.eip712)
+			}
+
+			packed, err := PackSignatureForSafeEthSign(sigHex)
+			if err != nil {
+				t.Fatalf("PackSignatureForSafeEthSign failed: %v", err)
+			}
+			packedBytes, _ := hexutil.Decode(packed)
+			if int(packedBytes[64]) != tt.ethSign || packed[:130] != sigHex[:130] {
+				t.Errorf("PackSignatureForSafeEthSign = %s, want r,s unchanged and v %d", packed, tt.ethSign)
+			}
+
+			packed, err = PackSignatureForSafeEIP712(sigHex)
+			if err != nil {
+				t.Fatalf("PackSignatureForSafeEIP712 failed: %v", err)
+			}
+			packedBytes, _ = hexutil.Decode(packed)
+			if int(packedBytes[64]) != tt.eip712 || packed[:130] != sigHex[:130] {
+				t.Errorf("PackSignatureForSafeEIP712 = %s, want r,s unchanged and v %d", packed, tt.eip712)
+			}
+		})
+	}
+}
+
+func TestSignatureVariants_UnsupportedV(t *testing.T) {
+	sigHex := signatureWithV(5)
+
+	if _, _, _, err := SplitSignatureForSafeEthSign(sigHex); err == nil {
+		t.Error("SplitSignatureForSafeEthSign should reject v=5")
+	}
+	if _, err := PackSignatureForSafeEIP712(sigHex); err == nil {
+		t.Error("PackSignatureForSafeEIP712 should reject v=5")
+	}
+	if _, _, v, err := SplitSignatureRaw(sigHex); err != nil || v != 5 {
+		t.Errorf("SplitSignatureRaw v = %d (err %v), want 5", v, err)
+	}
+
+	// EIP-155 and other out-of-range v values are not normalised
+	for _, v := range []byte{37, 38, 100, 255} {
+		if _, err := PackSignatureForSafeEthSign(signatureWithV(v)); err == nil {
+			t.Errorf("PackSignatureForSafeEthSign should reject v=%d", v)
+		}
+		if _, _, _, err := SplitSignatureForSafeEIP712(signatureWithV(v)); err == nil {
+			t.Errorf("SplitSignatureForSafeEIP712 should reject v=%d", v)
+		}
+	}
+}
+
 func TestPackSignatures(t *testing.T) {
 	// Create two dummy signatures
 	sig1 := make([]byte, 65)
@@ -232,3 +468,202 @@ func TestKeccak256Hash(t *testing.T) {
 		t.Error("Hash mismatch")
 	}
 }
+
+// signRecoverable signs hash with the test key and returns the signature with
+// v holding the raw recovery parity
+func signRecoverable(t *testing.T, hash []byte) []byte {
+	t.Helper()
+	key, err := crypto.HexToECDSA(testPrivateKey)
+	if err != nil {
+		t.Fatalf("HexToECDSA failed: %v", err)
+	}
+	signature, err := crypto.Sign(hash, key)
+	if err != nil {
+		t.Fatalf("crypto.Sign failed: %v", err)
+	}
+	return signature
+}
+
+// withV returns a copy of signature with v replaced
+func withV(signature []byte, v int) []byte {
+	sig := append([]byte(nil), signature...)
+	sig[64] = byte(v)
+	return sig
+}
+
+func TestRecoverAddress_VValues(t *testing.T) {
+	hash := crypto.Keccak256([]byte("test"))
+	bare := signRecoverable(t, hash)
+	ethSign := signRecoverable(t, ethSignHash(hash))
+	parity := int(bare[64])
+	ethSignParity := int(ethSign[64])
+
+	tests := []struct {
+		name      string
+		signature []byte
+		shouldErr bool
+	}{
+		{name: "raw parity", signature: withV(bare, parity)},
+		{name: "27/28", signature: withV(bare, 27+parity)},
+		{name: "EIP-155 without a chain", signature: withV(bare, 1*2+35+parity), shouldErr: true},
+		{name: "v 255", signature: withV(bare, 255), shouldErr: true},
+		{name: "Safe eth_sign 31/32", signature: withV(ethSign, 31+ethSignParity)},
+		{name: "v 2", signature: withV(bare, 2), shouldErr: true},
+		{name: "v 26", signature: withV(bare, 26), shouldErr: true},
+		{name: "v 29", signature: withV(bare, 29), shouldErr: true},
+		{name: "v 30", signature: withV(bare, 30), shouldErr: true},
+		{name: "v 33", signature: withV(bare, 33), shouldErr: true},
+		{name: "v 34", signature: withV(bare, 34), shouldErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := append([]byte(nil), tt.signature...)
+
+			recovered, err := RecoverAddress(hash, tt.signature)
+			if !bytes.Equal(tt.signature, original) {
+				t.Errorf("RecoverAddress modified the signature: %x, was %x", tt.signature, original)
+			}
+			if (err != nil) != tt.shouldErr {
+				t.Fatalf("RecoverAddress() error = %v, shouldErr %v", err, tt.shouldErr)
+			}
+			if tt.shouldErr {
+				var vErr *errors.UnsupportedVError
+				if !stderrors.As(err, &vErr) || vErr.V != int(tt.signature[64]) {
+					t.Errorf("error = %v, want an UnsupportedVError for v %d", err, tt.signature[64])
+				}
+				return
+			}
+			if !strings.EqualFold(recovered.Hex(), testAddress) {
+				t.Errorf("Recovered address = %s, want %s", recovered.Hex(), testAddress)
+			}
+		})
+	}
+}
+
+func TestRecoverAddressForChain_EIP155(t *testing.T) {
+	hash := crypto.Keccak256([]byte("test"))
+	bare := signRecoverable(t, hash)
+	parity := int(bare[64])
+
+	tests := []struct {
+		name      string
+		chainID   int64
+		v         int
+		shouldErr bool
+	}{
+		{name: "chain 1", chainID: 1, v: 1*2 + 35 + parity},
+		{name: "chain 109", chainID: 109, v: 109*2 + 35 + parity},
+		{name: "plain v", chainID: 1, v: 27 + parity},
+		{name: "another chain's v", chainID: 5, v: 1*2 + 35 + parity, shouldErr: true},
+		{name: "between chains", chainID: 1, v: 39, shouldErr: true},
+		{name: "v 255", chainID: 1, v: 255, shouldErr: true},
+		{name: "no chain", chainID: 0, v: 35 + parity, shouldErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recovered, err := RecoverAddressForChain(hash, withV(bare, tt.v), tt.chainID)
+			if tt.shouldErr {
+				var vErr *errors.UnsupportedVError
+				if !stderrors.As(err, &vErr) || vErr.V != tt.v {
+					t.Errorf("error = %v, want an UnsupportedVError for v %d", err, tt.v)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RecoverAddressForChain failed: %v", err)
+			}
+			if !strings.EqualFold(recovered.Hex(), testAddress) {
+				t.Errorf("Recovered address = %s, want %s", recovered.Hex(), testAddress)
+			}
+		})
+	}
+}
+
+func TestRecoverAddress_ReusedSignature(t *testing.T) {
+	hash := crypto.Keccak256([]byte("test"))
+	signature := signRecoverable(t, hash)
+	signature[64] += 27
+
+	// Recovering twice from the same slice must give the same signer
+	for i := 0; i < 2; i++ {
+		recovered, err := RecoverAddress(hash, signature)
+		if err != nil {
+			t.Fatalf("RecoverAddress #%d failed: %v", i+1, err)
+		}
+		if !strings.EqualFold(recovered.Hex(), testAddress) {
+			t.Fatalf("RecoverAddress #%d = %s, want %s", i+1, recovered.Hex(), testAddress)
+		}
+	}
+	if signature[64] < 27 {
+		t.Errorf("v = %d after recovery, want it unchanged", signature[64])
+	}
+}
+
+func TestRecoverSafeSignature(t *testing.T) {
+	signer, err := NewSigner(testPrivateKey, 80002)
+	if err != nil {
+		t.Fatalf("NewSigner failed: %v", err)
+	}
+	safeTxHash := crypto.Keccak256([]byte("safe tx"))
+	bare := signRecoverable(t, safeTxHash)
+
+	tests := []struct {
+		name      string
+		signature func(t *testing.T) []byte
+		shouldErr bool
+	}{
+		{
+			name: "eth_sign scheme",
+			signature: func(t *testing.T) []byte {
+				packed, err := signer.SignSafeTxHash(safeTxHash, SchemeEthSign)
+				if err != nil {
+					t.Fatalf("SignSafeTxHash failed: %v", err)
+				}
+				return hexutil.MustDecode(packed)
+			},
+		},
+		{
+			name: "EIP-712 scheme",
+			signature: func(t *testing.T) []byte {
+				packed, err := signer.SignSafeTxHash(safeTxHash, SchemeEIP712)
+				if err != nil {
+					t.Fatalf("SignSafeTxHash failed: %v", err)
+				}
+				return hexutil.MustDecode(packed)
+			},
+		},
+		{name: "contract signature", signature: func(*testing.T) []byte { return withV(bare, 0) }, shouldErr: true},
+		{name: "approved hash", signature: func(*testing.T) []byte { return withV(bare, 1) }, shouldErr: true},
+		{name: "v 29", signature: func(*testing.T) []byte { return withV(bare, 29) }, shouldErr: true},
+		{name: "v 30", signature: func(*testing.T) []byte { return withV(bare, 30) }, shouldErr: true},
+		{name: "v 33", signature: func(*testing.T) []byte { return withV(bare, 33) }, shouldErr: true},
+		{name: "EIP-155 v", signature: func(*testing.T) []byte { return withV(bare, 37) }, shouldErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signature := tt.signature(t)
+			original := append([]byte(nil), signature...)
+
+			recovered, err := RecoverSafeSignature(safeTxHash, signature)
+			if !bytes.Equal(signature, original) {
+				t.Errorf("RecoverSafeSignature modified the signature")
+			}
+			if (err != nil) != tt.shouldErr {
+				t.Fatalf("RecoverSafeSignature() error = %v, shouldErr %v", err, tt.shouldErr)
+			}
+			if tt.shouldErr {
+				var vErr *errors.UnsupportedVError
+				if !stderrors.As(err, &vErr) || vErr.V != int(signature[64]) {
+					t.Errorf("error = %v, want an UnsupportedVError for v %d", err, signature[64])
+				}
+				return
+			}
+			if recovered != signer.Address() {
+				t.Errorf("Recovered address = %s, want %s", recovered.Hex(), signer.AddressHex())
+			}
+		})
+	}
+}
@@ -0,0 +1,11 @@
+// Package tests holds the live smoke suite, which runs against a real relayer
+// and is excluded from the default test run:
+//
+//	CHAIN_ID=80002 PK=... BUILDER_API_KEY=... BUILDER_SECRET=... BUILDER_PASS_PHRASE=... \
+//		go test -tags=integration -v ./tests/
+//
+// The variables may also come from a .env file (see .env.example). The
+// suite submits a zero-value transfer from the signer's Safe to the signer,
+// deploying the Safe first if needed. Relayer state is append-only, so
+// nothing is cleaned up.
+package tests
@@ -0,0 +1,136 @@
+//go:build integration
+
+package tests
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joho/godotenv"
+
+	"github.com/davidt58/go-builder-relayer-client/client"
+	"github.com/davidt58/go-builder-relayer-client/config"
+	"github.com/davidt58/go-builder-relayer-client/errors"
+	"github.com/davidt58/go-builder-relayer-client/models"
+)
+
+const (
+	// submitTimeout bounds each Deploy and Execute call
+	submitTimeout = 2 * time.Minute
+	// waitPolls and waitInterval bound each wait to about five minutes
+	waitPolls    = 150
+	waitInterval = 2 * time.Second
+)
+
+// liveClient creates a client from the environment, skipping the test when
+// the signer or builder credentials are not configured
+func liveClient(t *testing.T) *client.RelayClient {
+	t.Helper()
+
+	// A .env file is optional; the environment may already be set
+	_ = godotenv.Load("../.env")
+
+	env, err := config.LoadFromEnv()
+	if err != nil {
+		t.Skipf("live suite skipped: %v (set CHAIN_ID, PK and the BUILDER_* credentials, see .env.example)", err)
+	}
+	if !env.HasSigner() || !env.HasBuilderConfig() {
+		t.Skip("live suite skipped: PK and the BUILDER_* credentials are required (see .env.example)")
+	}
+
+	c, err := client.NewRelayClient(env.RelayerURL, env.ChainID, env.PrivateKey, env.BuilderConfig, client.WithBuilderCredentialCheck())
+	if err != nil {
+		t.Fatalf("NewRelayClient failed: %v", err)
+	}
+	t.Logf("relayer %s, chain %d", c.GetRelayerURL(), env.ChainID)
+	return c
+}
+
+// logStep logs a step's outcome with the operation ID the relayer saw, so
+// failures can be correlated with relayer logs
+func logStep(t *testing.T, step, operationID string, err error) {
+	t.Helper()
+	if operationID == "" {
+		operationID = errors.OperationIDOf(err)
+	}
+	if operationID == "" {
+		operationID = "none"
+	}
+	if err != nil {
+		t.Fatalf("%s failed (operation %s): %v", step, operationID, err)
+	}
+	t.Logf("%s done (operation %s)", step, operationID)
+}
+
+// waitMined waits for a submitted transaction with bounded polling
+func waitMined(t *testing.T, step string, response *models.ClientRelayerTransactionResponse) *models.RelayerTransaction {
+	t.Helper()
+	txn, err := response.WaitWithOptions(models.WaitOptions{
+		TargetStates: []models.RelayerTransactionState{models.STATE_MINED, models.STATE_CONFIRMED},
+		FailState:    models.STATE_FAILED,
+		MaxPolls:     waitPolls,
+		Interval:     waitInterval,
+	})
+	logStep(t, step, response.OperationID, err)
+	t.Logf("%s: transaction %s is %s, hash %s", step, response.TransactionID, txn.State, stringValue(txn.Hash))
+	return txn
+}
+
+func TestLive_Lifecycle(t *testing.T) {
+	c := liveClient(t)
+
+	safeAddress, err := c.GetExpectedSafe()
+	logStep(t, "derive Safe", "", err)
+	t.Logf("Safe %s for signer %s", safeAddress, c.GetSigner().AddressHex())
+
+	deployed, err := c.GetDeployed(safeAddress)
+	logStep(t, "check deployed", "", err)
+
+	if !deployed {
+		response, err := c.DeployWithOptions(client.DeployOptions{Timeout: submitTimeout})
+		switch {
+		case err != nil && strings.Contains(err.Error(), "already deployed"):
+			// Deployed between the check and the submission, e.g. by a
+			// concurrent run
+			t.Logf("deploy skipped (operation %s): %v", errors.OperationIDOf(err), err)
+		default:
+			logStep(t, "deploy", operationIDOf(response), err)
+			waitMined(t, "wait for deployment", response)
+		}
+	} else {
+		t.Log("Safe already deployed")
+	}
+
+	// A zero-value transfer to the signer changes nothing but the Safe nonce
+	transfer := models.NewSafeTransaction(c.GetSigner().AddressHex(), "0", "0x")
+	response, err := c.ExecuteWithOptions([]models.SafeTransaction{*transfer}, "live smoke test", client.ExecuteOptions{Timeout: submitTimeout})
+	logStep(t, "execute", operationIDOf(response), err)
+	waitMined(t, "wait for execution", response)
+
+	transactions, err := c.GetTransactions()
+	logStep(t, "list transactions", "", err)
+	for _, txn := range transactions.Transactions {
+		if txn.TransactionID == response.TransactionID {
+			t.Logf("transaction %s listed as %s", txn.TransactionID, txn.State)
+			return
+		}
+	}
+	t.Errorf("transaction %s not among the %d listed transactions", response.TransactionID, len(transactions.Transactions))
+}
+
+// operationIDOf returns the operation ID of a response, if there is one
+func operationIDOf(response *models.ClientRelayerTransactionResponse) string {
+	if response == nil {
+		return ""
+	}
+	return response.OperationID
+}
+
+// stringValue dereferences s, returning "" for nil
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}